@@ -0,0 +1,121 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admintoken issues short-lived, narrowly-scoped bearer tokens for the
+// control plane. A token is minted from an admin session, carries a subset of
+// the minting user's permissions in one scope, and lets automation call admin
+// APIs without holding a full session cookie.
+package admintoken
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// Lifetime bounds
+const (
+	// DefaultTTL is the lifetime of a token minted without an explicit TTL.
+	DefaultTTL = 10 * time.Minute
+	// MaxTTL is the longest lifetime a token may be minted with.
+	MaxTTL = time.Hour
+	// MaxPermissions bounds the permissions carried by one token.
+	MaxPermissions = 8
+)
+
+// Domain errors
+var (
+	ErrInvalidRequest    = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid admin token request")
+	ErrAdminSession      = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "admin tokens can only be minted from an admin session")
+	ErrNotPermitted      = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "permission not held by the minting user")
+	ErrInvalidToken      = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, apperror.OAuth2InvalidToken, "invalid admin token")
+	ErrInsufficientScope = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2InsufficientScope, "admin token does not grant this permission")
+	ErrTokenNotFound     = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "admin token not found")
+)
+
+// Token is a persisted admin token.
+//
+// Purpose: Delegated, time-boxed subset of an admin's permissions.
+// Domain: Authorization (Control Plane)
+// Invariants: Only the SHA-256 of the bearer value is stored. Permissions is a
+// non-empty subset of what UserID held at Scope when minted. ScopeContextID is
+// nil for platform scope and the tenant ID for tenant scope. ExpiresAt never
+// exceeds the minting session's expiry.
+type Token struct {
+	ID             string     `json:"id"`
+	TokenHash      string     `json:"-"`
+	UserID         string     `json:"user_id"`
+	SessionID      string     `json:"-"`
+	Scope          role.Scope `json:"scope"`
+	ScopeContextID *string    `json:"scope_context_id,omitempty"`
+	Permissions    []string   `json:"permissions"`
+	Description    string     `json:"description,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive reports whether the token can be used at now.
+func (t *Token) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// Grants reports whether the token carries permission for the given scope context.
+func (t *Token) Grants(scope role.Scope, scopeContextID *string, permission string) bool {
+	if t.Scope != scope || !slices.Contains(t.Permissions, permission) {
+		return false
+	}
+	if t.ScopeContextID == nil || scopeContextID == nil {
+		return t.ScopeContextID == nil && scopeContextID == nil
+	}
+	return *t.ScopeContextID == *scopeContextID
+}
+
+// MintRequest describes the token to mint.
+type MintRequest struct {
+	// Scope is platform or tenant.
+	Scope role.Scope
+	// ScopeContextID is the tenant ID for tenant scope; nil for platform scope.
+	ScopeContextID *string
+	// Permissions are the policy permissions the token carries.
+	Permissions []string
+	// TTL is the token lifetime; zero selects DefaultTTL.
+	TTL time.Duration
+	// Description labels the token in listings and audit, e.g. the automation using it.
+	Description string
+}
+
+// Repository defines admin token persistence.
+//
+// Purpose: Abstraction for storing hashed admin tokens.
+// Domain: Authorization (Control Plane)
+type Repository interface {
+	// Create stores a new token
+	Create(ctx context.Context, t *Token) error
+	// GetByHash returns the token with the given hash, or ErrTokenNotFound
+	GetByHash(ctx context.Context, tokenHash string) (*Token, error)
+	// Get returns the token with the given ID, or ErrTokenNotFound
+	Get(ctx context.Context, id string) (*Token, error)
+	// ListByUser returns the user's unexpired tokens, newest first
+	ListByUser(ctx context.Context, userID string, now time.Time) ([]*Token, error)
+	// Revoke marks the token revoked, or returns ErrTokenNotFound
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	// RevokeByUser revokes every active token of the user and returns how many were revoked
+	RevokeByUser(ctx context.Context, userID string, revokedAt time.Time) (int, error)
+	// DeleteExpired removes tokens that expired before now
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
@@ -0,0 +1,353 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admintoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/session"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Audit metadata keys
+const (
+	attrPermissions = "permissions"
+	attrScope       = "scope"
+	attrExpiresAt   = "expires_at"
+	attrDescription = "description"
+	attrCount       = "count"
+	attrReason      = "reason"
+)
+
+// SessionStore resolves the session a token is minted from; session.Service implements it.
+type SessionStore interface {
+	Get(ctx context.Context, sessionID string) (*session.Session, error)
+}
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// Service mints and verifies admin tokens.
+//
+// Purpose: Delegated control-plane access for automation without session cookies.
+// Domain: Authorization (Control Plane)
+// Invariants: A token never grants more than its owner currently holds; every
+// use re-checks the owner's permissions, so revoking a role takes effect at once.
+type Service struct {
+	repo        Repository
+	sessions    SessionStore
+	permissions PermissionChecker
+	auditLogger audit.Logger
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithTracer emits spans for minting and verification on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new admin token service.
+//
+// Purpose: Constructor for the admin token service.
+// Domain: Authorization (Control Plane)
+// Audited: No
+// Errors: None
+func NewService(repo Repository, sessions SessionStore, permissions PermissionChecker, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		sessions:    sessions,
+		permissions: permissions,
+		auditLogger: auditLogger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Mint issues a token carrying req.Permissions for the user of an admin session.
+//
+// Purpose: Lets an administrator hand automation a narrow, short-lived credential.
+// Domain: Authorization (Control Plane)
+// Security: Only admin-namespace sessions may mint. Every permission must be a
+// platform or tenant permission the user holds at req.Scope. The bearer value is
+// returned once and only its SHA-256 is stored. The token expires no later than
+// the session it was minted from.
+// Audited: Yes (AdminTokenIssued)
+// Errors: ErrInvalidRequest, ErrAdminSession, ErrNotPermitted, session errors, System errors
+func (s *Service) Mint(ctx context.Context, sessionID string, req MintRequest) (string, *Token, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "admintoken.Mint")
+	defer span.End()
+
+	if err := validateRequest(req); err != nil {
+		return "", nil, err
+	}
+	sess, err := s.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return "", nil, err
+	}
+	if sess.Namespace != session.NamespaceAdmin {
+		return "", nil, ErrAdminSession
+	}
+	for _, p := range req.Permissions {
+		ok, err := s.permissions.HasPermission(ctx, sess.UserID, req.Scope, req.ScopeContextID, p)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to check permission: %w", err)
+		}
+		if !ok {
+			return "", nil, ErrNotPermitted
+		}
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("failed to generate admin token: %w", err)
+	}
+	bearer := base64.RawURLEncoding.EncodeToString(b)
+
+	ttl := req.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	if sess.ExpiresAt.Before(expiresAt) {
+		expiresAt = sess.ExpiresAt
+	}
+
+	t := &Token{
+		ID:             id.NewUUIDv7(),
+		TokenHash:      hashToken(bearer),
+		UserID:         sess.UserID,
+		SessionID:      sess.ID,
+		Scope:          req.Scope,
+		ScopeContextID: req.ScopeContextID,
+		Permissions:    slices.Clone(req.Permissions),
+		Description:    req.Description,
+		ExpiresAt:      expiresAt,
+		CreatedAt:      now,
+	}
+	if err := s.repo.Create(ctx, t); err != nil {
+		return "", nil, fmt.Errorf("failed to create admin token: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeAdminTokenIssued,
+		TenantID: tenantOf(t),
+		ActorID:  t.UserID,
+		Resource: audit.ResourceAdminToken,
+		TargetID: t.ID,
+		Metadata: map[string]any{
+			attrPermissions: t.Permissions,
+			attrScope:       t.Scope,
+			attrExpiresAt:   t.ExpiresAt,
+			attrDescription: t.Description,
+		},
+	})
+	return bearer, t, nil
+}
+
+// Authorize verifies a bearer token and that it grants permission at the scope context.
+//
+// Purpose: Control-plane authorization check for token-authenticated requests.
+// Domain: Authorization (Control Plane)
+// Security: Unknown, expired, and revoked tokens are indistinguishable to the
+// caller. The owner's current permissions are re-checked, so a token stops
+// working as soon as the owner loses the permission.
+// Audited: No
+// Errors: ErrInvalidToken, ErrInsufficientScope, System errors
+func (s *Service) Authorize(ctx context.Context, bearer string, scope role.Scope, scopeContextID *string, permission string) (*Token, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "admintoken.Authorize")
+	defer span.End()
+
+	if bearer == "" {
+		return nil, ErrInvalidToken
+	}
+	t, err := s.repo.GetByHash(ctx, hashToken(bearer))
+	if errors.Is(err, ErrTokenNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin token: %w", err)
+	}
+	if !t.IsActive(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+	if !t.Grants(scope, scopeContextID, permission) {
+		return nil, ErrInsufficientScope
+	}
+
+	ok, err := s.permissions.HasPermission(ctx, t.UserID, scope, scopeContextID, permission)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !ok {
+		return nil, ErrInsufficientScope
+	}
+	return t, nil
+}
+
+// List returns the user's unexpired tokens, newest first.
+func (s *Service) List(ctx context.Context, userID string) ([]*Token, error) {
+	return s.repo.ListByUser(ctx, userID, time.Now())
+}
+
+// Revoke revokes one of actorID's tokens before it expires.
+//
+// Purpose: Early shutdown of a token that is no longer needed or has leaked.
+// Domain: Authorization (Control Plane)
+// Security: Tokens of other users are reported as not found.
+// Audited: Yes (AdminTokenRevoked)
+// Errors: ErrTokenNotFound, System errors
+func (s *Service) Revoke(ctx context.Context, tokenID, actorID string) error {
+	t, err := s.repo.Get(ctx, tokenID)
+	if err != nil {
+		return err
+	}
+	if t.UserID != actorID {
+		return ErrTokenNotFound
+	}
+	if t.RevokedAt != nil {
+		return nil
+	}
+	if err := s.repo.Revoke(ctx, tokenID, time.Now()); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeAdminTokenRevoked,
+		TenantID: tenantOf(t),
+		ActorID:  actorID,
+		Resource: audit.ResourceAdminToken,
+		TargetID: t.ID,
+		Metadata: map[string]any{attrPermissions: t.Permissions},
+	})
+	return nil
+}
+
+// RevokeAllForUser revokes every active token of userID.
+//
+// Purpose: Cascades credential-wide revocation (logout everywhere, lockout, deletion) to admin tokens.
+// Domain: Authorization (Control Plane)
+// Audited: Yes (AdminTokenRevoked, when any token was active)
+// Errors: System errors
+func (s *Service) RevokeAllForUser(ctx context.Context, userID, reason string) error {
+	n, err := s.repo.RevokeByUser(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke admin tokens: %w", err)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeAdminTokenRevoked,
+		Resource: audit.ResourceAdminToken,
+		TargetID: userID,
+		Metadata: map[string]any{attrCount: n, attrReason: reason},
+	})
+	return nil
+}
+
+// HandleEvent revokes a user's tokens when all their sessions are revoked or the
+// account is locked or deleted; it is an events.Handler.
+func (s *Service) HandleEvent(ctx context.Context, e events.Event) error {
+	switch ev := e.(type) {
+	case events.SessionRevoked:
+		if ev.All {
+			return s.RevokeAllForUser(ctx, ev.UserID, ev.EventName())
+		}
+	case events.UserLocked:
+		return s.RevokeAllForUser(ctx, ev.UserID, ev.EventName())
+	case events.UserDeleted:
+		return s.RevokeAllForUser(ctx, ev.UserID, ev.EventName())
+	}
+	return nil
+}
+
+// CleanupExpired removes tokens past their lifetime
+func (s *Service) CleanupExpired(ctx context.Context) error {
+	return s.repo.DeleteExpired(ctx, time.Now())
+}
+
+// validateRequest checks the shape of req before any lookup.
+func validateRequest(req MintRequest) error {
+	switch req.Scope {
+	case role.ScopePlatform:
+		if req.ScopeContextID != nil {
+			return ErrInvalidRequest
+		}
+	case role.ScopeTenant:
+		if req.ScopeContextID == nil || *req.ScopeContextID == "" {
+			return ErrInvalidRequest
+		}
+	default:
+		return ErrInvalidRequest
+	}
+	if req.TTL < 0 || req.TTL > MaxTTL {
+		return ErrInvalidRequest
+	}
+	if len(req.Permissions) == 0 || len(req.Permissions) > MaxPermissions {
+		return ErrInvalidRequest
+	}
+	for _, p := range req.Permissions {
+		if !adminPermission(p) {
+			return ErrInvalidRequest
+		}
+	}
+	return nil
+}
+
+// adminPermission reports whether p is a platform or tenant permission.
+// Self-service and client permissions are never delegated to admin tokens.
+func adminPermission(p string) bool {
+	if !slices.Contains(policy.AllPermissions, p) {
+		return false
+	}
+	return strings.HasPrefix(p, "platform:") || strings.HasPrefix(p, "tenant:")
+}
+
+// tenantOf returns the tenant a token is scoped to, or "" for platform tokens.
+func tenantOf(t *Token) string {
+	if t.ScopeContextID == nil {
+		return ""
+	}
+	return *t.ScopeContextID
+}
+
+// hashToken returns the stored form of a bearer token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,310 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admintoken
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/session"
+)
+
+type mockRepo struct {
+	tokens map[string]*Token
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{tokens: make(map[string]*Token)}
+}
+
+func (m *mockRepo) Create(ctx context.Context, t *Token) error {
+	m.tokens[t.ID] = t
+	return nil
+}
+
+func (m *mockRepo) GetByHash(ctx context.Context, tokenHash string) (*Token, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, ErrTokenNotFound
+}
+
+func (m *mockRepo) Get(ctx context.Context, id string) (*Token, error) {
+	t, ok := m.tokens[id]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return t, nil
+}
+
+func (m *mockRepo) ListByUser(ctx context.Context, userID string, now time.Time) ([]*Token, error) {
+	var out []*Token
+	for _, t := range m.tokens {
+		if t.UserID == userID && now.Before(t.ExpiresAt) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	t, ok := m.tokens[id]
+	if !ok {
+		return ErrTokenNotFound
+	}
+	t.RevokedAt = &revokedAt
+	return nil
+}
+
+func (m *mockRepo) RevokeByUser(ctx context.Context, userID string, revokedAt time.Time) (int, error) {
+	n := 0
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.IsActive(revokedAt) {
+			t.RevokedAt = &revokedAt
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	for id, t := range m.tokens {
+		if t.ExpiresAt.Before(now) {
+			delete(m.tokens, id)
+		}
+	}
+	return nil
+}
+
+type mockSessions struct {
+	sessions map[string]*session.Session
+}
+
+func (m *mockSessions) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	s, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, session.ErrSessionNotFound
+	}
+	return s, nil
+}
+
+// mockPermissions grants permission strings keyed by "user|context|permission".
+type mockPermissions struct {
+	granted map[string]bool
+}
+
+func (m *mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	ctxID := ""
+	if scopeContextID != nil {
+		ctxID = *scopeContextID
+	}
+	return m.granted[userID+"|"+ctxID+"|"+permission], nil
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+func newTestService() (*Service, *mockRepo, *mockPermissions, *mockAuditLogger) {
+	repo := newMockRepo()
+	sessions := &mockSessions{sessions: map[string]*session.Session{
+		"admin-sess": {ID: "admin-sess", UserID: "u1", Namespace: session.NamespaceAdmin, ExpiresAt: time.Now().Add(time.Hour)},
+		"short-sess": {ID: "short-sess", UserID: "u1", Namespace: session.NamespaceAdmin, ExpiresAt: time.Now().Add(time.Minute)},
+		"auth-sess":  {ID: "auth-sess", UserID: "u1", Namespace: session.NamespaceAuth, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	perms := &mockPermissions{granted: map[string]bool{
+		"u1|t1|" + policy.PermTenantManageClients: true,
+		"u1|t1|" + policy.PermTenantManageUsers:   true,
+		"u1||" + policy.PermPlatformViewAudit:     true,
+	}}
+	logger := &mockAuditLogger{}
+	return NewService(repo, sessions, perms, logger), repo, perms, logger
+}
+
+func TestMint(t *testing.T) {
+	tenant := "t1"
+	other := "t2"
+	tests := []struct {
+		name      string
+		sessionID string
+		req       MintRequest
+		wantErr   error
+	}{
+		{"tenant token", "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermTenantManageClients}}, nil},
+		{"platform token", "admin-sess", MintRequest{Scope: role.ScopePlatform, Permissions: []string{policy.PermPlatformViewAudit}, TTL: MaxTTL}, nil},
+		{"auth session", "auth-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermTenantManageClients}}, ErrAdminSession},
+		{"unknown session", "missing", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermTenantManageClients}}, session.ErrSessionNotFound},
+		{"permission not held", "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &other, Permissions: []string{policy.PermTenantManageClients}}, ErrNotPermitted},
+		{"no permissions", "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant}, ErrInvalidRequest},
+		{"self-service permission", "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermUserChangePassword}}, ErrInvalidRequest},
+		{"unknown permission", "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{"tenant:everything"}}, ErrInvalidRequest},
+		{"ttl too long", "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermTenantManageClients}, TTL: MaxTTL + time.Second}, ErrInvalidRequest},
+		{"tenant scope without tenant", "admin-sess", MintRequest{Scope: role.ScopeTenant, Permissions: []string{policy.PermTenantManageClients}}, ErrInvalidRequest},
+		{"platform scope with tenant", "admin-sess", MintRequest{Scope: role.ScopePlatform, ScopeContextID: &tenant, Permissions: []string{policy.PermPlatformViewAudit}}, ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _, logger := newTestService()
+			bearer, tok, err := svc.Mint(context.Background(), tt.sessionID, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Mint() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.tokens) != 0 || len(logger.events) != 0 {
+					t.Errorf("failed Mint() stored %d tokens, logged %d events", len(repo.tokens), len(logger.events))
+				}
+				return
+			}
+			if bearer == "" || tok.TokenHash == bearer || tok.TokenHash != hashToken(bearer) {
+				t.Errorf("Mint() must store only the hash of the bearer value")
+			}
+			if len(logger.events) != 1 || logger.events[0].Type != audit.TypeAdminTokenIssued {
+				t.Errorf("audit events = %+v", logger.events)
+			}
+		})
+	}
+}
+
+func TestMintLifetime(t *testing.T) {
+	svc, _, _, _ := newTestService()
+	tenant := "t1"
+	req := MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermTenantManageClients}}
+
+	_, tok, err := svc.Mint(context.Background(), "admin-sess", req)
+	if err != nil {
+		t.Fatalf("Mint() error = %v", err)
+	}
+	if d := time.Until(tok.ExpiresAt); d > DefaultTTL || d < DefaultTTL-time.Minute {
+		t.Errorf("default lifetime = %v, want %v", d, DefaultTTL)
+	}
+
+	_, tok, err = svc.Mint(context.Background(), "short-sess", req)
+	if err != nil {
+		t.Fatalf("Mint() error = %v", err)
+	}
+	if time.Until(tok.ExpiresAt) > time.Minute {
+		t.Errorf("token outlives its session: expires %v", tok.ExpiresAt)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ctx := context.Background()
+	tenant := "t1"
+	other := "t2"
+	svc, repo, perms, _ := newTestService()
+	bearer, tok, err := svc.Mint(ctx, "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermTenantManageClients}})
+	if err != nil {
+		t.Fatalf("Mint() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		bearer     string
+		tenantID   *string
+		permission string
+		wantErr    error
+	}{
+		{"granted", bearer, &tenant, policy.PermTenantManageClients, nil},
+		{"unknown token", "not-a-token", &tenant, policy.PermTenantManageClients, ErrInvalidToken},
+		{"empty token", "", &tenant, policy.PermTenantManageClients, ErrInvalidToken},
+		{"permission not carried", bearer, &tenant, policy.PermTenantManageUsers, ErrInsufficientScope},
+		{"other tenant", bearer, &other, policy.PermTenantManageClients, ErrInsufficientScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Authorize(ctx, tt.bearer, role.ScopeTenant, tt.tenantID, tt.permission)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	perms.granted["u1|t1|"+policy.PermTenantManageClients] = false
+	if _, err := svc.Authorize(ctx, bearer, role.ScopeTenant, &tenant, policy.PermTenantManageClients); !errors.Is(err, ErrInsufficientScope) {
+		t.Errorf("Authorize() after role loss error = %v, want %v", err, ErrInsufficientScope)
+	}
+
+	repo.tokens[tok.ID].ExpiresAt = time.Now().Add(-time.Second)
+	perms.granted["u1|t1|"+policy.PermTenantManageClients] = true
+	if _, err := svc.Authorize(ctx, bearer, role.ScopeTenant, &tenant, policy.PermTenantManageClients); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authorize() of expired token error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	tenant := "t1"
+	svc, _, _, logger := newTestService()
+	bearer, tok, err := svc.Mint(ctx, "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermTenantManageClients}})
+	if err != nil {
+		t.Fatalf("Mint() error = %v", err)
+	}
+
+	if err := svc.Revoke(ctx, tok.ID, "u2"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Revoke() by other user error = %v, want %v", err, ErrTokenNotFound)
+	}
+	if err := svc.Revoke(ctx, tok.ID, "u1"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := svc.Authorize(ctx, bearer, role.ScopeTenant, &tenant, policy.PermTenantManageClients); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authorize() of revoked token error = %v, want %v", err, ErrInvalidToken)
+	}
+	if last := logger.events[len(logger.events)-1]; last.Type != audit.TypeAdminTokenRevoked {
+		t.Errorf("last audit event = %q, want %q", last.Type, audit.TypeAdminTokenRevoked)
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	tenant := "t1"
+	tests := []struct {
+		name        string
+		event       events.Event
+		wantRevoked bool
+	}{
+		{"single session revoked", events.SessionRevoked{UserID: "u1"}, false},
+		{"all sessions revoked", events.SessionRevoked{UserID: "u1", All: true}, true},
+		{"user locked", events.UserLocked{UserID: "u1"}, true},
+		{"user deleted", events.UserDeleted{UserID: "u1"}, true},
+		{"other user", events.UserDeleted{UserID: "u2"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, repo, _, _ := newTestService()
+			_, tok, err := svc.Mint(ctx, "admin-sess", MintRequest{Scope: role.ScopeTenant, ScopeContextID: &tenant, Permissions: []string{policy.PermTenantManageClients}})
+			if err != nil {
+				t.Fatalf("Mint() error = %v", err)
+			}
+			if err := svc.HandleEvent(ctx, tt.event); err != nil {
+				t.Fatalf("HandleEvent() error = %v", err)
+			}
+			if revoked := repo.tokens[tok.ID].RevokedAt != nil; revoked != tt.wantRevoked {
+				t.Errorf("revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apperror defines the structured error model shared by all core
+// services. Domain sentinel errors are *Error values, so callers keep using
+// errors.Is against the sentinels while transports use errors.As (or the
+// helpers here) to obtain a stable code, an HTTP status hint, the OAuth2
+// error parameter, and a message that is safe to show to clients.
+package apperror
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Code is a stable identifier for an error condition.
+// Codes are part of the public contract and must never be renamed.
+type Code string
+
+// Error codes
+const (
+	CodeInternal              Code = "internal_error"
+	CodeInvalidRequest        Code = "invalid_request"
+	CodeInvalidCredentials    Code = "invalid_credentials"
+	CodeAccountLocked         Code = "account_locked"
+	CodeWeakPassword          Code = "weak_password"
+	CodeInvalidEmail          Code = "invalid_email"
+	CodeUserAlreadyExists     Code = "user_already_exists"
+	CodeNotFound              Code = "not_found"
+	CodeAlreadyExists         Code = "already_exists"
+	CodeSessionExpired        Code = "session_expired"
+	CodeAccessDenied          Code = "access_denied"
+	CodeInvalidScope          Code = "invalid_scope"
+	CodeInvalidRedirectURI    Code = "invalid_redirect_uri"
+	CodeInvalidGrantType      Code = "invalid_grant_type"
+	CodeInvalidClient         Code = "invalid_client"
+	CodeInvalidGrant          Code = "invalid_grant"
+	CodeInvalidToken          Code = "invalid_token"
+	CodeInvalidTenantName     Code = "invalid_tenant_name"
+	CodeSourceBlocked         Code = "source_blocked"
+	CodePasswordExpired       Code = "password_expired"
+	CodeMFARequired           Code = "mfa_required"
+	CodeMFAEnrollmentRequired Code = "mfa_enrollment_required"
+	CodePasswordResetRequired Code = "password_reset_required"
+	CodeServiceUnavailable    Code = "service_unavailable"
+	CodeUnauthenticated       Code = "unauthenticated"
+)
+
+// Codes returns every defined code.
+func Codes() []Code {
+	return []Code{
+		CodeInternal, CodeInvalidRequest, CodeInvalidCredentials, CodeAccountLocked,
+		CodeWeakPassword, CodeInvalidEmail, CodeUserAlreadyExists, CodeNotFound,
+		CodeAlreadyExists, CodeSessionExpired, CodeAccessDenied, CodeInvalidScope,
+		CodeInvalidRedirectURI, CodeInvalidGrantType, CodeInvalidClient, CodeInvalidGrant,
+		CodeInvalidToken, CodeInvalidTenantName, CodeSourceBlocked, CodePasswordExpired,
+		CodeMFARequired, CodeMFAEnrollmentRequired, CodePasswordResetRequired, CodeServiceUnavailable,
+		CodeUnauthenticated,
+	}
+}
+
+// HTTP status hints. Core does not import net/http.
+const (
+	StatusBadRequest          = 400
+	StatusUnauthorized        = 401
+	StatusForbidden           = 403
+	StatusNotFound            = 404
+	StatusConflict            = 409
+	StatusLocked              = 423
+	StatusTooManyRequests     = 429
+	StatusInternalServerError = 500
+	StatusServiceUnavailable  = 503
+)
+
+// OAuth2 error parameters (RFC 6749 Section 5.2, RFC 6750 Section 3.1, RFC 7591 Section 3.2.2, RFC 9449 Section 7.1, OIDC Core Section 3.1.2.6)
+const (
+	OAuth2InvalidRequest          = "invalid_request"
+	OAuth2InvalidClient           = "invalid_client"
+	OAuth2InvalidGrant            = "invalid_grant"
+	OAuth2UnauthorizedClient      = "unauthorized_client"
+	OAuth2UnsupportedGrantType    = "unsupported_grant_type"
+	OAuth2UnsupportedResponseType = "unsupported_response_type"
+	OAuth2InvalidScope            = "invalid_scope"
+	OAuth2AccessDenied            = "access_denied"
+	OAuth2ServerError             = "server_error"
+	OAuth2TemporarilyUnavailable  = "temporarily_unavailable"
+	OAuth2InvalidToken            = "invalid_token"
+	OAuth2InsufficientScope       = "insufficient_scope"
+	OAuth2LoginRequired           = "login_required"
+	OAuth2InteractionRequired     = "interaction_required"
+	OAuth2InvalidDPoPProof        = "invalid_dpop_proof"
+	OAuth2InvalidTarget           = "invalid_target"
+	OAuth2InvalidRedirectURI      = "invalid_redirect_uri"
+	OAuth2InvalidClientMetadata   = "invalid_client_metadata"
+)
+
+// Error is a classified domain error.
+//
+// Purpose: Machine-readable error contract between core and transports.
+// Domain: Platform
+// Invariants: Code is a Code constant. Message is safe to return to clients and never
+// contains secrets, identifiers, or user input. OAuth2 is empty when the condition has
+// no OAuth2 meaning.
+type Error struct {
+	Code    Code
+	Status  int
+	OAuth2  string
+	Message string
+}
+
+// Error returns the safe message.
+func (e *Error) Error() string { return e.Message }
+
+// New returns a classified error. It is used to declare domain sentinels:
+//
+//	var ErrUserNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "user not found")
+func New(code Code, status int, oauth2, message string) error {
+	return &Error{Code: code, Status: status, OAuth2: oauth2, Message: message}
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// CodeOf returns err's code, or CodeInternal for unclassified errors.
+func CodeOf(err error) Code {
+	if e, ok := As(err); ok {
+		return e.Code
+	}
+	return CodeInternal
+}
+
+// StatusOf returns err's HTTP status hint, or 500 for unclassified errors.
+func StatusOf(err error) int {
+	if e, ok := As(err); ok && e.Status != 0 {
+		return e.Status
+	}
+	return StatusInternalServerError
+}
+
+// OAuth2Of returns the OAuth2 error parameter for err. Classified errors
+// without an explicit value map to invalid_request for client errors;
+// everything else is server_error.
+func OAuth2Of(err error) string {
+	e, ok := As(err)
+	switch {
+	case !ok:
+		return OAuth2ServerError
+	case e.OAuth2 != "":
+		return e.OAuth2
+	case e.Status >= 400 && e.Status < 500:
+		return OAuth2InvalidRequest
+	default:
+		return OAuth2ServerError
+	}
+}
+
+// PublicMessage returns the client-safe message for err. Unclassified errors,
+// which may carry internal detail, are replaced by a generic message.
+//
+// Purpose: Prevents wrapped context ("failed to ...: dial tcp ...") from reaching clients.
+// Domain: Platform
+// Security: Only sentinel messages are ever returned.
+// Audited: No
+// Errors: None
+func PublicMessage(err error) string {
+	if e, ok := As(err); ok {
+		return e.Message
+	}
+	return "internal error"
+}
+
+// Response is the client-facing body of an error.
+//
+// Purpose: One error shape for every transport, compatible with OAuth2 error responses.
+// Domain: Platform
+// Invariants: Carries only values safe for clients; CorrelationID lets support find the
+// server-side logs and audit events of the failed action.
+type Response struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Code             Code   `json:"code"`
+	CorrelationID    string `json:"correlation_id,omitempty"`
+}
+
+// ResponseOf builds the client-facing body of err for the request in ctx.
+func ResponseOf(ctx context.Context, err error) Response {
+	return Response{
+		Error:            OAuth2Of(err),
+		ErrorDescription: PublicMessage(err),
+		Code:             CodeOf(err),
+		CorrelationID:    tracing.CorrelationID(ctx),
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+var errSentinel = New(CodeInvalidGrant, StatusBadRequest, OAuth2InvalidGrant, "authorization code expired")
+
+func TestClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    Code
+		wantStatus  int
+		wantOAuth2  string
+		wantMessage string
+	}{
+		{
+			name: "sentinel", err: errSentinel,
+			wantCode: CodeInvalidGrant, wantStatus: 400, wantOAuth2: "invalid_grant", wantMessage: "authorization code expired",
+		},
+		{
+			name: "wrapped keeps classification and drops context", err: fmt.Errorf("failed to exchange code abc123: %w", errSentinel),
+			wantCode: CodeInvalidGrant, wantStatus: 400, wantOAuth2: "invalid_grant", wantMessage: "authorization code expired",
+		},
+		{
+			name: "client error without oauth2 parameter", err: New(CodeNotFound, StatusNotFound, "", "tenant not found"),
+			wantCode: CodeNotFound, wantStatus: 404, wantOAuth2: "invalid_request", wantMessage: "tenant not found",
+		},
+		{
+			name: "unclassified", err: errors.New("dial tcp 10.0.0.5:5432: connection refused"),
+			wantCode: CodeInternal, wantStatus: 500, wantOAuth2: "server_error", wantMessage: "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.wantCode {
+				t.Errorf("CodeOf() = %s, want %s", got, tt.wantCode)
+			}
+			if got := StatusOf(tt.err); got != tt.wantStatus {
+				t.Errorf("StatusOf() = %d, want %d", got, tt.wantStatus)
+			}
+			if got := OAuth2Of(tt.err); got != tt.wantOAuth2 {
+				t.Errorf("OAuth2Of() = %s, want %s", got, tt.wantOAuth2)
+			}
+			if got := PublicMessage(tt.err); got != tt.wantMessage {
+				t.Errorf("PublicMessage() = %q, want %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestSentinelIdentity(t *testing.T) {
+	other := New(CodeInvalidGrant, StatusBadRequest, OAuth2InvalidGrant, "authorization code expired")
+	if errors.Is(fmt.Errorf("wrap: %w", errSentinel), other) {
+		t.Error("distinct sentinels with equal fields must not match")
+	}
+	if !errors.Is(fmt.Errorf("wrap: %w", errSentinel), errSentinel) {
+		t.Error("wrapped sentinel must match itself")
+	}
+}
+
+func TestResponseOf(t *testing.T) {
+	ctx := tracing.WithCorrelationID(context.Background(), "corr-1")
+
+	got := ResponseOf(ctx, fmt.Errorf("failed to exchange code: %w", errSentinel))
+	want := Response{Error: OAuth2InvalidGrant, ErrorDescription: "authorization code expired", Code: CodeInvalidGrant, CorrelationID: "corr-1"}
+	if got != want {
+		t.Errorf("ResponseOf() = %+v, want %+v", got, want)
+	}
+
+	got = ResponseOf(context.Background(), errors.New("dial tcp: connection refused"))
+	if got.ErrorDescription != "internal error" || got.Code != CodeInternal || got.CorrelationID != "" {
+		t.Errorf("ResponseOf() of unclassified error = %+v", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "jittered up to 10%", delay: time.Minute, wantMin: time.Minute, wantMax: time.Minute + 6*time.Second},
+		{name: "floored at one second", delay: 0, wantMin: time.Second, wantMax: time.Second + 100*time.Millisecond},
+		{name: "elapsed deadline floored", delay: -time.Hour, wantMin: time.Second, wantMax: time.Second + 100*time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("failed to log in: %w", WithRetryAfter(errSentinel, tt.delay))
+			got, ok := RetryAfterOf(err)
+			if !ok || got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("RetryAfterOf() = %v, %v; want in [%v, %v]", got, ok, tt.wantMin, tt.wantMax)
+			}
+			if !errors.Is(err, errSentinel) || CodeOf(err) != CodeInvalidGrant {
+				t.Errorf("retry delay must keep the classification, got %v", CodeOf(err))
+			}
+		})
+	}
+
+	if got := RetryAfterSeconds(&RetryAfterError{Err: errSentinel, After: 1500 * time.Millisecond}); got != 2 {
+		t.Errorf("RetryAfterSeconds() = %d, want 2 (rounded up)", got)
+	}
+	if got := RetryAfterSeconds(errSentinel); got != 0 {
+		t.Errorf("RetryAfterSeconds() without delay = %d, want 0", got)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apperror
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// retryJitter is the largest fraction of a retry delay added as random jitter.
+const retryJitter = 0.1
+
+// RetryAfterError attaches a retry delay to a throttling or lockout error.
+//
+// Purpose: Structured Retry-After feedback; transports send RetryAfterSeconds in the
+// Retry-After header (RFC 9110 Section 10.2.3) with StatusOf's 429 or 423.
+// Domain: Platform
+// Invariants: Unwraps to the classified error it annotates. After is positive.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+// Error returns the wrapped error's message.
+func (e *RetryAfterError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the annotated error.
+func (e *RetryAfterError) Unwrap() error { return e.Err }
+
+// WithRetryAfter annotates err with the retry delay RetryDelay(d).
+func WithRetryAfter(err error, d time.Duration) error {
+	return &RetryAfterError{Err: err, After: RetryDelay(d)}
+}
+
+// RetryDelay returns d plus up to 10% random jitter, and at least one second, so
+// clients throttled together do not retry together. Jitter only lengthens the delay;
+// a client waiting for it never retries before d has passed.
+func RetryDelay(d time.Duration) time.Duration {
+	if d < time.Second {
+		d = time.Second
+	}
+	return d + time.Duration(rand.Int64N(int64(float64(d)*retryJitter)+1))
+}
+
+// RetryAfterOf returns the retry delay attached to err.
+func RetryAfterOf(err error) (time.Duration, bool) {
+	var e *RetryAfterError
+	if errors.As(err, &e) {
+		return e.After, true
+	}
+	return 0, false
+}
+
+// RetryAfterSeconds returns the Retry-After header value of err in whole seconds,
+// rounded up, or 0 if err carries no retry delay.
+func RetryAfterSeconds(err error) int {
+	d, ok := RetryAfterOf(err)
+	if !ok {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -19,39 +19,108 @@ import (
 	"log/slog"
 	"strings"
 	"time"
+
+	"github.com/opentrusty/opentrusty-core/metrics"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/tracing"
 )
 
 // Event types
 const (
-	TypeLoginSuccess           = "login_success"
-	TypeLoginFailed            = "login_failed"
-	TypeTokenIssued            = "token_issued"
-	TypeTokenRevoked           = "token_revoked"
-	TypeRoleAssigned           = "role_assigned"
-	TypeRoleRevoked            = "role_revoked"
-	TypeClientCreated          = "client_created"
-	TypeSecretRotated          = "secret_rotated"
-	TypeUserLocked             = "user_locked"
-	TypeUserUnlocked           = "user_unlocked"
-	TypeUserCreated            = "user_created"
-	TypePasswordChanged        = "password_changed"
-	TypeLogout                 = "logout"
-	TypePlatformAdminBootstrap = "platform_admin_bootstrap"
-	TypeTenantCreated          = "tenant_created"
-	TypeTenantUpdated          = "tenant_updated"
-	TypeTenantDeleted          = "tenant_deleted"
-	TypeClientDeleted          = "client_deleted"
-	TypeClientUpdated          = "client_updated"
-	TypeUserUpdated            = "user_updated"
+	TypeLoginSuccess    = "login_success"
+	TypeLoginFailed     = "login_failed"
+	TypeTokenIssued     = "token_issued"
+	TypeTokenRevoked    = "token_revoked"
+	TypeRoleAssigned    = "role_assigned"
+	TypeRoleRevoked     = "role_revoked"
+	TypeClientCreated   = "client_created"
+	TypeSecretRotated   = "secret_rotated"
+	TypeUserLocked      = "user_locked"
+	TypeUserUnlocked    = "user_unlocked"
+	TypeUserCreated     = "user_created"
+	TypePasswordChanged = "password_changed"
+	// TypeCredentialResetRequired is emitted when an administrator invalidates a user's password
+	TypeCredentialResetRequired = "credential_reset_required"
+	TypeLogout                  = "logout"
+	TypePlatformAdminBootstrap  = "platform_admin_bootstrap"
+	TypeTenantCreated           = "tenant_created"
+	TypeTenantUpdated           = "tenant_updated"
+	TypeTenantDeleted           = "tenant_deleted"
+	TypeClientDeleted           = "client_deleted"
+	TypeClientUpdated           = "client_updated"
+	TypeClientTrustChanged      = "client_trust_changed"
+	TypeConsentGranted          = "consent_granted"
+	TypeConsentRevoked          = "consent_revoked"
+	TypeConsentReceiptsExported = "consent_receipts_exported"
+	TypeUserUpdated             = "user_updated"
+	TypeUserDeleted             = "user_deleted"
+	TypeIdentityLinked          = "identity_linked"
+	TypeIdentityUnlinked        = "identity_unlinked"
+	TypeRecoveryRequested       = "recovery_requested"
+	TypeRecoveryCancelled       = "recovery_cancelled"
+	TypeRecoveryAttested        = "recovery_attested"
+	TypeRecoveryCompleted       = "recovery_completed"
+	TypeSourceBlocked           = "source_blocked"
+	TypeSourceUnblocked         = "source_unblocked"
+	TypeSourceAllowlisted       = "source_allowlisted"
+	TypeSourceAllowlistRemoved  = "source_allowlist_removed"
+	// TypeCredentialStuffingDetected is emitted when one source fails against many distinct accounts
+	TypeCredentialStuffingDetected = "credential_stuffing_detected"
+	// TypeBruteForceDetected is emitted when a network (ASN) exceeds its failure threshold
+	TypeBruteForceDetected = "brute_force_detected"
+	// TypeSuspiciousLogin is emitted when a successful login comes from a new country or implies impossible travel
+	TypeSuspiciousLogin    = "suspicious_login"
+	TypeWebhookCreated     = "webhook_created"
+	TypeWebhookUpdated     = "webhook_updated"
+	TypeWebhookDeleted     = "webhook_deleted"
+	TypeSCIMTargetCreated  = "scim_target_created"
+	TypeSCIMTargetUpdated  = "scim_target_updated"
+	TypeSCIMTargetDeleted  = "scim_target_deleted"
+	TypeFeatureFlagUpdated = "feature_flag_updated"
+	TypeIntegrityRepaired  = "integrity_repaired"
+	TypeLegalHoldPlaced    = "legal_hold_placed"
+	TypeLegalHoldReleased  = "legal_hold_released"
+	TypeRoleMappingCreated = "role_mapping_created"
+	TypeRoleMappingDeleted = "role_mapping_deleted"
 	// TypeAuditRead is emitted when a platform admin accesses tenant audit logs
 	TypeAuditRead = "audit.read"
 	// TypeAuditReadCrossTenant is emitted when a platform admin declares intent for cross-tenant audit access
 	TypeAuditReadCrossTenant = "audit.read.cross_tenant"
+	// TypeExcessiveIssuance is emitted when a user is issued tokens or sessions faster than the issuance policy allows
+	TypeExcessiveIssuance = "excessive_issuance"
+	// TypeIssuanceFlagReleased is emitted when an operator clears an excessive issuance flag
+	TypeIssuanceFlagReleased = "issuance_flag_released"
+	// TypeAdminTokenIssued is emitted when a scoped admin token is minted from an admin session
+	TypeAdminTokenIssued = "admin_token_issued"
+	// TypeAdminTokenRevoked is emitted when admin tokens are revoked before they expire
+	TypeAdminTokenRevoked = "admin_token_revoked"
+	// TypePlatformModeSet is emitted when the platform enters or leaves read-only or maintenance mode
+	TypePlatformModeSet = "platform_mode_set"
+	// TypeBackupExported is emitted when critical tables are exported to an encrypted backup archive
+	TypeBackupExported = "backup_exported"
+	// TypeBackupRestored is emitted when a backup archive is restored into the database
+	TypeBackupRestored = "backup_restored"
+	// TypeSigningKeyGenerated is emitted when a tenant signing key is generated and published
+	TypeSigningKeyGenerated = "signing_key_generated"
+	// TypeSigningKeyActivated is emitted when a published signing key starts signing tokens
+	TypeSigningKeyActivated = "signing_key_activated"
+	// TypeSigningKeyRetired is emitted when a signing key stops signing and enters its grace period
+	TypeSigningKeyRetired = "signing_key_retired"
+	// TypeSigningKeyPurged is emitted when a retired signing key leaves the JWKS after its grace period
+	TypeSigningKeyPurged = "signing_key_purged"
+	// TypeAccessDenied is emitted when an authorization guard refuses a request
+	TypeAccessDenied = "access_denied"
+	// TypeDelegationApproved is emitted when a service account is allowed to mint tokens for a user
+	TypeDelegationApproved = "delegation_approved"
+	// TypeDelegationRevoked is emitted when a delegation agreement is revoked with its tokens
+	TypeDelegationRevoked = "delegation_revoked"
 )
 
 // Standard audit attribute keys
 const (
 	AttrAuditType  = "audit_type"
+	AttrSeverity   = "severity"
+	AttrCategory   = "category"
 	AttrTenantID   = "tenant_id"
 	AttrActorID    = "actor_id"
 	AttrActorName  = "actor_name"
@@ -63,6 +132,9 @@ const (
 	AttrUserAgent  = "user_agent"
 	AttrComponent  = "component"
 	AttrMetadata   = "metadata"
+	AttrTraceID    = "trace_id"
+	// AttrCorrelationID is tracing.LogCorrelationID
+	AttrCorrelationID = "correlation_id"
 )
 
 // Common Resource Types
@@ -75,6 +147,20 @@ const (
 	ResourceSession         = "session"
 	ResourceUserCredentials = "user_credentials"
 	ResourceToken           = "token"
+	ResourceNetwork         = "network"
+	ResourceWebhook         = "webhook"
+	ResourceSCIMTarget      = "scim_target"
+	ResourceFeatureFlag     = "feature_flag"
+	ResourceConsent         = "consent"
+	ResourceRoleMapping     = "role_mapping"
+	ResourceIdentity        = "identity"
+	ResourceRecovery        = "recovery_request"
+	ResourceLegalHold       = "legal_hold"
+	ResourceAdminToken      = "admin_token"
+	ResourcePlatformMode    = "platform_mode"
+	ResourceBackup          = "backup"
+	ResourceSigningKey      = "signing_key"
+	ResourceDelegation      = "delegation"
 )
 
 // Standard Actor IDs
@@ -90,16 +176,21 @@ const (
 	AttrAttempts   = "attempts"
 	AttrSessionID  = "session_id"
 	AttrTenantName = "tenant_name"
+	// AttrGrantID links events of one authorization grant: code, tokens, introspections, revocations
+	AttrGrantID = "grant_id"
 )
 
 // Event represents an auditable action.
 //
 // Purpose: Canonical representation of a security or system event.
 // Domain: Audit
-// Invariants: Type must be a known Type constant. Timestamp must be set.
+// Invariants: Type must be a known Type constant. Timestamp must be set. Loggers set
+// Severity and Category from the type when the caller leaves them empty.
 type Event struct {
 	ID         string         `json:"id"`
 	Type       string         `json:"type"`
+	Severity   Severity       `json:"severity"`
+	Category   Category       `json:"category"`
 	TenantID   string         `json:"tenant_id"`
 	ActorID    string         `json:"actor_id"`
 	ActorName  string         `json:"actor_name"`
@@ -110,6 +201,10 @@ type Event struct {
 	Timestamp  time.Time      `json:"created_at"` // Match frontend expectation
 	IPAddress  string         `json:"ip_address"`
 	UserAgent  string         `json:"user_agent"`
+	TraceID    string         `json:"trace_id,omitempty"`
+
+	// CorrelationID links the events of one user action across requests and subsystems
+	CorrelationID string `json:"correlation_id,omitempty"`
 }
 
 // Logger defines the interface for audit logging.
@@ -120,15 +215,38 @@ type Logger interface {
 	Log(ctx context.Context, event Event)
 }
 
+// Flusher is implemented by loggers that buffer events before persisting them.
+//
+// Purpose: Lets shutdown drain buffered audit events before the store is closed.
+// Domain: Audit
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Flush persists any events buffered by l. Loggers that write synchronously
+// have nothing to flush.
+func Flush(ctx context.Context, l Logger) error {
+	if f, ok := l.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
 // Filter defines criteria for listing audit events
 type Filter struct {
 	TenantID  *string
 	ActorID   *string
 	Type      *string
+	GrantID   *string
 	StartDate *time.Time
 	EndDate   *time.Time
 	Limit     int
 	Offset    int
+
+	// MinSeverity keeps events of this severity or above
+	MinSeverity   *Severity
+	Category      *Category
+	CorrelationID *string
 }
 
 // Repository defines storage for audit events.
@@ -142,6 +260,31 @@ type Repository interface {
 	List(ctx context.Context, filter Filter) ([]Event, int, error)
 }
 
+// enrich fills the fields a caller left empty: the timestamp, trace and
+// correlation IDs, and the actor, address, and user agent the transport put on
+// ctx with requestctx. It then classifies the event.
+func (e *Event) enrich(ctx context.Context) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	if e.TraceID == "" {
+		e.TraceID = tracing.TraceID(ctx)
+	}
+	if e.CorrelationID == "" {
+		e.CorrelationID = tracing.CorrelationID(ctx)
+	}
+	if e.ActorID == "" {
+		e.ActorID = requestctx.ActorID(ctx)
+	}
+	if e.IPAddress == "" {
+		e.IPAddress = requestctx.IPAddress(ctx)
+	}
+	if e.UserAgent == "" {
+		e.UserAgent = requestctx.UserAgent(ctx)
+	}
+	e.classify()
+}
+
 // SlogLogger implements Logger using slog
 type SlogLogger struct{}
 
@@ -157,14 +300,13 @@ func NewSlogLogger() *SlogLogger {
 
 // Log records an audit event
 func (l *SlogLogger) Log(ctx context.Context, event Event) {
-	// Ensure timestamp is set
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now()
-	}
+	event.enrich(ctx)
 
 	// Prepare attributes
 	attrs := []any{
 		slog.String(AttrAuditType, event.Type),
+		slog.String(AttrSeverity, string(event.Severity)),
+		slog.String(AttrCategory, string(event.Category)),
 		slog.String(AttrTenantID, event.TenantID),
 		slog.String(AttrActorID, event.ActorID),
 		slog.String(AttrActorName, event.ActorName),
@@ -180,6 +322,12 @@ func (l *SlogLogger) Log(ctx context.Context, event Event) {
 	if event.UserAgent != "" {
 		attrs = append(attrs, slog.String(AttrUserAgent, event.UserAgent))
 	}
+	if event.TraceID != "" {
+		attrs = append(attrs, slog.String(AttrTraceID, event.TraceID))
+	}
+	if event.CorrelationID != "" {
+		attrs = append(attrs, slog.String(AttrCorrelationID, event.CorrelationID))
+	}
 
 	// Flatten metadata
 	if len(event.Metadata) > 0 {
@@ -200,24 +348,35 @@ func (l *SlogLogger) Log(ctx context.Context, event Event) {
 
 // RepositoryLogger implements Logger using a Repository and Slog
 type RepositoryLogger struct {
-	repo Repository
-	slog *SlogLogger
+	repo    Repository
+	slog    *SlogLogger
+	metrics *metrics.Metrics
+}
+
+// RepositoryLoggerOption configures optional RepositoryLogger dependencies.
+type RepositoryLoggerOption func(*RepositoryLogger)
+
+// WithMetrics counts events that fail to persist on m.
+func WithMetrics(m *metrics.Metrics) RepositoryLoggerOption {
+	return func(l *RepositoryLogger) { l.metrics = m }
 }
 
 // NewRepositoryLogger creates a new repository-backed logger
-func NewRepositoryLogger(repo Repository) *RepositoryLogger {
-	return &RepositoryLogger{
+func NewRepositoryLogger(repo Repository, opts ...RepositoryLoggerOption) *RepositoryLogger {
+	l := &RepositoryLogger{
 		repo: repo,
 		slog: NewSlogLogger(),
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 // Log records an audit event to both Slog and Repository
 func (l *RepositoryLogger) Log(ctx context.Context, event Event) {
-	// Ensure timestamp is set before processing
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now()
-	}
+	// Ensure timestamp, trace correlation, and request facts are set before processing
+	event.enrich(ctx)
 
 	// 1. Log to Slog (Stdout)
 	l.slog.Log(ctx, event)
@@ -227,6 +386,7 @@ func (l *RepositoryLogger) Log(ctx context.Context, event Event) {
 	// For now, synchronous execution to ensure audit trial integrity.
 	if err := l.repo.Log(ctx, event); err != nil {
 		slog.ErrorContext(ctx, "failed to persist audit event", "error", err)
+		l.metrics.AuditWriteFailed()
 	}
 }
 
@@ -0,0 +1,97 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Privileged operations a tenant may require a justification for
+const (
+	OpRoleGrant            = "role_grant"
+	OpImpersonation        = "impersonation"
+	OpCrossTenantAuditRead = "cross_tenant_audit_read"
+	OpClientTrust          = "client_trust"
+	OpDelegation           = "delegation"
+)
+
+// PrivilegedOperations lists every operation that accepts a required reason.
+var PrivilegedOperations = []string{OpRoleGrant, OpImpersonation, OpCrossTenantAuditRead, OpClientTrust, OpDelegation}
+
+// MaxReasonLength bounds a recorded justification.
+const MaxReasonLength = 500
+
+// Reason errors
+var (
+	ErrReasonRequired = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "a reason is required for this operation")
+	ErrReasonTooLong  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "reason is too long")
+)
+
+// ReasonPolicy reports whether a tenant requires a reason for an operation;
+// tenant.Service implements it.
+type ReasonPolicy interface {
+	ReasonRequired(ctx context.Context, tenantID, operation string) (bool, error)
+}
+
+type reasonKey struct{}
+
+// WithReason returns ctx carrying the actor's justification for the privileged
+// operation the context is used for. Transports set it from the request.
+func WithReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, reasonKey{}, strings.TrimSpace(reason))
+}
+
+// Reason returns the justification in ctx, or "".
+func Reason(ctx context.Context) string {
+	v, _ := ctx.Value(reasonKey{}).(string)
+	return v
+}
+
+// RequireReason returns the justification in ctx for operation in tenantID,
+// failing when the tenant requires one and none was given.
+//
+// Purpose: Single enforcement point for per-tenant justification requirements.
+// Domain: Audit
+// Security: A nil policy or an empty tenantID never requires a reason. The returned
+// reason is recorded under AttrReason in the operation's audit event.
+// Audited: No
+// Errors: ErrReasonRequired, ErrReasonTooLong, System errors
+func RequireReason(ctx context.Context, policy ReasonPolicy, tenantID, operation string) (string, error) {
+	reason := Reason(ctx)
+	if len(reason) > MaxReasonLength {
+		return "", ErrReasonTooLong
+	}
+	if reason != "" || policy == nil || tenantID == "" {
+		return reason, nil
+	}
+	required, err := policy.ReasonRequired(ctx, tenantID, operation)
+	if err != nil {
+		return "", fmt.Errorf("failed to check reason policy: %w", err)
+	}
+	if required {
+		return "", ErrReasonRequired
+	}
+	return "", nil
+}
+
+// IsPrivilegedOperation reports whether op is one of PrivilegedOperations.
+func IsPrivilegedOperation(op string) bool {
+	return slices.Contains(PrivilegedOperations, op)
+}
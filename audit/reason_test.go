@@ -0,0 +1,64 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type mockReasonPolicy map[string]bool
+
+func (m mockReasonPolicy) ReasonRequired(ctx context.Context, tenantID, operation string) (bool, error) {
+	return m[tenantID+"@"+operation], nil
+}
+
+func TestRequireReason(t *testing.T) {
+	policy := mockReasonPolicy{"t1@" + OpRoleGrant: true}
+	tests := []struct {
+		name       string
+		policy     ReasonPolicy
+		tenantID   string
+		operation  string
+		reason     string
+		wantReason string
+		wantErr    error
+	}{
+		{"required and given", policy, "t1", OpRoleGrant, " ticket 42 ", "ticket 42", nil},
+		{"required and missing", policy, "t1", OpRoleGrant, "", "", ErrReasonRequired},
+		{"blank reason", policy, "t1", OpRoleGrant, "   ", "", ErrReasonRequired},
+		{"other operation", policy, "t1", OpClientTrust, "", "", nil},
+		{"platform scope", policy, "", OpRoleGrant, "", "", nil},
+		{"no policy", nil, "t1", OpRoleGrant, "", "", nil},
+		{"too long", policy, "t1", OpRoleGrant, strings.Repeat("x", MaxReasonLength+1), "", ErrReasonTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.reason != "" {
+				ctx = WithReason(ctx, tt.reason)
+			}
+			got, err := RequireReason(ctx, tt.policy, tt.tenantID, tt.operation)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RequireReason() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.wantReason {
+				t.Errorf("RequireReason() = %q, want %q", got, tt.wantReason)
+			}
+		})
+	}
+}
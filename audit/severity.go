@@ -0,0 +1,184 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import "slices"
+
+// Severity ranks how urgently an event needs attention.
+type Severity string
+
+// Severities, in increasing order
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarn     Severity = "warn"
+	SeverityCritical Severity = "critical"
+)
+
+var severityOrder = []Severity{SeverityInfo, SeverityWarn, SeverityCritical}
+
+// IsValid reports whether s is a known severity.
+func (s Severity) IsValid() bool {
+	return slices.Contains(severityOrder, s)
+}
+
+// AtLeast reports whether s is as severe as min or more.
+func (s Severity) AtLeast(min Severity) bool {
+	return slices.Index(severityOrder, s) >= slices.Index(severityOrder, min)
+}
+
+// SeveritiesFrom returns min and every severity above it.
+func SeveritiesFrom(min Severity) []Severity {
+	i := slices.Index(severityOrder, min)
+	if i < 0 {
+		return nil
+	}
+	return slices.Clone(severityOrder[i:])
+}
+
+// Category groups events by the security domain they concern.
+type Category string
+
+// Categories
+const (
+	// CategoryAuthn covers sign-in, credentials, recovery, and attacks on them
+	CategoryAuthn Category = "authn"
+	// CategoryAuthz covers roles, consent, trust, and tokens
+	CategoryAuthz Category = "authz"
+	// CategoryAdmin covers configuration of tenants, users, clients, and integrations
+	CategoryAdmin Category = "admin"
+	// CategoryData covers access to and export of recorded data
+	CategoryData Category = "data"
+)
+
+// IsValid reports whether c is a known category.
+func (c Category) IsValid() bool {
+	switch c {
+	case CategoryAuthn, CategoryAuthz, CategoryAdmin, CategoryData:
+		return true
+	}
+	return false
+}
+
+type classification struct {
+	severity Severity
+	category Category
+}
+
+// classifications assigns every event type its severity and category.
+// Types missing here are classified as info/admin.
+var classifications = map[string]classification{
+	TypeLoginSuccess:               {SeverityInfo, CategoryAuthn},
+	TypeLoginFailed:                {SeverityWarn, CategoryAuthn},
+	TypeLogout:                     {SeverityInfo, CategoryAuthn},
+	TypePasswordChanged:            {SeverityInfo, CategoryAuthn},
+	TypeCredentialResetRequired:    {SeverityWarn, CategoryAuthn},
+	TypeUserLocked:                 {SeverityWarn, CategoryAuthn},
+	TypeUserUnlocked:               {SeverityInfo, CategoryAuthn},
+	TypeIdentityLinked:             {SeverityWarn, CategoryAuthn},
+	TypeIdentityUnlinked:           {SeverityWarn, CategoryAuthn},
+	TypeRecoveryRequested:          {SeverityWarn, CategoryAuthn},
+	TypeRecoveryCancelled:          {SeverityInfo, CategoryAuthn},
+	TypeRecoveryAttested:           {SeverityCritical, CategoryAuthn},
+	TypeRecoveryCompleted:          {SeverityCritical, CategoryAuthn},
+	TypeSourceBlocked:              {SeverityWarn, CategoryAuthn},
+	TypeSourceUnblocked:            {SeverityInfo, CategoryAuthn},
+	TypeSourceAllowlisted:          {SeverityWarn, CategoryAuthn},
+	TypeSourceAllowlistRemoved:     {SeverityInfo, CategoryAuthn},
+	TypeCredentialStuffingDetected: {SeverityCritical, CategoryAuthn},
+	TypeBruteForceDetected:         {SeverityCritical, CategoryAuthn},
+	TypeSuspiciousLogin:            {SeverityCritical, CategoryAuthn},
+	TypeExcessiveIssuance:          {SeverityWarn, CategoryAuthn},
+	TypeIssuanceFlagReleased:       {SeverityInfo, CategoryAuthn},
+
+	TypeTokenIssued:            {SeverityInfo, CategoryAuthz},
+	TypeTokenRevoked:           {SeverityInfo, CategoryAuthz},
+	TypeRoleAssigned:           {SeverityWarn, CategoryAuthz},
+	TypeRoleRevoked:            {SeverityWarn, CategoryAuthz},
+	TypeRoleMappingCreated:     {SeverityWarn, CategoryAuthz},
+	TypeRoleMappingDeleted:     {SeverityWarn, CategoryAuthz},
+	TypeConsentGranted:         {SeverityInfo, CategoryAuthz},
+	TypeConsentRevoked:         {SeverityInfo, CategoryAuthz},
+	TypeClientTrustChanged:     {SeverityCritical, CategoryAuthz},
+	TypePlatformAdminBootstrap: {SeverityCritical, CategoryAuthz},
+	TypeAdminTokenIssued:       {SeverityWarn, CategoryAuthz},
+	TypeAdminTokenRevoked:      {SeverityInfo, CategoryAuthz},
+	TypeAccessDenied:           {SeverityWarn, CategoryAuthz},
+	TypeDelegationApproved:     {SeverityCritical, CategoryAuthz},
+	TypeDelegationRevoked:      {SeverityWarn, CategoryAuthz},
+
+	TypeClientCreated:      {SeverityInfo, CategoryAdmin},
+	TypeClientUpdated:      {SeverityInfo, CategoryAdmin},
+	TypeClientDeleted:      {SeverityWarn, CategoryAdmin},
+	TypeSecretRotated:      {SeverityWarn, CategoryAdmin},
+	TypeUserCreated:        {SeverityInfo, CategoryAdmin},
+	TypeUserUpdated:        {SeverityInfo, CategoryAdmin},
+	TypeUserDeleted:        {SeverityWarn, CategoryAdmin},
+	TypeTenantCreated:      {SeverityInfo, CategoryAdmin},
+	TypeTenantUpdated:      {SeverityInfo, CategoryAdmin},
+	TypeTenantDeleted:      {SeverityCritical, CategoryAdmin},
+	TypeWebhookCreated:     {SeverityInfo, CategoryAdmin},
+	TypeWebhookUpdated:     {SeverityInfo, CategoryAdmin},
+	TypeWebhookDeleted:     {SeverityInfo, CategoryAdmin},
+	TypeSCIMTargetCreated:  {SeverityInfo, CategoryAdmin},
+	TypeSCIMTargetUpdated:  {SeverityInfo, CategoryAdmin},
+	TypeSCIMTargetDeleted:  {SeverityInfo, CategoryAdmin},
+	TypeFeatureFlagUpdated: {SeverityWarn, CategoryAdmin},
+	TypeIntegrityRepaired:  {SeverityWarn, CategoryAdmin},
+	TypePlatformModeSet:    {SeverityCritical, CategoryAdmin},
+
+	TypeSigningKeyGenerated: {SeverityInfo, CategoryAdmin},
+	TypeSigningKeyActivated: {SeverityWarn, CategoryAdmin},
+	TypeSigningKeyRetired:   {SeverityWarn, CategoryAdmin},
+	TypeSigningKeyPurged:    {SeverityInfo, CategoryAdmin},
+
+	TypeLegalHoldPlaced:         {SeverityWarn, CategoryData},
+	TypeLegalHoldReleased:       {SeverityWarn, CategoryData},
+	TypeConsentReceiptsExported: {SeverityWarn, CategoryData},
+	TypeAuditRead:               {SeverityWarn, CategoryData},
+	TypeAuditReadCrossTenant:    {SeverityCritical, CategoryData},
+	TypeBackupExported:          {SeverityCritical, CategoryData},
+	TypeBackupRestored:          {SeverityCritical, CategoryData},
+}
+
+// Classify returns the severity and category of eventType.
+func Classify(eventType string) (Severity, Category) {
+	if c, ok := classifications[eventType]; ok {
+		return c.severity, c.category
+	}
+	return SeverityInfo, CategoryAdmin
+}
+
+// New returns an event of eventType with its severity and category set.
+//
+// Purpose: Typed construction of audit events.
+// Domain: Audit
+// Audited: No
+// Errors: None
+func New(eventType string) Event {
+	e := Event{Type: eventType}
+	e.classify()
+	return e
+}
+
+// classify fills in Severity and Category from the event type when they are unset.
+func (e *Event) classify() {
+	severity, category := Classify(e.Type)
+	if e.Severity == "" {
+		e.Severity = severity
+	}
+	if e.Category == "" {
+		e.Category = category
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/requestctx"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		eventType    string
+		wantSeverity Severity
+		wantCategory Category
+	}{
+		{TypeLoginSuccess, SeverityInfo, CategoryAuthn},
+		{TypeLoginFailed, SeverityWarn, CategoryAuthn},
+		{TypeCredentialStuffingDetected, SeverityCritical, CategoryAuthn},
+		{TypeRoleAssigned, SeverityWarn, CategoryAuthz},
+		{TypeTenantDeleted, SeverityCritical, CategoryAdmin},
+		{TypeAuditReadCrossTenant, SeverityCritical, CategoryData},
+		{"unknown_type", SeverityInfo, CategoryAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			severity, category := Classify(tt.eventType)
+			if severity != tt.wantSeverity || category != tt.wantCategory {
+				t.Errorf("Classify() = %s/%s, want %s/%s", severity, category, tt.wantSeverity, tt.wantCategory)
+			}
+			e := New(tt.eventType)
+			if e.Type != tt.eventType || e.Severity != tt.wantSeverity || e.Category != tt.wantCategory {
+				t.Errorf("New() = %+v", e)
+			}
+		})
+	}
+}
+
+func TestClassifyKeepsExplicitValues(t *testing.T) {
+	e := Event{Type: TypeLoginSuccess, Severity: SeverityCritical}
+	e.classify()
+	if e.Severity != SeverityCritical || e.Category != CategoryAuthn {
+		t.Errorf("classify() = %s/%s, want the explicit severity kept", e.Severity, e.Category)
+	}
+}
+
+func TestEnrichFromRequest(t *testing.T) {
+	ctx := requestctx.WithClientInfo(requestctx.WithUser(context.Background(), "u1"), "203.0.113.7", "agent/1.0")
+
+	e := Event{Type: TypeLoginSuccess}
+	e.enrich(ctx)
+	if e.ActorID != "u1" || e.IPAddress != "203.0.113.7" || e.UserAgent != "agent/1.0" || e.Timestamp.IsZero() {
+		t.Errorf("enrich() = %+v, want request facts filled in", e)
+	}
+
+	e = Event{Type: TypeLoginSuccess, ActorID: "admin", IPAddress: "198.51.100.1"}
+	e.enrich(ctx)
+	if e.ActorID != "admin" || e.IPAddress != "198.51.100.1" || e.UserAgent != "agent/1.0" {
+		t.Errorf("enrich() = %+v, want explicit values kept", e)
+	}
+}
+
+func TestSeverityOrder(t *testing.T) {
+	if !SeverityCritical.AtLeast(SeverityWarn) || SeverityInfo.AtLeast(SeverityWarn) {
+		t.Error("AtLeast() does not follow info < warn < critical")
+	}
+	if got := SeveritiesFrom(SeverityWarn); !slices.Equal(got, []Severity{SeverityWarn, SeverityCritical}) {
+		t.Errorf("SeveritiesFrom(warn) = %v", got)
+	}
+	if got := SeveritiesFrom("bogus"); got != nil {
+		t.Errorf("SeveritiesFrom(bogus) = %v, want nil", got)
+	}
+	if Severity("bogus").IsValid() || !CategoryData.IsValid() || Category("bogus").IsValid() {
+		t.Error("IsValid() accepted an unknown value")
+	}
+}
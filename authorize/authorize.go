@@ -0,0 +1,346 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package authorize validates OAuth2 and OpenID Connect authorization requests
+// (RFC 6749 Section 4.1.1, OIDC Core Section 3.1.2.1) before the transport asks the
+// user to log in or consent. A rejected request comes back as an *Error that tells
+// the transport whether it may be redirected to the client or must be shown to the
+// user, and carries the error response parameters to send.
+package authorize
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/feature"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Domain errors
+var (
+	ErrClientIDRequired        = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "client_id is required")
+	ErrInvalidRedirectURI      = apperror.New(apperror.CodeInvalidRedirectURI, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "redirect_uri is missing or not registered for this client")
+	ErrResponseTypeRequired    = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "response_type is required")
+	ErrUnsupportedResponseType = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2UnsupportedResponseType, "unsupported response_type")
+	ErrResponseTypeNotAllowed  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2UnauthorizedClient, "response_type is not registered for this client")
+	ErrNonceRequired           = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "nonce is required for this response_type")
+)
+
+// Authorization request parameters (RFC 6749 Section 4.1.1, RFC 7636 Section 4.3)
+const (
+	ParamClientID            = "client_id"
+	ParamResponseType        = "response_type"
+	ParamRedirectURI         = "redirect_uri"
+	ParamScope               = "scope"
+	ParamState               = "state"
+	ParamNonce               = "nonce"
+	ParamCodeChallenge       = "code_challenge"
+	ParamCodeChallengeMethod = "code_challenge_method"
+)
+
+// Response type values (RFC 6749 Section 3.1.1, OAuth 2.0 Multiple Response Type Encoding Practices)
+const (
+	ResponseTypeCode    = "code"
+	ResponseTypeToken   = "token"
+	ResponseTypeIDToken = "id_token"
+)
+
+// Request is an authorization request.
+//
+// Purpose: Everything the authorization endpoint received, after transport-level parsing.
+// Domain: OAuth2
+// Invariants: Values are taken verbatim from the query or the parked request
+// (flow.PendingAuthorization); Validate interprets them.
+type Request struct {
+	TenantID            string
+	ClientID            string
+	ResponseType        string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// RequestFromParams builds the request carried by authorization parameters, such as
+// the Params of a flow.PendingAuthorization.
+func RequestFromParams(tenantID string, params map[string]string) Request {
+	return Request{
+		TenantID:            tenantID,
+		ClientID:            params[ParamClientID],
+		ResponseType:        params[ParamResponseType],
+		RedirectURI:         params[ParamRedirectURI],
+		Scope:               params[ParamScope],
+		State:               params[ParamState],
+		Nonce:               params[ParamNonce],
+		CodeChallenge:       params[ParamCodeChallenge],
+		CodeChallengeMethod: params[ParamCodeChallengeMethod],
+	}
+}
+
+// Authorization is a validated authorization request.
+//
+// Purpose: What the transport needs to continue with login, consent, and code issuance.
+// Domain: OAuth2
+// Invariants: Client is active and belongs to the request's tenant. RedirectURI is
+// registered for it, ResponseType is one it registered, Scope is allowed for it, and
+// the PKCE challenge satisfies its requirements and the tenant's method policy.
+type Authorization struct {
+	Client              *client.Client
+	ResponseType        string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// ErrorResponse is the error response of an authorization request (RFC 6749 Section 4.1.2.1).
+type ErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	State            string `json:"state,omitempty"`
+}
+
+// Error is a rejected authorization request.
+//
+// Purpose: Tells the transport where to send the error and what to send.
+// Domain: OAuth2
+// Invariants: Unwraps to the sentinel the request failed on. RedirectURI is set only
+// once the client and the redirect URI were verified; before that the error must be
+// shown to the user and never redirected (RFC 6749 Section 4.1.2.1). State echoes
+// the request's state.
+type Error struct {
+	Err         error
+	RedirectURI string
+	State       string
+}
+
+// Error returns the wrapped error's message.
+func (e *Error) Error() string { return e.Err.Error() }
+
+// Unwrap returns the error the request failed on.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Redirect reports whether the error may be returned to the client at RedirectURI.
+func (e *Error) Redirect() bool { return e.RedirectURI != "" }
+
+// Response returns the client-safe error response parameters.
+func (e *Error) Response() ErrorResponse {
+	return ErrorResponse{
+		Error:            apperror.OAuth2Of(e.Err),
+		ErrorDescription: apperror.PublicMessage(e.Err),
+		State:            e.State,
+	}
+}
+
+// ClientLookup resolves a tenant's client; client.Service implements it.
+type ClientLookup interface {
+	GetClientByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error)
+}
+
+// Service validates authorization requests.
+//
+// Purpose: The authorization endpoint's request validation, so transports do not hand-roll it.
+// Domain: OAuth2
+// Invariants: Stateless; every decision is made from the request, the client, and the
+// tenant's feature flags at the time of the request.
+type Service struct {
+	clients  ClientLookup
+	features feature.Checker
+	tracer   tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithFeatures consults c for feature.PKCEPlainAllowed and feature.ImplicitFlowAllowed.
+// Without it the built-in defaults apply.
+func WithFeatures(c feature.Checker) Option {
+	return func(s *Service) { s.features = c }
+}
+
+// WithTracer emits spans for authorization requests on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new authorization request validator.
+//
+// Purpose: Constructor for the authorization request validation service.
+// Domain: OAuth2
+// Audited: No
+// Errors: None
+func NewService(clients ClientLookup, opts ...Option) *Service {
+	s := &Service{clients: clients, features: feature.Defaults}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Validate checks an authorization request.
+//
+// Purpose: Full /authorize request validation: client, redirect_uri, response_type,
+// scope, and PKCE.
+// Domain: OAuth2
+// Security: The client and redirect_uri are verified first, and errors before that
+// point are never redirected, so the endpoint cannot be used as an open redirector.
+// redirect_uri must match a registered URI exactly; it may be omitted only by clients
+// with a single registered URI. response_type must be one the client registered;
+// those that return tokens from the endpoint also need the tenant's
+// feature.ImplicitFlowAllowed at request time, and an ID token needs a nonce. Scopes
+// must be allowed for the client, and OIDC scopes need "openid". Clients that require
+// PKCE must send an S256 challenge ("plain" only under feature.PKCEPlainAllowed).
+// Audited: No
+// Errors: *Error wrapping ErrClientIDRequired, client.ErrClientNotFound,
+// ErrInvalidRedirectURI, ErrResponseTypeRequired, ErrUnsupportedResponseType,
+// ErrResponseTypeNotAllowed, ErrNonceRequired, client.ErrDomainInvalidScope,
+// client.ErrPKCERequired, client.ErrUnsupportedChallengeMode,
+// client.ErrInvalidCodeChallenge, or a System error
+func (s *Service) Validate(ctx context.Context, req Request) (*Authorization, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "authorize.Validate", tracing.String(tracing.AttrTenantID, req.TenantID))
+	defer span.End()
+
+	if req.ClientID == "" {
+		return nil, &Error{Err: ErrClientIDRequired}
+	}
+	c, err := s.clients.GetClientByClientID(ctx, req.TenantID, req.ClientID)
+	if err != nil {
+		if !errors.Is(err, client.ErrClientNotFound) {
+			span.RecordError(err)
+		}
+		return nil, &Error{Err: err}
+	}
+	if !c.IsActive {
+		return nil, &Error{Err: client.ErrClientNotFound}
+	}
+
+	redirectURI, err := resolveRedirectURI(c, req.RedirectURI)
+	if err != nil {
+		return nil, &Error{Err: err}
+	}
+	fail := func(err error) (*Authorization, error) {
+		return nil, &Error{Err: err, RedirectURI: redirectURI, State: req.State}
+	}
+
+	responseType, err := s.checkResponseType(ctx, c, req)
+	if err != nil {
+		return fail(err)
+	}
+	scope := strings.Join(strings.Fields(req.Scope), " ")
+	if err := checkScope(c, scope); err != nil {
+		return fail(err)
+	}
+	pkce := client.PKCEPolicy{AllowPlain: s.features.Enabled(ctx, req.TenantID, feature.PKCEPlainAllowed)}
+	if err := pkce.CheckCodeChallenge(c, req.CodeChallenge, req.CodeChallengeMethod); err != nil {
+		return fail(err)
+	}
+
+	return &Authorization{
+		Client:              c,
+		ResponseType:        responseType,
+		RedirectURI:         redirectURI,
+		Scope:               scope,
+		State:               req.State,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	}, nil
+}
+
+// resolveRedirectURI returns the redirect URI to answer c at: redirectURI if it is
+// registered, or c's only registered URI if redirectURI is empty.
+func resolveRedirectURI(c *client.Client, redirectURI string) (string, error) {
+	if redirectURI == "" {
+		if len(c.RedirectURIs) == 1 {
+			return c.RedirectURIs[0], nil
+		}
+		return "", ErrInvalidRedirectURI
+	}
+	if !c.ValidateRedirectURI(redirectURI) {
+		return "", ErrInvalidRedirectURI
+	}
+	return redirectURI, nil
+}
+
+// checkResponseType validates req's response_type against c's registration and the
+// tenant's implicit flow policy, and returns it normalized: the order of its values
+// is not significant, so it is returned as c registered it.
+func (s *Service) checkResponseType(ctx context.Context, c *client.Client, req Request) (string, error) {
+	values := strings.Fields(req.ResponseType)
+	if len(values) == 0 {
+		return "", ErrResponseTypeRequired
+	}
+	for i, v := range values {
+		if (v != ResponseTypeCode && v != ResponseTypeToken && v != ResponseTypeIDToken) || slices.Contains(values[:i], v) {
+			return "", ErrUnsupportedResponseType
+		}
+	}
+
+	var registered string
+	for _, rt := range c.ResponseTypes {
+		if sameValues(strings.Fields(rt), values) {
+			registered = rt
+			break
+		}
+	}
+	if registered == "" {
+		return "", ErrResponseTypeNotAllowed
+	}
+
+	implicit := slices.Contains(values, ResponseTypeToken) || (len(values) == 1 && values[0] == ResponseTypeIDToken)
+	if implicit && !s.features.Enabled(ctx, req.TenantID, feature.ImplicitFlowAllowed) {
+		return "", ErrUnsupportedResponseType
+	}
+	if slices.Contains(values, ResponseTypeIDToken) && req.Nonce == "" {
+		return "", ErrNonceRequired
+	}
+	return registered, nil
+}
+
+// checkScope validates a normalized scope: every value must be allowed for c, and
+// OIDC scopes are only valid together with "openid".
+func checkScope(c *client.Client, scope string) error {
+	if !c.ValidateScope(scope) {
+		return client.ErrDomainInvalidScope
+	}
+	var oidcScopes []string
+	for _, s := range strings.Fields(scope) {
+		if client.OIDCScopes[s] {
+			oidcScopes = append(oidcScopes, s)
+		}
+	}
+	if len(oidcScopes) == 0 {
+		return nil
+	}
+	return client.ValidateOIDCScopes(oidcScopes)
+}
+
+// sameValues reports whether a and b hold the same values in any order.
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
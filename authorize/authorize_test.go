@@ -0,0 +1,146 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authorize
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/feature"
+)
+
+type mockClients map[string]*client.Client
+
+func (m mockClients) GetClientByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error) {
+	c, ok := m[clientID]
+	if !ok || c.TenantID != tenantID {
+		return nil, client.ErrClientNotFound
+	}
+	return c, nil
+}
+
+func challenge() string {
+	sum := sha256.Sum256([]byte("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestValidate(t *testing.T) {
+	clients := mockClients{
+		"web": {
+			ClientID: "web", TenantID: "t1", IsActive: true,
+			RedirectURIs:  []string{"https://app.example.com/cb", "https://app.example.com/alt"},
+			ResponseTypes: []string{"code", "id_token token"},
+			AllowedScopes: []string{"openid", "profile", "api:read"},
+		},
+		"spa": {
+			ClientID: "spa", TenantID: "t1", IsActive: true, TokenEndpointAuthMethod: client.AuthMethodNone,
+			RedirectURIs: []string{"https://spa.example.com/cb"}, ResponseTypes: []string{"code"}, AllowedScopes: []string{"openid"},
+		},
+		"disabled": {ClientID: "disabled", TenantID: "t1", RedirectURIs: []string{"https://app.example.com/cb"}, ResponseTypes: []string{"code"}},
+		"other":    {ClientID: "other", TenantID: "t2", IsActive: true, RedirectURIs: []string{"https://app.example.com/cb"}, ResponseTypes: []string{"code"}},
+	}
+	web := Request{TenantID: "t1", ClientID: "web", ResponseType: "code", RedirectURI: "https://app.example.com/cb", Scope: "openid  api:read", State: "s1"}
+	with := func(f func(*Request)) Request {
+		r := web
+		f(&r)
+		return r
+	}
+
+	tests := []struct {
+		name         string
+		req          Request
+		features     feature.Static
+		wantErr      error
+		wantRedirect bool
+		wantOAuth2   string
+	}{
+		{name: "valid code request", req: web},
+		{name: "missing client_id", req: with(func(r *Request) { r.ClientID = "" }), wantErr: ErrClientIDRequired, wantOAuth2: "invalid_request"},
+		{name: "unknown client", req: with(func(r *Request) { r.ClientID = "nope" }), wantErr: client.ErrClientNotFound, wantOAuth2: "invalid_client"},
+		{name: "client of another tenant", req: with(func(r *Request) { r.ClientID = "other" }), wantErr: client.ErrClientNotFound, wantOAuth2: "invalid_client"},
+		{name: "inactive client", req: with(func(r *Request) { r.ClientID = "disabled" }), wantErr: client.ErrClientNotFound, wantOAuth2: "invalid_client"},
+		{name: "unregistered redirect_uri is not redirected", req: with(func(r *Request) { r.RedirectURI = "https://evil.example.com/cb" }), wantErr: ErrInvalidRedirectURI, wantOAuth2: "invalid_request"},
+		{name: "redirect_uri required with several registered", req: with(func(r *Request) { r.RedirectURI = "" }), wantErr: ErrInvalidRedirectURI, wantOAuth2: "invalid_request"},
+		{name: "missing response_type", req: with(func(r *Request) { r.ResponseType = "" }), wantErr: ErrResponseTypeRequired, wantRedirect: true, wantOAuth2: "invalid_request"},
+		{name: "unknown response_type", req: with(func(r *Request) { r.ResponseType = "code device" }), wantErr: ErrUnsupportedResponseType, wantRedirect: true, wantOAuth2: "unsupported_response_type"},
+		{name: "unregistered response_type", req: with(func(r *Request) { r.ResponseType = "code id_token" }), wantErr: ErrResponseTypeNotAllowed, wantRedirect: true, wantOAuth2: "unauthorized_client"},
+		{name: "implicit response_type needs the tenant flag", req: with(func(r *Request) { r.ResponseType, r.Nonce = "token id_token", "n1" }), wantErr: ErrUnsupportedResponseType, wantRedirect: true, wantOAuth2: "unsupported_response_type"},
+		{name: "implicit response_type with the tenant flag", req: with(func(r *Request) { r.ResponseType, r.Nonce = "token id_token", "n1" }), features: feature.Static{feature.ImplicitFlowAllowed: true}},
+		{name: "id_token needs a nonce", req: with(func(r *Request) { r.ResponseType = "id_token token" }), features: feature.Static{feature.ImplicitFlowAllowed: true}, wantErr: ErrNonceRequired, wantRedirect: true, wantOAuth2: "invalid_request"},
+		{name: "scope not allowed for the client", req: with(func(r *Request) { r.Scope = "openid admin" }), wantErr: client.ErrDomainInvalidScope, wantRedirect: true, wantOAuth2: "invalid_scope"},
+		{name: "OIDC scope without openid", req: with(func(r *Request) { r.Scope = "profile" }), wantErr: client.ErrDomainInvalidScope, wantRedirect: true, wantOAuth2: "invalid_scope"},
+		{name: "public client without PKCE", req: Request{TenantID: "t1", ClientID: "spa", ResponseType: "code", RedirectURI: "https://spa.example.com/cb", Scope: "openid"}, wantErr: client.ErrPKCERequired, wantRedirect: true, wantOAuth2: "invalid_request"},
+		{name: "public client with S256 and a single registered redirect_uri", req: Request{TenantID: "t1", ClientID: "spa", ResponseType: "code", Scope: "openid", CodeChallenge: challenge(), CodeChallengeMethod: client.CodeChallengeMethodS256}},
+		{name: "plain PKCE needs the tenant flag", req: with(func(r *Request) {
+			r.CodeChallenge, r.CodeChallengeMethod = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", client.CodeChallengeMethodPlain
+		}), wantErr: client.ErrUnsupportedChallengeMode, wantRedirect: true, wantOAuth2: "invalid_request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.features != nil {
+				opts = append(opts, WithFeatures(tt.features))
+			}
+			got, err := NewService(clients, opts...).Validate(context.Background(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if got.Client.ClientID != tt.req.ClientID || got.RedirectURI == "" || got.State != tt.req.State {
+					t.Errorf("Validate() = %+v", got)
+				}
+				return
+			}
+
+			var authErr *Error
+			if !errors.As(err, &authErr) {
+				t.Fatalf("Validate() error = %T, want *Error", err)
+			}
+			if authErr.Redirect() != tt.wantRedirect {
+				t.Errorf("Redirect() = %v, want %v", authErr.Redirect(), tt.wantRedirect)
+			}
+			resp := authErr.Response()
+			if resp.Error != tt.wantOAuth2 || resp.ErrorDescription == "" {
+				t.Errorf("Response() = %+v, want error %s", resp, tt.wantOAuth2)
+			}
+			if tt.wantRedirect && (resp.State != tt.req.State || authErr.RedirectURI != tt.req.RedirectURI) {
+				t.Errorf("redirected error = %+v, want state and redirect_uri echoed", authErr)
+			}
+		})
+	}
+}
+
+func TestValidateNormalizes(t *testing.T) {
+	clients := mockClients{"web": {
+		ClientID: "web", TenantID: "t1", IsActive: true,
+		RedirectURIs: []string{"https://app.example.com/cb"}, ResponseTypes: []string{"id_token token"}, AllowedScopes: []string{"*"},
+	}}
+	params := map[string]string{
+		ParamClientID: "web", ParamResponseType: "token id_token", ParamScope: " openid\tprofile ", ParamState: "s1", ParamNonce: "n1",
+	}
+	svc := NewService(clients, WithFeatures(feature.Static{feature.ImplicitFlowAllowed: true}))
+
+	got, err := svc.Validate(context.Background(), RequestFromParams("t1", params))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got.ResponseType != "id_token token" || got.Scope != "openid profile" || got.RedirectURI != "https://app.example.com/cb" || got.Nonce != "n1" {
+		t.Errorf("Validate() = %+v", got)
+	}
+}
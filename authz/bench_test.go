@@ -0,0 +1,113 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+const (
+	// benchTenants and benchUsersPerTenant size the assignment table used by
+	// HasPermission benchmarks.
+	benchTenants        = 100
+	benchUsersPerTenant = 100
+	// benchMemberships is the number of tenants the benchmarked user belongs to.
+	benchMemberships = 20
+)
+
+// indexedAssignmentRepo answers ListForUser from a per-user index, as the
+// rbac_assignments user index does in PostgreSQL.
+type indexedAssignmentRepo struct {
+	role.AssignmentRepository
+	byUser map[string][]*role.Assignment
+}
+
+func (m *indexedAssignmentRepo) ListForUser(ctx context.Context, userID string) ([]*role.Assignment, error) {
+	return m.byUser[userID], nil
+}
+
+// newBenchAuthz builds a service over benchTenants tenants with
+// benchUsersPerTenant members each, plus a user holding a membership in
+// benchMemberships tenants.
+func newBenchAuthz(b *testing.B) *Service {
+	b.Helper()
+	roles := &mockRoleRepo{roles: map[string]*role.Role{
+		"role-owner":  {ID: "role-owner", Name: role.RoleTenantOwner, Scope: role.ScopeTenant, Permissions: role.TenantOwnerPermissions},
+		"role-member": {ID: "role-member", Name: role.RoleTenantMember, Scope: role.ScopeTenant, Permissions: role.TenantMemberPermissions},
+		"role-admin":  {ID: "role-admin", Name: "admin", Scope: role.ScopePlatform, Permissions: role.PlatformAdminPermissions},
+	}}
+	assignments := &indexedAssignmentRepo{byUser: make(map[string][]*role.Assignment)}
+	assign := func(userID, roleID string, scope role.Scope, contextID *string) {
+		assignments.byUser[userID] = append(assignments.byUser[userID], &role.Assignment{
+			UserID: userID, RoleID: roleID, Scope: scope, ScopeContextID: contextID,
+		})
+	}
+	for t := 0; t < benchTenants; t++ {
+		tenantID := fmt.Sprintf("tenant-%d", t)
+		for u := 0; u < benchUsersPerTenant; u++ {
+			assign(fmt.Sprintf("user-%d-%d", t, u), "role-member", role.ScopeTenant, stringPtr(tenantID))
+		}
+	}
+	for t := 0; t < benchMemberships; t++ {
+		assign("user-bench", "role-member", role.ScopeTenant, stringPtr(fmt.Sprintf("tenant-%d", t)))
+	}
+	assign("user-bench", "role-owner", role.ScopeTenant, stringPtr(fmt.Sprintf("tenant-%d", benchMemberships-1)))
+	assign("user-admin", "role-admin", role.ScopePlatform, nil)
+
+	return NewService(&mockProjectRepo{}, roles, assignments)
+}
+
+func BenchmarkHasPermission(b *testing.B) {
+	// Denials log at info level per role; keep the benchmark output readable.
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.Cleanup(func() { slog.SetDefault(prev) })
+
+	svc := newBenchAuthz(b)
+	ctx := context.Background()
+	owned := fmt.Sprintf("tenant-%d", benchMemberships-1)
+
+	cases := []struct {
+		name       string
+		userID     string
+		scope      role.Scope
+		contextID  *string
+		permission string
+		want       bool
+	}{
+		{"tenant_allowed", "user-bench", role.ScopeTenant, &owned, policy.PermTenantManageUsers, true},
+		{"tenant_denied", "user-bench", role.ScopeTenant, stringPtr("tenant-0"), policy.PermTenantManageUsers, false},
+		{"platform_allowed", "user-admin", role.ScopePlatform, nil, policy.PermPlatformManageTenants, true},
+		{"no_assignments", "user-unknown", role.ScopeTenant, &owned, policy.PermTenantViewUsers, false},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				got, err := svc.HasPermission(ctx, tc.userID, tc.scope, tc.contextID, tc.permission)
+				if err != nil || got != tc.want {
+					b.Fatalf("HasPermission() = %v, %v; want %v", got, err, tc.want)
+				}
+			}
+		})
+	}
+}
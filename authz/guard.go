@@ -0,0 +1,188 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// Guard errors. Transports map them with apperror.StatusOf: 401 and 403.
+var (
+	ErrUnauthenticated = apperror.New(apperror.CodeUnauthenticated, apperror.StatusUnauthorized, "", "authentication required")
+	ErrForbidden       = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "permission denied")
+)
+
+// Audit metadata keys of denials
+const (
+	attrPermission = "permission"
+	attrRole       = "role"
+)
+
+// tenantRoleRank orders the built-in tenant roles; a role satisfies a
+// requirement for any role ranked at or below it.
+var tenantRoleRank = map[string]int{
+	role.RoleTenantMember: 1,
+	role.RoleTenantAdmin:  2,
+	role.RoleTenantOwner:  3,
+}
+
+// Guard authorizes the request carried by ctx.
+//
+// Purpose: Transport-agnostic authorization check that HTTP and gRPC layers run
+// before a handler, reading the actor and tenant that middleware put on ctx with
+// requestctx.
+// Domain: Authz
+// Invariants: Returns nil, ErrUnauthenticated, ErrForbidden, or a wrapped system error.
+type Guard func(ctx context.Context) error
+
+// All returns a Guard that passes only if every guard passes, checked in order.
+func All(guards ...Guard) Guard {
+	return func(ctx context.Context) error {
+		for _, g := range guards {
+			if err := g(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// RequirePermission returns a Guard requiring the acting user to hold permission
+// in the request's tenant, or at platform scope when the request has no tenant.
+//
+// Purpose: Permission gate for admin and management endpoints.
+// Domain: Authz
+// Security: Only user actors hold roles; client and system actors are forbidden.
+// Platform roles apply within tenants except for tenant user management, as in HasPermission.
+// Audited: Yes (AccessDenied on refusal)
+// Errors: ErrUnauthenticated, ErrForbidden, System errors
+func (s *Service) RequirePermission(permission string) Guard {
+	return func(ctx context.Context) error {
+		userID, err := guardedUser(ctx)
+		if err != nil {
+			s.deny(ctx, attrPermission, permission, err)
+			return err
+		}
+		scope, scopeContextID := role.ScopePlatform, (*string)(nil)
+		if tenantID := requestctx.TenantID(ctx); tenantID != "" {
+			scope, scopeContextID = role.ScopeTenant, &tenantID
+		}
+		ok, err := s.HasPermission(ctx, userID, scope, scopeContextID, permission)
+		if err != nil {
+			return fmt.Errorf("failed to check permission: %w", err)
+		}
+		if !ok {
+			s.deny(ctx, attrPermission, permission, ErrForbidden)
+			return ErrForbidden
+		}
+		return nil
+	}
+}
+
+// RequireTenantRole returns a Guard requiring the acting user to hold roleName, or a
+// higher built-in tenant role (member < admin < owner), in the request's tenant.
+//
+// Purpose: Role gate for tenant-scoped endpoints.
+// Domain: Authz
+// Security: Platform roles do not satisfy it; tenant membership is never implied by
+// platform authority. A request without a tenant is forbidden.
+// Audited: Yes (AccessDenied on refusal)
+// Errors: ErrUnauthenticated, ErrForbidden, System errors
+func (s *Service) RequireTenantRole(roleName string) Guard {
+	return func(ctx context.Context) error {
+		userID, err := guardedUser(ctx)
+		if err != nil {
+			s.deny(ctx, attrRole, roleName, err)
+			return err
+		}
+		tenantID := requestctx.TenantID(ctx)
+		if tenantID == "" {
+			s.deny(ctx, attrRole, roleName, ErrForbidden)
+			return ErrForbidden
+		}
+		ok, err := s.hasTenantRole(ctx, userID, tenantID, roleName)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.deny(ctx, attrRole, roleName, ErrForbidden)
+			return ErrForbidden
+		}
+		return nil
+	}
+}
+
+// guardedUser returns the acting user, ErrUnauthenticated without an actor, or
+// ErrForbidden for an actor that is not a user.
+func guardedUser(ctx context.Context) (string, error) {
+	actor, ok := requestctx.ActorFrom(ctx)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	if actor.Type != requestctx.ActorUser {
+		return "", ErrForbidden
+	}
+	return actor.ID, nil
+}
+
+// hasTenantRole reports whether userID holds roleName or a higher role in tenantID.
+func (s *Service) hasTenantRole(ctx context.Context, userID, tenantID, roleName string) (bool, error) {
+	assignments, err := s.assignmentRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user assignments: %w", err)
+	}
+	for _, a := range assignments {
+		if a.Scope != role.ScopeTenant || a.ScopeContextID == nil || *a.ScopeContextID != tenantID {
+			continue
+		}
+		r, err := s.roleRepo.GetByID(ctx, a.RoleID)
+		if err != nil {
+			continue
+		}
+		if r.Name == roleName {
+			return true, nil
+		}
+		if want, known := tenantRoleRank[roleName]; known && tenantRoleRank[r.Name] >= want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deny audits a refused request. Missing authentication is not audited: it carries
+// no actor to attribute and is the normal state of an unauthenticated client.
+func (s *Service) deny(ctx context.Context, attr, value string, reason error) {
+	if s.auditLogger == nil || reason == ErrUnauthenticated {
+		return
+	}
+	tenantID := requestctx.TenantID(ctx)
+	resource := audit.ResourceTenant
+	if tenantID == "" {
+		resource = audit.ResourcePlatform
+	}
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeAccessDenied,
+		TenantID: tenantID,
+		Resource: resource,
+		TargetID: tenantID,
+		Metadata: map[string]any{attr: value},
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// ErrHistoryUnavailable is returned by history queries when no HistoryRepository is configured.
+var ErrHistoryUnavailable = apperror.New(apperror.CodeInternal, apperror.StatusInternalServerError, "", "assignment history is not available")
+
+// AssignmentHistory returns role grants and revocations matching filter, newest first.
+//
+// Purpose: Access reviews and investigations of who changed whose roles, and why.
+// Domain: Authz
+// Security: Returns history across tenants when the filter has no scope context;
+// callers must check the reader's own permissions first.
+// Audited: No
+// Errors: ErrHistoryUnavailable, System errors
+func (s *Service) AssignmentHistory(ctx context.Context, filter role.HistoryFilter) ([]*role.AssignmentChange, error) {
+	if s.history == nil {
+		return nil, ErrHistoryUnavailable
+	}
+	return s.history.ListChanges(ctx, filter)
+}
+
+// AssignmentsAt returns the role assignments in force at scope and context at time at.
+//
+// Purpose: Answers "who held which role in tenant X on date Y" after assignments were revoked.
+// Domain: Authz
+// Audited: No
+// Errors: ErrHistoryUnavailable, System errors
+func (s *Service) AssignmentsAt(ctx context.Context, scope role.Scope, scopeContextID *string, at time.Time) ([]*role.Assignment, error) {
+	if s.history == nil {
+		return nil, ErrHistoryUnavailable
+	}
+	return s.history.AssignmentsAt(ctx, scope, scopeContextID, at)
+}
+
+// UsersWithPermissionAt returns the users who held permission at scope and context at time at.
+//
+// Purpose: Answers "who had access to X on date Y" for a single permission.
+// Domain: Authz
+// Security: Applies the same rules as HasPermission: platform assignments count for
+// every scope except the tenant user permissions. Roles are evaluated with their
+// current permission sets; role definition changes are not historized.
+// Audited: No
+// Errors: ErrHistoryUnavailable, System errors
+func (s *Service) UsersWithPermissionAt(ctx context.Context, scope role.Scope, scopeContextID *string, permission string, at time.Time) ([]string, error) {
+	assignments, err := s.AssignmentsAt(ctx, scope, scopeContextID, at)
+	if err != nil {
+		return nil, err
+	}
+	if scope != role.ScopePlatform && permission != policy.PermTenantManageUsers && permission != policy.PermTenantViewUsers {
+		platform, err := s.AssignmentsAt(ctx, role.ScopePlatform, nil, at)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, platform...)
+	}
+
+	roles := make(map[string]*role.Role)
+	seen := make(map[string]bool)
+	var userIDs []string
+	for _, a := range assignments {
+		if seen[a.UserID] {
+			continue
+		}
+		r, ok := roles[a.RoleID]
+		if !ok {
+			// Roles deleted since then no longer grant anything.
+			r, _ = s.roleRepo.GetByID(ctx, a.RoleID)
+			roles[a.RoleID] = r
+		}
+		if r != nil && r.HasPermission(permission) {
+			seen[a.UserID] = true
+			userIDs = append(userIDs, a.UserID)
+		}
+	}
+	return userIDs, nil
+}
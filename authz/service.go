@@ -18,10 +18,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/metrics"
 	"github.com/opentrusty/opentrusty-core/policy"
 	"github.com/opentrusty/opentrusty-core/project"
 	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
 )
 
 // UserRoleAssignment represents a role assigned to a user with scope.
@@ -59,6 +64,34 @@ type Service struct {
 	projectRepo    project.ProjectRepository
 	roleRepo       role.RoleRepository
 	assignmentRepo role.AssignmentRepository
+	metrics        *metrics.Metrics
+	tracer         tracing.Tracer
+	auditLogger    audit.Logger
+
+	history role.HistoryRepository
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithMetrics records permission check outcomes and latency on m.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *Service) { s.metrics = m }
+}
+
+// WithTracer emits spans for permission checks on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithAuditLogger records requests refused by guards on l.
+func WithAuditLogger(l audit.Logger) Option {
+	return func(s *Service) { s.auditLogger = l }
+}
+
+// WithHistory enables assignment history queries backed by repo.
+func WithHistory(repo role.HistoryRepository) Option {
+	return func(s *Service) { s.history = repo }
 }
 
 // NewService creates a new authorization service.
@@ -71,12 +104,17 @@ func NewService(
 	projectRepo project.ProjectRepository,
 	roleRepo role.RoleRepository,
 	assignmentRepo role.AssignmentRepository,
+	opts ...Option,
 ) *Service {
-	return &Service{
+	s := &Service{
 		projectRepo:    projectRepo,
 		roleRepo:       roleRepo,
 		assignmentRepo: assignmentRepo,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // GetUserRoles retrieves all unique role names for a user across all scopes.
@@ -150,6 +188,19 @@ func (s *Service) BuildUserInfoClaims(ctx context.Context, userID string) (*User
 		return nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
 
+	projects, err := s.projectInfos(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserInfoClaims{
+		Roles:    roles,
+		Projects: projects,
+	}, nil
+}
+
+// projectInfos returns the projects a user has access to in their external form.
+func (s *Service) projectInfos(ctx context.Context, userID string) ([]*ProjectInfo, error) {
 	projects, err := s.GetUserProjects(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user projects: %w", err)
@@ -163,10 +214,43 @@ func (s *Service) BuildUserInfoClaims(ctx context.Context, userID string) (*User
 			Description: p.Description,
 		})
 	}
+	return projectInfos, nil
+}
+
+// BuildTenantUserInfoClaims builds the authorization claims of a user for a client of tenantID.
+//
+// Purpose: Role and project claims for the UserInfo response and tokens of one tenant.
+// Domain: Authz
+// Security: Roles are limited to the user's tenant-scoped assignments in tenantID, so a
+// client never learns the user's platform roles or roles in other tenants.
+// Audited: No
+// Errors: System errors
+func (s *Service) BuildTenantUserInfoClaims(ctx context.Context, tenantID, userID string) (*UserInfoClaims, error) {
+	assignments, err := s.assignmentRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user assignments: %w", err)
+	}
+
+	roles := []string{}
+	for _, a := range assignments {
+		if a.Scope != role.ScopeTenant || a.ScopeContextID == nil || *a.ScopeContextID != tenantID {
+			continue
+		}
+		r, err := s.roleRepo.GetByID(ctx, a.RoleID)
+		if err != nil || slices.Contains(roles, r.Name) {
+			continue
+		}
+		roles = append(roles, r.Name)
+	}
+
+	projects, err := s.projectInfos(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
 	return &UserInfoClaims{
 		Roles:    roles,
-		Projects: projectInfos,
+		Projects: projects,
 	}, nil
 }
 
@@ -178,6 +262,32 @@ func (s *Service) BuildUserInfoClaims(ctx context.Context, userID string) (*User
 // Audited: No
 // Errors: System errors
 func (s *Service) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "authz.HasPermission",
+		tracing.String(tracing.AttrUserID, userID),
+	)
+	defer span.End()
+
+	start := time.Now()
+	allowed, err := s.hasPermission(ctx, userID, scope, scopeContextID, permission)
+
+	result := metrics.PermissionDenied
+	switch {
+	case err != nil:
+		result = metrics.PermissionError
+	case allowed:
+		result = metrics.PermissionAllowed
+	}
+	s.metrics.PermissionCheck(result, time.Since(start))
+	span.SetAttributes(tracing.String(tracing.AttrResult, result))
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return allowed, err
+}
+
+// hasPermission implements HasPermission.
+func (s *Service) hasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
 	assignments, err := s.assignmentRepo.ListForUser(ctx, userID)
 	if err != nil {
 		slog.ErrorContext(ctx, "HasPermission: failed to get user assignments", "error", err)
@@ -16,10 +16,17 @@ package authz
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/policy"
 	"github.com/opentrusty/opentrusty-core/project"
+	"github.com/opentrusty/opentrusty-core/requestctx"
 	"github.com/opentrusty/opentrusty-core/role"
 )
 
@@ -187,3 +194,192 @@ func TestHasPermission(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestBuildTenantUserInfoClaims(t *testing.T) {
+	roleRepo := &mockRoleRepo{roles: map[string]*role.Role{
+		"r-platform": {ID: "r-platform", Name: role.RolePlatformAdmin, Scope: role.ScopePlatform},
+		"r-admin":    {ID: "r-admin", Name: role.RoleTenantAdmin, Scope: role.ScopeTenant},
+		"r-member":   {ID: "r-member", Name: role.RoleTenantMember, Scope: role.ScopeTenant},
+	}}
+	assignmentRepo := &mockAssignmentRepo{assignments: []*role.Assignment{
+		{UserID: "u1", RoleID: "r-platform", Scope: role.ScopePlatform},
+		{UserID: "u1", RoleID: "r-admin", Scope: role.ScopeTenant, ScopeContextID: stringPtr("t1")},
+		{UserID: "u1", RoleID: "r-member", Scope: role.ScopeTenant, ScopeContextID: stringPtr("t2")},
+	}}
+	svc := NewService(&mockProjectRepo{}, roleRepo, assignmentRepo)
+
+	claims, err := svc.BuildTenantUserInfoClaims(context.Background(), "t1", "u1")
+	if err != nil {
+		t.Fatalf("BuildTenantUserInfoClaims() error = %v", err)
+	}
+	if !slices.Equal(claims.Roles, []string{role.RoleTenantAdmin}) {
+		t.Errorf("roles = %v, want only the tenant_admin role in t1", claims.Roles)
+	}
+	if len(claims.Projects) != 1 || claims.Projects[0].ID != "p1" {
+		t.Errorf("projects = %v, want [p1]", claims.Projects)
+	}
+}
+
+// mockHistoryRepo replays changes in memory.
+type mockHistoryRepo struct {
+	changes []*role.AssignmentChange
+}
+
+func (m *mockHistoryRepo) ListChanges(ctx context.Context, filter role.HistoryFilter) ([]*role.AssignmentChange, error) {
+	var res []*role.AssignmentChange
+	for _, c := range m.changes {
+		if filter.UserID == "" || c.UserID == filter.UserID {
+			res = append(res, c)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockHistoryRepo) AssignmentsAt(ctx context.Context, scope role.Scope, scopeContextID *string, at time.Time) ([]*role.Assignment, error) {
+	held := make(map[string]*role.Assignment)
+	for _, c := range m.changes {
+		if c.Scope != scope || c.OccurredAt.After(at) {
+			continue
+		}
+		if (c.ScopeContextID == nil) != (scopeContextID == nil) || (c.ScopeContextID != nil && *c.ScopeContextID != *scopeContextID) {
+			continue
+		}
+		key := c.UserID + "|" + c.RoleID
+		if c.Action == role.ChangeGrant {
+			held[key] = &role.Assignment{UserID: c.UserID, RoleID: c.RoleID, Scope: c.Scope, ScopeContextID: c.ScopeContextID, GrantedAt: c.OccurredAt}
+		} else {
+			delete(held, key)
+		}
+	}
+	var res []*role.Assignment
+	for _, a := range held {
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+func TestUsersWithPermissionAt(t *testing.T) {
+	ctx := context.Background()
+	t1 := "t1"
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	roles := &mockRoleRepo{roles: map[string]*role.Role{
+		"role-editor":   {ID: "role-editor", Scope: role.ScopeTenant, Permissions: []string{policy.PermTenantManageClients}},
+		"role-viewer":   {ID: "role-viewer", Scope: role.ScopeTenant, Permissions: []string{policy.PermTenantView}},
+		"role-platform": {ID: "role-platform", Scope: role.ScopePlatform, Permissions: []string{"*"}},
+	}}
+	history := &mockHistoryRepo{changes: []*role.AssignmentChange{
+		{Action: role.ChangeGrant, UserID: "alice", RoleID: "role-editor", Scope: role.ScopeTenant, ScopeContextID: &t1, OccurredAt: day(1)},
+		{Action: role.ChangeGrant, UserID: "bob", RoleID: "role-viewer", Scope: role.ScopeTenant, ScopeContextID: &t1, OccurredAt: day(1)},
+		{Action: role.ChangeGrant, UserID: "carol", RoleID: "role-editor", Scope: role.ScopeTenant, ScopeContextID: &t1, OccurredAt: day(5)},
+		{Action: role.ChangeRevoke, UserID: "alice", RoleID: "role-editor", Scope: role.ScopeTenant, ScopeContextID: &t1, OccurredAt: day(10)},
+		{Action: role.ChangeGrant, UserID: "root", RoleID: "role-platform", Scope: role.ScopePlatform, OccurredAt: day(1)},
+	}}
+	svc := NewService(&mockProjectRepo{}, roles, &mockAssignmentRepo{}, WithHistory(history))
+
+	tests := []struct {
+		name       string
+		permission string
+		at         time.Time
+		want       []string
+	}{
+		{"before carol", policy.PermTenantManageClients, day(3), []string{"alice", "root"}},
+		{"both editors", policy.PermTenantManageClients, day(7), []string{"alice", "carol", "root"}},
+		{"after revoke", policy.PermTenantManageClients, day(12), []string{"carol", "root"}},
+		{"platform excluded from tenant users", policy.PermTenantManageUsers, day(12), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.UsersWithPermissionAt(ctx, role.ScopeTenant, &t1, tt.permission, tt.at)
+			if err != nil {
+				t.Fatalf("UsersWithPermissionAt() error = %v", err)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("UsersWithPermissionAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewService(&mockProjectRepo{}, roles, &mockAssignmentRepo{}).AssignmentsAt(ctx, role.ScopeTenant, &t1, day(1)); err != ErrHistoryUnavailable {
+		t.Errorf("AssignmentsAt() without history error = %v, want %v", err, ErrHistoryUnavailable)
+	}
+}
+
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Log(_ context.Context, e audit.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestGuards(t *testing.T) {
+	roleRepo := &mockRoleRepo{roles: map[string]*role.Role{
+		"r-admin":  {ID: "r-admin", Name: "admin", Scope: role.ScopePlatform, Permissions: role.PlatformAdminPermissions},
+		"r-owner":  {ID: "r-owner", Name: role.RoleTenantOwner, Scope: role.ScopeTenant, Permissions: []string{"*"}},
+		"r-member": {ID: "r-member", Name: role.RoleTenantMember, Scope: role.ScopeTenant, Permissions: []string{"tenant:view"}},
+		"r-editor": {ID: "r-editor", Name: "editor", Scope: role.ScopeTenant, Permissions: []string{"edit:stuff"}},
+	}}
+	assignmentRepo := &mockAssignmentRepo{assignments: []*role.Assignment{
+		{UserID: "admin", RoleID: "r-admin", Scope: role.ScopePlatform},
+		{UserID: "owner", RoleID: "r-owner", Scope: role.ScopeTenant, ScopeContextID: stringPtr("t1")},
+		{UserID: "member", RoleID: "r-member", Scope: role.ScopeTenant, ScopeContextID: stringPtr("t1")},
+		{UserID: "editor", RoleID: "r-editor", Scope: role.ScopeTenant, ScopeContextID: stringPtr("t1")},
+	}}
+	logger := &recordingAuditLogger{}
+	svc := NewService(&mockProjectRepo{}, roleRepo, assignmentRepo, WithAuditLogger(logger))
+
+	user := func(userID, tenantID string) context.Context {
+		ctx := requestctx.WithUser(context.Background(), userID)
+		if tenantID != "" {
+			ctx = requestctx.WithTenant(ctx, tenantID)
+		}
+		return ctx
+	}
+
+	tests := []struct {
+		name    string
+		guard   Guard
+		ctx     context.Context
+		wantErr error
+		audited bool
+	}{
+		{"no actor", svc.RequirePermission("platform:manage_tenants"), context.Background(), ErrUnauthenticated, false},
+		{"client actor", svc.RequirePermission("platform:manage_tenants"), requestctx.WithClient(context.Background(), "c1"), ErrForbidden, true},
+		{"platform permission", svc.RequirePermission("platform:manage_tenants"), user("admin", ""), nil, false},
+		{"missing platform permission", svc.RequirePermission("platform:manage_tenants"), user("owner", ""), ErrForbidden, true},
+		{"tenant permission", svc.RequirePermission("edit:stuff"), user("editor", "t1"), nil, false},
+		{"tenant permission in other tenant", svc.RequirePermission("edit:stuff"), user("editor", "t2"), ErrForbidden, true},
+		{"exact tenant role", svc.RequireTenantRole(role.RoleTenantMember), user("member", "t1"), nil, false},
+		{"higher tenant role", svc.RequireTenantRole(role.RoleTenantAdmin), user("owner", "t1"), nil, false},
+		{"lower tenant role", svc.RequireTenantRole(role.RoleTenantAdmin), user("member", "t1"), ErrForbidden, true},
+		{"custom role exact", svc.RequireTenantRole("editor"), user("editor", "t1"), nil, false},
+		{"custom role not implied", svc.RequireTenantRole("editor"), user("owner", "t1"), ErrForbidden, true},
+		{"platform admin holds no tenant role", svc.RequireTenantRole(role.RoleTenantMember), user("admin", "t1"), ErrForbidden, true},
+		{"tenant role without tenant", svc.RequireTenantRole(role.RoleTenantMember), user("member", ""), ErrForbidden, true},
+		{"all", All(svc.RequireTenantRole(role.RoleTenantMember), svc.RequirePermission("edit:stuff")), user("member", "t1"), ErrForbidden, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.events = nil
+			err := tt.guard(tt.ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("guard() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(logger.events) == 1; got != tt.audited {
+				t.Fatalf("audited = %v (%d events), want %v", got, len(logger.events), tt.audited)
+			}
+			if tt.audited && logger.events[0].Type != audit.TypeAccessDenied {
+				t.Errorf("audit type = %q, want %q", logger.events[0].Type, audit.TypeAccessDenied)
+			}
+		})
+	}
+
+	if status := apperror.StatusOf(ErrUnauthenticated); status != apperror.StatusUnauthorized {
+		t.Errorf("StatusOf(ErrUnauthenticated) = %d", status)
+	}
+	if status := apperror.StatusOf(ErrForbidden); status != apperror.StatusForbidden {
+		t.Errorf("StatusOf(ErrForbidden) = %d", status)
+	}
+}
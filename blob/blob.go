@@ -0,0 +1,75 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package blob stores user avatars and client logos outside the database.
+// Core ships a filesystem Store; object stores such as S3 are adapted by the
+// host against the same interface, as it does for tracing and webhook
+// delivery. Images validates uploads and derives keys and URLs, so the same
+// owner always maps to the same object.
+package blob
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrUnsupportedType = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "image must be PNG, JPEG, GIF, or WebP")
+	ErrTypeMismatch    = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "image content does not match its declared type")
+	ErrTooLarge        = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "image exceeds the maximum upload size")
+	ErrEmpty           = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "image is empty")
+)
+
+// Store persists opaque objects under slash-separated keys.
+//
+// Purpose: Storage backend abstraction for uploaded images (filesystem, S3, ...).
+// Domain: Platform
+// Invariants: Put replaces any object at key. Delete of a missing key succeeds.
+// URL is a pure function of key.
+type Store interface {
+	// Put writes data under key
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Delete removes the object under key
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL the object under key is served from
+	URL(key string) string
+}
+
+// Image content types accepted for upload. SVG is excluded because it can
+// carry script.
+const (
+	TypePNG  = "image/png"
+	TypeJPEG = "image/jpeg"
+	TypeGIF  = "image/gif"
+	TypeWebP = "image/webp"
+)
+
+// DetectImageType returns the content type of data from its signature, or
+// "" if data is not an accepted image format.
+func DetectImageType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return TypePNG
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return TypeJPEG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return TypeGIF
+	case len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return TypeWebP
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+var pngData = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestDetectImageType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"png", pngData, TypePNG},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), TypeJPEG},
+		{"gif", []byte("GIF89a\x01\x00"), TypeGIF},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), TypeWebP},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script/></svg>`), ""},
+		{"short riff", []byte("RIFF"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectImageType(tt.data); got != tt.want {
+				t.Errorf("DetectImageType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImages(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, "https://cdn.example.com/media/")
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	images := NewImages(store, WithMaxImageSize(64))
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		wantErr     error
+	}{
+		{"valid", TypePNG, pngData, nil},
+		{"declared type mismatch", TypeJPEG, pngData, ErrTypeMismatch},
+		{"svg", "image/svg+xml", []byte("<svg/>"), ErrUnsupportedType},
+		{"too large", TypePNG, append(append([]byte{}, pngData...), make([]byte, 64)...), ErrTooLarge},
+		{"empty", TypePNG, nil, ErrEmpty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := images.PutUserAvatar(ctx, "u1", tt.contentType, tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("PutUserAvatar() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	url, err := images.PutUserAvatar(ctx, "u1", TypePNG, pngData)
+	if err != nil {
+		t.Fatalf("PutUserAvatar() error = %v", err)
+	}
+	again, _ := images.PutUserAvatar(ctx, "u1", TypePNG, pngData)
+	if url != again || !strings.HasPrefix(url, "https://cdn.example.com/media/avatars/users/u1?v=") {
+		t.Errorf("URL = %q, again %q; want a deterministic URL under the base", url, again)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "avatars", "users", "u1")); err != nil {
+		t.Fatalf("avatar not written: %v", err)
+	}
+	logo, err := images.PutClientLogo(ctx, "t1", "c1", TypePNG, pngData)
+	if err != nil {
+		t.Fatalf("PutClientLogo() error = %v", err)
+	}
+	if !images.IsClientLogo("t1", "c1", logo) || images.IsClientLogo("t1", "c2", logo) || images.IsClientLogo("t1", "c1", "https://evil.example.com/logo.png") {
+		t.Errorf("IsClientLogo(%q) does not match only the stored logo", logo)
+	}
+
+	// Removing the owner removes the image.
+	if err := images.HandleEvent(ctx, events.UserDeleted{UserID: "u1"}); err != nil {
+		t.Fatalf("HandleEvent(UserDeleted) error = %v", err)
+	}
+	if err := images.HandleEvent(ctx, events.ClientDeleted{Meta: events.NewMeta("t1", ""), ClientID: "c1"}); err != nil {
+		t.Fatalf("HandleEvent(ClientDeleted) error = %v", err)
+	}
+	for _, p := range []string{"avatars/users/u1", "logos/t1/c1"} {
+		if _, err := os.Stat(filepath.Join(dir, p)); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after owner removal", p)
+		}
+	}
+	if err := images.HandleEvent(ctx, events.UserDeleted{UserID: "u1"}); err != nil {
+		t.Errorf("deleting a missing image error = %v", err)
+	}
+}
+
+func TestFileStoreRejectsTraversal(t *testing.T) {
+	store, err := NewFileStore(t.TempDir(), "https://cdn.example.com")
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	for _, key := range []string{"../escape", "a/../../b", "/abs", "", "a//b"} {
+		if err := store.Put(context.Background(), key, TypePNG, pngData); err == nil {
+			t.Errorf("Put(%q) succeeded, want error", key)
+		}
+	}
+}
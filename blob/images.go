@@ -0,0 +1,120 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+// DefaultMaxImageSize bounds an uploaded avatar or logo
+const DefaultMaxImageSize = 1 << 20
+
+// Images validates and stores user avatars and client logos.
+//
+// Purpose: The single entry point for image uploads.
+// Domain: Platform
+// Invariants: Only accepted raster formats within the size limit are stored.
+// Each owner has exactly one key; URLs carry a content digest so a replaced
+// image is never served from a stale cache.
+type Images struct {
+	store   Store
+	maxSize int
+}
+
+// ImagesOption configures optional Images settings.
+type ImagesOption func(*Images)
+
+// WithMaxImageSize replaces DefaultMaxImageSize.
+func WithMaxImageSize(n int) ImagesOption {
+	return func(i *Images) { i.maxSize = n }
+}
+
+// NewImages creates an image service on store.
+//
+// Purpose: Constructor for the image upload service.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func NewImages(store Store, opts ...ImagesOption) *Images {
+	i := &Images{store: store, maxSize: DefaultMaxImageSize}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// UserAvatarKey returns the key of a user's avatar.
+func UserAvatarKey(userID string) string {
+	return "avatars/users/" + userID
+}
+
+// ClientLogoKey returns the key of a client's logo.
+func ClientLogoKey(tenantID, clientID string) string {
+	return "logos/" + tenantID + "/" + clientID
+}
+
+// PutUserAvatar stores a user's avatar and returns its URL.
+func (i *Images) PutUserAvatar(ctx context.Context, userID, contentType string, data []byte) (string, error) {
+	return i.put(ctx, UserAvatarKey(userID), contentType, data)
+}
+
+// PutClientLogo stores a client's logo and returns its URL.
+func (i *Images) PutClientLogo(ctx context.Context, tenantID, clientID, contentType string, data []byte) (string, error) {
+	return i.put(ctx, ClientLogoKey(tenantID, clientID), contentType, data)
+}
+
+// put validates data and writes it under key.
+func (i *Images) put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", ErrEmpty
+	}
+	if len(data) > i.maxSize {
+		return "", ErrTooLarge
+	}
+	detected := DetectImageType(data)
+	if detected == "" {
+		return "", ErrUnsupportedType
+	}
+	if contentType != detected {
+		return "", ErrTypeMismatch
+	}
+	if err := i.store.Put(ctx, key, detected, data); err != nil {
+		return "", fmt.Errorf("failed to store image: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return i.store.URL(key) + "?v=" + hex.EncodeToString(sum[:8]), nil
+}
+
+// IsClientLogo reports whether uri is a logo this service stored for the client.
+func (i *Images) IsClientLogo(tenantID, clientID, uri string) bool {
+	return strings.HasPrefix(uri, i.store.URL(ClientLogoKey(tenantID, clientID))+"?v=")
+}
+
+// HandleEvent deletes a user's avatar or a client's logo when the owner is removed.
+func (i *Images) HandleEvent(ctx context.Context, e events.Event) error {
+	switch ev := e.(type) {
+	case events.UserDeleted:
+		return i.store.Delete(ctx, UserAvatarKey(ev.UserID))
+	case events.ClientDeleted:
+		return i.store.Delete(ctx, ClientLogoKey(ev.TenantID, ev.ClientID))
+	}
+	return nil
+}
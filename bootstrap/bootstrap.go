@@ -0,0 +1,82 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bootstrap turns an empty installation into a usable one. It issues a
+// one-time setup token and, on redemption, creates the first platform admin in
+// a single transaction, after which it permanently disables itself.
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// Domain errors
+var (
+	ErrAlreadyBootstrapped = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "installation is already bootstrapped")
+	ErrNoToken             = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, "", "no bootstrap token has been issued")
+	ErrInvalidToken        = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, "", "invalid bootstrap token")
+	ErrTokenExpired        = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, "", "bootstrap token expired")
+)
+
+// State is the persisted bootstrap progress.
+//
+// Purpose: Singleton record of the setup token and completion.
+// Domain: Platform
+// Invariants: Once CompletedAt is set it never changes and no token is stored.
+type State struct {
+	TokenHash      string
+	TokenExpiresAt *time.Time
+	CompletedAt    *time.Time
+	AdminUserID    string
+}
+
+// Completed reports whether bootstrap has finished.
+func (s *State) Completed() bool {
+	return s.CompletedAt != nil
+}
+
+// Admin is everything persisted atomically when bootstrap completes.
+type Admin struct {
+	User         *user.User
+	PasswordHash string
+	Assignment   *policy.Assignment
+}
+
+// AdminRequest is the operator input for creating the first platform admin.
+type AdminRequest struct {
+	Email    string
+	Password string
+	Profile  user.Profile
+}
+
+// Repository defines persistence for bootstrap state.
+//
+// Purpose: Storage abstraction for the one-time setup workflow.
+// Domain: Platform
+type Repository interface {
+	// GetState returns the current state (zero State if none was recorded)
+	GetState(ctx context.Context) (*State, error)
+	// HasPlatformAdmin reports whether any platform admin assignment exists
+	HasPlatformAdmin(ctx context.Context) (bool, error)
+	// SaveToken stores a new token hash; ErrAlreadyBootstrapped if completed
+	SaveToken(ctx context.Context, tokenHash string, expiresAt time.Time) error
+	// Complete consumes tokenHash and persists admin in one transaction.
+	// ErrAlreadyBootstrapped if completed, ErrInvalidToken if tokenHash is not current.
+	Complete(ctx context.Context, tokenHash string, admin Admin, completedAt time.Time) error
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// DefaultTokenTTL is how long an issued bootstrap token remains valid.
+const DefaultTokenTTL = time.Hour
+
+// Service runs the first-admin bootstrap workflow.
+//
+// Purpose: Safe, one-time creation of the initial platform admin.
+// Domain: Platform
+// Invariants: At most one successful Complete per installation.
+type Service struct {
+	repo        Repository
+	users       *user.Service
+	auditLogger audit.Logger
+	tokenTTL    time.Duration
+}
+
+// NewService creates a new bootstrap service.
+//
+// Purpose: Constructor for the bootstrap workflow.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func NewService(repo Repository, users *user.Service, auditLogger audit.Logger, tokenTTL time.Duration) *Service {
+	if tokenTTL <= 0 {
+		tokenTTL = DefaultTokenTTL
+	}
+	return &Service{
+		repo:        repo,
+		users:       users,
+		auditLogger: auditLogger,
+		tokenTTL:    tokenTTL,
+	}
+}
+
+// Required reports whether the installation still needs bootstrapping.
+//
+// Purpose: Lets hosts decide whether to expose the setup flow at all.
+// Domain: Platform
+// Audited: No
+// Errors: System errors
+func (s *Service) Required(ctx context.Context) (bool, error) {
+	state, err := s.repo.GetState(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to load bootstrap state: %w", err)
+	}
+	if state.Completed() {
+		return false, nil
+	}
+
+	hasAdmin, err := s.repo.HasPlatformAdmin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check platform admins: %w", err)
+	}
+	return !hasAdmin, nil
+}
+
+// IssueToken generates a new one-time bootstrap token, replacing any previous one.
+//
+// Purpose: Proves the operator has access to the host (logs or console) before setup.
+// Domain: Platform
+// Security: Only the SHA-256 of the token is stored. The plaintext is returned once.
+// Audited: No
+// Errors: ErrAlreadyBootstrapped, System errors
+func (s *Service) IssueToken(ctx context.Context) (string, error) {
+	required, err := s.Required(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !required {
+		return "", ErrAlreadyBootstrapped
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate bootstrap token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := s.repo.SaveToken(ctx, hashToken(token), time.Now().Add(s.tokenTTL)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Complete redeems the bootstrap token and creates the first platform admin.
+//
+// Purpose: Atomic transition from empty installation to administered platform.
+// Domain: Platform
+// Security: Token compared in constant time and consumed in the same transaction
+// that creates the admin, so concurrent redemptions cannot both succeed.
+// Audited: Yes (PlatformAdminBootstrap)
+// Errors: ErrAlreadyBootstrapped, ErrNoToken, ErrInvalidToken, ErrTokenExpired,
+// user.ErrInvalidEmail, user.ErrWeakPassword, System errors
+func (s *Service) Complete(ctx context.Context, token string, req AdminRequest) (*user.User, error) {
+	state, err := s.repo.GetState(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bootstrap state: %w", err)
+	}
+	if state.Completed() {
+		return nil, ErrAlreadyBootstrapped
+	}
+	if state.TokenHash == "" {
+		return nil, ErrNoToken
+	}
+	tokenHash := hashToken(token)
+	if !crypto.ConstantTimeEqualString(tokenHash, state.TokenHash) {
+		return nil, ErrInvalidToken
+	}
+	now := time.Now()
+	if state.TokenExpiresAt != nil && now.After(*state.TokenExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+
+	u, err := s.users.NewIdentity(req.Email, req.Profile)
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := s.users.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := Admin{
+		User:         u,
+		PasswordHash: passwordHash,
+		Assignment: &policy.Assignment{
+			ID:        id.NewUUIDv7(),
+			UserID:    u.ID,
+			RoleID:    role.RoleIDPlatformAdmin,
+			Scope:     policy.ScopePlatform,
+			GrantedAt: now,
+		},
+	}
+	if err := s.repo.Complete(ctx, tokenHash, admin, now); err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypePlatformAdminBootstrap,
+		ActorID:  audit.ActorSystemBootstrap,
+		Resource: audit.ResourcePlatform,
+		TargetID: u.ID,
+		Metadata: map[string]any{audit.AttrRoleID: role.RoleIDPlatformAdmin},
+	})
+	return u, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
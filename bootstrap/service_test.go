@@ -0,0 +1,151 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+type mockRepo struct {
+	state    State
+	hasAdmin bool
+	admins   []Admin
+}
+
+func (m *mockRepo) GetState(ctx context.Context) (*State, error) {
+	s := m.state
+	return &s, nil
+}
+
+func (m *mockRepo) HasPlatformAdmin(ctx context.Context) (bool, error) {
+	return m.hasAdmin, nil
+}
+
+func (m *mockRepo) SaveToken(ctx context.Context, tokenHash string, expiresAt time.Time) error {
+	if m.state.Completed() {
+		return ErrAlreadyBootstrapped
+	}
+	m.state.TokenHash = tokenHash
+	m.state.TokenExpiresAt = &expiresAt
+	return nil
+}
+
+func (m *mockRepo) Complete(ctx context.Context, tokenHash string, admin Admin, completedAt time.Time) error {
+	if m.state.Completed() {
+		return ErrAlreadyBootstrapped
+	}
+	if m.state.TokenHash != tokenHash {
+		return ErrInvalidToken
+	}
+	m.admins = append(m.admins, admin)
+	m.state = State{CompletedAt: &completedAt, AdminUserID: admin.User.ID}
+	m.hasAdmin = true
+	return nil
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+func newTestService(repo *mockRepo, logger audit.Logger, ttl time.Duration) *Service {
+	hasher := user.NewPasswordHasher(1024, 1, 1, 16, 32)
+	users := user.NewService(nil, hasher, logger, 5, time.Minute, "test-secret")
+	return NewService(repo, users, logger, ttl)
+}
+
+func TestBootstrapFlow(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{}
+	logger := &mockAuditLogger{}
+	svc := newTestService(repo, logger, time.Hour)
+
+	if _, err := svc.Complete(ctx, "anything", AdminRequest{}); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("Complete() before IssueToken error = %v, want ErrNoToken", err)
+	}
+
+	token, err := svc.IssueToken(ctx)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if repo.state.TokenHash == token {
+		t.Fatal("token stored in plaintext")
+	}
+
+	req := AdminRequest{Email: "admin@example.com", Password: "correct-horse-battery"}
+	if _, err := svc.Complete(ctx, "wrong-token", req); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Complete(wrong token) error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := svc.Complete(ctx, token, AdminRequest{Email: "admin@example.com", Password: "short"}); !errors.Is(err, user.ErrWeakPassword) {
+		t.Errorf("Complete(weak password) error = %v, want ErrWeakPassword", err)
+	}
+
+	u, err := svc.Complete(ctx, token, req)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if len(repo.admins) != 1 || repo.admins[0].Assignment.UserID != u.ID || repo.admins[0].PasswordHash == "" {
+		t.Errorf("persisted admins = %+v", repo.admins)
+	}
+	if len(logger.events) != 1 || logger.events[0].Type != audit.TypePlatformAdminBootstrap {
+		t.Errorf("audit events = %+v", logger.events)
+	}
+
+	// Disabled afterwards.
+	if required, _ := svc.Required(ctx); required {
+		t.Error("Required() = true after completion")
+	}
+	if _, err := svc.IssueToken(ctx); !errors.Is(err, ErrAlreadyBootstrapped) {
+		t.Errorf("IssueToken() after completion error = %v", err)
+	}
+	if _, err := svc.Complete(ctx, token, req); !errors.Is(err, ErrAlreadyBootstrapped) {
+		t.Errorf("second Complete() error = %v", err)
+	}
+}
+
+func TestBootstrapTokenExpiry(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{}
+	svc := newTestService(repo, &mockAuditLogger{}, time.Hour)
+
+	token, err := svc.IssueToken(ctx)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	past := time.Now().Add(-time.Minute)
+	repo.state.TokenExpiresAt = &past
+
+	_, err = svc.Complete(ctx, token, AdminRequest{Email: "admin@example.com", Password: "correct-horse-battery"})
+	if !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Complete() error = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestBootstrapNotRequiredWithExistingAdmin(t *testing.T) {
+	svc := newTestService(&mockRepo{hasAdmin: true}, &mockAuditLogger{}, time.Hour)
+
+	if _, err := svc.IssueToken(context.Background()); !errors.Is(err, ErrAlreadyBootstrapped) {
+		t.Errorf("IssueToken() error = %v, want ErrAlreadyBootstrapped", err)
+	}
+}
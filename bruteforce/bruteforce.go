@@ -0,0 +1,134 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bruteforce
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrSourceBlocked = apperror.New(apperror.CodeSourceBlocked, apperror.StatusTooManyRequests, apperror.OAuth2AccessDenied, "source address is temporarily blocked")
+	ErrInvalidCIDR   = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid IP address or CIDR")
+	ErrBlockNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "block not found")
+	ErrAllowNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "allowlist entry not found")
+)
+
+// Block reasons
+const (
+	ReasonFailedAttempts     = "failed_attempts"
+	ReasonCredentialStuffing = "credential_stuffing"
+	ReasonASNFailedAttempts  = "asn_failed_attempts"
+	ReasonManual             = "manual"
+)
+
+// Attempt is a single authentication attempt as seen by the transport.
+//
+// Purpose: Input to cross-account brute-force detection.
+// Domain: Identity (Security)
+// Invariants: AccountHash is the email hash, never the plaintext identifier. ASN is optional.
+type Attempt struct {
+	IP          string
+	ASN         string
+	AccountHash string
+	Success     bool
+	At          time.Time
+}
+
+// Block is a temporary deny rule for a source address or network.
+//
+// Purpose: Persisted IP/CIDR block shared across all instances.
+// Domain: Identity (Security)
+// Invariants: CIDR is canonical (e.g. "203.0.113.7/32"). Expired blocks are ignored.
+type Block struct {
+	ID        string
+	CIDR      string
+	Reason    string
+	CreatedBy string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// IsActive reports whether the block is still in force at now.
+func (b *Block) IsActive(now time.Time) bool {
+	return now.Before(b.ExpiresAt)
+}
+
+// AllowEntry exempts a source network from automatic blocking.
+//
+// Purpose: Allowlist for trusted egress (office NAT, health checkers, partner gateways).
+// Domain: Identity (Security)
+// Invariants: CIDR is canonical. Allowlisted sources are never blocked, automatically or manually.
+type AllowEntry struct {
+	ID          string
+	CIDR        string
+	Description string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// Policy holds detection thresholds.
+//
+// Purpose: Tunable cross-account brute-force limits.
+// Domain: Identity (Security)
+// Invariants: A zero threshold disables that detector.
+type Policy struct {
+	// Window is the sliding window over which attempts are counted.
+	Window time.Duration
+	// BlockDuration is how long an automatic block lasts.
+	BlockDuration time.Duration
+	// MaxFailuresPerIP blocks an IP after this many failures in Window.
+	MaxFailuresPerIP int
+	// MaxAccountsPerIP flags credential stuffing when one IP fails against this many distinct accounts in Window.
+	MaxAccountsPerIP int
+	// MaxFailuresPerASN blocks nothing but emits an alert when an ASN exceeds this many failures in Window.
+	MaxFailuresPerASN int
+}
+
+// DefaultPolicy returns conservative detection thresholds.
+func DefaultPolicy() Policy {
+	return Policy{
+		Window:            10 * time.Minute,
+		BlockDuration:     30 * time.Minute,
+		MaxFailuresPerIP:  50,
+		MaxAccountsPerIP:  10,
+		MaxFailuresPerASN: 500,
+	}
+}
+
+// Repository defines persistence for blocks and allowlist entries.
+//
+// Purpose: Shared storage so blocks apply across all instances.
+// Domain: Identity (Security)
+type Repository interface {
+	// CreateBlock persists a new block
+	CreateBlock(ctx context.Context, b *Block) error
+	// ListActiveBlocks returns blocks that have not expired at now
+	ListActiveBlocks(ctx context.Context, now time.Time) ([]*Block, error)
+	// DeleteBlock removes a block by ID
+	DeleteBlock(ctx context.Context, id string) error
+	// DeleteExpiredBlocks removes blocks that expired before now
+	DeleteExpiredBlocks(ctx context.Context, now time.Time) error
+
+	// AddAllow persists an allowlist entry
+	AddAllow(ctx context.Context, e *AllowEntry) error
+	// ListAllow returns all allowlist entries
+	ListAllow(ctx context.Context) ([]*AllowEntry, error)
+	// DeleteAllow removes an allowlist entry by ID
+	DeleteAllow(ctx context.Context, id string) error
+}
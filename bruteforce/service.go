@@ -0,0 +1,465 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bruteforce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/id"
+)
+
+// ErrSourceAllowlisted is returned when blocking a source covered by the allowlist.
+var ErrSourceAllowlisted = apperror.New(apperror.CodeInvalidRequest, apperror.StatusConflict, "", "source address is allowlisted")
+
+// rulesTTL bounds how long block/allow rules are served from memory before reloading.
+const rulesTTL = 15 * time.Second
+
+// Service detects cross-account brute-force and credential-stuffing patterns.
+//
+// Purpose: Source-level protection complementing per-account lockout.
+// Domain: Identity (Security)
+// Invariants: Attempt windows are per instance; blocks and allowlists are shared via Repository.
+type Service struct {
+	repo        Repository
+	auditLogger audit.Logger
+	policy      Policy
+
+	mu    sync.Mutex
+	ips   map[string]*window
+	asns  map[string]*window
+	rules *rules
+}
+
+// window holds recent failures for one source.
+type window struct {
+	failures []time.Time
+	accounts map[string]time.Time
+	alerted  bool
+}
+
+// rules is a cached snapshot of the persisted block and allow rules.
+type rules struct {
+	blocks   []blockRule
+	allow    []netip.Prefix
+	loadedAt time.Time
+}
+
+// blockRule is a parsed block and the time it lifts.
+type blockRule struct {
+	prefix    netip.Prefix
+	expiresAt time.Time
+}
+
+// NewService creates a new brute-force detection service.
+//
+// Purpose: Constructor for the brute-force detection service.
+// Domain: Identity (Security)
+// Audited: No
+// Errors: None
+func NewService(repo Repository, auditLogger audit.Logger, policy Policy) *Service {
+	return &Service{
+		repo:        repo,
+		auditLogger: auditLogger,
+		policy:      policy,
+		ips:         make(map[string]*window),
+		asns:        make(map[string]*window),
+	}
+}
+
+// Check reports whether an authentication attempt from ip may proceed.
+//
+// Purpose: Gate called by transports before credential verification.
+// Domain: Identity (Security)
+// Security: Allowlisted sources always pass. Unparseable addresses are not blocked.
+// A blocked source gets a jittered retry delay until its latest covering block lifts.
+// Audited: No
+// Errors: ErrSourceBlocked (wrapped in apperror.RetryAfterError), System errors
+func (s *Service) Check(ctx context.Context, ip string) error {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil
+	}
+
+	r, err := s.loadRules(ctx)
+	if err != nil {
+		return err
+	}
+	if containsAddr(r.allow, addr) {
+		return nil
+	}
+	var until time.Time
+	unmapped := addr.Unmap()
+	for _, b := range r.blocks {
+		if b.prefix.Contains(unmapped) && b.expiresAt.After(until) {
+			until = b.expiresAt
+		}
+	}
+	if !until.IsZero() {
+		return apperror.WithRetryAfter(ErrSourceBlocked, time.Until(until))
+	}
+	return nil
+}
+
+// RecordAttempt feeds an authentication outcome into the detectors and blocks
+// the source when a threshold is crossed.
+//
+// Purpose: Cross-account failure tracking per source IP and ASN.
+// Domain: Identity (Security)
+// Security: Only failures are counted. Allowlisted sources are reported but never blocked.
+// Audited: Yes (SourceBlocked, CredentialStuffingDetected, BruteForceDetected)
+// Errors: System errors
+func (s *Service) RecordAttempt(ctx context.Context, a Attempt) error {
+	if a.Success {
+		return nil
+	}
+	if a.At.IsZero() {
+		a.At = time.Now()
+	}
+
+	var blockReason string
+	var stuffing, asnAlert bool
+	var failures, accounts int
+
+	s.mu.Lock()
+	if a.IP != "" {
+		w := s.windowFor(s.ips, a.IP, a.At)
+		w.failures = append(w.failures, a.At)
+		if a.AccountHash != "" {
+			w.accounts[a.AccountHash] = a.At
+		}
+		failures, accounts = len(w.failures), len(w.accounts)
+
+		switch {
+		case s.policy.MaxAccountsPerIP > 0 && accounts >= s.policy.MaxAccountsPerIP:
+			blockReason, stuffing = ReasonCredentialStuffing, true
+		case s.policy.MaxFailuresPerIP > 0 && failures >= s.policy.MaxFailuresPerIP:
+			blockReason = ReasonFailedAttempts
+		}
+		if blockReason != "" {
+			delete(s.ips, a.IP)
+		}
+	}
+	if a.ASN != "" && s.policy.MaxFailuresPerASN > 0 {
+		w := s.windowFor(s.asns, a.ASN, a.At)
+		w.failures = append(w.failures, a.At)
+		if len(w.failures) >= s.policy.MaxFailuresPerASN && !w.alerted {
+			w.alerted = true
+			asnAlert = true
+		}
+	}
+	s.mu.Unlock()
+
+	if asnAlert {
+		s.auditLogger.Log(ctx, audit.Event{
+			Type:       audit.TypeBruteForceDetected,
+			Resource:   audit.ResourceNetwork,
+			TargetName: a.ASN,
+			Metadata: map[string]any{
+				audit.AttrReason: ReasonASNFailedAttempts,
+				"asn":            a.ASN,
+				"window":         s.policy.Window.String(),
+			},
+		})
+	}
+
+	if blockReason == "" {
+		return nil
+	}
+
+	if stuffing {
+		s.auditLogger.Log(ctx, audit.Event{
+			Type:       audit.TypeCredentialStuffingDetected,
+			Resource:   audit.ResourceNetwork,
+			TargetName: a.IP,
+			IPAddress:  a.IP,
+			Metadata: map[string]any{
+				"distinct_accounts": accounts,
+				audit.AttrAttempts:  failures,
+				"window":            s.policy.Window.String(),
+			},
+		})
+	}
+
+	// Automatic blocks have no human actor.
+	_, err := s.block(ctx, a.IP, s.policy.BlockDuration, blockReason, "")
+	if errors.Is(err, ErrSourceAllowlisted) || errors.Is(err, ErrInvalidCIDR) {
+		return nil
+	}
+	return err
+}
+
+// BlockSource manually blocks an address or network.
+//
+// Purpose: Operator response to an ongoing attack.
+// Domain: Identity (Security)
+// Audited: Yes (SourceBlocked)
+// Errors: ErrInvalidCIDR, ErrSourceAllowlisted, System errors
+func (s *Service) BlockSource(ctx context.Context, cidr string, duration time.Duration, actorID string) (*Block, error) {
+	return s.block(ctx, cidr, duration, ReasonManual, actorID)
+}
+
+// Unblock removes a block before it expires.
+//
+// Purpose: Operator override for false positives.
+// Domain: Identity (Security)
+// Audited: Yes (SourceUnblocked)
+// Errors: ErrBlockNotFound, System errors
+func (s *Service) Unblock(ctx context.Context, blockID, actorID string) error {
+	if err := s.repo.DeleteBlock(ctx, blockID); err != nil {
+		return err
+	}
+	s.invalidateRules()
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeSourceUnblocked,
+		ActorID:  actorID,
+		Resource: audit.ResourceNetwork,
+		TargetID: blockID,
+	})
+	return nil
+}
+
+// ListBlocks returns the blocks currently in force.
+func (s *Service) ListBlocks(ctx context.Context) ([]*Block, error) {
+	return s.repo.ListActiveBlocks(ctx, time.Now())
+}
+
+// Allow adds a network to the allowlist.
+//
+// Purpose: Exempts trusted egress from automatic blocking.
+// Domain: Identity (Security)
+// Audited: Yes (SourceAllowlisted)
+// Errors: ErrInvalidCIDR, System errors
+func (s *Service) Allow(ctx context.Context, cidr, description, actorID string) (*AllowEntry, error) {
+	prefix, err := ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &AllowEntry{
+		ID:          id.NewUUIDv7(),
+		CIDR:        prefix.String(),
+		Description: description,
+		CreatedBy:   actorID,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.AddAllow(ctx, e); err != nil {
+		return nil, err
+	}
+	s.invalidateRules()
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeSourceAllowlisted,
+		ActorID:    actorID,
+		Resource:   audit.ResourceNetwork,
+		TargetName: e.CIDR,
+		TargetID:   e.ID,
+	})
+	return e, nil
+}
+
+// RemoveAllow deletes an allowlist entry.
+//
+// Purpose: Revokes an allowlist exemption.
+// Domain: Identity (Security)
+// Audited: Yes (SourceAllowlistRemoved)
+// Errors: ErrAllowNotFound, System errors
+func (s *Service) RemoveAllow(ctx context.Context, entryID, actorID string) error {
+	if err := s.repo.DeleteAllow(ctx, entryID); err != nil {
+		return err
+	}
+	s.invalidateRules()
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeSourceAllowlistRemoved,
+		ActorID:  actorID,
+		Resource: audit.ResourceNetwork,
+		TargetID: entryID,
+	})
+	return nil
+}
+
+// ListAllow returns all allowlist entries.
+func (s *Service) ListAllow(ctx context.Context) ([]*AllowEntry, error) {
+	return s.repo.ListAllow(ctx)
+}
+
+// Prune drops expired in-memory windows and persisted blocks.
+//
+// Purpose: Periodic maintenance job for the scheduler.
+// Domain: Identity (Security)
+// Audited: No
+// Errors: System errors
+func (s *Service) Prune(ctx context.Context) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	for _, m := range []map[string]*window{s.ips, s.asns} {
+		for key := range m {
+			s.windowFor(m, key, now)
+			if len(m[key].failures) == 0 {
+				delete(m, key)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	return s.repo.DeleteExpiredBlocks(ctx, now)
+}
+
+// ParseCIDR parses an address or CIDR into its canonical masked prefix.
+// A bare address becomes a single-host prefix.
+func ParseCIDR(s string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w: %s", ErrInvalidCIDR, s)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func (s *Service) block(ctx context.Context, cidr string, duration time.Duration, reason, actorID string) (*Block, error) {
+	prefix, err := ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.loadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, allowed := range r.allow {
+		if allowed.Overlaps(prefix) {
+			return nil, ErrSourceAllowlisted
+		}
+	}
+
+	now := time.Now()
+	b := &Block{
+		ID:        id.NewUUIDv7(),
+		CIDR:      prefix.String(),
+		Reason:    reason,
+		CreatedBy: actorID,
+		ExpiresAt: now.Add(duration),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateBlock(ctx, b); err != nil {
+		return nil, err
+	}
+	s.invalidateRules()
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeSourceBlocked,
+		ActorID:    actorID,
+		Resource:   audit.ResourceNetwork,
+		TargetName: b.CIDR,
+		TargetID:   b.ID,
+		Metadata: map[string]any{
+			audit.AttrReason: reason,
+			"expires_at":     b.ExpiresAt,
+		},
+	})
+	return b, nil
+}
+
+// windowFor returns the window for key with entries older than the policy window removed.
+// Callers must hold s.mu.
+func (s *Service) windowFor(m map[string]*window, key string, now time.Time) *window {
+	w, ok := m[key]
+	if !ok {
+		w = &window{accounts: make(map[string]time.Time)}
+		m[key] = w
+	}
+
+	cutoff := now.Add(-s.policy.Window)
+	kept := w.failures[:0]
+	for _, t := range w.failures {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	w.failures = kept
+	for acct, t := range w.accounts {
+		if !t.After(cutoff) {
+			delete(w.accounts, acct)
+		}
+	}
+	if len(w.failures) == 0 {
+		w.alerted = false
+	}
+	return w
+}
+
+func (s *Service) loadRules(ctx context.Context) (*rules, error) {
+	s.mu.Lock()
+	cached := s.rules
+	s.mu.Unlock()
+	if cached != nil && time.Since(cached.loadedAt) < rulesTTL {
+		return cached, nil
+	}
+
+	now := time.Now()
+	blocks, err := s.repo.ListActiveBlocks(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blocks: %w", err)
+	}
+	allow, err := s.repo.ListAllow(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load allowlist: %w", err)
+	}
+
+	r := &rules{loadedAt: now}
+	for _, b := range blocks {
+		if p, err := ParseCIDR(b.CIDR); err == nil {
+			r.blocks = append(r.blocks, blockRule{prefix: p, expiresAt: b.ExpiresAt})
+		}
+	}
+	for _, e := range allow {
+		if p, err := ParseCIDR(e.CIDR); err == nil {
+			r.allow = append(r.allow, p)
+		}
+	}
+
+	s.mu.Lock()
+	s.rules = r
+	s.mu.Unlock()
+	return r, nil
+}
+
+func (s *Service) invalidateRules() {
+	s.mu.Lock()
+	s.rules = nil
+	s.mu.Unlock()
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bruteforce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+type mockRepo struct {
+	blocks []*Block
+	allow  []*AllowEntry
+}
+
+func (m *mockRepo) CreateBlock(ctx context.Context, b *Block) error {
+	m.blocks = append(m.blocks, b)
+	return nil
+}
+
+func (m *mockRepo) ListActiveBlocks(ctx context.Context, now time.Time) ([]*Block, error) {
+	var res []*Block
+	for _, b := range m.blocks {
+		if b.IsActive(now) {
+			res = append(res, b)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) DeleteBlock(ctx context.Context, id string) error {
+	for i, b := range m.blocks {
+		if b.ID == id {
+			m.blocks = append(m.blocks[:i], m.blocks[i+1:]...)
+			return nil
+		}
+	}
+	return ErrBlockNotFound
+}
+
+func (m *mockRepo) DeleteExpiredBlocks(ctx context.Context, now time.Time) error {
+	m.blocks, _ = m.ListActiveBlocks(ctx, now)
+	return nil
+}
+
+func (m *mockRepo) AddAllow(ctx context.Context, e *AllowEntry) error {
+	m.allow = append(m.allow, e)
+	return nil
+}
+
+func (m *mockRepo) ListAllow(ctx context.Context) ([]*AllowEntry, error) {
+	return m.allow, nil
+}
+
+func (m *mockRepo) DeleteAllow(ctx context.Context, id string) error {
+	for i, e := range m.allow {
+		if e.ID == id {
+			m.allow = append(m.allow[:i], m.allow[i+1:]...)
+			return nil
+		}
+	}
+	return ErrAllowNotFound
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+func (m *mockAuditLogger) has(eventType string) bool {
+	for _, e := range m.events {
+		if e.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRecordAttempt(t *testing.T) {
+	policy := Policy{
+		Window:            time.Minute,
+		BlockDuration:     time.Hour,
+		MaxFailuresPerIP:  5,
+		MaxAccountsPerIP:  3,
+		MaxFailuresPerASN: 4,
+	}
+
+	tests := []struct {
+		name      string
+		allow     string
+		attempts  func(now time.Time) []Attempt
+		ip        string
+		wantErr   error
+		wantEvent string
+	}{
+		{
+			name: "repeated failures on one account block the ip",
+			attempts: func(now time.Time) []Attempt {
+				var res []Attempt
+				for i := 0; i < 5; i++ {
+					res = append(res, Attempt{IP: "203.0.113.7", AccountHash: "h1", At: now})
+				}
+				return res
+			},
+			ip:        "203.0.113.7",
+			wantErr:   ErrSourceBlocked,
+			wantEvent: audit.TypeSourceBlocked,
+		},
+		{
+			name: "failures across many accounts flag credential stuffing",
+			attempts: func(now time.Time) []Attempt {
+				var res []Attempt
+				for i := 0; i < 3; i++ {
+					res = append(res, Attempt{IP: "203.0.113.8", AccountHash: fmt.Sprintf("h%d", i), At: now})
+				}
+				return res
+			},
+			ip:        "203.0.113.8",
+			wantErr:   ErrSourceBlocked,
+			wantEvent: audit.TypeCredentialStuffingDetected,
+		},
+		{
+			name: "failures outside the window are forgotten",
+			attempts: func(now time.Time) []Attempt {
+				var res []Attempt
+				for i := 0; i < 4; i++ {
+					res = append(res, Attempt{IP: "203.0.113.9", AccountHash: "h1", At: now.Add(-2 * time.Minute)})
+				}
+				return append(res, Attempt{IP: "203.0.113.9", AccountHash: "h1", At: now})
+			},
+			ip: "203.0.113.9",
+		},
+		{
+			name: "successes are not counted",
+			attempts: func(now time.Time) []Attempt {
+				var res []Attempt
+				for i := 0; i < 10; i++ {
+					res = append(res, Attempt{IP: "203.0.113.10", AccountHash: "h1", Success: true, At: now})
+				}
+				return res
+			},
+			ip: "203.0.113.10",
+		},
+		{
+			name:  "allowlisted sources are never blocked",
+			allow: "198.51.100.0/24",
+			attempts: func(now time.Time) []Attempt {
+				var res []Attempt
+				for i := 0; i < 5; i++ {
+					res = append(res, Attempt{IP: "198.51.100.20", AccountHash: fmt.Sprintf("h%d", i), At: now})
+				}
+				return res
+			},
+			ip:        "198.51.100.20",
+			wantEvent: audit.TypeCredentialStuffingDetected,
+		},
+		{
+			name: "asn threshold alerts without blocking",
+			attempts: func(now time.Time) []Attempt {
+				var res []Attempt
+				for i := 0; i < 4; i++ {
+					res = append(res, Attempt{IP: fmt.Sprintf("192.0.2.%d", i), ASN: "AS64500", AccountHash: "h1", At: now})
+				}
+				return res
+			},
+			ip:        "192.0.2.1",
+			wantEvent: audit.TypeBruteForceDetected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			logger := &mockAuditLogger{}
+			svc := NewService(&mockRepo{}, logger, policy)
+
+			if tt.allow != "" {
+				if _, err := svc.Allow(ctx, tt.allow, "office", "admin"); err != nil {
+					t.Fatalf("Allow() error = %v", err)
+				}
+			}
+
+			for _, a := range tt.attempts(time.Now()) {
+				if err := svc.RecordAttempt(ctx, a); err != nil {
+					t.Fatalf("RecordAttempt() error = %v", err)
+				}
+			}
+
+			if err := svc.Check(ctx, tt.ip); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantEvent != "" && !logger.has(tt.wantEvent) {
+				t.Errorf("expected audit event %q, got %+v", tt.wantEvent, logger.events)
+			}
+		})
+	}
+}
+
+func TestManualBlock(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockAuditLogger{}
+	svc := NewService(&mockRepo{}, logger, DefaultPolicy())
+
+	if _, err := svc.BlockSource(ctx, "not-an-ip", time.Hour, "admin"); !errors.Is(err, ErrInvalidCIDR) {
+		t.Fatalf("BlockSource(invalid) error = %v, want ErrInvalidCIDR", err)
+	}
+
+	b, err := svc.BlockSource(ctx, "203.0.113.0/24", time.Hour, "admin")
+	if err != nil {
+		t.Fatalf("BlockSource() error = %v", err)
+	}
+	err = svc.Check(ctx, "203.0.113.99")
+	if !errors.Is(err, ErrSourceBlocked) {
+		t.Errorf("Check() inside blocked range error = %v", err)
+	}
+	if d, ok := apperror.RetryAfterOf(err); !ok || d < time.Hour-time.Minute || d > time.Hour+time.Hour/10 {
+		t.Errorf("RetryAfterOf() = %v, %v; want about the block's remaining hour", d, ok)
+	}
+	if err := svc.Check(ctx, "::ffff:203.0.113.99"); !errors.Is(err, ErrSourceBlocked) {
+		t.Errorf("Check() for IPv4-mapped address error = %v", err)
+	}
+	if err := svc.Check(ctx, "203.0.114.1"); err != nil {
+		t.Errorf("Check() outside blocked range error = %v", err)
+	}
+
+	if err := svc.Unblock(ctx, b.ID, "admin"); err != nil {
+		t.Fatalf("Unblock() error = %v", err)
+	}
+	if err := svc.Check(ctx, "203.0.113.99"); err != nil {
+		t.Errorf("Check() after unblock error = %v", err)
+	}
+	if err := svc.Unblock(ctx, b.ID, "admin"); !errors.Is(err, ErrBlockNotFound) {
+		t.Errorf("second Unblock() error = %v, want ErrBlockNotFound", err)
+	}
+
+	if _, err := svc.Allow(ctx, "10.0.0.0/8", "vpn", "admin"); err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if _, err := svc.BlockSource(ctx, "10.1.2.3", time.Hour, "admin"); !errors.Is(err, ErrSourceAllowlisted) {
+		t.Errorf("BlockSource(allowlisted) error = %v, want ErrSourceAllowlisted", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache provides the shared TTL cache behind replay protection (DPoP
+// jti, nonces) and single-use stateless authorization codes: a sharded
+// in-process implementation for single instances and a Redis-backed one for
+// deployments with several.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a TTL key/value store.
+//
+// Purpose: Storage for short-lived values that must expire on their own.
+// Domain: Platform
+// Invariants: An entry is never returned after its TTL has elapsed. Add is atomic:
+// of concurrent Adds of one key, exactly one succeeds.
+type Cache interface {
+	// Get returns the value of key and whether it was present
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl, replacing any existing entry
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Add stores value under key for ttl unless the key is present, and reports whether it stored it
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Delete removes key
+	Delete(ctx context.Context, key string) error
+}
+
+// seen implements the Seen method of the replay caches on top of Add.
+func seen(ctx context.Context, c Cache, key string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// Already expired: nothing to remember, and a replay of it is rejected by its own expiry check.
+		return false, nil
+	}
+	added, err := c.Add(ctx, key, nil, ttl)
+	if err != nil {
+		return false, err
+	}
+	return !added, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/metrics"
+	"github.com/opentrusty/opentrusty-core/verifier"
+)
+
+var (
+	_ verifier.ReplayCache = (*Memory)(nil)
+	_ client.UsedCodeCache = (*Memory)(nil)
+	_ verifier.ReplayCache = (*Redis)(nil)
+	_ client.UsedCodeCache = (*Redis)(nil)
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+// fakeRedis is an in-memory RedisClient.
+type fakeRedis struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	now     func() time.Time
+}
+
+func newFakeRedis(now func() time.Time) *fakeRedis {
+	return &fakeRedis{entries: map[string]entry{}, now: now}
+}
+
+func (f *fakeRedis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, ok := f.entries[key]
+	if !ok || !f.now().Before(e.expiresAt) {
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+func (f *fakeRedis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries[key] = entry{value: value, expiresAt: f.now().Add(ttl)}
+	return nil
+}
+
+func (f *fakeRedis) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if e, ok := f.entries[key]; ok && f.now().Before(e.expiresAt) {
+		return false, nil
+	}
+	f.entries[key] = entry{value: value, expiresAt: f.now().Add(ttl)}
+	return true, nil
+}
+
+func (f *fakeRedis) Del(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries, key)
+	return nil
+}
+
+func TestCaches(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(*fakeClock) Cache
+	}{
+		{"memory", func(c *fakeClock) Cache {
+			m := NewMemory(WithShards(4))
+			m.now = c.now
+			return m
+		}},
+		{"redis", func(c *fakeClock) Cache {
+			return NewRedis(newFakeRedis(c.now), "ot:")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := &fakeClock{t: time.Now()}
+			c := tt.new(clock)
+
+			if err := c.Set(ctx, "k", []byte("v1"), time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if v, ok, _ := c.Get(ctx, "k"); !ok || string(v) != "v1" {
+				t.Errorf("Get() = %q, %v; want v1", v, ok)
+			}
+			if added, _ := c.Add(ctx, "k", []byte("v2"), time.Minute); added {
+				t.Error("Add() replaced a live entry")
+			}
+
+			clock.t = clock.t.Add(2 * time.Minute)
+			if _, ok, _ := c.Get(ctx, "k"); ok {
+				t.Error("Get() returned an expired entry")
+			}
+			if added, _ := c.Add(ctx, "k", []byte("v3"), time.Minute); !added {
+				t.Error("Add() refused to replace an expired entry")
+			}
+
+			if err := c.Delete(ctx, "k"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, ok, _ := c.Get(ctx, "k"); ok {
+				t.Error("Get() returned a deleted entry")
+			}
+		})
+	}
+}
+
+func TestSeen(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	if seen, _ := m.Seen(ctx, "jti-1", time.Now().Add(time.Minute)); seen {
+		t.Error("first Seen() = true")
+	}
+	if seen, _ := m.Seen(ctx, "jti-1", time.Now().Add(time.Minute)); !seen {
+		t.Error("second Seen() = false, want replay detected")
+	}
+	if seen, _ := m.Seen(ctx, "jti-2", time.Now().Add(-time.Second)); seen || m.Len() != 1 {
+		t.Errorf("Seen() of an expired key = %v with %d entries; want nothing stored", seen, m.Len())
+	}
+}
+
+func TestMemoryConcurrentAdd(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for range 64 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if added, _ := m.Add(ctx, "code", nil, time.Minute); added {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if wins.Load() != 1 {
+		t.Errorf("%d concurrent Adds succeeded, want exactly 1", wins.Load())
+	}
+}
+
+func TestMemoryBoundsAndMetrics(t *testing.T) {
+	ctx := context.Background()
+	reg := metrics.NewRegistry()
+	mt := metrics.New(reg)
+	clock := &fakeClock{t: time.Now()}
+	m := NewMemory(WithShards(1), WithMaxEntries(2), WithMetrics(mt, "dpop"))
+	m.now = clock.now
+
+	_ = m.Set(ctx, "short", nil, time.Second)
+	_ = m.Set(ctx, "long", nil, time.Hour)
+	_ = m.Set(ctx, "new", nil, time.Hour)
+
+	if m.Len() != 2 {
+		t.Fatalf("Len() = %d, want bounded to 2", m.Len())
+	}
+	if _, ok, _ := m.Get(ctx, "short"); ok {
+		t.Error("entry closest to expiry was not the one evicted")
+	}
+
+	clock.t = clock.t.Add(2 * time.Hour)
+	if err := m.Sweep(ctx); err != nil || m.Len() != 0 {
+		t.Errorf("Sweep() left %d entries, err = %v", m.Len(), err)
+	}
+
+	var sb strings.Builder
+	_ = reg.WriteText(&sb)
+	for _, want := range []string{
+		`opentrusty_cache_evictions_total{cache="dpop",reason="capacity"} 1`,
+		`opentrusty_cache_evictions_total{cache="dpop",reason="expired"} 2`,
+		`opentrusty_cache_lookups_total{cache="dpop",result="miss"} 1`,
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("metrics missing %q\n%s", want, sb.String())
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/metrics"
+)
+
+// Memory defaults
+const (
+	DefaultShards     = 32
+	DefaultMaxEntries = 100_000
+)
+
+// sweepInterval is how often a shard drops expired entries on write.
+const sweepInterval = time.Minute
+
+// MemoryOption configures a Memory cache.
+type MemoryOption func(*Memory)
+
+// WithShards splits the cache into n independently locked shards (at least 1).
+func WithShards(n int) MemoryOption {
+	return func(m *Memory) { m.shardCount = max(n, 1) }
+}
+
+// WithMaxEntries bounds the cache to about n entries; beyond it the entries
+// closest to expiry are evicted. n <= 0 removes the bound.
+func WithMaxEntries(n int) MemoryOption {
+	return func(m *Memory) { m.maxEntries = n }
+}
+
+// WithMetrics records lookups and evictions on mt under name.
+func WithMetrics(mt *metrics.Metrics, name string) MemoryOption {
+	return func(m *Memory) { m.metrics, m.name = mt, name }
+}
+
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+type shard struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	lastSweep time.Time
+}
+
+// Memory is a sharded in-process Cache.
+//
+// Purpose: Replay and single-use caches for single-instance deployments and tests.
+// Domain: Platform
+// Invariants: Each shard holds at most maxEntries/shards entries. Evicting a live
+// entry is counted as a capacity eviction; for replay caches it reopens that key,
+// so size the bound above the peak number of live entries.
+type Memory struct {
+	shards     []*shard
+	shardCount int
+	maxEntries int
+	perShard   int
+	metrics    *metrics.Metrics
+	name       string
+	now        func() time.Time
+}
+
+// NewMemory creates an empty in-process cache.
+//
+// Purpose: Constructor for the sharded TTL cache.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func NewMemory(opts ...MemoryOption) *Memory {
+	m := &Memory{
+		shardCount: DefaultShards,
+		maxEntries: DefaultMaxEntries,
+		name:       "memory",
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.shards = make([]*shard, m.shardCount)
+	for i := range m.shards {
+		m.shards[i] = &shard{entries: make(map[string]entry)}
+	}
+	if m.maxEntries > 0 {
+		m.perShard = max(m.maxEntries/m.shardCount, 1)
+	}
+	return m
+}
+
+// Get returns the value of key and whether it was present.
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s := m.shard(key)
+	s.mu.Lock()
+	e, ok := s.entries[key]
+	s.mu.Unlock()
+
+	if !ok || !m.now().Before(e.expiresAt) {
+		m.metrics.CacheLookup(m.name, metrics.CacheMiss)
+		return nil, false, nil
+	}
+	m.metrics.CacheLookup(m.name, metrics.CacheHit)
+	return e.value, true, nil
+}
+
+// Set stores value under key for ttl, replacing any existing entry.
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := m.now()
+	m.makeRoom(s, key, now)
+	s.entries[key] = entry{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Add stores value under key for ttl unless a live entry exists, and reports whether it stored it.
+func (m *Memory) Add(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := m.now()
+	if e, ok := s.entries[key]; ok && now.Before(e.expiresAt) {
+		m.metrics.CacheLookup(m.name, metrics.CacheHit)
+		return false, nil
+	}
+	m.metrics.CacheLookup(m.name, metrics.CacheMiss)
+	m.makeRoom(s, key, now)
+	s.entries[key] = entry{value: value, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Delete removes key.
+func (m *Memory) Delete(_ context.Context, key string) error {
+	s := m.shard(key)
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Seen records key until expiresAt and reports whether it was already recorded.
+// It implements verifier.ReplayCache and client.UsedCodeCache.
+func (m *Memory) Seen(ctx context.Context, key string, expiresAt time.Time) (bool, error) {
+	return seen(ctx, m, key, expiresAt)
+}
+
+// Len returns the number of entries held, including expired ones not yet swept.
+func (m *Memory) Len() int {
+	n := 0
+	for _, s := range m.shards {
+		s.mu.Lock()
+		n += len(s.entries)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Sweep drops every expired entry. It suits a scheduler job; writes also sweep
+// their shard periodically.
+func (m *Memory) Sweep(context.Context) error {
+	now := m.now()
+	for _, s := range m.shards {
+		s.mu.Lock()
+		m.sweep(s, now)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+func (m *Memory) shard(key string) *shard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return m.shards[h.Sum32()%uint32(len(m.shards))]
+}
+
+// sweep drops the expired entries of s. The caller holds s.mu.
+func (m *Memory) sweep(s *shard, now time.Time) {
+	n := 0
+	for k, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, k)
+			n++
+		}
+	}
+	s.lastSweep = now
+	m.metrics.CacheEvicted(m.name, metrics.CacheEvictExpired, n)
+}
+
+// makeRoom ensures s can take key: it sweeps periodically and, when s is full,
+// evicts expired entries or else the entry closest to expiry. The caller holds s.mu.
+func (m *Memory) makeRoom(s *shard, key string, now time.Time) {
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		m.sweep(s, now)
+	}
+	if m.perShard == 0 || len(s.entries) < m.perShard {
+		return
+	}
+	if _, ok := s.entries[key]; ok {
+		return
+	}
+	m.sweep(s, now)
+	if len(s.entries) < m.perShard {
+		return
+	}
+
+	var victim string
+	var soonest time.Time
+	for k, e := range s.entries {
+		if victim == "" || e.expiresAt.Before(soonest) {
+			victim, soonest = k, e.expiresAt
+		}
+	}
+	delete(s.entries, victim)
+	m.metrics.CacheEvicted(m.name, metrics.CacheEvictCapacity, 1)
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/metrics"
+)
+
+// RedisClient is the subset of Redis commands the cache uses. Hosts adapt
+// their Redis client (go-redis, rueidis, ...) to it; core has no Redis dependency.
+//
+// Purpose: Adapter boundary between core and the host's Redis driver.
+// Domain: Platform
+type RedisClient interface {
+	// Get runs GET and reports whether the key existed
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set runs SET key value PX ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX runs SET key value NX PX ttl and reports whether the key was set
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Del runs DEL
+	Del(ctx context.Context, key string) error
+}
+
+// RedisOption configures a Redis cache.
+type RedisOption func(*Redis)
+
+// WithRedisMetrics records lookups on mt under name.
+func WithRedisMetrics(mt *metrics.Metrics, name string) RedisOption {
+	return func(r *Redis) { r.metrics, r.name = mt, name }
+}
+
+// Redis is a Cache stored in Redis, shared by every instance using the same server and prefix.
+//
+// Purpose: Replay and single-use caches for multi-instance deployments.
+// Domain: Platform
+// Invariants: Every key is stored under prefix. Expiry and eviction are Redis's.
+type Redis struct {
+	client  RedisClient
+	prefix  string
+	metrics *metrics.Metrics
+	name    string
+}
+
+// NewRedis creates a cache storing keys under prefix in client.
+//
+// Purpose: Constructor for the shared cache.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func NewRedis(client RedisClient, prefix string, opts ...RedisOption) *Redis {
+	r := &Redis{client: client, prefix: prefix, name: "redis"}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Get returns the value of key and whether it was present.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	v, ok, err := r.client.Get(ctx, r.prefix+key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get cache entry: %w", err)
+	}
+	r.record(ok)
+	return v, ok, nil
+}
+
+// Set stores value under key for ttl, replacing any existing entry.
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := r.client.Set(ctx, r.prefix+key, value, ttl); err != nil {
+		return fmt.Errorf("failed to set cache entry: %w", err)
+	}
+	return nil
+}
+
+// Add stores value under key for ttl unless the key is present, and reports whether it stored it.
+func (r *Redis) Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	added, err := r.client.SetNX(ctx, r.prefix+key, value, ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to add cache entry: %w", err)
+	}
+	r.record(!added)
+	return added, nil
+}
+
+// Delete removes key.
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, r.prefix+key); err != nil {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// Seen records key until expiresAt and reports whether it was already recorded.
+// It implements verifier.ReplayCache and client.UsedCodeCache.
+func (r *Redis) Seen(ctx context.Context, key string, expiresAt time.Time) (bool, error) {
+	return seen(ctx, r, key, expiresAt)
+}
+
+func (r *Redis) record(hit bool) {
+	if hit {
+		r.metrics.CacheLookup(r.name, metrics.CacheHit)
+	} else {
+		r.metrics.CacheLookup(r.name, metrics.CacheMiss)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package changelog keeps an append-only record of client and tenant
+// configuration changes as field-level before/after diffs, so administrators can
+// see exactly what changed and when, beyond the coarse audit events.
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+// Domain errors
+var (
+	ErrNotPermitted = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to view configuration changes")
+)
+
+// Resources whose configuration changes are recorded
+const (
+	ResourceClient = audit.ResourceClient
+	ResourceTenant = audit.ResourceTenant
+)
+
+// Redacted replaces the values of secret fields in a Change.
+const Redacted = "[REDACTED]"
+
+// DefaultListLimit is the number of entries returned per query
+const DefaultListLimit = 100
+
+// ignoredFields change on every write and carry no configuration.
+var ignoredFields = []string{"created_at", "updated_at"}
+
+// secretMarkers identify fields whose values are never recorded.
+var secretMarkers = []string{"secret", "password", "private_key", "_hash"}
+
+// Change is one field's value before and after an update.
+type Change struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Entry is one recorded configuration change.
+//
+// Purpose: Field-level answer to "what changed and when" for one resource.
+// Domain: Platform
+// Invariants: Entries are append-only and have at least one Change. Secret values
+// appear only as Redacted.
+type Entry struct {
+	ID         string    `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	Resource   string    `json:"resource"`
+	ResourceID string    `json:"resource_id"`
+	ActorID    string    `json:"actor_id,omitempty"`
+	Changes    []Change  `json:"changes"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Repository defines persistence for the change log.
+//
+// Purpose: Append-only storage of configuration changes.
+// Domain: Platform
+type Repository interface {
+	// Append stores a new entry
+	Append(ctx context.Context, e *Entry) error
+	// ListByResource returns the newest entries of one resource of a tenant, newest first
+	ListByResource(ctx context.Context, tenantID, resource, resourceID string, limit int) ([]*Entry, error)
+}
+
+// Diff returns the fields whose JSON encoding differs between before and after, sorted
+// by field name. Fields hidden from JSON are never compared, timestamps are ignored, and
+// the values of secret fields, at any depth, are replaced by Redacted.
+func Diff(before, after any) ([]Change, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := slices.Collect(maps.Keys(b))
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var changes []Change
+	for _, name := range names {
+		if slices.Contains(ignoredFields, name) || reflect.DeepEqual(b[name], a[name]) {
+			continue
+		}
+		if isSecretField(name) {
+			changes = append(changes, Change{Field: name, Before: Redacted, After: Redacted})
+			continue
+		}
+		changes = append(changes, Change{Field: name, Before: redact(b[name]), After: redact(a[name])})
+	}
+	return changes, nil
+}
+
+// fields decodes the JSON object encoding of v.
+func fields(v any) (map[string]any, error) {
+	out := make(map[string]any)
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return out, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	return out, nil
+}
+
+// redact replaces secret values nested in v.
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, inner := range v {
+			if isSecretField(k) {
+				v[k] = Redacted
+			} else {
+				v[k] = redact(inner)
+			}
+		}
+	case []any:
+		for i, inner := range v {
+			v[i] = redact(inner)
+		}
+	}
+	return v
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, m := range secretMarkers {
+		if strings.Contains(name, m) {
+			return true
+		}
+	}
+	return false
+}
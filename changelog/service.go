@@ -0,0 +1,132 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// Service records and queries configuration changes.
+//
+// Purpose: Change log behind client and tenant settings updates.
+// Domain: Platform
+// Invariants: An update that changes no recorded field leaves no entry.
+type Service struct {
+	repo        Repository
+	permissions PermissionChecker
+	clock       clock.Clock
+	ids         id.Generator
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithClock reads the current time from c for entry timestamps.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// WithIDGenerator mints entry IDs with g. Defaults to id.UUIDv7.
+func WithIDGenerator(g id.Generator) Option {
+	return func(s *Service) { s.ids = g }
+}
+
+// NewService creates a new change log service.
+//
+// Purpose: Constructor for the configuration change log.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func NewService(repo Repository, permissions PermissionChecker, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		permissions: permissions,
+		clock:       clock.System(),
+		ids:         id.UUIDv7(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Record appends the diff between before and after of one resource, attributed to actorID.
+//
+// Purpose: Called by services after a configuration update has been persisted.
+// Domain: Platform
+// Security: Secret fields are recorded only as Redacted.
+// Audited: No
+// Errors: None; like audit logging, a failure to record is logged and does not undo
+// the update it describes.
+func (s *Service) Record(ctx context.Context, tenantID, resource, resourceID, actorID string, before, after any) {
+	changes, err := Diff(before, after)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to diff configuration change", "resource", resource, "resource_id", resourceID, "error", err)
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	e := &Entry{
+		ID:         s.ids.NewID(),
+		TenantID:   tenantID,
+		Resource:   resource,
+		ResourceID: resourceID,
+		ActorID:    requestctx.ResolveUserID(ctx, actorID),
+		Changes:    changes,
+		CreatedAt:  s.clock.Now(),
+	}
+	if err := s.repo.Append(ctx, e); err != nil {
+		slog.ErrorContext(ctx, "failed to record configuration change", "resource", resource, "resource_id", resourceID, "error", err)
+	}
+}
+
+// List returns the newest recorded changes of one client or tenant, newest first.
+//
+// Purpose: "What changed and when" view for tenant administrators.
+// Domain: Platform
+// Security: Requires PermTenantViewAudit in the tenant.
+// Audited: No
+// Errors: ErrNotPermitted, System errors
+func (s *Service) List(ctx context.Context, tenantID, actorID, resource, resourceID string) ([]*Entry, error) {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopeTenant, &tenantID, policy.PermTenantViewAudit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !ok {
+		return nil, ErrNotPermitted
+	}
+
+	entries, err := s.repo.ListByResource(ctx, tenantID, resource, resourceID, DefaultListLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configuration changes: %w", err)
+	}
+	return entries, nil
+}
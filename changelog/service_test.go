@@ -0,0 +1,178 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type mockRepo struct {
+	entries []*Entry
+	err     error
+}
+
+func (m *mockRepo) Append(ctx context.Context, e *Entry) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *mockRepo) ListByResource(ctx context.Context, tenantID, resource, resourceID string, limit int) ([]*Entry, error) {
+	var res []*Entry
+	for i := len(m.entries) - 1; i >= 0 && len(res) < limit; i-- {
+		e := m.entries[i]
+		if e.TenantID == tenantID && e.Resource == resource && e.ResourceID == resourceID {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+type mockPermissions map[string][]string
+
+func (m mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	for _, p := range m[userID] {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type settings struct {
+	Name       string            `json:"name"`
+	Scopes     []string          `json:"scopes"`
+	Secret     string            `json:"-"`
+	SecretHash string            `json:"secret_hash,omitempty"`
+	Mapping    map[string]any    `json:"mapping,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	base := settings{Name: "web", Scopes: []string{"openid"}, SecretHash: "h1", UpdatedAt: time.Unix(1, 0)}
+
+	tests := []struct {
+		name   string
+		before any
+		after  func(s settings) any
+		want   []Change
+	}{
+		{
+			name:   "unchanged apart from timestamp",
+			before: base,
+			after:  func(s settings) any { s.UpdatedAt = time.Unix(2, 0); return s },
+		},
+		{
+			name:   "changed fields sorted by name",
+			before: base,
+			after:  func(s settings) any { s.Scopes = []string{"openid", "email"}; s.Name = "portal"; return s },
+			want: []Change{
+				{Field: "name", Before: "web", After: "portal"},
+				{Field: "scopes", Before: []any{"openid"}, After: []any{"openid", "email"}},
+			},
+		},
+		{
+			name:   "secret fields are redacted",
+			before: base,
+			after:  func(s settings) any { s.SecretHash = "h2"; s.Secret = "plain"; return s },
+			want:   []Change{{Field: "secret_hash", Before: Redacted, After: Redacted}},
+		},
+		{
+			name:   "nested secrets are redacted",
+			before: base,
+			after:  func(s settings) any { s.Mapping = map[string]any{"api_password": "p", "team": "id"}; return s },
+			want:   []Change{{Field: "mapping", After: map[string]any{"api_password": Redacted, "team": "id"}}},
+		},
+		{
+			name:   "created resource",
+			before: (*settings)(nil),
+			after:  func(s settings) any { return &settings{Name: "web"} },
+			want:   []Change{{Field: "name", After: "web"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after(base))
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordAndList(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &mockRepo{}
+	perms := mockPermissions{"auditor": {policy.PermTenantViewAudit}, "viewer": {policy.PermTenantView}}
+	svc := NewService(repo, perms, WithClock(clock.Fixed(now)))
+
+	before := settings{Name: "web"}
+	svc.Record(ctx, "t1", ResourceClient, "web", "admin", before, before)
+	if len(repo.entries) != 0 {
+		t.Fatalf("unchanged update recorded %d entries, want 0", len(repo.entries))
+	}
+	svc.Record(ctx, "t1", ResourceClient, "web", "admin", before, settings{Name: "portal"})
+	svc.Record(ctx, "t1", ResourceTenant, "t1", "admin", before, settings{Name: "Acme"})
+
+	repo.err = errors.New("db down")
+	svc.Record(ctx, "t1", ResourceClient, "web", "admin", before, settings{Name: "lost"})
+
+	tests := []struct {
+		name    string
+		actor   string
+		wantErr error
+		want    int
+	}{
+		{"auditor lists one resource", "auditor", nil, 1},
+		{"viewer without audit permission", "viewer", ErrNotPermitted, 0},
+		{"anonymous", "", ErrNotPermitted, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := svc.List(ctx, "t1", tt.actor, ResourceClient, "web")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("List() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(entries) != tt.want {
+				t.Fatalf("List() = %d entries, want %d", len(entries), tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+			e := entries[0]
+			if e.ActorID != "admin" || !e.CreatedAt.Equal(now) || e.ID == "" {
+				t.Errorf("entry = %+v", e)
+			}
+			if len(e.Changes) != 1 || e.Changes[0] != (Change{Field: "name", Before: "web", After: "portal"}) {
+				t.Errorf("changes = %+v, want name web -> portal", e.Changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// ErrInvalidClaimMapping is returned for claim mappings that would alter protocol claims.
+var ErrInvalidClaimMapping = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid claim mapping")
+
+// Claims added by ClaimMapping's Include options.
+const (
+	ClaimRoles    = "roles"
+	ClaimTenantID = "tenant_id"
+	ClaimProjects = "projects"
+)
+
+// protectedClaims are registered JWT/OIDC claims and the claims resource servers rely on.
+// A claim mapping may neither produce nor rename them.
+var protectedClaims = []string{
+	"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "nonce", "azp", "auth_time",
+	"at_hash", "c_hash", "s_hash", "sid", "acr", "amr", "cnf", "scope", "client_id",
+	ClaimTenantID,
+}
+
+// ClaimMapping customizes the claims of tokens issued to one client.
+//
+// Purpose: Lets relying parties with fixed claim expectations integrate without code changes.
+// Domain: OAuth2
+// Invariants: Never renames, overrides, or emits protected claims (iss, sub, aud, exp,
+// tenant_id, ...); tenant_id is only ever set from the token's own tenant. Rename targets
+// are unique.
+type ClaimMapping struct {
+	// Rename maps a claim name to the name the relying party expects.
+	Rename map[string]string `json:"rename,omitempty"`
+	// IncludeRoles adds the user's role names in the client's tenant as "roles".
+	IncludeRoles bool `json:"include_roles,omitempty"`
+	// IncludeTenantID adds "tenant_id".
+	IncludeTenantID bool `json:"include_tenant_id,omitempty"`
+	// IncludeProjects adds the IDs of the projects the user has access to as "projects".
+	IncludeProjects bool `json:"include_projects,omitempty"`
+	// Static claims are added verbatim to every token.
+	Static map[string]any `json:"static,omitempty"`
+}
+
+// ClaimContext is the subject data a token builder supplies to ClaimMapping.Apply.
+type ClaimContext struct {
+	TenantID string
+	Roles    []string
+	Projects []string
+}
+
+// Validate checks that the mapping leaves protected claims untouched.
+func (m *ClaimMapping) Validate() error {
+	targets := make(map[string]bool, len(m.Rename))
+	for from, to := range m.Rename {
+		if from == "" || to == "" || from == to {
+			return fmt.Errorf("%w: rename %q to %q", ErrInvalidClaimMapping, from, to)
+		}
+		if isProtectedClaim(from) || isProtectedClaim(to) {
+			return fmt.Errorf("%w: %q is a protected claim", ErrInvalidClaimMapping, protectedOf(from, to))
+		}
+		if targets[to] {
+			return fmt.Errorf("%w: more than one claim renamed to %q", ErrInvalidClaimMapping, to)
+		}
+		targets[to] = true
+	}
+	for name := range m.Static {
+		if name == "" || isProtectedClaim(name) {
+			return fmt.Errorf("%w: static claim %q", ErrInvalidClaimMapping, name)
+		}
+	}
+	return nil
+}
+
+// Apply returns claims with the mapping applied. claims is not modified.
+//
+// Purpose: Single transformation used by both the ID token and access token builders.
+// Domain: OAuth2
+// Security: Protected claims in claims pass through unchanged even if the stored
+// mapping was written before a claim became protected.
+// Audited: No
+// Errors: None
+func (m *ClaimMapping) Apply(claims map[string]any, cc ClaimContext) map[string]any {
+	out := maps.Clone(claims)
+	if out == nil {
+		out = make(map[string]any)
+	}
+	if m == nil {
+		return out
+	}
+
+	for name, value := range m.Static {
+		if !isProtectedClaim(name) {
+			out[name] = value
+		}
+	}
+	if m.IncludeRoles {
+		out[ClaimRoles] = slices.Clone(cc.Roles)
+	}
+	if m.IncludeProjects {
+		out[ClaimProjects] = slices.Clone(cc.Projects)
+	}
+	if m.IncludeTenantID && cc.TenantID != "" {
+		out[ClaimTenantID] = cc.TenantID
+	}
+
+	renamed := make(map[string]any, len(m.Rename))
+	for from, to := range m.Rename {
+		if isProtectedClaim(from) || isProtectedClaim(to) {
+			continue
+		}
+		if value, ok := out[from]; ok {
+			renamed[to] = value
+			delete(out, from)
+		}
+	}
+	maps.Copy(out, renamed)
+	return out
+}
+
+func isProtectedClaim(name string) bool {
+	return slices.Contains(protectedClaims, name)
+}
+
+func protectedOf(a, b string) string {
+	if isProtectedClaim(a) {
+		return a
+	}
+	return b
+}
@@ -16,28 +16,58 @@ package client
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/id"
 )
 
 // Domain errors (Internal)
 var (
-	ErrClientNotFound           = errors.New("client not found")
-	ErrClientAlreadyExists      = errors.New("client already exists")
-	ErrDomainInvalidRedirectURI = errors.New("invalid redirect URI")
-	ErrDomainInvalidScope       = errors.New("invalid scope")
-	ErrDomainInvalidGrantType   = errors.New("invalid grant type")
-	ErrCodeExpired              = errors.New("authorization code expired")
-	ErrCodeAlreadyUsed          = errors.New("authorization code already used")
-	ErrCodeNotFound             = errors.New("authorization code not found")
-	ErrDomainInvalidClient      = errors.New("invalid client credentials")
-	ErrTokenExpired             = errors.New("token expired")
-	ErrTokenRevoked             = errors.New("token revoked")
-	ErrTokenNotFound            = errors.New("token not found")
+	ErrClientNotFound           = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, apperror.OAuth2InvalidClient, "client not found")
+	ErrClientAlreadyExists      = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "client already exists")
+	ErrDomainInvalidRedirectURI = apperror.New(apperror.CodeInvalidRedirectURI, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "invalid redirect URI")
+	ErrDomainInvalidScope       = apperror.New(apperror.CodeInvalidScope, apperror.StatusBadRequest, apperror.OAuth2InvalidScope, "invalid scope")
+	ErrDomainInvalidGrantType   = apperror.New(apperror.CodeInvalidGrantType, apperror.StatusBadRequest, apperror.OAuth2UnauthorizedClient, "invalid grant type")
+	ErrCodeExpired              = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "authorization code expired")
+	ErrCodeAlreadyUsed          = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "authorization code already used")
+	ErrCodeNotFound             = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "authorization code not found")
+	ErrCodeRedirectMismatch     = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "redirect URI does not match the authorization request")
+	ErrDomainInvalidClient      = apperror.New(apperror.CodeInvalidClient, apperror.StatusUnauthorized, apperror.OAuth2InvalidClient, "invalid client credentials")
+	ErrTokenExpired             = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "token expired")
+	ErrTokenRevoked             = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "token revoked")
+	ErrTokenNotFound            = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "token not found")
+	ErrTrustNotPermitted        = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to change client trust")
+	ErrClientTenantMismatch     = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "client tenant does not match the request tenant")
+	ErrFamilyNotFound           = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "token family not found")
+	ErrUsageUnavailable         = apperror.New(apperror.CodeInternal, apperror.StatusInternalServerError, "", "client usage tracking is not configured")
 )
 
+// DuplicateClientError reports that a client_id is already registered.
+//
+// Purpose: Typed conflict for client creation, so callers that generated the
+// client_id can retry with a fresh one and callers that chose it can report it.
+// Domain: OAuth2
+// Invariants: Unwraps to ErrClientAlreadyExists. Returned whether the client_id is
+// taken in the same tenant or another one, so the error never reveals which.
+type DuplicateClientError struct {
+	TenantID string
+	ClientID string
+}
+
+// Error returns the sentinel's message with the conflicting client_id.
+func (e *DuplicateClientError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrClientAlreadyExists.Error(), e.ClientID)
+}
+
+// Unwrap returns ErrClientAlreadyExists.
+func (e *DuplicateClientError) Unwrap() error { return ErrClientAlreadyExists }
+
 // OIDC Standard Scope Constants
 const (
 	ScopeOpenID        = "openid"
@@ -86,33 +116,90 @@ func ValidateOIDCScopes(scopes []string) error {
 	return nil
 }
 
+// Application types
+const (
+	// ApplicationTypeWeb is a server-side application that can keep a secret
+	ApplicationTypeWeb = "web"
+	// ApplicationTypeNative is an installed desktop or mobile application
+	ApplicationTypeNative = "native"
+	// ApplicationTypeSPA is a browser-based single-page application
+	ApplicationTypeSPA = "spa"
+)
+
+// Token endpoint authentication methods
+const (
+	AuthMethodClientSecretBasic = "client_secret_basic"
+	AuthMethodClientSecretPost  = "client_secret_post"
+	// AuthMethodNone marks a public client: it holds no secret and must use PKCE
+	AuthMethodNone = "none"
+)
+
+// Subject identifier types (OpenID Connect Core 1.0 Section 8)
+const (
+	// SubjectTypePublic gives every client the user's ID as "sub"
+	SubjectTypePublic = "public"
+	// SubjectTypePairwise gives each sector its own "sub", so clients of different
+	// sectors cannot correlate a user
+	SubjectTypePairwise = "pairwise"
+)
+
 // Client represents an OAuth2 client application.
 //
 // Purpose: Entity representing a third-party application or service using OIDC/OAuth2.
 // Domain: OAuth2
-// Invariants: ClientID must be unique. RedirectURIs must be valid.
+// Invariants: ClientID must be unique. RedirectURIs must be valid. AllowedOrigins are
+// bare origins (scheme://host[:port]). Native clients have no AllowedOrigins. Public
+// clients (TokenEndpointAuthMethod "none") have no ClientSecretHash; native and SPA
+// clients are always public. IsTrusted marks a first-party application whose users are
+// not asked for consent; only holders of policy.PermTenantTrustClients may change it.
+// AllowedCIDRs are canonical network prefixes restricting where token requests may come
+// from (empty means anywhere). DPoPBoundAccessTokens (RFC 9449) and
+// TLSClientCertificateBoundAccessTokens (RFC 8705) forbid unbound bearer tokens.
+// ClaimMapping, when set, customizes ID and access token claims. IDTokenSignedResponseAlg,
+// when set, must be the tenant's signing algorithm. Resources lists the APIs the client
+// may request audience-restricted access tokens for. RegistrationTokenHash is set only
+// for dynamically registered clients and hashes their registration access token.
+// PasswordGrantEnabled opts a legacy integration into the password grant, which its
+// tenant must allow as well; dynamic registration never sets it. JWTAccessTokens
+// switches the client from opaque access tokens to signed JWTs (RFC 9068). SubjectType
+// "pairwise" gives the client a "sub" derived for its sector: SectorIdentifier, a host
+// shared by related clients, or else the single host of its redirect URIs.
 type Client struct {
-	ID                      string     `json:"id"`
-	ClientID                string     `json:"client_id"`
-	TenantID                string     `json:"tenant_id"`
-	ClientSecretHash        string     `json:"-"`
-	ClientName              string     `json:"client_name"`
-	ClientURI               string     `json:"client_uri,omitempty"`
-	LogoURI                 string     `json:"logo_uri,omitempty"`
-	RedirectURIs            []string   `json:"redirect_uris"`
-	AllowedScopes           []string   `json:"allowed_scopes"`
-	GrantTypes              []string   `json:"grant_types"`
-	ResponseTypes           []string   `json:"response_types"`
-	TokenEndpointAuthMethod string     `json:"token_endpoint_auth_method"`
-	AccessTokenLifetime     int        `json:"access_token_lifetime"`
-	RefreshTokenLifetime    int        `json:"refresh_token_lifetime"`
-	IDTokenLifetime         int        `json:"id_token_lifetime"`
-	OwnerID                 string     `json:"owner_id,omitempty"`
-	IsTrusted               bool       `json:"is_trusted"`
-	IsActive                bool       `json:"is_active"`
-	CreatedAt               time.Time  `json:"created_at"`
-	UpdatedAt               time.Time  `json:"updated_at"`
-	DeletedAt               *time.Time `json:"deleted_at,omitempty"`
+	ID                                    string        `json:"id"`
+	ClientID                              string        `json:"client_id"`
+	TenantID                              string        `json:"tenant_id"`
+	ClientSecretHash                      string        `json:"-"`
+	ClientName                            string        `json:"client_name"`
+	ClientURI                             string        `json:"client_uri,omitempty"`
+	LogoURI                               string        `json:"logo_uri,omitempty"`
+	RedirectURIs                          []string      `json:"redirect_uris"`
+	AllowedScopes                         []string      `json:"allowed_scopes"`
+	GrantTypes                            []string      `json:"grant_types"`
+	ResponseTypes                         []string      `json:"response_types"`
+	AllowedOrigins                        []string      `json:"allowed_origins"`
+	ApplicationType                       string        `json:"application_type"`
+	Contacts                              []string      `json:"contacts,omitempty"`
+	AllowedCIDRs                          []string      `json:"allowed_cidrs,omitempty"`
+	DPoPBoundAccessTokens                 bool          `json:"dpop_bound_access_tokens"`
+	TLSClientCertificateBoundAccessTokens bool          `json:"tls_client_certificate_bound_access_tokens"`
+	JWTAccessTokens                       bool          `json:"jwt_access_tokens"`
+	ClaimMapping                          *ClaimMapping `json:"claim_mapping,omitempty"`
+	IDTokenSignedResponseAlg              string        `json:"id_token_signed_response_alg,omitempty"`
+	SubjectType                           string        `json:"subject_type"`
+	SectorIdentifier                      string        `json:"sector_identifier,omitempty"`
+	Resources                             []Resource    `json:"resources,omitempty"`
+	TokenEndpointAuthMethod               string        `json:"token_endpoint_auth_method"`
+	RegistrationTokenHash                 string        `json:"-"`
+	AccessTokenLifetime                   int           `json:"access_token_lifetime"`
+	RefreshTokenLifetime                  int           `json:"refresh_token_lifetime"`
+	IDTokenLifetime                       int           `json:"id_token_lifetime"`
+	OwnerID                               string        `json:"owner_id,omitempty"`
+	IsTrusted                             bool          `json:"is_trusted"`
+	PasswordGrantEnabled                  bool          `json:"password_grant_enabled"`
+	IsActive                              bool          `json:"is_active"`
+	CreatedAt                             time.Time     `json:"created_at"`
+	UpdatedAt                             time.Time     `json:"updated_at"`
+	DeletedAt                             *time.Time    `json:"deleted_at,omitempty"`
 }
 
 // ValidateRedirectURI checks if the redirect URI is allowed for this client
@@ -125,6 +212,74 @@ func (c *Client) ValidateRedirectURI(redirectURI string) bool {
 	return false
 }
 
+// AllowsOrigin reports whether browser requests from origin may be answered with CORS headers
+func (c *Client) AllowsOrigin(origin string) bool {
+	return slices.Contains(c.AllowedOrigins, strings.ToLower(origin))
+}
+
+// IsPublic reports whether the client cannot hold a secret and authenticates with "none"
+func (c *Client) IsPublic() bool {
+	return c.TokenEndpointAuthMethod == AuthMethodNone
+}
+
+// RequiresPKCE reports whether authorization requests from this client must carry a PKCE
+// challenge. Public, native, and browser-based applications cannot protect a client secret.
+func (c *Client) RequiresPKCE() bool {
+	return c.IsPublic() || c.ApplicationType == ApplicationTypeNative || c.ApplicationType == ApplicationTypeSPA
+}
+
+// IsPairwise reports whether the client receives pairwise subject identifiers
+func (c *Client) IsPairwise() bool {
+	return c.SubjectType == SubjectTypePairwise
+}
+
+// Sector returns the sector the client's pairwise subject identifiers are derived for:
+// SectorIdentifier, or else the host all redirect URIs share. It is empty when neither
+// applies.
+func (c *Client) Sector() string {
+	if c.SectorIdentifier != "" {
+		return c.SectorIdentifier
+	}
+	var sector string
+	for _, uri := range c.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || u.Hostname() == "" {
+			return ""
+		}
+		host := strings.ToLower(u.Hostname())
+		if sector != "" && host != sector {
+			return ""
+		}
+		sector = host
+	}
+	return sector
+}
+
+// VerifySecret checks a presented client secret. Public clients never authenticate
+// with a secret, so any secret presented for them is rejected.
+func (c *Client) VerifySecret(secret string) bool {
+	if c.IsPublic() {
+		return false
+	}
+	return VerifyClientSecret(secret, c.ClientSecretHash)
+}
+
+// UsesImplicitFlow reports whether the client receives tokens directly from
+// the authorization endpoint: the implicit grant, a response type carrying an
+// access token, or the OIDC "id_token" response type on its own.
+func (c *Client) UsesImplicitFlow() bool {
+	if slices.Contains(c.GrantTypes, "implicit") {
+		return true
+	}
+	for _, rt := range c.ResponseTypes {
+		parts := strings.Fields(rt)
+		if slices.Contains(parts, "token") || (len(parts) == 1 && parts[0] == "id_token") {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateScope checks if the requested scope is allowed for this client
 func (c *Client) ValidateScope(requestedScope string) bool {
 	if requestedScope == "" {
@@ -156,9 +311,14 @@ func (c *Client) ValidateScope(requestedScope string) bool {
 // Purpose: One-time use token for exchanging with an access token.
 // Domain: OAuth2
 // Invariants: Code must be a cryptographically secure token. Must expire within 10 minutes.
+// Redeemable only in the tenant and by the client it was issued to. Deleted with the session
+// it was issued under. GrantID is minted with the code (NewGrantID) and inherited by
+// every token issued from it.
 type AuthorizationCode struct {
 	ID                  string
 	Code                string
+	TenantID            string
+	SessionID           string
 	ClientID            string
 	UserID              string
 	RedirectURI         string
@@ -167,22 +327,72 @@ type AuthorizationCode struct {
 	Nonce               string
 	CodeChallenge       string
 	CodeChallengeMethod string
+	GrantID             string
 	ExpiresAt           time.Time
 	UsedAt              *time.Time
 	IsUsed              bool
 	CreatedAt           time.Time
 }
 
+// NewGrantID returns the identifier of a new authorization grant. It is minted when a
+// code is issued, or at the token endpoint for grants without one (client credentials).
+func NewGrantID() string {
+	return id.NewUUIDv7()
+}
+
 // IsExpired checks if the authorization code has expired
 func (a *AuthorizationCode) IsExpired() bool {
-	return time.Now().After(a.ExpiresAt)
+	return a.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt checks if the authorization code has expired at now
+func (a *AuthorizationCode) IsExpiredAt(now time.Time) bool {
+	return now.After(a.ExpiresAt)
+}
+
+// Validate checks that the code can be redeemed by clientID in tenantID with redirectURI
+// and codeVerifier under the default PKCE policy, which accepts S256 only.
+func (a *AuthorizationCode) Validate(tenantID, clientID, redirectURI, codeVerifier string) error {
+	return a.ValidateWithPolicy(PKCEPolicy{}, tenantID, clientID, redirectURI, codeVerifier)
+}
+
+// ValidateWithPolicy checks that the code can be redeemed by clientID in tenantID with
+// redirectURI and codeVerifier.
+//
+// Purpose: Single redemption check for the token endpoint.
+// Domain: OAuth2
+// Security: A code from another tenant or client is reported as not found so its existence is not disclosed.
+// The PKCE verifier must match the challenge bound to the code, by a method pkce accepts.
+// Audited: No
+// Errors: ErrCodeNotFound, ErrCodeAlreadyUsed, ErrCodeExpired, ErrCodeRedirectMismatch,
+// *VerifierError (ErrInvalidCodeVerifier), ErrUnsupportedChallengeMode
+func (a *AuthorizationCode) ValidateWithPolicy(pkce PKCEPolicy, tenantID, clientID, redirectURI, codeVerifier string) error {
+	return a.ValidateAt(time.Now(), pkce, tenantID, clientID, redirectURI, codeVerifier)
+}
+
+// ValidateAt is ValidateWithPolicy with expiry judged at now, for callers with an injected clock.
+func (a *AuthorizationCode) ValidateAt(now time.Time, pkce PKCEPolicy, tenantID, clientID, redirectURI, codeVerifier string) error {
+	if a.TenantID == "" || a.TenantID != tenantID || a.ClientID != clientID {
+		return ErrCodeNotFound
+	}
+	if a.IsUsed {
+		return ErrCodeAlreadyUsed
+	}
+	if a.IsExpiredAt(now) {
+		return ErrCodeExpired
+	}
+	if a.RedirectURI != redirectURI {
+		return ErrCodeRedirectMismatch
+	}
+	return pkce.VerifyCodeVerifier(a.CodeChallenge, a.CodeChallengeMethod, codeVerifier)
 }
 
 // AccessToken represents an OAuth2 access token.
 //
 // Purpose: Credential for accessing protected resources.
 // Domain: OAuth2
-// Invariants: TokenHash must be unique. Lifetime is configurable.
+// Invariants: TokenHash must be unique. Lifetime is configurable. GrantID is the grant
+// the token was issued under.
 type AccessToken struct {
 	ID        string
 	TenantID  string
@@ -191,22 +401,43 @@ type AccessToken struct {
 	UserID    string
 	Scope     string
 	TokenType string
-	ExpiresAt time.Time
-	RevokedAt *time.Time
-	IsRevoked bool
-	CreatedAt time.Time
+	GrantID   string
+	// DPoPJKT binds the token to a DPoP key (cnf.jkt); empty for bearer tokens.
+	DPoPJKT string
+	// CertThumbprint binds the token to a TLS client certificate (cnf.x5t#S256).
+	CertThumbprint string
+	ExpiresAt      time.Time
+	RevokedAt      *time.Time
+	IsRevoked      bool
+	CreatedAt      time.Time
+
+	// Audience restricts the token to one resource server (aud); empty for a token
+	// usable at every resource the client may reach.
+	Audience string
 }
 
 // IsExpired checks if the access token has expired
 func (a *AccessToken) IsExpired() bool {
-	return time.Now().After(a.ExpiresAt)
+	return a.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt checks if the access token has expired at now
+func (a *AccessToken) IsExpiredAt(now time.Time) bool {
+	return now.After(a.ExpiresAt)
+}
+
+// MatchesHash reports, in constant time, whether tokenHash is this token's stored hash
+func (a *AccessToken) MatchesHash(tokenHash string) bool {
+	return crypto.ConstantTimeEqualString(a.TokenHash, tokenHash)
 }
 
 // RefreshToken represents an OAuth2 refresh token.
 //
 // Purpose: Long-lived credential to obtain new access tokens.
 // Domain: OAuth2
-// Invariants: Associated with a specific client and user.
+// Invariants: Associated with a specific client and user. FamilyID, when set, names the
+// grant the token belongs to; ParentID is the token it was rotated from. GrantID is
+// inherited from the code or parent token, so rotation never starts a new grant.
 type RefreshToken struct {
 	ID            string
 	TenantID      string
@@ -215,6 +446,9 @@ type RefreshToken struct {
 	ClientID      string
 	UserID        string
 	Scope         string
+	FamilyID      string
+	ParentID      string
+	GrantID       string
 	ExpiresAt     time.Time
 	RevokedAt     *time.Time
 	IsRevoked     bool
@@ -223,7 +457,49 @@ type RefreshToken struct {
 
 // IsExpired checks if the refresh token has expired
 func (r *RefreshToken) IsExpired() bool {
-	return time.Now().After(r.ExpiresAt)
+	return r.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt checks if the refresh token has expired at now
+func (r *RefreshToken) IsExpiredAt(now time.Time) bool {
+	return now.After(r.ExpiresAt)
+}
+
+// MatchesHash reports, in constant time, whether tokenHash is this token's stored hash
+func (r *RefreshToken) MatchesHash(tokenHash string) bool {
+	return crypto.ConstantTimeEqualString(r.TokenHash, tokenHash)
+}
+
+// Family revocation reasons
+const (
+	FamilyRevokedLogout     = "logout"
+	FamilyRevokedReuse      = "reuse_detected"
+	FamilyRevokedAdmin      = "admin"
+	FamilyRevokedAllDevices = "all_devices"
+)
+
+// RefreshTokenFamily groups the refresh tokens rotated from one original grant.
+//
+// Purpose: Unit of refresh token revocation and reuse detection, bound to one device.
+// Domain: OAuth2
+// Invariants: All tokens of a family share its tenant, client, and user. Once revoked a
+// family never becomes active again and none of its tokens may be redeemed.
+type RefreshTokenFamily struct {
+	ID           string
+	TenantID     string
+	ClientID     string
+	UserID       string
+	DeviceID     string
+	DeviceName   string
+	CreatedAt    time.Time
+	LastUsedAt   time.Time
+	RevokedAt    *time.Time
+	RevokeReason string
+}
+
+// IsRevoked reports whether the family has been revoked
+func (f *RefreshTokenFamily) IsRevoked() bool {
+	return f.RevokedAt != nil
 }
 
 // ClientRepository defines the interface for OAuth2 client persistence.
@@ -231,10 +507,10 @@ func (r *RefreshToken) IsExpired() bool {
 // Purpose: Abstraction for managing persistent storage of client metadata.
 // Domain: OAuth2
 type ClientRepository interface {
-	// Create creates a new OAuth2 client
+	// Create creates a new OAuth2 client; a taken client_id fails with *DuplicateClientError
 	Create(ctx context.Context, client *Client) error
 
-	// GetByClientID retrieves a client by tenant_id and client_id
+	// GetByClientID retrieves a client by tenant_id and client_id; there is no cross-tenant lookup
 	GetByClientID(ctx context.Context, tenantID string, clientID string) (*Client, error)
 
 	// GetByID retrieves a client by tenant_id and internal ID
@@ -264,10 +540,10 @@ type AuthorizationCodeRepository interface {
 	// Create creates a new authorization code
 	Create(code *AuthorizationCode) error
 
-	// GetByCode retrieves an authorization code
-	GetByCode(code string) (*AuthorizationCode, error)
+	// GetByCode retrieves an authorization code issued in tenantID
+	GetByCode(tenantID, code string) (*AuthorizationCode, error)
 
-	// MarkAsUsed marks the code as used
+	// MarkAsUsed marks the code as used; a second call fails with ErrCodeAlreadyUsed
 	MarkAsUsed(code string) error
 
 	// Delete deletes an authorization code
@@ -290,6 +566,9 @@ type AccessTokenRepository interface {
 
 	// DeleteExpired deletes all expired access tokens
 	DeleteExpired() error
+
+	// ListByGrant retrieves the access tokens issued under one authorization grant, oldest first
+	ListByGrant(tenantID, grantID string) ([]*AccessToken, error)
 }
 
 // RefreshTokenRepository defines the interface for refresh token persistence
@@ -303,6 +582,27 @@ type RefreshTokenRepository interface {
 	// Revoke revokes a refresh token
 	Revoke(tokenHash string) error
 
-	// DeleteExpired deletes all expired refresh tokens
+	// DeleteExpired deletes all expired refresh tokens and families left without tokens
 	DeleteExpired() error
+
+	// ListByGrant retrieves the refresh tokens issued under one authorization grant, oldest first
+	ListByGrant(tenantID, grantID string) ([]*RefreshToken, error)
+
+	// CreateFamily creates a new refresh token family
+	CreateFamily(family *RefreshTokenFamily) error
+
+	// GetFamily retrieves a family by ID
+	GetFamily(tenantID, familyID string) (*RefreshTokenFamily, error)
+
+	// TouchFamily records that a token of the family was redeemed
+	TouchFamily(familyID string) error
+
+	// ListFamiliesByUser retrieves the active families of a user, one per signed-in device
+	ListFamiliesByUser(tenantID, userID string) ([]*RefreshTokenFamily, error)
+
+	// RevokeFamily revokes a family and every token in it
+	RevokeFamily(tenantID, familyID, reason string) error
+
+	// RevokeFamiliesByUser revokes every active family of a user and their tokens
+	RevokeFamiliesByUser(tenantID, userID, reason string) (int, error)
 }
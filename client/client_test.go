@@ -0,0 +1,1396 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"iter"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/changelog"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+func TestAuthorizationCodeValidate(t *testing.T) {
+	valid := func() *AuthorizationCode {
+		return &AuthorizationCode{
+			TenantID:    "tenant-a",
+			SessionID:   "session-1",
+			ClientID:    "client-1",
+			RedirectURI: "https://app.example.com/cb",
+			ExpiresAt:   time.Now().Add(time.Minute),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		mutate      func(*AuthorizationCode)
+		tenantID    string
+		clientID    string
+		redirectURI string
+		wantErr     error
+	}{
+		{"valid", nil, "tenant-a", "client-1", "https://app.example.com/cb", nil},
+		{"other tenant", nil, "tenant-b", "client-1", "https://app.example.com/cb", ErrCodeNotFound},
+		{"unbound code", func(c *AuthorizationCode) { c.TenantID = "" }, "", "client-1", "https://app.example.com/cb", ErrCodeNotFound},
+		{"other client", nil, "tenant-a", "client-2", "https://app.example.com/cb", ErrCodeNotFound},
+		{"used", func(c *AuthorizationCode) { c.IsUsed = true }, "tenant-a", "client-1", "https://app.example.com/cb", ErrCodeAlreadyUsed},
+		{"expired", func(c *AuthorizationCode) { c.ExpiresAt = time.Now().Add(-time.Second) }, "tenant-a", "client-1", "https://app.example.com/cb", ErrCodeExpired},
+		{"redirect mismatch", nil, "tenant-a", "client-1", "https://evil.example.com/cb", ErrCodeRedirectMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := valid()
+			if tt.mutate != nil {
+				tt.mutate(code)
+			}
+			if err := code.Validate(tt.tenantID, tt.clientID, tt.redirectURI, ""); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateClientMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  Client
+		wantErr error
+	}{
+		{"web defaults", Client{}, nil},
+		{"spa origins", Client{ApplicationType: ApplicationTypeSPA, TokenEndpointAuthMethod: AuthMethodNone, AllowedOrigins: []string{"https://app.example.com", "http://localhost:3000"}}, nil},
+		{"unknown type", Client{ApplicationType: "mobile"}, ErrInvalidApplicationType},
+		{"native with origins", Client{ApplicationType: ApplicationTypeNative, TokenEndpointAuthMethod: AuthMethodNone, AllowedOrigins: []string{"https://app.example.com"}}, ErrInvalidOrigin},
+		{"origin with path", Client{AllowedOrigins: []string{"https://app.example.com/cb"}}, ErrInvalidOrigin},
+		{"origin with trailing slash", Client{AllowedOrigins: []string{"https://app.example.com/"}}, ErrInvalidOrigin},
+		{"wildcard origin", Client{AllowedOrigins: []string{"*"}}, ErrInvalidOrigin},
+		{"upper-case origin", Client{AllowedOrigins: []string{"https://App.example.com"}}, ErrInvalidOrigin},
+		{"plain http origin", Client{AllowedOrigins: []string{"http://app.example.com"}}, ErrInvalidOrigin},
+		{"confidential spa", Client{ApplicationType: ApplicationTypeSPA, TokenEndpointAuthMethod: AuthMethodClientSecretBasic}, ErrInvalidAuthMethod},
+		{"public with secret", Client{TokenEndpointAuthMethod: AuthMethodNone, ClientSecretHash: "hash"}, ErrInvalidAuthMethod},
+		{"public client_credentials", Client{TokenEndpointAuthMethod: AuthMethodNone, GrantTypes: []string{"client_credentials"}}, ErrDomainInvalidGrantType},
+		{"contact", Client{Contacts: []string{"ops@example.com"}}, nil},
+		{"named contact", Client{Contacts: []string{"Ops <ops@example.com>"}}, ErrInvalidContact},
+		{"bad contact", Client{Contacts: []string{"not-an-email"}}, ErrInvalidContact},
+		{"cidrs", Client{AllowedCIDRs: []string{"203.0.113.0/24", "2001:db8::/32", "198.51.100.7/32"}}, nil},
+		{"bare address cidr", Client{AllowedCIDRs: []string{"203.0.113.7"}}, ErrInvalidCIDR},
+		{"unmasked cidr", Client{AllowedCIDRs: []string{"203.0.113.7/24"}}, ErrInvalidCIDR},
+		{"claim mapping", Client{ClaimMapping: &ClaimMapping{Rename: map[string]string{"email": "mail"}, Static: map[string]any{"org": "acme"}}}, nil},
+		{"rename to protected claim", Client{ClaimMapping: &ClaimMapping{Rename: map[string]string{"email": "sub"}}}, ErrInvalidClaimMapping},
+		{"rename protected claim", Client{ClaimMapping: &ClaimMapping{Rename: map[string]string{"tenant_id": "org_id"}}}, ErrInvalidClaimMapping},
+		{"rename collision", Client{ClaimMapping: &ClaimMapping{Rename: map[string]string{"email": "mail", "upn": "mail"}}}, ErrInvalidClaimMapping},
+		{"static protected claim", Client{ClaimMapping: &ClaimMapping{Static: map[string]any{"iss": "https://evil.example.com"}}}, ErrInvalidClaimMapping},
+		{"signing alg", Client{IDTokenSignedResponseAlg: "PS256"}, nil},
+		{"symmetric signing alg", Client{IDTokenSignedResponseAlg: "HS256"}, ErrInvalidSigningAlg},
+		{"none signing alg", Client{IDTokenSignedResponseAlg: "none"}, ErrInvalidSigningAlg},
+		{"resources", Client{AllowedScopes: []string{"orders:read"}, Resources: []Resource{{URI: "https://orders.example.com", Scopes: []string{"orders:read"}}}}, nil},
+		{"relative resource", Client{AllowedScopes: []string{"*"}, Resources: []Resource{{URI: "/orders", Scopes: []string{"orders:read"}}}}, ErrInvalidResource},
+		{"resource with fragment", Client{AllowedScopes: []string{"*"}, Resources: []Resource{{URI: "https://orders.example.com#v1", Scopes: []string{"orders:read"}}}}, ErrInvalidResource},
+		{"duplicate resource", Client{AllowedScopes: []string{"*"}, Resources: []Resource{{URI: "https://orders.example.com", Scopes: []string{"a"}}, {URI: "https://orders.example.com", Scopes: []string{"b"}}}}, ErrInvalidResource},
+		{"resource without scopes", Client{AllowedScopes: []string{"*"}, Resources: []Resource{{URI: "https://orders.example.com"}}}, ErrInvalidResource},
+		{"resource scope not allowed", Client{AllowedScopes: []string{"orders:read"}, Resources: []Resource{{URI: "https://orders.example.com", Scopes: []string{"orders:write"}}}}, ErrInvalidResource},
+		{"pairwise by redirect host", Client{SubjectType: SubjectTypePairwise, RedirectURIs: []string{"https://app.example.com/cb", "https://app.example.com/logout"}}, nil},
+		{"pairwise with sector", Client{SubjectType: SubjectTypePairwise, SectorIdentifier: "example.com", RedirectURIs: []string{"https://a.example.com/cb", "https://b.example.com/cb"}}, nil},
+		{"pairwise without sector", Client{SubjectType: SubjectTypePairwise, RedirectURIs: []string{"https://a.example.com/cb", "https://b.example.com/cb"}}, ErrInvalidClientMetadata},
+		{"sector with scheme", Client{SubjectType: SubjectTypePairwise, SectorIdentifier: "https://example.com"}, ErrInvalidClientMetadata},
+		{"unknown subject type", Client{SubjectType: "random"}, ErrInvalidClientMetadata},
+	}
+	svc := NewService(nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.ValidateClient(context.Background(), &tt.client); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateClient() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type mockLogoFetcher struct {
+	contentType string
+	data        []byte
+	addrs       []netip.Addr
+}
+
+func (m *mockLogoFetcher) FetchLogo(ctx context.Context, uri string, addrs []netip.Addr, maxBytes int64) (string, []byte, error) {
+	m.addrs = addrs
+	return m.contentType, m.data, nil
+}
+
+type mockResolver map[string][]netip.Addr
+
+func (m mockResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	return m[host], nil
+}
+
+type mockScopeRegistry map[string][]string
+
+func (m mockScopeRegistry) Scopes(ctx context.Context, tenantID string) ([]string, error) {
+	return m[tenantID], nil
+}
+
+func TestValidateRegistration(t *testing.T) {
+	tests := []struct {
+		name       string
+		client     Client
+		wantFields []string
+	}{
+		{"valid", Client{TenantID: "t1", RedirectURIs: []string{"https://app.example.com/cb"}, GrantTypes: []string{GrantTypeAuthorizationCode}, ResponseTypes: []string{"code"}, AllowedScopes: []string{"openid", "orders:read"}}, nil},
+		{
+			name: "every invalid field is reported",
+			client: Client{
+				TenantID:                "t1",
+				RedirectURIs:            []string{"https://app.example.com/cb", "not a uri"},
+				ApplicationType:         ApplicationTypeSPA,
+				TokenEndpointAuthMethod: AuthMethodClientSecretPost,
+				GrantTypes:              []string{GrantTypeRefreshToken, "device_code"},
+				ResponseTypes:           []string{"code"},
+				AllowedScopes:           []string{"openid", "billing", "bad\\scope"},
+				Contacts:                []string{"not-an-email"},
+			},
+			wantFields: []string{"redirect_uris[1]", "token_endpoint_auth_method", "grant_types", "response_types[0]", "allowed_scopes[1]", "allowed_scopes[2]", "contacts[0]"},
+		},
+		{"authorization_code needs a redirect_uri", Client{TenantID: "t1", GrantTypes: []string{GrantTypeAuthorizationCode}}, []string{"redirect_uris"}},
+		{"unsupported response type", Client{TenantID: "t1", RedirectURIs: []string{"https://app.example.com/cb"}, GrantTypes: []string{GrantTypeAuthorizationCode}, ResponseTypes: []string{"code code", "device"}}, []string{"response_types[0]", "response_types[1]"}},
+		{"public clients cannot use delegated grants", Client{TenantID: "t1", TokenEndpointAuthMethod: AuthMethodNone, GrantTypes: []string{GrantTypeDelegated}}, []string{"grant_types"}},
+	}
+	svc := NewService(nil, nil, WithScopeRegistry(mockScopeRegistry{"t1": {"orders:read"}}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := svc.ValidateRegistration(context.Background(), &tt.client)
+			if err != nil {
+				t.Fatalf("ValidateRegistration() error = %v", err)
+			}
+			var fields []string
+			for _, issue := range issues {
+				if _, ok := apperror.As(issue); !ok {
+					t.Errorf("issue %v is not a classified error", issue)
+				}
+				fields = append(fields, issue.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("ValidateRegistration() fields = %v, want %v", fields, tt.wantFields)
+			}
+
+			err = svc.ValidateClient(context.Background(), &tt.client)
+			if (len(issues) == 0 && err != nil) || (len(issues) > 0 && (err == nil || err.Error() != issues[0].Error())) {
+				t.Errorf("ValidateClient() error = %v, want the first issue", err)
+			}
+		})
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	for _, tmpl := range Templates() {
+		t.Run(string(tmpl), func(t *testing.T) {
+			c := &Client{
+				TenantID: "t1", ClientName: "App", ClientSecretHash: "hash",
+				RedirectURIs: []string{"https://app.example.com/cb"}, GrantTypes: []string{"implicit"}, AccessTokenLifetime: 60,
+			}
+			if err := tmpl.Apply(c); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if c.IsPublic() && (!c.RequiresPKCE() || c.ClientSecretHash != "") {
+				t.Errorf("public template: RequiresPKCE = %v, secret kept = %v", c.RequiresPKCE(), c.ClientSecretHash != "")
+			}
+			if slices.Contains(c.GrantTypes, "implicit") || c.AccessTokenLifetime != 60 {
+				t.Errorf("Apply() = %+v, want template grant types and the caller's lifetime", c)
+			}
+
+			svc := NewService(&mockClientRepo{clients: map[string]*Client{}}, &recordingAuditLogger{})
+			issues, err := svc.ValidateRegistration(context.Background(), c)
+			if err != nil || len(issues) != 0 {
+				t.Errorf("ValidateRegistration() = %v, %v, want a valid client", issues, err)
+			}
+		})
+	}
+
+	c := &Client{RedirectURIs: []string{"https://app.example.com/cb"}}
+	if err := TemplateMachine.Apply(c); err != nil || len(c.RedirectURIs) != 0 || c.RefreshTokenLifetime != 0 || c.IsPublic() {
+		t.Errorf("machine template = %+v, %v", c, err)
+	}
+	if err := TemplateSPA.Apply(c); err != nil || c.AccessTokenLifetime != 3600 || c.RefreshTokenLifetime != 86400 {
+		t.Errorf("lifetimes already set must be kept: %+v, %v", c, err)
+	}
+	if _, err := NewService(nil, nil).RegisterFromTemplate(context.Background(), "t1", "", "kiosk", &Client{}); !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("RegisterFromTemplate() error = %v, want %v", err, ErrUnknownTemplate)
+	}
+}
+
+func TestURIPolicy(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	resolver := mockResolver{
+		"cdn.example.com":      {netip.MustParseAddr("203.0.113.10")},
+		"internal.example.com": {netip.MustParseAddr("10.0.0.5")},
+		"metadata.example.com": {netip.MustParseAddr("169.254.169.254")},
+	}
+	tests := []struct {
+		name    string
+		policy  URIPolicy
+		client  Client
+		wantErr error
+	}{
+		{"https", URIPolicy{}, Client{ClientURI: "https://app.example.com", LogoURI: "https://cdn.example.com/logo.png"}, nil},
+		{"plain http client_uri", URIPolicy{}, Client{ClientURI: "http://app.example.com"}, ErrInvalidClientURI},
+		{"plain http logo", URIPolicy{}, Client{LogoURI: "http://cdn.example.com/logo.png"}, ErrInvalidLogoURI},
+		{"insecure allows http", URIPolicy{AllowInsecure: true}, Client{LogoURI: "http://localhost:8080/logo.png"}, nil},
+		{"credentials", URIPolicy{}, Client{LogoURI: "https://user:pw@cdn.example.com/logo.png"}, ErrInvalidLogoURI},
+		{"relative logo", URIPolicy{}, Client{LogoURI: "/logo.png"}, ErrInvalidLogoURI},
+		{"loopback literal", URIPolicy{}, Client{LogoURI: "https://127.0.0.1/logo.png"}, ErrInvalidLogoURI},
+		{"private literal", URIPolicy{}, Client{ClientURI: "https://192.168.1.1"}, ErrInvalidClientURI},
+		{"localhost", URIPolicy{}, Client{LogoURI: "https://localhost/logo.png"}, ErrInvalidLogoURI},
+		{"allowed host", URIPolicy{AllowedHosts: []string{"cdn.example.com"}}, Client{LogoURI: "https://cdn.example.com/logo.png"}, nil},
+		{"allowed subdomain", URIPolicy{AllowedHosts: []string{"*.example.com"}}, Client{ClientURI: "https://app.example.com"}, nil},
+		{"wildcard excludes apex", URIPolicy{AllowedHosts: []string{"*.example.com"}}, Client{ClientURI: "https://example.com"}, ErrInvalidClientURI},
+		{"host not allowed", URIPolicy{AllowedHosts: []string{"cdn.example.com"}}, Client{LogoURI: "https://evil.example.net/logo.png"}, ErrInvalidLogoURI},
+		{"fetched logo", URIPolicy{Fetcher: &mockLogoFetcher{contentType: "image/png", data: png}, Resolver: resolver}, Client{LogoURI: "https://cdn.example.com/logo.png"}, nil},
+		{"fetched non-image", URIPolicy{Fetcher: &mockLogoFetcher{contentType: "image/png", data: []byte("<svg/>")}, Resolver: resolver}, Client{LogoURI: "https://cdn.example.com/logo.png"}, ErrInvalidLogoURI},
+		{"fetched type mismatch", URIPolicy{Fetcher: &mockLogoFetcher{contentType: "image/jpeg", data: png}, Resolver: resolver}, Client{LogoURI: "https://cdn.example.com/logo.png"}, ErrInvalidLogoURI},
+		{"fetched too large", URIPolicy{Fetcher: &mockLogoFetcher{contentType: "image/png", data: png}, Resolver: resolver, MaxLogoSize: 4}, Client{LogoURI: "https://cdn.example.com/logo.png"}, ErrInvalidLogoURI},
+		{"resolves to private", URIPolicy{Fetcher: &mockLogoFetcher{}, Resolver: resolver}, Client{LogoURI: "https://internal.example.com/logo.png"}, ErrInvalidLogoURI},
+		{"resolves to link-local", URIPolicy{Fetcher: &mockLogoFetcher{}, Resolver: resolver}, Client{LogoURI: "https://metadata.example.com/logo.png"}, ErrInvalidLogoURI},
+		{"does not resolve", URIPolicy{Fetcher: &mockLogoFetcher{}, Resolver: resolver}, Client{LogoURI: "https://nowhere.example.com/logo.png"}, ErrInvalidLogoURI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(nil, nil, WithURIPolicy(tt.policy))
+			if err := svc.ValidateClient(context.Background(), &tt.client); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateClient() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	fetcher := &mockLogoFetcher{contentType: "image/png", data: png}
+	svc := NewService(nil, nil, WithURIPolicy(URIPolicy{Fetcher: fetcher, Resolver: resolver}))
+	svc.ValidateClient(context.Background(), &Client{LogoURI: "https://cdn.example.com/logo.png"})
+	if len(fetcher.addrs) != 1 || fetcher.addrs[0] != resolver["cdn.example.com"][0] {
+		t.Errorf("fetcher got addrs %v, want the checked resolution", fetcher.addrs)
+	}
+}
+
+func TestResourceTokens(t *testing.T) {
+	c := &Client{Resources: []Resource{
+		{URI: "https://orders.example.com", Scopes: []string{"orders:read", "orders:write"}},
+		{URI: "https://billing.example.com", Scopes: []string{"billing:read"}},
+	}}
+	tests := []struct {
+		name      string
+		scope     string
+		resources []string
+		want      []ResourceToken
+		wantErr   error
+	}{
+		{"no resources", "openid orders:read", nil, nil, nil},
+		{"filtered per resource", "openid orders:read billing:read", []string{"https://orders.example.com", "https://billing.example.com"}, []ResourceToken{
+			{Audience: "https://orders.example.com", Scope: "orders:read"},
+			{Audience: "https://billing.example.com", Scope: "billing:read"},
+		}, nil},
+		{"duplicate resource", "orders:read orders:write", []string{"https://orders.example.com", "https://orders.example.com"}, []ResourceToken{
+			{Audience: "https://orders.example.com", Scope: "orders:read orders:write"},
+		}, nil},
+		{"unregistered resource", "orders:read", []string{"https://evil.example.com"}, nil, ErrInvalidTarget},
+		{"no applicable scope", "orders:read", []string{"https://billing.example.com"}, nil, ErrInvalidTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ResourceTokens(tt.scope, tt.resources)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResourceTokens() error = %v, want %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ResourceTokens() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	many := &Client{}
+	var uris []string
+	for i := range MaxResourcesPerRequest + 1 {
+		uri := fmt.Sprintf("https://api%d.example.com", i)
+		many.Resources = append(many.Resources, Resource{URI: uri, Scopes: []string{"read"}})
+		uris = append(uris, uri)
+	}
+	if _, err := many.ResourceTokens("read", uris); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("ResourceTokens() over the limit error = %v, want ErrInvalidTarget", err)
+	}
+}
+
+type mockSigningAlgs map[string]string
+
+func (m mockSigningAlgs) SigningAlgorithm(ctx context.Context, tenantID string) (string, error) {
+	return m[tenantID], nil
+}
+
+func TestValidateClientSigningAlg(t *testing.T) {
+	svc := NewService(nil, nil, WithSigningAlgorithms(mockSigningAlgs{"t1": "ES256"}))
+	tests := []struct {
+		alg     string
+		wantErr error
+	}{
+		{"", nil},
+		{"ES256", nil},
+		{"RS256", ErrInvalidSigningAlg},
+	}
+	for _, tt := range tests {
+		c := &Client{TenantID: "t1", IDTokenSignedResponseAlg: tt.alg}
+		if err := svc.ValidateClient(context.Background(), c); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateClient(%q) error = %v, want %v", tt.alg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestClientSecurityDefaults(t *testing.T) {
+	tests := []struct {
+		appType  string
+		wantPKCE bool
+	}{
+		{ApplicationTypeWeb, false},
+		{ApplicationTypeNative, true},
+		{ApplicationTypeSPA, true},
+	}
+	for _, tt := range tests {
+		c := &Client{ApplicationType: tt.appType, AllowedOrigins: []string{"https://app.example.com"}}
+		if got := c.RequiresPKCE(); got != tt.wantPKCE {
+			t.Errorf("%s: RequiresPKCE() = %v, want %v", tt.appType, got, tt.wantPKCE)
+		}
+		if !c.AllowsOrigin("https://APP.example.com") || c.AllowsOrigin("https://evil.example.com") {
+			t.Errorf("%s: AllowsOrigin() mismatch", tt.appType)
+		}
+	}
+}
+
+func TestCheckTokenRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  Client
+		req     TokenRequest
+		wantErr error
+	}{
+		{"no policy", Client{}, TokenRequest{RemoteIP: "198.51.100.1"}, nil},
+		{"inside cidr", Client{AllowedCIDRs: []string{"203.0.113.0/24"}}, TokenRequest{RemoteIP: "203.0.113.9"}, nil},
+		{"mapped ipv4 inside cidr", Client{AllowedCIDRs: []string{"203.0.113.0/24"}}, TokenRequest{RemoteIP: "::ffff:203.0.113.9"}, nil},
+		{"outside cidr", Client{AllowedCIDRs: []string{"203.0.113.0/24"}}, TokenRequest{RemoteIP: "198.51.100.1"}, ErrSourceNotAllowed},
+		{"unknown source", Client{AllowedCIDRs: []string{"203.0.113.0/24"}}, TokenRequest{}, ErrSourceNotAllowed},
+		{"dpop missing", Client{DPoPBoundAccessTokens: true}, TokenRequest{}, ErrDPoPRequired},
+		{"dpop present", Client{DPoPBoundAccessTokens: true}, TokenRequest{DPoPJKT: "jkt"}, nil},
+		{"mtls missing", Client{TLSClientCertificateBoundAccessTokens: true}, TokenRequest{DPoPJKT: "jkt"}, ErrMTLSRequired},
+		{"mtls present", Client{TLSClientCertificateBoundAccessTokens: true}, TokenRequest{CertThumbprint: "x5t"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.client.CheckTokenRequest(tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckTokenRequest() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClaimMappingApply(t *testing.T) {
+	claims := map[string]any{"sub": "u1", "iss": "https://id.example.com", "email": "a@example.com"}
+	cc := ClaimContext{TenantID: "t1", Roles: []string{"admin"}, Projects: []string{"p1"}}
+
+	var none *ClaimMapping
+	if got := none.Apply(claims, cc); len(got) != len(claims) {
+		t.Errorf("nil mapping changed claims: %v", got)
+	}
+
+	m := &ClaimMapping{
+		Rename:          map[string]string{"email": "mail", "roles": "groups"},
+		IncludeRoles:    true,
+		IncludeTenantID: true,
+		IncludeProjects: true,
+		// Stored before "iss" was protected, or written around Validate.
+		Static: map[string]any{"org": "acme", "iss": "https://evil.example.com"},
+	}
+	got := m.Apply(claims, cc)
+
+	want := map[string]any{
+		"sub": "u1", "iss": "https://id.example.com", "mail": "a@example.com",
+		"groups": []string{"admin"}, "projects": []string{"p1"}, "tenant_id": "t1", "org": "acme",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Apply() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if fmt.Sprint(got[k]) != fmt.Sprint(v) {
+			t.Errorf("claim %q = %v, want %v", k, got[k], v)
+		}
+	}
+	if _, ok := claims["mail"]; ok {
+		t.Error("Apply() modified its input")
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	// RFC 7636 Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	public := &Client{TokenEndpointAuthMethod: AuthMethodNone}
+	confidential := &Client{TokenEndpointAuthMethod: AuthMethodClientSecretBasic}
+
+	challengeTests := []struct {
+		name      string
+		client    *Client
+		challenge string
+		method    string
+		wantErr   error
+	}{
+		{"public S256", public, challenge, CodeChallengeMethodS256, nil},
+		{"public without challenge", public, "", "", ErrPKCERequired},
+		{"confidential without challenge", confidential, "", "", nil},
+		{"plain", confidential, verifier, "plain", ErrUnsupportedChallengeMode},
+		{"method without challenge", confidential, "", CodeChallengeMethodS256, ErrInvalidCodeChallenge},
+		{"malformed challenge", public, "short", CodeChallengeMethodS256, ErrInvalidCodeChallenge},
+	}
+	for _, tt := range challengeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.client.CheckCodeChallenge(tt.challenge, tt.method); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckCodeChallenge() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	verifierTests := []struct {
+		name      string
+		challenge string
+		verifier  string
+		wantErr   error
+	}{
+		{"match", challenge, verifier, nil},
+		{"mismatch", challenge, verifier[:42] + "A", ErrInvalidCodeVerifier},
+		{"missing", challenge, "", ErrInvalidCodeVerifier},
+		{"unexpected", "", verifier, ErrInvalidCodeVerifier},
+		{"none", "", "", nil},
+	}
+	for _, tt := range verifierTests {
+		t.Run("verify "+tt.name, func(t *testing.T) {
+			code := &AuthorizationCode{
+				TenantID:            "tenant-a",
+				ClientID:            "client-1",
+				ExpiresAt:           time.Now().Add(time.Minute),
+				CodeChallenge:       tt.challenge,
+				CodeChallengeMethod: CodeChallengeMethodS256,
+			}
+			if err := code.Validate("tenant-a", "client-1", "", tt.verifier); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if public.VerifySecret("") {
+		t.Error("public clients must not authenticate with a secret")
+	}
+}
+
+func TestPKCEPolicy(t *testing.T) {
+	// RFC 7636 Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	plain := PKCEPolicy{AllowPlain: true}
+
+	if err := plain.CheckCodeChallenge(&Client{}, verifier, CodeChallengeMethodPlain); err != nil {
+		t.Errorf("CheckCodeChallenge(plain) error = %v", err)
+	}
+	if err := plain.CheckCodeChallenge(&Client{}, "short", CodeChallengeMethodPlain); !errors.Is(err, ErrInvalidCodeChallenge) {
+		t.Errorf("CheckCodeChallenge(short plain) error = %v, want %v", err, ErrInvalidCodeChallenge)
+	}
+
+	tests := []struct {
+		name       string
+		policy     PKCEPolicy
+		challenge  string
+		method     string
+		verifier   string
+		wantErr    error
+		wantReason string
+	}{
+		{"S256 match", PKCEPolicy{}, challenge, CodeChallengeMethodS256, verifier, nil, ""},
+		{"S256 mismatch", PKCEPolicy{}, challenge, CodeChallengeMethodS256, verifier[:42] + "A", ErrInvalidCodeVerifier, PKCEVerifierMismatch},
+		{"plain match", plain, verifier, CodeChallengeMethodPlain, verifier, nil, ""},
+		{"plain mismatch", plain, verifier, CodeChallengeMethodPlain, verifier[:42] + "A", ErrInvalidCodeVerifier, PKCEVerifierMismatch},
+		{"plain disabled", PKCEPolicy{}, verifier, CodeChallengeMethodPlain, verifier, ErrUnsupportedChallengeMode, ""},
+		{"missing", plain, challenge, CodeChallengeMethodS256, "", ErrInvalidCodeVerifier, PKCEVerifierMissing},
+		{"malformed", plain, challenge, CodeChallengeMethodS256, "short", ErrInvalidCodeVerifier, PKCEVerifierMalformed},
+		{"unexpected", plain, "", "", verifier, ErrInvalidCodeVerifier, PKCEVerifierUnexpected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.VerifyCodeVerifier(tt.challenge, tt.method, tt.verifier)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyCodeVerifier() error = %v, want %v", err, tt.wantErr)
+			}
+			reason := ""
+			var verr *VerifierError
+			if errors.As(err, &verr) {
+				reason = verr.Reason
+			}
+			if reason != tt.wantReason {
+				t.Errorf("VerifierError reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+// mockClientRepo implements the ClientRepository calls used by trust changes.
+type mockClientRepo struct {
+	ClientRepository
+	clients map[string]*Client
+}
+
+func (m *mockClientRepo) GetByID(ctx context.Context, tenantID, id string) (*Client, error) {
+	c, ok := m.clients[id]
+	if !ok || c.TenantID != tenantID {
+		return nil, ErrClientNotFound
+	}
+	cp := *c
+	return &cp, nil
+}
+
+func (m *mockClientRepo) Create(ctx context.Context, c *Client) error {
+	for _, existing := range m.clients {
+		if existing.ClientID == c.ClientID {
+			return &DuplicateClientError{TenantID: c.TenantID, ClientID: c.ClientID}
+		}
+	}
+	cp := *c
+	m.clients[c.ID] = &cp
+	return nil
+}
+
+func (m *mockClientRepo) GetByClientID(ctx context.Context, tenantID, clientID string) (*Client, error) {
+	for _, c := range m.clients {
+		if c.ClientID == clientID && c.TenantID == tenantID {
+			cp := *c
+			return &cp, nil
+		}
+	}
+	return nil, ErrClientNotFound
+}
+
+func (m *mockClientRepo) Delete(ctx context.Context, tenantID, id string) error {
+	if _, ok := m.clients[id]; !ok {
+		return ErrClientNotFound
+	}
+	delete(m.clients, id)
+	return nil
+}
+
+func (m *mockClientRepo) Update(ctx context.Context, c *Client) error {
+	cp := *c
+	m.clients[c.ID] = &cp
+	return nil
+}
+
+func (m *mockClientRepo) ListByTenant(ctx context.Context, tenantID string) ([]*Client, error) {
+	var out []*Client
+	for _, c := range m.clients {
+		if c.TenantID == tenantID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+type mockPermissions map[string]bool
+
+func (m mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return scope == role.ScopeTenant && permission == policy.PermTenantTrustClients && m[userID+"@"+*scopeContextID], nil
+}
+
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Log(_ context.Context, e audit.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestSetTrusted(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions PermissionChecker
+		actorID     string
+		wantErr     error
+	}{
+		{"owner", mockPermissions{"owner@t1": true}, "owner", nil},
+		{"owner of another tenant", mockPermissions{"owner@t2": true}, "owner", ErrTrustNotPermitted},
+		{"no permission checker", nil, "owner", ErrTrustNotPermitted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockClientRepo{clients: map[string]*Client{"c1": {ID: "c1", ClientID: "app", TenantID: "t1"}}}
+			logger := &recordingAuditLogger{}
+			var opts []Option
+			if tt.permissions != nil {
+				opts = append(opts, WithPermissions(tt.permissions))
+			}
+			svc := NewService(repo, logger, opts...)
+
+			err := svc.SetTrusted(context.Background(), "t1", "c1", true, tt.actorID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetTrusted() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.clients["c1"].IsTrusted != (tt.wantErr == nil) {
+				t.Errorf("IsTrusted = %v", repo.clients["c1"].IsTrusted)
+			}
+
+			trustEvents := 0
+			for _, e := range logger.events {
+				if e.Type == audit.TypeClientTrustChanged {
+					trustEvents++
+				}
+			}
+			if want := map[bool]int{true: 1, false: 0}[tt.wantErr == nil]; trustEvents != want {
+				t.Errorf("trust audit events = %d, want %d", trustEvents, want)
+			}
+		})
+	}
+}
+
+func TestRegisterClientTenant(t *testing.T) {
+	tests := []struct {
+		name     string
+		tenantID string
+		trusted  bool
+		wantErr  error
+	}{
+		{"trusted client in the actor's tenant", "t1", true, nil},
+		{"trusted client in another tenant", "t2", true, ErrClientTenantMismatch},
+		{"untrusted client in another tenant", "t2", false, ErrClientTenantMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockClientRepo{clients: map[string]*Client{}}
+			svc := NewService(repo, &recordingAuditLogger{}, WithPermissions(mockPermissions{"owner@t1": true}))
+
+			_, err := svc.RegisterClient(context.Background(), "t1", "owner", &Client{
+				TenantID: tt.tenantID, ClientName: "App", IsTrusted: tt.trusted,
+				RedirectURIs: []string{"https://app.example.com/cb"}, GrantTypes: []string{GrantTypeAuthorizationCode},
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RegisterClient() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(repo.clients); got != map[bool]int{true: 1, false: 0}[tt.wantErr == nil] {
+				t.Errorf("stored %d clients", got)
+			}
+		})
+	}
+}
+
+type recordingChangeLog struct {
+	resourceIDs []string
+	changes     [][]changelog.Change
+}
+
+func (r *recordingChangeLog) Record(ctx context.Context, tenantID, resource, resourceID, actorID string, before, after any) {
+	changes, _ := changelog.Diff(before, after)
+	r.resourceIDs = append(r.resourceIDs, resourceID)
+	r.changes = append(r.changes, changes)
+}
+
+func TestClientChangeLog(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockClientRepo{clients: map[string]*Client{"c1": {ID: "c1", ClientID: "app", TenantID: "t1", ClientName: "App"}}}
+	log := &recordingChangeLog{}
+	svc := NewService(repo, &recordingAuditLogger{}, WithChangeLog(log), WithPermissions(mockPermissions{"owner@t1": true}))
+
+	if err := svc.SetTrusted(ctx, "t1", "c1", true, "owner"); err != nil {
+		t.Fatalf("SetTrusted() error = %v", err)
+	}
+	if _, err := svc.IssueRegistrationToken(ctx, "t1", "c1", "owner"); err != nil {
+		t.Fatalf("IssueRegistrationToken() error = %v", err)
+	}
+
+	want := [][]changelog.Change{
+		{{Field: "is_trusted", Before: false, After: true}},
+		{{Field: "registration_token_hash", Before: changelog.Redacted, After: changelog.Redacted}},
+	}
+	if !slices.Equal(log.resourceIDs, []string{"app", "app"}) || !reflect.DeepEqual(log.changes, want) {
+		t.Errorf("change log = %v %+v, want %+v", log.resourceIDs, log.changes, want)
+	}
+}
+
+type mockReasonPolicy map[string]bool
+
+func (m mockReasonPolicy) ReasonRequired(ctx context.Context, tenantID, operation string) (bool, error) {
+	return m[tenantID+"@"+operation], nil
+}
+
+func TestSetTrustedReasonPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     mockReasonPolicy
+		reason     string
+		wantErr    error
+		wantReason string
+	}{
+		{"not required", mockReasonPolicy{}, "", nil, ""},
+		{"required and given", mockReasonPolicy{"t1@" + audit.OpClientTrust: true}, "  first-party portal  ", nil, "first-party portal"},
+		{"required and missing", mockReasonPolicy{"t1@" + audit.OpClientTrust: true}, "", audit.ErrReasonRequired, ""},
+		{"other tenant requires", mockReasonPolicy{"t2@" + audit.OpClientTrust: true}, "", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockClientRepo{clients: map[string]*Client{"c1": {ID: "c1", ClientID: "app", TenantID: "t1"}}}
+			logger := &recordingAuditLogger{}
+			svc := NewService(repo, logger, WithPermissions(mockPermissions{"owner@t1": true}), WithReasonPolicy(tt.policy))
+
+			ctx := context.Background()
+			if tt.reason != "" {
+				ctx = audit.WithReason(ctx, tt.reason)
+			}
+			err := svc.SetTrusted(ctx, "t1", "c1", true, "owner")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetTrusted() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, e := range logger.events {
+				if e.Type != audit.TypeClientTrustChanged {
+					continue
+				}
+				got, _ := e.Metadata[audit.AttrReason].(string)
+				if got != tt.wantReason {
+					t.Errorf("audited reason = %q, want %q", got, tt.wantReason)
+				}
+			}
+		})
+	}
+}
+
+type mockRegistrationPolicies map[string]RegistrationPolicy
+
+func (m mockRegistrationPolicies) RegistrationPolicy(ctx context.Context, tenantID string) (RegistrationPolicy, error) {
+	return m[tenantID], nil
+}
+
+func TestRegisterClientIDCollision(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientID     string
+		ids          []string
+		wantClientID string
+		wantErr      error
+	}{
+		{"generated client_id is redrawn", "", []string{"r1", "taken", "fresh"}, "fresh", nil},
+		{"chosen client_id is kept", "taken", []string{"r1"}, "", ErrClientAlreadyExists},
+		{"redraws are bounded", "", []string{"r1", "taken", "taken", "taken", "fresh"}, "", ErrClientAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The other tenant's client holds "taken": client_ids never repeat across tenants.
+			repo := &mockClientRepo{clients: map[string]*Client{"c0": {ID: "c0", ClientID: "taken", TenantID: "t2"}}}
+			next, stop := iter.Pull(slices.Values(tt.ids))
+			defer stop()
+			svc := NewService(repo, &recordingAuditLogger{}, WithIDGenerator(id.GeneratorFunc(func() string {
+				v, _ := next()
+				return v
+			})))
+
+			c, err := svc.RegisterClient(context.Background(), "t1", "", &Client{
+				ClientID: tt.clientID, TenantID: "t1", ClientName: "App",
+				RedirectURIs: []string{"https://app.example.com/cb"}, GrantTypes: []string{GrantTypeAuthorizationCode},
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RegisterClient() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var dup *DuplicateClientError
+				if !errors.As(err, &dup) || dup.ClientID != "taken" {
+					t.Errorf("RegisterClient() error = %v, want *DuplicateClientError for %q", err, "taken")
+				}
+				return
+			}
+			if c.ClientID != tt.wantClientID || repo.clients["r1"].ClientID != tt.wantClientID {
+				t.Errorf("client_id = %q, want %q", c.ClientID, tt.wantClientID)
+			}
+		})
+	}
+}
+
+func TestRegisterDynamic(t *testing.T) {
+	policies := mockRegistrationPolicies{
+		"open": {Enabled: true},
+		"narrow": {
+			Enabled:     true,
+			GrantTypes:  []string{GrantTypeAuthorizationCode},
+			Scopes:      []string{ScopeOpenID, "api:read"},
+			AuthMethods: []string{AuthMethodNone},
+		},
+		"closed": {},
+	}
+	web := RegistrationRequest{RedirectURIs: []string{"https://app.example.com/cb"}, ClientName: "App"}
+
+	tests := []struct {
+		name       string
+		tenantID   string
+		req        RegistrationRequest
+		wantErr    error
+		wantSecret bool
+	}{
+		{"defaults", "open", web, nil, true},
+		{"public native client", "open", RegistrationRequest{RedirectURIs: []string{"com.example.app:/cb"}, ApplicationType: ApplicationTypeNative}, nil, false},
+		{"machine client", "open", RegistrationRequest{GrantTypes: []string{GrantTypeClientCredentials}}, nil, true},
+		{"registration disabled", "closed", web, ErrRegistrationDisabled, false},
+		{"implicit grant", "open", RegistrationRequest{RedirectURIs: web.RedirectURIs, GrantTypes: []string{"implicit"}}, ErrInvalidClientMetadata, false},
+		{"token response type", "open", RegistrationRequest{RedirectURIs: web.RedirectURIs, ResponseTypes: []string{"code token"}}, ErrInvalidClientMetadata, false},
+		{"missing redirect URIs", "open", RegistrationRequest{}, ErrInvalidRegistrationURI, false},
+		{"malformed redirect URI", "open", RegistrationRequest{RedirectURIs: []string{"not a uri"}}, ErrInvalidRegistrationURI, false},
+		{"unknown scope", "open", RegistrationRequest{RedirectURIs: web.RedirectURIs, Scope: "openid admin"}, ErrDomainInvalidScope, false},
+		{"grant type outside policy", "narrow", RegistrationRequest{GrantTypes: []string{GrantTypeClientCredentials}}, ErrInvalidClientMetadata, false},
+		{"auth method outside policy", "narrow", web, ErrInvalidClientMetadata, false},
+		{"within policy", "narrow", RegistrationRequest{RedirectURIs: web.RedirectURIs, TokenEndpointAuthMethod: AuthMethodNone, Scope: "openid api:read"}, nil, false},
+		{"public client with secret-only grant", "open", RegistrationRequest{GrantTypes: []string{GrantTypeClientCredentials}, TokenEndpointAuthMethod: AuthMethodNone}, ErrInvalidClientMetadata, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockClientRepo{clients: map[string]*Client{}}
+			logger := &recordingAuditLogger{}
+			svc := NewService(repo, logger, WithRegistrationPolicies(policies), WithIDGenerator(id.Sequence()))
+
+			resp, err := svc.RegisterDynamic(context.Background(), tt.tenantID, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RegisterDynamic() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(repo.clients) != 0 {
+					t.Errorf("client persisted despite error")
+				}
+				return
+			}
+
+			var stored *Client
+			for _, c := range repo.clients {
+				stored = c
+			}
+			if len(repo.clients) != 1 || stored.ClientID != resp.ClientID || stored.TenantID != tt.tenantID {
+				t.Fatalf("stored client = %+v", stored)
+			}
+			if stored.IsTrusted || !stored.IsActive {
+				t.Errorf("IsTrusted = %v, IsActive = %v", stored.IsTrusted, stored.IsActive)
+			}
+			if (resp.ClientSecret != "") != tt.wantSecret || (resp.ClientSecretExpiresAt != nil) != tt.wantSecret {
+				t.Errorf("client_secret issued = %v, want %v", resp.ClientSecret != "", tt.wantSecret)
+			}
+			if tt.wantSecret && !stored.VerifySecret(resp.ClientSecret) {
+				t.Errorf("stored secret hash does not match the issued secret")
+			}
+			if resp.RegistrationAccessToken == "" || !VerifyClientSecret(resp.RegistrationAccessToken, stored.RegistrationTokenHash) {
+				t.Errorf("registration access token not stored as a hash")
+			}
+			if len(logger.events) != 1 || logger.events[0].Type != audit.TypeClientCreated {
+				t.Errorf("audit events = %+v", logger.events)
+			}
+		})
+	}
+}
+
+func TestRegistrationManagement(t *testing.T) {
+	ctx := context.Background()
+	policies := mockRegistrationPolicies{"t1": {Enabled: true}}
+	repo := &mockClientRepo{clients: map[string]*Client{}}
+	svc := NewService(repo, &recordingAuditLogger{}, WithRegistrationPolicies(policies))
+
+	reg, err := svc.RegisterDynamic(ctx, "t1", RegistrationRequest{RedirectURIs: []string{"https://app.example.com/cb"}, ClientName: "App"})
+	if err != nil {
+		t.Fatalf("RegisterDynamic() error = %v", err)
+	}
+	token := reg.RegistrationAccessToken
+
+	t.Run("read", func(t *testing.T) {
+		got, err := svc.ReadRegistration(ctx, "t1", reg.ClientID, token)
+		if err != nil || got.ClientName != "App" || got.ClientSecret != "" || got.RegistrationAccessToken != "" {
+			t.Fatalf("ReadRegistration() = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("rejected credentials", func(t *testing.T) {
+		for _, tc := range []struct{ tenantID, clientID, token string }{
+			{"t1", reg.ClientID, "wrong"},
+			{"t1", reg.ClientID, ""},
+			{"t1", "unknown", token},
+			{"t2", reg.ClientID, token},
+		} {
+			if _, err := svc.ReadRegistration(ctx, tc.tenantID, tc.clientID, tc.token); !errors.Is(err, ErrInvalidRegistrationToken) {
+				t.Errorf("ReadRegistration(%q, %q, %q) error = %v, want ErrInvalidRegistrationToken", tc.tenantID, tc.clientID, tc.token, err)
+			}
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		if _, err := svc.UpdateRegistration(ctx, "t1", reg.ClientID, token, RegistrationRequest{ClientID: "other", RedirectURIs: []string{"https://app.example.com/cb"}}); !errors.Is(err, ErrInvalidClientMetadata) {
+			t.Errorf("UpdateRegistration() with mismatched client_id error = %v, want ErrInvalidClientMetadata", err)
+		}
+
+		got, err := svc.UpdateRegistration(ctx, "t1", reg.ClientID, token, RegistrationRequest{
+			ClientID:                reg.ClientID,
+			RedirectURIs:            []string{"https://app.example.com/cb2"},
+			ClientName:              "Renamed",
+			TokenEndpointAuthMethod: AuthMethodNone,
+		})
+		if err != nil {
+			t.Fatalf("UpdateRegistration() error = %v", err)
+		}
+		if got.ClientName != "Renamed" || got.ClientSecret != "" || got.ClientIDIssuedAt != reg.ClientIDIssuedAt {
+			t.Errorf("UpdateRegistration() = %+v", got)
+		}
+		stored, _ := repo.GetByClientID(ctx, "t1", reg.ClientID)
+		if stored.ClientSecretHash != "" || stored.RedirectURIs[0] != "https://app.example.com/cb2" || !stored.IsActive {
+			t.Errorf("stored client = %+v", stored)
+		}
+
+		got, err = svc.UpdateRegistration(ctx, "t1", reg.ClientID, token, RegistrationRequest{RedirectURIs: stored.RedirectURIs})
+		if err != nil || got.ClientSecret == "" {
+			t.Fatalf("UpdateRegistration() to confidential = %+v, %v; want a new secret", got, err)
+		}
+	})
+
+	t.Run("update needs registration enabled", func(t *testing.T) {
+		policies["t1"] = RegistrationPolicy{}
+		defer func() { policies["t1"] = RegistrationPolicy{Enabled: true} }()
+		if _, err := svc.UpdateRegistration(ctx, "t1", reg.ClientID, token, RegistrationRequest{RedirectURIs: []string{"https://app.example.com/cb"}}); !errors.Is(err, ErrRegistrationDisabled) {
+			t.Errorf("UpdateRegistration() error = %v, want ErrRegistrationDisabled", err)
+		}
+	})
+
+	t.Run("issue replaces the token", func(t *testing.T) {
+		stored, _ := repo.GetByClientID(ctx, "t1", reg.ClientID)
+		issued, err := svc.IssueRegistrationToken(ctx, "t1", stored.ID, "admin")
+		if err != nil {
+			t.Fatalf("IssueRegistrationToken() error = %v", err)
+		}
+		if _, err := svc.ReadRegistration(ctx, "t1", reg.ClientID, token); !errors.Is(err, ErrInvalidRegistrationToken) {
+			t.Errorf("old token still accepted: %v", err)
+		}
+		token = issued
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := svc.DeleteRegistration(ctx, "t1", reg.ClientID, token); err != nil {
+			t.Fatalf("DeleteRegistration() error = %v", err)
+		}
+		if _, err := svc.ReadRegistration(ctx, "t1", reg.ClientID, token); !errors.Is(err, ErrInvalidRegistrationToken) {
+			t.Errorf("ReadRegistration() after delete error = %v, want ErrInvalidRegistrationToken", err)
+		}
+	})
+}
+
+func TestRegistrationPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RegistrationPolicy
+		wantErr bool
+	}{
+		{"zero", RegistrationPolicy{}, false},
+		{"restricted", RegistrationPolicy{Enabled: true, GrantTypes: []string{GrantTypeAuthorizationCode}, AuthMethods: []string{AuthMethodNone}, Scopes: []string{"openid"}}, false},
+		{"implicit grant", RegistrationPolicy{GrantTypes: []string{"implicit"}}, true},
+		{"unknown auth method", RegistrationPolicy{AuthMethods: []string{"private_key_jwt"}}, true},
+		{"scope with space", RegistrationPolicy{Scopes: []string{"openid email"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRegistrationPolicy) {
+				t.Errorf("error = %v, want ErrInvalidRegistrationPolicy", err)
+			}
+		})
+	}
+}
+
+type mockUsageRepo struct {
+	usage map[string]*Usage
+	fail  bool
+}
+
+func (m *mockUsageRepo) AddUsage(ctx context.Context, tenantID, clientID string, issued int64, at time.Time) error {
+	if m.fail {
+		return errors.New("unavailable")
+	}
+	u, ok := m.usage[clientID]
+	if !ok {
+		u = &Usage{TenantID: tenantID, ClientID: clientID}
+		m.usage[clientID] = u
+	}
+	u.TokensIssued += issued
+	if u.LastUsedAt == nil || at.After(*u.LastUsedAt) {
+		u.LastUsedAt = &at
+	}
+	return nil
+}
+
+func (m *mockUsageRepo) ListUsage(ctx context.Context, tenantID string) ([]*Usage, error) {
+	var out []*Usage
+	for _, u := range m.usage {
+		if u.TenantID == tenantID {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func TestUsageRecorder(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockUsageRepo{usage: map[string]*Usage{}}
+	rec := NewUsageRecorder(repo)
+	t0 := time.Now().Add(-time.Hour)
+
+	issued := func(kind, clientID string, at time.Time) events.TokenIssued {
+		return events.TokenIssued{Meta: events.Meta{TenantID: "t1", OccurredAt: at}, Kind: kind, ClientID: clientID}
+	}
+	_ = rec.HandleEvent(ctx, issued(events.TokenKindAccess, "c1", t0))
+	_ = rec.HandleEvent(ctx, issued(events.TokenKindAccess, "c1", t0.Add(time.Minute)))
+	_ = rec.HandleEvent(ctx, issued(events.TokenKindRefresh, "c1", t0.Add(2*time.Minute)))
+	_ = rec.HandleEvent(ctx, events.TokenRevoked{Meta: events.Meta{TenantID: "t1"}, ClientID: "c1"})
+
+	// A failed flush keeps the deltas for the next one.
+	repo.fail = true
+	if err := rec.Flush(ctx); err == nil {
+		t.Fatal("expected flush error")
+	}
+	repo.fail = false
+	_ = rec.HandleEvent(ctx, issued(events.TokenKindAccess, "c2", t0))
+	if err := rec.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	c1 := repo.usage["c1"]
+	if c1 == nil || c1.TokensIssued != 2 || !c1.LastUsedAt.Equal(t0.Add(time.Minute)) {
+		t.Errorf("c1 usage = %+v, want 2 tokens last used at %v", c1, t0.Add(time.Minute))
+	}
+	if c2 := repo.usage["c2"]; c2 == nil || c2.TokensIssued != 1 {
+		t.Errorf("c2 usage = %+v, want 1 token", c2)
+	}
+	if err := rec.Flush(ctx); err != nil || repo.usage["c1"].TokensIssued != 2 {
+		t.Errorf("second flush re-wrote usage: err=%v usage=%+v", err, repo.usage["c1"])
+	}
+}
+
+func TestClientUsage(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	recent, old := now.Add(-time.Hour), now.Add(-60*24*time.Hour)
+	clients := &mockClientRepo{clients: map[string]*Client{
+		"1": {ID: "1", ClientID: "active", TenantID: "t1", CreatedAt: old},
+		"2": {ID: "2", ClientID: "idle", TenantID: "t1", CreatedAt: old},
+		"3": {ID: "3", ClientID: "unused", TenantID: "t1", CreatedAt: old},
+		"4": {ID: "4", ClientID: "new", TenantID: "t1", CreatedAt: now},
+		"5": {ID: "5", ClientID: "other", TenantID: "t2", CreatedAt: old},
+	}}
+	usage := &mockUsageRepo{usage: map[string]*Usage{
+		"active": {TenantID: "t1", ClientID: "active", TokensIssued: 10, LastUsedAt: &recent},
+		"idle":   {TenantID: "t1", ClientID: "idle", TokensIssued: 3, LastUsedAt: &old},
+	}}
+
+	if _, err := NewService(clients, nil).ClientUsage(ctx, "t1", time.Hour); !errors.Is(err, ErrUsageUnavailable) {
+		t.Fatalf("without usage repo: err = %v, want ErrUsageUnavailable", err)
+	}
+
+	reports, err := NewService(clients, nil, WithUsage(usage)).ClientUsage(ctx, "t1", 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("ClientUsage: %v", err)
+	}
+	if len(reports) != 4 {
+		t.Fatalf("got %d reports, want 4", len(reports))
+	}
+	stale := map[string]bool{}
+	for _, r := range reports {
+		stale[r.Client.ClientID] = r.Stale
+	}
+	want := map[string]bool{"active": false, "idle": true, "unused": true, "new": false}
+	for id, w := range want {
+		if stale[id] != w {
+			t.Errorf("%s: Stale = %v, want %v", id, stale[id], w)
+		}
+	}
+	if last := reports[len(reports)-1]; last.Client.ClientID != "active" || last.Usage.TokensIssued != 10 {
+		t.Errorf("most recently used client should sort last, got %s (%d tokens)", last.Client.ClientID, last.Usage.TokensIssued)
+	}
+}
+
+type mockUsedCodes map[string]bool
+
+func (m mockUsedCodes) Seen(ctx context.Context, key string, expiresAt time.Time) (bool, error) {
+	seen := m[key]
+	m[key] = true
+	return seen, nil
+}
+
+func TestStatelessCodeRepository(t *testing.T) {
+	masterKey := []byte("0123456789abcdef0123456789abcdef")
+	newCode := func() *AuthorizationCode {
+		return &AuthorizationCode{
+			TenantID:            "t1",
+			SessionID:           "s1",
+			ClientID:            "c1",
+			UserID:              "u1",
+			RedirectURI:         "https://app.example.com/cb",
+			Scope:               "openid",
+			CodeChallenge:       "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+			CodeChallengeMethod: CodeChallengeMethodS256,
+			GrantID:             "g1",
+			ExpiresAt:           time.Now().Add(5 * time.Minute),
+		}
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		repo, err := NewStatelessCodeRepository(masterKey, mockUsedCodes{})
+		if err != nil {
+			t.Fatalf("NewStatelessCodeRepository() error = %v", err)
+		}
+		code := newCode()
+		if err := repo.Create(code); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		got, err := repo.GetByCode("t1", code.Code)
+		if err != nil {
+			t.Fatalf("GetByCode() error = %v", err)
+		}
+		if got.ID != code.ID || got.GrantID != "g1" || got.CodeChallenge != code.CodeChallenge || got.SessionID != "s1" {
+			t.Errorf("GetByCode() = %+v", got)
+		}
+		if err := got.Validate("t1", "c1", "https://app.example.com/cb", "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+	})
+
+	t.Run("other tenant", func(t *testing.T) {
+		repo, _ := NewStatelessCodeRepository(masterKey, mockUsedCodes{})
+		code := newCode()
+		repo.Create(code)
+		if _, err := repo.GetByCode("t2", code.Code); !errors.Is(err, ErrCodeNotFound) {
+			t.Errorf("GetByCode() error = %v, want ErrCodeNotFound", err)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		repo, _ := NewStatelessCodeRepository(masterKey, mockUsedCodes{})
+		code := newCode()
+		repo.Create(code)
+		if err := repo.MarkAsUsed(code.Code); err != nil {
+			t.Fatalf("MarkAsUsed() error = %v", err)
+		}
+		if err := repo.MarkAsUsed(code.Code); !errors.Is(err, ErrCodeAlreadyUsed) {
+			t.Errorf("second MarkAsUsed() error = %v, want ErrCodeAlreadyUsed", err)
+		}
+	})
+
+	t.Run("replay with a newline-mutated code", func(t *testing.T) {
+		repo, _ := NewStatelessCodeRepository(masterKey, mockUsedCodes{})
+		code := newCode()
+		repo.Create(code)
+		if err := repo.MarkAsUsed(code.Code); err != nil {
+			t.Fatalf("MarkAsUsed() error = %v", err)
+		}
+		parts := strings.Split(code.Code, ".")
+		for i := 2; i < len(parts); i++ {
+			mutated := slices.Clone(parts)
+			mutated[i] = mutated[i][:2] + "\n" + mutated[i][2:]
+			variant := strings.Join(mutated, ".")
+			if _, err := repo.GetByCode("t1", variant); err == nil {
+				t.Errorf("GetByCode() accepted a newline in segment %d", i)
+			}
+			if err := repo.MarkAsUsed(variant); err == nil {
+				t.Errorf("MarkAsUsed() accepted a replay with a newline in segment %d", i)
+			}
+		}
+	})
+
+	t.Run("codes do not share a replay key", func(t *testing.T) {
+		repo, _ := NewStatelessCodeRepository(masterKey, mockUsedCodes{})
+		first, second := newCode(), newCode()
+		repo.Create(first)
+		repo.Create(second)
+		if err := repo.MarkAsUsed(first.Code); err != nil {
+			t.Fatalf("MarkAsUsed() error = %v", err)
+		}
+		if err := repo.MarkAsUsed(second.Code); err != nil {
+			t.Errorf("MarkAsUsed() of another code error = %v", err)
+		}
+	})
+
+	t.Run("deleted code", func(t *testing.T) {
+		repo, _ := NewStatelessCodeRepository(masterKey, mockUsedCodes{})
+		code := newCode()
+		repo.Create(code)
+		repo.Delete(code.Code)
+		if err := repo.MarkAsUsed(code.Code); !errors.Is(err, ErrCodeAlreadyUsed) {
+			t.Errorf("MarkAsUsed() error = %v, want ErrCodeAlreadyUsed", err)
+		}
+	})
+
+	t.Run("destroyed session", func(t *testing.T) {
+		repo, _ := NewStatelessCodeRepository(masterKey, mockUsedCodes{}, WithSessionCheck(func(ctx context.Context, sessionID string) error {
+			return errors.New("session not found")
+		}))
+		code := newCode()
+		repo.Create(code)
+		if _, err := repo.GetByCode("t1", code.Code); !errors.Is(err, ErrCodeNotFound) {
+			t.Errorf("GetByCode() error = %v, want ErrCodeNotFound", err)
+		}
+	})
+
+	t.Run("lifetime over maximum", func(t *testing.T) {
+		repo, _ := NewStatelessCodeRepository(masterKey, mockUsedCodes{})
+		code := newCode()
+		code.ExpiresAt = time.Now().Add(time.Hour)
+		if err := repo.Create(code); err == nil {
+			t.Error("Create() error = nil")
+		}
+	})
+
+	t.Run("requires cache and key", func(t *testing.T) {
+		if _, err := NewStatelessCodeRepository(masterKey, nil); err == nil {
+			t.Error("NewStatelessCodeRepository(nil cache) error = nil")
+		}
+		if _, err := NewStatelessCodeRepository(nil, mockUsedCodes{}); err == nil {
+			t.Error("NewStatelessCodeRepository(nil key) error = nil")
+		}
+	})
+}
+
+func FuzzValidateRedirectURI(f *testing.F) {
+	f.Add("https://app.example.com/callback", "https://app.example.com/callback")
+	f.Add("https://app.example.com/callback", "https://app.example.com/callback/../admin")
+	f.Add("https://app.example.com/callback", "https://app.example.com/callback?next=https://evil.example")
+	f.Add("https://app.example.com/callback", "HTTPS://APP.EXAMPLE.COM/callback")
+	f.Add("https://app.example.com/callback", "https://app.example.com/callback#")
+	f.Add("com.example.app:/oauth", "com.example.app:/oauth\x00")
+	f.Add("", "")
+
+	f.Fuzz(func(t *testing.T, registered, candidate string) {
+		c := &Client{RedirectURIs: []string{registered}}
+		// Redirect URIs match exactly; no prefix, case, or path normalization.
+		if got, want := c.ValidateRedirectURI(candidate), candidate == registered; got != want {
+			t.Errorf("ValidateRedirectURI(%q) = %v with %q registered, want %v", candidate, got, registered, want)
+		}
+	})
+}
+
+func FuzzValidateScope(f *testing.F) {
+	f.Add("openid profile", "openid")
+	f.Add("openid profile", "openid email")
+	f.Add("openid", "openid\tprofile")
+	f.Add("*", "anything at all")
+	f.Add("openid", "openid profile")
+	f.Add("", "")
+
+	f.Fuzz(func(t *testing.T, allowed, requested string) {
+		c := &Client{AllowedScopes: strings.Fields(allowed)}
+		got := c.ValidateScope(requested)
+		want := true
+		if !slices.Contains(c.AllowedScopes, "*") {
+			for _, s := range strings.Fields(requested) {
+				if !slices.Contains(c.AllowedScopes, s) {
+					want = false
+				}
+			}
+		}
+		if got != want {
+			t.Errorf("ValidateScope(%q) = %v with %q allowed, want %v", requested, got, allowed, want)
+		}
+	})
+}
+
+func FuzzValidateOIDCScopes(f *testing.F) {
+	f.Add("openid profile email")
+	f.Add("profile")
+	f.Add("openid openid")
+	f.Add("openid admin")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, scope string) {
+		scopes := strings.Fields(scope)
+		if ValidateOIDCScopes(scopes) != nil {
+			return
+		}
+		if !slices.Contains(scopes, ScopeOpenID) {
+			t.Errorf("ValidateOIDCScopes(%q) accepted a scope list without openid", scope)
+		}
+		for _, s := range scopes {
+			if !OIDCScopes[s] {
+				t.Errorf("ValidateOIDCScopes(%q) accepted unknown scope %q", scope, s)
+			}
+		}
+	})
+}
+
+func FuzzPKCE(f *testing.F) {
+	// RFC 7636 Appendix B
+	f.Add("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallengeMethodS256, "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", false)
+	f.Add("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", CodeChallengeMethodPlain, "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", true)
+	f.Add("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", CodeChallengeMethodPlain, "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", false)
+	f.Add("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", "s256", "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", false)
+	f.Add("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-c=", CodeChallengeMethodS256, "short", false)
+	f.Add("", "", "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", false)
+	f.Add("", "", "", false)
+
+	f.Fuzz(func(t *testing.T, challenge, method, verifier string, allowPlain bool) {
+		p := PKCEPolicy{AllowPlain: allowPlain}
+
+		if err := p.CheckCodeChallenge(&Client{TokenEndpointAuthMethod: AuthMethodNone}, challenge, method); err == nil {
+			if challenge == "" || !p.allows(method) {
+				t.Errorf("CheckCodeChallenge(%q, %q) accepted a public client request without a usable challenge", challenge, method)
+			}
+		}
+
+		err := p.VerifyCodeVerifier(challenge, method, verifier)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidCodeVerifier) && !errors.Is(err, ErrUnsupportedChallengeMode) {
+				t.Errorf("VerifyCodeVerifier() error = %v, want a PKCE error", err)
+			}
+		} else if challenge == "" {
+			if verifier != "" {
+				t.Errorf("VerifyCodeVerifier() accepted verifier %q for a code without a challenge", verifier)
+			}
+		} else {
+			if !p.allows(method) || !validVerifier(verifier) {
+				t.Errorf("VerifyCodeVerifier(%q, %q, %q) accepted a disallowed method or malformed verifier", challenge, method, verifier)
+			}
+			expected := verifier
+			if method == CodeChallengeMethodS256 {
+				sum := sha256.Sum256([]byte(verifier))
+				expected = base64.RawURLEncoding.EncodeToString(sum[:])
+			}
+			if expected != challenge {
+				t.Errorf("VerifyCodeVerifier(%q, %q, %q) accepted a verifier that does not match", challenge, method, verifier)
+			}
+		}
+
+		if validVerifier(verifier) {
+			sum := sha256.Sum256([]byte(verifier))
+			if err := p.VerifyCodeVerifier(base64.RawURLEncoding.EncodeToString(sum[:]), CodeChallengeMethodS256, verifier); err != nil {
+				t.Errorf("VerifyCodeVerifier() rejected its own S256 round trip for %q: %v", verifier, err)
+			}
+		}
+	})
+}
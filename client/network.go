@@ -0,0 +1,100 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Network policy errors
+var (
+	ErrInvalidCIDR      = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid allowed CIDR")
+	ErrSourceNotAllowed = apperror.New(apperror.CodeInvalidClient, apperror.StatusUnauthorized, apperror.OAuth2InvalidClient, "client is not allowed from this network")
+	ErrDPoPRequired     = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidDPoPProof, "a DPoP proof is required for this client")
+	ErrMTLSRequired     = apperror.New(apperror.CodeInvalidClient, apperror.StatusUnauthorized, apperror.OAuth2InvalidClient, "a TLS client certificate is required for this client")
+)
+
+// TokenRequest is what the transport established about a token endpoint request.
+//
+// Purpose: Input to the client network and token binding policy.
+// Domain: OAuth2
+// Invariants: Thumbprints are set only after the transport verified the DPoP proof
+// or the TLS client certificate; RemoteIP is the address the transport trusts
+// (after proxy header resolution), not a client-supplied value.
+type TokenRequest struct {
+	RemoteIP string
+	// DPoPJKT is the base64url SHA-256 JWK thumbprint of the DPoP proof key (RFC 9449).
+	DPoPJKT string
+	// CertThumbprint is the base64url SHA-256 thumbprint of the client certificate (RFC 8705).
+	CertThumbprint string
+}
+
+// AllowsSource reports whether ip falls inside the client's AllowedCIDRs.
+// A client without AllowedCIDRs is reachable from anywhere.
+func (c *Client) AllowsSource(ip string) bool {
+	if len(c.AllowedCIDRs) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, cidr := range c.AllowedCIDRs {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTokenRequest enforces the client's network and token binding policy.
+//
+// Purpose: Token-time enforcement of AllowedCIDRs and required sender constraints.
+// Domain: OAuth2
+// Security: Runs after client authentication, before any token is issued. Tokens
+// of a client that requires binding are never issued as plain bearer tokens.
+// Audited: No
+// Errors: ErrSourceNotAllowed, ErrDPoPRequired, ErrMTLSRequired
+func (c *Client) CheckTokenRequest(req TokenRequest) error {
+	if !c.AllowsSource(req.RemoteIP) {
+		return ErrSourceNotAllowed
+	}
+	if c.DPoPBoundAccessTokens && req.DPoPJKT == "" {
+		return ErrDPoPRequired
+	}
+	if c.TLSClientCertificateBoundAccessTokens && req.CertThumbprint == "" {
+		return ErrMTLSRequired
+	}
+	return nil
+}
+
+// Bind records the sender constraint of req on the token (the "cnf" claim).
+func (a *AccessToken) Bind(req TokenRequest) {
+	a.DPoPJKT = req.DPoPJKT
+	a.CertThumbprint = req.CertThumbprint
+}
+
+// validateCIDR checks that cidr is a canonical, masked network prefix.
+func validateCIDR(cidr string) error {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil || prefix.Masked().String() != cidr {
+		return fmt.Errorf("%w: %q must be a network prefix such as 203.0.113.0/24", ErrInvalidCIDR, cidr)
+	}
+	return nil
+}
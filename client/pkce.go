@@ -0,0 +1,184 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/crypto"
+)
+
+// PKCE code challenge methods (RFC 7636 §4.2)
+const (
+	// CodeChallengeMethodS256 is the challenge method accepted by default.
+	CodeChallengeMethodS256 = "S256"
+	// CodeChallengeMethodPlain sends the verifier itself as the challenge. It is
+	// accepted only where PKCEPolicy.AllowPlain is set.
+	CodeChallengeMethodPlain = "plain"
+)
+
+// PKCE errors
+var (
+	ErrPKCERequired             = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "code_challenge is required for this client")
+	ErrInvalidCodeChallenge     = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "invalid code_challenge")
+	ErrUnsupportedChallengeMode = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "code_challenge_method must be S256")
+	ErrInvalidCodeVerifier      = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "invalid code_verifier")
+)
+
+// Reasons a code_verifier is rejected
+const (
+	// PKCEVerifierMissing: the code carries a challenge but no verifier was sent.
+	PKCEVerifierMissing = "verifier_missing"
+	// PKCEVerifierUnexpected: a verifier was sent for a code issued without a challenge.
+	PKCEVerifierUnexpected = "verifier_unexpected"
+	// PKCEVerifierMalformed: the verifier is not 43-128 unreserved characters.
+	PKCEVerifierMalformed = "verifier_malformed"
+	// PKCEVerifierMismatch: the verifier does not transform to the stored challenge.
+	PKCEVerifierMismatch = "verifier_mismatch"
+)
+
+// VerifierError reports why a token request's code_verifier was rejected.
+//
+// Purpose: Lets transports and audit distinguish a mismatched verifier (a possible
+// intercepted code) from a malformed or missing one.
+// Domain: OAuth2
+// Invariants: Unwraps to ErrInvalidCodeVerifier, so clients see the same
+// invalid_grant response whatever the reason. Reason is a PKCEVerifier* constant.
+type VerifierError struct {
+	Reason string
+}
+
+// Error returns the sentinel's safe message.
+func (e *VerifierError) Error() string { return ErrInvalidCodeVerifier.Error() }
+
+// Unwrap returns ErrInvalidCodeVerifier.
+func (e *VerifierError) Unwrap() error { return ErrInvalidCodeVerifier }
+
+// PKCEPolicy selects the accepted code challenge methods.
+//
+// Purpose: Per-tenant PKCE method policy, resolved by the caller from feature.PKCEPlainAllowed.
+// Domain: OAuth2
+// Invariants: The zero value accepts S256 only.
+type PKCEPolicy struct {
+	// AllowPlain accepts CodeChallengeMethodPlain.
+	AllowPlain bool
+}
+
+// allows reports whether method is accepted.
+func (p PKCEPolicy) allows(method string) bool {
+	return method == CodeChallengeMethodS256 || (p.AllowPlain && method == CodeChallengeMethodPlain)
+}
+
+// CheckCodeChallenge validates the PKCE parameters of an authorization request
+// under the default policy, which accepts S256 only.
+func (c *Client) CheckCodeChallenge(challenge, method string) error {
+	return PKCEPolicy{}.CheckCodeChallenge(c, challenge, method)
+}
+
+// CheckCodeChallenge validates the PKCE parameters of an authorization request from c.
+//
+// Purpose: Authorize-time PKCE enforcement.
+// Domain: OAuth2
+// Security: Public clients must send a challenge. S256 is accepted; "plain" only when
+// the policy allows it, since it exposes the verifier to anyone who can read the
+// authorization request.
+// Audited: No
+// Errors: ErrPKCERequired, ErrUnsupportedChallengeMode, ErrInvalidCodeChallenge
+func (p PKCEPolicy) CheckCodeChallenge(c *Client, challenge, method string) error {
+	if challenge == "" {
+		if method != "" {
+			return ErrInvalidCodeChallenge
+		}
+		if c.RequiresPKCE() {
+			return ErrPKCERequired
+		}
+		return nil
+	}
+	if !p.allows(method) {
+		return ErrUnsupportedChallengeMode
+	}
+	if method == CodeChallengeMethodPlain {
+		if !validVerifier(challenge) {
+			return ErrInvalidCodeChallenge
+		}
+		return nil
+	}
+	// A base64url-encoded SHA-256 digest is always 43 characters.
+	if b, err := base64.RawURLEncoding.DecodeString(challenge); err != nil || len(b) != sha256.Size {
+		return ErrInvalidCodeChallenge
+	}
+	return nil
+}
+
+// VerifyCodeVerifier checks a code_verifier under the default policy, which
+// accepts S256 only.
+func VerifyCodeVerifier(challenge, method, verifier string) error {
+	return PKCEPolicy{}.VerifyCodeVerifier(challenge, method, verifier)
+}
+
+// VerifyCodeVerifier checks a token request's code_verifier against the challenge
+// stored with the authorization code. A code issued without a challenge must be
+// redeemed without a verifier.
+//
+// Purpose: Token-time PKCE enforcement.
+// Domain: OAuth2
+// Security: Compares in constant time. Rejects methods the policy does not accept,
+// so a plain challenge stored before plain was disabled can no longer be redeemed.
+// Audited: No
+// Errors: *VerifierError (ErrInvalidCodeVerifier), ErrUnsupportedChallengeMode
+func (p PKCEPolicy) VerifyCodeVerifier(challenge, method, verifier string) error {
+	if challenge == "" {
+		if verifier != "" {
+			return &VerifierError{Reason: PKCEVerifierUnexpected}
+		}
+		return nil
+	}
+	if !p.allows(method) {
+		return ErrUnsupportedChallengeMode
+	}
+	if verifier == "" {
+		return &VerifierError{Reason: PKCEVerifierMissing}
+	}
+	if !validVerifier(verifier) {
+		return &VerifierError{Reason: PKCEVerifierMalformed}
+	}
+	expected := verifier
+	if method == CodeChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	if !crypto.ConstantTimeEqualString(expected, challenge) {
+		return &VerifierError{Reason: PKCEVerifierMismatch}
+	}
+	return nil
+}
+
+// validVerifier reports whether v is 43-128 characters from the RFC 7636 unreserved set.
+func validVerifier(v string) bool {
+	if len(v) < 43 || len(v) > 128 {
+		return false
+	}
+	for _, r := range v {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case r == '-', r == '.', r == '_', r == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
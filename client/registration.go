@@ -0,0 +1,498 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Dynamic registration errors (RFC 7591 Section 3.2.2, RFC 7592 Section 2)
+var (
+	ErrRegistrationDisabled      = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2AccessDenied, "dynamic client registration is disabled for this tenant")
+	ErrInvalidClientMetadata     = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidClientMetadata, "invalid client metadata")
+	ErrInvalidRegistrationURI    = apperror.New(apperror.CodeInvalidRedirectURI, apperror.StatusBadRequest, apperror.OAuth2InvalidRedirectURI, "invalid redirect_uris")
+	ErrInvalidRegistrationPolicy = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid registration policy")
+	ErrInvalidRegistrationToken  = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, apperror.OAuth2InvalidToken, "invalid registration access token")
+)
+
+// OAuth 2.0 grant types (RFC 6749); the token package aliases them
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// registrableGrantTypes are the grant types dynamic registration can grant. The implicit
+// grant is never registrable, whatever the tenant's feature flags.
+var registrableGrantTypes = []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials}
+
+// registrableAuthMethods are the token endpoint authentication methods dynamic
+// registration can grant.
+var registrableAuthMethods = []string{AuthMethodClientSecretBasic, AuthMethodClientSecretPost, AuthMethodNone}
+
+// RegistrationPolicy is a tenant's restrictions on dynamically registered clients.
+//
+// Purpose: Per-tenant opt-in and limits for RFC 7591 registration.
+// Domain: OAuth2
+// Invariants: Registration is refused unless Enabled. GrantTypes and AuthMethods hold
+// only registrable values; Scopes holds scope tokens. An empty list applies the default:
+// every registrable grant type, every registrable auth method, and the OIDC scopes.
+type RegistrationPolicy struct {
+	Enabled     bool     `json:"enabled"`
+	GrantTypes  []string `json:"grant_types,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	AuthMethods []string `json:"auth_methods,omitempty"`
+}
+
+// Validate checks that the policy grants only registrable values.
+func (p *RegistrationPolicy) Validate() error {
+	for _, gt := range p.GrantTypes {
+		if !slices.Contains(registrableGrantTypes, gt) {
+			return fmt.Errorf("%w: grant type %q cannot be registered dynamically", ErrInvalidRegistrationPolicy, gt)
+		}
+	}
+	for _, m := range p.AuthMethods {
+		if !slices.Contains(registrableAuthMethods, m) {
+			return fmt.Errorf("%w: unknown auth method %q", ErrInvalidRegistrationPolicy, m)
+		}
+	}
+	for _, sc := range p.Scopes {
+		if sc == "" || strings.ContainsAny(sc, " \t\n") {
+			return fmt.Errorf("%w: invalid scope %q", ErrInvalidRegistrationPolicy, sc)
+		}
+	}
+	return nil
+}
+
+// allowsGrantType reports whether the policy permits gt
+func (p *RegistrationPolicy) allowsGrantType(gt string) bool {
+	if len(p.GrantTypes) == 0 {
+		return slices.Contains(registrableGrantTypes, gt)
+	}
+	return slices.Contains(p.GrantTypes, gt)
+}
+
+// allowsAuthMethod reports whether the policy permits method
+func (p *RegistrationPolicy) allowsAuthMethod(method string) bool {
+	if len(p.AuthMethods) == 0 {
+		return slices.Contains(registrableAuthMethods, method)
+	}
+	return slices.Contains(p.AuthMethods, method)
+}
+
+// allowsScope reports whether the policy permits scope
+func (p *RegistrationPolicy) allowsScope(scope string) bool {
+	if len(p.Scopes) == 0 {
+		return OIDCScopes[scope]
+	}
+	return slices.Contains(p.Scopes, scope)
+}
+
+// RegistrationPolicies resolves a tenant's RegistrationPolicy; tenant.Service implements it.
+type RegistrationPolicies interface {
+	RegistrationPolicy(ctx context.Context, tenantID string) (RegistrationPolicy, error)
+}
+
+// WithRegistrationPolicies enables RegisterDynamic for tenants whose policy, as
+// resolved by p, allows it. Without it dynamic registration is refused.
+func WithRegistrationPolicies(p RegistrationPolicies) Option {
+	return func(s *Service) { s.registration = p }
+}
+
+// RegistrationRequest is a client registration request (RFC 7591 Section 2 and
+// OpenID Connect Dynamic Client Registration 1.0 Section 2).
+//
+// Purpose: Client metadata submitted to the registration endpoint.
+// Domain: OAuth2
+// Invariants: Scope is space-delimited. Metadata the server does not support is
+// dropped by the transport when decoding.
+type RegistrationRequest struct {
+	// ClientID is sent only in RFC 7592 update requests and must match the client.
+	ClientID                 string   `json:"client_id,omitempty"`
+	RedirectURIs             []string `json:"redirect_uris,omitempty"`
+	TokenEndpointAuthMethod  string   `json:"token_endpoint_auth_method,omitempty"`
+	GrantTypes               []string `json:"grant_types,omitempty"`
+	ResponseTypes            []string `json:"response_types,omitempty"`
+	ClientName               string   `json:"client_name,omitempty"`
+	ClientURI                string   `json:"client_uri,omitempty"`
+	LogoURI                  string   `json:"logo_uri,omitempty"`
+	Scope                    string   `json:"scope,omitempty"`
+	Contacts                 []string `json:"contacts,omitempty"`
+	ApplicationType          string   `json:"application_type,omitempty"`
+	IDTokenSignedResponseAlg string   `json:"id_token_signed_response_alg,omitempty"`
+	SubjectType              string   `json:"subject_type,omitempty"`
+}
+
+// RegistrationResponse is a client information response (RFC 7591 Section 3.2.1).
+//
+// Purpose: Registered metadata and credentials returned to the registering party.
+// Domain: OAuth2
+// Invariants: ClientSecret is set only for confidential clients and
+// RegistrationAccessToken only on registration; neither is retrievable later.
+// ClientSecretExpiresAt is 0 (never expires) whenever a secret is issued.
+// RegistrationClientURI is left to the transport, which owns the endpoint URL.
+type RegistrationResponse struct {
+	ClientID                 string   `json:"client_id"`
+	ClientSecret             string   `json:"client_secret,omitempty"`
+	ClientIDIssuedAt         int64    `json:"client_id_issued_at"`
+	ClientSecretExpiresAt    *int64   `json:"client_secret_expires_at,omitempty"`
+	RegistrationAccessToken  string   `json:"registration_access_token,omitempty"`
+	RegistrationClientURI    string   `json:"registration_client_uri,omitempty"`
+	RedirectURIs             []string `json:"redirect_uris,omitempty"`
+	TokenEndpointAuthMethod  string   `json:"token_endpoint_auth_method"`
+	GrantTypes               []string `json:"grant_types"`
+	ResponseTypes            []string `json:"response_types"`
+	ClientName               string   `json:"client_name,omitempty"`
+	ClientURI                string   `json:"client_uri,omitempty"`
+	LogoURI                  string   `json:"logo_uri,omitempty"`
+	Scope                    string   `json:"scope,omitempty"`
+	Contacts                 []string `json:"contacts,omitempty"`
+	ApplicationType          string   `json:"application_type"`
+	IDTokenSignedResponseAlg string   `json:"id_token_signed_response_alg,omitempty"`
+	SubjectType              string   `json:"subject_type"`
+}
+
+// RegisterDynamic registers a client from an RFC 7591 registration request.
+//
+// Purpose: Self-service client registration within the limits of the tenant's
+// RegistrationPolicy.
+// Domain: OAuth2
+// Security: Refused unless the tenant enables it. Dynamically registered clients are
+// never trusted and carry no claim mappings, resources, or network restrictions; those
+// remain administrative. The registration access token is returned once and stored
+// only as a hash.
+// Audited: Yes (ClientCreated)
+// Errors: ErrRegistrationDisabled, ErrInvalidClientMetadata, ErrInvalidRegistrationURI,
+// ErrDomainInvalidScope, System errors
+func (s *Service) RegisterDynamic(ctx context.Context, tenantID string, req RegistrationRequest) (*RegistrationResponse, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "client.RegisterDynamic", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	policy, err := s.registrationPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ClientID != "" {
+		return nil, fmt.Errorf("%w: client_id is assigned by the server", ErrInvalidClientMetadata)
+	}
+
+	c, err := clientFromRegistration(policy, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	var secret string
+	if !c.IsPublic() {
+		secret = GenerateClientSecret()
+		c.ClientSecretHash = HashClientSecret(secret)
+	}
+	registrationToken := GenerateClientSecret()
+	c.RegistrationTokenHash = HashClientSecret(registrationToken)
+
+	if err := s.ValidateClient(ctx, c); err != nil {
+		return nil, registrationError(err)
+	}
+	if _, err := s.RegisterClient(ctx, tenantID, "", c); err != nil {
+		return nil, err
+	}
+
+	resp := registrationResponse(c)
+	resp.RegistrationAccessToken = registrationToken
+	if secret != "" {
+		var never int64
+		resp.ClientSecret = secret
+		resp.ClientSecretExpiresAt = &never
+	}
+	return resp, nil
+}
+
+// ReadRegistration returns the current registration of a client (RFC 7592 Section 2.1).
+//
+// Purpose: Lets a client read its own registration with its registration access token.
+// Domain: OAuth2
+// Security: The token is compared in constant time against its stored hash. An unknown
+// client and a wrong token are indistinguishable. Secrets are never returned.
+// Audited: No
+// Errors: ErrInvalidRegistrationToken, System errors
+func (s *Service) ReadRegistration(ctx context.Context, tenantID, clientID, registrationToken string) (*RegistrationResponse, error) {
+	c, err := s.authenticateRegistration(ctx, tenantID, clientID, registrationToken)
+	if err != nil {
+		return nil, err
+	}
+	return registrationResponse(c), nil
+}
+
+// UpdateRegistration replaces a client's registered metadata (RFC 7592 Section 2.2).
+//
+// Purpose: Lets a client update its own registration with its registration access token.
+// Domain: OAuth2
+// Security: The request replaces the metadata as a whole and is checked against the
+// tenant's current RegistrationPolicy, so registration must still be enabled. Trust,
+// activation, lifetimes, and administrative settings are kept. A client becoming
+// confidential is issued a new secret; one becoming public loses its secret.
+// Audited: Yes (ClientUpdated)
+// Errors: ErrInvalidRegistrationToken, ErrRegistrationDisabled, ErrInvalidClientMetadata,
+// ErrInvalidRegistrationURI, ErrDomainInvalidScope, System errors
+func (s *Service) UpdateRegistration(ctx context.Context, tenantID, clientID, registrationToken string, req RegistrationRequest) (*RegistrationResponse, error) {
+	existing, err := s.authenticateRegistration(ctx, tenantID, clientID, registrationToken)
+	if err != nil {
+		return nil, err
+	}
+	if req.ClientID != "" && req.ClientID != existing.ClientID {
+		return nil, fmt.Errorf("%w: client_id does not match", ErrInvalidClientMetadata)
+	}
+	policy, err := s.registrationPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := clientFromRegistration(policy, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	c.ID, c.ClientID, c.OwnerID = existing.ID, existing.ClientID, existing.OwnerID
+	c.IsTrusted, c.IsActive, c.PasswordGrantEnabled = existing.IsTrusted, existing.IsActive, existing.PasswordGrantEnabled
+	c.AllowedOrigins, c.AllowedCIDRs, c.Resources, c.ClaimMapping = existing.AllowedOrigins, existing.AllowedCIDRs, existing.Resources, existing.ClaimMapping
+	c.DPoPBoundAccessTokens, c.TLSClientCertificateBoundAccessTokens = existing.DPoPBoundAccessTokens, existing.TLSClientCertificateBoundAccessTokens
+	c.JWTAccessTokens, c.SectorIdentifier = existing.JWTAccessTokens, existing.SectorIdentifier
+	c.AccessTokenLifetime, c.RefreshTokenLifetime, c.IDTokenLifetime = existing.AccessTokenLifetime, existing.RefreshTokenLifetime, existing.IDTokenLifetime
+	c.RegistrationTokenHash, c.CreatedAt = existing.RegistrationTokenHash, existing.CreatedAt
+	if c.ApplicationType == ApplicationTypeNative {
+		c.AllowedOrigins = nil
+	}
+
+	var secret string
+	switch {
+	case c.IsPublic():
+	case existing.ClientSecretHash != "":
+		c.ClientSecretHash = existing.ClientSecretHash
+	default:
+		secret = GenerateClientSecret()
+		c.ClientSecretHash = HashClientSecret(secret)
+	}
+
+	if err := s.ValidateClient(ctx, c); err != nil {
+		return nil, registrationError(err)
+	}
+	if err := s.UpdateClient(ctx, c, c.ClientID); err != nil {
+		return nil, err
+	}
+
+	resp := registrationResponse(c)
+	if secret != "" {
+		var never int64
+		resp.ClientSecret = secret
+		resp.ClientSecretExpiresAt = &never
+	}
+	return resp, nil
+}
+
+// DeleteRegistration deregisters a client (RFC 7592 Section 2.3).
+//
+// Purpose: Lets a client delete its own registration with its registration access token.
+// Domain: OAuth2
+// Security: The client is soft-deleted like an administrative deletion, which also
+// invalidates the registration access token.
+// Audited: Yes (ClientDeleted)
+// Errors: ErrInvalidRegistrationToken, System errors
+func (s *Service) DeleteRegistration(ctx context.Context, tenantID, clientID, registrationToken string) error {
+	c, err := s.authenticateRegistration(ctx, tenantID, clientID, registrationToken)
+	if err != nil {
+		return err
+	}
+	return s.DeleteClient(ctx, tenantID, c.ID, c.ClientID)
+}
+
+// IssueRegistrationToken issues a new registration access token for a client,
+// replacing any previous one.
+//
+// Purpose: Gives administratively registered clients, or clients that lost their
+// token, self-service management of their registration.
+// Domain: OAuth2
+// Security: The token is returned once and stored only as a hash; the previous token
+// stops working immediately.
+// Audited: Yes (ClientUpdated)
+// Errors: ErrClientNotFound, System errors
+func (s *Service) IssueRegistrationToken(ctx context.Context, tenantID, id, actorID string) (string, error) {
+	c, err := s.clientRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return "", err
+	}
+	token := GenerateClientSecret()
+	before := *c
+	c.RegistrationTokenHash = HashClientSecret(token)
+	c.UpdatedAt = s.clock.Now()
+	if err := s.clientRepo.Update(ctx, c); err != nil {
+		return "", err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeClientUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceClient,
+		TargetName: c.ClientName,
+		TargetID:   c.ClientID,
+		Metadata: map[string]any{
+			"client_id": c.ClientID,
+			"fields":    []string{"registration_access_token"},
+		},
+	})
+	s.recordChange(ctx, &before, c, actorID)
+	return token, nil
+}
+
+// registrationPolicy returns the tenant's policy, or ErrRegistrationDisabled.
+func (s *Service) registrationPolicy(ctx context.Context, tenantID string) (*RegistrationPolicy, error) {
+	if s.registration == nil {
+		return nil, ErrRegistrationDisabled
+	}
+	policy, err := s.registration.RegistrationPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve registration policy: %w", err)
+	}
+	if !policy.Enabled {
+		return nil, ErrRegistrationDisabled
+	}
+	return &policy, nil
+}
+
+// authenticateRegistration returns the client whose registration access token is token.
+func (s *Service) authenticateRegistration(ctx context.Context, tenantID, clientID, token string) (*Client, error) {
+	c, err := s.clientRepo.GetByClientID(ctx, tenantID, clientID)
+	if err != nil {
+		if errors.Is(err, ErrClientNotFound) {
+			return nil, ErrInvalidRegistrationToken
+		}
+		return nil, err
+	}
+	if token == "" || !VerifyClientSecret(token, c.RegistrationTokenHash) {
+		return nil, ErrInvalidRegistrationToken
+	}
+	return c, nil
+}
+
+// clientFromRegistration applies RFC 7591 defaults to req and checks it against policy.
+func clientFromRegistration(policy *RegistrationPolicy, tenantID string, req RegistrationRequest) (*Client, error) {
+	c := &Client{
+		TenantID:                 tenantID,
+		ClientName:               req.ClientName,
+		ClientURI:                req.ClientURI,
+		LogoURI:                  req.LogoURI,
+		RedirectURIs:             req.RedirectURIs,
+		GrantTypes:               req.GrantTypes,
+		ResponseTypes:            req.ResponseTypes,
+		TokenEndpointAuthMethod:  req.TokenEndpointAuthMethod,
+		ApplicationType:          req.ApplicationType,
+		Contacts:                 req.Contacts,
+		IDTokenSignedResponseAlg: req.IDTokenSignedResponseAlg,
+		SubjectType:              req.SubjectType,
+		AllowedScopes:            strings.Fields(req.Scope),
+		IsActive:                 true,
+	}
+	if len(c.GrantTypes) == 0 {
+		c.GrantTypes = []string{GrantTypeAuthorizationCode}
+	}
+	if len(c.ResponseTypes) == 0 && slices.Contains(c.GrantTypes, GrantTypeAuthorizationCode) {
+		c.ResponseTypes = []string{"code"}
+	}
+	if c.TokenEndpointAuthMethod == "" {
+		c.TokenEndpointAuthMethod = AuthMethodClientSecretBasic
+		if c.ApplicationType == ApplicationTypeNative || c.ApplicationType == ApplicationTypeSPA {
+			c.TokenEndpointAuthMethod = AuthMethodNone
+		}
+	}
+	if c.ApplicationType == "" {
+		c.ApplicationType = ApplicationTypeWeb
+	}
+	if c.SubjectType == "" {
+		c.SubjectType = SubjectTypePublic
+	}
+	if len(c.AllowedScopes) == 0 {
+		c.AllowedScopes = []string{ScopeOpenID}
+	}
+
+	for _, gt := range c.GrantTypes {
+		if !policy.allowsGrantType(gt) {
+			return nil, fmt.Errorf("%w: grant type %q is not allowed", ErrInvalidClientMetadata, gt)
+		}
+	}
+	for _, rt := range c.ResponseTypes {
+		if rt != "code" {
+			return nil, fmt.Errorf("%w: response type %q is not allowed", ErrInvalidClientMetadata, rt)
+		}
+	}
+	if len(c.ResponseTypes) > 0 && !slices.Contains(c.GrantTypes, GrantTypeAuthorizationCode) {
+		return nil, fmt.Errorf("%w: response type \"code\" requires the authorization_code grant", ErrInvalidClientMetadata)
+	}
+	if !policy.allowsAuthMethod(c.TokenEndpointAuthMethod) {
+		return nil, fmt.Errorf("%w: token_endpoint_auth_method %q is not allowed", ErrInvalidClientMetadata, c.TokenEndpointAuthMethod)
+	}
+	for _, sc := range c.AllowedScopes {
+		if !policy.allowsScope(sc) {
+			return nil, fmt.Errorf("%w: scope %q is not allowed", ErrDomainInvalidScope, sc)
+		}
+	}
+	if slices.Contains(c.GrantTypes, GrantTypeAuthorizationCode) && len(c.RedirectURIs) == 0 {
+		return nil, fmt.Errorf("%w: required for the authorization_code grant", ErrInvalidRegistrationURI)
+	}
+	return c, nil
+}
+
+// registrationError maps a client validation error onto the RFC 7591 error codes.
+func registrationError(err error) error {
+	if appErr, ok := apperror.As(err); !ok || appErr.Status >= apperror.StatusInternalServerError {
+		return err
+	}
+	if errors.Is(err, ErrInvalidRedirectURI) {
+		return fmt.Errorf("%w: %v", ErrInvalidRegistrationURI, err)
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidClientMetadata, err)
+}
+
+// registrationResponse returns the client information response for c.
+func registrationResponse(c *Client) *RegistrationResponse {
+	return &RegistrationResponse{
+		ClientID:                 c.ClientID,
+		ClientIDIssuedAt:         c.CreatedAt.Unix(),
+		RedirectURIs:             c.RedirectURIs,
+		TokenEndpointAuthMethod:  c.TokenEndpointAuthMethod,
+		GrantTypes:               c.GrantTypes,
+		ResponseTypes:            nonNilStrings(c.ResponseTypes),
+		ClientName:               c.ClientName,
+		ClientURI:                c.ClientURI,
+		LogoURI:                  c.LogoURI,
+		Scope:                    strings.Join(c.AllowedScopes, " "),
+		Contacts:                 c.Contacts,
+		ApplicationType:          c.ApplicationType,
+		IDTokenSignedResponseAlg: c.IDTokenSignedResponseAlg,
+		SubjectType:              c.SubjectType,
+	}
+}
+
+// nonNilStrings returns values, or an empty slice if it is nil
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
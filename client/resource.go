@@ -0,0 +1,116 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// MaxResourcesPerRequest bounds how many audience-restricted tokens one token request may mint.
+const MaxResourcesPerRequest = 10
+
+// Resource indicator errors
+var (
+	ErrInvalidResource = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid resource")
+	ErrInvalidTarget   = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidTarget, "requested resource is invalid or not allowed for this client")
+)
+
+// Resource is an API a client may request access tokens for (RFC 8707 resource indicator).
+//
+// Purpose: Per-client audience allowlist with the scopes each audience may carry.
+// Domain: OAuth2
+// Invariants: URI is an absolute URI without a fragment and unique per client. Scopes
+// are a subset of the client's AllowedScopes.
+type Resource struct {
+	URI    string   `json:"uri"`
+	Scopes []string `json:"scopes"`
+}
+
+// ResourceToken is one audience-restricted access token to mint for a token request.
+type ResourceToken struct {
+	// Audience is the resource URI, issued as the token's "aud"
+	Audience string `json:"audience"`
+	// Scope is the space-separated subset of the requested scope that applies to Audience
+	Scope string `json:"scope"`
+}
+
+// ResourceTokens plans one access token per requested resource, each carrying only
+// the requested scopes that resource accepts.
+//
+// Purpose: Lets a gateway obtain tokens for several APIs in one round trip instead of
+// one token exchange per API.
+// Domain: OAuth2
+// Security: Every resource must be registered for the client, and a token never carries
+// a scope its audience was not registered for, so a token leaked by one API is useless at
+// another. The transport must already have checked scope with ValidateScope.
+// Audited: No
+// Errors: ErrInvalidTarget
+func (c *Client) ResourceTokens(scope string, resources []string) ([]ResourceToken, error) {
+	requested := strings.Fields(scope)
+	seen := make(map[string]bool, len(resources))
+	var tokens []ResourceToken
+	for _, uri := range resources {
+		if seen[uri] {
+			continue
+		}
+		seen[uri] = true
+		if len(tokens) == MaxResourcesPerRequest {
+			return nil, fmt.Errorf("%w: at most %d resources per request", ErrInvalidTarget, MaxResourcesPerRequest)
+		}
+
+		i := slices.IndexFunc(c.Resources, func(r Resource) bool { return r.URI == uri })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTarget, uri)
+		}
+		var granted []string
+		for _, s := range requested {
+			if slices.Contains(c.Resources[i].Scopes, s) && !slices.Contains(granted, s) {
+				granted = append(granted, s)
+			}
+		}
+		if len(granted) == 0 {
+			return nil, fmt.Errorf("%w: no requested scope applies to %s", ErrInvalidTarget, uri)
+		}
+		tokens = append(tokens, ResourceToken{Audience: uri, Scope: strings.Join(granted, " ")})
+	}
+	return tokens, nil
+}
+
+// validateResources checks the client's resource registrations.
+func validateResources(c *Client) error {
+	seen := make(map[string]bool, len(c.Resources))
+	for _, r := range c.Resources {
+		u, err := url.Parse(r.URI)
+		if err != nil || !u.IsAbs() || u.Fragment != "" || strings.Contains(r.URI, "#") {
+			return fmt.Errorf("%w: %q must be an absolute URI without a fragment", ErrInvalidResource, r.URI)
+		}
+		if seen[r.URI] {
+			return fmt.Errorf("%w: %q is listed twice", ErrInvalidResource, r.URI)
+		}
+		seen[r.URI] = true
+		if len(r.Scopes) == 0 {
+			return fmt.Errorf("%w: %q has no scopes", ErrInvalidResource, r.URI)
+		}
+		if !c.ValidateScope(strings.Join(r.Scopes, " ")) {
+			return fmt.Errorf("%w: %q lists scopes the client may not request", ErrInvalidResource, r.URI)
+		}
+	}
+	return nil
+}
@@ -16,14 +16,25 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/feature"
 	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/jose"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
 )
 
+// maxClientIDAttempts bounds how often RegisterClient redraws a generated client_id
+// that collides with an existing one.
+const maxClientIDAttempts = 3
+
 // Service provides OAuth2 client management business logic.
 //
 // Purpose: Implementation of client registration, validation, and lifecycle rules.
@@ -31,6 +42,111 @@ import (
 type Service struct {
 	clientRepo  ClientRepository
 	auditLogger audit.Logger
+	tracer      tracing.Tracer
+	events      events.Publisher
+	features    feature.Checker
+	permissions PermissionChecker
+	usage       UsageRepository
+	signing     SigningAlgorithms
+	logos       LogoStore
+	uris        URIPolicy
+	reasons     audit.ReasonPolicy
+	clock       clock.Clock
+	ids         id.Generator
+	scopes      ScopeRegistry
+	changes     ChangeLog
+
+	registration RegistrationPolicies
+}
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// SigningAlgorithms resolves a tenant's token signing algorithm; tenant.Service implements it.
+type SigningAlgorithms interface {
+	SigningAlgorithm(ctx context.Context, tenantID string) (string, error)
+}
+
+// ChangeLog records field-level configuration diffs; changelog.Service implements it.
+type ChangeLog interface {
+	Record(ctx context.Context, tenantID, resource, resourceID, actorID string, before, after any)
+}
+
+// LogoStore stores uploaded client logos; blob.Images implements it.
+type LogoStore interface {
+	PutClientLogo(ctx context.Context, tenantID, clientID, contentType string, data []byte) (string, error)
+	IsClientLogo(tenantID, clientID, uri string) bool
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithTracer emits spans for client registration on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithEvents publishes client lifecycle events on p.
+func WithEvents(p events.Publisher) Option {
+	return func(s *Service) { s.events = p }
+}
+
+// WithFeatures consults c for per-tenant capability flags such as
+// feature.ImplicitFlowAllowed. Without it the built-in defaults apply.
+func WithFeatures(c feature.Checker) Option {
+	return func(s *Service) { s.features = c }
+}
+
+// WithPermissions checks policy.PermTenantTrustClients on p before a client's trust
+// is changed. Without it no actor may mark a client trusted.
+func WithPermissions(p PermissionChecker) Option {
+	return func(s *Service) { s.permissions = p }
+}
+
+// WithUsage reads per-client usage counters from repo for ClientUsage.
+func WithUsage(repo UsageRepository) Option {
+	return func(s *Service) { s.usage = repo }
+}
+
+// WithSigningAlgorithms requires a client's id_token_signed_response_alg to match
+// the tenant's signing algorithm as resolved by a. Without it any supported
+// algorithm is accepted.
+func WithSigningAlgorithms(a SigningAlgorithms) Option {
+	return func(s *Service) { s.signing = a }
+}
+
+// WithLogos stores uploaded logos in l. Without it UploadLogo fails.
+func WithLogos(l LogoStore) Option {
+	return func(s *Service) { s.logos = l }
+}
+
+// WithURIPolicy checks client_uri and logo_uri against p. Without it the zero
+// URIPolicy applies: https only, public hosts, logos not fetched.
+func WithURIPolicy(p URIPolicy) Option {
+	return func(s *Service) { s.uris = p }
+}
+
+// WithReasonPolicy requires a justification (audit.WithReason) for trust changes in
+// tenants whose policy asks for one for audit.OpClientTrust.
+func WithReasonPolicy(p audit.ReasonPolicy) Option {
+	return func(s *Service) { s.reasons = p }
+}
+
+// WithClock reads the current time from c for timestamps and staleness cutoffs.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// WithChangeLog records the before/after diff of every client update on l.
+func WithChangeLog(l ChangeLog) Option {
+	return func(s *Service) { s.changes = l }
+}
+
+// WithIDGenerator mints record IDs and client_ids with g. Defaults to id.UUIDv7.
+func WithIDGenerator(g id.Generator) Option {
+	return func(s *Service) { s.ids = g }
 }
 
 // NewService creates a new client management service.
@@ -39,37 +155,62 @@ type Service struct {
 // Domain: OAuth2
 // Audited: No
 // Errors: None
-func NewService(clientRepo ClientRepository, auditLogger audit.Logger) *Service {
-	return &Service{
+func NewService(clientRepo ClientRepository, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
 		clientRepo:  clientRepo,
 		auditLogger: auditLogger,
+		features:    feature.Defaults,
+		clock:       clock.System(),
+		ids:         id.UUIDv7(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // RegisterClient validates and creates a new OAuth2 client.
 //
 // Purpose: Enforces system rules on new client registrations and persists them.
 // Domain: OAuth2
-// Audited: Yes (ClientCreated)
-// Errors: ErrInvalidClientURI, ErrInvalidRedirectURI, System errors
+// Security: The client must belong to tenantID, the tenant permissions are checked in.
+// Registering a trusted client requires policy.PermTenantTrustClients in the tenant.
+// Audited: Yes (ClientCreated, ClientTrustChanged)
+// Errors: ErrClientTenantMismatch, ErrInvalidClientURI, ErrInvalidLogoURI, ErrInvalidRedirectURI, ErrInvalidApplicationType, ErrInvalidOrigin,
+// ErrInvalidContact, ErrTrustNotPermitted, *DuplicateClientError (ErrClientAlreadyExists), System errors
 func (s *Service) RegisterClient(ctx context.Context, tenantID, userID string, c *Client) (*Client, error) {
-	if err := s.validateClient(c); err != nil {
+	ctx, span := tracing.Start(ctx, s.tracer, "client.RegisterClient", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	if c.TenantID != tenantID {
+		return nil, ErrClientTenantMismatch
+	}
+	if err := s.ValidateClient(ctx, c); err != nil {
 		return nil, err
 	}
+	if c.IsTrusted {
+		if err := s.authorizeTrustChange(ctx, tenantID, userID); err != nil {
+			return nil, err
+		}
+	}
 
 	if c.ID == "" {
-		c.ID = id.NewUUIDv7()
+		c.ID = s.ids.NewID()
 	}
-	if c.ClientID == "" {
-		c.ClientID = id.NewUUIDv7()
+	if c.ApplicationType == "" {
+		c.ApplicationType = ApplicationTypeWeb
+	}
+	if c.SubjectType == "" {
+		c.SubjectType = SubjectTypePublic
 	}
 
+	now := s.clock.Now()
 	if c.CreatedAt.IsZero() {
-		c.CreatedAt = time.Now()
+		c.CreatedAt = now
 	}
-	c.UpdatedAt = time.Now()
+	c.UpdatedAt = now
 
-	if err := s.clientRepo.Create(ctx, c); err != nil {
+	if err := s.createClient(ctx, c); err != nil {
 		return nil, err
 	}
 
@@ -85,10 +226,30 @@ func (s *Service) RegisterClient(ctx context.Context, tenantID, userID string, c
 			"client_name": c.ClientName,
 		},
 	})
+	if c.IsTrusted {
+		s.logTrustChange(ctx, c, userID)
+	}
+	events.Emit(ctx, s.events, events.ClientCreated{Meta: events.NewMeta(tenantID, userID), ClientID: c.ClientID})
 
 	return c, nil
 }
 
+// createClient stores c. A client_id chosen by the caller is kept, and a conflict
+// is returned as *DuplicateClientError; a generated one is redrawn on conflict, up to
+// maxClientIDAttempts times.
+func (s *Service) createClient(ctx context.Context, c *Client) error {
+	generated := c.ClientID == ""
+	for attempt := 1; ; attempt++ {
+		if generated {
+			c.ClientID = s.ids.NewID()
+		}
+		err := s.clientRepo.Create(ctx, c)
+		if err == nil || !generated || attempt == maxClientIDAttempts || !errors.Is(err, ErrClientAlreadyExists) {
+			return err
+		}
+	}
+}
+
 // ListClients retrieves all OAuth2 clients for a tenant
 func (s *Service) ListClients(ctx context.Context, tenantID string) ([]*Client, error) {
 	return s.clientRepo.ListByTenant(ctx, tenantID)
@@ -126,15 +287,34 @@ func (s *Service) DeleteClient(ctx context.Context, tenantID, id string, actorID
 			"client_id": c.ClientID,
 		},
 	})
+	events.Emit(ctx, s.events, events.ClientDeleted{Meta: events.NewMeta(tenantID, actorID), ClientID: c.ClientID})
 	return nil
 }
 
-// UpdateClient updates an existing OAuth2 client
+// UpdateClient updates an existing OAuth2 client.
+//
+// Purpose: Enforces system rules on client changes and persists them.
+// Domain: OAuth2
+// Security: Changing IsTrusted requires policy.PermTenantTrustClients in the client's tenant,
+// and a reason in ctx when the tenant requires one for audit.OpClientTrust.
+// Audited: Yes (ClientUpdated, ClientTrustChanged)
+// Errors: ErrClientNotFound, ErrTrustNotPermitted, audit.ErrReasonRequired, validation errors, System errors
 func (s *Service) UpdateClient(ctx context.Context, c *Client, actorID string) error {
-	if err := s.validateClient(c); err != nil {
+	if err := s.ValidateClient(ctx, c); err != nil {
 		return err
 	}
-	c.UpdatedAt = time.Now()
+	existing, err := s.clientRepo.GetByID(ctx, c.TenantID, c.ID)
+	if err != nil {
+		return err
+	}
+	trustChanged := existing.IsTrusted != c.IsTrusted
+	if trustChanged {
+		if err := s.authorizeTrustChange(ctx, c.TenantID, actorID); err != nil {
+			return err
+		}
+	}
+
+	c.UpdatedAt = s.clock.Now()
 	if err := s.clientRepo.Update(ctx, c); err != nil {
 		return err
 	}
@@ -150,20 +330,192 @@ func (s *Service) UpdateClient(ctx context.Context, c *Client, actorID string) e
 			"client_id": c.ClientID,
 		},
 	})
+	if trustChanged {
+		s.logTrustChange(ctx, c, actorID)
+	}
+	s.recordChange(ctx, existing, c, actorID)
+	events.Emit(ctx, s.events, events.ClientUpdated{Meta: events.NewMeta(c.TenantID, actorID), ClientID: c.ClientID})
 	return nil
 }
 
-func (s *Service) validateClient(c *Client) error {
-	if c.ClientURI != "" {
-		if _, err := url.ParseRequestURI(c.ClientURI); err != nil {
-			return fmt.Errorf("%w: %s", ErrInvalidClientURI, err)
-		}
+// UploadLogo stores an uploaded image as the client's logo_uri.
+//
+// Purpose: Client logo upload through the configured LogoStore.
+// Domain: OAuth2
+// Security: The store validates the image type and size.
+// Audited: Yes (ClientUpdated)
+// Errors: ErrUploadsDisabled, ErrClientNotFound, blob validation errors, System errors
+func (s *Service) UploadLogo(ctx context.Context, tenantID, id, actorID, contentType string, data []byte) (*Client, error) {
+	if s.logos == nil {
+		return nil, ErrUploadsDisabled
+	}
+	c, err := s.clientRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	logo, err := s.logos.PutClientLogo(ctx, tenantID, c.ClientID, contentType, data)
+	if err != nil {
+		return nil, err
+	}
+	before := *c
+	c.LogoURI = logo
+	c.UpdatedAt = s.clock.Now()
+	if err := s.clientRepo.Update(ctx, c); err != nil {
+		return nil, err
 	}
 
-	for _, uri := range c.RedirectURIs {
-		if _, err := url.ParseRequestURI(uri); err != nil {
-			return fmt.Errorf("%w: %s", ErrInvalidRedirectURI, uri)
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeClientUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceClient,
+		TargetName: c.ClientName,
+		TargetID:   c.ClientID,
+		Metadata: map[string]any{
+			"client_id": c.ClientID,
+			"fields":    []string{"logo_uri"},
+		},
+	})
+	s.recordChange(ctx, &before, c, actorID)
+	events.Emit(ctx, s.events, events.ClientUpdated{Meta: events.NewMeta(tenantID, actorID), ClientID: c.ClientID})
+	return c, nil
+}
+
+// SetTrusted marks a client as a trusted first-party application, or clears the mark.
+//
+// Purpose: Controls whether the client's users are asked for consent.
+// Domain: OAuth2
+// Security: Requires policy.PermTenantTrustClients in the tenant, and a reason in ctx
+// when the tenant requires one for audit.OpClientTrust.
+// Audited: Yes (ClientUpdated, ClientTrustChanged)
+// Errors: ErrClientNotFound, ErrTrustNotPermitted, audit.ErrReasonRequired, System errors
+func (s *Service) SetTrusted(ctx context.Context, tenantID, id string, trusted bool, actorID string) error {
+	c, err := s.clientRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if c.IsTrusted == trusted {
+		return nil
+	}
+	c.IsTrusted = trusted
+	return s.UpdateClient(ctx, c, actorID)
+}
+
+func (s *Service) authorizeTrustChange(ctx context.Context, tenantID, actorID string) error {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	if s.permissions == nil {
+		return ErrTrustNotPermitted
+	}
+	allowed, err := s.permissions.HasPermission(ctx, actorID, role.ScopeTenant, &tenantID, policy.PermTenantTrustClients)
+	if err != nil {
+		return fmt.Errorf("failed to check trust permission: %w", err)
+	}
+	if !allowed {
+		return ErrTrustNotPermitted
+	}
+	if _, err := audit.RequireReason(ctx, s.reasons, tenantID, audit.OpClientTrust); err != nil {
+		return err
+	}
+	return nil
+}
+
+// changeView is the configuration of c recorded in the change log. The secret and
+// registration token hashes are included so their rotation is recorded; the change
+// log redacts their values.
+type changeView struct {
+	*Client
+	ClientSecretHash      string `json:"client_secret_hash,omitempty"`
+	RegistrationTokenHash string `json:"registration_token_hash,omitempty"`
+}
+
+// recordChange appends the diff between before and after to the change log.
+func (s *Service) recordChange(ctx context.Context, before, after *Client, actorID string) {
+	if s.changes == nil {
+		return
+	}
+	s.changes.Record(ctx, after.TenantID, audit.ResourceClient, after.ClientID, actorID,
+		changeView{before, before.ClientSecretHash, before.RegistrationTokenHash},
+		changeView{after, after.ClientSecretHash, after.RegistrationTokenHash})
+}
+
+func (s *Service) logTrustChange(ctx context.Context, c *Client, actorID string) {
+	metadata := map[string]any{
+		"client_id":  c.ClientID,
+		"is_trusted": c.IsTrusted,
+	}
+	if reason := audit.Reason(ctx); reason != "" {
+		metadata[audit.AttrReason] = reason
+	}
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeClientTrustChanged,
+		TenantID:   c.TenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceClient,
+		TargetName: c.ClientName,
+		TargetID:   c.ClientID,
+		Metadata:   metadata,
+	})
+}
+
+// AuthenticateClient authenticates a client at the token endpoint.
+//
+// Purpose: Token-time client authentication for confidential and public clients.
+// Domain: OAuth2
+// Security: Confidential clients must present their secret. Public clients must not
+// present one; they are identified only, and PKCE proves possession instead. The
+// client's network and token binding policy is enforced on req once it is identified.
+// Audited: No
+// Errors: ErrDomainInvalidClient, ErrSourceNotAllowed, ErrDPoPRequired, ErrMTLSRequired
+func (s *Service) AuthenticateClient(ctx context.Context, tenantID, clientID, secret string, req TokenRequest) (*Client, error) {
+	c, err := s.clientRepo.GetByClientID(ctx, tenantID, clientID)
+	if err != nil || !c.IsActive {
+		return nil, ErrDomainInvalidClient
+	}
+	if c.IsPublic() {
+		if secret != "" {
+			return nil, fmt.Errorf("%w: public clients must not authenticate with a secret", ErrDomainInvalidClient)
 		}
+	} else if !c.VerifySecret(secret) {
+		return nil, ErrDomainInvalidClient
+	}
+	if err := c.CheckTokenRequest(req); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ValidateClient checks client metadata without persisting it and returns the first
+// issue ValidateRegistration reports. Implicit-flow clients are rejected unless the
+// tenant allows them, and client_uri and logo_uri must satisfy the deployment's URIPolicy.
+func (s *Service) ValidateClient(ctx context.Context, c *Client) error {
+	issues, err := s.ValidateRegistration(ctx, c)
+	if err != nil {
+		return err
+	}
+	if len(issues) > 0 {
+		return issues[0]
+	}
+	return nil
+}
+
+// validateSigningAlg checks that the client can verify the tenant's ID tokens.
+func (s *Service) validateSigningAlg(ctx context.Context, c *Client) error {
+	if c.IDTokenSignedResponseAlg == "" {
+		return nil
+	}
+	if !jose.Supported(c.IDTokenSignedResponseAlg) {
+		return fmt.Errorf("%w: %s", ErrInvalidSigningAlg, c.IDTokenSignedResponseAlg)
+	}
+	if s.signing == nil {
+		return nil
+	}
+	alg, err := s.signing.SigningAlgorithm(ctx, c.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve tenant signing algorithm: %w", err)
+	}
+	if alg != c.IDTokenSignedResponseAlg {
+		return fmt.Errorf("%w: tenant signs with %s", ErrInvalidSigningAlg, alg)
 	}
 	return nil
 }
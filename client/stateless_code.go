@@ -0,0 +1,281 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+// MaxCodeLifetime is the longest an authorization code may remain redeemable.
+const MaxCodeLifetime = 10 * time.Minute
+
+// typStatelessCode is the JWE typ of a stateless authorization code.
+const typStatelessCode = "oauth-code+jwe"
+
+// UsedCodeCache remembers redeemed stateless authorization codes;
+// cache.Memory implements it for a single instance and cache.Redis for several.
+//
+// Purpose: Single-use enforcement without an authorization_codes row.
+// Domain: OAuth2
+type UsedCodeCache interface {
+	// Seen records key until expiresAt and reports whether it was already recorded
+	Seen(ctx context.Context, key string, expiresAt time.Time) (bool, error)
+}
+
+// statelessCode is the encrypted payload of a stateless authorization code.
+type statelessCode struct {
+	ID                  string `json:"jti"`
+	TenantID            string `json:"tid"`
+	SessionID           string `json:"sid,omitempty"`
+	ClientID            string `json:"cid"`
+	UserID              string `json:"sub"`
+	RedirectURI         string `json:"ru"`
+	Scope               string `json:"scp,omitempty"`
+	State               string `json:"st,omitempty"`
+	Nonce               string `json:"non,omitempty"`
+	CodeChallenge       string `json:"cc,omitempty"`
+	CodeChallengeMethod string `json:"ccm,omitempty"`
+	GrantID             string `json:"gid,omitempty"`
+	IssuedAt            int64  `json:"iat"`
+	ExpiresAt           int64  `json:"exp"`
+}
+
+// StatelessCodeRepository is an AuthorizationCodeRepository that stores nothing:
+// each code is the authorization request itself, encrypted as a JWE under a key
+// derived for the tenant.
+//
+// Purpose: Authorization codes without a database write per login, for high-throughput deployments.
+// Domain: OAuth2
+// Invariants: Codes are confidential and tamper-evident (dir/A256GCM) and only open in
+// the tenant they were issued in; their kid names that tenant. MarkAsUsed is the replay
+// check: it records the decrypted code ID in the UsedCodeCache and fails with
+// ErrCodeAlreadyUsed on a second call, however the code is spelled, so callers MUST
+// call it before issuing tokens. GetByCode never reports IsUsed. A code's session is only
+// re-checked when WithSessionCheck is set.
+type StatelessCodeRepository struct {
+	masterKey    []byte
+	used         UsedCodeCache
+	sessionCheck func(ctx context.Context, sessionID string) error
+
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+// StatelessCodeOption configures optional StatelessCodeRepository behavior.
+type StatelessCodeOption func(*StatelessCodeRepository)
+
+// WithSessionCheck rejects codes whose issuing session check reports as gone, which
+// keeps "destroying the session invalidates its codes" true without a stored row.
+func WithSessionCheck(check func(ctx context.Context, sessionID string) error) StatelessCodeOption {
+	return func(r *StatelessCodeRepository) { r.sessionCheck = check }
+}
+
+// NewStatelessCodeRepository creates a stateless code repository keyed by masterKey.
+//
+// Purpose: Constructor for self-contained authorization codes.
+// Domain: OAuth2
+// Security: Tenant keys are derived with HKDF (crypto.PurposeAuthorizationCode); the
+// master key itself never encrypts.
+// Audited: No
+// Errors: crypto.ErrEmptyMasterKey, missing used-code cache
+func NewStatelessCodeRepository(masterKey []byte, used UsedCodeCache, opts ...StatelessCodeOption) (*StatelessCodeRepository, error) {
+	if len(masterKey) == 0 {
+		return nil, crypto.ErrEmptyMasterKey
+	}
+	if used == nil {
+		return nil, errors.New("stateless authorization codes require a used-code cache")
+	}
+	r := &StatelessCodeRepository{
+		masterKey: masterKey,
+		used:      used,
+		keys:      make(map[string][]byte),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// Create encrypts code and stores the result in code.Code.
+func (r *StatelessCodeRepository) Create(code *AuthorizationCode) error {
+	now := time.Now()
+	if code.TenantID == "" {
+		return fmt.Errorf("failed to create authorization code: missing tenant")
+	}
+	if code.ExpiresAt.After(now.Add(MaxCodeLifetime)) {
+		return fmt.Errorf("failed to create authorization code: lifetime exceeds %s", MaxCodeLifetime)
+	}
+	if code.ID == "" {
+		code.ID = id.NewUUIDv7()
+	}
+	if code.CreatedAt.IsZero() {
+		code.CreatedAt = now
+	}
+
+	key, err := r.tenantKey(code.TenantID)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(statelessCode{
+		ID:                  code.ID,
+		TenantID:            code.TenantID,
+		SessionID:           code.SessionID,
+		ClientID:            code.ClientID,
+		UserID:              code.UserID,
+		RedirectURI:         code.RedirectURI,
+		Scope:               code.Scope,
+		State:               code.State,
+		Nonce:               code.Nonce,
+		CodeChallenge:       code.CodeChallenge,
+		CodeChallengeMethod: code.CodeChallengeMethod,
+		GrantID:             code.GrantID,
+		IssuedAt:            code.CreatedAt.Unix(),
+		ExpiresAt:           code.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode authorization code: %w", err)
+	}
+	compact, err := jose.Encrypt(key, jose.JWEHeader{Typ: typStatelessCode, Kid: code.TenantID}, payload)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt authorization code: %w", err)
+	}
+	code.Code = compact
+	return nil
+}
+
+// GetByCode decrypts a code issued in tenantID
+func (r *StatelessCodeRepository) GetByCode(tenantID, code string) (*AuthorizationCode, error) {
+	sc, err := r.open(tenantID, code)
+	if err != nil {
+		return nil, err
+	}
+	if r.sessionCheck != nil && sc.SessionID != "" {
+		if err := r.sessionCheck(context.Background(), sc.SessionID); err != nil {
+			return nil, ErrCodeNotFound
+		}
+	}
+
+	return &AuthorizationCode{
+		ID:                  sc.ID,
+		Code:                code,
+		TenantID:            sc.TenantID,
+		SessionID:           sc.SessionID,
+		ClientID:            sc.ClientID,
+		UserID:              sc.UserID,
+		RedirectURI:         sc.RedirectURI,
+		Scope:               sc.Scope,
+		State:               sc.State,
+		Nonce:               sc.Nonce,
+		CodeChallenge:       sc.CodeChallenge,
+		CodeChallengeMethod: sc.CodeChallengeMethod,
+		GrantID:             sc.GrantID,
+		ExpiresAt:           time.Unix(sc.ExpiresAt, 0),
+		CreatedAt:           time.Unix(sc.IssuedAt, 0),
+	}, nil
+}
+
+// MarkAsUsed records the code as redeemed; a second call fails with ErrCodeAlreadyUsed
+func (r *StatelessCodeRepository) MarkAsUsed(code string) error {
+	key, err := r.usedCodeKey(code)
+	if err != nil {
+		return err
+	}
+	seen, err := r.used.Seen(context.Background(), key, time.Now().Add(MaxCodeLifetime))
+	if err != nil {
+		return fmt.Errorf("failed to mark code as used: %w", err)
+	}
+	if seen {
+		return ErrCodeAlreadyUsed
+	}
+	return nil
+}
+
+// Delete makes the code unredeemable; codes that do not open already are
+func (r *StatelessCodeRepository) Delete(code string) error {
+	key, err := r.usedCodeKey(code)
+	if errors.Is(err, ErrCodeNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := r.used.Seen(context.Background(), key, time.Now().Add(MaxCodeLifetime)); err != nil {
+		return fmt.Errorf("failed to delete code: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired does nothing: expired codes fail to redeem and the cache prunes itself
+func (r *StatelessCodeRepository) DeleteExpired() error {
+	return nil
+}
+
+// tenantKey returns the code encryption key of tenantID, deriving it once.
+func (r *StatelessCodeRepository) tenantKey(tenantID string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key, ok := r.keys[tenantID]; ok {
+		return key, nil
+	}
+	derived, err := crypto.DeriveHMACKey(r.masterKey, crypto.PurposeAuthorizationCode, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive code key: %w", err)
+	}
+	r.keys[tenantID] = derived.Secret
+	return derived.Secret, nil
+}
+
+// open decrypts a code issued in tenantID.
+func (r *StatelessCodeRepository) open(tenantID, code string) (*statelessCode, error) {
+	key, err := r.tenantKey(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	h, payload, err := jose.Decrypt(key, code)
+	if err != nil || h.Typ != typStatelessCode {
+		return nil, ErrCodeNotFound
+	}
+	var sc statelessCode
+	if err := json.Unmarshal(payload, &sc); err != nil || sc.TenantID != tenantID || sc.ID == "" {
+		return nil, ErrCodeNotFound
+	}
+	return &sc, nil
+}
+
+// usedCodeKey is the replay cache key of a code: its tenant and decrypted ID, so every
+// spelling of one code shares a key. The code itself is never cached.
+func (r *StatelessCodeRepository) usedCodeKey(code string) (string, error) {
+	h, err := jose.ParseJWEHeader(code)
+	if err != nil || h.Kid == "" {
+		return "", ErrCodeNotFound
+	}
+	sc, err := r.open(h.Kid, code)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(sc.TenantID + "\x00" + sc.ID))
+	return "code:" + hex.EncodeToString(sum[:]), nil
+}
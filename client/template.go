@@ -0,0 +1,163 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// GrantTypeDeviceCode is the device authorization grant (RFC 8628 Section 3.4).
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+// GrantTypeDelegated lets a service account mint tokens for a user under a delegation
+// agreement (package delegation). Only confidential clients may register it.
+const GrantTypeDelegated = "urn:opentrusty:params:oauth:grant-type:delegated"
+
+// ErrUnknownTemplate is returned for a template name not in Templates.
+var ErrUnknownTemplate = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "unknown client template")
+
+// Template names a pre-canned client configuration for a common integration type.
+type Template string
+
+// Client templates
+const (
+	// TemplateSPA is a browser-based single-page application: public, PKCE, short-lived tokens.
+	TemplateSPA Template = "spa"
+	// TemplateWeb is a server-side web application holding a client secret.
+	TemplateWeb Template = "web"
+	// TemplateMobile is an installed mobile or desktop application: public, PKCE, long-lived refresh tokens.
+	TemplateMobile Template = "mobile"
+	// TemplateMachine is a machine-to-machine service using client credentials only.
+	TemplateMachine Template = "machine"
+	// TemplateDevice is an input-constrained device (TV, CLI) using the device authorization grant.
+	TemplateDevice Template = "device"
+)
+
+// templateSpec is the configuration a Template applies.
+type templateSpec struct {
+	applicationType string
+	authMethod      string
+	grantTypes      []string
+	responseTypes   []string
+	// Token lifetimes in seconds; 0 leaves the service default.
+	accessTokenLifetime  int
+	refreshTokenLifetime int
+	idTokenLifetime      int
+}
+
+var templates = map[Template]templateSpec{
+	TemplateSPA: {
+		applicationType:      ApplicationTypeSPA,
+		authMethod:           AuthMethodNone,
+		grantTypes:           []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken},
+		responseTypes:        []string{"code"},
+		accessTokenLifetime:  15 * 60,
+		refreshTokenLifetime: 24 * 60 * 60,
+		idTokenLifetime:      15 * 60,
+	},
+	TemplateWeb: {
+		applicationType:      ApplicationTypeWeb,
+		authMethod:           AuthMethodClientSecretBasic,
+		grantTypes:           []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken},
+		responseTypes:        []string{"code"},
+		accessTokenLifetime:  60 * 60,
+		refreshTokenLifetime: 30 * 24 * 60 * 60,
+		idTokenLifetime:      60 * 60,
+	},
+	TemplateMobile: {
+		applicationType:      ApplicationTypeNative,
+		authMethod:           AuthMethodNone,
+		grantTypes:           []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken},
+		responseTypes:        []string{"code"},
+		accessTokenLifetime:  30 * 60,
+		refreshTokenLifetime: 90 * 24 * 60 * 60,
+		idTokenLifetime:      60 * 60,
+	},
+	TemplateMachine: {
+		applicationType:     ApplicationTypeWeb,
+		authMethod:          AuthMethodClientSecretBasic,
+		grantTypes:          []string{GrantTypeClientCredentials},
+		accessTokenLifetime: 60 * 60,
+	},
+	TemplateDevice: {
+		applicationType:      ApplicationTypeNative,
+		authMethod:           AuthMethodNone,
+		grantTypes:           []string{GrantTypeDeviceCode, GrantTypeRefreshToken},
+		accessTokenLifetime:  60 * 60,
+		refreshTokenLifetime: 30 * 24 * 60 * 60,
+		idTokenLifetime:      60 * 60,
+	},
+}
+
+// Templates returns every client template, in a stable order.
+func Templates() []Template {
+	return []Template{TemplateSPA, TemplateWeb, TemplateMobile, TemplateMachine, TemplateDevice}
+}
+
+// Apply configures c for the integration type t.
+//
+// Purpose: Correct protocol settings for common integrations without hand-picking them.
+// Domain: OAuth2
+// Security: The application type, token endpoint auth method, grant types, and
+// response types always come from the template, so it cannot be combined with a
+// weaker setting; public templates (spa, mobile, device) thereby require PKCE
+// (Client.RequiresPKCE). Token lifetimes are set only where c leaves them zero, so a
+// caller may shorten them. Templates without an authorization grant get no redirect URIs.
+// Audited: No
+// Errors: ErrUnknownTemplate
+func (t Template) Apply(c *Client) error {
+	spec, ok := templates[t]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownTemplate, t)
+	}
+	c.ApplicationType = spec.applicationType
+	c.TokenEndpointAuthMethod = spec.authMethod
+	c.GrantTypes = slices.Clone(spec.grantTypes)
+	c.ResponseTypes = slices.Clone(spec.responseTypes)
+	if spec.authMethod == AuthMethodNone {
+		c.ClientSecretHash = ""
+	}
+	if !slices.Contains(spec.grantTypes, GrantTypeAuthorizationCode) {
+		c.RedirectURIs, c.AllowedOrigins = nil, nil
+	}
+	if c.AccessTokenLifetime == 0 {
+		c.AccessTokenLifetime = spec.accessTokenLifetime
+	}
+	if c.RefreshTokenLifetime == 0 {
+		c.RefreshTokenLifetime = spec.refreshTokenLifetime
+	}
+	if c.IDTokenLifetime == 0 {
+		c.IDTokenLifetime = spec.idTokenLifetime
+	}
+	return nil
+}
+
+// RegisterFromTemplate applies t to c and registers it.
+//
+// Purpose: Registration of a client for a common integration type.
+// Domain: OAuth2
+// Security: As Template.Apply and RegisterClient.
+// Audited: Yes (ClientCreated, ClientTrustChanged)
+// Errors: ErrUnknownTemplate, RegisterClient errors
+func (s *Service) RegisterFromTemplate(ctx context.Context, tenantID, userID string, t Template, c *Client) (*Client, error) {
+	if err := t.Apply(c); err != nil {
+		return nil, err
+	}
+	return s.RegisterClient(ctx, tenantID, userID, c)
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/blob"
+)
+
+// URI policy errors
+var (
+	ErrInvalidLogoURI = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid logo_uri")
+)
+
+// LogoFetcher downloads a remote logo so its content can be verified; the
+// transport implements it.
+type LogoFetcher interface {
+	// FetchLogo returns the declared content type and at most maxBytes+1 bytes of
+	// uri. It must connect only to addrs, which were already checked for SSRF, and
+	// must not follow redirects.
+	FetchLogo(ctx context.Context, uri string, addrs []netip.Addr, maxBytes int64) (contentType string, data []byte, err error)
+}
+
+// Resolver resolves host names; *net.Resolver implements it.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// URIPolicy restricts the client_uri and logo_uri a client may register.
+//
+// Purpose: Deployment-wide rules for URLs shown to end users on login and consent pages.
+// Domain: OAuth2
+// Invariants: The zero value accepts https URLs on public hosts and does not fetch logos.
+// Hosts that resolve to loopback, private, link-local, or otherwise non-public
+// addresses are never fetched unless AllowInsecure is set.
+type URIPolicy struct {
+	// AllowInsecure accepts plain http and non-public hosts; for development only.
+	AllowInsecure bool
+	// AllowedHosts limits URLs to these hosts; "*.example.com" matches any subdomain.
+	// Empty allows any public host.
+	AllowedHosts []string
+	// Fetcher, when set, downloads logo_uri and checks its type and size.
+	Fetcher LogoFetcher
+	// Resolver resolves logo hosts before fetching; nil uses net.DefaultResolver.
+	Resolver Resolver
+	// MaxLogoSize bounds a fetched logo; 0 uses blob.DefaultMaxImageSize.
+	MaxLogoSize int64
+}
+
+// validateURIs applies the service's URIPolicy to c. A logo stored through
+// UploadLogo was verified on upload and is exempt.
+func (s *Service) validateURIs(ctx context.Context, c *Client) error {
+	if c.ClientURI != "" {
+		if _, err := s.uris.check(c.ClientURI); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidClientURI, err)
+		}
+	}
+	if c.LogoURI == "" || (s.logos != nil && s.logos.IsClientLogo(c.TenantID, c.ClientID, c.LogoURI)) {
+		return nil
+	}
+	u, err := s.uris.check(c.LogoURI)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidLogoURI, err)
+	}
+	if s.uris.Fetcher != nil {
+		if err := s.uris.verifyLogo(ctx, u); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidLogoURI, err)
+		}
+	}
+	return nil
+}
+
+// check parses raw and enforces scheme, credentials, and host rules.
+func (p URIPolicy) check(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("%q must be an absolute URL", raw)
+	}
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && p.AllowInsecure:
+	default:
+		return nil, fmt.Errorf("%q must use https", raw)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("%q must not carry credentials", raw)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if !p.hostAllowed(host) {
+		return nil, fmt.Errorf("host %q is not allowed", host)
+	}
+	if p.AllowInsecure {
+		return u, nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return nil, fmt.Errorf("host %q is not public", host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublic(addr) {
+		return nil, fmt.Errorf("host %q is not public", host)
+	}
+	return u, nil
+}
+
+// hostAllowed matches host against AllowedHosts.
+func (p URIPolicy) hostAllowed(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(p.AllowedHosts, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			return strings.HasSuffix(host, "."+suffix)
+		}
+		return host == allowed
+	})
+}
+
+// verifyLogo resolves u's host, refuses non-public addresses, then fetches the
+// logo and checks that it is a supported image within the size limit.
+func (p URIPolicy) verifyLogo(ctx context.Context, u *url.URL) error {
+	var resolver Resolver = net.DefaultResolver
+	if p.Resolver != nil {
+		resolver = p.Resolver
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("host %q does not resolve", u.Hostname())
+	}
+	for i, addr := range addrs {
+		addrs[i] = addr.Unmap()
+		if !p.AllowInsecure && !isPublic(addrs[i]) {
+			return fmt.Errorf("host %q resolves to a non-public address", u.Hostname())
+		}
+	}
+
+	maxSize := p.MaxLogoSize
+	if maxSize <= 0 {
+		maxSize = blob.DefaultMaxImageSize
+	}
+	contentType, data, err := p.Fetcher.FetchLogo(ctx, u.String(), addrs, maxSize)
+	if err != nil {
+		return fmt.Errorf("failed to fetch logo: %s", err)
+	}
+	if int64(len(data)) > maxSize {
+		return fmt.Errorf("logo exceeds %d bytes", maxSize)
+	}
+	detected := blob.DetectImageType(data)
+	if detected == "" {
+		return fmt.Errorf("logo is not a supported image")
+	}
+	if mediaType, _, _ := strings.Cut(contentType, ";"); !strings.EqualFold(strings.TrimSpace(mediaType), detected) {
+		return fmt.Errorf("logo content type %q does not match its content", contentType)
+	}
+	return nil
+}
+
+// isPublic reports whether addr is a globally routable unicast address.
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which IsPrivate does not cover
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
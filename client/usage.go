@@ -0,0 +1,187 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+// Usage is the accumulated token activity of one client.
+//
+// Purpose: Reporting input for identifying stale or abused clients.
+// Domain: OAuth2
+// Invariants: TokensIssued only grows. LastUsedAt is nil until the first access token is issued.
+type Usage struct {
+	TenantID     string
+	ClientID     string
+	TokensIssued int64
+	LastUsedAt   *time.Time
+}
+
+// UsageReport is a client together with its usage, as shown to tenant admins.
+type UsageReport struct {
+	Client *Client
+	Usage  Usage
+	// Stale is true when the client has not been used within the requested window.
+	Stale bool
+}
+
+// UsageRepository defines persistence for per-client usage counters.
+//
+// Purpose: Durable counters that survive restarts and are shared across instances.
+// Domain: OAuth2
+type UsageRepository interface {
+	// AddUsage increments the counter of a client by issued and advances its last-used time to at
+	AddUsage(ctx context.Context, tenantID, clientID string, issued int64, at time.Time) error
+	// ListUsage retrieves the usage of every client in a tenant that has been used
+	ListUsage(ctx context.Context, tenantID string) ([]*Usage, error)
+}
+
+type usageKey struct {
+	tenantID string
+	clientID string
+}
+
+type usageDelta struct {
+	issued int64
+	last   time.Time
+}
+
+// UsageRecorder buffers token issuance in memory and writes it in batches.
+//
+// Purpose: Keeps usage tracking off the token endpoint's critical path.
+// Domain: OAuth2
+// Invariants: Only access tokens are counted, so one token response counts once.
+// Deltas that fail to flush are kept and retried on the next Flush.
+type UsageRecorder struct {
+	repo    UsageRepository
+	mu      sync.Mutex
+	pending map[usageKey]usageDelta
+}
+
+// NewUsageRecorder creates a recorder that flushes to repo.
+func NewUsageRecorder(repo UsageRepository) *UsageRecorder {
+	return &UsageRecorder{repo: repo, pending: make(map[usageKey]usageDelta)}
+}
+
+// HandleEvent records events.TokenIssued for access tokens; it is an events.Handler.
+func (r *UsageRecorder) HandleEvent(_ context.Context, e events.Event) error {
+	ev, ok := e.(events.TokenIssued)
+	if !ok || ev.Kind != events.TokenKindAccess || ev.TenantID == "" || ev.ClientID == "" {
+		return nil
+	}
+	r.Record(ev.TenantID, ev.ClientID, ev.OccurredAt)
+	return nil
+}
+
+// Record counts one token issued to clientID at at.
+func (r *UsageRecorder) Record(tenantID, clientID string, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.merge(usageKey{tenantID, clientID}, usageDelta{issued: 1, last: at})
+}
+
+// Flush writes buffered usage to the repository.
+//
+// Purpose: Periodic and shutdown-time persistence of buffered counters.
+// Domain: OAuth2
+// Audited: No
+// Errors: Joined repository errors (failed deltas are re-buffered)
+func (r *UsageRecorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[usageKey]usageDelta)
+	r.mu.Unlock()
+
+	var errs []error
+	for k, d := range batch {
+		if err := r.repo.AddUsage(ctx, k.tenantID, k.clientID, d.issued, d.last); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record usage of client %s: %w", k.clientID, err))
+			r.mu.Lock()
+			r.merge(k, d)
+			r.mu.Unlock()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// merge adds d to the pending delta of k. The caller holds r.mu.
+func (r *UsageRecorder) merge(k usageKey, d usageDelta) {
+	cur := r.pending[k]
+	cur.issued += d.issued
+	if d.last.After(cur.last) {
+		cur.last = d.last
+	}
+	r.pending[k] = cur
+}
+
+// ClientUsage reports token activity for every client of a tenant.
+//
+// Purpose: Lets tenant admins find clients that are unused or issuing unusually many tokens.
+// Domain: OAuth2
+// Audited: No
+// Errors: ErrUsageUnavailable, system errors
+func (s *Service) ClientUsage(ctx context.Context, tenantID string, staleAfter time.Duration) ([]*UsageReport, error) {
+	if s.usage == nil {
+		return nil, ErrUsageUnavailable
+	}
+	clients, err := s.clientRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+	usage, err := s.usage.ListUsage(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list client usage: %w", err)
+	}
+	byClient := make(map[string]*Usage, len(usage))
+	for _, u := range usage {
+		byClient[u.ClientID] = u
+	}
+
+	cutoff := s.clock.Now().Add(-staleAfter)
+	reports := make([]*UsageReport, 0, len(clients))
+	for _, c := range clients {
+		r := &UsageReport{Client: c, Usage: Usage{TenantID: tenantID, ClientID: c.ClientID}}
+		if u, ok := byClient[c.ClientID]; ok {
+			r.Usage = *u
+		}
+		if r.Usage.LastUsedAt != nil {
+			r.Stale = r.Usage.LastUsedAt.Before(cutoff)
+		} else {
+			r.Stale = c.CreatedAt.Before(cutoff)
+		}
+		reports = append(reports, r)
+	}
+	// Least recently used first; never-used clients lead.
+	slices.SortStableFunc(reports, func(a, b *UsageReport) int {
+		switch {
+		case a.Usage.LastUsedAt == nil && b.Usage.LastUsedAt == nil:
+			return 0
+		case a.Usage.LastUsedAt == nil:
+			return -1
+		case b.Usage.LastUsedAt == nil:
+			return 1
+		}
+		return a.Usage.LastUsedAt.Compare(*b.Usage.LastUsedAt)
+	})
+	return reports, nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/feature"
+)
+
+// GrantTypeImplicit is the implicit grant (RFC 6749 Section 4.2), registrable only
+// where feature.ImplicitFlowAllowed is on.
+const GrantTypeImplicit = "implicit"
+
+// knownGrantTypes are the grant types a client may register.
+var knownGrantTypes = []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials, GrantTypeDeviceCode, GrantTypeImplicit, GrantTypeDelegated}
+
+// knownAuthMethods are the token endpoint authentication methods a client may register.
+var knownAuthMethods = []string{AuthMethodClientSecretBasic, AuthMethodClientSecretPost, AuthMethodNone}
+
+// FieldError is a problem with one field of a client registration.
+//
+// Purpose: Inline validation feedback, so registration forms can mark each invalid
+// field instead of only the first.
+// Domain: OAuth2
+// Invariants: Field is the field's JSON name, indexed for list entries
+// ("redirect_uris[1]"). Unwraps to a classified client error (ErrInvalidRedirectURI,
+// ErrDomainInvalidScope, ...).
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// Error returns the field name and the wrapped error's message.
+func (e *FieldError) Error() string { return e.Field + ": " + e.Err.Error() }
+
+// Unwrap returns the field's validation error.
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// ScopeRegistry resolves the scopes a tenant defines beyond the OIDC standard scopes.
+type ScopeRegistry interface {
+	Scopes(ctx context.Context, tenantID string) ([]string, error)
+}
+
+// WithScopeRegistry requires a client's allowed scopes to be OIDC standard scopes or
+// scopes r defines for its tenant. Without it any well-formed scope is accepted.
+func WithScopeRegistry(r ScopeRegistry) Option {
+	return func(s *Service) { s.scopes = r }
+}
+
+// ValidateRegistration checks client metadata without persisting it and reports every
+// invalid field.
+//
+// Purpose: Dry-run validation for registration and edit forms.
+// Domain: OAuth2
+// Security: Runs exactly the checks RegisterClient runs (ValidateClient returns the
+// first of these issues), so a client without issues is one RegisterClient accepts.
+// Only the logo may be fetched, when the URIPolicy has a Fetcher; nothing is stored
+// or audited.
+// Audited: No
+// Errors: System errors (tenant signing algorithm or scope registry lookups)
+func (s *Service) ValidateRegistration(ctx context.Context, c *Client) ([]*FieldError, error) {
+	v := &fieldValidator{}
+
+	if c.ClientURI != "" {
+		if _, err := url.ParseRequestURI(c.ClientURI); err != nil {
+			v.add("client_uri", fmt.Errorf("%w: %s", ErrInvalidClientURI, err))
+		}
+	}
+	if err := s.validateURIs(ctx, c); err != nil {
+		if errors.Is(err, ErrInvalidLogoURI) {
+			v.add("logo_uri", err)
+		} else if !v.has("client_uri") {
+			v.add("client_uri", err)
+		}
+	}
+
+	for i, uri := range c.RedirectURIs {
+		if _, err := url.ParseRequestURI(uri); err != nil {
+			v.add(indexed("redirect_uris", i), fmt.Errorf("%w: %s", ErrInvalidRedirectURI, uri))
+		}
+	}
+	if len(c.RedirectURIs) == 0 && slices.Contains(c.GrantTypes, GrantTypeAuthorizationCode) {
+		v.add("redirect_uris", fmt.Errorf("%w: required for the authorization_code grant", ErrInvalidRedirectURI))
+	}
+
+	switch c.ApplicationType {
+	case "", ApplicationTypeWeb, ApplicationTypeNative, ApplicationTypeSPA:
+	default:
+		v.add("application_type", fmt.Errorf("%w: %s", ErrInvalidApplicationType, c.ApplicationType))
+	}
+
+	switch {
+	case c.TokenEndpointAuthMethod != "" && !slices.Contains(knownAuthMethods, c.TokenEndpointAuthMethod):
+		v.add("token_endpoint_auth_method", fmt.Errorf("%w: %s", ErrInvalidAuthMethod, c.TokenEndpointAuthMethod))
+	case c.IsPublic():
+		if c.ClientSecretHash != "" {
+			v.add("token_endpoint_auth_method", fmt.Errorf("%w: public clients do not have a secret", ErrInvalidAuthMethod))
+		}
+		if slices.Contains(c.GrantTypes, GrantTypeClientCredentials) {
+			v.add("grant_types", fmt.Errorf("%w: public clients cannot use client_credentials", ErrDomainInvalidGrantType))
+		}
+		if slices.Contains(c.GrantTypes, GrantTypeDelegated) {
+			v.add("grant_types", fmt.Errorf("%w: public clients cannot use delegated grants", ErrDomainInvalidGrantType))
+		}
+	case c.ApplicationType == ApplicationTypeNative || c.ApplicationType == ApplicationTypeSPA:
+		v.add("token_endpoint_auth_method", fmt.Errorf("%w: %s clients must use token_endpoint_auth_method \"none\"", ErrInvalidAuthMethod, c.ApplicationType))
+	}
+
+	for _, gt := range c.GrantTypes {
+		if !slices.Contains(knownGrantTypes, gt) {
+			v.add("grant_types", fmt.Errorf("%w: %s", ErrDomainInvalidGrantType, gt))
+		}
+	}
+	for i, rt := range c.ResponseTypes {
+		if err := validateResponseType(c, rt); err != nil {
+			v.add(indexed("response_types", i), err)
+		}
+	}
+	if c.UsesImplicitFlow() && !s.features.Enabled(ctx, c.TenantID, feature.ImplicitFlowAllowed) {
+		v.add("grant_types", fmt.Errorf("%w: implicit flow is disabled", ErrDomainInvalidGrantType))
+	}
+
+	if err := s.validateScopes(ctx, c, v); err != nil {
+		return nil, err
+	}
+
+	if c.ApplicationType == ApplicationTypeNative && len(c.AllowedOrigins) > 0 {
+		v.add("allowed_origins", fmt.Errorf("%w: native clients do not make browser requests", ErrInvalidOrigin))
+	}
+	for i, origin := range c.AllowedOrigins {
+		v.add(indexed("allowed_origins", i), validateOrigin(origin))
+	}
+	for i, contact := range c.Contacts {
+		v.add(indexed("contacts", i), validateContact(contact))
+	}
+	for i, cidr := range c.AllowedCIDRs {
+		v.add(indexed("allowed_cidrs", i), validateCIDR(cidr))
+	}
+	if c.ClaimMapping != nil {
+		v.add("claim_mapping", c.ClaimMapping.Validate())
+	}
+	v.add("resources", validateResources(c))
+	switch c.SubjectType {
+	case "", SubjectTypePublic:
+	case SubjectTypePairwise:
+		if c.Sector() == "" {
+			v.add("sector_identifier", fmt.Errorf("%w: pairwise clients need a sector_identifier unless all redirect URIs share one host", ErrInvalidClientMetadata))
+		}
+	default:
+		v.add("subject_type", fmt.Errorf("%w: subject type %q is not supported", ErrInvalidClientMetadata, c.SubjectType))
+	}
+	if c.SectorIdentifier != "" && !validSectorIdentifier(c.SectorIdentifier) {
+		v.add("sector_identifier", fmt.Errorf("%w: %q is not a lowercase host name", ErrInvalidClientMetadata, c.SectorIdentifier))
+	}
+
+	if err := s.validateSigningAlg(ctx, c); err != nil {
+		if _, ok := apperror.As(err); !ok {
+			return nil, err
+		}
+		v.add("id_token_signed_response_alg", err)
+	}
+	return v.issues, nil
+}
+
+// validateScopes checks the syntax of c's allowed scopes (RFC 6749 Section 3.3) and,
+// with a ScopeRegistry, that each is an OIDC standard scope or defined for the tenant.
+func (s *Service) validateScopes(ctx context.Context, c *Client, v *fieldValidator) error {
+	var defined []string
+	if s.scopes != nil {
+		var err error
+		if defined, err = s.scopes.Scopes(ctx, c.TenantID); err != nil {
+			return fmt.Errorf("failed to resolve tenant scopes: %w", err)
+		}
+	}
+	for i, sc := range c.AllowedScopes {
+		switch {
+		case sc == "*" || OIDCScopes[sc]:
+		case !validScopeToken(sc):
+			v.add(indexed("allowed_scopes", i), fmt.Errorf("%w: %q is not a valid scope", ErrDomainInvalidScope, sc))
+		case s.scopes != nil && !slices.Contains(defined, sc):
+			v.add(indexed("allowed_scopes", i), fmt.Errorf("%w: %q is not defined for the tenant", ErrDomainInvalidScope, sc))
+		}
+	}
+	return nil
+}
+
+// validSectorIdentifier reports whether sector is a bare lowercase host name, without
+// scheme, port, or path.
+func validSectorIdentifier(sector string) bool {
+	u, err := url.Parse("https://" + sector)
+	return err == nil && u.Host == sector && u.Hostname() == sector && sector == strings.ToLower(sector)
+}
+
+// validateResponseType checks one registered response type: a set of "code", "token",
+// and "id_token", where "code" needs the authorization_code grant.
+func validateResponseType(c *Client, rt string) error {
+	values := strings.Fields(rt)
+	if len(values) == 0 {
+		return fmt.Errorf("%w: empty response type", ErrInvalidClientMetadata)
+	}
+	for i, val := range values {
+		if (val != "code" && val != "token" && val != "id_token") || slices.Contains(values[:i], val) {
+			return fmt.Errorf("%w: response type %q is not supported", ErrInvalidClientMetadata, rt)
+		}
+	}
+	if slices.Contains(values, "code") && !slices.Contains(c.GrantTypes, GrantTypeAuthorizationCode) {
+		return fmt.Errorf("%w: response type %q requires the authorization_code grant", ErrInvalidClientMetadata, rt)
+	}
+	return nil
+}
+
+// validScopeToken reports whether sc is a scope-token: printable ASCII without
+// space, double quote, or backslash (RFC 6749 Section 3.3).
+func validScopeToken(sc string) bool {
+	if sc == "" {
+		return false
+	}
+	for _, r := range sc {
+		if r < 0x21 || r > 0x7e || r == '"' || r == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+// indexed returns the field name of entry i of a list field.
+func indexed(field string, i int) string {
+	return fmt.Sprintf("%s[%d]", field, i)
+}
+
+// fieldValidator collects the issues of one validation run.
+type fieldValidator struct {
+	issues []*FieldError
+}
+
+// add records err against field; nil errors are ignored.
+func (v *fieldValidator) add(field string, err error) {
+	if err != nil {
+		v.issues = append(v.issues, &FieldError{Field: field, Err: err})
+	}
+}
+
+// has reports whether field already has an issue.
+func (v *fieldValidator) has(field string) bool {
+	return slices.ContainsFunc(v.issues, func(e *FieldError) bool { return e.Field == field })
+}
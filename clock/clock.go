@@ -0,0 +1,44 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clock abstracts the current time. Services read time through a Clock
+// so expiry, lockout, and ordering logic can be tested against a fixed instant.
+package clock
+
+import "time"
+
+// Clock reports the current time.
+//
+// Purpose: Injectable time source for services.
+// Domain: Platform
+// Invariants: Implementations are safe for concurrent use.
+type Clock interface {
+	Now() time.Time
+}
+
+// Func adapts an ordinary function to a Clock.
+type Func func() time.Time
+
+// Now calls f.
+func (f Func) Now() time.Time { return f() }
+
+// System returns the wall clock, the default for every service.
+func System() Clock {
+	return Func(time.Now)
+}
+
+// Fixed returns a Clock that always reports t.
+func Fixed(t time.Time) Clock {
+	return Func(func() time.Time { return t })
+}
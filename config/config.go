@@ -0,0 +1,278 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/feature"
+	"github.com/opentrusty/opentrusty-core/maintenance"
+	"github.com/opentrusty/opentrusty-core/store/postgres"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// Domain errors
+var (
+	ErrInvalidConfig = errors.New("invalid configuration")
+)
+
+// Environment names recognized by Validate.
+const (
+	EnvDev  = "dev"
+	EnvProd = "prod"
+)
+
+// minIdentitySecretLength is the minimum accepted length of the identity HMAC
+// secret in production.
+const minIdentitySecretLength = 32
+
+// Config is the complete runtime configuration for the core services.
+//
+// Purpose: Single typed source for values that were previously threaded through positional constructor arguments.
+// Domain: Platform
+// Invariants: Must pass Validate before use. Secret fields hold resolved values only after Load.
+type Config struct {
+	Env      string         `json:"env"`
+	Database DatabaseConfig `json:"database"`
+	Identity IdentityConfig `json:"identity"`
+	Password PasswordConfig `json:"password"`
+	Session  SessionConfig  `json:"session"`
+	// Features sets deployment values for feature flags, keyed by flag name.
+	Features map[string]bool `json:"features,omitempty"`
+	// Mode starts the platform in "read_only" or "maintenance" mode; "" is "normal".
+	Mode string `json:"mode,omitempty"`
+}
+
+// DatabaseConfig holds PostgreSQL connectivity settings.
+//
+// Purpose: Discrete database fields per the environment contract.
+// Domain: Platform (Infrastructure)
+type DatabaseConfig struct {
+	Host         string `json:"host"`
+	Port         string `json:"port"`
+	User         string `json:"user"`
+	Password     Secret `json:"password"`
+	Name         string `json:"name"`
+	SSLMode      string `json:"sslmode"`
+	MaxOpenConns int    `json:"max_open_conns"`
+	MaxIdleConns int    `json:"max_idle_conns"`
+}
+
+// IdentityConfig holds identity hashing, lockout, and password change settings.
+//
+// Purpose: Inputs to the identity service.
+// Domain: Identity
+type IdentityConfig struct {
+	Secret             Secret   `json:"secret"`
+	LockoutMaxAttempts int      `json:"lockout_max_attempts"`
+	LockoutDuration    Duration `json:"lockout_duration"`
+	// KeepSessionsOnPasswordChange leaves a user's other sessions and tokens valid
+	// when their password changes. Off by default: they are revoked.
+	KeepSessionsOnPasswordChange bool `json:"keep_sessions_on_password_change,omitempty"`
+}
+
+// PasswordConfig holds Argon2id cost parameters.
+//
+// Purpose: Tunable password hashing cost.
+// Domain: Identity
+type PasswordConfig struct {
+	Memory      uint32 `json:"memory"`
+	Iterations  uint32 `json:"iterations"`
+	Parallelism uint8  `json:"parallelism"`
+	SaltLength  uint32 `json:"salt_length"`
+	KeyLength   uint32 `json:"key_length"`
+}
+
+// SessionConfig holds session lifetime settings.
+//
+// Purpose: Inputs to the session service.
+// Domain: Session
+type SessionConfig struct {
+	Secret      Secret   `json:"secret"`
+	Lifetime    Duration `json:"lifetime"`
+	IdleTimeout Duration `json:"idle_timeout"`
+}
+
+// Duration is a time.Duration that is encoded as a Go duration string ("15m").
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string such as "15m" or "24h".
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Default returns a configuration populated with safe defaults.
+//
+// Purpose: Baseline onto which files and environment variables are layered.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func Default() *Config {
+	return &Config{
+		Env: EnvDev,
+		Database: DatabaseConfig{
+			Port:    "5432",
+			SSLMode: "disable",
+		},
+		Identity: IdentityConfig{
+			LockoutMaxAttempts: 5,
+			LockoutDuration:    Duration(15 * time.Minute),
+		},
+		Password: PasswordConfig{
+			Memory:      64 * 1024,
+			Iterations:  3,
+			Parallelism: 2,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+		Session: SessionConfig{
+			Lifetime:    Duration(24 * time.Hour),
+			IdleTimeout: Duration(30 * time.Minute),
+		},
+	}
+}
+
+// Validate checks the configuration for missing or out-of-range values.
+//
+// Purpose: Fail-fast startup check so misconfiguration never reaches the services.
+// Domain: Platform
+// Audited: No
+// Errors: ErrInvalidConfig (wrapping every problem found)
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Env != EnvDev && c.Env != EnvProd {
+		problems = append(problems, fmt.Sprintf("env must be %q or %q", EnvDev, EnvProd))
+	}
+
+	if c.Database.Host == "" {
+		problems = append(problems, "database.host is required")
+	}
+	if c.Database.User == "" {
+		problems = append(problems, "database.user is required")
+	}
+	if c.Database.Name == "" {
+		problems = append(problems, "database.name is required")
+	}
+
+	if c.Env == EnvProd {
+		if c.Database.Password == "" {
+			problems = append(problems, "database.password is required in prod")
+		}
+		if len(c.Identity.Secret) < minIdentitySecretLength {
+			problems = append(problems, fmt.Sprintf("identity.secret must be at least %d bytes in prod", minIdentitySecretLength))
+		}
+	}
+	if c.Identity.Secret == "" {
+		problems = append(problems, "identity.secret is required")
+	}
+	if c.Identity.LockoutMaxAttempts <= 0 {
+		problems = append(problems, "identity.lockout_max_attempts must be positive")
+	}
+	if c.Identity.LockoutDuration <= 0 {
+		problems = append(problems, "identity.lockout_duration must be positive")
+	}
+
+	if c.Password.Iterations == 0 {
+		problems = append(problems, "password.iterations must be positive")
+	}
+	if c.Password.Parallelism == 0 {
+		problems = append(problems, "password.parallelism must be positive")
+	}
+	if c.Password.Memory < 8*uint32(c.Password.Parallelism) {
+		problems = append(problems, "password.memory must be at least 8 KiB per lane")
+	}
+	if c.Password.SaltLength < 8 {
+		problems = append(problems, "password.salt_length must be at least 8")
+	}
+	if c.Password.KeyLength < 16 {
+		problems = append(problems, "password.key_length must be at least 16")
+	}
+
+	if c.Session.Lifetime <= 0 {
+		problems = append(problems, "session.lifetime must be positive")
+	}
+	if c.Session.IdleTimeout <= 0 || c.Session.IdleTimeout > c.Session.Lifetime {
+		problems = append(problems, "session.idle_timeout must be positive and not exceed session.lifetime")
+	}
+
+	if _, err := maintenance.ParseMode(c.Mode); err != nil {
+		problems = append(problems, fmt.Sprintf("mode must be %q, %q, or %q", maintenance.ModeNormal, maintenance.ModeReadOnly, maintenance.ModeMaintenance))
+	}
+
+	for name := range c.Features {
+		if !feature.IsKnown(name) {
+			problems = append(problems, fmt.Sprintf("features.%s is not a known feature flag", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// PostgresConfig returns the connection settings for postgres.New.
+func (c *Config) PostgresConfig() postgres.Config {
+	return postgres.Config{
+		Host:         c.Database.Host,
+		Port:         c.Database.Port,
+		User:         c.Database.User,
+		Password:     string(c.Database.Password),
+		Database:     c.Database.Name,
+		SSLMode:      c.Database.SSLMode,
+		MaxOpenConns: c.Database.MaxOpenConns,
+		MaxIdleConns: c.Database.MaxIdleConns,
+	}
+}
+
+// FeatureFlags returns the deployment feature flag values.
+func (c *Config) FeatureFlags() feature.Static {
+	flags := make(feature.Static, len(c.Features))
+	for name, enabled := range c.Features {
+		flags[feature.Flag(name)] = enabled
+	}
+	return flags
+}
+
+// PlatformMode returns the mode the platform starts in.
+func (c *Config) PlatformMode() maintenance.Mode {
+	m, _ := maintenance.ParseMode(c.Mode)
+	return m
+}
+
+// PasswordHasher returns an identity password hasher built from the Argon2id parameters.
+func (c *Config) PasswordHasher() *user.PasswordHasher {
+	p := c.Password
+	return user.NewPasswordHasher(p.Memory, p.Iterations, p.Parallelism, p.SaltLength, p.KeyLength)
+}
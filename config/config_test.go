@@ -0,0 +1,166 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/maintenance"
+)
+
+func validConfig() *Config {
+	cfg := Default()
+	cfg.Database.Host = "localhost"
+	cfg.Database.User = "opentrusty"
+	cfg.Database.Name = "opentrusty"
+	cfg.Identity.Secret = "dev-identity-secret"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr bool
+	}{
+		{"valid dev", func(*Config) {}, false},
+		{"missing host", func(c *Config) { c.Database.Host = "" }, true},
+		{"missing identity secret", func(c *Config) { c.Identity.Secret = "" }, true},
+		{"short secret in prod", func(c *Config) { c.Env = EnvProd; c.Database.Password = "pw" }, true},
+		{"unknown env", func(c *Config) { c.Env = "staging" }, true},
+		{"zero lockout attempts", func(c *Config) { c.Identity.LockoutMaxAttempts = 0 }, true},
+		{"zero argon2 iterations", func(c *Config) { c.Password.Iterations = 0 }, true},
+		{"idle exceeds lifetime", func(c *Config) { c.Session.IdleTimeout = c.Session.Lifetime + 1 }, true},
+		{"known feature flag", func(c *Config) { c.Features = map[string]bool{"dpop_required": true} }, false},
+		{"unknown feature flag", func(c *Config) { c.Features = map[string]bool{"dpop_requried": true} }, true},
+		{"read-only mode", func(c *Config) { c.Mode = "read_only" }, false},
+		{"unknown mode", func(c *Config) { c.Mode = "readonly" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Validate() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		EnvVarDBHost:                       "db.internal",
+		EnvVarIdentitySecret:               "file:/run/secrets/identity",
+		EnvVarLockoutMaxAttempts:           "7",
+		EnvVarKeepSessionsOnPasswordChange: "true",
+		EnvVarSessionIdleTimeout:           "10m",
+		EnvVarFeatures:                     "dpop_required, legacy_hash_login=false",
+		EnvVarMode:                         "maintenance",
+	}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+
+	cfg := Default()
+	if err := cfg.applyEnv(lookup); err != nil {
+		t.Fatalf("applyEnv() error = %v", err)
+	}
+
+	if cfg.Database.Host != "db.internal" {
+		t.Errorf("Database.Host = %q", cfg.Database.Host)
+	}
+	if cfg.Database.Port != "5432" {
+		t.Errorf("Database.Port default lost: %q", cfg.Database.Port)
+	}
+	if cfg.Identity.Secret != "file:/run/secrets/identity" {
+		t.Errorf("Identity.Secret reference not preserved")
+	}
+	if cfg.Identity.LockoutMaxAttempts != 7 {
+		t.Errorf("LockoutMaxAttempts = %d, want 7", cfg.Identity.LockoutMaxAttempts)
+	}
+	if !cfg.Identity.KeepSessionsOnPasswordChange {
+		t.Errorf("KeepSessionsOnPasswordChange not applied")
+	}
+	if time.Duration(cfg.Session.IdleTimeout) != 10*time.Minute {
+		t.Errorf("IdleTimeout = %v, want 10m", time.Duration(cfg.Session.IdleTimeout))
+	}
+
+	if flags := cfg.FeatureFlags(); !flags["dpop_required"] || flags["legacy_hash_login"] {
+		t.Errorf("FeatureFlags() = %v, want dpop_required on and legacy_hash_login off", flags)
+	}
+	if cfg.PlatformMode() != maintenance.ModeMaintenance {
+		t.Errorf("PlatformMode() = %q, want maintenance", cfg.PlatformMode())
+	}
+
+	env[EnvVarLockoutDuration] = "soon"
+	if err := Default().applyEnv(lookup); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("applyEnv() with bad duration error = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestLoadFileAndResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "identity")
+	if err := os.WriteFile(secretPath, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgPath := filepath.Join(dir, "config.json")
+	body := fmt.Sprintf(`{
+		"database": {"host": "h", "user": "u", "name": "n", "password": "vault:kv/db#password"},
+		"identity": {"secret": "file:%s", "lockout_duration": "1h"}
+	}`, secretPath)
+	if err := os.WriteFile(cfgPath, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	if err := cfg.loadFile(cfgPath); err != nil {
+		t.Fatalf("loadFile() error = %v", err)
+	}
+	if time.Duration(cfg.Identity.LockoutDuration) != time.Hour {
+		t.Errorf("LockoutDuration = %v, want 1h", time.Duration(cfg.Identity.LockoutDuration))
+	}
+
+	r := NewSecretResolver()
+	if err := cfg.ResolveSecrets(context.Background(), r); !errors.Is(err, ErrSecretSchemeNotFound) {
+		t.Fatalf("ResolveSecrets() without vault provider error = %v, want ErrSecretSchemeNotFound", err)
+	}
+
+	r.Register("vault", SecretProviderFunc(func(_ context.Context, ref string) (string, error) {
+		return "resolved:" + ref, nil
+	}))
+	if err := cfg.ResolveSecrets(context.Background(), r); err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+	if cfg.Identity.Secret != "from-file" {
+		t.Errorf("Identity.Secret not resolved from file")
+	}
+	if cfg.Database.Password != "resolved:kv/db#password" {
+		t.Errorf("Database.Password not resolved from vault provider")
+	}
+	if got := fmt.Sprint(cfg.Identity.Secret); got != "[REDACTED]" {
+		t.Errorf("Secret formatted as %q, want redacted", got)
+	}
+}
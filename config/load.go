@@ -0,0 +1,203 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variable names. See docs/_ai/env-contract.md.
+const (
+	EnvVarEnv                = "OPENTRUSTY_ENV"
+	EnvVarDBHost             = "OPENTRUSTY_DB_HOST"
+	EnvVarDBPort             = "OPENTRUSTY_DB_PORT"
+	EnvVarDBUser             = "OPENTRUSTY_DB_USER"
+	EnvVarDBPassword         = "OPENTRUSTY_DB_PASSWORD"
+	EnvVarDBName             = "OPENTRUSTY_DB_NAME"
+	EnvVarDBSSLMode          = "OPENTRUSTY_DB_SSLMODE"
+	EnvVarIdentitySecret     = "OPENTRUSTY_IDENTITY_SECRET"
+	EnvVarLockoutMaxAttempts = "OPENTRUSTY_LOCKOUT_MAX_ATTEMPTS"
+	EnvVarLockoutDuration    = "OPENTRUSTY_LOCKOUT_DURATION"
+	// EnvVarKeepSessionsOnPasswordChange is a bool; true leaves other sessions valid after a password change.
+	EnvVarKeepSessionsOnPasswordChange = "OPENTRUSTY_KEEP_SESSIONS_ON_PASSWORD_CHANGE"
+	EnvVarSessionSecret                = "OPENTRUSTY_SESSION_SECRET"
+	EnvVarSessionLifetime              = "OPENTRUSTY_SESSION_LIFETIME"
+	EnvVarSessionIdleTimeout           = "OPENTRUSTY_SESSION_IDLE_TIMEOUT"
+	// EnvVarFeatures holds comma-separated flag=bool pairs, e.g. "dpop_required=true,legacy_hash_login=false".
+	EnvVarFeatures = "OPENTRUSTY_FEATURES"
+	// EnvVarMode is the platform mode: normal, read_only, or maintenance.
+	EnvVarMode = "OPENTRUSTY_MODE"
+)
+
+// Load builds a configuration from defaults, an optional JSON file, and the
+// process environment, resolves secret references, and validates the result.
+//
+// Purpose: One-call configuration for host binaries.
+// Domain: Platform
+// Security: Secret references are resolved after layering so files can point at env or file secrets.
+// Audited: No
+// Errors: File read/parse errors, secret resolution errors, ErrInvalidConfig
+func Load(ctx context.Context, path string, resolver *SecretResolver) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if resolver == nil {
+		resolver = NewSecretResolver()
+	}
+	if err := cfg.ResolveSecrets(ctx, resolver); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ResolveSecrets replaces every secret reference in the configuration with its
+// value. The configuration is left unchanged if any reference fails to resolve.
+func (c *Config) ResolveSecrets(ctx context.Context, r *SecretResolver) error {
+	secrets := []struct {
+		name string
+		dst  *Secret
+	}{
+		{"database.password", &c.Database.Password},
+		{"identity.secret", &c.Identity.Secret},
+		{"session.secret", &c.Session.Secret},
+	}
+
+	resolved := make([]string, len(secrets))
+	for i, s := range secrets {
+		v, err := r.Resolve(ctx, *s.dst)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		resolved[i] = v
+	}
+	for i, s := range secrets {
+		*s.dst = Secret(resolved[i])
+	}
+	return nil
+}
+
+// loadFile overlays values from a JSON file. Fields absent from the file keep their current values.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return nil
+}
+
+// applyEnv overlays values from environment variables using lookup.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	setString := func(name string, dst *string) {
+		if v, ok := lookup(name); ok {
+			*dst = v
+		}
+	}
+	setSecret := func(name string, dst *Secret) {
+		if v, ok := lookup(name); ok {
+			*dst = Secret(v)
+		}
+	}
+	setDuration := func(name string, dst *Duration) error {
+		if v, ok := lookup(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, name, err)
+			}
+			*dst = Duration(d)
+		}
+		return nil
+	}
+
+	setString(EnvVarEnv, &c.Env)
+	setString(EnvVarDBHost, &c.Database.Host)
+	setString(EnvVarDBPort, &c.Database.Port)
+	setString(EnvVarDBUser, &c.Database.User)
+	setSecret(EnvVarDBPassword, &c.Database.Password)
+	setString(EnvVarDBName, &c.Database.Name)
+	setString(EnvVarDBSSLMode, &c.Database.SSLMode)
+	setSecret(EnvVarIdentitySecret, &c.Identity.Secret)
+	setSecret(EnvVarSessionSecret, &c.Session.Secret)
+	setString(EnvVarMode, &c.Mode)
+
+	if v, ok := lookup(EnvVarLockoutMaxAttempts); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, EnvVarLockoutMaxAttempts, err)
+		}
+		c.Identity.LockoutMaxAttempts = n
+	}
+
+	if v, ok := lookup(EnvVarKeepSessionsOnPasswordChange); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, EnvVarKeepSessionsOnPasswordChange, err)
+		}
+		c.Identity.KeepSessionsOnPasswordChange = b
+	}
+
+	if v, ok := lookup(EnvVarFeatures); ok {
+		for pair := range strings.SplitSeq(v, ",") {
+			name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+			if name == "" {
+				continue
+			}
+			enabled := true
+			if found {
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return fmt.Errorf("%w: %s: %s: %v", ErrInvalidConfig, EnvVarFeatures, name, err)
+				}
+				enabled = b
+			}
+			if c.Features == nil {
+				c.Features = make(map[string]bool)
+			}
+			c.Features[name] = enabled
+		}
+	}
+
+	for name, dst := range map[string]*Duration{
+		EnvVarLockoutDuration:    &c.Identity.LockoutDuration,
+		EnvVarSessionLifetime:    &c.Session.Lifetime,
+		EnvVarSessionIdleTimeout: &c.Session.IdleTimeout,
+	} {
+		if err := setDuration(name, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
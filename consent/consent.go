@@ -0,0 +1,104 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package consent records which scopes a user has granted to a client,
+// decides whether an authorization request needs the consent screen, and keeps
+// a signed receipt of every consent act for compliance.
+package consent
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrGrantNotFound   = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "consent grant not found")
+	ErrReceiptNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "consent receipt not found")
+	ErrReceiptUnsigned = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "consent receipt is not signed")
+	ErrReceiptInvalid  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "consent receipt signature does not match its contents")
+	ErrNotPermitted    = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to export consent receipts")
+)
+
+// Decision reasons
+const (
+	// ReasonTrustedClient means the client is a trusted first-party application
+	ReasonTrustedClient = "trusted_client"
+	// ReasonPreviouslyGranted means the user already granted every requested scope
+	ReasonPreviouslyGranted = "previously_granted"
+	// ReasonRequired means at least one requested scope has not been granted
+	ReasonRequired = "consent_required"
+	// ReasonExpired means the user's earlier grant has expired and must be renewed
+	ReasonExpired = "consent_expired"
+)
+
+// Grant is the set of scopes a user has approved for one client.
+//
+// Purpose: Remembered consent so the user is not asked again for the same scopes.
+// Domain: OAuth2
+// Invariants: One grant per (tenant, user, client). Scopes only grow through Service.Grant
+// and are removed only by revoking the grant or letting it expire. A nil ExpiresAt never expires.
+type Grant struct {
+	TenantID  string     `json:"tenant_id"`
+	UserID    string     `json:"user_id"`
+	ClientID  string     `json:"client_id"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// IsExpired reports whether the grant has expired at now
+func (g *Grant) IsExpired(now time.Time) bool {
+	return g.ExpiresAt != nil && !now.Before(*g.ExpiresAt)
+}
+
+// Decision is the outcome of evaluating an authorization request.
+//
+// Purpose: Tells the transport whether to show the consent screen and for which scopes.
+// Domain: OAuth2
+// Invariants: Required is true exactly when Missing is not empty.
+type Decision struct {
+	Required bool
+	Reason   string
+	// Granted are the requested scopes that need no further approval.
+	Granted []string
+	// Missing are the requested scopes the user must approve.
+	Missing []string
+}
+
+// Repository defines persistence for consent grants.
+//
+// Purpose: Storage of remembered consent.
+// Domain: OAuth2
+type Repository interface {
+	// Get returns the grant of a user to a client
+	Get(ctx context.Context, tenantID, userID, clientID string) (*Grant, error)
+	// Save creates or replaces a grant
+	Save(ctx context.Context, g *Grant) error
+	// Delete removes a grant
+	Delete(ctx context.Context, tenantID, userID, clientID string) error
+	// ListByUser returns every grant of a user in a tenant
+	ListByUser(ctx context.Context, tenantID, userID string) ([]*Grant, error)
+
+	// CreateReceipt persists a consent receipt
+	CreateReceipt(ctx context.Context, r *Receipt) error
+	// GetReceipt returns a receipt of a tenant, or ErrReceiptNotFound
+	GetReceipt(ctx context.Context, tenantID, receiptID string) (*Receipt, error)
+	// ListReceipts returns the receipts of a tenant collected in [since, until), oldest first;
+	// a non-empty userID limits them to that user
+	ListReceipts(ctx context.Context, tenantID, userID string, since, until time.Time) ([]*Receipt, error)
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consent
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+// ReceiptVersion identifies the receipt format.
+const ReceiptVersion = "1"
+
+// receiptType is the JWS "typ" of a signed receipt.
+const receiptType = "consent-receipt+jwt"
+
+// Receipt is the record of one consent act, modelled on ISO/IEC 29184 consent receipts.
+//
+// Purpose: Evidence of what a user agreed to, when, and under which policy.
+// Domain: OAuth2
+// Invariants: Immutable once created. Scopes are those approved in this act, not the
+// cumulative grant. When a signer is configured JWS is a compact JWS over the receipt's
+// fields, and a receipt whose fields differ from the JWS payload is not authentic.
+type Receipt struct {
+	ID            string    `json:"receipt_id"`
+	Version       string    `json:"version"`
+	TenantID      string    `json:"tenant_id"`
+	UserID        string    `json:"sub"`
+	ClientID      string    `json:"client_id"`
+	ClientName    string    `json:"client_name"`
+	Scopes        []string  `json:"scopes"`
+	PolicyVersion string    `json:"policy_version"`
+	CollectedAt   time.Time `json:"collected_at"`
+	KeyID         string    `json:"-"`
+	JWS           string    `json:"-"`
+}
+
+// IsSigned reports whether the receipt carries a signature.
+func (r *Receipt) IsSigned() bool {
+	return r.JWS != ""
+}
+
+// sign sets r.JWS to a compact JWS over r's fields.
+func (r *Receipt) sign(key crypto.Signer, keyID string) error {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal consent receipt: %w", err)
+	}
+	token, err := jose.Sign(key, jose.Header{Kid: keyID, Typ: receiptType}, payload)
+	if err != nil {
+		return fmt.Errorf("failed to sign consent receipt: %w", err)
+	}
+	r.KeyID, r.JWS = keyID, token
+	return nil
+}
+
+// VerifyReceipt checks that r is signed by key and that its fields match the signed payload.
+//
+// Purpose: Lets a user, tenant, or auditor confirm a receipt has not been altered.
+// Domain: OAuth2
+// Audited: No
+// Errors: ErrReceiptUnsigned, ErrReceiptInvalid
+func VerifyReceipt(r *Receipt, key crypto.PublicKey) error {
+	if !r.IsSigned() {
+		return ErrReceiptUnsigned
+	}
+	jws, err := jose.Parse(r.JWS)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrReceiptInvalid, err)
+	}
+	if err := jws.Verify(key); err != nil {
+		return fmt.Errorf("%w: %v", ErrReceiptInvalid, err)
+	}
+
+	var signed Receipt
+	if err := json.Unmarshal(jws.Payload, &signed); err != nil {
+		return fmt.Errorf("%w: %v", ErrReceiptInvalid, err)
+	}
+	if signed.ID != r.ID || signed.Version != r.Version || signed.TenantID != r.TenantID ||
+		signed.UserID != r.UserID || signed.ClientID != r.ClientID || signed.ClientName != r.ClientName ||
+		!slices.Equal(signed.Scopes, r.Scopes) || signed.PolicyVersion != r.PolicyVersion ||
+		!signed.CollectedAt.Equal(r.CollectedAt) {
+		return ErrReceiptInvalid
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consent
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// Service evaluates and records user consent.
+//
+// Purpose: Consent policy, including the trusted first-party client exemption.
+// Domain: OAuth2
+// Invariants: Trusted clients never require consent. Trust applies only within the client's own tenant.
+// Every recorded consent act has a receipt. An expired grant counts as no grant.
+type Service struct {
+	repo        Repository
+	permissions PermissionChecker
+	auditLogger audit.Logger
+	signer      crypto.Signer
+	keyID       string
+	lifetime    time.Duration
+	clock       clock.Clock
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithReceiptSigner signs consent receipts with key, naming it keyID in the JWS header.
+// Without a signer receipts are stored unsigned.
+func WithReceiptSigner(key crypto.Signer, keyID string) Option {
+	return func(s *Service) { s.signer, s.keyID = key, keyID }
+}
+
+// WithGrantLifetime expires a grant d after the user last approved scopes for the client,
+// so the consent screen is shown again. Zero, the default, keeps grants until revoked.
+func WithGrantLifetime(d time.Duration) Option {
+	return func(s *Service) { s.lifetime = d }
+}
+
+// WithClock reads the current time from c for grant timestamps and expiry.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// NewService creates a new consent service.
+//
+// Purpose: Constructor for the consent service.
+// Domain: OAuth2
+// Audited: No
+// Errors: None
+func NewService(repo Repository, permissions PermissionChecker, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		permissions: permissions,
+		auditLogger: auditLogger,
+		clock:       clock.System(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Evaluate decides whether userID must be shown the consent screen for c and scopes.
+//
+// Purpose: Authorize-time consent check.
+// Domain: OAuth2
+// Security: Scopes outside the client's AllowedScopes are rejected before trust is considered,
+// so a trusted client cannot be auto-granted scopes it was never registered for.
+// An expired grant approves nothing; every requested scope is reported missing.
+// Audited: No
+// Errors: client.ErrClientNotFound, client.ErrDomainInvalidScope, System errors
+func (s *Service) Evaluate(ctx context.Context, tenantID, userID string, c *client.Client, scopes []string) (*Decision, error) {
+	if c.TenantID != tenantID {
+		return nil, client.ErrClientNotFound
+	}
+	if !c.ValidateScope(strings.Join(scopes, " ")) {
+		return nil, client.ErrDomainInvalidScope
+	}
+
+	if c.IsTrusted {
+		return &Decision{Reason: ReasonTrustedClient, Granted: scopes}, nil
+	}
+
+	var granted []string
+	expired := false
+	g, err := s.repo.Get(ctx, tenantID, userID, c.ClientID)
+	switch {
+	case err == nil && g.IsExpired(s.clock.Now()):
+		expired = true
+	case err == nil:
+		granted = g.Scopes
+	case !errors.Is(err, ErrGrantNotFound):
+		return nil, fmt.Errorf("failed to get consent grant: %w", err)
+	}
+
+	d := &Decision{Reason: ReasonPreviouslyGranted}
+	for _, scope := range scopes {
+		if slices.Contains(granted, scope) {
+			d.Granted = append(d.Granted, scope)
+		} else {
+			d.Missing = append(d.Missing, scope)
+		}
+	}
+	if len(d.Missing) > 0 {
+		d.Required, d.Reason = true, ReasonRequired
+		if expired {
+			d.Reason = ReasonExpired
+		}
+	}
+	return d, nil
+}
+
+// Grant records that userID approved scopes for c, adding them to any earlier unexpired grant,
+// and issues a receipt naming policyVersion, the version of the notice the user was shown.
+// With a grant lifetime configured, the grant's expiry restarts from now.
+//
+// Purpose: Persists the outcome of the consent screen.
+// Domain: OAuth2
+// Audited: Yes (ConsentGranted)
+// Errors: client.ErrClientNotFound, client.ErrDomainInvalidScope, System errors
+func (s *Service) Grant(ctx context.Context, tenantID, userID string, c *client.Client, scopes []string, policyVersion string) (*Grant, error) {
+	if c.TenantID != tenantID {
+		return nil, client.ErrClientNotFound
+	}
+	if !c.ValidateScope(strings.Join(scopes, " ")) {
+		return nil, client.ErrDomainInvalidScope
+	}
+
+	now := s.clock.Now()
+	g, err := s.repo.Get(ctx, tenantID, userID, c.ClientID)
+	switch {
+	case errors.Is(err, ErrGrantNotFound), err == nil && g.IsExpired(now):
+		g = &Grant{TenantID: tenantID, UserID: userID, ClientID: c.ClientID, CreatedAt: now}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get consent grant: %w", err)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(g.Scopes, scope) {
+			g.Scopes = append(g.Scopes, scope)
+		}
+	}
+	g.UpdatedAt = now
+	g.ExpiresAt = nil
+	if s.lifetime > 0 {
+		expiresAt := now.Add(s.lifetime)
+		g.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Save(ctx, g); err != nil {
+		return nil, fmt.Errorf("failed to save consent grant: %w", err)
+	}
+
+	r := &Receipt{
+		ID:            id.NewUUIDv7(),
+		Version:       ReceiptVersion,
+		TenantID:      tenantID,
+		UserID:        userID,
+		ClientID:      c.ClientID,
+		ClientName:    c.ClientName,
+		Scopes:        slices.Clone(scopes),
+		PolicyVersion: policyVersion,
+		CollectedAt:   now.UTC().Truncate(time.Second),
+	}
+	if s.signer != nil {
+		if err := r.sign(s.signer, s.keyID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.CreateReceipt(ctx, r); err != nil {
+		return nil, fmt.Errorf("failed to create consent receipt: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeConsentGranted,
+		TenantID:   tenantID,
+		ActorID:    userID,
+		Resource:   audit.ResourceConsent,
+		TargetName: c.ClientName,
+		TargetID:   c.ClientID,
+		Metadata:   map[string]any{"scopes": scopes, "receipt_id": r.ID, "policy_version": policyVersion},
+	})
+	return g, nil
+}
+
+// Revoke removes a user's grant to a client so the next request asks again.
+//
+// Purpose: Self-service or administrative withdrawal of consent.
+// Domain: OAuth2
+// Audited: Yes (ConsentRevoked)
+// Errors: ErrGrantNotFound, System errors
+func (s *Service) Revoke(ctx context.Context, tenantID, userID, clientID, actorID string) error {
+	if err := s.repo.Delete(ctx, tenantID, userID, clientID); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeConsentRevoked,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceConsent,
+		TargetID: clientID,
+		Metadata: map[string]any{"user_id": userID},
+	})
+	return nil
+}
+
+// ListGrants returns every client a user has an unexpired grant to
+func (s *Service) ListGrants(ctx context.Context, tenantID, userID string) ([]*Grant, error) {
+	grants, err := s.repo.ListByUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	return slices.DeleteFunc(grants, func(g *Grant) bool { return g.IsExpired(now) }), nil
+}
+
+// ListReceipts returns every consent receipt of a user, oldest first
+func (s *Service) ListReceipts(ctx context.Context, tenantID, userID string) ([]*Receipt, error) {
+	return s.repo.ListReceipts(ctx, tenantID, userID, time.Time{}, s.clock.Now())
+}
+
+// GetReceipt returns one of the user's own consent receipts.
+//
+// Purpose: Self-service retrieval of a receipt.
+// Domain: OAuth2
+// Security: A receipt of another user is reported as not found.
+// Audited: No
+// Errors: ErrReceiptNotFound, System errors
+func (s *Service) GetReceipt(ctx context.Context, tenantID, userID, receiptID string) (*Receipt, error) {
+	r, err := s.repo.GetReceipt(ctx, tenantID, receiptID)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID != userID {
+		return nil, ErrReceiptNotFound
+	}
+	return r, nil
+}
+
+// ExportReceipts returns every consent receipt of a tenant collected in [since, until).
+//
+// Purpose: Compliance export for tenant administrators.
+// Domain: OAuth2
+// Security: Requires PermTenantViewAudit in the tenant.
+// Audited: Yes (ConsentReceiptsExported)
+// Errors: ErrNotPermitted, System errors
+func (s *Service) ExportReceipts(ctx context.Context, tenantID, actorID string, since, until time.Time) ([]*Receipt, error) {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopeTenant, &tenantID, policy.PermTenantViewAudit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !ok {
+		return nil, ErrNotPermitted
+	}
+
+	receipts, err := s.repo.ListReceipts(ctx, tenantID, "", since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consent receipts: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeConsentReceiptsExported,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceConsent,
+		TargetID: tenantID,
+		Metadata: map[string]any{"since": since, "until": until, "count": len(receipts)},
+	})
+	return receipts, nil
+}
@@ -0,0 +1,324 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consent
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type mockRepo struct {
+	grants   map[string]*Grant
+	receipts []*Receipt
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{grants: make(map[string]*Grant)}
+}
+
+func grantKey(tenantID, userID, clientID string) string {
+	return tenantID + "/" + userID + "/" + clientID
+}
+
+func (m *mockRepo) Get(ctx context.Context, tenantID, userID, clientID string) (*Grant, error) {
+	g, ok := m.grants[grantKey(tenantID, userID, clientID)]
+	if !ok {
+		return nil, ErrGrantNotFound
+	}
+	cp := *g
+	cp.Scopes = slices.Clone(g.Scopes)
+	return &cp, nil
+}
+
+func (m *mockRepo) Save(ctx context.Context, g *Grant) error {
+	cp := *g
+	m.grants[grantKey(g.TenantID, g.UserID, g.ClientID)] = &cp
+	return nil
+}
+
+func (m *mockRepo) Delete(ctx context.Context, tenantID, userID, clientID string) error {
+	k := grantKey(tenantID, userID, clientID)
+	if _, ok := m.grants[k]; !ok {
+		return ErrGrantNotFound
+	}
+	delete(m.grants, k)
+	return nil
+}
+
+func (m *mockRepo) ListByUser(ctx context.Context, tenantID, userID string) ([]*Grant, error) {
+	var res []*Grant
+	for _, g := range m.grants {
+		if g.TenantID == tenantID && g.UserID == userID {
+			res = append(res, g)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) CreateReceipt(ctx context.Context, r *Receipt) error {
+	cp := *r
+	m.receipts = append(m.receipts, &cp)
+	return nil
+}
+
+func (m *mockRepo) GetReceipt(ctx context.Context, tenantID, receiptID string) (*Receipt, error) {
+	for _, r := range m.receipts {
+		if r.TenantID == tenantID && r.ID == receiptID {
+			cp := *r
+			return &cp, nil
+		}
+	}
+	return nil, ErrReceiptNotFound
+}
+
+func (m *mockRepo) ListReceipts(ctx context.Context, tenantID, userID string, since, until time.Time) ([]*Receipt, error) {
+	var res []*Receipt
+	for _, r := range m.receipts {
+		if r.TenantID == tenantID && (userID == "" || r.UserID == userID) &&
+			!r.CollectedAt.Before(since) && r.CollectedAt.Before(until) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+type mockPermissions struct {
+	allowed map[string]bool
+}
+
+func (m *mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return m.allowed[userID] && scope == role.ScopeTenant && *scopeContextID == "t1" && permission == policy.PermTenantViewAudit, nil
+}
+
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Log(_ context.Context, e audit.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestEvaluate(t *testing.T) {
+	ctx := context.Background()
+	thirdParty := &client.Client{TenantID: "t1", ClientID: "app", AllowedScopes: []string{"openid", "profile", "email"}}
+	firstParty := &client.Client{TenantID: "t1", ClientID: "portal", AllowedScopes: []string{"openid", "profile"}, IsTrusted: true}
+
+	svc := NewService(newMockRepo(), &mockPermissions{}, &recordingAuditLogger{})
+	if _, err := svc.Grant(ctx, "t1", "u1", thirdParty, []string{"openid", "profile"}, "v1"); err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		tenantID    string
+		userID      string
+		client      *client.Client
+		scopes      []string
+		wantErr     error
+		wantReq     bool
+		wantReason  string
+		wantMissing []string
+	}{
+		{"trusted client skips consent", "t1", "u2", firstParty, []string{"openid", "profile"}, nil, false, ReasonTrustedClient, nil},
+		{"trusted client outside its scopes", "t1", "u2", firstParty, []string{"openid", "email"}, client.ErrDomainInvalidScope, false, "", nil},
+		{"trusted client in another tenant", "t2", "u2", firstParty, []string{"openid"}, client.ErrClientNotFound, false, "", nil},
+		{"previously granted", "t1", "u1", thirdParty, []string{"openid"}, nil, false, ReasonPreviouslyGranted, nil},
+		{"new scope", "t1", "u1", thirdParty, []string{"openid", "email"}, nil, true, ReasonRequired, []string{"email"}},
+		{"first request", "t1", "u2", thirdParty, []string{"openid"}, nil, true, ReasonRequired, []string{"openid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := svc.Evaluate(ctx, tt.tenantID, tt.userID, tt.client, tt.scopes)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Evaluate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if d.Required != tt.wantReq || d.Reason != tt.wantReason || !slices.Equal(d.Missing, tt.wantMissing) {
+				t.Errorf("Evaluate() = %+v, want required=%v reason=%s missing=%v", d, tt.wantReq, tt.wantReason, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestGrantAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	c := &client.Client{TenantID: "t1", ClientID: "app", AllowedScopes: []string{"openid", "profile", "email"}}
+	logger := &recordingAuditLogger{}
+	svc := NewService(newMockRepo(), &mockPermissions{}, logger)
+
+	if _, err := svc.Grant(ctx, "t1", "u1", c, []string{"openid"}, "v1"); err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	g, err := svc.Grant(ctx, "t1", "u1", c, []string{"openid", "email"}, "v1")
+	if err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if !slices.Equal(g.Scopes, []string{"openid", "email"}) {
+		t.Errorf("scopes = %v, want merged [openid email]", g.Scopes)
+	}
+
+	if err := svc.Revoke(ctx, "t1", "u1", "app", "u1"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := svc.Revoke(ctx, "t1", "u1", "app", "u1"); !errors.Is(err, ErrGrantNotFound) {
+		t.Errorf("second Revoke() error = %v, want ErrGrantNotFound", err)
+	}
+
+	want := []string{audit.TypeConsentGranted, audit.TypeConsentGranted, audit.TypeConsentRevoked}
+	var got []string
+	for _, e := range logger.events {
+		got = append(got, e.Type)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("audit events = %v, want %v", got, want)
+	}
+}
+
+func TestGrantExpiry(t *testing.T) {
+	ctx := context.Background()
+	c := &client.Client{TenantID: "t1", ClientID: "app", AllowedScopes: []string{"openid", "profile", "email"}}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewService(newMockRepo(), &mockPermissions{}, &recordingAuditLogger{},
+		WithGrantLifetime(24*time.Hour), WithClock(clock.Func(func() time.Time { return now })))
+
+	g, err := svc.Grant(ctx, "t1", "u1", c, []string{"openid", "profile"}, "v1")
+	if err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if g.ExpiresAt == nil || !g.ExpiresAt.Equal(now.Add(24*time.Hour)) {
+		t.Fatalf("ExpiresAt = %v, want %v", g.ExpiresAt, now.Add(24*time.Hour))
+	}
+
+	now = now.Add(12 * time.Hour)
+	if d, _ := svc.Evaluate(ctx, "t1", "u1", c, []string{"openid"}); d.Required {
+		t.Errorf("Evaluate() before expiry = %+v, want no consent required", d)
+	}
+
+	now = now.Add(12 * time.Hour)
+	d, err := svc.Evaluate(ctx, "t1", "u1", c, []string{"openid"})
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if !d.Required || d.Reason != ReasonExpired || !slices.Equal(d.Missing, []string{"openid"}) {
+		t.Errorf("Evaluate() after expiry = %+v, want required with reason %s", d, ReasonExpired)
+	}
+	if grants, _ := svc.ListGrants(ctx, "t1", "u1"); len(grants) != 0 {
+		t.Errorf("ListGrants() = %d grants, want expired grant hidden", len(grants))
+	}
+
+	g, err = svc.Grant(ctx, "t1", "u1", c, []string{"email"}, "v1")
+	if err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if !slices.Equal(g.Scopes, []string{"email"}) || !g.CreatedAt.Equal(now) || !g.ExpiresAt.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("renewed grant = %+v, want fresh grant of [email] expiring in 24h", g)
+	}
+}
+
+func TestConsentReceipts(t *testing.T) {
+	ctx := context.Background()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &client.Client{TenantID: "t1", ClientID: "app", ClientName: "App", AllowedScopes: []string{"openid", "email"}}
+	logger := &recordingAuditLogger{}
+	svc := NewService(newMockRepo(), &mockPermissions{allowed: map[string]bool{"admin": true}}, logger,
+		WithReceiptSigner(key, "consent-1"))
+
+	for _, userID := range []string{"u1", "u2"} {
+		if _, err := svc.Grant(ctx, "t1", userID, c, []string{"openid", "email"}, "privacy-2026-01"); err != nil {
+			t.Fatalf("Grant() error = %v", err)
+		}
+	}
+
+	receipts, err := svc.ListReceipts(ctx, "t1", "u1")
+	if err != nil || len(receipts) != 1 {
+		t.Fatalf("ListReceipts() = %d, %v; want 1 receipt", len(receipts), err)
+	}
+	r := receipts[0]
+	if r.UserID != "u1" || r.ClientName != "App" || r.PolicyVersion != "privacy-2026-01" ||
+		!slices.Equal(r.Scopes, []string{"openid", "email"}) || r.KeyID != "consent-1" {
+		t.Errorf("receipt = %+v", r)
+	}
+	if err := VerifyReceipt(r, &key.PublicKey); err != nil {
+		t.Errorf("VerifyReceipt() error = %v", err)
+	}
+
+	tampered := *r
+	tampered.Scopes = []string{"openid"}
+	if err := VerifyReceipt(&tampered, &key.PublicKey); !errors.Is(err, ErrReceiptInvalid) {
+		t.Errorf("VerifyReceipt() of tampered receipt error = %v, want ErrReceiptInvalid", err)
+	}
+
+	if _, err := svc.GetReceipt(ctx, "t1", "u1", r.ID); err != nil {
+		t.Errorf("GetReceipt() by owner error = %v", err)
+	}
+	if _, err := svc.GetReceipt(ctx, "t1", "u2", r.ID); !errors.Is(err, ErrReceiptNotFound) {
+		t.Errorf("GetReceipt() by another user error = %v, want ErrReceiptNotFound", err)
+	}
+
+	tests := []struct {
+		name    string
+		actorID string
+		want    int
+		wantErr error
+	}{
+		{"tenant admin", "admin", 2, nil},
+		{"member", "u1", 0, ErrNotPermitted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.ExportReceipts(ctx, "t1", tt.actorID, time.Time{}, time.Now().Add(time.Hour))
+			if !errors.Is(err, tt.wantErr) || len(got) != tt.want {
+				t.Errorf("ExportReceipts() = %d, %v; want %d, %v", len(got), err, tt.want, tt.wantErr)
+			}
+		})
+	}
+	if last := logger.events[len(logger.events)-1]; last.Type != audit.TypeConsentReceiptsExported {
+		t.Errorf("last audit event = %q, want export", last.Type)
+	}
+}
+
+func TestUnsignedReceipt(t *testing.T) {
+	ctx := context.Background()
+	c := &client.Client{TenantID: "t1", ClientID: "app", AllowedScopes: []string{"openid"}}
+	repo := newMockRepo()
+	svc := NewService(repo, &mockPermissions{}, &recordingAuditLogger{})
+
+	if _, err := svc.Grant(ctx, "t1", "u1", c, []string{"openid"}, "v1"); err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if len(repo.receipts) != 1 || repo.receipts[0].IsSigned() {
+		t.Fatalf("want one unsigned receipt, got %+v", repo.receipts)
+	}
+	if err := VerifyReceipt(repo.receipts[0], nil); !errors.Is(err, ErrReceiptUnsigned) {
+		t.Errorf("VerifyReceipt() error = %v, want ErrReceiptUnsigned", err)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import "crypto/subtle"
+
+// ConstantTimeEqual reports whether a and b are equal without leaking timing information.
+//
+// Purpose: Single comparison primitive for secrets, derived keys, and stored hashes.
+// Domain: Platform
+// Security: Runs in time dependent only on the lengths of the inputs, never their contents.
+// Audited: No
+// Errors: None
+func ConstantTimeEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// ConstantTimeEqualString is the string form of ConstantTimeEqual.
+func ConstantTimeEqualString(a, b string) bool {
+	return ConstantTimeEqual([]byte(a), []byte(b))
+}
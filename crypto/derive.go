@@ -0,0 +1,132 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Key derivation purposes. Each purpose yields an independent key so that the
+// compromise of one derived key does not allow correlation across purposes.
+const (
+	PurposeEmailHash = "email-hash"
+	PurposeLookup    = "lookup"
+	// PurposeAuthorizationCode keys the encryption of stateless authorization codes
+	PurposeAuthorizationCode = "authorization-code"
+	// PurposeBackup keys the encryption of backup archives
+	PurposeBackup = "backup"
+	// PurposeSigningKey keys the encryption of stored token signing keys
+	PurposeSigningKey = "signing-key"
+	// PurposePairwiseSubject keys the derivation of pairwise subject identifiers
+	PurposePairwiseSubject = "pairwise-subject"
+)
+
+// LegacyKeyID identifies hashes computed directly with the master key,
+// before purpose-specific derivation was introduced.
+const LegacyKeyID = "legacy"
+
+// derivedKeyLength is the size in bytes of every derived HMAC key.
+const derivedKeyLength = 32
+
+// ErrEmptyMasterKey is returned when key derivation is attempted without a master key.
+var ErrEmptyMasterKey = errors.New("master key is empty")
+
+// HMACKey is a keyed-hash secret together with its non-secret identifier.
+//
+// Purpose: Carries the key ID that must be persisted alongside every hash so lookups and re-keying know which key produced it.
+// Domain: Platform
+// Invariants: ID never reveals key material. Secret is never logged or serialized.
+type HMACKey struct {
+	ID     string
+	Secret []byte
+}
+
+// DeriveHMACKey derives a purpose- and optionally tenant-specific HMAC key from a master key.
+//
+// Purpose: Domain separation for keyed hashes (HKDF-SHA256, RFC 5869).
+// Domain: Platform
+// Security: The info string binds purpose and tenant; an empty tenantID yields a platform-wide key for that purpose.
+// Audited: No
+// Errors: ErrEmptyMasterKey, derivation errors
+func DeriveHMACKey(masterKey []byte, purpose, tenantID string) (HMACKey, error) {
+	if len(masterKey) == 0 {
+		return HMACKey{}, ErrEmptyMasterKey
+	}
+
+	info := "opentrusty/" + purpose
+	if tenantID != "" {
+		info += "/tenant/" + tenantID
+	}
+
+	secret, err := hkdf.Key(sha256.New, masterKey, nil, info, derivedKeyLength)
+	if err != nil {
+		return HMACKey{}, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	return HMACKey{ID: keyFingerprint(purpose, secret), Secret: secret}, nil
+}
+
+// LegacyHMACKey wraps a master key used directly, without derivation.
+func LegacyHMACKey(masterKey []byte) HMACKey {
+	return HMACKey{ID: LegacyKeyID, Secret: masterKey}
+}
+
+// Sum computes the hex-encoded HMAC-SHA256 of data under the key.
+func (k HMACKey) Sum(data string) string {
+	h := hmac.New(sha256.New, k.Secret)
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PairwiseSubject derives the pairwise subject identifier of localSub for sector.
+//
+// Purpose: Per-sector "sub" values (OpenID Connect Core 1.0 Section 8.1), so clients of
+// different sectors cannot correlate a user by subject.
+// Domain: Identity
+// Security: Deterministic under key, so the same user always gets the same identifier in
+// a sector; without key the identifier reveals neither localSub nor its value in another
+// sector. A NUL separator, which no host name contains, keeps sector and localSub apart.
+// Audited: No
+// Errors: None
+func PairwiseSubject(key HMACKey, sector, localSub string) string {
+	h := hmac.New(sha256.New, key.Secret)
+	h.Write([]byte(sector))
+	h.Write([]byte{0})
+	h.Write([]byte(localSub))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// ComputeEmailHashWithKey computes the email identity hash under a specific key.
+//
+// Purpose: Key-aware variant of ComputeEmailHash used with derived keys.
+// Domain: Identity
+// Invariants: Applies the same normalization as ComputeEmailHash.
+// Audited: No
+// Errors: None
+func ComputeEmailHashWithKey(key HMACKey, emailPlain string) string {
+	return key.Sum(normalizeEmail(emailPlain))
+}
+
+// keyFingerprint returns a stable, non-reversible identifier for a derived key.
+func keyFingerprint(purpose string, secret []byte) string {
+	sum := sha256.Sum256(secret)
+	return purpose + ":" + hex.EncodeToString(sum[:6])
+}
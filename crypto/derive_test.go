@@ -0,0 +1,92 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDeriveHMACKey(t *testing.T) {
+	master := []byte("0123456789abcdef0123456789abcdef")
+
+	email, err := DeriveHMACKey(master, PurposeEmailHash, "")
+	if err != nil {
+		t.Fatalf("DeriveHMACKey() error = %v", err)
+	}
+	again, _ := DeriveHMACKey(master, PurposeEmailHash, "")
+	lookup, _ := DeriveHMACKey(master, PurposeLookup, "")
+	tenant, _ := DeriveHMACKey(master, PurposeEmailHash, "tenant-a")
+
+	if !bytes.Equal(email.Secret, again.Secret) || email.ID != again.ID {
+		t.Errorf("derivation is not deterministic")
+	}
+	if bytes.Equal(email.Secret, lookup.Secret) || email.ID == lookup.ID {
+		t.Errorf("different purposes produced the same key")
+	}
+	if bytes.Equal(email.Secret, tenant.Secret) {
+		t.Errorf("tenant-scoped key equals platform key")
+	}
+	if bytes.Equal(email.Secret, master) {
+		t.Errorf("derived key equals master key")
+	}
+	if !strings.HasPrefix(email.ID, PurposeEmailHash+":") {
+		t.Errorf("ID = %q, want purpose prefix", email.ID)
+	}
+
+	if _, err := DeriveHMACKey(nil, PurposeEmailHash, ""); !errors.Is(err, ErrEmptyMasterKey) {
+		t.Errorf("DeriveHMACKey(nil) error = %v, want ErrEmptyMasterKey", err)
+	}
+}
+
+func TestComputeEmailHashWithKey(t *testing.T) {
+	master := []byte("0123456789abcdef0123456789abcdef")
+
+	legacy := LegacyHMACKey(master)
+	if got, want := ComputeEmailHashWithKey(legacy, " User@Example.COM "), ComputeEmailHash(string(master), "user@example.com"); got != want {
+		t.Errorf("legacy hash = %s, want %s", got, want)
+	}
+
+	derived, _ := DeriveHMACKey(master, PurposeEmailHash, "")
+	if ComputeEmailHashWithKey(derived, "user@example.com") == ComputeEmailHashWithKey(legacy, "user@example.com") {
+		t.Errorf("derived and legacy hashes must differ")
+	}
+}
+
+func TestPairwiseSubject(t *testing.T) {
+	master := []byte("0123456789abcdef0123456789abcdef")
+	key, _ := DeriveHMACKey(master, PurposePairwiseSubject, "tenant-a")
+	other, _ := DeriveHMACKey(master, PurposePairwiseSubject, "tenant-b")
+
+	sub := PairwiseSubject(key, "app.example.com", "user-1")
+	if sub != PairwiseSubject(key, "app.example.com", "user-1") {
+		t.Errorf("derivation is not deterministic")
+	}
+	if strings.Contains(sub, "user-1") || len(sub) != 43 {
+		t.Errorf("PairwiseSubject() = %q, want an opaque 256-bit identifier", sub)
+	}
+	for name, got := range map[string]string{
+		"other sector": PairwiseSubject(key, "crm.example.com", "user-1"),
+		"other user":   PairwiseSubject(key, "app.example.com", "user-2"),
+		"other key":    PairwiseSubject(other, "app.example.com", "user-1"),
+		"shifted":      PairwiseSubject(key, "app.example.comu", "ser-1"),
+	} {
+		if got == sub {
+			t.Errorf("%s produced the same subject", name)
+		}
+	}
+}
@@ -29,10 +29,15 @@ import (
 // Audited: No
 // Errors: None
 func ComputeEmailHash(key string, emailPlain string) string {
-	normalized := strings.TrimSpace(strings.ToLower(emailPlain))
+	normalized := normalizeEmail(emailPlain)
 
 	h := hmac.New(sha256.New, []byte(key))
 	h.Write([]byte(normalized))
 
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+// normalizeEmail lowercases and trims an email before hashing.
+func normalizeEmail(emailPlain string) string {
+	return strings.TrimSpace(strings.ToLower(emailPlain))
+}
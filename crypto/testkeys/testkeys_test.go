@@ -0,0 +1,102 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testkeys
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestGoldenJWSVerify(t *testing.T) {
+	for _, v := range GoldenJWS() {
+		t.Run(v.Name, func(t *testing.T) {
+			parts := strings.Split(v.Compact, ".")
+			if len(parts) != 3 {
+				t.Fatalf("expected 3 segments, got %d", len(parts))
+			}
+
+			payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+			if err != nil {
+				t.Fatalf("failed to decode payload: %v", err)
+			}
+			if string(payload) != GoldenClaims {
+				t.Errorf("payload mismatch: %s", payload)
+			}
+
+			sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+			if err != nil {
+				t.Fatalf("failed to decode signature: %v", err)
+			}
+
+			signingInput := []byte(parts[0] + "." + parts[1])
+			digest := sha256.Sum256(signingInput)
+
+			var ok bool
+			switch v.Alg {
+			case "RS256":
+				ok = rsa.VerifyPKCS1v15(&RSA().PublicKey, crypto.SHA256, digest[:], sig) == nil
+			case "ES256":
+				r := new(big.Int).SetBytes(sig[:32])
+				s := new(big.Int).SetBytes(sig[32:])
+				ok = ecdsa.Verify(&ECDSA().PublicKey, digest[:], r, s)
+			case "EdDSA":
+				ok = ed25519.Verify(Ed25519().Public().(ed25519.PublicKey), signingInput, sig)
+			}
+			if !ok {
+				t.Errorf("signature did not verify with fixture key")
+			}
+		})
+	}
+}
+
+func TestGoldenJWEDecrypt(t *testing.T) {
+	parts := strings.Split(GoldenJWEDirA256GCM.Compact, ".")
+	if len(parts) != 5 {
+		t.Fatalf("expected 5 segments, got %d", len(parts))
+	}
+	if parts[1] != "" {
+		t.Fatalf("dir JWE must have an empty encrypted key segment")
+	}
+
+	iv, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	ciphertext, _ := base64.RawURLEncoding.DecodeString(parts[3])
+	tag, _ := base64.RawURLEncoding.DecodeString(parts[4])
+
+	block, err := aes.NewCipher(DirectKey())
+	if err != nil {
+		t.Fatalf("failed to create cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("failed to create GCM: %v", err)
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		t.Fatalf("failed to open JWE: %v", err)
+	}
+	if string(plaintext) != GoldenJWEPlaintext {
+		t.Errorf("plaintext mismatch: %s", plaintext)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dashboard is the read model behind the tenant admin dashboard. It
+// gathers the tenant's headline numbers and recent security activity with a
+// few aggregate queries instead of one list call per widget.
+package dashboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+// Domain errors
+var (
+	ErrNotPermitted = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to view this tenant's dashboard")
+)
+
+// DefaultEventLimit is the number of recent security events returned
+const DefaultEventLimit = 20
+
+// SecurityEventTypes are the audit types shown as recent security activity.
+var SecurityEventTypes = []string{
+	audit.TypeLoginFailed,
+	audit.TypeUserLocked,
+	audit.TypeUserUnlocked,
+	audit.TypeSuspiciousLogin,
+	audit.TypeCredentialResetRequired,
+	audit.TypePasswordChanged,
+	audit.TypeRoleAssigned,
+	audit.TypeRoleRevoked,
+	audit.TypeSecretRotated,
+	audit.TypeClientTrustChanged,
+	audit.TypeIdentityLinked,
+	audit.TypeIdentityUnlinked,
+	audit.TypeRecoveryRequested,
+	audit.TypeRecoveryAttested,
+	audit.TypeRecoveryCompleted,
+}
+
+// Counts are the tenant's headline numbers.
+//
+// Purpose: Aggregates computed by the store in a single query.
+// Domain: Tenant
+// Invariants: Sessions and locks are counted as of the time passed to the repository.
+type Counts struct {
+	Members           int `json:"members"`
+	Clients           int `json:"clients"`
+	ActiveSessions    int `json:"active_sessions"`
+	LockedMembers     int `json:"locked_members"`
+	PendingRecoveries int `json:"pending_recoveries"`
+}
+
+// Summary is everything the tenant dashboard shows.
+//
+// Purpose: One-call response for the tenant admin dashboard.
+// Domain: Tenant
+// Invariants: RecentSecurityEvents is nil unless the viewer may read the tenant's audit log.
+type Summary struct {
+	TenantID             string        `json:"tenant_id"`
+	Counts               Counts        `json:"counts"`
+	RecentSecurityEvents []audit.Event `json:"recent_security_events,omitempty"`
+	GeneratedAt          time.Time     `json:"generated_at"`
+}
+
+// Repository defines the dashboard aggregate queries.
+//
+// Purpose: Read-only aggregate access to tenant data.
+// Domain: Tenant
+type Repository interface {
+	// Counts returns the tenant's headline numbers as of now
+	Counts(ctx context.Context, tenantID string, now time.Time) (*Counts, error)
+	// RecentEvents returns the newest events of the given types in the tenant, including
+	// untenanted events whose actor is a member of the tenant
+	RecentEvents(ctx context.Context, tenantID string, types []string, limit int) ([]audit.Event, error)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// Service builds tenant dashboard summaries.
+//
+// Purpose: Read model for the tenant admin dashboard.
+// Domain: Tenant
+type Service struct {
+	repo        Repository
+	permissions PermissionChecker
+	eventLimit  int
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithEventLimit returns up to n recent security events instead of DefaultEventLimit.
+func WithEventLimit(n int) Option {
+	return func(s *Service) { s.eventLimit = n }
+}
+
+// WithTracer emits spans for summary queries on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new dashboard service.
+//
+// Purpose: Constructor for the tenant dashboard read model.
+// Domain: Tenant
+// Audited: No
+// Errors: None
+func NewService(repo Repository, permissions PermissionChecker, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		permissions: permissions,
+		eventLimit:  DefaultEventLimit,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetSummary returns the dashboard summary of tenantID as seen by actorID.
+//
+// Purpose: Single call behind the tenant admin dashboard.
+// Domain: Tenant
+// Security: Requires policy.PermTenantView. Recent security events are only
+// included when the actor also holds policy.PermTenantViewAudit.
+// Audited: No
+// Errors: ErrNotPermitted, System errors
+func (s *Service) GetSummary(ctx context.Context, tenantID, actorID string) (*Summary, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "dashboard.GetSummary", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	ok, err := s.can(ctx, actorID, tenantID, policy.PermTenantView)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotPermitted
+	}
+
+	now := time.Now()
+	counts, err := s.repo.Counts(ctx, tenantID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant resources: %w", err)
+	}
+	summary := &Summary{TenantID: tenantID, Counts: *counts, GeneratedAt: now}
+
+	canAudit, err := s.can(ctx, actorID, tenantID, policy.PermTenantViewAudit)
+	if err != nil {
+		return nil, err
+	}
+	if canAudit && s.eventLimit > 0 {
+		summary.RecentSecurityEvents, err = s.repo.RecentEvents(ctx, tenantID, SecurityEventTypes, s.eventLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list security events: %w", err)
+		}
+	}
+	return summary, nil
+}
+
+func (s *Service) can(ctx context.Context, actorID, tenantID, permission string) (bool, error) {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	if actorID == "" || s.permissions == nil {
+		return false, nil
+	}
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopeTenant, &tenantID, permission)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type mockRepo struct {
+	counts      Counts
+	events      []audit.Event
+	eventsCalls int
+}
+
+func (m *mockRepo) Counts(ctx context.Context, tenantID string, now time.Time) (*Counts, error) {
+	c := m.counts
+	return &c, nil
+}
+
+func (m *mockRepo) RecentEvents(ctx context.Context, tenantID string, types []string, limit int) ([]audit.Event, error) {
+	m.eventsCalls++
+	if len(m.events) > limit {
+		return m.events[:limit], nil
+	}
+	return m.events, nil
+}
+
+type mockPermissions map[string][]string
+
+func (m mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	for _, p := range m[userID] {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestGetSummary(t *testing.T) {
+	perms := mockPermissions{
+		"auditor": {policy.PermTenantView, policy.PermTenantViewAudit},
+		"viewer":  {policy.PermTenantView},
+	}
+	events := []audit.Event{{Type: audit.TypeUserLocked}, {Type: audit.TypeLoginFailed}, {Type: audit.TypeRoleAssigned}}
+
+	tests := []struct {
+		name       string
+		actor      string
+		wantErr    error
+		wantEvents int
+	}{
+		{"auditor sees events", "auditor", nil, 2},
+		{"viewer sees counts only", "viewer", nil, 0},
+		{"outsider", "stranger", ErrNotPermitted, 0},
+		{"anonymous", "", ErrNotPermitted, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{counts: Counts{Members: 5, Clients: 2, ActiveSessions: 3}, events: events}
+			svc := NewService(repo, perms, WithEventLimit(2))
+
+			summary, err := svc.GetSummary(context.Background(), "t1", tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetSummary() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if summary.Counts.Members != 5 || summary.Counts.Clients != 2 || summary.Counts.ActiveSessions != 3 {
+				t.Errorf("counts = %+v", summary.Counts)
+			}
+			if len(summary.RecentSecurityEvents) != tt.wantEvents {
+				t.Errorf("events = %d, want %d", len(summary.RecentSecurityEvents), tt.wantEvents)
+			}
+			if tt.wantEvents == 0 && repo.eventsCalls != 0 {
+				t.Error("audit events must not be queried without PermTenantViewAudit")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package delegation records the agreements under which a service account may
+// mint user-scoped tokens for approved background jobs, such as a nightly sync,
+// without the user being present. An agreement is a persistent grant: approved
+// once by an administrator, bounded in scope and lifetime, and revocable
+// together with every token issued under it.
+package delegation
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrNotPermitted       = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to manage delegation agreements")
+	ErrAgreementNotFound  = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "delegation agreement not found")
+	ErrAgreementInactive  = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "delegation agreement is not active")
+	ErrInvalidAgreement   = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "invalid delegation agreement")
+	ErrClientNotDelegated = apperror.New(apperror.CodeInvalidClient, apperror.StatusBadRequest, apperror.OAuth2UnauthorizedClient, "client is not registered for delegated grants")
+)
+
+const (
+	// DefaultLifetime is how long an agreement lasts when the approval names no lifetime
+	DefaultLifetime = 90 * 24 * time.Hour
+	// DefaultMaxLifetime is the longest lifetime an agreement may be approved for
+	DefaultMaxLifetime = 365 * 24 * time.Hour
+	// MaxPurposeLength bounds the recorded purpose of an agreement
+	MaxPurposeLength = 255
+)
+
+// Agreement is a recorded delegation from a user to a service account.
+//
+// Purpose: The persistent grant a service account's delegated token requests are
+// checked against.
+// Domain: OAuth2
+// Invariants: ClientID is a confidential client of TenantID registered for
+// client.GrantTypeDelegated. Scopes are a subset of the client's allowed scopes and
+// never include offline_access. ExpiresAt is always set. A revoked agreement is never
+// reactivated. Tokens issued under it carry ID as their grant ID.
+type Agreement struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	ClientID   string     `json:"client_id"`
+	UserID     string     `json:"user_id"`
+	Scopes     []string   `json:"scopes"`
+	Purpose    string     `json:"purpose"`
+	ApprovedBy string     `json:"approved_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy  string     `json:"revoked_by,omitempty"`
+}
+
+// IsActive reports whether tokens may be issued under the agreement at now
+func (a *Agreement) IsActive(now time.Time) bool {
+	return a.RevokedAt == nil && now.Before(a.ExpiresAt)
+}
+
+// Covers reports whether every scope in scopes was delegated
+func (a *Agreement) Covers(scopes []string) bool {
+	for _, sc := range scopes {
+		if !slices.Contains(a.Scopes, sc) {
+			return false
+		}
+	}
+	return true
+}
+
+// Approval is a request to record a delegation agreement.
+//
+// Purpose: What an administrator approves: which service account may act for which
+// user, with which scopes, why, and for how long.
+// Domain: OAuth2
+// Invariants: Purpose names the background job; Lifetime zero means DefaultLifetime.
+type Approval struct {
+	ClientID string
+	UserID   string
+	Scope    string
+	Purpose  string
+	Lifetime time.Duration
+}
+
+// Repository persists delegation agreements.
+type Repository interface {
+	// Create stores a new agreement
+	Create(ctx context.Context, a *Agreement) error
+	// Get returns an agreement of a tenant, or ErrAgreementNotFound
+	Get(ctx context.Context, tenantID, id string) (*Agreement, error)
+	// ListByUser returns the agreements of a user in a tenant, newest first
+	ListByUser(ctx context.Context, tenantID, userID string) ([]*Agreement, error)
+	// Revoke marks an unrevoked agreement revoked, or returns ErrAgreementNotFound
+	Revoke(ctx context.Context, tenantID, id, revokedBy string, at time.Time) error
+	// MarkUsed records when tokens were last issued under an agreement
+	MarkUsed(ctx context.Context, tenantID, id string, at time.Time) error
+}
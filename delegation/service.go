@@ -0,0 +1,363 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package delegation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Audit metadata keys
+const (
+	attrClientID  = "client_id"
+	attrUserID    = "user_id"
+	attrScope     = "scope"
+	attrPurpose   = "purpose"
+	attrExpiresAt = "expires_at"
+	attrRevoked   = "revoked_tokens"
+)
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// ClientSource looks up the service account an agreement names; client.Service implements it.
+type ClientSource interface {
+	GetClientByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error)
+}
+
+// TokenRevoker revokes the tokens of one grant; grant.Repository implements it.
+type TokenRevoker interface {
+	RevokeGrant(ctx context.Context, tenantID, userID, grantID string) (int, error)
+}
+
+// Service approves, revokes, and checks delegation agreements.
+//
+// Purpose: The persistent grants behind delegated token issuance.
+// Domain: OAuth2
+// Invariants: Approving requires policy.PermTenantApproveDelegations in the tenant.
+// Users may list and revoke their own agreements. Approvals and revocations are audited.
+type Service struct {
+	repo        Repository
+	permissions PermissionChecker
+	clients     ClientSource
+	tokens      TokenRevoker
+	auditLogger audit.Logger
+	reasons     audit.ReasonPolicy
+	maxLifetime time.Duration
+	clock       clock.Clock
+	ids         id.Generator
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithMaxLifetime caps the lifetime an agreement may be approved for. Defaults to
+// DefaultMaxLifetime.
+func WithMaxLifetime(d time.Duration) Option {
+	return func(s *Service) { s.maxLifetime = d }
+}
+
+// WithReasonPolicy requires a justification (audit.WithReason) for approvals in
+// tenants whose policy asks for one for audit.OpDelegation.
+func WithReasonPolicy(p audit.ReasonPolicy) Option {
+	return func(s *Service) { s.reasons = p }
+}
+
+// WithClock reads the current time from c for approval, expiry, and use.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// WithIDGenerator mints agreement IDs with g. Defaults to id.UUIDv7.
+func WithIDGenerator(g id.Generator) Option {
+	return func(s *Service) { s.ids = g }
+}
+
+// WithTracer emits spans for delegation operations on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new delegation service.
+//
+// Purpose: Constructor for the delegation agreement service.
+// Domain: OAuth2
+// Audited: No
+// Errors: None
+func NewService(repo Repository, permissions PermissionChecker, clients ClientSource, tokens TokenRevoker, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		permissions: permissions,
+		clients:     clients,
+		tokens:      tokens,
+		auditLogger: auditLogger,
+		maxLifetime: DefaultMaxLifetime,
+		clock:       clock.System(),
+		ids:         id.UUIDv7(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Approve records an agreement allowing req.ClientID to mint tokens for req.UserID.
+//
+// Purpose: Administrative approval of a background job acting on a user's behalf.
+// Domain: OAuth2
+// Security: Requires policy.PermTenantApproveDelegations in the tenant, and a reason in
+// ctx when the tenant requires one for audit.OpDelegation. The client must be an
+// active confidential client of the tenant registered for client.GrantTypeDelegated.
+// Scopes must be allowed for the client and may not include offline_access; delegated
+// tokens are short-lived access tokens and the agreement itself is the persistent part.
+// Every agreement expires, at most WithMaxLifetime after approval.
+// Audited: Yes (DelegationApproved)
+// Errors: ErrNotPermitted, ErrInvalidAgreement, ErrClientNotDelegated,
+// client.ErrClientNotFound, client.ErrDomainInvalidScope, audit.ErrReasonRequired,
+// System errors
+func (s *Service) Approve(ctx context.Context, tenantID, actorID string, req Approval) (*Agreement, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "delegation.Approve", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	ok, err := s.can(ctx, actorID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotPermitted
+	}
+	reason, err := audit.RequireReason(ctx, s.reasons, tenantID, audit.OpDelegation)
+	if err != nil {
+		return nil, err
+	}
+
+	purpose := strings.TrimSpace(req.Purpose)
+	switch {
+	case req.UserID == "":
+		return nil, fmt.Errorf("%w: user is required", ErrInvalidAgreement)
+	case purpose == "":
+		return nil, fmt.Errorf("%w: purpose is required", ErrInvalidAgreement)
+	case len(purpose) > MaxPurposeLength:
+		return nil, fmt.Errorf("%w: purpose is too long", ErrInvalidAgreement)
+	}
+	lifetime := req.Lifetime
+	if lifetime == 0 {
+		lifetime = min(DefaultLifetime, s.maxLifetime)
+	}
+	if lifetime < 0 || lifetime > s.maxLifetime {
+		return nil, fmt.Errorf("%w: lifetime must be positive and at most %s", ErrInvalidAgreement, s.maxLifetime)
+	}
+
+	c, err := s.clients.GetClientByClientID(ctx, tenantID, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.IsActive || c.IsPublic() || !slices.Contains(c.GrantTypes, client.GrantTypeDelegated) {
+		return nil, ErrClientNotDelegated
+	}
+	scopes := strings.Fields(req.Scope)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+	switch {
+	case len(scopes) == 0:
+		return nil, fmt.Errorf("%w: scope is required", ErrInvalidAgreement)
+	case slices.Contains(scopes, client.ScopeOfflineAccess):
+		return nil, fmt.Errorf("%w: offline_access cannot be delegated", ErrInvalidAgreement)
+	case !c.ValidateScope(req.Scope):
+		return nil, client.ErrDomainInvalidScope
+	}
+
+	now := s.clock.Now()
+	a := &Agreement{
+		ID:         s.ids.NewID(),
+		TenantID:   tenantID,
+		ClientID:   c.ClientID,
+		UserID:     req.UserID,
+		Scopes:     scopes,
+		Purpose:    purpose,
+		ApprovedBy: actorID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(lifetime),
+	}
+	if err := s.repo.Create(ctx, a); err != nil {
+		return nil, fmt.Errorf("failed to create delegation agreement: %w", err)
+	}
+
+	metadata := map[string]any{
+		attrClientID:  a.ClientID,
+		attrUserID:    a.UserID,
+		attrScope:     strings.Join(a.Scopes, " "),
+		attrPurpose:   a.Purpose,
+		attrExpiresAt: a.ExpiresAt,
+	}
+	if reason != "" {
+		metadata[audit.AttrReason] = reason
+	}
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeDelegationApproved,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceDelegation,
+		TargetID:   a.ID,
+		TargetName: a.Purpose,
+		Metadata:   metadata,
+	})
+	return a, nil
+}
+
+// List returns the agreements of userID in tenantID, newest first.
+//
+// Purpose: Lets users and administrators see which jobs act on a user's behalf.
+// Domain: OAuth2
+// Security: Self-service or policy.PermTenantApproveDelegations.
+// Audited: No
+// Errors: ErrNotPermitted, System errors
+func (s *Service) List(ctx context.Context, tenantID, actorID, userID string) ([]*Agreement, error) {
+	if err := s.authorize(ctx, actorID, tenantID, userID); err != nil {
+		return nil, err
+	}
+	agreements, err := s.repo.ListByUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegation agreements: %w", err)
+	}
+	return agreements, nil
+}
+
+// Revoke ends an agreement and revokes every token issued under it.
+//
+// Purpose: Withdraw a service account's right to act for a user.
+// Domain: OAuth2
+// Security: Self-service or policy.PermTenantApproveDelegations. The agreement is
+// revoked before its tokens, so no token can be issued in between; revoking an
+// already revoked agreement revokes any remaining tokens again.
+// Audited: Yes (DelegationRevoked)
+// Errors: ErrAgreementNotFound, ErrNotPermitted, System errors
+func (s *Service) Revoke(ctx context.Context, tenantID, actorID, agreementID string) error {
+	ctx, span := tracing.Start(ctx, s.tracer, "delegation.Revoke", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	a, err := s.repo.Get(ctx, tenantID, agreementID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, actorID, tenantID, a.UserID); err != nil {
+		return err
+	}
+
+	revoked := a.RevokedAt == nil
+	if revoked {
+		if err := s.repo.Revoke(ctx, tenantID, a.ID, actorID, s.clock.Now()); err != nil && !errors.Is(err, ErrAgreementNotFound) {
+			return fmt.Errorf("failed to revoke delegation agreement: %w", err)
+		}
+	}
+	n, err := s.tokens.RevokeGrant(ctx, tenantID, a.UserID, a.ID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke delegated tokens: %w", err)
+	}
+	if !revoked {
+		return nil
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeDelegationRevoked,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceDelegation,
+		TargetID:   a.ID,
+		TargetName: a.Purpose,
+		Metadata: map[string]any{
+			attrClientID: a.ClientID,
+			attrUserID:   a.UserID,
+			attrRevoked:  n,
+		},
+	})
+	return nil
+}
+
+// Use returns the agreement clientID presents for a delegated token request and
+// records its use.
+//
+// Purpose: The token service's check before minting delegated tokens.
+// Domain: OAuth2
+// Security: Unknown, revoked, and expired agreements, and agreements of another
+// client or tenant, all yield ErrAgreementInactive, so a client learns nothing about
+// agreements it does not hold.
+// Audited: No (the token request itself is audited by the token service)
+// Errors: ErrAgreementInactive, System errors
+func (s *Service) Use(ctx context.Context, tenantID, clientID, agreementID string) (*Agreement, error) {
+	a, err := s.repo.Get(ctx, tenantID, agreementID)
+	if errors.Is(err, ErrAgreementNotFound) {
+		return nil, ErrAgreementInactive
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegation agreement: %w", err)
+	}
+	now := s.clock.Now()
+	if a.ClientID != clientID || !a.IsActive(now) {
+		return nil, ErrAgreementInactive
+	}
+	if err := s.repo.MarkUsed(ctx, tenantID, a.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to record delegation use: %w", err)
+	}
+	a.LastUsedAt = &now
+	return a, nil
+}
+
+// authorize allows actors to manage their own agreements, and holders of
+// policy.PermTenantApproveDelegations to manage anyone's in the tenant.
+func (s *Service) authorize(ctx context.Context, actorID, tenantID, userID string) error {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	if actorID != "" && actorID == userID {
+		return nil
+	}
+	ok, err := s.can(ctx, actorID, tenantID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotPermitted
+	}
+	return nil
+}
+
+// can reports whether actorID holds policy.PermTenantApproveDelegations in tenantID.
+func (s *Service) can(ctx context.Context, actorID, tenantID string) (bool, error) {
+	if actorID == "" {
+		return false, nil
+	}
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopeTenant, &tenantID, policy.PermTenantApproveDelegations)
+	if err != nil {
+		return false, fmt.Errorf("failed to check delegation permission: %w", err)
+	}
+	return ok, nil
+}
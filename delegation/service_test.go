@@ -0,0 +1,319 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package delegation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type mockRepo struct {
+	agreements map[string]*Agreement
+	used       []string
+}
+
+func (m *mockRepo) Create(ctx context.Context, a *Agreement) error {
+	m.agreements[a.ID] = a
+	return nil
+}
+
+func (m *mockRepo) Get(ctx context.Context, tenantID, id string) (*Agreement, error) {
+	a, ok := m.agreements[id]
+	if !ok || a.TenantID != tenantID {
+		return nil, ErrAgreementNotFound
+	}
+	cp := *a
+	return &cp, nil
+}
+
+func (m *mockRepo) ListByUser(ctx context.Context, tenantID, userID string) ([]*Agreement, error) {
+	var out []*Agreement
+	for _, a := range m.agreements {
+		if a.TenantID == tenantID && a.UserID == userID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) Revoke(ctx context.Context, tenantID, id, revokedBy string, at time.Time) error {
+	a, ok := m.agreements[id]
+	if !ok || a.TenantID != tenantID || a.RevokedAt != nil {
+		return ErrAgreementNotFound
+	}
+	a.RevokedAt, a.RevokedBy = &at, revokedBy
+	return nil
+}
+
+func (m *mockRepo) MarkUsed(ctx context.Context, tenantID, id string, at time.Time) error {
+	m.used = append(m.used, id)
+	return nil
+}
+
+type mockPermissions map[string]bool
+
+func (m mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return m[userID], nil
+}
+
+type mockClients map[string]*client.Client
+
+func (m mockClients) GetClientByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error) {
+	c, ok := m[clientID]
+	if !ok || c.TenantID != tenantID {
+		return nil, client.ErrClientNotFound
+	}
+	return c, nil
+}
+
+type mockTokens struct {
+	revoked []string
+}
+
+func (m *mockTokens) RevokeGrant(ctx context.Context, tenantID, userID, grantID string) (int, error) {
+	m.revoked = append(m.revoked, grantID)
+	return 2, nil
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+type mockReasonPolicy bool
+
+func (m mockReasonPolicy) ReasonRequired(ctx context.Context, tenantID, operation string) (bool, error) {
+	return bool(m) && operation == audit.OpDelegation, nil
+}
+
+type fixture struct {
+	svc    *Service
+	repo   *mockRepo
+	tokens *mockTokens
+	logger *mockAuditLogger
+}
+
+func newFixture(opts ...Option) *fixture {
+	clients := mockClients{
+		"reports": {ClientID: "reports", TenantID: "t1", IsActive: true, GrantTypes: []string{client.GrantTypeDelegated}, AllowedScopes: []string{"openid", "read", "write", "offline_access"}},
+		"web":     {ClientID: "web", TenantID: "t1", IsActive: true, GrantTypes: []string{"authorization_code"}, AllowedScopes: []string{"openid", "read"}},
+		"spa":     {ClientID: "spa", TenantID: "t1", IsActive: true, TokenEndpointAuthMethod: client.AuthMethodNone, GrantTypes: []string{client.GrantTypeDelegated}},
+		"retired": {ClientID: "retired", TenantID: "t1", GrantTypes: []string{client.GrantTypeDelegated}, AllowedScopes: []string{"read"}},
+	}
+	repo, tokens, logger := &mockRepo{agreements: map[string]*Agreement{}}, &mockTokens{}, &mockAuditLogger{}
+	opts = append([]Option{WithClock(clock.Fixed(testNow))}, opts...)
+	return &fixture{
+		svc:    NewService(repo, mockPermissions{"admin": true}, clients, tokens, logger, opts...),
+		repo:   repo,
+		tokens: tokens,
+		logger: logger,
+	}
+}
+
+func TestApprove(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   string
+		modify  func(*Approval)
+		opts    []Option
+		reason  string
+		wantErr error
+	}{
+		{name: "valid", actor: "admin"},
+		{name: "without permission", actor: "u1", wantErr: ErrNotPermitted},
+		{name: "reason required", actor: "admin", opts: []Option{WithReasonPolicy(mockReasonPolicy(true))}, wantErr: audit.ErrReasonRequired},
+		{name: "reason given", actor: "admin", opts: []Option{WithReasonPolicy(mockReasonPolicy(true))}, reason: "nightly export"},
+		{name: "missing user", actor: "admin", modify: func(r *Approval) { r.UserID = "" }, wantErr: ErrInvalidAgreement},
+		{name: "missing purpose", actor: "admin", modify: func(r *Approval) { r.Purpose = " " }, wantErr: ErrInvalidAgreement},
+		{name: "lifetime too long", actor: "admin", modify: func(r *Approval) { r.Lifetime = 2 * DefaultMaxLifetime }, wantErr: ErrInvalidAgreement},
+		{name: "lifetime over custom cap", actor: "admin", opts: []Option{WithMaxLifetime(time.Hour)}, modify: func(r *Approval) { r.Lifetime = 2 * time.Hour }, wantErr: ErrInvalidAgreement},
+		{name: "unknown client", actor: "admin", modify: func(r *Approval) { r.ClientID = "missing" }, wantErr: client.ErrClientNotFound},
+		{name: "client without grant type", actor: "admin", modify: func(r *Approval) { r.ClientID = "web" }, wantErr: ErrClientNotDelegated},
+		{name: "public client", actor: "admin", modify: func(r *Approval) { r.ClientID = "spa" }, wantErr: ErrClientNotDelegated},
+		{name: "inactive client", actor: "admin", modify: func(r *Approval) { r.ClientID = "retired" }, wantErr: ErrClientNotDelegated},
+		{name: "missing scope", actor: "admin", modify: func(r *Approval) { r.Scope = "" }, wantErr: ErrInvalidAgreement},
+		{name: "offline access", actor: "admin", modify: func(r *Approval) { r.Scope = "read offline_access" }, wantErr: ErrInvalidAgreement},
+		{name: "scope not allowed", actor: "admin", modify: func(r *Approval) { r.Scope = "read admin" }, wantErr: client.ErrDomainInvalidScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(tt.opts...)
+			req := Approval{ClientID: "reports", UserID: "u1", Scope: "write read read", Purpose: "Nightly report export"}
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+			ctx := context.Background()
+			if tt.reason != "" {
+				ctx = audit.WithReason(ctx, tt.reason)
+			}
+
+			a, err := f.svc.Approve(ctx, "t1", tt.actor, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Approve() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(f.repo.agreements) != 0 || len(f.logger.events) != 0 {
+					t.Errorf("failed Approve() stored %d agreements, audited %d events", len(f.repo.agreements), len(f.logger.events))
+				}
+				return
+			}
+
+			if len(a.Scopes) != 2 || a.Scopes[0] != "read" || a.Scopes[1] != "write" {
+				t.Errorf("Scopes = %v, want [read write]", a.Scopes)
+			}
+			if a.ApprovedBy != "admin" || !a.ExpiresAt.Equal(testNow.Add(DefaultLifetime)) || !a.IsActive(testNow) {
+				t.Errorf("agreement = %+v", a)
+			}
+			if len(f.logger.events) != 1 || f.logger.events[0].Type != audit.TypeDelegationApproved || f.logger.events[0].TargetID != a.ID {
+				t.Fatalf("audit events = %+v", f.logger.events)
+			}
+			if got := f.logger.events[0].Metadata[audit.AttrReason]; tt.reason != "" && got != tt.reason {
+				t.Errorf("audited reason = %v, want %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+func seed(f *fixture, a *Agreement) {
+	if a.TenantID == "" {
+		a.TenantID = "t1"
+	}
+	if a.ClientID == "" {
+		a.ClientID = "reports"
+	}
+	if a.UserID == "" {
+		a.UserID = "u1"
+	}
+	if a.ExpiresAt.IsZero() {
+		a.ExpiresAt = testNow.Add(time.Hour)
+	}
+	a.Scopes = []string{"read"}
+	f.repo.agreements[a.ID] = a
+}
+
+func TestRevoke(t *testing.T) {
+	revokedAt := testNow.Add(-time.Hour)
+	tests := []struct {
+		name        string
+		actor       string
+		id          string
+		wantErr     error
+		wantAudited bool
+	}{
+		{name: "by the user", actor: "u1", id: "a1", wantAudited: true},
+		{name: "by an administrator", actor: "admin", id: "a1", wantAudited: true},
+		{name: "by another user", actor: "u2", id: "a1", wantErr: ErrNotPermitted},
+		{name: "unknown agreement", actor: "admin", id: "missing", wantErr: ErrAgreementNotFound},
+		{name: "other tenant", actor: "admin", id: "other", wantErr: ErrAgreementNotFound},
+		{name: "already revoked sweeps tokens again", actor: "u1", id: "revoked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			seed(f, &Agreement{ID: "a1"})
+			seed(f, &Agreement{ID: "other", TenantID: "t2"})
+			seed(f, &Agreement{ID: "revoked", RevokedAt: &revokedAt})
+
+			err := f.svc.Revoke(context.Background(), "t1", tt.actor, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Revoke() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(f.tokens.revoked) != 0 || len(f.logger.events) != 0 {
+					t.Errorf("failed Revoke() revoked %v, audited %d events", f.tokens.revoked, len(f.logger.events))
+				}
+				return
+			}
+
+			if len(f.tokens.revoked) != 1 || f.tokens.revoked[0] != tt.id {
+				t.Errorf("revoked grants = %v, want [%s]", f.tokens.revoked, tt.id)
+			}
+			if f.repo.agreements[tt.id].RevokedAt == nil {
+				t.Errorf("agreement %s not revoked", tt.id)
+			}
+			if got := len(f.logger.events) == 1 && f.logger.events[0].Type == audit.TypeDelegationRevoked; got != tt.wantAudited {
+				t.Errorf("audit events = %+v, want audited %v", f.logger.events, tt.wantAudited)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	f := newFixture()
+	seed(f, &Agreement{ID: "a1"})
+	seed(f, &Agreement{ID: "a2", UserID: "u2"})
+
+	for _, actor := range []string{"u1", "admin"} {
+		got, err := f.svc.List(context.Background(), "t1", actor, "u1")
+		if err != nil || len(got) != 1 || got[0].ID != "a1" {
+			t.Errorf("List(%s) = %v, %v, want [a1]", actor, got, err)
+		}
+	}
+	if _, err := f.svc.List(context.Background(), "t1", "u2", "u1"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("List() by another user error = %v, want ErrNotPermitted", err)
+	}
+}
+
+func TestUse(t *testing.T) {
+	revokedAt := testNow.Add(-time.Hour)
+	tests := []struct {
+		name    string
+		tenant  string
+		client  string
+		id      string
+		wantErr error
+	}{
+		{name: "active", tenant: "t1", client: "reports", id: "a1"},
+		{name: "unknown agreement", tenant: "t1", client: "reports", id: "missing", wantErr: ErrAgreementInactive},
+		{name: "other client", tenant: "t1", client: "web", id: "a1", wantErr: ErrAgreementInactive},
+		{name: "other tenant", tenant: "t2", client: "reports", id: "a1", wantErr: ErrAgreementInactive},
+		{name: "expired", tenant: "t1", client: "reports", id: "expired", wantErr: ErrAgreementInactive},
+		{name: "revoked", tenant: "t1", client: "reports", id: "revoked", wantErr: ErrAgreementInactive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			seed(f, &Agreement{ID: "a1"})
+			seed(f, &Agreement{ID: "expired", ExpiresAt: testNow})
+			seed(f, &Agreement{ID: "revoked", RevokedAt: &revokedAt})
+
+			a, err := f.svc.Use(context.Background(), tt.tenant, tt.client, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Use() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(f.repo.used) != 0 {
+					t.Errorf("failed Use() recorded use of %v", f.repo.used)
+				}
+				return
+			}
+			if a.LastUsedAt == nil || !a.LastUsedAt.Equal(testNow) || len(f.repo.used) != 1 {
+				t.Errorf("Use() = %+v, recorded %v", a, f.repo.used)
+			}
+		})
+	}
+}
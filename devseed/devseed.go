@@ -0,0 +1,331 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package devseed provisions a demo environment: tenants, users with known
+// passwords, OAuth2 clients with known secrets, role assignments, and sample
+// audit events. It exists for local development and for integration tests of
+// applications built on core, and refuses to run unless explicitly unlocked.
+package devseed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/token"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// Domain errors
+var (
+	ErrUnsafeNotAllowed = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "demo seeding is disabled; it must be unlocked with AllowUnsafe")
+)
+
+// DemoPassword is the password of every demo user.
+const DemoPassword = "opentrusty-demo"
+
+// Spec describes a demo environment.
+//
+// Purpose: Declarative demo data, built in Go or decoded from JSON.
+// Domain: Platform (Development)
+// Invariants: Passwords and client secrets are plaintext on purpose; a Spec must never
+// describe a real deployment.
+type Spec struct {
+	Tenants []TenantSpec `json:"tenants"`
+}
+
+// TenantSpec is one demo tenant. Owner becomes its tenant_owner.
+type TenantSpec struct {
+	Name    string       `json:"name"`
+	Owner   UserSpec     `json:"owner"`
+	Users   []UserSpec   `json:"users,omitempty"`
+	Clients []ClientSpec `json:"clients,omitempty"`
+}
+
+// UserSpec is one demo user. Role is a tenant role (role.RoleTenantAdmin, role.RoleTenantMember).
+type UserSpec struct {
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	GivenName  string `json:"given_name,omitempty"`
+	FamilyName string `json:"family_name,omitempty"`
+	Role       string `json:"role,omitempty"`
+}
+
+// ClientSpec is one demo client. ClientID must be a UUID so that reruns find it again.
+// A client without Secret is a public single-page application.
+type ClientSpec struct {
+	ClientID     string   `json:"client_id"`
+	Name         string   `json:"name"`
+	Secret       string   `json:"secret,omitempty"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Origins      []string `json:"origins,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+// Demo returns the built-in demo environment: two tenants, each with an owner, an
+// admin, and a member (all with DemoPassword), a confidential web client, and a
+// public SPA client.
+func Demo() *Spec {
+	return &Spec{Tenants: []TenantSpec{
+		demoTenant("Acme Corp", "acme.test", "018f0000-0000-7000-8000-00000000a001", "018f0000-0000-7000-8000-00000000a002"),
+		demoTenant("Globex", "globex.test", "018f0000-0000-7000-8000-00000000b001", "018f0000-0000-7000-8000-00000000b002"),
+	}}
+}
+
+// demoTenant builds one demo tenant whose users live under domain.
+func demoTenant(name, domain, webClientID, spaClientID string) TenantSpec {
+	return TenantSpec{
+		Name:  name,
+		Owner: UserSpec{Email: "owner@" + domain, Password: DemoPassword, GivenName: "Olivia", FamilyName: "Owner"},
+		Users: []UserSpec{
+			{Email: "admin@" + domain, Password: DemoPassword, GivenName: "Adam", FamilyName: "Admin", Role: role.RoleTenantAdmin},
+			{Email: "member@" + domain, Password: DemoPassword, GivenName: "Mia", FamilyName: "Member", Role: role.RoleTenantMember},
+		},
+		Clients: []ClientSpec{
+			{
+				ClientID:     webClientID,
+				Name:         name + " Web",
+				Secret:       "demo-secret-" + domain,
+				RedirectURIs: []string{"http://localhost:3000/callback"},
+				Scopes:       []string{client.ScopeOpenID, client.ScopeProfile, client.ScopeEmail, client.ScopeOfflineAccess},
+			},
+			{
+				ClientID:     spaClientID,
+				Name:         name + " SPA",
+				RedirectURIs: []string{"http://localhost:5173/callback"},
+				Origins:      []string{"http://localhost:5173"},
+				Scopes:       []string{client.ScopeOpenID, client.ScopeProfile},
+			},
+		},
+	}
+}
+
+// Tenants creates demo tenants and assigns their roles; tenant.Service implements it.
+type Tenants interface {
+	GetTenantByName(ctx context.Context, name string) (*tenant.Tenant, error)
+	CreateTenant(ctx context.Context, name, ownerEmail, ownerPassword, creatorUserID string) (*tenant.Tenant, error)
+	AssignRole(ctx context.Context, tenantID, userID, roleName, grantedBy string) error
+}
+
+// Users creates demo identities; user.Service implements it.
+type Users interface {
+	GetByEmail(ctx context.Context, emailPlain string) (*user.User, error)
+	ProvisionIdentity(ctx context.Context, emailPlain string, profile user.Profile) (*user.User, error)
+	SetPassword(ctx context.Context, userID, password string) error
+}
+
+// Clients registers demo clients; client.Service implements it.
+type Clients interface {
+	GetClientByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error)
+	RegisterClient(ctx context.Context, tenantID, userID string, c *client.Client) (*client.Client, error)
+}
+
+// Result lists what Seed provisioned, keyed by tenant name, email, and client ID.
+type Result struct {
+	Tenants map[string]string
+	Users   map[string]string
+	Clients []string
+	// Existing counts entities that were already present and left unchanged.
+	Existing int
+}
+
+// Service provisions demo environments.
+//
+// Purpose: One call from an empty database to a usable sandbox.
+// Domain: Platform (Development)
+// Invariants: Seed does nothing unless the Service was built with AllowUnsafe. All
+// writes go through the domain services, so demo data obeys the same validation and
+// audit rules as real data.
+type Service struct {
+	tenants     Tenants
+	users       Users
+	clients     Clients
+	auditLogger audit.Logger
+	unsafe      bool
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// AllowUnsafe unlocks Seed. Only set it for local development and test environments:
+// seeded accounts have published passwords.
+func AllowUnsafe() Option {
+	return func(s *Service) { s.unsafe = true }
+}
+
+// NewService creates a new demo seeding service.
+func NewService(tenants Tenants, users Users, clients Clients, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{tenants: tenants, users: users, clients: clients, auditLogger: auditLogger}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Seed provisions spec. It is idempotent: tenants are matched by name, users by email,
+// and clients by client ID, and existing ones are left as they are.
+//
+// Purpose: Populate a development or test database with demo data.
+// Domain: Platform (Development)
+// Security: Refused unless AllowUnsafe was given, since it creates accounts whose
+// passwords and client secrets are public. Tenants are created by the system; roles
+// and clients are granted and registered by the tenant owner.
+// Audited: Yes (through the domain services; sample login events are tagged "demo")
+// Errors: ErrUnsafeNotAllowed, domain validation errors, System errors
+func (s *Service) Seed(ctx context.Context, spec *Spec) (*Result, error) {
+	if !s.unsafe {
+		return nil, ErrUnsafeNotAllowed
+	}
+	res := &Result{Tenants: make(map[string]string), Users: make(map[string]string)}
+	for _, ts := range spec.Tenants {
+		if err := s.seedTenant(ctx, ts, res); err != nil {
+			return res, fmt.Errorf("failed to seed tenant %q: %w", ts.Name, err)
+		}
+	}
+	return res, nil
+}
+
+func (s *Service) seedTenant(ctx context.Context, ts TenantSpec, res *Result) error {
+	t, err := s.tenants.GetTenantByName(ctx, ts.Name)
+	created := false
+	switch {
+	case err == nil:
+		res.Existing++
+	case errors.Is(err, tenant.ErrTenantNotFound):
+		t, err = s.tenants.CreateTenant(ctx, ts.Name, ts.Owner.Email, ts.Owner.Password, "")
+		if err != nil {
+			return err
+		}
+		created = true
+	default:
+		return err
+	}
+	res.Tenants[ts.Name] = t.ID
+
+	// CreateTenant provisioned the owner together with the tenant.
+	owner, err := s.users.GetByEmail(ctx, ts.Owner.Email)
+	if err != nil {
+		return fmt.Errorf("failed to look up owner %s: %w", ts.Owner.Email, err)
+	}
+	res.Users[ts.Owner.Email] = owner.ID
+	if created {
+		s.logSampleLogin(ctx, t.ID, owner.ID)
+	}
+
+	for _, us := range ts.Users {
+		u, created, err := s.seedUser(ctx, us, res)
+		if err != nil {
+			return err
+		}
+		if !created {
+			continue
+		}
+		if us.Role != "" {
+			if err := s.tenants.AssignRole(ctx, t.ID, u.ID, us.Role, owner.ID); err != nil {
+				return fmt.Errorf("failed to assign %s to %s: %w", us.Role, us.Email, err)
+			}
+		}
+		s.logSampleLogin(ctx, t.ID, u.ID)
+	}
+
+	for _, cs := range ts.Clients {
+		if err := s.seedClient(ctx, t.ID, owner.ID, cs, res); err != nil {
+			return fmt.Errorf("failed to seed client %q: %w", cs.Name, err)
+		}
+	}
+	return nil
+}
+
+// seedUser returns the user with us.Email, creating it with its password if missing,
+// and reports whether it was created.
+func (s *Service) seedUser(ctx context.Context, us UserSpec, res *Result) (*user.User, bool, error) {
+	u, err := s.users.GetByEmail(ctx, us.Email)
+	switch {
+	case err == nil:
+		res.Existing++
+		res.Users[us.Email] = u.ID
+		return u, false, nil
+	case errors.Is(err, user.ErrUserNotFound):
+	default:
+		return nil, false, err
+	}
+
+	u, err = s.users.ProvisionIdentity(ctx, us.Email, user.Profile{GivenName: us.GivenName, FamilyName: us.FamilyName})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to provision %s: %w", us.Email, err)
+	}
+	if err := s.users.SetPassword(ctx, u.ID, us.Password); err != nil {
+		return nil, false, fmt.Errorf("failed to set password of %s: %w", us.Email, err)
+	}
+	res.Users[us.Email] = u.ID
+	return u, true, nil
+}
+
+// seedClient registers cs in the tenant, owned by the tenant owner, unless it exists.
+func (s *Service) seedClient(ctx context.Context, tenantID, ownerID string, cs ClientSpec, res *Result) error {
+	if _, err := s.clients.GetClientByClientID(ctx, tenantID, cs.ClientID); err == nil {
+		res.Existing++
+		res.Clients = append(res.Clients, cs.ClientID)
+		return nil
+	}
+	c := &client.Client{
+		ClientID:                cs.ClientID,
+		TenantID:                tenantID,
+		ClientName:              cs.Name,
+		RedirectURIs:            cs.RedirectURIs,
+		AllowedScopes:           cs.Scopes,
+		GrantTypes:              []string{token.GrantTypeAuthorizationCode},
+		ResponseTypes:           []string{"code"},
+		TokenEndpointAuthMethod: client.AuthMethodClientSecretBasic,
+		ApplicationType:         client.ApplicationTypeWeb,
+		OwnerID:                 ownerID,
+		IsActive:                true,
+	}
+	if len(c.AllowedScopes) == 0 {
+		c.AllowedScopes = []string{client.ScopeOpenID}
+	}
+	if cs.Secret == "" {
+		c.TokenEndpointAuthMethod = client.AuthMethodNone
+		c.ApplicationType = client.ApplicationTypeSPA
+		c.AllowedOrigins = cs.Origins
+	} else {
+		c.ClientSecretHash = client.HashClientSecret(cs.Secret)
+		if slices.Contains(cs.Scopes, client.ScopeOfflineAccess) {
+			c.GrantTypes = append(c.GrantTypes, token.GrantTypeRefreshToken)
+		}
+	}
+	if _, err := s.clients.RegisterClient(ctx, tenantID, ownerID, c); err != nil {
+		return err
+	}
+	res.Clients = append(res.Clients, cs.ClientID)
+	return nil
+}
+
+// logSampleLogin records a demo login so audit views have something to show.
+func (s *Service) logSampleLogin(ctx context.Context, tenantID, userID string) {
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeLoginSuccess,
+		TenantID: tenantID,
+		ActorID:  userID,
+		Resource: audit.ResourceUser,
+		TargetID: userID,
+		Metadata: map[string]any{"demo": true},
+	})
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devseed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+type mockUsers struct {
+	byEmail   map[string]*user.User
+	passwords map[string]string
+}
+
+func (m *mockUsers) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	if u, ok := m.byEmail[email]; ok {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (m *mockUsers) ProvisionIdentity(ctx context.Context, email string, profile user.Profile) (*user.User, error) {
+	u := &user.User{ID: fmt.Sprintf("u%d", len(m.byEmail)+1), Profile: profile}
+	m.byEmail[email] = u
+	return u, nil
+}
+
+func (m *mockUsers) SetPassword(ctx context.Context, userID, password string) error {
+	m.passwords[userID] = password
+	return nil
+}
+
+type mockTenants struct {
+	users   *mockUsers
+	byName  map[string]*tenant.Tenant
+	roles   map[string]string
+	created int
+}
+
+func (m *mockTenants) GetTenantByName(ctx context.Context, name string) (*tenant.Tenant, error) {
+	if t, ok := m.byName[name]; ok {
+		return t, nil
+	}
+	return nil, tenant.ErrTenantNotFound
+}
+
+func (m *mockTenants) CreateTenant(ctx context.Context, name, ownerEmail, ownerPassword, creatorUserID string) (*tenant.Tenant, error) {
+	m.created++
+	t := &tenant.Tenant{ID: fmt.Sprintf("t%d", m.created), Name: name}
+	m.byName[name] = t
+	owner, _ := m.users.ProvisionIdentity(ctx, ownerEmail, user.Profile{})
+	_ = m.users.SetPassword(ctx, owner.ID, ownerPassword)
+	m.roles[t.ID+"/"+owner.ID] = "tenant_owner"
+	return t, nil
+}
+
+func (m *mockTenants) AssignRole(ctx context.Context, tenantID, userID, roleName, grantedBy string) error {
+	m.roles[tenantID+"/"+userID] = roleName
+	return nil
+}
+
+type mockClients struct {
+	clients map[string]*client.Client
+}
+
+func (m *mockClients) GetClientByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error) {
+	if c, ok := m.clients[clientID]; ok && c.TenantID == tenantID {
+		return c, nil
+	}
+	return nil, client.ErrClientNotFound
+}
+
+func (m *mockClients) RegisterClient(ctx context.Context, tenantID, userID string, c *client.Client) (*client.Client, error) {
+	m.clients[c.ClientID] = c
+	return c, nil
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+func newTestService(opts ...Option) (*Service, *mockTenants, *mockUsers, *mockClients, *mockAuditLogger) {
+	users := &mockUsers{byEmail: map[string]*user.User{}, passwords: map[string]string{}}
+	tenants := &mockTenants{users: users, byName: map[string]*tenant.Tenant{}, roles: map[string]string{}}
+	clients := &mockClients{clients: map[string]*client.Client{}}
+	logger := &mockAuditLogger{}
+	return NewService(tenants, users, clients, logger, opts...), tenants, users, clients, logger
+}
+
+func TestSeedRequiresUnsafe(t *testing.T) {
+	s, tenants, _, _, _ := newTestService()
+	if _, err := s.Seed(context.Background(), Demo()); !errors.Is(err, ErrUnsafeNotAllowed) {
+		t.Fatalf("Seed() error = %v, want ErrUnsafeNotAllowed", err)
+	}
+	if tenants.created != 0 {
+		t.Errorf("Seed() created %d tenants while locked", tenants.created)
+	}
+}
+
+func TestSeedDemo(t *testing.T) {
+	ctx := context.Background()
+	s, tenants, users, clients, logger := newTestService(AllowUnsafe())
+
+	res, err := s.Seed(ctx, Demo())
+	if err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+	if len(res.Tenants) != 2 || len(res.Users) != 6 || len(res.Clients) != 4 || res.Existing != 0 {
+		t.Fatalf("Seed() = %+v", res)
+	}
+	for email, id := range res.Users {
+		if users.passwords[id] != DemoPassword {
+			t.Errorf("%s password = %q, want DemoPassword", email, users.passwords[id])
+		}
+	}
+	acme := res.Tenants["Acme Corp"]
+	if got := tenants.roles[acme+"/"+res.Users["admin@acme.test"]]; got != "tenant_admin" {
+		t.Errorf("admin@acme.test role = %q, want tenant_admin", got)
+	}
+
+	web := clients.clients["018f0000-0000-7000-8000-00000000a001"]
+	if web == nil || web.TenantID != acme || !web.VerifySecret("demo-secret-acme.test") || web.OwnerID != res.Users["owner@acme.test"] {
+		t.Errorf("web client = %+v", web)
+	}
+	spa := clients.clients["018f0000-0000-7000-8000-00000000a002"]
+	if spa == nil || !spa.IsPublic() || spa.ClientSecretHash != "" || len(spa.AllowedOrigins) != 1 {
+		t.Errorf("spa client = %+v", spa)
+	}
+
+	logins := 0
+	for _, e := range logger.events {
+		if e.Type == audit.TypeLoginSuccess && e.Metadata["demo"] == true {
+			logins++
+		}
+	}
+	if logins != 6 {
+		t.Errorf("sample login events = %d, want 6", logins)
+	}
+
+	events := len(logger.events)
+	again, err := s.Seed(ctx, Demo())
+	if err != nil {
+		t.Fatalf("second Seed() error = %v", err)
+	}
+	if tenants.created != 2 || len(users.byEmail) != 6 || len(logger.events) != events || again.Existing != 2+4+4 {
+		t.Errorf("second Seed() created data: tenants %d, users %d, result %+v", tenants.created, len(users.byEmail), again)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/id"
+)
+
+// Envelope is the wire format used when forwarding events to a message broker.
+type Envelope struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Data Event  `json:"data"`
+}
+
+// Transport sends encoded events to an external broker.
+//
+// Purpose: Adapter boundary for NATS, Kafka, or any other broker client owned by the host.
+// Domain: Platform (Events)
+//
+// A NATS adapter publishes data on subject; a Kafka adapter writes a message
+// to topic subject keyed by key so that a tenant's events stay ordered.
+type Transport interface {
+	Send(ctx context.Context, subject, key string, data []byte) error
+}
+
+// TransportFunc adapts a function to Transport.
+type TransportFunc func(ctx context.Context, subject, key string, data []byte) error
+
+// Send calls f.
+func (f TransportFunc) Send(ctx context.Context, subject, key string, data []byte) error {
+	return f(ctx, subject, key, data)
+}
+
+// BrokerHandler returns a Handler that forwards every event to t as a JSON
+// Envelope on subject "<prefix>.<event name>", keyed by tenant ID.
+//
+// Purpose: Bridges the in-process bus to out-of-process consumers.
+// Domain: Platform (Events)
+// Audited: No
+// Errors: Encoding and transport errors
+func BrokerHandler(t Transport, prefix string) Handler {
+	return func(ctx context.Context, e Event) error {
+		data, err := json.Marshal(Envelope{ID: id.NewUUIDv7(), Name: e.EventName(), Data: e})
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+
+		subject := e.EventName()
+		if prefix != "" {
+			subject = prefix + "." + subject
+		}
+		if err := t.Send(ctx, subject, e.EventTenantID(), data); err != nil {
+			return fmt.Errorf("failed to forward event: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Dispatcher is the in-process event bus.
+//
+// Purpose: Synchronous fan-out of events to subscribed handlers.
+// Domain: Platform (Events)
+// Invariants: Handlers run in subscription order on the publishing goroutine.
+// A failing handler is logged and does not stop later handlers or the publisher.
+type Dispatcher struct {
+	mu       sync.RWMutex
+	byName   map[string][]Handler
+	wildcard []Handler
+}
+
+// NewDispatcher creates an empty dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{byName: make(map[string][]Handler)}
+}
+
+// Subscribe registers h for events named name.
+func (d *Dispatcher) Subscribe(name string, h Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.byName[name] = append(d.byName[name], h)
+}
+
+// SubscribeAll registers h for every event.
+func (d *Dispatcher) SubscribeAll(h Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.wildcard = append(d.wildcard, h)
+}
+
+// Publish delivers e to every matching handler.
+func (d *Dispatcher) Publish(ctx context.Context, e Event) {
+	d.mu.RLock()
+	handlers := make([]Handler, 0, len(d.wildcard)+len(d.byName[e.EventName()]))
+	handlers = append(handlers, d.byName[e.EventName()]...)
+	handlers = append(handlers, d.wildcard...)
+	d.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			slog.WarnContext(ctx, "event handler failed", "event", e.EventName(), "error", err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDispatcherRouting(t *testing.T) {
+	d := NewDispatcher()
+
+	var named, all []string
+	d.Subscribe(NameUserCreated, func(ctx context.Context, e Event) error {
+		named = append(named, e.EventName())
+		return errors.New("handler failure must not stop later handlers")
+	})
+	d.SubscribeAll(func(ctx context.Context, e Event) error {
+		all = append(all, e.EventName())
+		return nil
+	})
+
+	ctx := context.Background()
+	d.Publish(ctx, UserCreated{Meta: NewMeta("", ""), UserID: "u1"})
+	d.Publish(ctx, TenantDeleted{Meta: NewMeta("t1", "admin")})
+
+	if len(named) != 1 || named[0] != NameUserCreated {
+		t.Errorf("named handler saw %v, want [%s]", named, NameUserCreated)
+	}
+	if len(all) != 2 {
+		t.Errorf("wildcard handler saw %v, want 2 events", all)
+	}
+}
+
+func TestEmitNilPublisher(t *testing.T) {
+	// Services hold a nil Publisher unless configured; Emit must tolerate it.
+	Emit(context.Background(), nil, UserCreated{UserID: "u1"})
+}
+
+func TestBrokerHandler(t *testing.T) {
+	var subject, key string
+	var env struct {
+		ID   string          `json:"id"`
+		Name string          `json:"name"`
+		Data json.RawMessage `json:"data"`
+	}
+	transport := TransportFunc(func(ctx context.Context, s, k string, data []byte) error {
+		subject, key = s, k
+		return json.Unmarshal(data, &env)
+	})
+
+	h := BrokerHandler(transport, "opentrusty")
+	err := h(context.Background(), RoleAssigned{Meta: NewMeta("t1", "admin"), UserID: "u1", Role: "tenant_admin"})
+	if err != nil {
+		t.Fatalf("BrokerHandler() error = %v", err)
+	}
+
+	if subject != "opentrusty.role.assigned" || key != "t1" {
+		t.Errorf("subject=%q key=%q", subject, key)
+	}
+	if env.ID == "" || env.Name != NameRoleAssigned {
+		t.Errorf("envelope = %+v", env)
+	}
+
+	var data RoleAssigned
+	if err := json.Unmarshal(env.Data, &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if data.TenantID != "t1" || data.UserID != "u1" || data.Role != "tenant_admin" {
+		t.Errorf("data = %+v", data)
+	}
+}
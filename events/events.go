@@ -0,0 +1,281 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events defines the typed domain events emitted by core services and
+// the in-process dispatcher that fans them out to subscribers (webhooks,
+// provisioning, cache invalidation, message brokers) without coupling the
+// emitting service to any of them.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Event names. Names are stable and shared with external consumers.
+const (
+	NameUserCreated     = "user.created"
+	NameUserUpdated     = "user.updated"
+	NameUserLocked      = "user.locked"
+	NameUserUnlocked    = "user.unlocked"
+	NameUserDeleted     = "user.deleted"
+	NameSuspiciousLogin = "user.suspicious_login"
+	NameLoginFailed     = "user.login_failed"
+	NameRecoveryStarted = "user.recovery_started"
+	NameUserRecovered   = "user.recovered"
+	NamePasswordChanged = "user.password_changed"
+	NameTenantCreated   = "tenant.created"
+	NameTenantUpdated   = "tenant.updated"
+	NameTenantDeleted   = "tenant.deleted"
+	NameRoleAssigned    = "role.assigned"
+	NameRoleRevoked     = "role.revoked"
+	NameClientCreated   = "client.created"
+	NameClientUpdated   = "client.updated"
+	NameClientDeleted   = "client.deleted"
+	NameSessionCreated  = "session.created"
+	NameSessionRevoked  = "session.revoked"
+	NameTokenIssued     = "token.issued"
+	NameTokenRevoked    = "token.revoked"
+)
+
+// Token kinds carried by TokenIssued.
+const (
+	TokenKindAccess  = "access"
+	TokenKindRefresh = "refresh"
+)
+
+// Event is a domain event.
+//
+// Purpose: Common contract for everything published on the bus.
+// Domain: Platform (Events)
+// Invariants: Events carry identifiers only, never PII, secrets, or credentials.
+type Event interface {
+	// EventName returns one of the Name constants
+	EventName() string
+	// EventTenantID returns the owning tenant, or "" for platform-level events
+	EventTenantID() string
+	// EventTime returns when the event occurred
+	EventTime() time.Time
+}
+
+// Meta holds fields common to all events.
+type Meta struct {
+	TenantID   string    `json:"tenant_id,omitempty"`
+	ActorID    string    `json:"actor_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// NewMeta returns Meta stamped with the current time.
+func NewMeta(tenantID, actorID string) Meta {
+	return Meta{TenantID: tenantID, ActorID: actorID, OccurredAt: time.Now()}
+}
+
+// EventTenantID returns the owning tenant.
+func (m Meta) EventTenantID() string { return m.TenantID }
+
+// EventTime returns when the event occurred.
+func (m Meta) EventTime() time.Time { return m.OccurredAt }
+
+// UserCreated is emitted when an identity is provisioned.
+type UserCreated struct {
+	Meta
+	UserID string `json:"user_id"`
+}
+
+// UserUpdated is emitted when a user's profile changes.
+type UserUpdated struct {
+	Meta
+	UserID string `json:"user_id"`
+}
+
+// UserLocked is emitted when repeated failures lock an account.
+type UserLocked struct {
+	Meta
+	UserID      string    `json:"user_id"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// UserUnlocked is emitted when an administrator lifts a lockout.
+type UserUnlocked struct {
+	Meta
+	UserID string `json:"user_id"`
+}
+
+// UserDeleted is emitted when an identity is removed.
+type UserDeleted struct {
+	Meta
+	UserID string `json:"user_id"`
+}
+
+// SuspiciousLogin is emitted when a successful login raises risk signals.
+type SuspiciousLogin struct {
+	Meta
+	UserID  string   `json:"user_id"`
+	Signals []string `json:"signals"`
+	Score   int      `json:"score"`
+	Country string   `json:"country,omitempty"`
+}
+
+// LoginFailed is emitted when a password login is rejected. It carries the
+// error code only, not the account, so unknown identifiers are not disclosed.
+type LoginFailed struct {
+	Meta
+	Code string `json:"code"`
+}
+
+// RecoveryStarted is emitted when an account recovery is requested.
+type RecoveryStarted struct {
+	Meta
+	UserID      string    `json:"user_id"`
+	RequestID   string    `json:"request_id"`
+	Method      string    `json:"method"`
+	AvailableAt time.Time `json:"available_at"`
+}
+
+// UserRecovered is emitted when an account recovery completes.
+type UserRecovered struct {
+	Meta
+	UserID    string `json:"user_id"`
+	RequestID string `json:"request_id"`
+	Method    string `json:"method"`
+}
+
+// PasswordChanged is emitted when a user's password is set or changed.
+type PasswordChanged struct {
+	Meta
+	UserID string `json:"user_id"`
+}
+
+// TenantCreated is emitted when a tenant is created.
+type TenantCreated struct {
+	Meta
+}
+
+// TenantUpdated is emitted when a tenant is renamed.
+type TenantUpdated struct {
+	Meta
+}
+
+// TenantDeleted is emitted after a tenant and its dependents are deleted.
+type TenantDeleted struct {
+	Meta
+}
+
+// RoleAssigned is emitted when a tenant role is granted to a user.
+type RoleAssigned struct {
+	Meta
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// RoleRevoked is emitted when a tenant role is removed from a user.
+type RoleRevoked struct {
+	Meta
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// ClientCreated is emitted when an OAuth2 client is registered.
+type ClientCreated struct {
+	Meta
+	ClientID string `json:"client_id"`
+}
+
+// ClientUpdated is emitted when an OAuth2 client changes.
+type ClientUpdated struct {
+	Meta
+	ClientID string `json:"client_id"`
+}
+
+// ClientDeleted is emitted when an OAuth2 client is deleted.
+type ClientDeleted struct {
+	Meta
+	ClientID string `json:"client_id"`
+}
+
+// SessionCreated is emitted when a session is established.
+// The session ID is a bearer secret and is deliberately not included.
+type SessionCreated struct {
+	Meta
+	UserID string `json:"user_id"`
+}
+
+// SessionRevoked is emitted when one or all of a user's sessions are destroyed.
+type SessionRevoked struct {
+	Meta
+	UserID string `json:"user_id"`
+	// All is true when every session of UserID was revoked.
+	All bool `json:"all,omitempty"`
+}
+
+// TokenIssued is emitted when an access or refresh token is persisted.
+type TokenIssued struct {
+	Meta
+	TokenID  string `json:"token_id"`
+	Kind     string `json:"kind"`
+	ClientID string `json:"client_id"`
+	UserID   string `json:"user_id,omitempty"`
+	GrantID  string `json:"grant_id,omitempty"`
+}
+
+// TokenRevoked is emitted when an access or refresh token is revoked.
+type TokenRevoked struct {
+	Meta
+	TokenID  string `json:"token_id"`
+	ClientID string `json:"client_id,omitempty"`
+	UserID   string `json:"user_id,omitempty"`
+	GrantID  string `json:"grant_id,omitempty"`
+}
+
+func (UserCreated) EventName() string     { return NameUserCreated }
+func (UserUpdated) EventName() string     { return NameUserUpdated }
+func (UserLocked) EventName() string      { return NameUserLocked }
+func (UserUnlocked) EventName() string    { return NameUserUnlocked }
+func (UserDeleted) EventName() string     { return NameUserDeleted }
+func (SuspiciousLogin) EventName() string { return NameSuspiciousLogin }
+func (LoginFailed) EventName() string     { return NameLoginFailed }
+func (RecoveryStarted) EventName() string { return NameRecoveryStarted }
+func (UserRecovered) EventName() string   { return NameUserRecovered }
+func (PasswordChanged) EventName() string { return NamePasswordChanged }
+func (TenantCreated) EventName() string   { return NameTenantCreated }
+func (TenantUpdated) EventName() string   { return NameTenantUpdated }
+func (TenantDeleted) EventName() string   { return NameTenantDeleted }
+func (RoleAssigned) EventName() string    { return NameRoleAssigned }
+func (RoleRevoked) EventName() string     { return NameRoleRevoked }
+func (ClientCreated) EventName() string   { return NameClientCreated }
+func (ClientUpdated) EventName() string   { return NameClientUpdated }
+func (ClientDeleted) EventName() string   { return NameClientDeleted }
+func (SessionCreated) EventName() string  { return NameSessionCreated }
+func (SessionRevoked) EventName() string  { return NameSessionRevoked }
+func (TokenIssued) EventName() string     { return NameTokenIssued }
+func (TokenRevoked) EventName() string    { return NameTokenRevoked }
+
+// Publisher accepts events from services.
+//
+// Purpose: The only dependency services take on the event system.
+// Domain: Platform (Events)
+// Invariants: Publish never fails the caller's operation.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// Emit publishes e on p if p is non-nil.
+func Emit(ctx context.Context, p Publisher, e Event) {
+	if p != nil {
+		p.Publish(ctx, e)
+	}
+}
+
+// Handler consumes an event.
+type Handler func(ctx context.Context, e Event) error
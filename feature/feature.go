@@ -0,0 +1,203 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package feature is the capability registry for optional protocol behaviors.
+// Each flag has a built-in default that a deployment can override through
+// configuration and, where the flag allows it, a tenant can override again.
+// Services consult a Checker at their decision points; transports use the
+// same Checker to enforce request-level rules and to build discovery metadata.
+package feature
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrUnknownFlag      = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "unknown feature flag")
+	ErrNotTenantScoped  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "feature flag cannot be overridden per tenant")
+	ErrOverrideNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "feature flag override not found")
+)
+
+// Flag names an optional behavior.
+type Flag string
+
+// Flags
+const (
+	// DPoPRequired rejects bearer access tokens; every token must be DPoP-bound (RFC 9449).
+	DPoPRequired Flag = "dpop_required"
+	// PARRequired rejects authorization requests not made through pushed authorization (RFC 9126).
+	PARRequired Flag = "par_required"
+	// ImplicitFlowAllowed permits clients that receive tokens from the authorization endpoint.
+	ImplicitFlowAllowed Flag = "implicit_flow_allowed"
+	// LegacyHashLogin permits login against imported bcrypt/PBKDF2 hashes, which are upgraded on success.
+	LegacyHashLogin Flag = "legacy_hash_login"
+	// LoginAttemptFeedback reports remaining attempts and lockout countdowns on failed logins.
+	// It discloses that an account exists, so it is off unless a tenant opts in.
+	LoginAttemptFeedback Flag = "login_attempt_feedback"
+	// PKCEPlainAllowed accepts the "plain" PKCE code challenge method, which exposes
+	// the verifier in the authorization request; only S256 is accepted otherwise.
+	PKCEPlainAllowed Flag = "pkce_plain_allowed"
+	// PasswordGrantAllowed lets the tenant's opted-in clients use the resource owner
+	// password credentials grant, which exposes user passwords to the client.
+	PasswordGrantAllowed Flag = "password_grant_allowed"
+)
+
+// Definition describes a flag.
+//
+// Purpose: Registry entry with the flag's default and override scope.
+// Domain: Platform
+// Invariants: Defaults are the secure choice unless a default-on flag is needed for compatibility.
+type Definition struct {
+	Flag        Flag
+	Description string
+	Default     bool
+	// TenantScoped reports whether tenants may override the deployment value.
+	TenantScoped bool
+	// Metadata is the authorization server metadata parameter that reports the
+	// flag, or "" if the flag is not advertised.
+	Metadata string
+}
+
+var definitions = []Definition{
+	{
+		Flag:         DPoPRequired,
+		Description:  "Require DPoP-bound access tokens",
+		TenantScoped: true,
+	},
+	{
+		Flag:         PARRequired,
+		Description:  "Require pushed authorization requests",
+		TenantScoped: true,
+		Metadata:     "require_pushed_authorization_requests",
+	},
+	{
+		Flag:         ImplicitFlowAllowed,
+		Description:  "Allow implicit-flow clients",
+		TenantScoped: true,
+	},
+	{
+		Flag:        LegacyHashLogin,
+		Description: "Allow login with imported legacy password hashes",
+		Default:     true,
+	},
+	{
+		Flag:         LoginAttemptFeedback,
+		Description:  "Report remaining login attempts and lockout countdowns",
+		TenantScoped: true,
+	},
+	{
+		Flag:         PKCEPlainAllowed,
+		Description:  "Accept the plain PKCE code challenge method",
+		TenantScoped: true,
+	},
+	{
+		Flag:         PasswordGrantAllowed,
+		Description:  "Allow the password grant for opted-in clients",
+		TenantScoped: true,
+	},
+}
+
+// Definitions returns every registered flag.
+func Definitions() []Definition {
+	return slices.Clone(definitions)
+}
+
+// Lookup returns the definition of f.
+func Lookup(f Flag) (Definition, bool) {
+	for _, d := range definitions {
+		if d.Flag == f {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// IsKnown reports whether name is a registered flag.
+func IsKnown(name string) bool {
+	_, ok := Lookup(Flag(name))
+	return ok
+}
+
+// Checker answers whether a flag is on.
+//
+// Purpose: The only dependency services take on the flag system.
+// Domain: Platform
+// Invariants: Enabled never fails; lookup errors resolve to the deployment value.
+type Checker interface {
+	// Enabled reports whether f is on for tenantID ("" for deployment-wide decisions).
+	Enabled(ctx context.Context, tenantID string, f Flag) bool
+}
+
+// Static is a Checker backed only by deployment values. Flags it does not
+// contain use their defaults, so a nil Static yields the built-in defaults.
+type Static map[Flag]bool
+
+// Enabled reports the deployment value of f, or its default.
+func (s Static) Enabled(_ context.Context, _ string, f Flag) bool {
+	if v, ok := s[f]; ok {
+		return v
+	}
+	d, _ := Lookup(f)
+	return d.Default
+}
+
+// Defaults is a Checker that reports the built-in default of every flag.
+var Defaults Checker = Static(nil)
+
+// Sources of a flag's effective value
+const (
+	SourceDefault    = "default"
+	SourceDeployment = "deployment"
+	SourceTenant     = "tenant"
+)
+
+// Override is a tenant's explicit value for a flag.
+//
+// Purpose: Persisted per-tenant capability setting.
+// Domain: Tenant
+// Invariants: Flag is TenantScoped. At most one override per (TenantID, Flag).
+type Override struct {
+	TenantID  string
+	Flag      Flag
+	Enabled   bool
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+// State is a flag's effective value and where it came from.
+type State struct {
+	Flag    Flag   `json:"flag"`
+	Enabled bool   `json:"enabled"`
+	Source  string `json:"source"`
+}
+
+// Repository defines persistence for tenant overrides.
+//
+// Purpose: Storage abstraction for per-tenant flags.
+// Domain: Tenant
+type Repository interface {
+	// ListOverrides returns every override of a tenant
+	ListOverrides(ctx context.Context, tenantID string) ([]*Override, error)
+	// GetOverride returns a tenant's override of one flag
+	GetOverride(ctx context.Context, tenantID string, f Flag) (*Override, error)
+	// SetOverride creates or replaces an override
+	SetOverride(ctx context.Context, o *Override) error
+	// DeleteOverride removes an override
+	DeleteOverride(ctx context.Context, tenantID string, f Flag) error
+}
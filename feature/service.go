@@ -0,0 +1,167 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+// Service resolves flags from defaults, deployment values, and tenant overrides.
+//
+// Purpose: Database-backed Checker with tenant override management.
+// Domain: Platform
+// Invariants: Tenant overrides apply only to TenantScoped flags. Deployment values are immutable at runtime.
+type Service struct {
+	repo        Repository
+	deployment  Static
+	auditLogger audit.Logger
+}
+
+// NewService creates a new feature flag service.
+//
+// Purpose: Constructor for the feature flag service.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func NewService(repo Repository, deployment Static, auditLogger audit.Logger) *Service {
+	return &Service{
+		repo:        repo,
+		deployment:  deployment,
+		auditLogger: auditLogger,
+	}
+}
+
+// Enabled reports whether f is on for tenantID.
+func (s *Service) Enabled(ctx context.Context, tenantID string, f Flag) bool {
+	return s.state(ctx, tenantID, f).Enabled
+}
+
+func (s *Service) state(ctx context.Context, tenantID string, f Flag) State {
+	d, _ := Lookup(f)
+	st := State{Flag: f, Enabled: d.Default, Source: SourceDefault}
+	if v, ok := s.deployment[f]; ok {
+		st.Enabled, st.Source = v, SourceDeployment
+	}
+	if tenantID == "" || !d.TenantScoped {
+		return st
+	}
+
+	o, err := s.repo.GetOverride(ctx, tenantID, f)
+	if err != nil {
+		if !errors.Is(err, ErrOverrideNotFound) {
+			slog.WarnContext(ctx, "failed to load feature flag override; using deployment value",
+				"flag", string(f), "tenant_id", tenantID, "error", err)
+		}
+		return st
+	}
+	st.Enabled, st.Source = o.Enabled, SourceTenant
+	return st
+}
+
+// States returns the effective value of every flag for tenantID.
+func (s *Service) States(ctx context.Context, tenantID string) []State {
+	states := make([]State, 0, len(definitions))
+	for _, d := range definitions {
+		states = append(states, s.state(ctx, tenantID, d.Flag))
+	}
+	return states
+}
+
+// SetTenantFlag overrides a flag for one tenant.
+//
+// Purpose: Tenant-level capability configuration.
+// Domain: Tenant
+// Security: Only TenantScoped flags can be overridden; deployment-only flags stay under operator control.
+// Audited: Yes (FeatureFlagUpdated)
+// Errors: ErrUnknownFlag, ErrNotTenantScoped, System errors
+func (s *Service) SetTenantFlag(ctx context.Context, tenantID string, f Flag, enabled bool, actorID string) error {
+	if err := checkTenantScoped(f); err != nil {
+		return err
+	}
+
+	o := &Override{TenantID: tenantID, Flag: f, Enabled: enabled, UpdatedBy: actorID, UpdatedAt: time.Now()}
+	if err := s.repo.SetOverride(ctx, o); err != nil {
+		return fmt.Errorf("failed to set feature flag override: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeFeatureFlagUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceFeatureFlag,
+		TargetName: string(f),
+		Metadata:   map[string]any{"enabled": enabled},
+	})
+	return nil
+}
+
+// ClearTenantFlag removes a tenant's override so the deployment value applies again.
+//
+// Purpose: Tenant-level capability configuration.
+// Domain: Tenant
+// Audited: Yes (FeatureFlagUpdated)
+// Errors: ErrUnknownFlag, ErrNotTenantScoped, ErrOverrideNotFound, System errors
+func (s *Service) ClearTenantFlag(ctx context.Context, tenantID string, f Flag, actorID string) error {
+	if err := checkTenantScoped(f); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteOverride(ctx, tenantID, f); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeFeatureFlagUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceFeatureFlag,
+		TargetName: string(f),
+		Metadata:   map[string]any{"cleared": true},
+	})
+	return nil
+}
+
+// Discovery returns the authorization server metadata parameters that
+// advertise flags, with their effective values for tenantID.
+func (s *Service) Discovery(ctx context.Context, tenantID string) map[string]any {
+	return Discovery(ctx, s, tenantID)
+}
+
+// Discovery returns the metadata parameters advertised by c for tenantID.
+func Discovery(ctx context.Context, c Checker, tenantID string) map[string]any {
+	metadata := make(map[string]any)
+	for _, d := range definitions {
+		if d.Metadata != "" {
+			metadata[d.Metadata] = c.Enabled(ctx, tenantID, d.Flag)
+		}
+	}
+	return metadata
+}
+
+func checkTenantScoped(f Flag) error {
+	d, ok := Lookup(f)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, f)
+	}
+	if !d.TenantScoped {
+		return fmt.Errorf("%w: %s", ErrNotTenantScoped, f)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feature
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+type mockRepo struct {
+	overrides map[string]*Override
+	err       error
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{overrides: make(map[string]*Override)}
+}
+
+func key(tenantID string, f Flag) string { return tenantID + "/" + string(f) }
+
+func (m *mockRepo) ListOverrides(ctx context.Context, tenantID string) ([]*Override, error) {
+	var res []*Override
+	for _, o := range m.overrides {
+		if o.TenantID == tenantID {
+			res = append(res, o)
+		}
+	}
+	return res, m.err
+}
+
+func (m *mockRepo) GetOverride(ctx context.Context, tenantID string, f Flag) (*Override, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	o, ok := m.overrides[key(tenantID, f)]
+	if !ok {
+		return nil, ErrOverrideNotFound
+	}
+	return o, nil
+}
+
+func (m *mockRepo) SetOverride(ctx context.Context, o *Override) error {
+	m.overrides[key(o.TenantID, o.Flag)] = o
+	return nil
+}
+
+func (m *mockRepo) DeleteOverride(ctx context.Context, tenantID string, f Flag) error {
+	if _, ok := m.overrides[key(tenantID, f)]; !ok {
+		return ErrOverrideNotFound
+	}
+	delete(m.overrides, key(tenantID, f))
+	return nil
+}
+
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Log(_ context.Context, e audit.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestService_Precedence(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	svc := NewService(repo, Static{PARRequired: true}, &recordingAuditLogger{})
+
+	if err := svc.SetTenantFlag(ctx, "t1", PARRequired, false, "admin"); err != nil {
+		t.Fatalf("SetTenantFlag: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		tenantID   string
+		flag       Flag
+		wantOn     bool
+		wantSource string
+	}{
+		{"default", "t1", DPoPRequired, false, SourceDefault},
+		{"default on", "t1", LegacyHashLogin, true, SourceDefault},
+		{"deployment", "t2", PARRequired, true, SourceDeployment},
+		{"deployment without tenant", "", PARRequired, true, SourceDeployment},
+		{"tenant override", "t1", PARRequired, false, SourceTenant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := svc.state(ctx, tt.tenantID, tt.flag)
+			if st.Enabled != tt.wantOn || st.Source != tt.wantSource {
+				t.Errorf("state = (%v, %s), want (%v, %s)", st.Enabled, st.Source, tt.wantOn, tt.wantSource)
+			}
+			if got := svc.Enabled(ctx, tt.tenantID, tt.flag); got != tt.wantOn {
+				t.Errorf("Enabled = %v, want %v", got, tt.wantOn)
+			}
+		})
+	}
+
+	if err := svc.ClearTenantFlag(ctx, "t1", PARRequired, "admin"); err != nil {
+		t.Fatalf("ClearTenantFlag: %v", err)
+	}
+	if !svc.Enabled(ctx, "t1", PARRequired) {
+		t.Error("cleared override should fall back to the deployment value")
+	}
+}
+
+func TestService_SetTenantFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    Flag
+		wantErr error
+	}{
+		{"tenant scoped", ImplicitFlowAllowed, nil},
+		{"deployment only", LegacyHashLogin, ErrNotTenantScoped},
+		{"unknown", Flag("nope"), ErrUnknownFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingAuditLogger{}
+			svc := NewService(newMockRepo(), nil, logger)
+
+			err := svc.SetTenantFlag(context.Background(), "t1", tt.flag, true, "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			wantEvents := 0
+			if tt.wantErr == nil {
+				wantEvents = 1
+			}
+			if len(logger.events) != wantEvents {
+				t.Fatalf("audit events = %d, want %d", len(logger.events), wantEvents)
+			}
+			if wantEvents == 1 && logger.events[0].Type != audit.TypeFeatureFlagUpdated {
+				t.Errorf("audit type = %q", logger.events[0].Type)
+			}
+		})
+	}
+}
+
+func TestService_RepositoryErrorFallsBack(t *testing.T) {
+	repo := newMockRepo()
+	repo.err = errors.New("db down")
+	svc := NewService(repo, Static{DPoPRequired: true}, &recordingAuditLogger{})
+
+	if !svc.Enabled(context.Background(), "t1", DPoPRequired) {
+		t.Error("repository failure should fall back to the deployment value")
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	svc := NewService(newMockRepo(), Static{PARRequired: true}, &recordingAuditLogger{})
+
+	md := svc.Discovery(context.Background(), "t1")
+	if len(md) != 1 {
+		t.Fatalf("metadata = %v, want one parameter", md)
+	}
+	if md["require_pushed_authorization_requests"] != true {
+		t.Errorf("require_pushed_authorization_requests = %v", md["require_pushed_authorization_requests"])
+	}
+
+	if Discovery(context.Background(), Defaults, "")["require_pushed_authorization_requests"] != false {
+		t.Error("Defaults should not require PAR")
+	}
+}
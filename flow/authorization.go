@@ -0,0 +1,188 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Pending authorization errors
+var (
+	ErrAuthorizationNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "pending authorization not found")
+	ErrAuthorizationExpired  = apperror.New(apperror.CodeSessionExpired, apperror.StatusUnauthorized, apperror.OAuth2LoginRequired, "pending authorization expired")
+	ErrAuthorizationInvalid  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidRequest, "authorization parameters cannot be kept")
+)
+
+// DataAuthorization is the Flow.Data key holding the handle of the pending
+// authorization a flow was started for.
+const DataAuthorization = "authorization"
+
+// Pending authorization limits
+const (
+	// MaxAuthorizationParams bounds the number of parameters kept per request
+	MaxAuthorizationParams = 32
+	// MaxAuthorizationSize bounds the total size of the keys and values kept per request
+	MaxAuthorizationSize = 16 << 10
+)
+
+// secretParams are never persisted: they authenticate the client at the token
+// endpoint and have no business in a front-channel request.
+var secretParams = []string{"client_secret", "client_assertion", "password"}
+
+// PendingAuthorization is an authorization request parked while the user logs in.
+//
+// Purpose: Keeps the original /authorize parameters (redirect_uri, scope, state,
+// nonce, PKCE challenge, ...) server-side across the login and MFA steps, so the
+// browser only carries an opaque handle.
+// Domain: Session
+// Invariants: Handle is 256 bits from a CSPRNG. The request is consumed at most once
+// and expires with the flow it belongs to. Params never contain client credentials.
+type PendingAuthorization struct {
+	Handle    string            `json:"handle"`
+	TenantID  string            `json:"tenant_id"`
+	ClientID  string            `json:"client_id"`
+	Params    map[string]string `json:"params"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// IsExpired reports whether the request can no longer be resumed at now.
+func (a *PendingAuthorization) IsExpired(now time.Time) bool {
+	return !now.Before(a.ExpiresAt)
+}
+
+// validateParams rejects parameters that are too large or carry secrets.
+func validateParams(params map[string]string) error {
+	if len(params) > MaxAuthorizationParams {
+		return ErrAuthorizationInvalid
+	}
+	size := 0
+	for k, v := range params {
+		size += len(k) + len(v)
+	}
+	if size > MaxAuthorizationSize {
+		return ErrAuthorizationInvalid
+	}
+	for _, k := range secretParams {
+		if _, ok := params[k]; ok {
+			return ErrAuthorizationInvalid
+		}
+	}
+	return nil
+}
+
+// StartAuthorization parks an authorization request and begins the login flow
+// that must finish before it is answered.
+//
+// Purpose: Entry point for /authorize when the user has no session, must enroll
+// MFA, or must step up; the flow carries the request handle in Data[DataAuthorization].
+// Domain: Session
+// Security: The request and the flow share one lifetime, so a parked request never
+// outlives the login it waits for. Client credentials are refused.
+// Audited: No
+// Errors: ErrAuthorizationInvalid, System errors
+func (s *Service) StartAuthorization(ctx context.Context, tenantID, clientID string, req Requirements, params map[string]string) (*Flow, *PendingAuthorization, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "flow.StartAuthorization", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	if err := validateParams(params); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	a := &PendingAuthorization{
+		Handle:    generateFlowID(),
+		TenantID:  tenantID,
+		ClientID:  clientID,
+		Params:    maps.Clone(params),
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateAuthorization(ctx, a); err != nil {
+		return nil, nil, fmt.Errorf("failed to create pending authorization: %w", err)
+	}
+
+	f, err := s.Start(ctx, tenantID, clientID, req, map[string]string{DataAuthorization: a.Handle})
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, a, nil
+}
+
+// GetAuthorization returns a pending authorization without consuming it.
+//
+// Purpose: Lets the login pages show which client and scopes the user is signing in for.
+// Domain: Session
+// Audited: No
+// Errors: ErrAuthorizationNotFound, ErrAuthorizationExpired
+func (s *Service) GetAuthorization(ctx context.Context, tenantID, handle string) (*PendingAuthorization, error) {
+	a, err := s.repo.GetAuthorization(ctx, tenantID, handle)
+	if err != nil {
+		return nil, err
+	}
+	if a.IsExpired(time.Now()) {
+		return nil, ErrAuthorizationExpired
+	}
+	return a, nil
+}
+
+// ResumeAuthorization consumes the request a completed flow was started for.
+//
+// Purpose: Hands the original /authorize parameters back to the transport once the
+// user has logged in, so it can issue the code or ask for consent.
+// Domain: Session
+// Security: Only a completed flow releases its request, and only once; a replayed
+// resume gets ErrAuthorizationNotFound. The request must belong to the flow's client.
+// Audited: No
+// Errors: ErrFlowNotFound, ErrFlowFinished, ErrInvalidTransition, ErrAuthorizationNotFound,
+// ErrAuthorizationExpired
+func (s *Service) ResumeAuthorization(ctx context.Context, tenantID, flowID string) (*PendingAuthorization, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "flow.ResumeAuthorization", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	f, err := s.repo.Get(ctx, tenantID, flowID)
+	if err != nil {
+		return nil, err
+	}
+	switch f.State {
+	case StateCompleted:
+	case StateFailed:
+		return nil, ErrFlowFinished
+	default:
+		return nil, ErrInvalidTransition
+	}
+
+	handle := f.Data[DataAuthorization]
+	if handle == "" {
+		return nil, ErrAuthorizationNotFound
+	}
+	a, err := s.repo.ConsumeAuthorization(ctx, tenantID, handle)
+	if err != nil {
+		return nil, err
+	}
+	if a.ClientID != f.ClientID {
+		return nil, ErrAuthorizationNotFound
+	}
+	if a.IsExpired(time.Now()) {
+		return nil, ErrAuthorizationExpired
+	}
+	return a, nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package flow models a multi-step login (password, forced password change, MFA
+// or MFA enrollment,
+// consent, step-up) as a persisted state machine, so a transport can resume an
+// interrupted login at the step it stopped at instead of inferring progress from
+// cookies and sessions. The authorization request that triggered a login is
+// parked alongside the flow and handed back once the flow completes.
+package flow
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrFlowNotFound      = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "login flow not found")
+	ErrFlowExpired       = apperror.New(apperror.CodeSessionExpired, apperror.StatusUnauthorized, apperror.OAuth2LoginRequired, "login flow expired")
+	ErrFlowFinished      = apperror.New(apperror.CodeInvalidRequest, apperror.StatusConflict, "", "login flow already finished")
+	ErrInvalidTransition = apperror.New(apperror.CodeInvalidRequest, apperror.StatusConflict, "", "transition not allowed at the current login step")
+	ErrSubjectMismatch   = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2AccessDenied, "login flow belongs to another user")
+	ErrConcurrentUpdate  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusConflict, "", "login flow was modified concurrently")
+)
+
+// State is a step of a login flow.
+type State string
+
+// States
+const (
+	StatePassword       State = "password"
+	StatePasswordChange State = "password_change"
+	StateMFA            State = "mfa"
+	StateMFAEnrollment  State = "mfa_enrollment"
+	StateConsent        State = "consent"
+	StateStepUp         State = "step_up"
+	StateCompleted      State = "completed"
+	StateFailed         State = "failed"
+)
+
+// IsTerminal reports whether no transition leaves s.
+func (s State) IsTerminal() bool {
+	return s == StateCompleted || s == StateFailed
+}
+
+// Transition is an event that completes the current step.
+type Transition string
+
+// Transitions
+const (
+	PasswordVerified Transition = "password_verified"
+	PasswordChanged  Transition = "password_changed"
+	MFAVerified      Transition = "mfa_verified"
+	MFAEnrolled      Transition = "mfa_enrolled"
+	ConsentGranted   Transition = "consent_granted"
+	StepUpVerified   Transition = "step_up_verified"
+)
+
+// completes maps each step to the only transition that completes it.
+var completes = map[State]Transition{
+	StatePassword:       PasswordVerified,
+	StatePasswordChange: PasswordChanged,
+	StateMFA:            MFAVerified,
+	StateMFAEnrollment:  MFAEnrolled,
+	StateConsent:        ConsentGranted,
+	StateStepUp:         StepUpVerified,
+}
+
+// Authentication method references (RFC 8176) recorded as steps complete
+const (
+	AMRPassword = "pwd"
+	AMRMFA      = "mfa"
+)
+
+// Requirements selects the optional steps of a flow.
+//
+// Purpose: Decided by the transport from tenant policy, client, and request when the flow starts.
+// Domain: Session
+type Requirements struct {
+	MFA     bool
+	Consent bool
+	StepUp  bool
+}
+
+// steps returns the ordered steps a flow with r must pass.
+func (r Requirements) steps() []State {
+	steps := []State{StatePassword}
+	if r.MFA {
+		steps = append(steps, StateMFA)
+	}
+	if r.Consent {
+		steps = append(steps, StateConsent)
+	}
+	if r.StepUp {
+		steps = append(steps, StateStepUp)
+	}
+	return steps
+}
+
+// Flow is one login attempt in progress.
+//
+// Purpose: Persisted login progress that survives redirects, reloads, and instance changes.
+// Domain: Session
+// Invariants: State is one of Steps, or terminal. Steps are passed in order and only by
+// their own Transition. UserID is set by PasswordVerified and never changes afterwards.
+// A flow is unusable once ExpiresAt or StepExpiresAt has passed. Version increases with
+// every update. Data carries transport state (such as a pending authorization handle),
+// never secrets.
+type Flow struct {
+	ID            string            `json:"id"`
+	TenantID      string            `json:"tenant_id"`
+	ClientID      string            `json:"client_id,omitempty"`
+	UserID        string            `json:"user_id,omitempty"`
+	State         State             `json:"state"`
+	Steps         []State           `json:"steps"`
+	AMR           []string          `json:"amr,omitempty"`
+	Data          map[string]string `json:"data,omitempty"`
+	FailureReason string            `json:"failure_reason,omitempty"`
+	Version       int               `json:"version"`
+	StepExpiresAt time.Time         `json:"step_expires_at"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// IsExpired reports whether the flow or its current step timed out at now.
+func (f *Flow) IsExpired(now time.Time) bool {
+	return !f.State.IsTerminal() && (!now.Before(f.ExpiresAt) || !now.Before(f.StepExpiresAt))
+}
+
+// Next returns the state t leads to from the current state.
+//
+// Purpose: The transition function of the state machine, free of side effects.
+// Domain: Session
+// Audited: No
+// Errors: ErrFlowFinished, ErrInvalidTransition
+func (f *Flow) Next(t Transition) (State, error) {
+	if f.State.IsTerminal() {
+		return "", ErrFlowFinished
+	}
+	if completes[f.State] != t {
+		return "", ErrInvalidTransition
+	}
+	i := slices.Index(f.Steps, f.State)
+	if i < 0 {
+		return "", ErrInvalidTransition
+	}
+	if i == len(f.Steps)-1 {
+		return StateCompleted, nil
+	}
+	return f.Steps[i+1], nil
+}
+
+// Repository defines persistence for login flows.
+//
+// Purpose: Shared storage so a flow can resume on any instance.
+// Domain: Session
+type Repository interface {
+	// Create persists a new flow
+	Create(ctx context.Context, f *Flow) error
+	// Get returns a flow of a tenant
+	Get(ctx context.Context, tenantID, id string) (*Flow, error)
+	// Update saves f if its stored Version still equals f.Version, then increments f.Version
+	Update(ctx context.Context, f *Flow) error
+	// DeleteExpired removes flows and pending authorizations whose ExpiresAt is before now
+	DeleteExpired(ctx context.Context, now time.Time) error
+
+	// CreateAuthorization persists a pending authorization
+	CreateAuthorization(ctx context.Context, a *PendingAuthorization) error
+	// GetAuthorization returns a pending authorization of a tenant
+	GetAuthorization(ctx context.Context, tenantID, handle string) (*PendingAuthorization, error)
+	// ConsumeAuthorization deletes and returns a pending authorization, so it is handed out once
+	ConsumeAuthorization(ctx context.Context, tenantID, handle string) (*PendingAuthorization, error)
+}
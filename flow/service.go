@@ -0,0 +1,295 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Flow timeouts
+const (
+	// DefaultTTL bounds a whole login flow
+	DefaultTTL = 15 * time.Minute
+	// DefaultStepTimeout bounds each step of a login flow
+	DefaultStepTimeout = 5 * time.Minute
+)
+
+// Service drives login flows through their steps.
+//
+// Purpose: Single place that decides what a login may do next.
+// Domain: Session
+// Invariants: Every change goes through Next and is saved with optimistic concurrency,
+// so two tabs or instances racing on one flow cannot both advance it.
+type Service struct {
+	repo        Repository
+	ttl         time.Duration
+	stepTimeout time.Duration
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service settings.
+type Option func(*Service)
+
+// WithTTL bounds each flow to d instead of DefaultTTL.
+func WithTTL(d time.Duration) Option {
+	return func(s *Service) { s.ttl = d }
+}
+
+// WithStepTimeout bounds each step to d instead of DefaultStepTimeout.
+func WithStepTimeout(d time.Duration) Option {
+	return func(s *Service) { s.stepTimeout = d }
+}
+
+// WithTracer emits spans for flow transitions on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new login flow service.
+//
+// Purpose: Constructor for the login flow service.
+// Domain: Session
+// Audited: No
+// Errors: None
+func NewService(repo Repository, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		ttl:         DefaultTTL,
+		stepTimeout: DefaultStepTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start begins a login flow at the password step.
+//
+// Purpose: Creates the resumable handle a transport keeps for the browser.
+// Domain: Session
+// Security: The flow ID is 256 bits from a CSPRNG.
+// Audited: No
+// Errors: System errors
+func (s *Service) Start(ctx context.Context, tenantID, clientID string, req Requirements, data map[string]string) (*Flow, error) {
+	now := time.Now()
+	f := &Flow{
+		ID:            generateFlowID(),
+		TenantID:      tenantID,
+		ClientID:      clientID,
+		State:         StatePassword,
+		Steps:         req.steps(),
+		Data:          maps.Clone(data),
+		StepExpiresAt: now.Add(min(s.stepTimeout, s.ttl)),
+		ExpiresAt:     now.Add(s.ttl),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.repo.Create(ctx, f); err != nil {
+		return nil, fmt.Errorf("failed to create login flow: %w", err)
+	}
+	return f, nil
+}
+
+// Get returns a flow that has not timed out.
+//
+// Purpose: Resumes a login at its current step.
+// Domain: Session
+// Audited: No
+// Errors: ErrFlowNotFound, ErrFlowExpired
+func (s *Service) Get(ctx context.Context, tenantID, flowID string) (*Flow, error) {
+	f, err := s.repo.Get(ctx, tenantID, flowID)
+	if err != nil {
+		return nil, err
+	}
+	if f.IsExpired(time.Now()) {
+		return nil, ErrFlowExpired
+	}
+	return f, nil
+}
+
+// Advance completes the current step with t on behalf of userID.
+//
+// Purpose: Moves the flow to its next step, or to StateCompleted after the last one.
+// Domain: Session
+// Security: The transport calls Advance only after verifying the step's credential.
+// PasswordVerified binds the flow to userID; every later step must be for the same user.
+// Audited: No
+// Errors: ErrFlowNotFound, ErrFlowExpired, ErrFlowFinished, ErrInvalidTransition,
+// ErrSubjectMismatch, ErrConcurrentUpdate
+func (s *Service) Advance(ctx context.Context, tenantID, flowID string, t Transition, userID string) (*Flow, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "flow.Advance", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	f, err := s.Get(ctx, tenantID, flowID)
+	if err != nil {
+		return nil, err
+	}
+	next, err := f.Next(t)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case userID == "":
+		return nil, ErrSubjectMismatch
+	case t == PasswordVerified:
+		f.UserID = userID
+		f.AMR = append(f.AMR, AMRPassword)
+	case f.UserID != userID:
+		return nil, ErrSubjectMismatch
+	case t == MFAVerified, t == MFAEnrolled:
+		f.AMR = append(f.AMR, AMRMFA)
+	}
+
+	now := time.Now()
+	f.State = next
+	f.StepExpiresAt = now.Add(s.stepTimeout)
+	if f.StepExpiresAt.After(f.ExpiresAt) {
+		f.StepExpiresAt = f.ExpiresAt
+	}
+	f.UpdatedAt = now
+	if err := s.repo.Update(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// RequirePasswordChange inserts a forced password change right after the password
+// step. Call it when the password was accepted but has expired, before advancing
+// with PasswordVerified; the flow then cannot complete until PasswordChanged.
+//
+// Purpose: Blocks session issuance until an expired password is rotated.
+// Domain: Session
+// Security: Only allowed at the password step, so a user cannot skip a change the
+// transport already decided on by racing the flow forward.
+// Audited: No
+// Errors: ErrFlowNotFound, ErrFlowExpired, ErrFlowFinished, ErrInvalidTransition,
+// ErrConcurrentUpdate
+func (s *Service) RequirePasswordChange(ctx context.Context, tenantID, flowID string) (*Flow, error) {
+	f, err := s.Get(ctx, tenantID, flowID)
+	if err != nil {
+		return nil, err
+	}
+	if f.State.IsTerminal() {
+		return nil, ErrFlowFinished
+	}
+	if f.State != StatePassword {
+		return nil, ErrInvalidTransition
+	}
+	if slices.Contains(f.Steps, StatePasswordChange) {
+		return f, nil
+	}
+
+	f.Steps = slices.Insert(f.Steps, slices.Index(f.Steps, StatePassword)+1, StatePasswordChange)
+	f.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// RequireMFA inserts an MFA step after the password (and any forced password
+// change) step; with enroll the user must first register a factor, which also
+// verifies it. Call it when tenant policy demands MFA for the user, before
+// advancing past the password step.
+//
+// Purpose: Enforces tenant MFA policy decided after the user is identified.
+// Domain: Session
+// Security: Only allowed at the password step, like RequirePasswordChange. An
+// enrollment step replaces a plain MFA step, never the other way round.
+// Audited: No
+// Errors: ErrFlowNotFound, ErrFlowExpired, ErrFlowFinished, ErrInvalidTransition,
+// ErrConcurrentUpdate
+func (s *Service) RequireMFA(ctx context.Context, tenantID, flowID string, enroll bool) (*Flow, error) {
+	f, err := s.Get(ctx, tenantID, flowID)
+	if err != nil {
+		return nil, err
+	}
+	if f.State.IsTerminal() {
+		return nil, ErrFlowFinished
+	}
+	if f.State != StatePassword {
+		return nil, ErrInvalidTransition
+	}
+
+	switch i := slices.Index(f.Steps, StateMFA); {
+	case slices.Contains(f.Steps, StateMFAEnrollment):
+		return f, nil
+	case i >= 0 && !enroll:
+		return f, nil
+	case i >= 0:
+		f.Steps[i] = StateMFAEnrollment
+	default:
+		step := StateMFA
+		if enroll {
+			step = StateMFAEnrollment
+		}
+		at := slices.Index(f.Steps, StatePassword) + 1
+		if slices.Contains(f.Steps, StatePasswordChange) {
+			at = slices.Index(f.Steps, StatePasswordChange) + 1
+		}
+		f.Steps = slices.Insert(f.Steps, at, step)
+	}
+
+	f.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Fail ends the flow without completing it.
+//
+// Purpose: Records why a login stopped (consent denied, too many MFA failures, user cancelled).
+// Domain: Session
+// Audited: No
+// Errors: ErrFlowNotFound, ErrFlowExpired, ErrFlowFinished, ErrConcurrentUpdate
+func (s *Service) Fail(ctx context.Context, tenantID, flowID, reason string) (*Flow, error) {
+	f, err := s.Get(ctx, tenantID, flowID)
+	if err != nil {
+		return nil, err
+	}
+	if f.State.IsTerminal() {
+		return nil, ErrFlowFinished
+	}
+
+	f.State = StateFailed
+	f.FailureReason = reason
+	f.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// CleanupExpired removes flows and pending authorizations past their lifetime
+func (s *Service) CleanupExpired(ctx context.Context) error {
+	return s.repo.DeleteExpired(ctx, time.Now())
+}
+
+// generateFlowID generates a cryptographically secure flow ID
+func generateFlowID() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,388 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockRepo struct {
+	flows map[string]Flow
+	auths map[string]PendingAuthorization
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{flows: make(map[string]Flow), auths: make(map[string]PendingAuthorization)}
+}
+
+func (m *mockRepo) Create(ctx context.Context, f *Flow) error {
+	m.flows[f.ID] = *f
+	return nil
+}
+
+func (m *mockRepo) Get(ctx context.Context, tenantID, id string) (*Flow, error) {
+	f, ok := m.flows[id]
+	if !ok || f.TenantID != tenantID {
+		return nil, ErrFlowNotFound
+	}
+	return &f, nil
+}
+
+func (m *mockRepo) Update(ctx context.Context, f *Flow) error {
+	stored, ok := m.flows[f.ID]
+	if !ok || stored.Version != f.Version {
+		return ErrConcurrentUpdate
+	}
+	f.Version++
+	m.flows[f.ID] = *f
+	return nil
+}
+
+func (m *mockRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	for id, f := range m.flows {
+		if f.ExpiresAt.Before(now) {
+			delete(m.flows, id)
+		}
+	}
+	for h, a := range m.auths {
+		if a.ExpiresAt.Before(now) {
+			delete(m.auths, h)
+		}
+	}
+	return nil
+}
+
+func (m *mockRepo) CreateAuthorization(ctx context.Context, a *PendingAuthorization) error {
+	m.auths[a.Handle] = *a
+	return nil
+}
+
+func (m *mockRepo) GetAuthorization(ctx context.Context, tenantID, handle string) (*PendingAuthorization, error) {
+	a, ok := m.auths[handle]
+	if !ok || a.TenantID != tenantID {
+		return nil, ErrAuthorizationNotFound
+	}
+	return &a, nil
+}
+
+func (m *mockRepo) ConsumeAuthorization(ctx context.Context, tenantID, handle string) (*PendingAuthorization, error) {
+	a, err := m.GetAuthorization(ctx, tenantID, handle)
+	if err != nil {
+		return nil, err
+	}
+	delete(m.auths, handle)
+	return a, nil
+}
+
+func TestFlowNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Requirements
+		state   State
+		t       Transition
+		want    State
+		wantErr error
+	}{
+		{"password only", Requirements{}, StatePassword, PasswordVerified, StateCompleted, nil},
+		{"password then mfa", Requirements{MFA: true}, StatePassword, PasswordVerified, StateMFA, nil},
+		{"password skips to consent", Requirements{Consent: true}, StatePassword, PasswordVerified, StateConsent, nil},
+		{"mfa then step-up", Requirements{MFA: true, StepUp: true}, StateMFA, MFAVerified, StateStepUp, nil},
+		{"step-up completes", Requirements{MFA: true, Consent: true, StepUp: true}, StateStepUp, StepUpVerified, StateCompleted, nil},
+		{"mfa before password", Requirements{MFA: true}, StatePassword, MFAVerified, "", ErrInvalidTransition},
+		{"consent at mfa", Requirements{MFA: true, Consent: true}, StateMFA, ConsentGranted, "", ErrInvalidTransition},
+		{"completed", Requirements{}, StateCompleted, PasswordVerified, "", ErrFlowFinished},
+		{"failed", Requirements{}, StateFailed, PasswordVerified, "", ErrFlowFinished},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Flow{State: tt.state, Steps: tt.req.steps()}
+			got, err := f.Next(tt.t)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Next() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Next() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("full login", func(t *testing.T) {
+		svc := NewService(newMockRepo())
+		f, err := svc.Start(ctx, "t1", "c1", Requirements{MFA: true, Consent: true}, map[string]string{"authorize": "h1"})
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		for _, tr := range []Transition{PasswordVerified, MFAVerified, ConsentGranted} {
+			if f, err = svc.Advance(ctx, "t1", f.ID, tr, "u1"); err != nil {
+				t.Fatalf("Advance(%s) error = %v", tr, err)
+			}
+		}
+		if f.State != StateCompleted || f.UserID != "u1" || f.Data["authorize"] != "h1" {
+			t.Errorf("flow = %+v", f)
+		}
+		if !slices.Equal(f.AMR, []string{AMRPassword, AMRMFA}) {
+			t.Errorf("AMR = %v", f.AMR)
+		}
+		if _, err := svc.Advance(ctx, "t1", f.ID, ConsentGranted, "u1"); !errors.Is(err, ErrFlowFinished) {
+			t.Errorf("Advance() after completion error = %v, want ErrFlowFinished", err)
+		}
+	})
+
+	t.Run("forced password change", func(t *testing.T) {
+		svc := NewService(newMockRepo())
+		f, _ := svc.Start(ctx, "t1", "c1", Requirements{MFA: true}, nil)
+		f, err := svc.RequirePasswordChange(ctx, "t1", f.ID)
+		if err != nil {
+			t.Fatalf("RequirePasswordChange() error = %v", err)
+		}
+		if !slices.Equal(f.Steps, []State{StatePassword, StatePasswordChange, StateMFA}) {
+			t.Fatalf("Steps = %v", f.Steps)
+		}
+		if f, err = svc.Advance(ctx, "t1", f.ID, PasswordVerified, "u1"); err != nil || f.State != StatePasswordChange {
+			t.Fatalf("Advance(PasswordVerified) = %+v, %v", f, err)
+		}
+		if _, err := svc.Advance(ctx, "t1", f.ID, MFAVerified, "u1"); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("skipping the change error = %v, want ErrInvalidTransition", err)
+		}
+		if _, err := svc.RequirePasswordChange(ctx, "t1", f.ID); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("RequirePasswordChange() after password step error = %v, want ErrInvalidTransition", err)
+		}
+		if f, err = svc.Advance(ctx, "t1", f.ID, PasswordChanged, "u1"); err != nil || f.State != StateMFA {
+			t.Errorf("Advance(PasswordChanged) = %+v, %v", f, err)
+		}
+	})
+
+	t.Run("tenant MFA policy", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			req    Requirements
+			change bool
+			enroll bool
+			want   []State
+		}{
+			{"adds mfa", Requirements{Consent: true}, false, false, []State{StatePassword, StateMFA, StateConsent}},
+			{"after password change", Requirements{}, true, false, []State{StatePassword, StatePasswordChange, StateMFA}},
+			{"already required", Requirements{MFA: true}, false, false, []State{StatePassword, StateMFA}},
+			{"enrollment replaces mfa", Requirements{MFA: true}, false, true, []State{StatePassword, StateMFAEnrollment}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				svc := NewService(newMockRepo())
+				f, _ := svc.Start(ctx, "t1", "c1", tt.req, nil)
+				if tt.change {
+					svc.RequirePasswordChange(ctx, "t1", f.ID)
+				}
+				f, err := svc.RequireMFA(ctx, "t1", f.ID, tt.enroll)
+				if err != nil {
+					t.Fatalf("RequireMFA() error = %v", err)
+				}
+				if !slices.Equal(f.Steps, tt.want) {
+					t.Errorf("Steps = %v, want %v", f.Steps, tt.want)
+				}
+			})
+		}
+
+		svc := NewService(newMockRepo())
+		f, _ := svc.Start(ctx, "t1", "c1", Requirements{}, nil)
+		svc.RequireMFA(ctx, "t1", f.ID, true)
+		f, _ = svc.Advance(ctx, "t1", f.ID, PasswordVerified, "u1")
+		if _, err := svc.RequireMFA(ctx, "t1", f.ID, false); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("RequireMFA() after password step error = %v, want ErrInvalidTransition", err)
+		}
+		f, err := svc.Advance(ctx, "t1", f.ID, MFAEnrolled, "u1")
+		if err != nil || f.State != StateCompleted || !slices.Contains(f.AMR, AMRMFA) {
+			t.Errorf("Advance(MFAEnrolled) = %+v, %v", f, err)
+		}
+	})
+
+	t.Run("other user cannot continue", func(t *testing.T) {
+		svc := NewService(newMockRepo())
+		f, _ := svc.Start(ctx, "t1", "c1", Requirements{MFA: true}, nil)
+		svc.Advance(ctx, "t1", f.ID, PasswordVerified, "u1")
+		if _, err := svc.Advance(ctx, "t1", f.ID, MFAVerified, "u2"); !errors.Is(err, ErrSubjectMismatch) {
+			t.Errorf("Advance() error = %v, want ErrSubjectMismatch", err)
+		}
+	})
+
+	t.Run("other tenant", func(t *testing.T) {
+		svc := NewService(newMockRepo())
+		f, _ := svc.Start(ctx, "t1", "c1", Requirements{}, nil)
+		if _, err := svc.Advance(ctx, "t2", f.ID, PasswordVerified, "u1"); !errors.Is(err, ErrFlowNotFound) {
+			t.Errorf("Advance() error = %v, want ErrFlowNotFound", err)
+		}
+	})
+
+	t.Run("step timeout", func(t *testing.T) {
+		repo := newMockRepo()
+		svc := NewService(repo)
+		f, _ := svc.Start(ctx, "t1", "c1", Requirements{MFA: true}, nil)
+		stored := repo.flows[f.ID]
+		stored.StepExpiresAt = time.Now().Add(-time.Second)
+		repo.flows[f.ID] = stored
+		if _, err := svc.Advance(ctx, "t1", f.ID, PasswordVerified, "u1"); !errors.Is(err, ErrFlowExpired) {
+			t.Errorf("Advance() error = %v, want ErrFlowExpired", err)
+		}
+	})
+
+	t.Run("step timeout never outlives flow", func(t *testing.T) {
+		svc := NewService(newMockRepo(), WithTTL(time.Minute), WithStepTimeout(time.Hour))
+		f, _ := svc.Start(ctx, "t1", "c1", Requirements{MFA: true}, nil)
+		if f.StepExpiresAt.After(f.ExpiresAt) {
+			t.Errorf("StepExpiresAt %v after ExpiresAt %v", f.StepExpiresAt, f.ExpiresAt)
+		}
+		f, _ = svc.Advance(ctx, "t1", f.ID, PasswordVerified, "u1")
+		if f.StepExpiresAt.After(f.ExpiresAt) {
+			t.Errorf("StepExpiresAt %v after ExpiresAt %v", f.StepExpiresAt, f.ExpiresAt)
+		}
+	})
+
+	t.Run("concurrent advance", func(t *testing.T) {
+		repo := newMockRepo()
+		svc := NewService(repo)
+		f, _ := svc.Start(ctx, "t1", "c1", Requirements{MFA: true}, nil)
+		stale, _ := repo.Get(ctx, "t1", f.ID)
+		svc.Advance(ctx, "t1", f.ID, PasswordVerified, "u1")
+		stale.State = StateMFA
+		if err := repo.Update(ctx, stale); !errors.Is(err, ErrConcurrentUpdate) {
+			t.Errorf("Update() with stale version error = %v, want ErrConcurrentUpdate", err)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		svc := NewService(newMockRepo())
+		f, _ := svc.Start(ctx, "t1", "c1", Requirements{Consent: true}, nil)
+		f, err := svc.Fail(ctx, "t1", f.ID, "consent_denied")
+		if err != nil || f.State != StateFailed || f.FailureReason != "consent_denied" {
+			t.Fatalf("Fail() = %+v, %v", f, err)
+		}
+		if _, err := svc.Fail(ctx, "t1", f.ID, "again"); !errors.Is(err, ErrFlowFinished) {
+			t.Errorf("second Fail() error = %v, want ErrFlowFinished", err)
+		}
+	})
+}
+
+func TestPendingAuthorization(t *testing.T) {
+	ctx := context.Background()
+	params := map[string]string{
+		"response_type":  "code",
+		"redirect_uri":   "https://app.example.com/cb",
+		"scope":          "openid profile",
+		"state":          "xyz",
+		"code_challenge": "abc",
+	}
+
+	t.Run("resume after login", func(t *testing.T) {
+		svc := NewService(newMockRepo())
+		f, a, err := svc.StartAuthorization(ctx, "t1", "c1", Requirements{MFA: true}, params)
+		if err != nil {
+			t.Fatalf("StartAuthorization() error = %v", err)
+		}
+		if f.Data[DataAuthorization] != a.Handle || len(a.Handle) != 43 {
+			t.Fatalf("flow data = %v, handle = %q", f.Data, a.Handle)
+		}
+		if a.ExpiresAt.After(f.ExpiresAt) {
+			t.Errorf("authorization outlives flow: %v > %v", a.ExpiresAt, f.ExpiresAt)
+		}
+		if got, err := svc.GetAuthorization(ctx, "t1", a.Handle); err != nil || got.Params["state"] != "xyz" {
+			t.Errorf("GetAuthorization() = %+v, %v", got, err)
+		}
+
+		svc.Advance(ctx, "t1", f.ID, PasswordVerified, "u1")
+		if _, err := svc.ResumeAuthorization(ctx, "t1", f.ID); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("ResumeAuthorization() before completion error = %v, want ErrInvalidTransition", err)
+		}
+		svc.Advance(ctx, "t1", f.ID, MFAVerified, "u1")
+
+		got, err := svc.ResumeAuthorization(ctx, "t1", f.ID)
+		if err != nil {
+			t.Fatalf("ResumeAuthorization() error = %v", err)
+		}
+		if got.ClientID != "c1" || got.Params["redirect_uri"] != params["redirect_uri"] {
+			t.Errorf("ResumeAuthorization() = %+v", got)
+		}
+		if _, err := svc.ResumeAuthorization(ctx, "t1", f.ID); !errors.Is(err, ErrAuthorizationNotFound) {
+			t.Errorf("second ResumeAuthorization() error = %v, want ErrAuthorizationNotFound", err)
+		}
+	})
+
+	t.Run("failed flow", func(t *testing.T) {
+		svc := NewService(newMockRepo())
+		f, _, _ := svc.StartAuthorization(ctx, "t1", "c1", Requirements{}, params)
+		svc.Fail(ctx, "t1", f.ID, "cancelled")
+		if _, err := svc.ResumeAuthorization(ctx, "t1", f.ID); !errors.Is(err, ErrFlowFinished) {
+			t.Errorf("ResumeAuthorization() error = %v, want ErrFlowFinished", err)
+		}
+	})
+
+	t.Run("other tenant", func(t *testing.T) {
+		svc := NewService(newMockRepo())
+		_, a, _ := svc.StartAuthorization(ctx, "t1", "c1", Requirements{}, params)
+		if _, err := svc.GetAuthorization(ctx, "t2", a.Handle); !errors.Is(err, ErrAuthorizationNotFound) {
+			t.Errorf("GetAuthorization() error = %v, want ErrAuthorizationNotFound", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		repo := newMockRepo()
+		svc := NewService(repo)
+		_, a, _ := svc.StartAuthorization(ctx, "t1", "c1", Requirements{}, params)
+		stored := repo.auths[a.Handle]
+		stored.ExpiresAt = time.Now().Add(-time.Second)
+		repo.auths[a.Handle] = stored
+		if _, err := svc.GetAuthorization(ctx, "t1", a.Handle); !errors.Is(err, ErrAuthorizationExpired) {
+			t.Errorf("GetAuthorization() error = %v, want ErrAuthorizationExpired", err)
+		}
+		svc.CleanupExpired(ctx)
+		if _, ok := repo.auths[a.Handle]; ok {
+			t.Error("CleanupExpired() kept an expired authorization")
+		}
+	})
+
+	t.Run("rejected parameters", func(t *testing.T) {
+		large := make(map[string]string)
+		for i := range MaxAuthorizationParams + 1 {
+			large[strings.Repeat("k", i+1)] = "v"
+		}
+		tests := []struct {
+			name   string
+			params map[string]string
+		}{
+			{"client secret", map[string]string{"client_id": "c1", "client_secret": "s"}},
+			{"too many", large},
+			{"too large", map[string]string{"state": strings.Repeat("x", MaxAuthorizationSize+1)}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := newMockRepo()
+				svc := NewService(repo)
+				if _, _, err := svc.StartAuthorization(ctx, "t1", "c1", Requirements{}, tt.params); !errors.Is(err, ErrAuthorizationInvalid) {
+					t.Errorf("StartAuthorization() error = %v, want ErrAuthorizationInvalid", err)
+				}
+				if len(repo.flows) != 0 || len(repo.auths) != 0 {
+					t.Error("rejected request was stored")
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grant lets tenant administrators and users inspect the tokens issued
+// to a user and revoke them individually, per authorization grant, or all at once,
+// and lists the applications a user is connected to.
+package grant
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrTokenNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "token not found")
+	ErrGrantNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "grant not found")
+	ErrNotPermitted  = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to manage this user's tokens")
+)
+
+// Token kinds
+const (
+	KindAccess  = "access"
+	KindRefresh = "refresh"
+)
+
+// Connection kinds besides KindAccess and KindRefresh
+const (
+	KindConsent = "consent"
+	KindSession = "session"
+)
+
+// Token is an active access or refresh token as shown to administrators.
+//
+// Purpose: Read model of an issued token; never carries the token value or its hash.
+// Domain: OAuth2
+// Invariants: Only unrevoked, unexpired tokens are listed. GrantID is empty for tokens
+// issued before grant IDs were recorded.
+type Token struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	TenantID   string    `json:"tenant_id"`
+	UserID     string    `json:"user_id"`
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	GrantID    string    `json:"grant_id,omitempty"`
+	Scopes     []string  `json:"scopes"`
+	IssuedAt   time.Time `json:"issued_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Grant groups the active tokens of one authorization grant.
+//
+// Purpose: What a user approved for a client, as one revocable unit.
+// Domain: OAuth2
+// Invariants: All Tokens share ID and ClientID. A token without a grant ID forms a grant
+// of its own with an empty ID and can only be revoked individually.
+type Grant struct {
+	ID         string    `json:"id,omitempty"`
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Scopes     []string  `json:"scopes"`
+	IssuedAt   time.Time `json:"issued_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Tokens     []*Token  `json:"tokens"`
+}
+
+// Connection is one consent, active token, or active session linking a user to a client.
+//
+// Purpose: Row of the connected-apps query, grouped per client by Service.ListConnectedApps.
+// Domain: OAuth2
+// Invariants: Kind is KindConsent, KindAccess, KindRefresh, or KindSession. Consents have
+// no ID and a zero ExpiresAt; UserAgent is set only for sessions, GrantID only for tokens.
+type Connection struct {
+	Kind       string
+	ID         string
+	ClientID   string
+	ClientName string
+	LogoURI    string
+	GrantID    string
+	Scopes     []string
+	UserAgent  string
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+	LastSeenAt time.Time
+}
+
+// AppSession is an active login session from which the user authorized a client.
+//
+// Purpose: Lets the user see where an application was approved.
+// Domain: OAuth2
+// Invariants: Never carries the session's IP address.
+type AppSession struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ConnectedApp is everything linking a user to one client.
+//
+// Purpose: One entry of a "Manage your connected apps" page.
+// Domain: OAuth2
+// Invariants: At least one of ConsentedScopes, Grants, and Sessions is not empty.
+// LastUsedAt is the latest consent update, token issuance, or session activity.
+type ConnectedApp struct {
+	ClientID        string        `json:"client_id"`
+	ClientName      string        `json:"client_name"`
+	LogoURI         string        `json:"logo_uri,omitempty"`
+	ConsentedScopes []string      `json:"consented_scopes"`
+	ConsentedAt     *time.Time    `json:"consented_at,omitempty"`
+	Grants          []*Grant      `json:"grants"`
+	Sessions        []*AppSession `json:"sessions"`
+	LastUsedAt      time.Time     `json:"last_used_at"`
+}
+
+// Repository defines token inspection and revocation storage.
+//
+// Purpose: Tenant-scoped queries across access and refresh tokens.
+// Domain: OAuth2
+type Repository interface {
+	// ListActive returns the unrevoked, unexpired tokens of a user, newest first
+	ListActive(ctx context.Context, tenantID, userID string) ([]*Token, error)
+	// ListConnections returns the consents, active tokens, and active sessions that
+	// authorized a client of a user, in one round trip, newest first
+	ListConnections(ctx context.Context, tenantID, userID string) ([]*Connection, error)
+	// RevokeToken revokes one token of a user and returns it, or ErrTokenNotFound
+	RevokeToken(ctx context.Context, tenantID, userID, tokenID string) (*Token, error)
+	// RevokeGrant revokes every token of a user's grant and returns how many were revoked
+	RevokeGrant(ctx context.Context, tenantID, userID, grantID string) (int, error)
+	// RevokeAll revokes every token of a user in a tenant and returns how many were revoked
+	RevokeAll(ctx context.Context, tenantID, userID string) (int, error)
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// Revocation reasons recorded in audit metadata
+const (
+	ReasonSingle = "single"
+	ReasonGrant  = "grant"
+	ReasonAll    = "all"
+)
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// Service inspects and revokes a user's tokens.
+//
+// Purpose: Admin and self-service token management.
+// Domain: OAuth2
+// Invariants: Users may always manage their own tokens; managing another user's tokens
+// requires policy.PermTenantManageUsers in the tenant. Every revocation is audited.
+type Service struct {
+	repo        Repository
+	permissions PermissionChecker
+	auditLogger audit.Logger
+}
+
+// NewService creates a new grant service.
+//
+// Purpose: Constructor for the token and grant inspection service.
+// Domain: OAuth2
+// Audited: No
+// Errors: None
+func NewService(repo Repository, permissions PermissionChecker, auditLogger audit.Logger) *Service {
+	return &Service{
+		repo:        repo,
+		permissions: permissions,
+		auditLogger: auditLogger,
+	}
+}
+
+// ListTokens returns the active tokens of userID in tenantID.
+//
+// Purpose: Token inspection for the admin API and the user's own account page.
+// Domain: OAuth2
+// Security: Self-service or policy.PermTenantManageUsers.
+// Audited: No
+// Errors: ErrNotPermitted, System errors
+func (s *Service) ListTokens(ctx context.Context, actorID, tenantID, userID string) ([]*Token, error) {
+	if err := s.authorize(ctx, actorID, tenantID, userID); err != nil {
+		return nil, err
+	}
+	tokens, err := s.repo.ListActive(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// ListGrants returns the active tokens of userID grouped by authorization grant, newest first.
+//
+// Purpose: One entry per client approval, with the scopes and lifetime of its tokens.
+// Domain: OAuth2
+// Security: Self-service or policy.PermTenantManageUsers.
+// Audited: No
+// Errors: ErrNotPermitted, System errors
+func (s *Service) ListGrants(ctx context.Context, actorID, tenantID, userID string) ([]*Grant, error) {
+	tokens, err := s.ListTokens(ctx, actorID, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return groupGrants(tokens), nil
+}
+
+// ListConnectedApps returns the clients userID has consented to, holds active tokens
+// for, or authorized from an active session, most recently used first.
+//
+// Purpose: The "Manage your connected apps" page, from a single repository query.
+// Domain: OAuth2
+// Security: Self-service or policy.PermTenantManageUsers. Sessions are listed without
+// their IP address.
+// Audited: No
+// Errors: ErrNotPermitted, System errors
+func (s *Service) ListConnectedApps(ctx context.Context, actorID, tenantID, userID string) ([]*ConnectedApp, error) {
+	if err := s.authorize(ctx, actorID, tenantID, userID); err != nil {
+		return nil, err
+	}
+	conns, err := s.repo.ListConnections(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connected apps: %w", err)
+	}
+
+	var apps []*ConnectedApp
+	byClient := make(map[string]*ConnectedApp)
+	tokens := make(map[string][]*Token)
+	for _, c := range conns {
+		app := byClient[c.ClientID]
+		if app == nil {
+			app = &ConnectedApp{ClientID: c.ClientID, ClientName: c.ClientName, LogoURI: c.LogoURI}
+			byClient[c.ClientID] = app
+			apps = append(apps, app)
+		}
+		if app.ClientName == "" {
+			app.ClientName, app.LogoURI = c.ClientName, c.LogoURI
+		}
+		switch c.Kind {
+		case KindConsent:
+			at := c.IssuedAt
+			app.ConsentedScopes, app.ConsentedAt = c.Scopes, &at
+		case KindSession:
+			app.Sessions = append(app.Sessions, &AppSession{ID: c.ID, UserAgent: c.UserAgent, CreatedAt: c.IssuedAt, LastSeenAt: c.LastSeenAt, ExpiresAt: c.ExpiresAt})
+		case KindAccess, KindRefresh:
+			tokens[c.ClientID] = append(tokens[c.ClientID], &Token{
+				ID: c.ID, Kind: c.Kind, TenantID: tenantID, UserID: userID, ClientID: c.ClientID, ClientName: c.ClientName,
+				GrantID: c.GrantID, Scopes: c.Scopes, IssuedAt: c.IssuedAt, ExpiresAt: c.ExpiresAt,
+			})
+		}
+		last := c.IssuedAt
+		if c.LastSeenAt.After(last) {
+			last = c.LastSeenAt
+		}
+		if last.After(app.LastUsedAt) {
+			app.LastUsedAt = last
+		}
+	}
+	for _, app := range apps {
+		app.Grants = groupGrants(tokens[app.ClientID])
+	}
+	slices.SortStableFunc(apps, func(a, b *ConnectedApp) int { return b.LastUsedAt.Compare(a.LastUsedAt) })
+	return apps, nil
+}
+
+// groupGrants groups tokens by authorization grant, newest first.
+func groupGrants(tokens []*Token) []*Grant {
+	var grants []*Grant
+	byID := make(map[string]*Grant)
+	for _, t := range tokens {
+		g := byID[t.GrantID]
+		if g == nil || t.GrantID == "" {
+			g = &Grant{ID: t.GrantID, ClientID: t.ClientID, ClientName: t.ClientName, IssuedAt: t.IssuedAt, ExpiresAt: t.ExpiresAt}
+			grants = append(grants, g)
+			if t.GrantID != "" {
+				byID[t.GrantID] = g
+			}
+		}
+		g.Tokens = append(g.Tokens, t)
+		for _, scope := range t.Scopes {
+			if !slices.Contains(g.Scopes, scope) {
+				g.Scopes = append(g.Scopes, scope)
+			}
+		}
+		if t.IssuedAt.Before(g.IssuedAt) {
+			g.IssuedAt = t.IssuedAt
+		}
+		if t.ExpiresAt.After(g.ExpiresAt) {
+			g.ExpiresAt = t.ExpiresAt
+		}
+	}
+	slices.SortStableFunc(grants, func(a, b *Grant) int { return b.IssuedAt.Compare(a.IssuedAt) })
+	return grants
+}
+
+// RevokeToken revokes a single token of userID.
+//
+// Purpose: Targeted revocation, e.g. of a token reported as leaked.
+// Domain: OAuth2
+// Security: Self-service or policy.PermTenantManageUsers. The token must belong to userID in tenantID.
+// Audited: Yes (TypeTokenRevoked)
+// Errors: ErrNotPermitted, ErrTokenNotFound, System errors
+func (s *Service) RevokeToken(ctx context.Context, actorID, tenantID, userID, tokenID string) error {
+	if err := s.authorize(ctx, actorID, tenantID, userID); err != nil {
+		return err
+	}
+	t, err := s.repo.RevokeToken(ctx, tenantID, userID, tokenID)
+	if err != nil {
+		return err
+	}
+	s.logRevocation(ctx, actorID, tenantID, userID, tokenID, map[string]any{
+		audit.AttrReason:  ReasonSingle,
+		audit.AttrGrantID: t.GrantID,
+		"kind":            t.Kind,
+		"client_id":       t.ClientID,
+	})
+	return nil
+}
+
+// RevokeGrant revokes every token issued under grantID.
+//
+// Purpose: Ends one client approval on every device it was used on.
+// Domain: OAuth2
+// Security: Self-service or policy.PermTenantManageUsers. The grant must belong to userID in tenantID.
+// Audited: Yes (TypeTokenRevoked)
+// Errors: ErrNotPermitted, ErrGrantNotFound, System errors
+func (s *Service) RevokeGrant(ctx context.Context, actorID, tenantID, userID, grantID string) error {
+	if err := s.authorize(ctx, actorID, tenantID, userID); err != nil {
+		return err
+	}
+	n, err := s.repo.RevokeGrant(ctx, tenantID, userID, grantID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke grant: %w", err)
+	}
+	if n == 0 {
+		return ErrGrantNotFound
+	}
+	s.logRevocation(ctx, actorID, tenantID, userID, grantID, map[string]any{
+		audit.AttrReason:  ReasonGrant,
+		audit.AttrGrantID: grantID,
+		"count":           n,
+	})
+	return nil
+}
+
+// RevokeAll revokes every token of userID in tenantID and returns how many were revoked.
+//
+// Purpose: Bulk revocation after account compromise or offboarding.
+// Domain: OAuth2
+// Security: Self-service or policy.PermTenantManageUsers.
+// Audited: Yes (TypeTokenRevoked)
+// Errors: ErrNotPermitted, System errors
+func (s *Service) RevokeAll(ctx context.Context, actorID, tenantID, userID string) (int, error) {
+	if err := s.authorize(ctx, actorID, tenantID, userID); err != nil {
+		return 0, err
+	}
+	n, err := s.repo.RevokeAll(ctx, tenantID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	s.logRevocation(ctx, actorID, tenantID, userID, userID, map[string]any{
+		audit.AttrReason: ReasonAll,
+		"count":          n,
+	})
+	return n, nil
+}
+
+func (s *Service) authorize(ctx context.Context, actorID, tenantID, userID string) error {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	if actorID != "" && actorID == userID {
+		return nil
+	}
+	if s.permissions == nil {
+		return ErrNotPermitted
+	}
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopeTenant, &tenantID, policy.PermTenantManageUsers)
+	if err != nil {
+		return fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !ok {
+		return ErrNotPermitted
+	}
+	return nil
+}
+
+func (s *Service) logRevocation(ctx context.Context, actorID, tenantID, userID, targetID string, metadata map[string]any) {
+	metadata["user_id"] = userID
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTokenRevoked,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceToken,
+		TargetID: targetID,
+		Metadata: metadata,
+	})
+}
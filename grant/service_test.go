@@ -0,0 +1,237 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grant
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type mockRepo struct {
+	tokens []*Token
+	// others are the consents and sessions of u1 in t1
+	others []*Connection
+}
+
+func (m *mockRepo) ListActive(ctx context.Context, tenantID, userID string) ([]*Token, error) {
+	var res []*Token
+	for _, t := range m.tokens {
+		if t.TenantID == tenantID && t.UserID == userID {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) ListConnections(ctx context.Context, tenantID, userID string) ([]*Connection, error) {
+	tokens, _ := m.ListActive(ctx, tenantID, userID)
+	var res []*Connection
+	for _, t := range tokens {
+		res = append(res, &Connection{Kind: t.Kind, ID: t.ID, ClientID: t.ClientID, GrantID: t.GrantID, Scopes: t.Scopes, IssuedAt: t.IssuedAt, ExpiresAt: t.ExpiresAt, LastSeenAt: t.IssuedAt})
+	}
+	if tenantID == "t1" && userID == "u1" {
+		res = append(res, m.others...)
+	}
+	return res, nil
+}
+
+func (m *mockRepo) RevokeToken(ctx context.Context, tenantID, userID, tokenID string) (*Token, error) {
+	for i, t := range m.tokens {
+		if t.TenantID == tenantID && t.UserID == userID && t.ID == tokenID {
+			m.tokens = append(m.tokens[:i], m.tokens[i+1:]...)
+			return t, nil
+		}
+	}
+	return nil, ErrTokenNotFound
+}
+
+func (m *mockRepo) RevokeGrant(ctx context.Context, tenantID, userID, grantID string) (int, error) {
+	return m.remove(func(t *Token) bool { return t.TenantID == tenantID && t.UserID == userID && t.GrantID == grantID }), nil
+}
+
+func (m *mockRepo) RevokeAll(ctx context.Context, tenantID, userID string) (int, error) {
+	return m.remove(func(t *Token) bool { return t.TenantID == tenantID && t.UserID == userID }), nil
+}
+
+func (m *mockRepo) remove(match func(*Token) bool) int {
+	kept := m.tokens[:0]
+	for _, t := range m.tokens {
+		if !match(t) {
+			kept = append(kept, t)
+		}
+	}
+	n := len(m.tokens) - len(kept)
+	m.tokens = kept
+	return n
+}
+
+type mockPermissions map[string]bool
+
+func (m mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return scope == role.ScopeTenant && permission == policy.PermTenantManageUsers && m[userID+"@"+*scopeContextID], nil
+}
+
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Log(_ context.Context, e audit.Event) {
+	r.events = append(r.events, e)
+}
+
+func newFixture() (*Service, *mockRepo, *recordingAuditLogger) {
+	now := time.Now()
+	repo := &mockRepo{tokens: []*Token{
+		{ID: "a1", Kind: KindAccess, TenantID: "t1", UserID: "u1", ClientID: "c1", GrantID: "g1", Scopes: []string{"openid"}, IssuedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "r1", Kind: KindRefresh, TenantID: "t1", UserID: "u1", ClientID: "c1", GrantID: "g1", Scopes: []string{"openid", "offline_access"}, IssuedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(24 * time.Hour)},
+		{ID: "a2", Kind: KindAccess, TenantID: "t1", UserID: "u1", ClientID: "c2", GrantID: "g2", IssuedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "legacy", Kind: KindAccess, TenantID: "t1", UserID: "u1", ClientID: "c2", IssuedAt: now.Add(-3 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "a3", Kind: KindAccess, TenantID: "t2", UserID: "u1", ClientID: "c3", GrantID: "g3", IssuedAt: now, ExpiresAt: now.Add(time.Hour)},
+	}, others: []*Connection{
+		{Kind: KindConsent, ClientID: "c1", ClientName: "Photos", Scopes: []string{"openid", "offline_access"}, IssuedAt: now.Add(-48 * time.Hour), LastSeenAt: now.Add(-10 * time.Minute)},
+		{Kind: KindSession, ID: "s1", ClientID: "c1", UserAgent: "Firefox", IssuedAt: now.Add(-3 * time.Hour), LastSeenAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{Kind: KindSession, ID: "s2", ClientID: "c4", IssuedAt: now.Add(-2 * time.Hour), LastSeenAt: now.Add(-30 * time.Minute), ExpiresAt: now.Add(time.Hour)},
+	}}
+	logger := &recordingAuditLogger{}
+	return NewService(repo, mockPermissions{"admin@t1": true}, logger), repo, logger
+}
+
+func TestAuthorization(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		actorID  string
+		tenantID string
+		wantErr  error
+	}{
+		{"self service", "u1", "t1", nil},
+		{"tenant admin", "admin", "t1", nil},
+		{"admin of another tenant", "admin", "t2", ErrNotPermitted},
+		{"other user", "u2", "t1", ErrNotPermitted},
+		{"anonymous", "", "t1", ErrNotPermitted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newFixture()
+			if _, err := svc.ListTokens(ctx, tt.actorID, tt.tenantID, "u1"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ListTokens() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := svc.RevokeToken(ctx, tt.actorID, tt.tenantID, "u1", "a1"); tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("RevokeToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestListGrants(t *testing.T) {
+	svc, _, _ := newFixture()
+	grants, err := svc.ListGrants(context.Background(), "u1", "t1", "u1")
+	if err != nil {
+		t.Fatalf("ListGrants: %v", err)
+	}
+	if len(grants) != 3 {
+		t.Fatalf("got %d grants, want 3 (g1, g2, legacy token)", len(grants))
+	}
+	if grants[0].ID != "g2" || grants[1].ID != "g1" || grants[2].ID != "" {
+		t.Errorf("grants not newest first: %s, %s, %q", grants[0].ID, grants[1].ID, grants[2].ID)
+	}
+	g1 := grants[1]
+	if len(g1.Tokens) != 2 || len(g1.Scopes) != 2 || !g1.ExpiresAt.Equal(g1.Tokens[1].ExpiresAt) {
+		t.Errorf("g1 = %+v, want 2 tokens, scopes openid+offline_access, refresh expiry", g1)
+	}
+}
+
+func TestListConnectedApps(t *testing.T) {
+	svc, _, _ := newFixture()
+	if _, err := svc.ListConnectedApps(context.Background(), "u2", "t1", "u1"); !errors.Is(err, ErrNotPermitted) {
+		t.Fatalf("ListConnectedApps() by another user error = %v, want ErrNotPermitted", err)
+	}
+
+	apps, err := svc.ListConnectedApps(context.Background(), "u1", "t1", "u1")
+	if err != nil {
+		t.Fatalf("ListConnectedApps: %v", err)
+	}
+	var order []string
+	for _, a := range apps {
+		order = append(order, a.ClientID)
+	}
+	if !slices.Equal(order, []string{"c1", "c4", "c2"}) {
+		t.Fatalf("apps = %v, want c1, c4, c2 (most recently used first, no other tenant)", order)
+	}
+	c1 := apps[0]
+	if c1.ClientName != "Photos" || len(c1.ConsentedScopes) != 2 || c1.ConsentedAt == nil || len(c1.Grants) != 1 || len(c1.Grants[0].Tokens) != 2 || len(c1.Sessions) != 1 || c1.Sessions[0].UserAgent != "Firefox" {
+		t.Errorf("c1 = %+v, want consent, grant g1 with 2 tokens, and session s1", c1)
+	}
+	if c4 := apps[1]; len(c4.Sessions) != 1 || len(c4.Grants) != 0 || c4.ConsentedAt != nil {
+		t.Errorf("c4 = %+v, want only session s2", c4)
+	}
+	if c2 := apps[2]; len(c2.Grants) != 2 || c2.ConsentedScopes != nil {
+		t.Errorf("c2 = %+v, want grant g2 and the legacy token", c2)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("single token", func(t *testing.T) {
+		svc, repo, logger := newFixture()
+		if err := svc.RevokeToken(ctx, "admin", "t1", "u1", "r1"); err != nil {
+			t.Fatalf("RevokeToken: %v", err)
+		}
+		if len(repo.tokens) != 4 {
+			t.Errorf("%d tokens left, want 4", len(repo.tokens))
+		}
+		if len(logger.events) != 1 || logger.events[0].Type != audit.TypeTokenRevoked || logger.events[0].Metadata[audit.AttrGrantID] != "g1" {
+			t.Errorf("audit = %+v, want one token_revoked for grant g1", logger.events)
+		}
+		if err := svc.RevokeToken(ctx, "admin", "t1", "u1", "a3"); !errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("token of another tenant: error = %v, want ErrTokenNotFound", err)
+		}
+	})
+
+	t.Run("grant", func(t *testing.T) {
+		svc, repo, logger := newFixture()
+		if err := svc.RevokeGrant(ctx, "u1", "t1", "u1", "g1"); err != nil {
+			t.Fatalf("RevokeGrant: %v", err)
+		}
+		if len(repo.tokens) != 3 || logger.events[0].Metadata["count"] != 2 {
+			t.Errorf("tokens left = %d, audit = %+v", len(repo.tokens), logger.events)
+		}
+		if err := svc.RevokeGrant(ctx, "u1", "t1", "u1", "g1"); !errors.Is(err, ErrGrantNotFound) {
+			t.Errorf("revoked grant: error = %v, want ErrGrantNotFound", err)
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		svc, repo, logger := newFixture()
+		n, err := svc.RevokeAll(ctx, "admin", "t1", "u1")
+		if err != nil || n != 4 {
+			t.Fatalf("RevokeAll = %d, %v; want 4", n, err)
+		}
+		if len(repo.tokens) != 1 || repo.tokens[0].TenantID != "t2" {
+			t.Errorf("tokens of other tenants must survive, left %+v", repo.tokens)
+		}
+		if len(logger.events) != 1 || logger.events[0].ActorID != "admin" {
+			t.Errorf("audit = %+v", logger.events)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// DefaultLocale is the locale every lookup falls back to.
+const DefaultLocale = "en"
+
+// Message is a rendered, user-safe error.
+type Message struct {
+	Code    Code   `json:"code"`
+	Locale  string `json:"locale"`
+	Message string `json:"message"`
+}
+
+// Catalog holds translated messages per locale.
+//
+// Purpose: Locale-aware rendering of error codes.
+// Domain: Platform
+// Invariants: DefaultLocale defines a message for every code. Safe for concurrent use.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[Code]string
+}
+
+// NewCatalog returns a catalog preloaded with the built-in translations.
+func NewCatalog() *Catalog {
+	c := &Catalog{messages: make(map[string]map[Code]string)}
+	for locale, msgs := range builtin {
+		c.Add(locale, msgs)
+	}
+	return c
+}
+
+// Add registers or overrides messages for a locale. Hosts use it to add
+// locales or to reword built-in messages.
+func (c *Catalog) Add(locale string, msgs map[Code]string) {
+	locale = normalizeLocale(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.messages[locale]
+	if !ok {
+		m = make(map[Code]string, len(msgs))
+		c.messages[locale] = m
+	}
+	for code, text := range msgs {
+		m[code] = text
+	}
+}
+
+// Locales returns the locales the catalog has messages for.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.messages))
+	for l := range c.messages {
+		locales = append(locales, l)
+	}
+	return locales
+}
+
+// Lookup returns the message for code in the first of locales that has one,
+// trying each locale's base language ("pt-br" then "pt") before moving on,
+// and DefaultLocale last. It reports the locale actually used.
+func (c *Catalog) Lookup(code Code, locales ...string) (string, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, l := range append(slices.Clip(locales), DefaultLocale) {
+		l = normalizeLocale(l)
+		if l == "" {
+			continue
+		}
+		if text, ok := c.messages[l][code]; ok {
+			return text, l
+		}
+		if base, _, found := strings.Cut(l, "-"); found {
+			if text, ok := c.messages[base][code]; ok {
+				return text, base
+			}
+		}
+	}
+	return string(code), DefaultLocale
+}
+
+// Render converts err into a safe, translated message.
+//
+// Purpose: Entry point for transports presenting domain errors to end users.
+// Domain: Platform
+// Security: The returned text comes only from the catalog, never from err.Error().
+// Audited: No
+// Errors: None
+func (c *Catalog) Render(err error, locales ...string) Message {
+	code := CodeOf(err)
+	text, locale := c.Lookup(code, locales...)
+	return Message{Code: code, Locale: locale, Message: text}
+}
+
+// RenderForUser renders err in the user's profile locale, falling back to
+// fallback (typically the request's Accept-Language preference) and then
+// DefaultLocale. u may be nil, e.g. when authentication failed.
+func (c *Catalog) RenderForUser(err error, u *user.User, fallback ...string) Message {
+	if u == nil || u.Profile.Locale == "" {
+		return c.Render(err, fallback...)
+	}
+	return c.Render(err, append([]string{u.Profile.Locale}, fallback...)...)
+}
+
+// normalizeLocale lowercases a BCP 47 tag and accepts POSIX-style "pt_BR".
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package i18n maps domain errors to stable, machine-readable codes and
+// renders them as safe, translated messages. Transports return the code to
+// clients and the rendered message to end users; the underlying error text,
+// which may carry internal detail, never leaves the process.
+package i18n
+
+import "github.com/opentrusty/opentrusty-core/apperror"
+
+// Code is a stable identifier for a user-facing error condition.
+// It is the apperror code carried by every domain error.
+type Code = apperror.Code
+
+// Error codes
+const (
+	CodeInternal              = apperror.CodeInternal
+	CodeInvalidRequest        = apperror.CodeInvalidRequest
+	CodeInvalidCredentials    = apperror.CodeInvalidCredentials
+	CodeAccountLocked         = apperror.CodeAccountLocked
+	CodeWeakPassword          = apperror.CodeWeakPassword
+	CodeInvalidEmail          = apperror.CodeInvalidEmail
+	CodeUserAlreadyExists     = apperror.CodeUserAlreadyExists
+	CodeNotFound              = apperror.CodeNotFound
+	CodeAlreadyExists         = apperror.CodeAlreadyExists
+	CodeSessionExpired        = apperror.CodeSessionExpired
+	CodeAccessDenied          = apperror.CodeAccessDenied
+	CodeInvalidScope          = apperror.CodeInvalidScope
+	CodeInvalidRedirectURI    = apperror.CodeInvalidRedirectURI
+	CodeInvalidGrantType      = apperror.CodeInvalidGrantType
+	CodeInvalidClient         = apperror.CodeInvalidClient
+	CodeInvalidGrant          = apperror.CodeInvalidGrant
+	CodeInvalidToken          = apperror.CodeInvalidToken
+	CodeInvalidTenantName     = apperror.CodeInvalidTenantName
+	CodeSourceBlocked         = apperror.CodeSourceBlocked
+	CodePasswordExpired       = apperror.CodePasswordExpired
+	CodeMFARequired           = apperror.CodeMFARequired
+	CodeMFAEnrollmentRequired = apperror.CodeMFAEnrollmentRequired
+	CodePasswordResetRequired = apperror.CodePasswordResetRequired
+	CodeServiceUnavailable    = apperror.CodeServiceUnavailable
+	CodeUnauthenticated       = apperror.CodeUnauthenticated
+)
+
+// Codes returns every defined code.
+func Codes() []Code {
+	return apperror.Codes()
+}
+
+// CodeOf returns the code for err, or CodeInternal if err is not a
+// classified domain error. Wrapped errors are matched with errors.As.
+//
+// Purpose: Single mapping from domain errors to public codes.
+// Domain: Platform
+// Security: Unknown errors collapse to CodeInternal so internal detail is never exposed.
+// Audited: No
+// Errors: None
+func CodeOf(err error) Code {
+	return apperror.CodeOf(err)
+}
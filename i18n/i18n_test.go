@@ -0,0 +1,107 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{name: "sentinel", err: user.ErrWeakPassword, want: CodeWeakPassword},
+		{name: "wrapped", err: fmt.Errorf("failed to set password: %w", user.ErrAccountLocked), want: CodeAccountLocked},
+		{name: "client scope", err: client.ErrDomainInvalidScope, want: CodeInvalidScope},
+		{name: "token revoked", err: client.ErrTokenRevoked, want: CodeInvalidGrant},
+		{name: "unknown", err: errors.New("pq: relation users does not exist"), want: CodeInternal},
+		{name: "nil", err: nil, want: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinCatalogIsComplete(t *testing.T) {
+	for locale, msgs := range builtin {
+		for _, code := range Codes() {
+			if msgs[code] == "" {
+				t.Errorf("locale %s has no message for %s", locale, code)
+			}
+		}
+		if len(msgs) != len(Codes()) {
+			t.Errorf("locale %s has %d messages, want %d", locale, len(msgs), len(Codes()))
+		}
+	}
+}
+
+func TestRenderLocaleFallback(t *testing.T) {
+	c := NewCatalog()
+	c.Add("pt_BR", map[Code]string{CodeWeakPassword: "A senha não atende aos requisitos de segurança."})
+
+	tests := []struct {
+		name       string
+		locales    []string
+		wantLocale string
+	}{
+		{name: "exact", locales: []string{"de"}, wantLocale: "de"},
+		{name: "region falls back to base language", locales: []string{"fr-CA"}, wantLocale: "fr"},
+		{name: "posix tag normalized", locales: []string{"pt_br"}, wantLocale: "pt-br"},
+		{name: "preference order", locales: []string{"xx", "ja-JP"}, wantLocale: "ja"},
+		{name: "default", locales: []string{"xx"}, wantLocale: DefaultLocale},
+		{name: "none", wantLocale: DefaultLocale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := c.Render(fmt.Errorf("wrapped: %w", user.ErrWeakPassword), tt.locales...)
+			if m.Code != CodeWeakPassword || m.Locale != tt.wantLocale || m.Message == "" {
+				t.Errorf("Render() = %+v, want locale %s", m, tt.wantLocale)
+			}
+		})
+	}
+}
+
+func TestRenderForUserUsesProfileLocale(t *testing.T) {
+	c := NewCatalog()
+	u := &user.User{Profile: user.Profile{Locale: "es-MX"}}
+
+	if m := c.RenderForUser(user.ErrAccountLocked, u, "de"); m.Locale != "es" {
+		t.Errorf("RenderForUser() locale = %s, want es", m.Locale)
+	}
+	if m := c.RenderForUser(user.ErrAccountLocked, nil, "de"); m.Locale != "de" {
+		t.Errorf("RenderForUser(nil) locale = %s, want de", m.Locale)
+	}
+}
+
+func TestRenderNeverLeaksErrorText(t *testing.T) {
+	m := NewCatalog().Render(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+	if m.Code != CodeInternal || strings.Contains(m.Message, "10.0.0.5") {
+		t.Errorf("Render() = %+v, leaked internal detail", m)
+	}
+}
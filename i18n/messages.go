@@ -0,0 +1,155 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+// builtin holds the translations shipped with core. Messages are written for
+// end users: they never name internal components or echo user input.
+var builtin = map[string]map[Code]string{
+	"en": {
+		CodeInternal:              "Something went wrong. Please try again later.",
+		CodeInvalidRequest:        "The request is invalid.",
+		CodeInvalidCredentials:    "The email address or password is incorrect.",
+		CodeAccountLocked:         "This account is temporarily locked after too many failed sign-in attempts. Please try again later.",
+		CodeWeakPassword:          "The password does not meet the security requirements.",
+		CodeInvalidEmail:          "The email address is not valid.",
+		CodeUserAlreadyExists:     "An account with this email address already exists.",
+		CodeNotFound:              "The requested resource was not found.",
+		CodeAlreadyExists:         "The resource already exists.",
+		CodeSessionExpired:        "Your session has expired. Please sign in again.",
+		CodeAccessDenied:          "You do not have permission to perform this action.",
+		CodeInvalidScope:          "The requested scope is not allowed.",
+		CodeInvalidRedirectURI:    "The redirect URI is not registered for this application.",
+		CodeInvalidGrantType:      "This application is not allowed to use the requested grant type.",
+		CodeInvalidClient:         "The application could not be authenticated.",
+		CodeInvalidGrant:          "The authorization code or token is invalid or has expired.",
+		CodeInvalidToken:          "The token is invalid or has expired.",
+		CodeInvalidTenantName:     "The tenant name is not valid.",
+		CodeSourceBlocked:         "Too many requests from your network. Please try again later.",
+		CodePasswordExpired:       "Your password has expired. Please choose a new password to continue.",
+		CodeMFARequired:           "Additional verification is required. Please complete multi-factor authentication to continue.",
+		CodeMFAEnrollmentRequired: "Set up multi-factor authentication to continue. Your organization requires it.",
+		CodePasswordResetRequired: "Your password must be reset. Use the password reset link sent to your email address.",
+		CodeServiceUnavailable:    "The service is temporarily unavailable. Please try again later.",
+		CodeUnauthenticated:       "Please sign in to continue.",
+	},
+	"de": {
+		CodeInternal:              "Etwas ist schiefgelaufen. Bitte versuchen Sie es später erneut.",
+		CodeInvalidRequest:        "Die Anfrage ist ungültig.",
+		CodeInvalidCredentials:    "Die E-Mail-Adresse oder das Passwort ist falsch.",
+		CodeAccountLocked:         "Dieses Konto ist nach zu vielen fehlgeschlagenen Anmeldeversuchen vorübergehend gesperrt. Bitte versuchen Sie es später erneut.",
+		CodeWeakPassword:          "Das Passwort erfüllt nicht die Sicherheitsanforderungen.",
+		CodeInvalidEmail:          "Die E-Mail-Adresse ist ungültig.",
+		CodeUserAlreadyExists:     "Es existiert bereits ein Konto mit dieser E-Mail-Adresse.",
+		CodeNotFound:              "Die angeforderte Ressource wurde nicht gefunden.",
+		CodeAlreadyExists:         "Die Ressource existiert bereits.",
+		CodeSessionExpired:        "Ihre Sitzung ist abgelaufen. Bitte melden Sie sich erneut an.",
+		CodeAccessDenied:          "Sie haben keine Berechtigung, diese Aktion auszuführen.",
+		CodeInvalidScope:          "Der angeforderte Geltungsbereich ist nicht zulässig.",
+		CodeInvalidRedirectURI:    "Die Weiterleitungs-URI ist für diese Anwendung nicht registriert.",
+		CodeInvalidGrantType:      "Diese Anwendung darf den angeforderten Grant-Typ nicht verwenden.",
+		CodeInvalidClient:         "Die Anwendung konnte nicht authentifiziert werden.",
+		CodeInvalidGrant:          "Der Autorisierungscode oder das Token ist ungültig oder abgelaufen.",
+		CodeInvalidToken:          "Das Token ist ungültig oder abgelaufen.",
+		CodeInvalidTenantName:     "Der Mandantenname ist ungültig.",
+		CodeSourceBlocked:         "Zu viele Anfragen aus Ihrem Netzwerk. Bitte versuchen Sie es später erneut.",
+		CodePasswordExpired:       "Ihr Passwort ist abgelaufen. Bitte wählen Sie ein neues Passwort, um fortzufahren.",
+		CodeMFARequired:           "Eine zusätzliche Bestätigung ist erforderlich. Bitte schließen Sie die Multi-Faktor-Authentifizierung ab, um fortzufahren.",
+		CodeMFAEnrollmentRequired: "Richten Sie die Multi-Faktor-Authentifizierung ein, um fortzufahren. Ihre Organisation schreibt sie vor.",
+		CodePasswordResetRequired: "Ihr Passwort muss zurückgesetzt werden. Verwenden Sie den Link, der an Ihre E-Mail-Adresse gesendet wurde.",
+		CodeServiceUnavailable:    "Der Dienst ist vorübergehend nicht verfügbar. Bitte versuchen Sie es später erneut.",
+		CodeUnauthenticated:       "Bitte melden Sie sich an, um fortzufahren.",
+	},
+	"fr": {
+		CodeInternal:              "Une erreur s'est produite. Veuillez réessayer plus tard.",
+		CodeInvalidRequest:        "La requête n'est pas valide.",
+		CodeInvalidCredentials:    "L'adresse e-mail ou le mot de passe est incorrect.",
+		CodeAccountLocked:         "Ce compte est temporairement verrouillé après trop de tentatives de connexion infructueuses. Veuillez réessayer plus tard.",
+		CodeWeakPassword:          "Le mot de passe ne respecte pas les exigences de sécurité.",
+		CodeInvalidEmail:          "L'adresse e-mail n'est pas valide.",
+		CodeUserAlreadyExists:     "Un compte existe déjà avec cette adresse e-mail.",
+		CodeNotFound:              "La ressource demandée est introuvable.",
+		CodeAlreadyExists:         "La ressource existe déjà.",
+		CodeSessionExpired:        "Votre session a expiré. Veuillez vous reconnecter.",
+		CodeAccessDenied:          "Vous n'avez pas l'autorisation d'effectuer cette action.",
+		CodeInvalidScope:          "La portée demandée n'est pas autorisée.",
+		CodeInvalidRedirectURI:    "L'URI de redirection n'est pas enregistrée pour cette application.",
+		CodeInvalidGrantType:      "Cette application n'est pas autorisée à utiliser le type d'autorisation demandé.",
+		CodeInvalidClient:         "L'application n'a pas pu être authentifiée.",
+		CodeInvalidGrant:          "Le code d'autorisation ou le jeton est invalide ou a expiré.",
+		CodeInvalidToken:          "Le jeton est invalide ou a expiré.",
+		CodeInvalidTenantName:     "Le nom du locataire n'est pas valide.",
+		CodeSourceBlocked:         "Trop de requêtes depuis votre réseau. Veuillez réessayer plus tard.",
+		CodePasswordExpired:       "Votre mot de passe a expiré. Veuillez choisir un nouveau mot de passe pour continuer.",
+		CodeMFARequired:           "Une vérification supplémentaire est requise. Veuillez effectuer l'authentification multifacteur pour continuer.",
+		CodeMFAEnrollmentRequired: "Configurez l'authentification multifacteur pour continuer. Votre organisation l'exige.",
+		CodePasswordResetRequired: "Votre mot de passe doit être réinitialisé. Utilisez le lien envoyé à votre adresse e-mail.",
+		CodeServiceUnavailable:    "Le service est temporairement indisponible. Veuillez réessayer plus tard.",
+		CodeUnauthenticated:       "Veuillez vous connecter pour continuer.",
+	},
+	"es": {
+		CodeInternal:              "Se ha producido un error. Inténtelo de nuevo más tarde.",
+		CodeInvalidRequest:        "La solicitud no es válida.",
+		CodeInvalidCredentials:    "El correo electrónico o la contraseña son incorrectos.",
+		CodeAccountLocked:         "Esta cuenta está bloqueada temporalmente tras demasiados intentos de inicio de sesión fallidos. Inténtelo de nuevo más tarde.",
+		CodeWeakPassword:          "La contraseña no cumple los requisitos de seguridad.",
+		CodeInvalidEmail:          "La dirección de correo electrónico no es válida.",
+		CodeUserAlreadyExists:     "Ya existe una cuenta con esta dirección de correo electrónico.",
+		CodeNotFound:              "No se ha encontrado el recurso solicitado.",
+		CodeAlreadyExists:         "El recurso ya existe.",
+		CodeSessionExpired:        "Su sesión ha caducado. Vuelva a iniciar sesión.",
+		CodeAccessDenied:          "No tiene permiso para realizar esta acción.",
+		CodeInvalidScope:          "El ámbito solicitado no está permitido.",
+		CodeInvalidRedirectURI:    "El URI de redirección no está registrado para esta aplicación.",
+		CodeInvalidGrantType:      "Esta aplicación no puede usar el tipo de concesión solicitado.",
+		CodeInvalidClient:         "No se ha podido autenticar la aplicación.",
+		CodeInvalidGrant:          "El código de autorización o el token no es válido o ha caducado.",
+		CodeInvalidToken:          "El token no es válido o ha caducado.",
+		CodeInvalidTenantName:     "El nombre del inquilino no es válido.",
+		CodeSourceBlocked:         "Demasiadas solicitudes desde su red. Inténtelo de nuevo más tarde.",
+		CodePasswordExpired:       "Su contraseña ha caducado. Elija una contraseña nueva para continuar.",
+		CodeMFARequired:           "Se requiere una verificación adicional. Complete la autenticación multifactor para continuar.",
+		CodeMFAEnrollmentRequired: "Configure la autenticación multifactor para continuar. Su organización la exige.",
+		CodePasswordResetRequired: "Debe restablecer su contraseña. Utilice el enlace enviado a su dirección de correo electrónico.",
+		CodeServiceUnavailable:    "El servicio no está disponible temporalmente. Vuelva a intentarlo más tarde.",
+		CodeUnauthenticated:       "Inicie sesión para continuar.",
+	},
+	"ja": {
+		CodeInternal:              "エラーが発生しました。しばらくしてから再度お試しください。",
+		CodeInvalidRequest:        "リクエストが無効です。",
+		CodeInvalidCredentials:    "メールアドレスまたはパスワードが正しくありません。",
+		CodeAccountLocked:         "サインインの失敗が続いたため、このアカウントは一時的にロックされています。しばらくしてから再度お試しください。",
+		CodeWeakPassword:          "パスワードがセキュリティ要件を満たしていません。",
+		CodeInvalidEmail:          "メールアドレスが無効です。",
+		CodeUserAlreadyExists:     "このメールアドレスのアカウントは既に存在します。",
+		CodeNotFound:              "要求されたリソースが見つかりません。",
+		CodeAlreadyExists:         "リソースは既に存在します。",
+		CodeSessionExpired:        "セッションの有効期限が切れました。再度サインインしてください。",
+		CodeAccessDenied:          "この操作を実行する権限がありません。",
+		CodeInvalidScope:          "要求されたスコープは許可されていません。",
+		CodeInvalidRedirectURI:    "リダイレクト URI がこのアプリケーションに登録されていません。",
+		CodeInvalidGrantType:      "このアプリケーションは要求されたグラントタイプを使用できません。",
+		CodeInvalidClient:         "アプリケーションを認証できませんでした。",
+		CodeInvalidGrant:          "認可コードまたはトークンが無効か、有効期限が切れています。",
+		CodeInvalidToken:          "トークンが無効か、有効期限が切れています。",
+		CodeInvalidTenantName:     "テナント名が無効です。",
+		CodeSourceBlocked:         "お使いのネットワークからのリクエストが多すぎます。しばらくしてから再度お試しください。",
+		CodePasswordExpired:       "パスワードの有効期限が切れました。続行するには新しいパスワードを設定してください。",
+		CodeMFARequired:           "追加の確認が必要です。続行するには多要素認証を完了してください。",
+		CodeMFAEnrollmentRequired: "続行するには多要素認証を設定してください。組織で必須になっています。",
+		CodePasswordResetRequired: "パスワードの再設定が必要です。メールアドレスに送信されたリンクを使用してください。",
+		CodeServiceUnavailable:    "サービスは一時的に利用できません。しばらくしてから再度お試しください。",
+		CodeUnauthenticated:       "続行するにはサインインしてください。",
+	},
+}
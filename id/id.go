@@ -14,7 +14,12 @@
 
 package id
 
-import "github.com/google/uuid"
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
 
 // NewUUIDv7 generates a new UUIDv7 in canonical string format.
 //
@@ -27,3 +32,33 @@ func NewUUIDv7() string {
 	id, _ := uuid.NewV7()
 	return id.String()
 }
+
+// Generator mints entity identifiers.
+//
+// Purpose: Injectable ID source, so tests can assert on predictable identifiers.
+// Domain: Platform
+// Invariants: Identifiers are unique for the generator's lifetime and are valid
+// UUIDs, since the PostgreSQL store keys entities by UUID.
+type Generator interface {
+	NewID() string
+}
+
+// GeneratorFunc adapts an ordinary function to a Generator.
+type GeneratorFunc func() string
+
+// NewID calls f.
+func (f GeneratorFunc) NewID() string { return f() }
+
+// UUIDv7 returns the default Generator, backed by NewUUIDv7.
+func UUIDv7() Generator {
+	return GeneratorFunc(NewUUIDv7)
+}
+
+// Sequence returns a Generator that yields UUID-shaped identifiers counting up
+// from 1 (00000000-0000-7000-8000-000000000001, ...). It is meant for tests.
+func Sequence() Generator {
+	var n atomic.Uint64
+	return GeneratorFunc(func() string {
+		return fmt.Sprintf("00000000-0000-7000-8000-%012x", n.Add(1))
+	})
+}
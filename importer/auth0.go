@@ -0,0 +1,312 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+type auth0User struct {
+	UserID        string `json:"user_id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Name          string `json:"name"`
+	Nickname      string `json:"nickname"`
+	Picture       string `json:"picture"`
+	Blocked       bool   `json:"blocked"`
+
+	// PasswordHash is the bulk import field; PasswordHashExport is the field
+	// used by password hash exports obtained from Auth0 support.
+	PasswordHash       string `json:"password_hash"`
+	PasswordHashExport string `json:"passwordHash"`
+	CustomPasswordHash *struct {
+		Algorithm string `json:"algorithm"`
+		Hash      struct {
+			Value string `json:"value"`
+		} `json:"hash"`
+	} `json:"custom_password_hash"`
+
+	Roles       []string `json:"roles"`
+	AppMetadata struct {
+		Roles []string `json:"roles"`
+	} `json:"app_metadata"`
+}
+
+type auth0Tenant struct {
+	Clients []auth0Client `json:"clients"`
+	Roles   []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"roles"`
+}
+
+type auth0Client struct {
+	Name                    string   `json:"name"`
+	ClientID                string   `json:"client_id"`
+	ClientSecret            string   `json:"client_secret"`
+	AppType                 string   `json:"app_type"`
+	Callbacks               []string `json:"callbacks"`
+	GrantTypes              []string `json:"grant_types"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	InitiateLoginURI        string   `json:"initiate_login_uri"`
+}
+
+// auth0GrantTypes are the Auth0 grant types with an OpenTrusty equivalent.
+var auth0GrantTypes = map[string]bool{
+	"authorization_code": true,
+	"refresh_token":      true,
+	"client_credentials": true,
+}
+
+// ParseAuth0 reads an Auth0 user export and, optionally, a tenant export.
+//
+// users may be newline-delimited JSON (bulk export or password hash export)
+// or a JSON array (bulk import format). tenant, if not nil, is a JSON document
+// with "clients" and "roles" arrays as returned by the Management API or
+// auth0-deploy-cli.
+//
+// Purpose: Normalizes an Auth0 tenant into a Dataset.
+// Domain: Identity
+// Security: bcrypt and PBKDF2 password hashes are carried over. Client secrets
+// are hashed immediately and never kept in plaintext.
+// Audited: No
+// Errors: ErrInvalidExport
+func ParseAuth0(users io.Reader, tenant io.Reader) (*Dataset, error) {
+	records, err := decodeAuth0Users(users)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dataset{Source: SourceAuth0}
+	for _, au := range records {
+		ref := au.UserID
+		if ref == "" {
+			ref = au.Email
+		}
+		if au.Email == "" {
+			d.issue(SeverityError, EntityUser, ref, "user has no email address")
+			continue
+		}
+
+		u := User{
+			SourceID:      au.UserID,
+			Email:         au.Email,
+			EmailVerified: au.EmailVerified,
+			Profile: user.Profile{
+				GivenName:  au.GivenName,
+				FamilyName: au.FamilyName,
+				FullName:   au.Name,
+				Nickname:   au.Nickname,
+				Picture:    au.Picture,
+			},
+			Disabled: au.Blocked,
+			Roles:    slices.Concat(au.Roles, au.AppMetadata.Roles),
+		}
+
+		hash, err := auth0PasswordHash(au)
+		if err != nil {
+			d.issue(SeverityWarning, EntityUser, ref, fmt.Sprintf("password not imported: %v", err))
+		}
+		u.PasswordHash = hash
+
+		d.Users = append(d.Users, u)
+	}
+
+	if tenant == nil {
+		return d, nil
+	}
+
+	var t auth0Tenant
+	if err := json.NewDecoder(tenant).Decode(&t); err != nil {
+		return nil, fmt.Errorf("%w: tenant export: %v", ErrInvalidExport, err)
+	}
+	for _, r := range t.Roles {
+		d.Roles = append(d.Roles, Role{Name: r.Name, Description: r.Description})
+	}
+	for _, ac := range t.Clients {
+		d.Clients = append(d.Clients, auth0ClientToClient(d, ac))
+	}
+	return d, nil
+}
+
+// decodeAuth0Users accepts either a JSON array or newline-delimited JSON.
+func decodeAuth0Users(r io.Reader) ([]auth0User, error) {
+	br := bufio.NewReader(r)
+	first, err := firstNonSpace(br)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+
+	var records []auth0User
+	if first == '[' {
+		if err := json.NewDecoder(br).Decode(&records); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+		}
+		return records, nil
+	}
+
+	dec := json.NewDecoder(br)
+	for {
+		var u auth0User
+		err := dec.Decode(&u)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: record %d: %v", ErrInvalidExport, len(records)+1, err)
+		}
+		records = append(records, u)
+	}
+}
+
+func firstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
+
+// auth0PasswordHash extracts a verifiable password hash from an Auth0 record.
+func auth0PasswordHash(au auth0User) (string, error) {
+	hash := au.PasswordHash
+	if hash == "" {
+		hash = au.PasswordHashExport
+	}
+	if hash == "" && au.CustomPasswordHash != nil {
+		if au.CustomPasswordHash.Algorithm != "bcrypt" && au.CustomPasswordHash.Algorithm != "pbkdf2" {
+			return "", fmt.Errorf("unsupported algorithm %q", au.CustomPasswordHash.Algorithm)
+		}
+		hash = au.CustomPasswordHash.Hash.Value
+	}
+	if hash == "" || strings.HasPrefix(hash, "$2") {
+		return hash, nil
+	}
+	if strings.HasPrefix(hash, "$pbkdf2") {
+		return convertPHCPBKDF2(hash)
+	}
+	return "", fmt.Errorf("unsupported hash format")
+}
+
+// convertPHCPBKDF2 rewrites a PHC PBKDF2 string ($pbkdf2-sha512$i=N,l=L$salt$hash)
+// into the user package format ($pbkdf2-sha512$i=N$salt$hash).
+func convertPHCPBKDF2(phc string) (string, error) {
+	sections := strings.Split(phc, "$")
+	if len(sections) != 5 {
+		return "", fmt.Errorf("malformed PBKDF2 hash")
+	}
+
+	var iterations int
+	for _, param := range strings.Split(sections[2], ",") {
+		if v, ok := strings.CutPrefix(param, "i="); ok {
+			if _, err := fmt.Sscanf(v, "%d", &iterations); err != nil {
+				return "", fmt.Errorf("malformed PBKDF2 iterations")
+			}
+		}
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(sections[3], "="))
+	if err != nil {
+		return "", fmt.Errorf("malformed PBKDF2 salt")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(sections[4], "="))
+	if err != nil {
+		return "", fmt.Errorf("malformed PBKDF2 hash value")
+	}
+	return user.FormatPBKDF2Hash(sections[1], iterations, salt, key), nil
+}
+
+// auth0ClientToClient maps an Auth0 application, recording anything that cannot be carried over.
+func auth0ClientToClient(d *Dataset, ac auth0Client) *client.Client {
+	ref := ac.ClientID
+	if ref == "" {
+		ref = ac.Name
+		d.issue(SeverityWarning, EntityClient, ref, "no client_id in the export; a new one is generated on import")
+	}
+
+	c := &client.Client{
+		ClientID:      ac.ClientID,
+		ClientName:    ac.Name,
+		ClientURI:     ac.InitiateLoginURI,
+		AllowedScopes: []string{client.ScopeOpenID, client.ScopeProfile, client.ScopeEmail},
+		IsActive:      true,
+	}
+
+	for _, gt := range ac.GrantTypes {
+		if !auth0GrantTypes[gt] {
+			d.issue(SeverityWarning, EntityClient, ref, fmt.Sprintf("grant type %q is not supported and was dropped", gt))
+			continue
+		}
+		c.GrantTypes = append(c.GrantTypes, gt)
+		if gt == "authorization_code" {
+			c.ResponseTypes = []string{"code"}
+		}
+	}
+
+	for _, uri := range ac.Callbacks {
+		if strings.Contains(uri, "*") {
+			d.issue(SeverityWarning, EntityClient, ref, fmt.Sprintf("wildcard redirect URI %q dropped; register exact URIs", uri))
+			continue
+		}
+		c.RedirectURIs = append(c.RedirectURIs, uri)
+	}
+
+	switch ac.AppType {
+	case "spa":
+		c.ApplicationType = client.ApplicationTypeSPA
+	case "native":
+		c.ApplicationType = client.ApplicationTypeNative
+	default:
+		c.ApplicationType = client.ApplicationTypeWeb
+	}
+
+	public := ac.AppType == "spa" || ac.AppType == "native" || ac.TokenEndpointAuthMethod == client.AuthMethodNone
+	switch {
+	case public:
+		c.TokenEndpointAuthMethod = client.AuthMethodNone
+	case ac.TokenEndpointAuthMethod == client.AuthMethodClientSecretPost:
+		c.TokenEndpointAuthMethod = client.AuthMethodClientSecretPost
+	default:
+		c.TokenEndpointAuthMethod = client.AuthMethodClientSecretBasic
+	}
+	if !public {
+		if ac.ClientSecret == "" {
+			d.issue(SeverityWarning, EntityClient, ref, "confidential client has no secret in the export; rotate its secret after import")
+		} else {
+			c.ClientSecretHash = client.HashClientSecret(ac.ClientSecret)
+		}
+	}
+	return c
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package importer migrates identities from other identity providers into
+// OpenTrusty. Parsers normalize a Keycloak realm export or an Auth0 export
+// into a Dataset; Service validates a Dataset against a tenant and imports
+// it, or reports what it would do in dry-run mode.
+package importer
+
+import (
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// Domain errors
+var (
+	ErrInvalidExport  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid export file")
+	ErrInvalidOptions = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid import options")
+)
+
+// Export sources
+const (
+	SourceKeycloak = "keycloak"
+	SourceAuth0    = "auth0"
+)
+
+// Issue severities
+const (
+	// SeverityError marks a record that will not be imported.
+	SeverityError = "error"
+	// SeverityWarning marks a record that is imported with some data dropped.
+	SeverityWarning = "warning"
+)
+
+// Entity kinds
+const (
+	EntityUser   = "user"
+	EntityClient = "client"
+	EntityRole   = "role"
+)
+
+// Issue is a problem found while parsing or validating a record.
+type Issue struct {
+	Severity string `json:"severity"`
+	Entity   string `json:"entity"`
+	Ref      string `json:"ref"`
+	Message  string `json:"message"`
+}
+
+// User is an identity read from an export.
+//
+// Purpose: Source-neutral user record.
+// Domain: Identity
+// Invariants: PasswordHash is empty or in a format user.PasswordHasher verifies.
+type User struct {
+	SourceID      string
+	Email         string
+	EmailVerified bool
+	Profile       user.Profile
+	PasswordHash  string
+	Disabled      bool
+	// Roles are source role names; Options.RoleMapping translates them.
+	Roles []string
+}
+
+// Role is a role defined in the source system.
+type Role struct {
+	Name        string
+	Description string
+}
+
+// Dataset is the normalized content of one export.
+//
+// Purpose: Common input to validation and import regardless of source.
+// Domain: Identity
+// Invariants: Client secrets are held only as hashes.
+type Dataset struct {
+	Source  string
+	Users   []User
+	Clients []*client.Client
+	Roles   []Role
+	// Issues found while parsing. Records they refer to may be missing or partial.
+	Issues []Issue
+}
+
+func (d *Dataset) issue(severity, entity, ref, message string) {
+	d.Issues = append(d.Issues, Issue{Severity: severity, Entity: entity, Ref: ref, Message: message})
+}
+
+// Report summarizes a validation or import run.
+type Report struct {
+	Source         string  `json:"source"`
+	DryRun         bool    `json:"dry_run"`
+	UsersCreated   int     `json:"users_created"`
+	UsersSkipped   int     `json:"users_skipped"`
+	ClientsCreated int     `json:"clients_created"`
+	ClientsSkipped int     `json:"clients_skipped"`
+	RolesAssigned  int     `json:"roles_assigned"`
+	Issues         []Issue `json:"issues"`
+}
+
+// HasErrors reports whether any record was rejected.
+func (r *Report) HasErrors() bool {
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Report) issue(severity, entity, ref, message string) {
+	r.Issues = append(r.Issues, Issue{Severity: severity, Entity: entity, Ref: ref, Message: message})
+}
@@ -0,0 +1,400 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"context"
+	stdpbkdf2 "crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/user"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "migrated-password"
+
+type mockUserRepo struct {
+	user.UserRepository
+	users map[string]*user.User
+	creds map[string]*user.Credentials
+}
+
+func (m *mockUserRepo) Create(ctx context.Context, u *user.User) error {
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *mockUserRepo) AddCredentials(ctx context.Context, c *user.Credentials) error {
+	m.creds[c.UserID] = c
+	return nil
+}
+
+func (m *mockUserRepo) GetByID(ctx context.Context, id string) (*user.User, error) {
+	if u, ok := m.users[id]; ok {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (m *mockUserRepo) GetByHash(ctx context.Context, hash string) (*user.User, error) {
+	for _, u := range m.users {
+		if u.EmailHash == hash {
+			return u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (m *mockUserRepo) GetCredentials(ctx context.Context, userID string) (*user.Credentials, error) {
+	if c, ok := m.creds[userID]; ok {
+		return c, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (m *mockUserRepo) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	m.creds[userID].PasswordHash = passwordHash
+	return nil
+}
+
+func (m *mockUserRepo) RehashPassword(ctx context.Context, userID, passwordHash string) error {
+	m.creds[userID].PasswordHash = passwordHash
+	return nil
+}
+
+func (m *mockUserRepo) UpdateLockout(ctx context.Context, userID string, failedAttempts int, lockedUntil *time.Time) error {
+	return nil
+}
+
+type mockTenantRepo struct {
+	tenant.Repository
+}
+
+func (m *mockTenantRepo) GetByID(ctx context.Context, id string) (*tenant.Tenant, error) {
+	if id != "tenant-1" {
+		return nil, tenant.ErrTenantNotFound
+	}
+	return &tenant.Tenant{ID: id, Name: "Acme"}, nil
+}
+
+type mockTenantRoleRepo struct {
+	tenant.RoleRepository
+	assigned []string
+}
+
+func (m *mockTenantRoleRepo) AssignRole(ctx context.Context, tenantID, userID, roleName, grantedBy string) error {
+	m.assigned = append(m.assigned, userID+"/"+roleName)
+	return nil
+}
+
+type mockClientRepo struct {
+	client.ClientRepository
+	clients map[string]*client.Client
+}
+
+func (m *mockClientRepo) GetByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error) {
+	if c, ok := m.clients[tenantID+"/"+clientID]; ok {
+		return c, nil
+	}
+	return nil, client.ErrClientNotFound
+}
+
+func (m *mockClientRepo) Create(ctx context.Context, c *client.Client) error {
+	m.clients[c.TenantID+"/"+c.ClientID] = c
+	return nil
+}
+
+type nopAuditLogger struct{}
+
+func (nopAuditLogger) Log(ctx context.Context, e audit.Event) {}
+
+type fixture struct {
+	svc      *Service
+	users    *user.Service
+	userRepo *mockUserRepo
+	roles    *mockTenantRoleRepo
+	clients  *mockClientRepo
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		userRepo: &mockUserRepo{users: map[string]*user.User{}, creds: map[string]*user.Credentials{}},
+		roles:    &mockTenantRoleRepo{},
+		clients:  &mockClientRepo{clients: map[string]*client.Client{}},
+	}
+	f.users = user.NewService(f.userRepo, user.NewPasswordHasher(1024, 1, 1, 16, 32), nopAuditLogger{}, 5, time.Hour, "test-key")
+	tenants := tenant.NewService(&mockTenantRepo{}, f.roles, nil, f.users, f.clients, nil, nopAuditLogger{})
+	clients := client.NewService(f.clients, nopAuditLogger{})
+	f.svc = NewService(f.users, tenants, clients)
+	return f
+}
+
+func keycloakExport(t *testing.T) string {
+	t.Helper()
+	salt := []byte("keycloak-salt-16")
+	key, err := stdpbkdf2.Key(sha256.New, testPassword, salt, 27500, 64)
+	if err != nil {
+		t.Fatalf("failed to derive key: %v", err)
+	}
+	secretData := fmt.Sprintf(`{"value":"%s","salt":"%s","additionalParameters":{}}`,
+		base64.StdEncoding.EncodeToString(key), base64.StdEncoding.EncodeToString(salt))
+
+	return fmt.Sprintf(`{
+  "realm": "Demo",
+  "roles": {"realm": [
+    {"name": "admin", "description": "Administrators"},
+    {"name": "auditor"},
+    {"name": "offline_access"},
+    {"name": "default-roles-demo"}
+  ]},
+  "users": [
+    {
+      "id": "kc-1", "username": "alice", "email": "alice@example.com", "emailVerified": true,
+      "firstName": "Alice", "lastName": "Liddell", "enabled": true,
+      "attributes": {"locale": ["en"]},
+      "realmRoles": ["admin", "default-roles-demo"],
+      "credentials": [
+        {"type": "password", "secretData": %q, "credentialData": "{\"hashIterations\":27500,\"algorithm\":\"pbkdf2-sha256\",\"additionalParameters\":{}}"},
+        {"type": "otp", "secretData": "{}", "credentialData": "{}"}
+      ]
+    },
+    {"id": "kc-2", "username": "bob", "email": "bob@example.com", "enabled": false},
+    {"id": "kc-3", "username": "carol", "email": "carol@example.com",
+      "credentials": [{"type": "password", "secretData": "{}", "credentialData": "{\"algorithm\":\"argon2\"}"}]},
+    {"id": "kc-4", "username": "nomail"}
+  ],
+  "clients": [
+    {"clientId": "account"},
+    {"clientId": "web", "name": "Web App", "secret": "s3cret", "standardFlowEnabled": true,
+      "serviceAccountsEnabled": true, "directAccessGrantsEnabled": true,
+      "redirectUris": ["https://app.example.com/callback", "https://app.example.com/*"]},
+    {"clientId": "spa", "publicClient": true, "standardFlowEnabled": true, "serviceAccountsEnabled": true,
+      "redirectUris": ["https://spa.example.com/cb"]},
+    {"clientId": "api", "bearerOnly": true}
+  ]
+}`, secretData)
+}
+
+func hasIssue(issues []Issue, severity, ref, fragment string) bool {
+	for _, i := range issues {
+		if i.Severity == severity && i.Ref == ref && strings.Contains(i.Message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestParseKeycloak(t *testing.T) {
+	d, err := ParseKeycloak(strings.NewReader(keycloakExport(t)))
+	if err != nil {
+		t.Fatalf("ParseKeycloak() error = %v", err)
+	}
+
+	if len(d.Roles) != 2 {
+		t.Errorf("expected built-in roles to be dropped, got %+v", d.Roles)
+	}
+	if len(d.Users) != 3 {
+		t.Fatalf("expected 3 users, got %d", len(d.Users))
+	}
+
+	alice := d.Users[0]
+	if !strings.HasPrefix(alice.PasswordHash, "$pbkdf2-sha256$i=27500$") {
+		t.Errorf("unexpected password hash %q", alice.PasswordHash)
+	}
+	if alice.Profile.FullName != "Alice Liddell" || alice.Profile.Locale != "en" || !alice.EmailVerified {
+		t.Errorf("unexpected profile %+v", alice)
+	}
+	if len(alice.Roles) != 1 || alice.Roles[0] != "admin" {
+		t.Errorf("unexpected roles %v", alice.Roles)
+	}
+	if !d.Users[1].Disabled {
+		t.Error("expected bob to be disabled")
+	}
+	if d.Users[2].PasswordHash != "" {
+		t.Error("expected unsupported hash to be dropped")
+	}
+
+	if len(d.Clients) != 2 {
+		t.Fatalf("expected 2 clients, got %d", len(d.Clients))
+	}
+	web := d.Clients[0]
+	if web.ClientSecretHash != client.HashClientSecret("s3cret") || web.TokenEndpointAuthMethod != "client_secret_basic" {
+		t.Errorf("unexpected confidential client %+v", web)
+	}
+	if len(web.RedirectURIs) != 1 || len(web.GrantTypes) != 3 {
+		t.Errorf("unexpected web client mapping: uris=%v grants=%v", web.RedirectURIs, web.GrantTypes)
+	}
+	spa := d.Clients[1]
+	if spa.TokenEndpointAuthMethod != "none" || spa.ClientSecretHash != "" || len(spa.GrantTypes) != 2 {
+		t.Errorf("unexpected public client %+v", spa)
+	}
+
+	for _, want := range []struct{ severity, ref, fragment string }{
+		{SeverityError, "nomail", "no email"},
+		{SeverityWarning, "alice", `"otp"`},
+		{SeverityWarning, "carol", "argon2"},
+		{SeverityWarning, "web", "wildcard"},
+		{SeverityWarning, "web", "password grant"},
+		{SeverityWarning, "api", "bearer-only"},
+	} {
+		if !hasIssue(d.Issues, want.severity, want.ref, want.fragment) {
+			t.Errorf("missing %s issue for %s containing %q in %+v", want.severity, want.ref, want.fragment, d.Issues)
+		}
+	}
+}
+
+func TestParseAuth0(t *testing.T) {
+	bcryptHash, _ := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	salt := []byte("auth0-salt-bytes")
+	key, _ := stdpbkdf2.Key(sha256.New, testPassword, salt, 1000, 32)
+	phc := fmt.Sprintf("$pbkdf2-sha256$i=1000,l=32$%s$%s",
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+
+	users := fmt.Sprintf(`{"user_id":"auth0|1","email":"dave@example.com","email_verified":true,"name":"Dave","passwordHash":%q,"app_metadata":{"roles":["editor"]}}
+{"user_id":"auth0|2","email":"erin@example.com","custom_password_hash":{"algorithm":"pbkdf2","hash":{"value":%q}}}
+{"user_id":"auth0|3","email":"frank@example.com","blocked":true,"custom_password_hash":{"algorithm":"md5","hash":{"value":"x"}}}
+`, bcryptHash, phc)
+	tenantExport := `{
+  "roles": [{"name": "editor", "description": "Editors"}],
+  "clients": [
+    {"name": "Dashboard", "client_id": "dash", "client_secret": "abc", "app_type": "regular_web",
+      "callbacks": ["https://dash.example.com/cb"], "grant_types": ["authorization_code", "password", "refresh_token"]},
+    {"name": "Mobile", "client_id": "mobile", "app_type": "native", "callbacks": ["com.example.app://cb"]}
+  ]
+}`
+
+	d, err := ParseAuth0(strings.NewReader(users), strings.NewReader(tenantExport))
+	if err != nil {
+		t.Fatalf("ParseAuth0() error = %v", err)
+	}
+	if len(d.Users) != 3 || len(d.Clients) != 2 || len(d.Roles) != 1 {
+		t.Fatalf("unexpected dataset sizes: users=%d clients=%d roles=%d", len(d.Users), len(d.Clients), len(d.Roles))
+	}
+	if d.Users[0].PasswordHash != string(bcryptHash) || d.Users[0].Roles[0] != "editor" {
+		t.Errorf("unexpected first user %+v", d.Users[0])
+	}
+	if !strings.HasPrefix(d.Users[1].PasswordHash, "$pbkdf2-sha256$i=1000$") {
+		t.Errorf("expected converted PBKDF2 hash, got %q", d.Users[1].PasswordHash)
+	}
+	if !d.Users[2].Disabled || d.Users[2].PasswordHash != "" {
+		t.Errorf("unexpected third user %+v", d.Users[2])
+	}
+	if len(d.Clients[0].GrantTypes) != 2 || d.Clients[1].TokenEndpointAuthMethod != "none" || d.Clients[1].ApplicationType != client.ApplicationTypeNative {
+		t.Errorf("unexpected client mapping %+v %+v", d.Clients[0], d.Clients[1])
+	}
+	if !hasIssue(d.Issues, SeverityWarning, "dash", `"password"`) || !hasIssue(d.Issues, SeverityWarning, "auth0|3", "md5") {
+		t.Errorf("missing issues in %+v", d.Issues)
+	}
+
+	// The bulk import format is a JSON array.
+	d, err = ParseAuth0(strings.NewReader(` [{"email":"gina@example.com"}]`), nil)
+	if err != nil || len(d.Users) != 1 {
+		t.Errorf("ParseAuth0(array) = %v, %v", d, err)
+	}
+
+	if _, err := ParseAuth0(strings.NewReader(`{"email":`), nil); !errors.Is(err, ErrInvalidExport) {
+		t.Errorf("expected ErrInvalidExport, got %v", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{
+		TenantID:    "tenant-1",
+		ActorID:     "admin-1",
+		RoleMapping: map[string]string{"admin": role.RoleTenantAdmin},
+	}
+
+	d, err := ParseKeycloak(strings.NewReader(keycloakExport(t)))
+	if err != nil {
+		t.Fatalf("ParseKeycloak() error = %v", err)
+	}
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		f := newFixture()
+		dry := opts
+		dry.DryRun = true
+		report, err := f.svc.Import(ctx, d, dry)
+		if err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+		if report.UsersCreated != 2 || report.UsersSkipped != 1 || report.ClientsCreated != 2 || report.RolesAssigned != 3 {
+			t.Errorf("unexpected report %+v", report)
+		}
+		if !report.HasErrors() || !hasIssue(report.Issues, SeverityWarning, "auditor", "not mapped") {
+			t.Errorf("unexpected issues %+v", report.Issues)
+		}
+		if len(f.userRepo.users) != 0 || len(f.clients.clients) != 0 || len(f.roles.assigned) != 0 {
+			t.Error("dry run must not write")
+		}
+	})
+
+	t.Run("import and rerun", func(t *testing.T) {
+		f := newFixture()
+		report, err := f.svc.Import(ctx, d, opts)
+		if err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+		if report.UsersCreated != 2 || report.ClientsCreated != 2 || report.RolesAssigned != 3 {
+			t.Errorf("unexpected report %+v", report)
+		}
+		if len(f.roles.assigned) != 3 {
+			t.Errorf("unexpected assignments %v", f.roles.assigned)
+		}
+
+		// The migrated password works and is upgraded on first login.
+		u, err := f.users.Authenticate(ctx, "alice@example.com", testPassword)
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if !strings.HasPrefix(f.userRepo.creds[u.ID].PasswordHash, "$argon2id$") {
+			t.Errorf("expected hash upgrade, got %q", f.userRepo.creds[u.ID].PasswordHash)
+		}
+
+		rerun, err := f.svc.Import(ctx, d, opts)
+		if err != nil {
+			t.Fatalf("second Import() error = %v", err)
+		}
+		if rerun.UsersCreated != 0 || rerun.ClientsCreated != 0 || rerun.ClientsSkipped != 2 {
+			t.Errorf("expected rerun to skip existing records, got %+v", rerun)
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		f := newFixture()
+		for _, bad := range []Options{
+			{},
+			{TenantID: "tenant-1", DefaultRole: role.RoleTenantOwner},
+			{TenantID: "tenant-1", RoleMapping: map[string]string{"admin": role.RolePlatformAdmin}},
+		} {
+			if _, err := f.svc.Import(ctx, d, bad); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Import(%+v) error = %v, want ErrInvalidOptions", bad, err)
+			}
+		}
+		if _, err := f.svc.Import(ctx, d, Options{TenantID: "missing"}); !errors.Is(err, tenant.ErrTenantNotFound) {
+			t.Errorf("expected ErrTenantNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// keycloakBuiltinClients are created by Keycloak in every realm and have no
+// OpenTrusty counterpart.
+var keycloakBuiltinClients = []string{
+	"account", "account-console", "admin-cli", "broker", "realm-management", "security-admin-console",
+}
+
+// keycloakBuiltinRoles are implicit Keycloak roles; "default-roles-<realm>" is handled separately.
+var keycloakBuiltinRoles = []string{"offline_access", "uma_authorization"}
+
+// keycloakHashSchemes maps Keycloak password hash algorithms to user package schemes.
+var keycloakHashSchemes = map[string]string{
+	"pbkdf2":        user.SchemePBKDF2SHA1,
+	"pbkdf2-sha256": user.SchemePBKDF2SHA256,
+	"pbkdf2-sha512": user.SchemePBKDF2SHA512,
+}
+
+type keycloakRealm struct {
+	Realm   string           `json:"realm"`
+	Users   []keycloakUser   `json:"users"`
+	Clients []keycloakClient `json:"clients"`
+	Roles   struct {
+		Realm []keycloakRole `json:"realm"`
+	} `json:"roles"`
+}
+
+type keycloakUser struct {
+	ID            string               `json:"id"`
+	Username      string               `json:"username"`
+	Email         string               `json:"email"`
+	EmailVerified bool                 `json:"emailVerified"`
+	FirstName     string               `json:"firstName"`
+	LastName      string               `json:"lastName"`
+	Enabled       *bool                `json:"enabled"`
+	Credentials   []keycloakCredential `json:"credentials"`
+	RealmRoles    []string             `json:"realmRoles"`
+	Attributes    map[string][]string  `json:"attributes"`
+}
+
+type keycloakCredential struct {
+	Type           string `json:"type"`
+	SecretData     string `json:"secretData"`
+	CredentialData string `json:"credentialData"`
+}
+
+type keycloakClient struct {
+	ClientID                  string   `json:"clientId"`
+	Name                      string   `json:"name"`
+	Secret                    string   `json:"secret"`
+	Enabled                   *bool    `json:"enabled"`
+	PublicClient              bool     `json:"publicClient"`
+	BearerOnly                bool     `json:"bearerOnly"`
+	RootURL                   string   `json:"rootUrl"`
+	RedirectURIs              []string `json:"redirectUris"`
+	StandardFlowEnabled       bool     `json:"standardFlowEnabled"`
+	ImplicitFlowEnabled       bool     `json:"implicitFlowEnabled"`
+	DirectAccessGrantsEnabled bool     `json:"directAccessGrantsEnabled"`
+	ServiceAccountsEnabled    bool     `json:"serviceAccountsEnabled"`
+}
+
+type keycloakRole struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ParseKeycloak reads a Keycloak realm export (realm-export.json, or the
+// output of kc.sh export with users in the same file).
+//
+// Purpose: Normalizes a Keycloak realm into a Dataset.
+// Domain: Identity
+// Security: PBKDF2 password hashes are carried over; other credential types are dropped.
+// Client secrets are hashed immediately and never kept in plaintext.
+// Audited: No
+// Errors: ErrInvalidExport
+func ParseKeycloak(r io.Reader) (*Dataset, error) {
+	var realm keycloakRealm
+	if err := json.NewDecoder(r).Decode(&realm); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+
+	d := &Dataset{Source: SourceKeycloak}
+	defaultRoles := "default-roles-" + strings.ToLower(realm.Realm)
+
+	for _, kr := range realm.Roles.Realm {
+		if slices.Contains(keycloakBuiltinRoles, kr.Name) || kr.Name == defaultRoles {
+			continue
+		}
+		d.Roles = append(d.Roles, Role{Name: kr.Name, Description: kr.Description})
+	}
+
+	for _, ku := range realm.Users {
+		ref := ku.Username
+		if ref == "" {
+			ref = ku.ID
+		}
+		if ku.Email == "" {
+			d.issue(SeverityError, EntityUser, ref, "user has no email address")
+			continue
+		}
+
+		u := User{
+			SourceID:      ku.ID,
+			Email:         ku.Email,
+			EmailVerified: ku.EmailVerified,
+			Profile: user.Profile{
+				GivenName:  ku.FirstName,
+				FamilyName: ku.LastName,
+				FullName:   strings.TrimSpace(ku.FirstName + " " + ku.LastName),
+				Nickname:   ku.Username,
+				Locale:     firstAttribute(ku.Attributes, "locale"),
+			},
+			Disabled: ku.Enabled != nil && !*ku.Enabled,
+		}
+		for _, role := range ku.RealmRoles {
+			if !slices.Contains(keycloakBuiltinRoles, role) && role != defaultRoles {
+				u.Roles = append(u.Roles, role)
+			}
+		}
+
+		for _, cred := range ku.Credentials {
+			if cred.Type != "password" {
+				d.issue(SeverityWarning, EntityUser, ref, fmt.Sprintf("credential type %q is not imported", cred.Type))
+				continue
+			}
+			hash, err := keycloakPasswordHash(cred)
+			if err != nil {
+				d.issue(SeverityWarning, EntityUser, ref, fmt.Sprintf("password not imported: %v", err))
+				continue
+			}
+			u.PasswordHash = hash
+		}
+
+		d.Users = append(d.Users, u)
+	}
+
+	for _, kc := range realm.Clients {
+		if slices.Contains(keycloakBuiltinClients, kc.ClientID) {
+			continue
+		}
+		if c := keycloakClientToClient(d, kc); c != nil {
+			d.Clients = append(d.Clients, c)
+		}
+	}
+
+	return d, nil
+}
+
+// keycloakPasswordHash converts a Keycloak password credential to a PBKDF2 hash string.
+func keycloakPasswordHash(cred keycloakCredential) (string, error) {
+	var secret struct {
+		Value string `json:"value"`
+		Salt  string `json:"salt"`
+	}
+	var params struct {
+		HashIterations int    `json:"hashIterations"`
+		Algorithm      string `json:"algorithm"`
+	}
+	if err := json.Unmarshal([]byte(cred.SecretData), &secret); err != nil {
+		return "", fmt.Errorf("malformed secretData")
+	}
+	if err := json.Unmarshal([]byte(cred.CredentialData), &params); err != nil {
+		return "", fmt.Errorf("malformed credentialData")
+	}
+
+	scheme, ok := keycloakHashSchemes[params.Algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm %q", params.Algorithm)
+	}
+	key, err := base64.StdEncoding.DecodeString(secret.Value)
+	if err != nil {
+		return "", fmt.Errorf("malformed hash value")
+	}
+	salt, err := base64.StdEncoding.DecodeString(secret.Salt)
+	if err != nil {
+		return "", fmt.Errorf("malformed salt")
+	}
+	return user.FormatPBKDF2Hash(scheme, params.HashIterations, salt, key), nil
+}
+
+// keycloakClientToClient maps a Keycloak client, recording anything that cannot be carried over.
+func keycloakClientToClient(d *Dataset, kc keycloakClient) *client.Client {
+	ref := kc.ClientID
+	if kc.BearerOnly {
+		d.issue(SeverityWarning, EntityClient, ref, "bearer-only client skipped; resource servers are not clients in OpenTrusty")
+		return nil
+	}
+
+	c := &client.Client{
+		ClientID:      kc.ClientID,
+		ClientName:    kc.Name,
+		ClientURI:     kc.RootURL,
+		AllowedScopes: []string{client.ScopeOpenID, client.ScopeProfile, client.ScopeEmail},
+		IsActive:      kc.Enabled == nil || *kc.Enabled,
+	}
+	if c.ClientName == "" {
+		c.ClientName = kc.ClientID
+	}
+
+	if kc.StandardFlowEnabled {
+		c.GrantTypes = append(c.GrantTypes, "authorization_code", "refresh_token")
+		c.ResponseTypes = []string{"code"}
+	}
+	if kc.ServiceAccountsEnabled && !kc.PublicClient {
+		c.GrantTypes = append(c.GrantTypes, "client_credentials")
+	}
+	if kc.ImplicitFlowEnabled {
+		d.issue(SeverityWarning, EntityClient, ref, "implicit flow is not supported and was dropped")
+	}
+	if kc.DirectAccessGrantsEnabled {
+		d.issue(SeverityWarning, EntityClient, ref, "direct access grants (password grant) are not supported and were dropped")
+	}
+
+	for _, uri := range kc.RedirectURIs {
+		if strings.Contains(uri, "*") {
+			d.issue(SeverityWarning, EntityClient, ref, fmt.Sprintf("wildcard redirect URI %q dropped; register exact URIs", uri))
+			continue
+		}
+		c.RedirectURIs = append(c.RedirectURIs, uri)
+	}
+
+	if kc.PublicClient {
+		c.TokenEndpointAuthMethod = client.AuthMethodNone
+	} else {
+		c.TokenEndpointAuthMethod = client.AuthMethodClientSecretBasic
+		if kc.Secret == "" {
+			d.issue(SeverityWarning, EntityClient, ref, "confidential client has no secret in the export; rotate its secret after import")
+		} else {
+			c.ClientSecretHash = client.HashClientSecret(kc.Secret)
+		}
+	}
+	return c
+}
+
+func firstAttribute(attrs map[string][]string, name string) string {
+	if values := attrs[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
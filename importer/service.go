@@ -0,0 +1,238 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// importableRoles are the tenant roles source roles may be mapped to. The
+// tenant owner is excluded: a tenant has exactly one, provisioned at creation.
+var importableRoles = []string{role.RoleTenantAdmin, role.RoleTenantMember}
+
+// Options controls how a Dataset is imported.
+type Options struct {
+	// TenantID is the tenant that receives the users and clients.
+	TenantID string
+	// ActorID is recorded as the actor in audit events.
+	ActorID string
+	// RoleMapping translates source role names to tenant roles. Unmapped
+	// source roles are reported and their assignments dropped.
+	RoleMapping map[string]string
+	// DefaultRole is assigned to every imported user. Defaults to tenant_member.
+	DefaultRole string
+	// DryRun validates and reports without writing anything.
+	DryRun bool
+}
+
+// Service imports normalized datasets.
+//
+// Purpose: Migration of users, clients, and role assignments into a tenant.
+// Domain: Identity
+type Service struct {
+	users   *user.Service
+	tenants *tenant.Service
+	clients *client.Service
+}
+
+// NewService creates a new import service.
+//
+// Purpose: Constructor for the import service.
+// Domain: Identity
+// Audited: No
+// Errors: None
+func NewService(users *user.Service, tenants *tenant.Service, clients *client.Service) *Service {
+	return &Service{users: users, tenants: tenants, clients: clients}
+}
+
+// Import validates d against the target tenant and, unless opts.DryRun is
+// set, creates its users, clients, and role assignments.
+//
+// Purpose: Idempotent migration from another identity provider.
+// Domain: Identity
+// Security: Existing users and clients are never modified; they are skipped and reported.
+// Password hashes are stored as exported and upgraded to Argon2id on next login.
+// Audited: Yes (UserCreated, ClientCreated, RoleAssigned per record)
+// Errors: ErrInvalidOptions, tenant.ErrTenantNotFound, System errors. Per-record
+// failures are reported in the Report, not returned.
+func (s *Service) Import(ctx context.Context, d *Dataset, opts Options) (*Report, error) {
+	if err := s.checkOptions(&opts); err != nil {
+		return nil, err
+	}
+	if _, err := s.tenants.GetTenant(ctx, opts.TenantID); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Source: d.Source, DryRun: opts.DryRun}
+	report.Issues = append(report.Issues, d.Issues...)
+
+	for _, r := range d.Roles {
+		if _, ok := opts.RoleMapping[r.Name]; !ok {
+			report.issue(SeverityWarning, EntityRole, r.Name, "role is not mapped; its assignments are dropped")
+		}
+	}
+
+	seen := make(map[string]bool, len(d.Users))
+	for _, u := range d.Users {
+		key := strings.ToLower(strings.TrimSpace(u.Email))
+		if seen[key] {
+			report.issue(SeverityError, EntityUser, u.Email, "duplicate email address in export")
+			continue
+		}
+		seen[key] = true
+		if err := s.importUser(ctx, u, opts, report); err != nil {
+			return report, err
+		}
+	}
+
+	seenClients := make(map[string]bool, len(d.Clients))
+	for _, c := range d.Clients {
+		if c.ClientID != "" {
+			if seenClients[c.ClientID] {
+				report.issue(SeverityError, EntityClient, c.ClientID, "duplicate client_id in export")
+				continue
+			}
+			seenClients[c.ClientID] = true
+		}
+		if err := s.importClient(ctx, c, opts, report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func (s *Service) checkOptions(opts *Options) error {
+	if opts.TenantID == "" {
+		return fmt.Errorf("%w: tenant ID is required", ErrInvalidOptions)
+	}
+	if opts.DefaultRole == "" {
+		opts.DefaultRole = role.RoleTenantMember
+	}
+	if !slices.Contains(importableRoles, opts.DefaultRole) {
+		return fmt.Errorf("%w: default role %q cannot be imported", ErrInvalidOptions, opts.DefaultRole)
+	}
+	for source, target := range opts.RoleMapping {
+		if !slices.Contains(importableRoles, target) {
+			return fmt.Errorf("%w: role %q maps to %q, which cannot be imported", ErrInvalidOptions, source, target)
+		}
+	}
+	return nil
+}
+
+// importUser validates and imports one user. Only system errors are returned.
+func (s *Service) importUser(ctx context.Context, u User, opts Options, report *Report) error {
+	ref := u.Email
+	if u.Disabled {
+		report.issue(SeverityWarning, EntityUser, ref, "user is disabled in the source and was skipped")
+		report.UsersSkipped++
+		return nil
+	}
+	if _, err := s.users.NewIdentity(u.Email, u.Profile); err != nil {
+		report.issue(SeverityError, EntityUser, ref, err.Error())
+		return nil
+	}
+	if u.PasswordHash != "" {
+		if err := s.users.CheckPasswordHash(u.PasswordHash); err != nil {
+			report.issue(SeverityError, EntityUser, ref, err.Error())
+			return nil
+		}
+	}
+
+	_, err := s.users.GetByEmail(ctx, u.Email)
+	if err == nil {
+		report.issue(SeverityWarning, EntityUser, ref, "user already exists and was skipped")
+		report.UsersSkipped++
+		return nil
+	}
+	if !errors.Is(err, user.ErrUserNotFound) {
+		return fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	roles := []string{opts.DefaultRole}
+	for _, source := range u.Roles {
+		if target, ok := opts.RoleMapping[source]; ok && !slices.Contains(roles, target) {
+			roles = append(roles, target)
+		}
+	}
+
+	if opts.DryRun {
+		report.UsersCreated++
+		report.RolesAssigned += len(roles)
+		return nil
+	}
+
+	created, err := s.users.ImportIdentity(ctx, u.Email, u.Profile, u.EmailVerified, u.PasswordHash, opts.ActorID)
+	if err != nil {
+		report.issue(SeverityError, EntityUser, ref, err.Error())
+		return nil
+	}
+	report.UsersCreated++
+
+	for _, r := range roles {
+		if err := s.tenants.AssignRole(ctx, opts.TenantID, created.ID, r, opts.ActorID); err != nil {
+			report.issue(SeverityError, EntityUser, ref, fmt.Sprintf("failed to assign role %s: %v", r, err))
+			continue
+		}
+		report.RolesAssigned++
+	}
+	return nil
+}
+
+// importClient validates and imports one client. Only system errors are returned.
+func (s *Service) importClient(ctx context.Context, c *client.Client, opts Options, report *Report) error {
+	ref := c.ClientID
+	if ref == "" {
+		ref = c.ClientName
+	}
+	if err := s.clients.ValidateClient(ctx, c); err != nil {
+		report.issue(SeverityError, EntityClient, ref, err.Error())
+		return nil
+	}
+
+	if c.ClientID != "" {
+		_, err := s.clients.GetClientByClientID(ctx, opts.TenantID, c.ClientID)
+		if err == nil {
+			report.issue(SeverityWarning, EntityClient, ref, "client already exists and was skipped")
+			report.ClientsSkipped++
+			return nil
+		}
+		if !errors.Is(err, client.ErrClientNotFound) {
+			return fmt.Errorf("failed to look up client: %w", err)
+		}
+	}
+
+	if opts.DryRun {
+		report.ClientsCreated++
+		return nil
+	}
+
+	c.TenantID = opts.TenantID
+	if _, err := s.clients.RegisterClient(ctx, opts.TenantID, opts.ActorID, c); err != nil {
+		report.issue(SeverityError, EntityClient, ref, err.Error())
+		return nil
+	}
+	report.ClientsCreated++
+	return nil
+}
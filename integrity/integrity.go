@@ -0,0 +1,135 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package integrity finds records left inconsistent by soft deletes and
+// unconstrained references: role assignments scoped to deleted tenants or
+// clients, memberships of deleted users, live tokens of deleted clients, and
+// unused authorization codes of deleted users. A scheduled check reports them
+// and, when enabled, repairs them with an audit trail.
+package integrity
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrUnknownKind = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "unknown integrity check")
+)
+
+// ActorIntegrityCheck is the audit actor of automatic repairs.
+const ActorIntegrityCheck = "system:integrity"
+
+// Kind names one class of inconsistency.
+type Kind string
+
+// Kinds
+const (
+	// KindOrphanedAssignment is a role assignment whose user, tenant, or client was deleted.
+	KindOrphanedAssignment Kind = "orphaned_assignment"
+	// KindOrphanedMembership is a tenant membership whose user or tenant was deleted.
+	KindOrphanedMembership Kind = "orphaned_membership"
+	// KindTokenOfDeletedClient is an unrevoked access or refresh token of a deleted client.
+	KindTokenOfDeletedClient Kind = "token_of_deleted_client"
+	// KindCodeOfDeletedUser is an unused authorization code of a deleted user.
+	KindCodeOfDeletedUser Kind = "code_of_deleted_user"
+)
+
+// Kinds returns every check, in the order they run.
+func Kinds() []Kind {
+	return []Kind{KindOrphanedAssignment, KindOrphanedMembership, KindTokenOfDeletedClient, KindCodeOfDeletedUser}
+}
+
+// IsKnown reports whether k is a defined check.
+func IsKnown(k Kind) bool {
+	for _, known := range Kinds() {
+		if k == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Finding is one inconsistent record.
+//
+// Purpose: Identifies a record for review or repair.
+// Domain: Platform
+// Invariants: Carries identifiers only, never PII or secrets.
+type Finding struct {
+	Kind     Kind   `json:"kind"`
+	Table    string `json:"table"`
+	RecordID string `json:"record_id"`
+	// Reference is the identifier of the missing or deleted parent record.
+	Reference string `json:"reference"`
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Kind Kind `json:"kind"`
+	// Total is the number of inconsistent records found.
+	Total int `json:"total"`
+	// Sample holds up to the service's sample limit of the findings.
+	Sample []Finding `json:"sample,omitempty"`
+	// Repaired is the number of records repaired, or 0 when repair did not run.
+	Repaired int64 `json:"repaired"`
+}
+
+// Report is the outcome of an integrity run.
+//
+// Purpose: Reviewable summary of every check.
+// Domain: Platform
+// Invariants: Results follow the order of Kinds.
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Results   []Result  `json:"results"`
+}
+
+// Clean reports whether no check found anything.
+func (r *Report) Clean() bool {
+	for _, res := range r.Results {
+		if res.Total > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Exclusions are records a repair must leave untouched.
+type Exclusions struct {
+	// TenantIDs are tenants under a legal hold.
+	TenantIDs []string
+	// UserIDs are users under a legal hold.
+	UserIDs []string
+}
+
+// HoldChecker lists the tenants and users under a legal hold;
+// retention.Service implements it.
+type HoldChecker interface {
+	HeldIDs(ctx context.Context) (tenantIDs, userIDs []string, err error)
+}
+
+// Repository runs the integrity queries.
+//
+// Purpose: Store-specific detection and repair of inconsistent records.
+// Domain: Platform
+type Repository interface {
+	// Find returns up to limit findings of kind and the total number found
+	Find(ctx context.Context, kind Kind, limit int) ([]Finding, int, error)
+	// Repair fixes every finding of kind (deleting or revoking the record) outside exclude
+	// and returns how many it fixed
+	Repair(ctx context.Context, kind Kind, exclude Exclusions) (int64, error)
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// DefaultSampleLimit is how many findings per check a report includes
+const DefaultSampleLimit = 50
+
+// Service checks and repairs data integrity.
+//
+// Purpose: Detection and optional repair of orphaned and inconsistent records.
+// Domain: Platform
+// Invariants: Checks never modify data; every repair that changes records is audited.
+type Service struct {
+	repo        Repository
+	auditLogger audit.Logger
+	holds       HoldChecker
+	autoRepair  bool
+	sampleLimit int
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithAutoRepair makes the scheduled Run repair what it finds instead of only reporting it.
+func WithAutoRepair(enabled bool) Option {
+	return func(s *Service) { s.autoRepair = enabled }
+}
+
+// WithHoldChecker makes repairs skip records of tenants and users under a legal hold.
+func WithHoldChecker(h HoldChecker) Option {
+	return func(s *Service) { s.holds = h }
+}
+
+// WithSampleLimit includes up to n findings per check in reports instead of DefaultSampleLimit.
+func WithSampleLimit(n int) Option {
+	return func(s *Service) { s.sampleLimit = n }
+}
+
+// WithTracer emits spans for integrity runs on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new integrity service.
+//
+// Purpose: Constructor for the integrity checker.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func NewService(repo Repository, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		auditLogger: auditLogger,
+		sampleLimit: DefaultSampleLimit,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Check runs every check without changing data.
+//
+// Purpose: On-demand integrity report for platform operators.
+// Domain: Platform
+// Audited: No
+// Errors: System errors
+func (s *Service) Check(ctx context.Context) (*Report, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "integrity.Check")
+	defer span.End()
+
+	report := &Report{CheckedAt: time.Now()}
+	for _, kind := range Kinds() {
+		sample, total, err := s.repo.Find(ctx, kind, s.sampleLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %s check: %w", kind, err)
+		}
+		report.Results = append(report.Results, Result{Kind: kind, Total: total, Sample: sample})
+	}
+	return report, nil
+}
+
+// Repair fixes every finding of the given kinds and reports what was found and fixed.
+//
+// Purpose: Operator-initiated cleanup after reviewing a report.
+// Domain: Platform
+// Security: Orphaned assignments, memberships, and codes are deleted; tokens of deleted
+// clients are revoked. Records of tenants and users under a legal hold are left in
+// place. Callers must hold platform administrator rights.
+// Audited: Yes (IntegrityRepaired, one event per kind that changed records)
+// Errors: ErrUnknownKind, System errors
+func (s *Service) Repair(ctx context.Context, kinds []Kind, actorID string) (*Report, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "integrity.Repair")
+	defer span.End()
+
+	for _, kind := range kinds {
+		if !IsKnown(kind) {
+			return nil, ErrUnknownKind
+		}
+	}
+
+	var exclude Exclusions
+	if s.holds != nil {
+		var err error
+		exclude.TenantIDs, exclude.UserIDs, err = s.holds.HeldIDs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list legal holds: %w", err)
+		}
+	}
+
+	report := &Report{CheckedAt: time.Now()}
+	for _, kind := range kinds {
+		sample, total, err := s.repo.Find(ctx, kind, s.sampleLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %s check: %w", kind, err)
+		}
+		res := Result{Kind: kind, Total: total, Sample: sample}
+		if total > 0 {
+			res.Repaired, err = s.repo.Repair(ctx, kind, exclude)
+			if err != nil {
+				return nil, fmt.Errorf("failed to repair %s: %w", kind, err)
+			}
+		}
+		if res.Repaired > 0 {
+			s.auditLogger.Log(ctx, audit.Event{
+				Type:     audit.TypeIntegrityRepaired,
+				ActorID:  actorID,
+				Resource: audit.ResourcePlatform,
+				TargetID: string(kind),
+				Metadata: map[string]any{"kind": string(kind), "found": total, "repaired": res.Repaired},
+			})
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report, nil
+}
+
+// Run is the scheduled integrity job: it checks, logs findings, and repairs
+// them when auto-repair is enabled.
+func (s *Service) Run(ctx context.Context) error {
+	var report *Report
+	var err error
+	if s.autoRepair {
+		report, err = s.Repair(ctx, Kinds(), ActorIntegrityCheck)
+	} else {
+		report, err = s.Check(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	for _, res := range report.Results {
+		if res.Total > 0 {
+			slog.WarnContext(ctx, "integrity check found inconsistent records",
+				"kind", res.Kind, "total", res.Total, "repaired", res.Repaired)
+		}
+	}
+	return nil
+}
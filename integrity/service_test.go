@@ -0,0 +1,154 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integrity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+type mockRepo struct {
+	findings map[Kind][]Finding
+	repaired []Kind
+	excluded Exclusions
+}
+
+func (m *mockRepo) Find(ctx context.Context, kind Kind, limit int) ([]Finding, int, error) {
+	f := m.findings[kind]
+	if len(f) > limit {
+		return f[:limit], len(f), nil
+	}
+	return f, len(f), nil
+}
+
+func (m *mockRepo) Repair(ctx context.Context, kind Kind, exclude Exclusions) (int64, error) {
+	m.repaired = append(m.repaired, kind)
+	m.excluded = exclude
+	n := int64(len(m.findings[kind]))
+	delete(m.findings, kind)
+	return n, nil
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+type mockHolds struct {
+	tenants, users []string
+}
+
+func (m *mockHolds) HeldIDs(ctx context.Context) ([]string, []string, error) {
+	return m.tenants, m.users, nil
+}
+
+func newRepo() *mockRepo {
+	return &mockRepo{findings: map[Kind][]Finding{
+		KindOrphanedAssignment: {
+			{Kind: KindOrphanedAssignment, Table: "rbac_assignments", RecordID: "a1", Reference: "t-deleted"},
+			{Kind: KindOrphanedAssignment, Table: "rbac_assignments", RecordID: "a2", Reference: "t-deleted"},
+		},
+		KindCodeOfDeletedUser: {
+			{Kind: KindCodeOfDeletedUser, Table: "authorization_codes", RecordID: "c1", Reference: "u-deleted"},
+		},
+	}}
+}
+
+func TestCheckDoesNotRepair(t *testing.T) {
+	repo := newRepo()
+	logger := &mockAuditLogger{}
+	svc := NewService(repo, logger, WithSampleLimit(1))
+
+	report, err := svc.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(report.Results) != len(Kinds()) || report.Clean() {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if r := report.Results[0]; r.Kind != KindOrphanedAssignment || r.Total != 2 || len(r.Sample) != 1 {
+		t.Errorf("assignment result = %+v, want total 2 with one sample", r)
+	}
+	if len(repo.repaired) != 0 || len(logger.events) != 0 {
+		t.Error("Check must neither repair nor audit")
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name        string
+		run         func(*Service) error
+		wantRepairs int
+		wantAudits  int
+	}{
+		{"scheduled run reports only", func(s *Service) error { return s.Run(context.Background()) }, 0, 0},
+		{"scheduled run with auto-repair", func(s *Service) error {
+			WithAutoRepair(true)(s)
+			return s.Run(context.Background())
+		}, 2, 2},
+		{"operator repair of one kind", func(s *Service) error {
+			_, err := s.Repair(context.Background(), []Kind{KindCodeOfDeletedUser, KindOrphanedMembership}, "admin")
+			return err
+		}, 1, 1},
+		{"unknown kind", func(s *Service) error {
+			_, err := s.Repair(context.Background(), []Kind{"bogus"}, "admin")
+			if !errors.Is(err, ErrUnknownKind) {
+				t.Errorf("err = %v, want ErrUnknownKind", err)
+			}
+			return nil
+		}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			logger := &mockAuditLogger{}
+			svc := NewService(repo, logger)
+			if err := tt.run(svc); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if len(repo.repaired) != tt.wantRepairs {
+				t.Errorf("repaired kinds = %v, want %d", repo.repaired, tt.wantRepairs)
+			}
+			if len(logger.events) != tt.wantAudits {
+				t.Fatalf("audit events = %d, want %d", len(logger.events), tt.wantAudits)
+			}
+			for _, e := range logger.events {
+				if e.Type != audit.TypeIntegrityRepaired {
+					t.Errorf("audit type = %q", e.Type)
+				}
+			}
+		})
+	}
+}
+
+func TestRepairSkipsLegalHolds(t *testing.T) {
+	repo := newRepo()
+	holds := &mockHolds{tenants: []string{"t-held"}, users: []string{"u-held"}}
+	svc := NewService(repo, &mockAuditLogger{}, WithHoldChecker(holds))
+
+	if _, err := svc.Repair(context.Background(), []Kind{KindOrphanedAssignment}, "admin"); err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if len(repo.excluded.TenantIDs) != 1 || repo.excluded.TenantIDs[0] != "t-held" ||
+		len(repo.excluded.UserIDs) != 1 || repo.excluded.UserIDs[0] != "u-held" {
+		t.Errorf("repair exclusions = %+v, want the held tenant and user", repo.excluded)
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package introspection answers OAuth2 token introspection requests (RFC 7662).
+// Transports authenticate the calling client through this package, which then
+// resolves the presented access or refresh token by its hash and reports whether
+// it is active.
+package introspection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/token"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Domain errors
+var (
+	ErrNotPermitted = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2AccessDenied, "client is not permitted to introspect tokens")
+)
+
+// Request is a token introspection request.
+//
+// Purpose: Everything the introspection endpoint received, after transport-level parsing.
+// Domain: OAuth2
+// Invariants: ClientID and ClientSecret identify the calling client, not the client the
+// token was issued to. TokenTypeHint is only an optimization of the lookup order.
+type Request struct {
+	// Issuer is the tenant's issuer identifier, reported as iss of active tokens.
+	Issuer        string
+	TenantID      string
+	ClientID      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+	Request       client.TokenRequest
+}
+
+// Response is the introspection response (RFC 7662 Section 2.2).
+//
+// Purpose: The JSON body the transport returns.
+// Domain: OAuth2
+// Invariants: An inactive response carries nothing but Active=false, so callers learn
+// nothing about unknown, expired, revoked, or foreign-tenant tokens. An active access
+// token reports its audience and sender constraint, so resource servers apply the same
+// checks as for a JWT access token.
+type Response struct {
+	Active    bool          `json:"active"`
+	Scope     string        `json:"scope,omitempty"`
+	ClientID  string        `json:"client_id,omitempty"`
+	Sub       string        `json:"sub,omitempty"`
+	Aud       string        `json:"aud,omitempty"`
+	Iss       string        `json:"iss,omitempty"`
+	Exp       int64         `json:"exp,omitempty"`
+	Iat       int64         `json:"iat,omitempty"`
+	TokenType string        `json:"token_type,omitempty"`
+	Cnf       *Confirmation `json:"cnf,omitempty"`
+}
+
+// Confirmation is the cnf member of an active response for a sender-constrained token.
+type Confirmation struct {
+	// JKT is the JWK SHA-256 thumbprint of the DPoP key (RFC 9449 Section 6.2).
+	JKT string `json:"jkt,omitempty"`
+	// X5TS256 is the SHA-256 thumbprint of the client certificate (RFC 8705 Section 3.2).
+	X5TS256 string `json:"x5t#S256,omitempty"`
+}
+
+// ClientAuthenticator authenticates the calling client; client.Service implements it.
+type ClientAuthenticator interface {
+	AuthenticateClient(ctx context.Context, tenantID, clientID, secret string, req client.TokenRequest) (*client.Client, error)
+}
+
+// ClientSource looks up the client a token was issued to; client.Service implements it.
+type ClientSource interface {
+	GetClientByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error)
+}
+
+// SubjectMapper returns the "sub" a client knows a user by; subject.Service implements it.
+type SubjectMapper interface {
+	Subject(ctx context.Context, c *client.Client, userID string) (string, error)
+}
+
+// Service answers token introspection requests.
+//
+// Purpose: The introspection endpoint's business logic, so transports do not hand-roll it.
+// Domain: OAuth2
+// Invariants: Only authenticated clients whose owner holds
+// policy.PermClientTokenIntrospect in the client's tenant may introspect, and only
+// tokens of that tenant are ever reported active.
+type Service struct {
+	clients  ClientAuthenticator
+	authz    client.PermissionChecker
+	access   client.AccessTokenRepository
+	refresh  client.RefreshTokenRepository
+	owners   ClientSource
+	subjects SubjectMapper
+	tracer   tracing.Tracer
+	clock    clock.Clock
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithTracer emits spans for introspection requests on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithSubjects reports sub as the token's client knows the user: its pairwise
+// identifier for pairwise clients, looked up through clients and subjects. Without it
+// sub is always the user's ID.
+func WithSubjects(clients ClientSource, subjects SubjectMapper) Option {
+	return func(s *Service) { s.owners, s.subjects = clients, subjects }
+}
+
+// WithClock reads the current time from c to decide expiry.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// NewService creates a new introspection service.
+//
+// Purpose: Constructor for the token introspection service.
+// Domain: OAuth2
+// Audited: No
+// Errors: None
+func NewService(
+	clients ClientAuthenticator,
+	authz client.PermissionChecker,
+	access client.AccessTokenRepository,
+	refresh client.RefreshTokenRepository,
+	opts ...Option,
+) *Service {
+	s := &Service{
+		clients: clients,
+		authz:   authz,
+		access:  access,
+		refresh: refresh,
+		clock:   clock.System(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Introspect reports whether a token is active and, if so, its metadata.
+//
+// Purpose: Token introspection (RFC 7662).
+// Domain: OAuth2
+// Security: The calling client is authenticated with its network and binding policy,
+// and its owner must hold policy.PermClientTokenIntrospect in the client's tenant;
+// clients without an owner may not introspect. The token is looked up only by its
+// hash. Unknown, revoked, and expired tokens, and tokens of another tenant, all yield
+// the same inactive response. With WithSubjects, sub is the identifier the token's
+// client knows, so a pairwise client's tokens never reveal the user's ID. Active
+// access tokens carry their aud and cnf, so a resource server relying on introspection
+// still enforces the token's audience and sender constraint.
+// Audited: No
+// Errors: client.ErrDomainInvalidClient, ErrNotPermitted, client network and binding
+// errors, System errors
+func (s *Service) Introspect(ctx context.Context, req Request) (*Response, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "introspection.Introspect")
+	defer span.End()
+
+	c, err := s.clients.AuthenticateClient(ctx, req.TenantID, req.ClientID, req.ClientSecret, req.Request)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, c); err != nil {
+		return nil, err
+	}
+	if req.Token == "" {
+		return &Response{Active: false}, nil
+	}
+
+	hash := token.HashToken(req.Token)
+	lookups := []func(string, string) (*Response, error){s.accessToken, s.refreshToken}
+	if req.TokenTypeHint == token.HintRefreshToken {
+		lookups[0], lookups[1] = lookups[1], lookups[0]
+	}
+	for _, lookup := range lookups {
+		resp, err := lookup(c.TenantID, hash)
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil {
+			if resp.Active {
+				resp.Iss = req.Issuer
+			}
+			return s.withClientSubject(ctx, c.TenantID, resp)
+		}
+	}
+	return &Response{Active: false}, nil
+}
+
+// withClientSubject replaces the user ID in an active resp with the sub the token's
+// client knows the user by.
+func (s *Service) withClientSubject(ctx context.Context, tenantID string, resp *Response) (*Response, error) {
+	if s.subjects == nil || !resp.Active || resp.Sub == "" {
+		return resp, nil
+	}
+	owner, err := s.owners.GetClientByClientID(ctx, tenantID, resp.ClientID)
+	if errors.Is(err, client.ErrClientNotFound) {
+		return &Response{Active: false}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up token client: %w", err)
+	}
+	if resp.Sub, err = s.subjects.Subject(ctx, owner, resp.Sub); err != nil {
+		return nil, fmt.Errorf("failed to resolve subject: %w", err)
+	}
+	return resp, nil
+}
+
+// authorize checks that the calling client may introspect tokens.
+func (s *Service) authorize(ctx context.Context, c *client.Client) error {
+	if c.OwnerID == "" {
+		return ErrNotPermitted
+	}
+	tenantID := c.TenantID
+	ok, err := s.authz.HasPermission(ctx, c.OwnerID, role.ScopeTenant, &tenantID, policy.PermClientTokenIntrospect)
+	if err != nil {
+		return fmt.Errorf("failed to check introspection permission: %w", err)
+	}
+	if !ok {
+		return ErrNotPermitted
+	}
+	return nil
+}
+
+// accessToken resolves an access token; a nil response means it is unknown.
+func (s *Service) accessToken(tenantID, hash string) (*Response, error) {
+	t, err := s.access.GetByTokenHash(hash)
+	if errors.Is(err, client.ErrTokenNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up access token: %w", err)
+	}
+	if t.TenantID != tenantID || t.IsRevoked || !s.clock.Now().Before(t.ExpiresAt) {
+		return &Response{Active: false}, nil
+	}
+	tokenType := t.TokenType
+	if tokenType == "" {
+		tokenType = token.TypeBearer
+	}
+	return &Response{
+		Active:    true,
+		Scope:     t.Scope,
+		ClientID:  t.ClientID,
+		Sub:       t.UserID,
+		Aud:       t.Audience,
+		Exp:       t.ExpiresAt.Unix(),
+		Iat:       t.CreatedAt.Unix(),
+		TokenType: tokenType,
+		Cnf:       confirmation(t),
+	}, nil
+}
+
+// confirmation returns the cnf of a sender-constrained access token, or nil for a
+// bearer token.
+func confirmation(t *client.AccessToken) *Confirmation {
+	if t.DPoPJKT == "" && t.CertThumbprint == "" {
+		return nil
+	}
+	return &Confirmation{JKT: t.DPoPJKT, X5TS256: t.CertThumbprint}
+}
+
+// refreshToken resolves a refresh token; a nil response means it is unknown.
+func (s *Service) refreshToken(tenantID, hash string) (*Response, error) {
+	t, err := s.refresh.GetByTokenHash(hash)
+	if errors.Is(err, client.ErrTokenNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up refresh token: %w", err)
+	}
+	if t.TenantID != tenantID || t.IsRevoked || !s.clock.Now().Before(t.ExpiresAt) {
+		return &Response{Active: false}, nil
+	}
+	return &Response{
+		Active:    true,
+		Scope:     t.Scope,
+		ClientID:  t.ClientID,
+		Sub:       t.UserID,
+		Exp:       t.ExpiresAt.Unix(),
+		Iat:       t.CreatedAt.Unix(),
+		TokenType: token.HintRefreshToken,
+	}, nil
+}
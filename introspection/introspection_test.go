@@ -0,0 +1,269 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/token"
+	"github.com/opentrusty/opentrusty-core/verifier"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+const testIssuer = "https://t1.example.com"
+
+type mockClients struct {
+	clients map[string]*client.Client
+}
+
+func (m *mockClients) AuthenticateClient(ctx context.Context, tenantID, clientID, secret string, req client.TokenRequest) (*client.Client, error) {
+	c, ok := m.clients[clientID]
+	if !ok || c.TenantID != tenantID || secret != "secret" {
+		return nil, client.ErrDomainInvalidClient
+	}
+	return c, nil
+}
+
+func (m *mockClients) GetClientByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error) {
+	c, ok := m.clients[clientID]
+	if !ok || c.TenantID != tenantID {
+		return nil, client.ErrClientNotFound
+	}
+	return c, nil
+}
+
+type mockSubjects struct{}
+
+func (mockSubjects) Subject(ctx context.Context, c *client.Client, userID string) (string, error) {
+	if c.IsPairwise() {
+		return "pairwise-" + userID, nil
+	}
+	return userID, nil
+}
+
+type mockAuthz struct {
+	granted map[string]bool
+}
+
+func (m *mockAuthz) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return scope == role.ScopeTenant && m.granted[userID+"@"+*scopeContextID+":"+permission], nil
+}
+
+type mockAccess struct {
+	client.AccessTokenRepository
+	tokens map[string]*client.AccessToken
+}
+
+func (m *mockAccess) GetByTokenHash(tokenHash string) (*client.AccessToken, error) {
+	if t, ok := m.tokens[tokenHash]; ok {
+		return t, nil
+	}
+	return nil, client.ErrTokenNotFound
+}
+
+type mockRefresh struct {
+	client.RefreshTokenRepository
+	tokens map[string]*client.RefreshToken
+}
+
+func (m *mockRefresh) GetByTokenHash(tokenHash string) (*client.RefreshToken, error) {
+	if t, ok := m.tokens[tokenHash]; ok {
+		return t, nil
+	}
+	return nil, client.ErrTokenNotFound
+}
+
+func newTestService() *Service {
+	clients := &mockClients{clients: map[string]*client.Client{
+		"rs":           {ClientID: "rs", TenantID: "t1", OwnerID: "owner"},
+		"unowned":      {ClientID: "unowned", TenantID: "t1"},
+		"unprivileged": {ClientID: "unprivileged", TenantID: "t1", OwnerID: "nobody"},
+	}}
+	authz := &mockAuthz{granted: map[string]bool{"owner@t1:client:token_introspect": true}}
+	issued := testNow.Add(-time.Minute)
+	access := &mockAccess{tokens: map[string]*client.AccessToken{
+		token.HashToken("at"):       {TenantID: "t1", ClientID: "app", UserID: "u1", Scope: "openid", TokenType: token.TypeDPoP, DPoPJKT: "jkt", Audience: "api", ExpiresAt: testNow.Add(time.Hour), CreatedAt: issued},
+		token.HashToken("expired"):  {TenantID: "t1", ClientID: "app", UserID: "u1", ExpiresAt: testNow.Add(-time.Second), CreatedAt: issued},
+		token.HashToken("revoked"):  {TenantID: "t1", ClientID: "app", UserID: "u1", IsRevoked: true, ExpiresAt: testNow.Add(time.Hour), CreatedAt: issued},
+		token.HashToken("t2-token"): {TenantID: "t2", ClientID: "app", UserID: "u1", ExpiresAt: testNow.Add(time.Hour), CreatedAt: issued},
+	}}
+	refresh := &mockRefresh{tokens: map[string]*client.RefreshToken{
+		token.HashToken("rt"): {TenantID: "t1", ClientID: "app", UserID: "u1", Scope: "openid offline_access", ExpiresAt: testNow.Add(24 * time.Hour), CreatedAt: issued},
+	}}
+	return NewService(clients, authz, access, refresh, WithClock(clock.Fixed(testNow)))
+}
+
+func TestIntrospect(t *testing.T) {
+	s := newTestService()
+	tests := []struct {
+		name     string
+		clientID string
+		token    string
+		hint     string
+		want     Response
+		wantErr  error
+	}{
+		{"active access token", "rs", "at", "", Response{
+			Active: true, Scope: "openid", ClientID: "app", Sub: "u1", Aud: "api", Iss: testIssuer, TokenType: token.TypeDPoP,
+			Cnf: &Confirmation{JKT: "jkt"}, Exp: testNow.Add(time.Hour).Unix(), Iat: testNow.Add(-time.Minute).Unix(),
+		}, nil},
+		{"active refresh token with hint", "rs", "rt", token.HintRefreshToken, Response{
+			Active: true, Scope: "openid offline_access", ClientID: "app", Sub: "u1", Iss: testIssuer, TokenType: token.HintRefreshToken,
+			Exp: testNow.Add(24 * time.Hour).Unix(), Iat: testNow.Add(-time.Minute).Unix(),
+		}, nil},
+		{"refresh token with wrong hint", "rs", "rt", token.HintAccessToken, Response{
+			Active: true, Scope: "openid offline_access", ClientID: "app", Sub: "u1", Iss: testIssuer, TokenType: token.HintRefreshToken,
+			Exp: testNow.Add(24 * time.Hour).Unix(), Iat: testNow.Add(-time.Minute).Unix(),
+		}, nil},
+		{"expired", "rs", "expired", "", Response{}, nil},
+		{"revoked", "rs", "revoked", "", Response{}, nil},
+		{"other tenant", "rs", "t2-token", "", Response{}, nil},
+		{"unknown", "rs", "nope", "", Response{}, nil},
+		{"empty token", "rs", "", "", Response{}, nil},
+		{"client without owner", "unowned", "at", "", Response{}, ErrNotPermitted},
+		{"owner without permission", "unprivileged", "at", "", Response{}, ErrNotPermitted},
+		{"unknown client", "ghost", "at", "", Response{}, client.ErrDomainInvalidClient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Introspect(context.Background(), Request{
+				Issuer: testIssuer, TenantID: "t1", ClientID: tt.clientID, ClientSecret: "secret", Token: tt.token, TokenTypeHint: tt.hint,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Introspect() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Introspect() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntrospectPairwiseSubject(t *testing.T) {
+	clients := &mockClients{clients: map[string]*client.Client{
+		"rs":     {ClientID: "rs", TenantID: "t1", OwnerID: "owner"},
+		"app":    {ClientID: "app", TenantID: "t1", SubjectType: client.SubjectTypePairwise, SectorIdentifier: "app.example.com"},
+		"public": {ClientID: "public", TenantID: "t1", SubjectType: client.SubjectTypePublic},
+	}}
+	authz := &mockAuthz{granted: map[string]bool{"owner@t1:client:token_introspect": true}}
+	exp := testNow.Add(time.Hour)
+	access := &mockAccess{tokens: map[string]*client.AccessToken{
+		token.HashToken("pairwise"): {TenantID: "t1", ClientID: "app", UserID: "u1", ExpiresAt: exp},
+		token.HashToken("public"):   {TenantID: "t1", ClientID: "public", UserID: "u1", ExpiresAt: exp},
+		token.HashToken("orphan"):   {TenantID: "t1", ClientID: "deleted", UserID: "u1", ExpiresAt: exp},
+	}}
+	s := NewService(clients, authz, access, &mockRefresh{}, WithClock(clock.Fixed(testNow)), WithSubjects(clients, mockSubjects{}))
+
+	tests := []struct {
+		token      string
+		wantActive bool
+		wantSub    string
+	}{
+		{"pairwise", true, "pairwise-u1"},
+		{"public", true, "u1"},
+		{"orphan", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			got, err := s.Introspect(context.Background(), Request{TenantID: "t1", ClientID: "rs", ClientSecret: "secret", Token: tt.token})
+			if err != nil {
+				t.Fatalf("Introspect() error = %v", err)
+			}
+			if got.Active != tt.wantActive || got.Sub != tt.wantSub {
+				t.Errorf("Introspect() = %+v, want active %v sub %q", *got, tt.wantActive, tt.wantSub)
+			}
+		})
+	}
+}
+
+// resourceServer is the introspection client a resource server plugs into its
+// verifier: it decodes the wire response into verifier claims.
+type resourceServer struct {
+	s *Service
+}
+
+func (r resourceServer) Introspect(ctx context.Context, tok string) (*verifier.Claims, error) {
+	resp, err := r.s.Introspect(ctx, Request{Issuer: testIssuer, TenantID: "t1", ClientID: "rs", ClientSecret: "secret", Token: tok})
+	if err != nil || !resp.Active {
+		return nil, err
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	var claims verifier.Claims
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func TestIntrospectVerifierRoundTrip(t *testing.T) {
+	clients := &mockClients{clients: map[string]*client.Client{
+		"rs": {ClientID: "rs", TenantID: "t1", OwnerID: "owner"},
+	}}
+	authz := &mockAuthz{granted: map[string]bool{"owner@t1:client:token_introspect": true}}
+	exp := time.Now().Add(time.Hour)
+	access := &mockAccess{tokens: map[string]*client.AccessToken{
+		token.HashToken("bearer"):    {TenantID: "t1", ClientID: "app", UserID: "u1", Audience: "api", ExpiresAt: exp},
+		token.HashToken("dpop"):      {TenantID: "t1", ClientID: "app", UserID: "u1", Audience: "api", DPoPJKT: "jkt", ExpiresAt: exp},
+		token.HashToken("mtls"):      {TenantID: "t1", ClientID: "app", UserID: "u1", Audience: "api", CertThumbprint: "cert", ExpiresAt: exp},
+		token.HashToken("other-api"): {TenantID: "t1", ClientID: "app", UserID: "u1", Audience: "billing", ExpiresAt: exp},
+	}}
+	s := NewService(clients, authz, access, &mockRefresh{})
+	v, err := verifier.New(nil, verifier.Config{Issuer: testIssuer, Audience: "api"}, verifier.WithIntrospector(resourceServer{s}))
+	if err != nil {
+		t.Fatalf("verifier.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		cert    string
+		wantErr error
+	}{
+		{"bearer token", "bearer", "", nil},
+		{"certificate-bound token with its certificate", "mtls", "cert", nil},
+		{"certificate-bound token with another certificate", "mtls", "other", verifier.ErrInvalidToken},
+		{"DPoP-bound token as bearer", "dpop", "", verifier.ErrInvalidToken},
+		{"token for another audience", "other-api", "", verifier.ErrInvalidAudience},
+		{"unknown token", "nope", "", verifier.ErrInactiveToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Authenticate(context.Background(), verifier.Request{
+				Authorization: verifier.SchemeBearer + " " + tt.token, CertThumbprint: tt.cert,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (claims.Issuer != testIssuer || claims.Subject != "u1") {
+				t.Errorf("Authenticate() = %+v, want iss %q sub u1", *claims, testIssuer)
+			}
+		})
+	}
+}
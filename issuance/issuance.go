@@ -0,0 +1,123 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package issuance tracks how many tokens and sessions each user is issued and
+// flags users whose rates exceed the deployment thresholds, a sign of a stolen
+// credential or a runaway integration. Flagged users raise a login risk signal
+// and may be throttled for a while.
+package issuance
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrThrottled    = apperror.New(apperror.CodeAccessDenied, apperror.StatusTooManyRequests, apperror.OAuth2TemporarilyUnavailable, "issuance is temporarily throttled for this user")
+	ErrFlagNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "issuance flag not found")
+)
+
+// Kind is a class of issued credential.
+type Kind string
+
+// Kinds
+const (
+	KindToken   Kind = "token"
+	KindSession Kind = "session"
+)
+
+// Policy holds the issuance thresholds.
+//
+// Purpose: Tunable per-user rate limits for abuse detection.
+// Domain: Identity (Security)
+// Invariants: A zero threshold disables that detector. ThrottleDuration never
+// exceeds FlagDuration, so a throttled user is always flagged.
+type Policy struct {
+	// MaxTokensPerHour flags a user issued more access tokens than this in the last hour.
+	MaxTokensPerHour int
+	// MaxSessionsPerDay flags a user issued more sessions than this in the last day.
+	MaxSessionsPerDay int
+	// FlagDuration is how long a flagged user raises a login risk signal.
+	FlagDuration time.Duration
+	// ThrottleDuration is how long issuance is refused after a flag; zero only flags.
+	ThrottleDuration time.Duration
+}
+
+// DefaultPolicy returns thresholds well above interactive use.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxTokensPerHour:  500,
+		MaxSessionsPerDay: 100,
+		FlagDuration:      24 * time.Hour,
+		ThrottleDuration:  15 * time.Minute,
+	}
+}
+
+// window returns the counting window and threshold of kind.
+func (p Policy) window(kind Kind) (time.Duration, int) {
+	switch kind {
+	case KindToken:
+		return time.Hour, p.MaxTokensPerHour
+	case KindSession:
+		return 24 * time.Hour, p.MaxSessionsPerDay
+	}
+	return 0, 0
+}
+
+// Flag records a user whose issuance rate exceeded a threshold.
+//
+// Purpose: Shared abuse marker read by the risk evaluator and the issuance gate.
+// Domain: Identity (Security)
+// Invariants: One flag per user; a new flag replaces the old one. Throttling is in
+// force only before ThrottledUntil (never when nil), the flag only before ExpiresAt.
+type Flag struct {
+	UserID         string     `json:"user_id"`
+	TenantID       string     `json:"tenant_id"`
+	Kind           Kind       `json:"kind"`
+	Count          int        `json:"count"`
+	Limit          int        `json:"limit"`
+	ThrottledUntil *time.Time `json:"throttled_until,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the flag is still in force at now.
+func (f *Flag) IsActive(now time.Time) bool {
+	return now.Before(f.ExpiresAt)
+}
+
+// IsThrottled reports whether issuance is refused at now.
+func (f *Flag) IsThrottled(now time.Time) bool {
+	return f.ThrottledUntil != nil && now.Before(*f.ThrottledUntil)
+}
+
+// Repository defines issuance counting and flag persistence.
+//
+// Purpose: Counts come from the persisted tokens and sessions, so every instance sees the same rate.
+// Domain: Identity (Security)
+type Repository interface {
+	// CountIssued returns how many credentials of kind were issued to the user since t
+	CountIssued(ctx context.Context, userID string, kind Kind, since time.Time) (int, error)
+	// SaveFlag creates or replaces the user's flag
+	SaveFlag(ctx context.Context, f *Flag) error
+	// GetFlag returns the user's flag, or ErrFlagNotFound
+	GetFlag(ctx context.Context, userID string) (*Flag, error)
+	// DeleteFlag removes the user's flag, or returns ErrFlagNotFound
+	DeleteFlag(ctx context.Context, userID string) error
+	// DeleteExpiredFlags removes flags that expired before now
+	DeleteExpiredFlags(ctx context.Context, now time.Time) error
+}
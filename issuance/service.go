@@ -0,0 +1,249 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issuance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/metrics"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Audit metadata keys
+const (
+	attrKind           = "kind"
+	attrCount          = "count"
+	attrLimit          = "limit"
+	attrThrottledUntil = "throttled_until"
+)
+
+// Service watches per-user token and session issuance.
+//
+// Purpose: Abuse detection on issuance rates, complementing login risk and brute-force detection.
+// Domain: Identity (Security)
+// Invariants: A user is flagged at most once per FlagDuration; later excess while
+// flagged is neither re-audited nor extends the throttle.
+type Service struct {
+	repo        Repository
+	auditLogger audit.Logger
+	policy      Policy
+	metrics     *metrics.Metrics
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithPolicy replaces DefaultPolicy.
+func WithPolicy(p Policy) Option {
+	return func(s *Service) { s.policy = p }
+}
+
+// WithMetrics records flags and refused issuance on m.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *Service) { s.metrics = m }
+}
+
+// WithTracer emits spans for issuance checks on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new issuance monitoring service.
+//
+// Purpose: Constructor for the issuance monitoring service.
+// Domain: Identity (Security)
+// Audited: No
+// Errors: None
+func NewService(repo Repository, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		auditLogger: auditLogger,
+		policy:      DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.policy.ThrottleDuration = min(s.policy.ThrottleDuration, s.policy.FlagDuration)
+	return s
+}
+
+// Check reports whether a token or session may be issued to userID.
+//
+// Purpose: Gate called by transports before issuing a session or token to a user.
+// Domain: Identity (Security)
+// Security: Storage errors are returned, not treated as throttled; the transport decides whether to fail open.
+// Audited: No
+// Errors: ErrThrottled (wrapped in apperror.RetryAfterError until the throttle lifts), System errors
+func (s *Service) Check(ctx context.Context, userID string, kind Kind) error {
+	f, err := s.activeFlag(ctx, userID)
+	if err != nil || f == nil {
+		return err
+	}
+	now := time.Now()
+	if f.IsThrottled(now) {
+		s.metrics.IssuanceThrottled(string(kind))
+		return apperror.WithRetryAfter(ErrThrottled, f.ThrottledUntil.Sub(now))
+	}
+	return nil
+}
+
+// Flagged reports whether userID recently exceeded an issuance threshold.
+// risk.Service consults it when scoring logins.
+func (s *Service) Flagged(ctx context.Context, userID string) (bool, error) {
+	f, err := s.activeFlag(ctx, userID)
+	return f != nil, err
+}
+
+// GetFlag returns the user's flag if it is still in force.
+func (s *Service) GetFlag(ctx context.Context, userID string) (*Flag, error) {
+	f, err := s.activeFlag(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, ErrFlagNotFound
+	}
+	return f, nil
+}
+
+// Record counts an issuance and flags the user when the policy threshold is exceeded.
+//
+// Purpose: Detection step run after a token or session was persisted.
+// Domain: Identity (Security)
+// Security: Counts are read from storage, so the limit holds across instances.
+// Audited: Yes (ExcessiveIssuance, once per flag)
+// Errors: System errors
+func (s *Service) Record(ctx context.Context, tenantID, userID string, kind Kind) error {
+	window, limit := s.policy.window(kind)
+	if limit <= 0 || userID == "" {
+		return nil
+	}
+	ctx, span := tracing.Start(ctx, s.tracer, "issuance.Record", tracing.String(tracing.AttrUserID, userID))
+	defer span.End()
+
+	now := time.Now()
+	count, err := s.repo.CountIssued(ctx, userID, kind, now.Add(-window))
+	if err != nil {
+		return fmt.Errorf("failed to count issuance: %w", err)
+	}
+	if count <= limit {
+		return nil
+	}
+	if f, err := s.activeFlag(ctx, userID); err != nil || f != nil {
+		return err
+	}
+
+	f := &Flag{
+		UserID:    userID,
+		TenantID:  tenantID,
+		Kind:      kind,
+		Count:     count,
+		Limit:     limit,
+		ExpiresAt: now.Add(s.policy.FlagDuration),
+		CreatedAt: now,
+	}
+	if s.policy.ThrottleDuration > 0 {
+		until := now.Add(s.policy.ThrottleDuration)
+		f.ThrottledUntil = &until
+	}
+	if err := s.repo.SaveFlag(ctx, f); err != nil {
+		return fmt.Errorf("failed to save issuance flag: %w", err)
+	}
+	s.metrics.IssuanceFlagged(string(kind))
+
+	metadata := map[string]any{
+		attrKind:  kind,
+		attrCount: count,
+		attrLimit: limit,
+	}
+	if f.ThrottledUntil != nil {
+		metadata[attrThrottledUntil] = *f.ThrottledUntil
+	}
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeExcessiveIssuance,
+		TenantID: tenantID,
+		ActorID:  userID,
+		Resource: audit.ResourceUser,
+		TargetID: userID,
+		Metadata: metadata,
+	})
+	return nil
+}
+
+// HandleEvent records issued access tokens and created sessions; it is an events.Handler.
+func (s *Service) HandleEvent(ctx context.Context, e events.Event) error {
+	switch ev := e.(type) {
+	case events.TokenIssued:
+		if ev.Kind == events.TokenKindAccess {
+			return s.Record(ctx, ev.TenantID, ev.UserID, KindToken)
+		}
+	case events.SessionCreated:
+		return s.Record(ctx, ev.TenantID, ev.UserID, KindSession)
+	}
+	return nil
+}
+
+// Release clears a user's flag and any throttle before they expire.
+//
+// Purpose: Operator override for false positives.
+// Domain: Identity (Security)
+// Audited: Yes (IssuanceFlagReleased)
+// Errors: ErrFlagNotFound, System errors
+func (s *Service) Release(ctx context.Context, userID, actorID string) error {
+	f, err := s.repo.GetFlag(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteFlag(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeIssuanceFlagReleased,
+		TenantID: f.TenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceUser,
+		TargetID: userID,
+		Metadata: map[string]any{attrKind: f.Kind},
+	})
+	return nil
+}
+
+// CleanupExpired removes flags past their lifetime
+func (s *Service) CleanupExpired(ctx context.Context) error {
+	return s.repo.DeleteExpiredFlags(ctx, time.Now())
+}
+
+// activeFlag returns the user's flag if it is in force, or nil.
+func (s *Service) activeFlag(ctx context.Context, userID string) (*Flag, error) {
+	f, err := s.repo.GetFlag(ctx, userID)
+	if errors.Is(err, ErrFlagNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load issuance flag: %w", err)
+	}
+	if !f.IsActive(time.Now()) {
+		return nil, nil
+	}
+	return f, nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issuance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+type mockRepo struct {
+	issued map[Kind][]time.Time
+	flags  map[string]Flag
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{issued: make(map[Kind][]time.Time), flags: make(map[string]Flag)}
+}
+
+func (m *mockRepo) CountIssued(ctx context.Context, userID string, kind Kind, since time.Time) (int, error) {
+	n := 0
+	for _, at := range m.issued[kind] {
+		if !at.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *mockRepo) SaveFlag(ctx context.Context, f *Flag) error {
+	m.flags[f.UserID] = *f
+	return nil
+}
+
+func (m *mockRepo) GetFlag(ctx context.Context, userID string) (*Flag, error) {
+	f, ok := m.flags[userID]
+	if !ok {
+		return nil, ErrFlagNotFound
+	}
+	return &f, nil
+}
+
+func (m *mockRepo) DeleteFlag(ctx context.Context, userID string) error {
+	if _, ok := m.flags[userID]; !ok {
+		return ErrFlagNotFound
+	}
+	delete(m.flags, userID)
+	return nil
+}
+
+func (m *mockRepo) DeleteExpiredFlags(ctx context.Context, now time.Time) error {
+	for id, f := range m.flags {
+		if f.ExpiresAt.Before(now) {
+			delete(m.flags, id)
+		}
+	}
+	return nil
+}
+
+// issue records n credentials of kind issued at.
+func (m *mockRepo) issue(kind Kind, n int, at time.Time) {
+	for range n {
+		m.issued[kind] = append(m.issued[kind], at)
+	}
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+func TestRecord(t *testing.T) {
+	policy := Policy{MaxTokensPerHour: 10, MaxSessionsPerDay: 3, FlagDuration: time.Hour, ThrottleDuration: time.Minute}
+	tests := []struct {
+		name         string
+		policy       Policy
+		kind         Kind
+		recent       int
+		old          int
+		wantFlag     bool
+		wantThrottle bool
+	}{
+		{"tokens within limit", policy, KindToken, 10, 0, false, false},
+		{"tokens over limit", policy, KindToken, 11, 0, true, true},
+		{"old tokens not counted", policy, KindToken, 5, 20, false, false},
+		{"sessions over limit", policy, KindSession, 4, 0, true, true},
+		{"flag only", Policy{MaxTokensPerHour: 10, FlagDuration: time.Hour}, KindToken, 11, 0, true, false},
+		{"detector disabled", Policy{FlagDuration: time.Hour}, KindSession, 100, 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newMockRepo()
+			repo.issue(tt.kind, tt.recent, time.Now().Add(-time.Minute))
+			repo.issue(tt.kind, tt.old, time.Now().Add(-48*time.Hour))
+			logger := &mockAuditLogger{}
+			svc := NewService(repo, logger, WithPolicy(tt.policy))
+
+			if err := svc.Record(ctx, "t1", "u1", tt.kind); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+			flagged, _ := svc.Flagged(ctx, "u1")
+			if flagged != tt.wantFlag {
+				t.Errorf("Flagged() = %v, want %v", flagged, tt.wantFlag)
+			}
+			if got := len(logger.events) == 1 && logger.events[0].Type == audit.TypeExcessiveIssuance; got != tt.wantFlag {
+				t.Errorf("audit events = %+v", logger.events)
+			}
+			err := svc.Check(ctx, "u1", tt.kind)
+			if errors.Is(err, ErrThrottled) != tt.wantThrottle {
+				t.Errorf("Check() error = %v, want throttled %v", err, tt.wantThrottle)
+			}
+			if _, ok := apperror.RetryAfterOf(err); ok != tt.wantThrottle {
+				t.Errorf("RetryAfterOf(%v) present = %v, want %v", err, ok, tt.wantThrottle)
+			}
+		})
+	}
+}
+
+func TestRecordOncePerFlag(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	repo.issue(KindSession, 5, time.Now())
+	logger := &mockAuditLogger{}
+	svc := NewService(repo, logger, WithPolicy(Policy{MaxSessionsPerDay: 3, FlagDuration: time.Hour, ThrottleDuration: time.Minute}))
+
+	svc.Record(ctx, "t1", "u1", KindSession)
+	first := repo.flags["u1"]
+	repo.issue(KindSession, 5, time.Now())
+	svc.HandleEvent(ctx, events.SessionCreated{Meta: events.NewMeta("t1", "u1"), UserID: "u1"})
+	if len(logger.events) != 1 || !repo.flags["u1"].ThrottledUntil.Equal(*first.ThrottledUntil) {
+		t.Errorf("repeated excess re-flagged: %d audit events, throttle %v -> %v", len(logger.events), first.ThrottledUntil, repo.flags["u1"].ThrottledUntil)
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	repo.issue(KindToken, 2, time.Now())
+	svc := NewService(repo, &mockAuditLogger{}, WithPolicy(Policy{MaxTokensPerHour: 1, FlagDuration: time.Hour}))
+
+	svc.HandleEvent(ctx, events.TokenIssued{Meta: events.NewMeta("t1", ""), Kind: events.TokenKindRefresh, UserID: "u1"})
+	svc.HandleEvent(ctx, events.TokenIssued{Meta: events.NewMeta("t1", ""), Kind: events.TokenKindAccess})
+	if len(repo.flags) != 0 {
+		t.Fatalf("refresh tokens and client tokens flagged: %+v", repo.flags)
+	}
+	svc.HandleEvent(ctx, events.TokenIssued{Meta: events.NewMeta("t1", ""), Kind: events.TokenKindAccess, UserID: "u1"})
+	if _, ok := repo.flags["u1"]; !ok {
+		t.Error("access token excess not flagged")
+	}
+}
+
+func TestRelease(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	until := time.Now().Add(time.Minute)
+	repo.flags["u1"] = Flag{UserID: "u1", TenantID: "t1", Kind: KindToken, ThrottledUntil: &until, ExpiresAt: time.Now().Add(time.Hour)}
+	repo.flags["u2"] = Flag{UserID: "u2", Kind: KindToken, ExpiresAt: time.Now().Add(-time.Minute)}
+	logger := &mockAuditLogger{}
+	svc := NewService(repo, logger)
+
+	if flagged, _ := svc.Flagged(ctx, "u2"); flagged {
+		t.Error("expired flag still in force")
+	}
+	if err := svc.Release(ctx, "u1", "admin"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := svc.Check(ctx, "u1", KindToken); err != nil {
+		t.Errorf("Check() after Release error = %v", err)
+	}
+	if len(logger.events) != 1 || logger.events[0].Type != audit.TypeIssuanceFlagReleased || logger.events[0].TenantID != "t1" {
+		t.Errorf("audit events = %+v", logger.events)
+	}
+	if err := svc.Release(ctx, "u1", "admin"); !errors.Is(err, ErrFlagNotFound) {
+		t.Errorf("second Release() error = %v, want ErrFlagNotFound", err)
+	}
+	svc.CleanupExpired(ctx)
+	if len(repo.flags) != 0 {
+		t.Errorf("CleanupExpired() kept %+v", repo.flags)
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jose implements the subset of JWS (RFC 7515), JWE (RFC 7516), and
+// JWK (RFC 7517) that OpenTrusty uses: compact serialization, the RS256, PS256,
+// ES256, and EdDSA signing algorithms, and "dir" A256GCM encryption. It depends
+// only on the standard library.
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// Domain errors
+var (
+	ErrMalformed        = errors.New("malformed JOSE object")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidKey       = errors.New("invalid key")
+	ErrKeyNotFound      = errors.New("key not found")
+)
+
+// Supported JWS algorithms
+const (
+	RS256 = "RS256"
+	PS256 = "PS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// algorithms lists the supported algorithms in order of preference for discovery metadata.
+var algorithms = []string{RS256, PS256, ES256, EdDSA}
+
+// pssOptions are the RSASSA-PSS parameters of PS256 (RFC 7518 Section 3.5).
+var pssOptions = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+
+// es256SigSize is the length of a raw R||S ES256 signature.
+const es256SigSize = 64
+
+// Header is the protected header of a JWS.
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+	JWK *JWK   `json:"jwk,omitempty"`
+}
+
+// JWS is a parsed, not yet verified, compact JWS.
+//
+// Purpose: Separates parsing from verification so callers can select the key by header.
+// Domain: Cryptography
+// Invariants: Payload MUST NOT be trusted until Verify succeeds.
+type JWS struct {
+	Header    Header
+	Payload   []byte
+	Signature []byte
+
+	signingInput string
+}
+
+// Parse decodes a compact JWS without verifying its signature.
+//
+// Purpose: First step of token verification.
+// Domain: Cryptography
+// Security: Rejects the "none" algorithm and anything outside RS256/PS256/ES256/EdDSA.
+// Audited: No
+// Errors: ErrMalformed, ErrUnsupportedAlg
+func Parse(compact string) (*JWS, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var h Header
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, ErrMalformed
+	}
+	if !Supported(h.Alg) {
+		return nil, ErrUnsupportedAlg
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return &JWS{
+		Header:       h,
+		Payload:      payload,
+		Signature:    sig,
+		signingInput: parts[0] + "." + parts[1],
+	}, nil
+}
+
+// Verify checks the signature against key.
+//
+// Purpose: Authenticates the JWS payload.
+// Domain: Cryptography
+// Security: The key type MUST match the header algorithm; a mismatch is rejected.
+// Audited: No
+// Errors: ErrInvalidKey, ErrInvalidSignature
+func (j *JWS) Verify(key crypto.PublicKey) error {
+	input := []byte(j.signingInput)
+	switch j.Header.Alg {
+	case RS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidKey
+		}
+		digest := sha256.Sum256(input)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], j.Signature) != nil {
+			return ErrInvalidSignature
+		}
+	case PS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidKey
+		}
+		digest := sha256.Sum256(input)
+		if rsa.VerifyPSS(pub, crypto.SHA256, digest[:], j.Signature, pssOptions) != nil {
+			return ErrInvalidSignature
+		}
+	case ES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve.Params().Name != "P-256" {
+			return ErrInvalidKey
+		}
+		if len(j.Signature) != es256SigSize {
+			return ErrInvalidSignature
+		}
+		digest := sha256.Sum256(input)
+		r := new(big.Int).SetBytes(j.Signature[:es256SigSize/2])
+		s := new(big.Int).SetBytes(j.Signature[es256SigSize/2:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+	case EdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return ErrInvalidKey
+		}
+		if !ed25519.Verify(pub, input, j.Signature) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedAlg
+	}
+	return nil
+}
+
+// Sign produces a compact JWS over payload.
+//
+// Purpose: Issues signed tokens and proofs.
+// Domain: Cryptography
+// Security: The algorithm is derived from the key type, never from caller input.
+// Audited: No
+// Errors: ErrInvalidKey, signing errors
+func Sign(key crypto.Signer, h Header, payload []byte) (string, error) {
+	alg, err := algFor(key)
+	if err != nil {
+		return "", err
+	}
+	return SignWith(key, alg, h, payload)
+}
+
+// SignWith produces a compact JWS over payload using alg.
+//
+// Purpose: Issues tokens with a configured algorithm, such as PS256 with an RSA key.
+// Domain: Cryptography
+// Security: alg MUST be supported and match the key type. key must be an
+// *rsa.PrivateKey, *ecdsa.PrivateKey, or ed25519.PrivateKey; other signers are
+// refused rather than producing a token without a signature.
+// Audited: No
+// Errors: ErrUnsupportedAlg, ErrInvalidKey, signing errors
+func SignWith(key crypto.Signer, alg string, h Header, payload []byte) (string, error) {
+	if !Supported(alg) {
+		return "", ErrUnsupportedAlg
+	}
+	if !KeyMatches(alg, key.Public()) {
+		return "", ErrInvalidKey
+	}
+	h.Alg = alg
+
+	rawHeader, err := json.Marshal(h)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode header: %w", err)
+	}
+	input := base64.RawURLEncoding.EncodeToString(rawHeader) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		if alg == PS256 {
+			sig, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, digest[:], pssOptions)
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		}
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		if err == nil {
+			sig = make([]byte, es256SigSize)
+			r.FillBytes(sig[:es256SigSize/2])
+			s.FillBytes(sig[es256SigSize/2:])
+		}
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(input))
+	default:
+		return "", ErrInvalidKey
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sign: %w", err)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// IsCompactJWS reports whether token has the shape of a compact JWS (three
+// dot-separated segments). It does not validate the segments.
+func IsCompactJWS(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Supported reports whether alg is a JWS algorithm this package signs and verifies.
+func Supported(alg string) bool {
+	return slices.Contains(algorithms, alg)
+}
+
+// Algorithms returns the supported JWS algorithms.
+func Algorithms() []string {
+	return slices.Clone(algorithms)
+}
+
+// KeyMatches reports whether pub is a key of the type alg signs with.
+func KeyMatches(alg string, pub crypto.PublicKey) bool {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return alg == RS256 || alg == PS256
+	case *ecdsa.PublicKey:
+		return alg == ES256 && k.Curve.Params().Name == "P-256"
+	case ed25519.PublicKey:
+		return alg == EdDSA
+	default:
+		return false
+	}
+}
+
+func algFor(key crypto.Signer) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return RS256, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve.Params().Name != "P-256" {
+			return "", ErrInvalidKey
+		}
+		return ES256, nil
+	case ed25519.PrivateKey:
+		return EdDSA, nil
+	default:
+		return "", ErrInvalidKey
+	}
+}
+
+// b64 decodes an unpadded base64url field of a JWK.
+func b64(field, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("%w: missing %q", ErrInvalidKey, field)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad %q", ErrInvalidKey, field)
+	}
+	return b, nil
+}
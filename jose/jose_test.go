@@ -0,0 +1,306 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jose
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/crypto/testkeys"
+)
+
+func fixtureKeys() map[string]crypto.Signer {
+	return map[string]crypto.Signer{
+		testkeys.RSAKeyID:     testkeys.RSA(),
+		testkeys.ECKeyID:      testkeys.ECDSA(),
+		testkeys.Ed25519KeyID: testkeys.Ed25519(),
+	}
+}
+
+func TestGoldenVectors(t *testing.T) {
+	keys := fixtureKeys()
+	for _, v := range testkeys.GoldenJWS() {
+		t.Run(v.Name, func(t *testing.T) {
+			jws, err := Parse(v.Compact)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if jws.Header.Alg != v.Alg || jws.Header.Kid != v.KeyID {
+				t.Errorf("header = %+v", jws.Header)
+			}
+			if string(jws.Payload) != testkeys.GoldenClaims {
+				t.Errorf("payload mismatch: %s", jws.Payload)
+			}
+			if err := jws.Verify(keys[v.KeyID].Public()); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	keys := fixtureKeys()
+	for kid, key := range keys {
+		t.Run(kid, func(t *testing.T) {
+			compact, err := Sign(key, Header{Kid: kid, Typ: "JWT"}, []byte(`{"sub":"u1"}`))
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			jws, err := Parse(compact)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if err := jws.Verify(key.Public()); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+
+			// Any other fixture key must be rejected.
+			for otherKid, other := range keys {
+				if otherKid == kid {
+					continue
+				}
+				if err := jws.Verify(other.Public()); err == nil {
+					t.Errorf("Verify() with %s key succeeded", otherKid)
+				}
+			}
+		})
+	}
+}
+
+// wrappedSigner hides the concrete key type, as KMS and HSM signers do.
+type wrappedSigner struct {
+	crypto.Signer
+}
+
+func TestSignWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     crypto.Signer
+		alg     string
+		wantErr error
+	}{
+		{"RS256", testkeys.RSA(), RS256, nil},
+		{"PS256", testkeys.RSA(), PS256, nil},
+		{"ES256", testkeys.ECDSA(), ES256, nil},
+		{"EdDSA", testkeys.Ed25519(), EdDSA, nil},
+		{"PS256 with EC key", testkeys.ECDSA(), PS256, ErrInvalidKey},
+		{"ES256 with RSA key", testkeys.RSA(), ES256, ErrInvalidKey},
+		{"HS256", testkeys.RSA(), "HS256", ErrUnsupportedAlg},
+		{"non-concrete signer", wrappedSigner{testkeys.RSA()}, RS256, ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compact, err := SignWith(tt.key, tt.alg, Header{}, []byte(`{"sub":"u1"}`))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SignWith() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			jws, err := Parse(compact)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if jws.Header.Alg != tt.alg {
+				t.Errorf("alg = %q, want %q", jws.Header.Alg, tt.alg)
+			}
+			if err := jws.Verify(tt.key.Public()); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+
+	t.Run("PS256 signature does not verify as RS256", func(t *testing.T) {
+		compact, err := SignWith(testkeys.RSA(), PS256, Header{}, []byte(`{}`))
+		if err != nil {
+			t.Fatalf("SignWith() error = %v", err)
+		}
+		jws, _ := Parse(compact)
+		jws.Header.Alg = RS256
+		if err := jws.Verify(testkeys.RSA().Public()); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+		}
+	})
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		compact string
+		wantErr error
+	}{
+		{"two segments", "a.b", ErrMalformed},
+		{"bad header encoding", "!!.e30.", ErrMalformed},
+		// {"alg":"none"}
+		{"alg none", "eyJhbGciOiJub25lIn0.e30.", ErrUnsupportedAlg},
+		// {"alg":"HS256"}
+		{"alg HS256", "eyJhbGciOiJIUzI1NiJ9.e30.c2ln", ErrUnsupportedAlg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.compact); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyTamperedPayload(t *testing.T) {
+	jws, err := Parse(testkeys.GoldenRS256.Compact)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	jws.signingInput += "x"
+	if err := jws.Verify(testkeys.RSA().Public()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	for kid, key := range fixtureKeys() {
+		t.Run(kid, func(t *testing.T) {
+			jwk, err := NewJWK(key.Public(), kid)
+			if err != nil {
+				t.Fatalf("NewJWK() error = %v", err)
+			}
+			raw, err := json.Marshal(JWKS{Keys: []JWK{jwk}})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var set JWKS
+			if err := json.Unmarshal(raw, &set); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			got, err := set.Key(kid)
+			if err != nil {
+				t.Fatalf("Key() error = %v", err)
+			}
+			pub, err := got.PublicKey()
+			if err != nil {
+				t.Fatalf("PublicKey() error = %v", err)
+			}
+			type equaler interface{ Equal(crypto.PublicKey) bool }
+			if !pub.(equaler).Equal(key.Public()) {
+				t.Errorf("decoded key does not match original")
+			}
+		})
+	}
+}
+
+func TestJWKRejectsOffCurvePoint(t *testing.T) {
+	jwk, _ := NewJWK(testkeys.ECDSA().Public(), "ec")
+	jwk.Y = jwk.X
+	if _, err := jwk.PublicKey(); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("PublicKey() error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// RFC 7638, Section 3.1.
+	jwk := JWK{
+		Kty: KeyTypeRSA,
+		Kid: "2011-04-29",
+		E:   "AQAB",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+	got, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatalf("Thumbprint() error = %v", err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("Thumbprint() = %s, want %s", got, want)
+	}
+}
+
+func FuzzParseVerify(f *testing.F) {
+	authentic := map[string]bool{}
+	for _, v := range testkeys.GoldenJWS() {
+		f.Add(v.Compact)
+		authentic[v.Compact[:strings.LastIndexByte(v.Compact, '.')]] = true
+	}
+	f.Add("eyJhbGciOiJub25lIn0.e30.")
+	f.Add("eyJhbGciOiJIUzI1NiJ9.e30.c2ln")
+	f.Add("a.b")
+	f.Add("..")
+
+	keys := fixtureKeys()
+	f.Fuzz(func(t *testing.T, compact string) {
+		jws, err := Parse(compact)
+		if err != nil {
+			return
+		}
+		if !Supported(jws.Header.Alg) {
+			t.Fatalf("Parse() accepted alg %q", jws.Header.Alg)
+		}
+		for kid, key := range keys {
+			if jws.Verify(key.Public()) != nil {
+				continue
+			}
+			if !KeyMatches(jws.Header.Alg, key.Public()) {
+				t.Errorf("Verify() accepted alg %s with key %s", jws.Header.Alg, kid)
+			}
+			// Nothing but the golden vectors carries a real signature from the fixture keys.
+			if !authentic[jws.signingInput] {
+				t.Errorf("Verify() accepted forged signing input %q with key %s", jws.signingInput, kid)
+			}
+		}
+	})
+}
+
+func FuzzJWKPublicKey(f *testing.F) {
+	for kid, key := range fixtureKeys() {
+		jwk, err := NewJWK(key.Public(), kid)
+		if err != nil {
+			f.Fatalf("NewJWK() error = %v", err)
+		}
+		raw, err := json.Marshal(jwk)
+		if err != nil {
+			f.Fatalf("Marshal() error = %v", err)
+		}
+		f.Add(raw)
+	}
+	f.Add([]byte(`{"kty":"EC","crv":"P-256","x":"","y":""}`))
+	f.Add([]byte(`{"kty":"RSA","n":"AQ","e":"AA"}`))
+	f.Add([]byte(`{"kty":"OKP","crv":"Ed25519","x":"AA"}`))
+	f.Add([]byte(`{}`))
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		var jwk JWK
+		if json.Unmarshal(raw, &jwk) != nil {
+			return
+		}
+		_, _ = jwk.Thumbprint()
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			return
+		}
+		// Whatever decodes must re-encode to the same key.
+		again, err := NewJWK(pub, jwk.Kid)
+		if err != nil {
+			t.Fatalf("NewJWK() rejected a decoded %s key: %v", jwk.Kty, err)
+		}
+		roundTrip, err := again.PublicKey()
+		if err != nil {
+			t.Fatalf("PublicKey() rejected a re-encoded %s key: %v", jwk.Kty, err)
+		}
+		type equaler interface{ Equal(crypto.PublicKey) bool }
+		if !roundTrip.(equaler).Equal(pub) {
+			t.Errorf("%s key changed across a JWK round trip", jwk.Kty)
+		}
+	})
+}
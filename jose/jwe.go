@@ -0,0 +1,185 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jose
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDecryption is returned when a JWE cannot be decrypted with the given key.
+var ErrDecryption = errors.New("decryption failed")
+
+// Supported JWE algorithms
+const (
+	// AlgDir uses the shared key directly as the content encryption key
+	AlgDir = "dir"
+	// EncA256GCM is AES-256-GCM content encryption
+	EncA256GCM = "A256GCM"
+)
+
+// strictB64 rejects base64url with non-zero trailing bits, which would otherwise
+// give one JWE several accepted spellings.
+var strictB64 = base64.RawURLEncoding.Strict()
+
+// a256KeySize is the key length of A256GCM.
+const a256KeySize = 32
+
+// JWEHeader is the protected header of a JWE.
+type JWEHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Encrypt produces a compact JWE of plaintext under a 256-bit shared key.
+//
+// Purpose: Self-contained encrypted artifacts, such as stateless authorization codes.
+// Domain: Cryptography
+// Security: "dir" key management with A256GCM; the protected header is authenticated
+// as additional data and a fresh random IV is used for every call.
+// Audited: No
+// Errors: ErrInvalidKey, encryption errors
+func Encrypt(key []byte, h JWEHeader, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	h.Alg, h.Enc = AlgDir, EncA256GCM
+
+	rawHeader, err := json.Marshal(h)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode header: %w", err)
+	}
+	protected := base64.RawURLEncoding.EncodeToString(rawHeader)
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("failed to generate iv: %w", err)
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return strings.Join([]string{
+		protected,
+		"", // no encrypted key with "dir"
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// Decrypt authenticates and decrypts a compact JWE produced by Encrypt.
+//
+// Purpose: Opens self-contained encrypted artifacts.
+// Domain: Cryptography
+// Security: Only "dir"/A256GCM is accepted; any tampering with the header, IV,
+// ciphertext, or tag fails authentication. Segments must be canonically encoded, so
+// each plaintext has exactly one accepted serialization per encryption.
+// Audited: No
+// Errors: ErrMalformed, ErrUnsupportedAlg, ErrInvalidKey, ErrDecryption
+func Decrypt(key []byte, compact string) (*JWEHeader, []byte, error) {
+	h, parts, err := parseJWE(compact)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	iv, err := decodeSegment(parts[2])
+	if err != nil || len(iv) != gcm.NonceSize() {
+		return nil, nil, ErrMalformed
+	}
+	ciphertext, err := decodeSegment(parts[3])
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+	tag, err := decodeSegment(parts[4])
+	if err != nil || len(tag) != gcm.Overhead() {
+		return nil, nil, ErrMalformed
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, nil, ErrDecryption
+	}
+	return h, plaintext, nil
+}
+
+// ParseJWEHeader returns the protected header of a compact JWE without decrypting it.
+//
+// Purpose: Key selection, such as by kid, before Decrypt.
+// Domain: Cryptography
+// Security: The header is not authenticated until Decrypt succeeds; use it only to
+// choose the key to decrypt with.
+// Audited: No
+// Errors: ErrMalformed, ErrUnsupportedAlg
+func ParseJWEHeader(compact string) (*JWEHeader, error) {
+	h, _, err := parseJWE(compact)
+	return h, err
+}
+
+// parseJWE splits a compact JWE and decodes its protected header.
+func parseJWE(compact string) (*JWEHeader, []string, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 5 || parts[1] != "" {
+		return nil, nil, ErrMalformed
+	}
+	rawHeader, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+	var h JWEHeader
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, nil, ErrMalformed
+	}
+	if h.Alg != AlgDir || h.Enc != EncA256GCM {
+		return nil, nil, ErrUnsupportedAlg
+	}
+	return &h, parts, nil
+}
+
+// decodeSegment decodes a base64url segment that must re-encode to exactly s. The
+// decoder skips CR and LF, which would otherwise give one JWE several spellings.
+func decodeSegment(s string) ([]byte, error) {
+	b, err := strictB64.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if strictB64.EncodeToString(b) != s {
+		return nil, ErrMalformed
+	}
+	return b, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != a256KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return cipher.NewGCM(block)
+}
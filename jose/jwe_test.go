@@ -0,0 +1,116 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jose
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/crypto/testkeys"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	compact, err := Encrypt(key, JWEHeader{Kid: "k1"}, []byte(`{"sub":"u1"}`))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if strings.Contains(compact, "u1") {
+		t.Error("plaintext visible in JWE")
+	}
+
+	h, plaintext, err := Decrypt(key, compact)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if h.Alg != AlgDir || h.Enc != EncA256GCM || h.Kid != "k1" {
+		t.Errorf("header = %+v", h)
+	}
+	if string(plaintext) != `{"sub":"u1"}` {
+		t.Errorf("plaintext = %s", plaintext)
+	}
+
+	other, _ := Encrypt(key, JWEHeader{}, []byte(`{"sub":"u1"}`))
+	if other == compact {
+		t.Error("Encrypt() reused an IV")
+	}
+}
+
+func TestDecryptRejects(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	compact, err := Encrypt(key, JWEHeader{}, []byte("payload"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	parts := strings.Split(compact, ".")
+
+	tests := []struct {
+		name    string
+		key     []byte
+		compact string
+		wantErr error
+	}{
+		{"wrong key", bytes.Repeat([]byte{8}, 32), compact, ErrDecryption},
+		{"short key", key[:16], compact, ErrInvalidKey},
+		{"four segments", key, strings.Join(parts[:4], "."), ErrMalformed},
+		{"encrypted key present", key, strings.Join([]string{parts[0], "a2V5", parts[2], parts[3], parts[4]}, "."), ErrMalformed},
+		// {"alg":"RSA-OAEP","enc":"A256GCM"}
+		{"key wrapping alg", key, strings.Join([]string{"eyJhbGciOiJSU0EtT0FFUCIsImVuYyI6IkEyNTZHQ00ifQ", "", parts[2], parts[3], parts[4]}, "."), ErrUnsupportedAlg},
+		// {"alg":"dir","enc":"A256GCM","kid":"x"}
+		{"tampered header", key, strings.Join([]string{"eyJhbGciOiJkaXIiLCJlbmMiOiJBMjU2R0NNIiwia2lkIjoieCJ9", "", parts[2], parts[3], parts[4]}, "."), ErrDecryption},
+		{"non-canonical tag", key, strings.Join([]string{parts[0], "", parts[2], parts[3], parts[4][:len(parts[4])-1] + nonCanonical(parts[4])}, "."), ErrMalformed},
+		{"newline in iv", key, strings.Join([]string{parts[0], "", parts[2][:4] + "\n" + parts[2][4:], parts[3], parts[4]}, "."), ErrMalformed},
+		{"newline in ciphertext", key, strings.Join([]string{parts[0], "", parts[2], parts[3][:2] + "\r\n" + parts[3][2:], parts[4]}, "."), ErrMalformed},
+		{"newline in tag", key, strings.Join([]string{parts[0], "", parts[2], parts[3], parts[4] + "\n"}, "."), ErrMalformed},
+		{"tampered tag", key, strings.Join([]string{parts[0], "", parts[2], parts[3], "AAAAAAAAAAAAAAAAAAAAAA"}, "."), ErrDecryption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decrypt(tt.key, tt.compact); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func FuzzDecrypt(f *testing.F) {
+	f.Add(testkeys.GoldenJWEDirA256GCM.Compact)
+	f.Add("eyJhbGciOiJSU0EtT0FFUCIsImVuYyI6IkEyNTZHQ00ifQ..oKGio6Slpqeoqaqr.AA.AA")
+	f.Add("....")
+	f.Add("")
+
+	key := testkeys.DirectKey()
+	f.Fuzz(func(t *testing.T, compact string) {
+		h, plaintext, err := Decrypt(key, compact)
+		if err != nil {
+			return
+		}
+		if h.Alg != AlgDir || h.Enc != EncA256GCM {
+			t.Errorf("Decrypt() accepted header %+v", h)
+		}
+		// Only the golden ciphertext was produced under the fixture key.
+		if string(plaintext) != testkeys.GoldenJWEPlaintext {
+			t.Errorf("Decrypt() returned forged plaintext %q", plaintext)
+		}
+	})
+}
+
+// nonCanonical returns a last character for s that sets trailing bits the decoder ignores.
+func nonCanonical(s string) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	return string(alphabet[strings.IndexByte(alphabet, s[len(s)-1])|1])
+}
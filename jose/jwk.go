@@ -0,0 +1,213 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK key types and curves
+const (
+	KeyTypeRSA = "RSA"
+	KeyTypeEC  = "EC"
+	KeyTypeOKP = "OKP"
+
+	CurveP256    = "P-256"
+	CurveEd25519 = "Ed25519"
+)
+
+// p256CoordSize is the byte length of a P-256 coordinate.
+const p256CoordSize = 32
+
+// minRSAModulusBits is the smallest RSA key accepted (RFC 7518 §3.3).
+const minRSAModulusBits = 2048
+
+// JWK is a public JSON Web Key.
+//
+// Purpose: Wire representation of a verification key (RFC 7517).
+// Domain: Cryptography
+// Invariants: Only public members are modeled; private JWKs are never serialized.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Key returns the key with the given kid.
+func (s *JWKS) Key(kid string) (*JWK, error) {
+	for i := range s.Keys {
+		if s.Keys[i].Kid == kid {
+			return &s.Keys[i], nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// NewJWK encodes a public key as a JWK.
+//
+// Purpose: Publishes verification keys and embeds DPoP keys in proof headers.
+// Domain: Cryptography
+// Audited: No
+// Errors: ErrInvalidKey
+func NewJWK(pub crypto.PublicKey, kid string) (JWK, error) {
+	enc := base64.RawURLEncoding.EncodeToString
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: KeyTypeRSA, Kid: kid, Use: "sig", Alg: RS256,
+			N: enc(k.N.Bytes()),
+			E: enc(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return JWK{}, ErrInvalidKey
+		}
+		raw, err := k.Bytes()
+		if err != nil {
+			return JWK{}, ErrInvalidKey
+		}
+		// raw is the uncompressed point 0x04 || X || Y.
+		return JWK{
+			Kty: KeyTypeEC, Kid: kid, Use: "sig", Alg: ES256, Crv: CurveP256,
+			X: enc(raw[1 : 1+p256CoordSize]),
+			Y: enc(raw[1+p256CoordSize:]),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{Kty: KeyTypeOKP, Kid: kid, Use: "sig", Alg: EdDSA, Crv: CurveEd25519, X: enc(k)}, nil
+	default:
+		return JWK{}, ErrInvalidKey
+	}
+}
+
+// PublicKey decodes the JWK into a crypto.PublicKey.
+//
+// Purpose: Turns a fetched or embedded JWK into a verification key.
+// Domain: Cryptography
+// Security: EC points are validated to lie on the curve; RSA moduli shorter
+// than 2048 bits are rejected.
+// Audited: No
+// Errors: ErrInvalidKey
+func (k *JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case KeyTypeRSA:
+		n, err := b64("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		modulus := new(big.Int).SetBytes(n)
+		if modulus.BitLen() < minRSAModulusBits {
+			return nil, fmt.Errorf("%w: RSA modulus shorter than %d bits", ErrInvalidKey, minRSAModulusBits)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("%w: bad RSA exponent", ErrInvalidKey)
+		}
+		return &rsa.PublicKey{N: modulus, E: int(exp.Int64())}, nil
+	case KeyTypeEC:
+		if k.Crv != CurveP256 {
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidKey, k.Crv)
+		}
+		x, err := b64("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b64("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != p256CoordSize || len(y) != p256CoordSize {
+			return nil, fmt.Errorf("%w: bad EC coordinate length", ErrInvalidKey)
+		}
+		point := append(append([]byte{4}, x...), y...)
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+		return pub, nil
+	case KeyTypeOKP:
+		if k.Crv != CurveEd25519 {
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidKey, k.Crv)
+		}
+		x, err := b64("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: bad Ed25519 key length", ErrInvalidKey)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %q", ErrInvalidKey, k.Kty)
+	}
+}
+
+// Thumbprint returns the base64url SHA-256 JWK thumbprint (RFC 7638).
+//
+// Purpose: Stable key identifier used for DPoP "jkt" confirmation.
+// Domain: Cryptography
+// Audited: No
+// Errors: ErrInvalidKey
+func (k *JWK) Thumbprint() (string, error) {
+	// Required members only, in lexicographic order, without whitespace.
+	var canonical string
+	switch k.Kty {
+	case KeyTypeRSA:
+		if k.E == "" || k.N == "" {
+			return "", ErrInvalidKey
+		}
+		canonical = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, k.E, k.Kty, k.N)
+	case KeyTypeEC:
+		if k.Crv == "" || k.X == "" || k.Y == "" {
+			return "", ErrInvalidKey
+		}
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, k.Crv, k.Kty, k.X, k.Y)
+	case KeyTypeOKP:
+		if k.Crv == "" || k.X == "" {
+			return "", ErrInvalidKey
+		}
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, k.Crv, k.Kty, k.X)
+	default:
+		return "", ErrInvalidKey
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keys manages the asymmetric keys each tenant's tokens are signed with.
+// A tenant has at most one active key, which signs, and at most one next key,
+// which is published in the JWKS ahead of activation so relying parties have
+// cached it before it signs anything. A retired key stays published until its
+// grace period ends so the tokens it signed keep verifying. Private keys are
+// stored encrypted under a key derived from the deployment secret.
+package keys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	corecrypto "github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+// RSAKeyBits is the modulus size of generated RS256 and PS256 keys.
+const RSAKeyBits = 2048
+
+// privateKeyType is the JWE "typ" of an encrypted private key.
+const privateKeyType = "signing-key"
+
+// Domain errors
+var (
+	ErrKeyNotFound   = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "signing key not found")
+	ErrKeyConflict   = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "signing key state changed concurrently")
+	ErrInvalidPolicy = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid key rotation policy")
+	ErrCorruptKey    = apperror.New(apperror.CodeInternal, apperror.StatusInternalServerError, "", "stored signing key does not match its public key")
+)
+
+// State is the position of a key in its rotation lifecycle.
+type State string
+
+// Key states
+const (
+	// StateNext keys are published but do not sign yet.
+	StateNext State = "next"
+	// StateActive keys sign the tenant's tokens; a tenant has at most one.
+	StateActive State = "active"
+	// StateRetired keys no longer sign and stay published until their grace period ends.
+	StateRetired State = "retired"
+)
+
+// Key is a tenant signing key.
+//
+// Purpose: One asymmetric key and where it stands in its rotation.
+// Domain: Cryptography
+// Invariants: ID is the RFC 7638 thumbprint of the public key and is the JWS "kid".
+// PrivateKey is a compact JWE of the PKCS #8 private key and is never logged or
+// serialized. A tenant has at most one next and one active key. ActivatedAt is set
+// once the key signs; RetiredAt once it stops.
+type Key struct {
+	ID          string     `json:"kid"`
+	TenantID    string     `json:"tenant_id"`
+	Algorithm   string     `json:"alg"`
+	State       State      `json:"state"`
+	PublicKey   jose.JWK   `json:"public_key"`
+	PrivateKey  string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+}
+
+// activatedAt returns when k started signing, falling back to its creation time.
+func (k *Key) activatedAt() time.Time {
+	if k.ActivatedAt != nil {
+		return *k.ActivatedAt
+	}
+	return k.CreatedAt
+}
+
+// Repository defines signing key persistence.
+//
+// Purpose: Abstraction for storing tenant signing keys.
+// Domain: Cryptography
+type Repository interface {
+	// Create stores a new key, or returns ErrKeyConflict if the tenant already has a key in its state
+	Create(ctx context.Context, k *Key) error
+	// List returns the tenant's stored keys, newest first
+	List(ctx context.Context, tenantID string) ([]*Key, error)
+	// ListTenants returns the IDs of tenants holding at least one key
+	ListTenants(ctx context.Context) ([]string, error)
+	// Activate retires the tenant's active key and activates its next key keyID in one
+	// transaction, or returns ErrKeyConflict if keyID is no longer the next key
+	Activate(ctx context.Context, tenantID, keyID string, at time.Time) error
+	// Retire retires a key that is not yet retired, or returns ErrKeyNotFound
+	Retire(ctx context.Context, keyID string, at time.Time) error
+	// DeleteRetired removes the tenant's keys retired before cutoff and returns their IDs
+	DeleteRetired(ctx context.Context, tenantID string, cutoff time.Time) ([]string, error)
+}
+
+// Policy sets the rotation timeline.
+//
+// Purpose: How long keys sign, how early successors are published, and how long retired keys stay verifiable.
+// Domain: Cryptography
+// Invariants: All durations are positive and PrePublish is shorter than RotationPeriod.
+// PrePublish must exceed how long relying parties cache the JWKS, and RetirementGrace
+// the longest lifetime of a signed token.
+type Policy struct {
+	RotationPeriod  time.Duration
+	PrePublish      time.Duration
+	RetirementGrace time.Duration
+}
+
+// DefaultPolicy rotates every 90 days, publishing the next key two days ahead and
+// keeping retired keys for a week.
+func DefaultPolicy() Policy {
+	return Policy{
+		RotationPeriod:  90 * 24 * time.Hour,
+		PrePublish:      48 * time.Hour,
+		RetirementGrace: 7 * 24 * time.Hour,
+	}
+}
+
+// Validate checks the policy invariants.
+func (p Policy) Validate() error {
+	if p.RotationPeriod <= 0 || p.PrePublish <= 0 || p.RetirementGrace <= 0 {
+		return fmt.Errorf("%w: durations must be positive", ErrInvalidPolicy)
+	}
+	if p.PrePublish >= p.RotationPeriod {
+		return fmt.Errorf("%w: pre-publication must be shorter than the rotation period", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// Generate creates a private key for alg.
+//
+// Purpose: Key generation for every supported signing algorithm.
+// Domain: Cryptography
+// Security: RSA keys are RSAKeyBits long; ES256 uses P-256 and EdDSA Ed25519.
+// Audited: No
+// Errors: jose.ErrUnsupportedAlg, generation errors
+func Generate(alg string) (crypto.Signer, error) {
+	switch alg {
+	case jose.RS256, jose.PS256:
+		return rsa.GenerateKey(rand.Reader, RSAKeyBits)
+	case jose.ES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jose.EdDSA:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("%w: %s", jose.ErrUnsupportedAlg, alg)
+	}
+}
+
+// newKey wraps signer as a key of tenantID, encrypting it under masterKey.
+func newKey(masterKey []byte, tenantID, alg string, signer crypto.Signer, state State, now time.Time) (*Key, error) {
+	jwk, err := jose.NewJWK(signer.Public(), "")
+	if err != nil {
+		return nil, err
+	}
+	kid, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	jwk.Kid, jwk.Alg = kid, alg
+
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	wrap, err := wrappingKey(masterKey, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := jose.Encrypt(wrap.Secret, jose.JWEHeader{Kid: wrap.ID, Typ: privateKeyType}, der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt private key: %w", err)
+	}
+
+	k := &Key{
+		ID:         kid,
+		TenantID:   tenantID,
+		Algorithm:  alg,
+		State:      state,
+		PublicKey:  jwk,
+		PrivateKey: sealed,
+		CreatedAt:  now,
+	}
+	if state == StateActive {
+		k.ActivatedAt = &now
+	}
+	return k, nil
+}
+
+// signer decrypts k's private key.
+func (k *Key) signer(masterKey []byte) (crypto.Signer, error) {
+	wrap, err := wrappingKey(masterKey, k.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	h, der, err := jose.Decrypt(wrap.Secret, k.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt private key: %w", err)
+	}
+	if h.Typ != privateKeyType {
+		return nil, ErrCorruptKey
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, ErrCorruptKey
+	}
+
+	// A private key swapped in from another row would sign under the wrong kid.
+	jwk, err := jose.NewJWK(signer.Public(), "")
+	if err != nil {
+		return nil, err
+	}
+	if kid, err := jwk.Thumbprint(); err != nil || kid != k.ID {
+		return nil, ErrCorruptKey
+	}
+	return signer, nil
+}
+
+// wrappingKey derives the key tenantID's private keys are encrypted under.
+func wrappingKey(masterKey []byte, tenantID string) (corecrypto.HMACKey, error) {
+	return corecrypto.DeriveHMACKey(masterKey, corecrypto.PurposeSigningKey, tenantID)
+}
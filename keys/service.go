@@ -0,0 +1,460 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/jose"
+	"github.com/opentrusty/opentrusty-core/oidc"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Rotation reasons recorded in audit metadata
+const (
+	reasonBootstrap        = "bootstrap"
+	reasonScheduled        = "scheduled"
+	reasonAlgorithmChanged = "algorithm_changed"
+)
+
+// Service manages tenant signing keys.
+//
+// Purpose: Key generation, rotation, and JWKS publication.
+// Domain: Cryptography
+// Invariants: A key is published before it signs: scheduled rotation activates a next
+// key only after it has been in the JWKS for Policy.PrePublish. Retired keys stay in the
+// JWKS for Policy.RetirementGrace. Every phase is audited. Decrypted private keys are
+// cached in memory by kid and never leave the process.
+type Service struct {
+	repo        Repository
+	masterKey   []byte
+	auditLogger audit.Logger
+	algs        oidc.AlgorithmSource
+	policy      Policy
+	tracer      tracing.Tracer
+	now         func() time.Time
+
+	mu      sync.RWMutex
+	signers map[string]crypto.Signer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithPolicy replaces DefaultPolicy.
+func WithPolicy(p Policy) Option {
+	return func(s *Service) { s.policy = p }
+}
+
+// WithAlgorithms generates each tenant's keys for the algorithm a reports. Without
+// it keys are RS256.
+func WithAlgorithms(a oidc.AlgorithmSource) Option {
+	return func(s *Service) { s.algs = a }
+}
+
+// WithTracer emits spans for key lookups and rotations on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithClock overrides the clock driving the rotation timeline.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.now = c.Now }
+}
+
+// NewService creates a new signing key service. Private keys are encrypted under
+// keys derived from masterKey, so masterKey must stay the same across restarts.
+//
+// Purpose: Constructor for the signing key service.
+// Domain: Cryptography
+// Audited: No
+// Errors: ErrInvalidPolicy
+func NewService(repo Repository, masterKey []byte, auditLogger audit.Logger, opts ...Option) (*Service, error) {
+	s := &Service{
+		repo:        repo,
+		masterKey:   masterKey,
+		auditLogger: auditLogger,
+		policy:      DefaultPolicy(),
+		now:         time.Now,
+		signers:     make(map[string]crypto.Signer),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.policy.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SigningKey returns the key tenantID's tokens are signed with, generating the
+// tenant's first key on demand.
+//
+// Purpose: oidc.KeySource backed by persisted, rotating keys.
+// Domain: Cryptography
+// Security: If the tenant's signing algorithm changed since its key was generated,
+// the key is replaced immediately: it can no longer sign, so pre-publication is skipped.
+// Audited: Yes (SigningKeyGenerated, SigningKeyActivated, SigningKeyRetired when a key is created or replaced)
+// Errors: ErrCorruptKey, jose.ErrUnsupportedAlg, System errors
+func (s *Service) SigningKey(ctx context.Context, tenantID string) (*oidc.SigningKey, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "keys.SigningKey")
+	defer span.End()
+
+	active, err := s.activeKey(ctx, tenantID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	signer, err := s.signer(active)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return &oidc.SigningKey{Signer: signer, KeyID: active.ID}, nil
+}
+
+// JWKS returns the public keys relying parties verify tenantID's tokens with: the
+// active key, the next key, and retired keys within their grace period.
+//
+// Purpose: Body of the tenant's jwks_uri.
+// Domain: Cryptography
+// Security: Only public key members are returned.
+// Audited: No
+// Errors: System errors
+func (s *Service) JWKS(ctx context.Context, tenantID string) (*jose.JWKS, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "keys.JWKS")
+	defer span.End()
+
+	stored, err := s.repo.List(ctx, tenantID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	now := s.now()
+	set := &jose.JWKS{Keys: []jose.JWK{}}
+	for _, state := range []State{StateActive, StateNext, StateRetired} {
+		for _, k := range stored {
+			if k.State != state {
+				continue
+			}
+			if state == StateRetired && !s.inGrace(k, now) {
+				continue
+			}
+			set.Keys = append(set.Keys, k.PublicKey)
+		}
+	}
+	return set, nil
+}
+
+// Rotate advances tenantID's keys along the rotation timeline: it generates the
+// first key, publishes a next key PrePublish before the active key's rotation is
+// due, activates it once due, and purges retired keys past their grace period.
+//
+// Purpose: Scheduled, zero-downtime key rollover.
+// Domain: Cryptography
+// Security: A next key is activated only after it has been published for PrePublish.
+// A next key generated for a previous signing algorithm is retired without signing.
+// Concurrent rotations on several instances are resolved by the repository's
+// one-next, one-active constraint; the loser re-reads and moves on.
+// Audited: Yes (SigningKeyGenerated, SigningKeyActivated, SigningKeyRetired, SigningKeyPurged)
+// Errors: jose.ErrUnsupportedAlg, System errors
+func (s *Service) Rotate(ctx context.Context, tenantID string) error {
+	ctx, span := tracing.Start(ctx, s.tracer, "keys.Rotate")
+	defer span.End()
+
+	if err := s.rotate(ctx, tenantID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// RotateDue runs Rotate for every tenant holding keys. Tenants without keys get
+// their first one when they first sign.
+//
+// Purpose: Scheduler job for key rotation.
+// Domain: Cryptography
+// Audited: Yes (see Rotate)
+// Errors: Joined per-tenant errors, System errors
+func (s *Service) RotateDue(ctx context.Context) error {
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants with signing keys: %w", err)
+	}
+	var errs []error
+	for _, tenantID := range tenants {
+		if err := s.Rotate(ctx, tenantID); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rotate implements Rotate.
+func (s *Service) rotate(ctx context.Context, tenantID string) error {
+	alg, err := s.algorithm(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	active, next, err := s.current(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if active == nil || active.Algorithm != alg {
+		if _, err := s.replaceActive(ctx, tenantID, active, next, alg); err != nil {
+			return err
+		}
+		return s.purge(ctx, tenantID, now)
+	}
+
+	if next != nil && next.Algorithm != alg {
+		if err := s.retire(ctx, next, reasonAlgorithmChanged); err != nil {
+			return err
+		}
+		next = nil
+	}
+
+	// Another instance winning a race below has already made the same step.
+	due := active.activatedAt().Add(s.policy.RotationPeriod)
+	if next == nil && !now.Before(due.Add(-s.policy.PrePublish)) {
+		next, err = s.generate(ctx, tenantID, alg, StateNext, reasonScheduled)
+		if err != nil && !errors.Is(err, ErrKeyConflict) {
+			return err
+		}
+	}
+	if next != nil && !now.Before(due) && !now.Before(next.CreatedAt.Add(s.policy.PrePublish)) {
+		if err := s.activate(ctx, active, next, reasonScheduled); err != nil && !errors.Is(err, ErrKeyConflict) {
+			return err
+		}
+	}
+
+	return s.purge(ctx, tenantID, now)
+}
+
+// activeKey returns tenantID's active key, creating or replacing it when it is
+// missing or was generated for another algorithm.
+func (s *Service) activeKey(ctx context.Context, tenantID string) (*Key, error) {
+	active, next, err := s.current(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil && s.algs == nil {
+		return active, nil
+	}
+	alg, err := s.algorithm(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil && active.Algorithm == alg {
+		return active, nil
+	}
+	return s.replaceActive(ctx, tenantID, active, next, alg)
+}
+
+// replaceActive makes a key of alg active without pre-publication: the tenant has no
+// key yet, or its active key cannot sign with alg. A next key of alg is promoted.
+func (s *Service) replaceActive(ctx context.Context, tenantID string, active, next *Key, alg string) (*Key, error) {
+	if active == nil {
+		k, err := s.generate(ctx, tenantID, alg, StateActive, reasonBootstrap)
+		if errors.Is(err, ErrKeyConflict) {
+			return s.reloadActive(ctx, tenantID)
+		}
+		return k, err
+	}
+
+	if next != nil && next.Algorithm != alg {
+		if err := s.retire(ctx, next, reasonAlgorithmChanged); err != nil {
+			return nil, err
+		}
+		next = nil
+	}
+	if next == nil {
+		var err error
+		next, err = s.generate(ctx, tenantID, alg, StateNext, reasonAlgorithmChanged)
+		if errors.Is(err, ErrKeyConflict) {
+			return s.reloadActive(ctx, tenantID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.activate(ctx, active, next, reasonAlgorithmChanged); err != nil {
+		if errors.Is(err, ErrKeyConflict) {
+			return s.reloadActive(ctx, tenantID)
+		}
+		return nil, err
+	}
+	return next, nil
+}
+
+// reloadActive re-reads the active key after losing a race with another instance.
+func (s *Service) reloadActive(ctx context.Context, tenantID string) (*Key, error) {
+	active, _, err := s.current(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return nil, ErrKeyNotFound
+	}
+	return active, nil
+}
+
+// current returns tenantID's active and next keys, either of which may be nil.
+func (s *Service) current(ctx context.Context, tenantID string) (active, next *Key, err error) {
+	stored, err := s.repo.List(ctx, tenantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	for _, k := range stored {
+		switch k.State {
+		case StateActive:
+			active = k
+		case StateNext:
+			next = k
+		}
+	}
+	return active, next, nil
+}
+
+// generate creates, stores, and audits a key of alg in state.
+func (s *Service) generate(ctx context.Context, tenantID, alg string, state State, reason string) (*Key, error) {
+	signer, err := Generate(alg)
+	if err != nil {
+		return nil, err
+	}
+	k, err := newKey(s.masterKey, tenantID, alg, signer, state, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, k); err != nil {
+		if errors.Is(err, ErrKeyConflict) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	s.log(ctx, audit.TypeSigningKeyGenerated, k, reason)
+	if state == StateActive {
+		s.log(ctx, audit.TypeSigningKeyActivated, k, reason)
+	}
+	return k, nil
+}
+
+// activate promotes next and retires active.
+func (s *Service) activate(ctx context.Context, active, next *Key, reason string) error {
+	now := s.now()
+	if err := s.repo.Activate(ctx, next.TenantID, next.ID, now); err != nil {
+		if errors.Is(err, ErrKeyConflict) {
+			return err
+		}
+		return fmt.Errorf("failed to activate signing key: %w", err)
+	}
+	next.State, next.ActivatedAt = StateActive, &now
+	s.log(ctx, audit.TypeSigningKeyActivated, next, reason)
+	if active != nil {
+		active.State, active.RetiredAt = StateRetired, &now
+		s.log(ctx, audit.TypeSigningKeyRetired, active, reason)
+	}
+	return nil
+}
+
+// retire retires k without a successor.
+func (s *Service) retire(ctx context.Context, k *Key, reason string) error {
+	now := s.now()
+	if err := s.repo.Retire(ctx, k.ID, now); err != nil {
+		return fmt.Errorf("failed to retire signing key: %w", err)
+	}
+	k.State, k.RetiredAt = StateRetired, &now
+	s.log(ctx, audit.TypeSigningKeyRetired, k, reason)
+	return nil
+}
+
+// purge removes tenantID's retired keys whose grace period has ended.
+func (s *Service) purge(ctx context.Context, tenantID string, now time.Time) error {
+	purged, err := s.repo.DeleteRetired(ctx, tenantID, now.Add(-s.policy.RetirementGrace))
+	if err != nil {
+		return fmt.Errorf("failed to purge retired signing keys: %w", err)
+	}
+	for _, kid := range purged {
+		s.mu.Lock()
+		delete(s.signers, kid)
+		s.mu.Unlock()
+		s.log(ctx, audit.TypeSigningKeyPurged, &Key{ID: kid, TenantID: tenantID}, reasonScheduled)
+	}
+	return nil
+}
+
+// signer returns k's decrypted private key, caching it by kid.
+func (s *Service) signer(k *Key) (crypto.Signer, error) {
+	s.mu.RLock()
+	signer, ok := s.signers[k.ID]
+	s.mu.RUnlock()
+	if ok {
+		return signer, nil
+	}
+
+	signer, err := k.signer(s.masterKey)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.signers[k.ID] = signer
+	s.mu.Unlock()
+	return signer, nil
+}
+
+// algorithm returns the algorithm tenantID's keys are generated for.
+func (s *Service) algorithm(ctx context.Context, tenantID string) (string, error) {
+	if s.algs == nil {
+		return jose.RS256, nil
+	}
+	alg, err := s.algs.SigningAlgorithm(ctx, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get signing algorithm: %w", err)
+	}
+	return alg, nil
+}
+
+// inGrace reports whether the retired key k is still published at now.
+func (s *Service) inGrace(k *Key, now time.Time) bool {
+	return k.RetiredAt != nil && now.Before(k.RetiredAt.Add(s.policy.RetirementGrace))
+}
+
+// log records a key lifecycle event.
+func (s *Service) log(ctx context.Context, eventType string, k *Key, reason string) {
+	metadata := map[string]any{
+		audit.AttrTenantID: k.TenantID,
+		audit.AttrReason:   reason,
+	}
+	if k.Algorithm != "" {
+		metadata["alg"] = k.Algorithm
+	}
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     eventType,
+		TenantID: k.TenantID,
+		Resource: audit.ResourceSigningKey,
+		TargetID: k.ID,
+		Metadata: metadata,
+	})
+}
@@ -0,0 +1,352 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+type memoryRepo struct {
+	keys []*Key
+}
+
+func (m *memoryRepo) Create(ctx context.Context, k *Key) error {
+	for _, existing := range m.keys {
+		if existing.TenantID == k.TenantID && existing.State == k.State && k.State != StateRetired {
+			return ErrKeyConflict
+		}
+	}
+	copied := *k
+	m.keys = append(m.keys, &copied)
+	return nil
+}
+
+func (m *memoryRepo) List(ctx context.Context, tenantID string) ([]*Key, error) {
+	var res []*Key
+	for i := len(m.keys) - 1; i >= 0; i-- {
+		if m.keys[i].TenantID == tenantID {
+			copied := *m.keys[i]
+			res = append(res, &copied)
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryRepo) ListTenants(ctx context.Context) ([]string, error) {
+	var tenants []string
+	for _, k := range m.keys {
+		if !slices.Contains(tenants, k.TenantID) {
+			tenants = append(tenants, k.TenantID)
+		}
+	}
+	return tenants, nil
+}
+
+func (m *memoryRepo) Activate(ctx context.Context, tenantID, keyID string, at time.Time) error {
+	var next *Key
+	for _, k := range m.keys {
+		if k.TenantID == tenantID && k.ID == keyID && k.State == StateNext {
+			next = k
+		}
+	}
+	if next == nil {
+		return ErrKeyConflict
+	}
+	for _, k := range m.keys {
+		if k.TenantID == tenantID && k.State == StateActive {
+			k.State, k.RetiredAt = StateRetired, &at
+		}
+	}
+	next.State, next.ActivatedAt = StateActive, &at
+	return nil
+}
+
+func (m *memoryRepo) Retire(ctx context.Context, keyID string, at time.Time) error {
+	for _, k := range m.keys {
+		if k.ID == keyID && k.State != StateRetired {
+			k.State, k.RetiredAt = StateRetired, &at
+			return nil
+		}
+	}
+	return ErrKeyNotFound
+}
+
+func (m *memoryRepo) DeleteRetired(ctx context.Context, tenantID string, cutoff time.Time) ([]string, error) {
+	var deleted []string
+	m.keys = slices.DeleteFunc(m.keys, func(k *Key) bool {
+		if k.TenantID == tenantID && k.State == StateRetired && k.RetiredAt.Before(cutoff) {
+			deleted = append(deleted, k.ID)
+			return true
+		}
+		return false
+	})
+	return deleted, nil
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+func (m *mockAuditLogger) types() []string {
+	var types []string
+	for _, e := range m.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+type tenantAlgorithms map[string]string
+
+func (a tenantAlgorithms) SigningAlgorithm(ctx context.Context, tenantID string) (string, error) {
+	return a[tenantID], nil
+}
+
+type steppedClock struct {
+	now time.Time
+}
+
+func (c *steppedClock) Now() time.Time { return c.now }
+
+var testPolicy = Policy{RotationPeriod: 30 * 24 * time.Hour, PrePublish: 24 * time.Hour, RetirementGrace: 48 * time.Hour}
+
+func newTestService(t *testing.T, algs tenantAlgorithms) (*Service, *memoryRepo, *mockAuditLogger, *steppedClock) {
+	t.Helper()
+	repo, logger := &memoryRepo{}, &mockAuditLogger{}
+	clk := &steppedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	svc, err := NewService(repo, []byte("test-master-key"), logger,
+		WithPolicy(testPolicy), WithAlgorithms(algs), WithClock(clk))
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	return svc, repo, logger, clk
+}
+
+func kids(t *testing.T, svc *Service, tenantID string) []string {
+	t.Helper()
+	set, err := svc.JWKS(context.Background(), tenantID)
+	if err != nil {
+		t.Fatalf("JWKS() error = %v", err)
+	}
+	var ids []string
+	for _, k := range set.Keys {
+		ids = append(ids, k.Kid)
+	}
+	return ids
+}
+
+func TestSigningKey(t *testing.T) {
+	ctx := context.Background()
+	for _, alg := range []string{jose.RS256, jose.PS256, jose.ES256, jose.EdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			svc, _, logger, _ := newTestService(t, tenantAlgorithms{"t1": alg})
+
+			key, err := svc.SigningKey(ctx, "t1")
+			if err != nil {
+				t.Fatalf("SigningKey() error = %v", err)
+			}
+			again, err := svc.SigningKey(ctx, "t1")
+			if err != nil || again.KeyID != key.KeyID {
+				t.Fatalf("second SigningKey() = %v, %v; want kid %s", again, err, key.KeyID)
+			}
+			if got := logger.types(); !slices.Equal(got, []string{audit.TypeSigningKeyGenerated, audit.TypeSigningKeyActivated}) {
+				t.Errorf("audit events = %v", got)
+			}
+
+			set, err := svc.JWKS(ctx, "t1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			jwk, err := set.Key(key.KeyID)
+			if err != nil {
+				t.Fatalf("active key not published: %v", err)
+			}
+			if jwk.Alg != alg {
+				t.Errorf("published alg = %s, want %s", jwk.Alg, alg)
+			}
+			pub, err := jwk.PublicKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := jose.SignWith(key.Signer, alg, jose.Header{Kid: key.KeyID}, []byte(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			jws, err := jose.Parse(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := jws.Verify(pub); err != nil {
+				t.Errorf("token does not verify with published key: %v", err)
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	ctx := context.Background()
+	svc, _, logger, clk := newTestService(t, tenantAlgorithms{"t1": jose.EdDSA})
+	start := clk.now
+
+	first, err := svc.SigningKey(ctx, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.events = nil
+
+	steps := []struct {
+		name       string
+		at         time.Time
+		wantEvents []string
+		wantKeys   int
+		wantActive string // "first" or "second"
+	}{
+		{"nothing due", start.Add(testPolicy.RotationPeriod - testPolicy.PrePublish - time.Hour), nil, 1, "first"},
+		{"next key published", start.Add(testPolicy.RotationPeriod - testPolicy.PrePublish), []string{audit.TypeSigningKeyGenerated}, 2, "first"},
+		{"next key activated", start.Add(testPolicy.RotationPeriod), []string{audit.TypeSigningKeyActivated, audit.TypeSigningKeyRetired}, 2, "second"},
+		{"retired key in grace", start.Add(testPolicy.RotationPeriod + testPolicy.RetirementGrace - time.Hour), nil, 2, "second"},
+		{"retired key purged", start.Add(testPolicy.RotationPeriod + testPolicy.RetirementGrace + time.Hour), []string{audit.TypeSigningKeyPurged}, 1, "second"},
+	}
+	for _, step := range steps {
+		clk.now = step.at
+		logger.events = nil
+		if err := svc.RotateDue(ctx); err != nil {
+			t.Fatalf("%s: RotateDue() error = %v", step.name, err)
+		}
+		if got := logger.types(); !slices.Equal(got, step.wantEvents) {
+			t.Errorf("%s: audit events = %v, want %v", step.name, got, step.wantEvents)
+		}
+		published := kids(t, svc, "t1")
+		if len(published) != step.wantKeys {
+			t.Errorf("%s: JWKS has %d keys, want %d", step.name, len(published), step.wantKeys)
+		}
+		active, err := svc.SigningKey(ctx, "t1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (active.KeyID == first.KeyID) != (step.wantActive == "first") {
+			t.Errorf("%s: active key = %s, first key = %s, want %s", step.name, active.KeyID, first.KeyID, step.wantActive)
+		}
+		if !slices.Contains(published, active.KeyID) || published[0] != active.KeyID {
+			t.Errorf("%s: active key %s not listed first in %v", step.name, active.KeyID, published)
+		}
+	}
+}
+
+func TestAlgorithmChange(t *testing.T) {
+	ctx := context.Background()
+	algs := tenantAlgorithms{"t1": jose.EdDSA}
+	svc, _, logger, clk := newTestService(t, algs)
+
+	old, err := svc.SigningKey(ctx, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A next key is pending when the tenant switches algorithm.
+	clk.now = clk.now.Add(testPolicy.RotationPeriod - testPolicy.PrePublish)
+	if err := svc.Rotate(ctx, "t1"); err != nil {
+		t.Fatal(err)
+	}
+	logger.events = nil
+
+	algs["t1"] = jose.ES256
+	key, err := svc.SigningKey(ctx, "t1")
+	if err != nil {
+		t.Fatalf("SigningKey() error = %v", err)
+	}
+	if key.KeyID == old.KeyID || !jose.KeyMatches(jose.ES256, key.Signer.Public()) {
+		t.Fatalf("expected a new ES256 key, got kid %s", key.KeyID)
+	}
+	want := []string{
+		audit.TypeSigningKeyRetired, // stale EdDSA next key
+		audit.TypeSigningKeyGenerated,
+		audit.TypeSigningKeyActivated,
+		audit.TypeSigningKeyRetired, // old active key
+	}
+	if got := logger.types(); !slices.Equal(got, want) {
+		t.Errorf("audit events = %v, want %v", got, want)
+	}
+	// The old key still verifies tokens it signed.
+	if published := kids(t, svc, "t1"); !slices.Contains(published, old.KeyID) {
+		t.Errorf("retired key missing from JWKS %v", published)
+	}
+}
+
+func TestSignerIntegrity(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := newTestService(t, tenantAlgorithms{"t1": jose.EdDSA, "t2": jose.EdDSA})
+	if _, err := svc.SigningKey(ctx, "t1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SigningKey(ctx, "t2"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("wrong master key", func(t *testing.T) {
+		other, err := NewService(repo, []byte("other-master-key"), &mockAuditLogger{}, WithPolicy(testPolicy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := other.SigningKey(ctx, "t1"); !errors.Is(err, jose.ErrDecryption) {
+			t.Errorf("expected jose.ErrDecryption, got %v", err)
+		}
+	})
+
+	t.Run("private key swapped between rows", func(t *testing.T) {
+		k1, k2 := repo.keys[0], repo.keys[1]
+		swapped := *k1
+		swapped.PrivateKey = k2.PrivateKey
+		if _, err := swapped.signer([]byte("test-master-key")); err == nil {
+			t.Error("expected a private key sealed for another tenant to be rejected")
+		}
+		swapped.TenantID = k2.TenantID
+		if _, err := swapped.signer([]byte("test-master-key")); !errors.Is(err, ErrCorruptKey) {
+			t.Errorf("expected ErrCorruptKey, got %v", err)
+		}
+	})
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"default", DefaultPolicy(), false},
+		{"zero duration", Policy{RotationPeriod: time.Hour, PrePublish: time.Minute}, true},
+		{"pre-publication longer than rotation", Policy{RotationPeriod: time.Hour, PrePublish: 2 * time.Hour, RetirementGrace: time.Hour}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("expected ErrInvalidPolicy, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lifecycle coordinates ordered, timeout-bounded shutdown of the
+// components wired by the composition root and the embedding application.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultHookTimeout bounds a hook that does not set its own Timeout.
+const DefaultHookTimeout = 10 * time.Second
+
+// Domain errors
+var (
+	ErrInvalidHook   = errors.New("invalid shutdown hook")
+	ErrDuplicateHook = errors.New("shutdown hook already registered")
+	ErrShuttingDown  = errors.New("shutdown already started")
+	ErrHookTimeout   = errors.New("shutdown hook timed out")
+)
+
+// Hook is a named step of the shutdown sequence.
+//
+// Purpose: Release one component (stop jobs, flush audit events, close pools).
+// Domain: Platform
+// Invariants: Name is unique per Manager. Stop must honour ctx cancellation where it can.
+type Hook struct {
+	Name string
+	// Timeout bounds Stop. Zero means DefaultHookTimeout.
+	Timeout time.Duration
+	Stop    func(ctx context.Context) error
+}
+
+// Manager runs registered hooks in reverse registration order on Shutdown.
+//
+// Purpose: Single shutdown sequence shared by core services and the host.
+// Domain: Platform
+// Invariants: Hooks run one at a time, last registered first, and each at most once.
+// A hook that times out does not block the hooks after it.
+type Manager struct {
+	mu       sync.Mutex
+	hooks    []Hook
+	shutdown bool
+	done     chan struct{}
+	err      error
+}
+
+// New creates an empty lifecycle manager.
+//
+// Purpose: Constructor for the shutdown coordinator.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func New() *Manager {
+	return &Manager{done: make(chan struct{})}
+}
+
+// Register appends a hook to the shutdown sequence.
+//
+// Purpose: Declares cleanup for a component. Components a hook depends on must
+// be registered before it, so that they are still available when it runs.
+// Domain: Platform
+// Audited: No
+// Errors: ErrInvalidHook, ErrDuplicateHook, ErrShuttingDown
+func (m *Manager) Register(h Hook) error {
+	if h.Name == "" || h.Stop == nil || h.Timeout < 0 {
+		return ErrInvalidHook
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shutdown {
+		return ErrShuttingDown
+	}
+	for _, existing := range m.hooks {
+		if existing.Name == h.Name {
+			return ErrDuplicateHook
+		}
+	}
+	m.hooks = append(m.hooks, h)
+	return nil
+}
+
+// Hooks returns the hook names in the order they will run.
+func (m *Manager) Hooks() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.hooks))
+	for i := len(m.hooks) - 1; i >= 0; i-- {
+		names = append(names, m.hooks[i].Name)
+	}
+	return names
+}
+
+// Shutdown runs every hook, last registered first.
+//
+// Purpose: Drains in-flight work (audit events, deliveries) before resources are released.
+// Domain: Platform
+// Audited: No
+// Errors: Joined hook errors, each prefixed with the hook name; ErrHookTimeout for hooks
+// that exceeded their timeout. Concurrent and repeated calls wait for and return the
+// result of the first.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.shutdown {
+		m.mu.Unlock()
+		select {
+		case <-m.done:
+			return m.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	m.shutdown = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := run(ctx, hooks[i]); err != nil {
+			slog.ErrorContext(ctx, "shutdown hook failed", "hook", hooks[i].Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", hooks[i].Name, err))
+		}
+	}
+
+	m.err = errors.Join(errs...)
+	close(m.done)
+	return m.err
+}
+
+// run calls h.Stop and returns once it finishes or its timeout elapses. A
+// timed-out Stop keeps running in the background; the sequence moves on.
+func run(ctx context.Context, h Hook) error {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() { result <- h.Stop(ctx) }()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrHookTimeout, ctx.Err())
+	}
+}
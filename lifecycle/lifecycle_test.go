@@ -0,0 +1,107 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+
+	m := New()
+	if err := m.Register(Hook{Name: "a", Stop: noop}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := m.Register(Hook{Name: "a", Stop: noop}); !errors.Is(err, ErrDuplicateHook) {
+		t.Errorf("duplicate Register() error = %v, want ErrDuplicateHook", err)
+	}
+	if err := m.Register(Hook{Name: "b"}); !errors.Is(err, ErrInvalidHook) {
+		t.Errorf("nil Stop Register() error = %v, want ErrInvalidHook", err)
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := m.Register(Hook{Name: "c", Stop: noop}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Register() after Shutdown error = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestShutdownOrder(t *testing.T) {
+	var order []string
+	m := New()
+	for _, name := range []string{"database", "audit-flush", "scheduler"} {
+		_ = m.Register(Hook{Name: name, Stop: func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}})
+	}
+
+	want := []string{"scheduler", "audit-flush", "database"}
+	if got := m.Hooks(); !slices.Equal(got, want) {
+		t.Errorf("Hooks() = %v, want %v", got, want)
+	}
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !slices.Equal(order, want) {
+		t.Errorf("ran %v, want %v", order, want)
+	}
+
+	// A second Shutdown returns the first result without rerunning hooks.
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown() error = %v", err)
+	}
+	if len(order) != len(want) {
+		t.Errorf("hooks ran %d times, want %d", len(order), len(want))
+	}
+}
+
+func TestShutdownErrorsAndTimeouts(t *testing.T) {
+	errFlush := errors.New("flush failed")
+	var closed bool
+
+	m := New()
+	_ = m.Register(Hook{Name: "database", Stop: func(context.Context) error {
+		closed = true
+		return nil
+	}})
+	_ = m.Register(Hook{Name: "audit-flush", Stop: func(context.Context) error { return errFlush }})
+	_ = m.Register(Hook{Name: "stuck", Timeout: 10 * time.Millisecond, Stop: func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second)
+		return nil
+	}})
+
+	start := time.Now()
+	err := m.Shutdown(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Shutdown() took %v; timed-out hook blocked the sequence", elapsed)
+	}
+	if !errors.Is(err, errFlush) {
+		t.Errorf("Shutdown() error = %v, want flush error", err)
+	}
+	if !errors.Is(err, ErrHookTimeout) {
+		t.Errorf("Shutdown() error = %v, want ErrHookTimeout", err)
+	}
+	if !closed {
+		t.Error("hooks after a failed hook must still run")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maintenance holds the platform's runtime availability mode. In
+// read-only and maintenance mode core refuses logins and token issuance with a
+// typed error, while introspection and validation of credentials already issued
+// keep working, so relying parties stay up through an upgrade or an incident.
+package maintenance
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+// Domain errors
+var (
+	ErrReadOnly    = apperror.New(apperror.CodeServiceUnavailable, apperror.StatusServiceUnavailable, apperror.OAuth2TemporarilyUnavailable, "the service is in read-only mode")
+	ErrMaintenance = apperror.New(apperror.CodeServiceUnavailable, apperror.StatusServiceUnavailable, apperror.OAuth2TemporarilyUnavailable, "the service is down for maintenance")
+	ErrInvalidMode = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid platform mode")
+)
+
+// Mode is the platform's availability mode.
+type Mode string
+
+// Modes
+const (
+	// ModeNormal accepts every operation; the zero value behaves the same.
+	ModeNormal Mode = "normal"
+	// ModeReadOnly refuses logins, token issuance, and administrative changes.
+	ModeReadOnly Mode = "read_only"
+	// ModeMaintenance also refuses administrative reads.
+	ModeMaintenance Mode = "maintenance"
+)
+
+// ParseMode returns the mode named s; "" is ModeNormal.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case "", ModeNormal:
+		return ModeNormal, nil
+	case ModeReadOnly, ModeMaintenance:
+		return m, nil
+	}
+	return "", ErrInvalidMode
+}
+
+// Operation is a class of request the mode may refuse. Token introspection and
+// validation, session lookup, and discovery are never refused and have no operation.
+type Operation string
+
+// Operations
+const (
+	// OpLogin authenticates a user to start a session.
+	OpLogin Operation = "login"
+	// OpTokenIssuance issues tokens at the token endpoint.
+	OpTokenIssuance Operation = "token_issuance"
+	// OpWrite changes administrative state; transports gate write endpoints on it.
+	OpWrite Operation = "write"
+	// OpRead reads administrative state; transports gate read endpoints on it.
+	OpRead Operation = "read"
+)
+
+// Allows reports whether the mode accepts op.
+func (m Mode) Allows(op Operation) bool {
+	switch m {
+	case ModeReadOnly:
+		return op == OpRead
+	case ModeMaintenance:
+		return false
+	}
+	return true
+}
+
+// Gate refuses operations the platform mode does not accept; Controller implements it.
+type Gate interface {
+	Allow(ctx context.Context, op Operation) error
+}
+
+// Status is the current mode and when and why it was entered.
+//
+// Purpose: Health check and admin console view of the platform mode.
+// Domain: Platform
+// Invariants: Since, Message, and SetBy are empty in ModeNormal. SetBy is "" when
+// the mode came from configuration.
+type Status struct {
+	Mode    Mode       `json:"mode"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+	SetBy   string     `json:"set_by,omitempty"`
+}
+
+// Controller holds the platform mode of this process.
+//
+// Purpose: Runtime switch between normal, read-only, and maintenance operation.
+// Domain: Platform
+// Invariants: Safe for concurrent use. The mode is per process: every instance of a
+// deployment starts from configuration and is switched individually.
+type Controller struct {
+	mu          sync.RWMutex
+	status      Status
+	auditLogger audit.Logger
+}
+
+// NewController creates a controller in the configured mode.
+//
+// Purpose: Constructor for the platform mode switch.
+// Domain: Platform
+// Audited: No
+// Errors: None
+func NewController(mode Mode, auditLogger audit.Logger) *Controller {
+	c := &Controller{auditLogger: auditLogger, status: Status{Mode: ModeNormal}}
+	if mode != "" && mode != ModeNormal {
+		now := time.Now()
+		c.status = Status{Mode: mode, Since: &now}
+	}
+	return c
+}
+
+// Mode returns the current mode.
+func (c *Controller) Mode() Mode {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status.Mode
+}
+
+// Status returns the current mode and when and why it was entered.
+func (c *Controller) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// Allow returns nil if the current mode accepts op.
+//
+// Purpose: Decision point for services and transports.
+// Domain: Platform
+// Audited: No
+// Errors: ErrReadOnly, ErrMaintenance
+func (c *Controller) Allow(ctx context.Context, op Operation) error {
+	switch mode := c.Mode(); {
+	case mode.Allows(op):
+		return nil
+	case mode == ModeMaintenance:
+		return ErrMaintenance
+	default:
+		return ErrReadOnly
+	}
+}
+
+// Set switches the platform mode. message is shown to users while the mode lasts.
+//
+// Purpose: Operator control over read-only and maintenance operation.
+// Domain: Platform
+// Security: Callers must hold platform administration rights; refused logins and
+// token requests fail with a 503 so clients retry later.
+// Audited: Yes (TypePlatformModeSet)
+// Errors: ErrInvalidMode
+func (c *Controller) Set(ctx context.Context, mode Mode, message, actorID string) error {
+	mode, err := ParseMode(string(mode))
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	old := c.status.Mode
+	if mode == ModeNormal {
+		c.status = Status{Mode: ModeNormal}
+	} else {
+		now := time.Now()
+		c.status = Status{Mode: mode, Message: strings.TrimSpace(message), Since: &now, SetBy: actorID}
+	}
+	c.mu.Unlock()
+
+	c.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypePlatformModeSet,
+		ActorID:  actorID,
+		Resource: audit.ResourcePlatformMode,
+		Metadata: map[string]any{
+			"mode_from": old,
+			"mode_to":   mode,
+		},
+	})
+	return nil
+}
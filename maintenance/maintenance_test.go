@@ -0,0 +1,87 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Log(_ context.Context, e audit.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestControllerAllow(t *testing.T) {
+	tests := []struct {
+		mode    Mode
+		op      Operation
+		wantErr error
+	}{
+		{ModeNormal, OpLogin, nil},
+		{ModeNormal, OpWrite, nil},
+		{ModeReadOnly, OpLogin, ErrReadOnly},
+		{ModeReadOnly, OpTokenIssuance, ErrReadOnly},
+		{ModeReadOnly, OpWrite, ErrReadOnly},
+		{ModeReadOnly, OpRead, nil},
+		{ModeMaintenance, OpTokenIssuance, ErrMaintenance},
+		{ModeMaintenance, OpRead, ErrMaintenance},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+"/"+string(tt.op), func(t *testing.T) {
+			c := NewController(tt.mode, &recordingAuditLogger{})
+			if err := c.Allow(context.Background(), tt.op); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Allow() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestControllerSet(t *testing.T) {
+	ctx := context.Background()
+	logger := &recordingAuditLogger{}
+	c := NewController("", logger)
+	if s := c.Status(); s.Mode != ModeNormal || s.Since != nil {
+		t.Fatalf("initial Status() = %+v, want normal", s)
+	}
+
+	if err := c.Set(ctx, ModeMaintenance, " database upgrade ", "admin"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	s := c.Status()
+	if s.Mode != ModeMaintenance || s.Message != "database upgrade" || s.SetBy != "admin" || s.Since == nil {
+		t.Errorf("Status() = %+v", s)
+	}
+
+	if err := c.Set(ctx, ModeNormal, "ignored", "admin"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if s := c.Status(); s != (Status{Mode: ModeNormal}) {
+		t.Errorf("Status() after leaving maintenance = %+v", s)
+	}
+
+	if err := c.Set(ctx, "offline", "", "admin"); !errors.Is(err, ErrInvalidMode) {
+		t.Errorf("Set() with unknown mode error = %v, want %v", err, ErrInvalidMode)
+	}
+	if len(logger.events) != 2 || logger.events[0].Type != audit.TypePlatformModeSet || logger.events[0].Metadata["mode_to"] != ModeMaintenance {
+		t.Errorf("audit events = %+v", logger.events)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNilMetricsIsNoop(t *testing.T) {
+	var m *Metrics
+	m.LoginAttempt(LoginSuccess)
+	m.TokenIssued(TokenAccess)
+	m.TokenRevoked(TokenRefresh)
+	m.PermissionCheck(PermissionAllowed, time.Millisecond)
+	m.SessionCreated()
+	m.AuditWriteFailed()
+	m.CacheLookup("dpop", CacheHit)
+	m.CacheEvicted("dpop", CacheEvictCapacity, 1)
+}
+
+func TestWriteText(t *testing.T) {
+	reg := NewRegistry()
+	m := New(reg)
+
+	m.LoginAttempt(LoginSuccess)
+	m.LoginAttempt(LoginFailed)
+	m.LoginAttempt(LoginFailed)
+	m.PermissionCheck(PermissionDenied, 2*time.Millisecond)
+	reg.NewGaugeFunc("opentrusty_test_gauge", "A gauge.", func() float64 { return 3 })
+
+	var sb strings.Builder
+	if err := reg.WriteText(&sb); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"# TYPE opentrusty_logins_total counter",
+		`opentrusty_logins_total{result="failed"} 2`,
+		`opentrusty_logins_total{result="success"} 1`,
+		`opentrusty_permission_checks_total{result="denied"} 1`,
+		"# TYPE opentrusty_permission_check_duration_seconds histogram",
+		`opentrusty_permission_check_duration_seconds_bucket{le="0.001"} 0`,
+		`opentrusty_permission_check_duration_seconds_bucket{le="0.0025"} 1`,
+		`opentrusty_permission_check_duration_seconds_bucket{le="+Inf"} 1`,
+		"opentrusty_permission_check_duration_seconds_count 1",
+		"opentrusty_test_gauge 3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestDuplicateRegistrationPanics(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounterVec("dup_total", "")
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic on duplicate metric name")
+		}
+	}()
+	reg.NewCounterVec("dup_total", "")
+}
@@ -0,0 +1,318 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets in seconds suitable for in-process checks and queries.
+var DefaultBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// Registry holds metric families and renders them in the Prometheus text exposition format.
+//
+// Purpose: Dependency-free metrics sink the host application exposes on its own endpoint.
+// Domain: Platform (Observability)
+// Invariants: Metric names are unique. Families are rendered in registration order.
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]struct{}
+}
+
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// NewRegistry creates an empty registry.
+//
+// Purpose: Constructor for the metrics registry.
+// Domain: Platform (Observability)
+// Audited: No
+// Errors: None
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]struct{})}
+}
+
+// register adds f to the registry. Duplicate names are a programming error and panic.
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, dup := r.names[f.name()]; dup {
+		panic(fmt.Sprintf("metrics: duplicate metric %q", f.name()))
+	}
+	r.names[f.name()] = struct{}{}
+	r.families = append(r.families, f)
+}
+
+// WriteText writes every registered metric in the Prometheus text format (version 0.0.4).
+//
+// Purpose: Exposition for scraping; the host mounts it on its own HTTP endpoint.
+// Domain: Platform (Observability)
+// Audited: No
+// Errors: Write errors from w
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]family(nil), r.families...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// CounterVec is a monotonically increasing counter partitioned by label values.
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labels []string
+	v      float64
+}
+
+// NewCounterVec registers a counter family.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{n: name, help: help, labelNames: labelNames},
+		values: make(map[string]*counterValue),
+	}
+	r.register(c)
+	return c
+}
+
+// Inc increments the counter for the given label values by one.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by v. Negative values are ignored.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	key := c.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cv, ok := c.values[key]
+	if !ok {
+		cv = &counterValue{labels: append([]string(nil), labelValues...)}
+		c.values[key] = cv
+	}
+	cv.v += v
+}
+
+// Value returns the current counter value for the given label values.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cv, ok := c.values[c.key(labelValues)]; ok {
+		return cv.v
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.header(w, "counter")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range sortedKeys(c.values) {
+		cv := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.n, c.labels(cv.labels, "", ""), formatFloat(cv.v))
+	}
+}
+
+// HistogramVec samples observations into cumulative buckets, partitioned by label values.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec registers a histogram family. Nil buckets select DefaultBuckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &HistogramVec{
+		desc:    desc{n: name, help: help, labelNames: labelNames},
+		buckets: buckets,
+		values:  make(map[string]*histogramValue),
+	}
+	r.register(h)
+	return h
+}
+
+// Observe records v for the given label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{
+			labels: append([]string(nil), labelValues...),
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = hv
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hv.counts[i]++
+		}
+	}
+	hv.sum += v
+	hv.count++
+}
+
+// Count returns the number of observations for the given label values.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if hv, ok := h.values[h.key(labelValues)]; ok {
+		return hv.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.header(w, "histogram")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, key := range sortedKeys(h.values) {
+		hv := h.values[key]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.n, h.labels(hv.labels, "le", formatFloat(upper)), hv.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.n, h.labels(hv.labels, "le", "+Inf"), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.n, h.labels(hv.labels, "", ""), formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.n, h.labels(hv.labels, "", ""), hv.count)
+	}
+}
+
+// gaugeFunc is a gauge whose value is read at exposition time.
+type gaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is computed by fn on every scrape.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&gaugeFunc{desc: desc{n: name, help: help}, fn: fn})
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	g.header(w, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.n, formatFloat(g.fn()))
+}
+
+// desc is the metadata shared by every metric family.
+type desc struct {
+	n          string
+	help       string
+	labelNames []string
+}
+
+func (d *desc) name() string { return d.n }
+
+func (d *desc) header(w *bufio.Writer, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.n, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.n, typ)
+}
+
+// key joins label values into a map key. Missing values are treated as empty.
+func (d *desc) key(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// labels renders {name="value",...}, optionally appending one extra pair (e.g. le).
+func (d *desc) labels(values []string, extraName, extraValue string) string {
+	var pairs []string
+	for i, name := range d.labelNames {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		pairs = append(pairs, name+`="`+escapeLabel(v)+`"`)
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+extraValue+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
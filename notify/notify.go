@@ -0,0 +1,132 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notify tells users about security-relevant changes to their
+// accounts. Core decides when a user must be told; the host owns channels
+// (email, SMS, push), templates, and the lookup of the user's address, and
+// plugs them in as a Sender.
+package notify
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+// Notification kinds
+const (
+	// KindAccountLocked tells a user their account was locked after repeated login failures.
+	KindAccountLocked = "account_locked"
+	// KindSuspiciousLogin tells a user about a sign-in from an unusual location.
+	KindSuspiciousLogin = "suspicious_login"
+	// KindRecoveryRequested tells a user an account recovery was started, so they can cancel it.
+	KindRecoveryRequested = "recovery_requested"
+	// KindAccountRecovered tells a user their account was recovered and all sessions ended.
+	KindAccountRecovered = "account_recovered"
+)
+
+// Data keys
+const (
+	DataLockedUntil = "locked_until"
+	DataCountry     = "country"
+	DataSignals     = "signals"
+	DataRequestID   = "request_id"
+	DataMethod      = "method"
+	DataAvailableAt = "available_at"
+)
+
+// Notification is a message to one user.
+//
+// Purpose: Channel-neutral notification handed to the host.
+// Domain: Identity
+// Invariants: Carries identifiers and timestamps only, never PII or secrets;
+// the Sender resolves the user's address itself.
+type Notification struct {
+	Kind      string            `json:"kind"`
+	TenantID  string            `json:"tenant_id,omitempty"`
+	UserID    string            `json:"user_id"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// Sender delivers notifications.
+//
+// Purpose: Host adapter boundary for notification channels.
+// Domain: Identity
+type Sender interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// Service turns domain events into user notifications.
+//
+// Purpose: Event-driven notification dispatch.
+// Domain: Identity
+type Service struct {
+	sender Sender
+}
+
+// NewService creates a notification service delivering through sender.
+//
+// Purpose: Constructor for the notification service.
+// Domain: Identity
+// Audited: No
+// Errors: None
+func NewService(sender Sender) *Service {
+	return &Service{sender: sender}
+}
+
+// HandleEvent notifies the affected user of events they must know about.
+func (s *Service) HandleEvent(ctx context.Context, e events.Event) error {
+	switch ev := e.(type) {
+	case events.UserLocked:
+		return s.sender.Send(ctx, Notification{
+			Kind:      KindAccountLocked,
+			TenantID:  ev.TenantID,
+			UserID:    ev.UserID,
+			Data:      map[string]string{DataLockedUntil: ev.LockedUntil.UTC().Format(time.RFC3339)},
+			CreatedAt: ev.OccurredAt,
+		})
+	case events.SuspiciousLogin:
+		return s.sender.Send(ctx, Notification{
+			Kind:      KindSuspiciousLogin,
+			TenantID:  ev.TenantID,
+			UserID:    ev.UserID,
+			Data:      map[string]string{DataCountry: ev.Country, DataSignals: strings.Join(ev.Signals, ",")},
+			CreatedAt: ev.OccurredAt,
+		})
+	case events.RecoveryStarted:
+		return s.sender.Send(ctx, Notification{
+			Kind:     KindRecoveryRequested,
+			TenantID: ev.TenantID,
+			UserID:   ev.UserID,
+			Data: map[string]string{
+				DataRequestID:   ev.RequestID,
+				DataMethod:      ev.Method,
+				DataAvailableAt: ev.AvailableAt.UTC().Format(time.RFC3339),
+			},
+			CreatedAt: ev.OccurredAt,
+		})
+	case events.UserRecovered:
+		return s.sender.Send(ctx, Notification{
+			Kind:      KindAccountRecovered,
+			TenantID:  ev.TenantID,
+			UserID:    ev.UserID,
+			Data:      map[string]string{DataRequestID: ev.RequestID, DataMethod: ev.Method},
+			CreatedAt: ev.OccurredAt,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+type recordingSender struct {
+	sent []Notification
+}
+
+func (r *recordingSender) Send(_ context.Context, n Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func TestHandleEvent(t *testing.T) {
+	until := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		event    events.Event
+		wantKind string
+		wantKey  string
+		wantVal  string
+	}{
+		{"locked", events.UserLocked{Meta: events.NewMeta("", ""), UserID: "u1", LockedUntil: until}, KindAccountLocked, DataLockedUntil, "2026-01-02T03:04:05Z"},
+		{"suspicious login", events.SuspiciousLogin{Meta: events.NewMeta("", ""), UserID: "u1", Signals: []string{"new_country"}, Country: "AU"}, KindSuspiciousLogin, DataCountry, "AU"},
+		{"recovery requested", events.RecoveryStarted{Meta: events.NewMeta("", ""), UserID: "u1", RequestID: "r1", Method: "delayed", AvailableAt: until}, KindRecoveryRequested, DataAvailableAt, "2026-01-02T03:04:05Z"},
+		{"account recovered", events.UserRecovered{Meta: events.NewMeta("", ""), UserID: "u1", RequestID: "r1", Method: "admin_attested"}, KindAccountRecovered, DataMethod, "admin_attested"},
+		{"unrelated", events.UserUpdated{Meta: events.NewMeta("", ""), UserID: "u1"}, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &recordingSender{}
+			if err := NewService(sender).HandleEvent(context.Background(), tt.event); err != nil {
+				t.Fatalf("HandleEvent() error = %v", err)
+			}
+			if tt.wantKind == "" {
+				if len(sender.sent) != 0 {
+					t.Errorf("sent = %v, want none", sender.sent)
+				}
+				return
+			}
+			if len(sender.sent) != 1 {
+				t.Fatalf("sent %d notifications, want 1", len(sender.sent))
+			}
+			n := sender.sent[0]
+			if n.Kind != tt.wantKind || n.UserID != "u1" || n.Data[tt.wantKey] != tt.wantVal {
+				t.Errorf("notification = %+v", n)
+			}
+		})
+	}
+}
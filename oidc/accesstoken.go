@@ -0,0 +1,126 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/jose"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// accessTokenType is the JWS "typ" of a JWT access token (RFC 9068 Section 2.1).
+const accessTokenType = "at+jwt"
+
+// AccessTokenRequest is everything needed to mint one JWT access token.
+//
+// Purpose: The claims of a JWT access token, gathered by the token service.
+// Domain: OAuth2
+// Invariants: Issuer is the tenant's issuer identifier exactly as published in its
+// discovery document. Token is the access token record being issued: its ID becomes
+// jti and its lifetime, scope, audience, and binding become claims. Roles are the
+// subject's role names in the client's tenant.
+type AccessTokenRequest struct {
+	Issuer string
+	Client *client.Client
+	Token  *client.AccessToken
+	Roles  []string
+}
+
+// IssueAccessToken mints a signed JWT access token.
+//
+// Purpose: Self-contained access tokens (RFC 9068) for clients with JWTAccessTokens.
+// Domain: OAuth2
+// Security: Signed with the tenant's key and algorithm like ID tokens, but typed
+// "at+jwt", which verifier.Verify requires, so an ID token is never accepted as an
+// access token. aud is the token's resource, or the client when it has none.
+// tenant_id, client_id, scope, and roles are always present, and cnf carries a DPoP
+// or certificate binding. sub is pairwise for pairwise clients when the issuer has a
+// SubjectMapper. The client's claim mapping applies but never alters registered
+// claims or tenant_id, client_id, scope, and roles.
+// Audited: No (the token request itself is audited by the token service)
+// Errors: ErrIssuerRequired, jose.ErrInvalidKey, jose.ErrUnsupportedAlg, System errors
+func (i *IDTokenIssuer) IssueAccessToken(ctx context.Context, req AccessTokenRequest) (string, error) {
+	ctx, span := tracing.Start(ctx, i.tracer, "oidc.IssueAccessToken",
+		tracing.String(tracing.AttrUserID, req.Token.UserID),
+	)
+	defer span.End()
+
+	if req.Issuer == "" {
+		return "", ErrIssuerRequired
+	}
+	key, alg, err := i.signingKey(ctx, req.Client)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	sub, err := clientSubject(ctx, i.subjects, req.Client, req.Token.UserID)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(accessTokenClaims(req, sub))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode access token claims: %w", err)
+	}
+	token, err := jose.SignWith(key.Signer, alg, jose.Header{Kid: key.KeyID, Typ: accessTokenType}, payload)
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return token, nil
+}
+
+// accessTokenClaims assembles the JWT access token claims for req (RFC 9068 Section 2.2),
+// identifying the user as sub.
+func accessTokenClaims(req AccessTokenRequest, sub string) map[string]any {
+	at := req.Token
+	aud := at.Audience
+	if aud == "" {
+		aud = req.Client.ClientID
+	}
+	roles := slices.Clone(req.Roles)
+	if roles == nil {
+		roles = []string{}
+	}
+
+	claims := map[string]any{
+		"iss":                req.Issuer,
+		"sub":                sub,
+		"aud":                aud,
+		"client_id":          at.ClientID,
+		"jti":                at.ID,
+		"scope":              at.Scope,
+		"iat":                at.CreatedAt.Unix(),
+		"exp":                at.ExpiresAt.Unix(),
+		client.ClaimTenantID: at.TenantID,
+		client.ClaimRoles:    roles,
+	}
+	switch {
+	case at.DPoPJKT != "":
+		claims["cnf"] = map[string]string{"jkt": at.DPoPJKT}
+	case at.CertThumbprint != "":
+		claims["cnf"] = map[string]string{"x5t#S256": at.CertThumbprint}
+	}
+
+	mapped := req.Client.ClaimMapping.Apply(claims, client.ClaimContext{TenantID: at.TenantID, Roles: req.Roles})
+	// roles is not a protected claim, but resource servers rely on it here
+	mapped[client.ClaimRoles] = roles
+	return mapped
+}
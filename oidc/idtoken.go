@@ -0,0 +1,326 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidc mints OpenID Connect ID tokens (OpenID Connect Core 1.0 Section 2).
+// Transports call IDTokenIssuer after a successful token request with the user,
+// the client, and the granted scope; the issuer releases only the claims that
+// scope allows and signs the token with the tenant's key and algorithm.
+// UserInfoResolver releases the same claims for the UserInfo endpoint.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/jose"
+	"github.com/opentrusty/opentrusty-core/tracing"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// DefaultIDTokenLifetime applies to clients that do not set their own.
+const DefaultIDTokenLifetime = time.Hour
+
+// idTokenType is the JWS "typ" of an ID token.
+const idTokenType = "JWT"
+
+// Domain errors
+var (
+	ErrOpenIDScopeRequired = apperror.New(apperror.CodeInvalidScope, apperror.StatusBadRequest, apperror.OAuth2InvalidScope, "id tokens require the openid scope")
+	ErrIssuerRequired      = apperror.New(apperror.CodeInternal, apperror.StatusInternalServerError, "", "id token issuer is not set")
+)
+
+// Standard claims released by scope (OpenID Connect Core 1.0 Section 5.4)
+const (
+	ClaimName          = "name"
+	ClaimGivenName     = "given_name"
+	ClaimFamilyName    = "family_name"
+	ClaimNickname      = "nickname"
+	ClaimPicture       = "picture"
+	ClaimLocale        = "locale"
+	ClaimZoneinfo      = "zoneinfo"
+	ClaimEmail         = "email"
+	ClaimEmailVerified = "email_verified"
+)
+
+// IDTokenRequest is everything needed to mint one ID token.
+//
+// Purpose: The subject, audience, and session facts of an ID token, gathered by the transport.
+// Domain: OIDC
+// Invariants: Issuer is the tenant's issuer identifier exactly as published in its
+// discovery document. Scope is the granted scope, not the requested one. AccessToken,
+// when set, is the access token issued alongside and yields at_hash.
+type IDTokenRequest struct {
+	Issuer      string
+	Client      *client.Client
+	User        *user.User
+	Scope       string
+	Nonce       string
+	AuthTime    time.Time
+	AccessToken string
+	// Claims carries the roles and projects a client.ClaimMapping may add; TenantID is
+	// always taken from Client.
+	Claims client.ClaimContext
+}
+
+// SigningKey is a private key ID tokens are signed with.
+type SigningKey struct {
+	Signer crypto.Signer
+	KeyID  string
+}
+
+// KeySource returns the key a tenant's ID tokens are signed with.
+type KeySource interface {
+	SigningKey(ctx context.Context, tenantID string) (*SigningKey, error)
+}
+
+// StaticKey is a KeySource signing every tenant's tokens with one key.
+type StaticKey SigningKey
+
+// SigningKey returns k for every tenant.
+func (k StaticKey) SigningKey(ctx context.Context, tenantID string) (*SigningKey, error) {
+	key := SigningKey(k)
+	return &key, nil
+}
+
+// AlgorithmSource returns a tenant's signing algorithm; tenant.Service implements it.
+type AlgorithmSource interface {
+	SigningAlgorithm(ctx context.Context, tenantID string) (string, error)
+}
+
+// IDTokenIssuer mints signed ID tokens.
+//
+// Purpose: The single place ID token claims are assembled and signed.
+// Domain: OIDC
+// Invariants: Only openid grants get ID tokens. Profile claims are released only
+// under the profile scope and email claims only under the email scope; a client's
+// ClaimMapping never alters the registered claims.
+type IDTokenIssuer struct {
+	keys     KeySource
+	algs     AlgorithmSource
+	subjects SubjectMapper
+	tracer   tracing.Tracer
+	now      func() time.Time
+}
+
+// Option configures optional IDTokenIssuer dependencies.
+type Option func(*IDTokenIssuer)
+
+// WithAlgorithms signs each tenant's tokens with the algorithm a reports. Without it
+// the client's id_token_signed_response_alg is used, or the key type's default algorithm.
+func WithAlgorithms(a AlgorithmSource) Option {
+	return func(i *IDTokenIssuer) { i.algs = a }
+}
+
+// SubjectMapper returns the "sub" a client knows a user by; subject.Service implements it.
+type SubjectMapper interface {
+	Subject(ctx context.Context, c *client.Client, userID string) (string, error)
+}
+
+// WithSubjects takes the "sub" of ID and access tokens from m, so pairwise clients get
+// their pairwise identifier. Without it sub is always the user's ID.
+func WithSubjects(m SubjectMapper) Option {
+	return func(i *IDTokenIssuer) { i.subjects = m }
+}
+
+// WithTracer emits spans for ID token issuance on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(i *IDTokenIssuer) { i.tracer = t }
+}
+
+// WithClock overrides the clock used for iat and exp.
+func WithClock(c clock.Clock) Option {
+	return func(i *IDTokenIssuer) { i.now = c.Now }
+}
+
+// NewIDTokenIssuer creates a new ID token issuer signing with keys.
+//
+// Purpose: Constructor for the ID token issuer.
+// Domain: OIDC
+// Audited: No
+// Errors: None
+func NewIDTokenIssuer(keys KeySource, opts ...Option) *IDTokenIssuer {
+	i := &IDTokenIssuer{
+		keys: keys,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Issue mints a signed ID token.
+//
+// Purpose: ID token issuance for the authorization_code and refresh_token grants.
+// Domain: OIDC
+// Security: The token lives for the client's IDTokenLifetime (DefaultIDTokenLifetime if
+// unset). Profile, email, address and phone claims are released only for the granted
+// scopes, and email, address and phone only when the user has them stored. The
+// signing algorithm is the tenant's; a key that does not match it is refused rather
+// than signing with another algorithm. Pairwise clients get their pairwise sub when
+// the issuer has a SubjectMapper.
+// Audited: No (the token request itself is audited by the token service)
+// Errors: ErrOpenIDScopeRequired, ErrIssuerRequired, jose.ErrInvalidKey,
+// jose.ErrUnsupportedAlg, System errors
+func (i *IDTokenIssuer) Issue(ctx context.Context, req IDTokenRequest) (string, error) {
+	ctx, span := tracing.Start(ctx, i.tracer, "oidc.IssueIDToken",
+		tracing.String(tracing.AttrUserID, req.User.ID),
+	)
+	defer span.End()
+
+	scopes := strings.Fields(req.Scope)
+	if !slices.Contains(scopes, client.ScopeOpenID) {
+		return "", ErrOpenIDScopeRequired
+	}
+	if req.Issuer == "" {
+		return "", ErrIssuerRequired
+	}
+
+	key, alg, err := i.signingKey(ctx, req.Client)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	sub, err := clientSubject(ctx, i.subjects, req.Client, req.User.ID)
+	if err != nil {
+		return "", err
+	}
+	claims, err := i.claims(req, sub, scopes, alg)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode id token claims: %w", err)
+	}
+
+	token, err := jose.SignWith(key.Signer, alg, jose.Header{Kid: key.KeyID, Typ: idTokenType}, payload)
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to sign id token: %w", err)
+	}
+	return token, nil
+}
+
+// signingKey returns the tenant key and algorithm to sign c's tokens with.
+func (i *IDTokenIssuer) signingKey(ctx context.Context, c *client.Client) (*SigningKey, string, error) {
+	key, err := i.keys.SigningKey(ctx, c.TenantID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+	alg, err := i.algorithm(ctx, c, key.Signer)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, alg, nil
+}
+
+// algorithm returns the algorithm to sign c's ID tokens with.
+func (i *IDTokenIssuer) algorithm(ctx context.Context, c *client.Client, key crypto.Signer) (string, error) {
+	if i.algs != nil {
+		alg, err := i.algs.SigningAlgorithm(ctx, c.TenantID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get signing algorithm: %w", err)
+		}
+		return alg, nil
+	}
+	if c.IDTokenSignedResponseAlg != "" {
+		return c.IDTokenSignedResponseAlg, nil
+	}
+	for _, alg := range jose.Algorithms() {
+		if jose.KeyMatches(alg, key.Public()) {
+			return alg, nil
+		}
+	}
+	return "", jose.ErrInvalidKey
+}
+
+// clientSubject returns the "sub" c knows userID by, which is userID without a mapper.
+func clientSubject(ctx context.Context, m SubjectMapper, c *client.Client, userID string) (string, error) {
+	if m == nil {
+		return userID, nil
+	}
+	sub, err := m.Subject(ctx, c, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve subject: %w", err)
+	}
+	return sub, nil
+}
+
+// claims assembles the ID token claims for req, identifying the user as sub.
+func (i *IDTokenIssuer) claims(req IDTokenRequest, sub string, scopes []string, alg string) (map[string]any, error) {
+	now := i.now()
+	lifetime := DefaultIDTokenLifetime
+	if req.Client.IDTokenLifetime > 0 {
+		lifetime = time.Duration(req.Client.IDTokenLifetime) * time.Second
+	}
+
+	claims := map[string]any{
+		"iss": req.Issuer,
+		"sub": sub,
+		"aud": req.Client.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(lifetime).Unix(),
+	}
+	if !req.AuthTime.IsZero() {
+		claims["auth_time"] = req.AuthTime.Unix()
+	}
+	if req.Nonce != "" {
+		claims["nonce"] = req.Nonce
+	}
+	if req.AccessToken != "" {
+		atHash, err := AccessTokenHash(alg, req.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		claims["at_hash"] = atHash
+	}
+
+	maps.Copy(claims, scopeClaims(req.User, scopes))
+
+	cc := req.Claims
+	cc.TenantID = req.Client.TenantID
+	return req.Client.ClaimMapping.Apply(claims, cc), nil
+}
+
+// AccessTokenHash returns the at_hash of accessToken for an ID token signed with alg:
+// the base64url left half of its digest under the algorithm's hash (OpenID Connect
+// Core 1.0 Section 3.1.3.6). EdDSA with Ed25519 uses SHA-512.
+func AccessTokenHash(alg, accessToken string) (string, error) {
+	var digest []byte
+	switch alg {
+	case jose.RS256, jose.PS256, jose.ES256:
+		sum := sha256.Sum256([]byte(accessToken))
+		digest = sum[:]
+	case jose.EdDSA:
+		sum := sha512.Sum512([]byte(accessToken))
+		digest = sum[:]
+	default:
+		return "", jose.ErrUnsupportedAlg
+	}
+	return base64.RawURLEncoding.EncodeToString(digest[:len(digest)/2]), nil
+}
@@ -0,0 +1,467 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/authz"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/jose"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+type staticAlgorithm string
+
+func (a staticAlgorithm) SigningAlgorithm(ctx context.Context, tenantID string) (string, error) {
+	return string(a), nil
+}
+
+func TestIssue(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	authTime := now.Add(-5 * time.Minute)
+	email := "alice@example.com"
+	alice := &user.User{
+		ID:            "u1",
+		EmailPlain:    &email,
+		EmailVerified: true,
+		Profile:       user.Profile{GivenName: "Alice", FamilyName: "Liddell", FullName: "Alice Liddell"},
+	}
+	web := &client.Client{ClientID: "web", TenantID: "t1"}
+
+	tests := []struct {
+		name    string
+		key     *SigningKey
+		opts    []Option
+		req     IDTokenRequest
+		want    map[string]any
+		absent  []string
+		wantAlg string
+		wantErr error
+	}{
+		{
+			name: "openid only releases no profile or email claims",
+			key:  &SigningKey{Signer: ecKey, KeyID: "k1"},
+			req:  IDTokenRequest{Issuer: "https://id.example.com/t1", Client: web, User: alice, Scope: "openid", Nonce: "n1", AuthTime: authTime},
+			want: map[string]any{
+				"iss": "https://id.example.com/t1", "sub": "u1", "aud": "web", "nonce": "n1",
+				"iat": float64(now.Unix()), "exp": float64(now.Add(DefaultIDTokenLifetime).Unix()),
+				"auth_time": float64(authTime.Unix()),
+			},
+			absent:  []string{ClaimName, ClaimEmail, ClaimEmailVerified, "at_hash"},
+			wantAlg: jose.ES256,
+		},
+		{
+			name: "profile and email scopes release their claims",
+			key:  &SigningKey{Signer: ecKey, KeyID: "k1"},
+			req:  IDTokenRequest{Issuer: "https://id.example.com/t1", Client: web, User: alice, Scope: "openid profile email"},
+			want: map[string]any{
+				ClaimName: "Alice Liddell", ClaimGivenName: "Alice", ClaimFamilyName: "Liddell",
+				ClaimEmail: email, ClaimEmailVerified: true,
+			},
+			absent:  []string{ClaimNickname, ClaimPicture, "nonce", "auth_time"},
+			wantAlg: jose.ES256,
+		},
+		{
+			name: "client lifetime and at_hash",
+			key:  &SigningKey{Signer: ecKey, KeyID: "k1"},
+			req: IDTokenRequest{
+				Issuer: "https://id.example.com/t1", Client: &client.Client{ClientID: "web", TenantID: "t1", IDTokenLifetime: 300},
+				User: alice, Scope: "openid", AccessToken: "dNZX1hEZ9wBCzNL40Upu646bdzQA",
+			},
+			want: map[string]any{
+				"exp":     float64(now.Add(5 * time.Minute).Unix()),
+				"at_hash": "wfgvmE9VxjAudsl9lc6TqA",
+			},
+			wantAlg: jose.ES256,
+		},
+		{
+			name: "claim mapping cannot override registered claims",
+			key:  &SigningKey{Signer: ecKey, KeyID: "k1"},
+			req: IDTokenRequest{
+				Issuer: "https://id.example.com/t1", User: alice, Scope: "openid profile",
+				Client: &client.Client{ClientID: "web", TenantID: "t1", ClaimMapping: &client.ClaimMapping{
+					Rename:          map[string]string{ClaimGivenName: "first_name", "sub": "user"},
+					IncludeTenantID: true,
+					Static:          map[string]any{"aud": "evil", "department": "R&D"},
+				}},
+			},
+			want:    map[string]any{"first_name": "Alice", "sub": "u1", "aud": "web", client.ClaimTenantID: "t1", "department": "R&D"},
+			absent:  []string{ClaimGivenName, "user"},
+			wantAlg: jose.ES256,
+		},
+		{
+			name:    "EdDSA key signs with EdDSA",
+			key:     &SigningKey{Signer: edKey, KeyID: "k2"},
+			req:     IDTokenRequest{Issuer: "https://id.example.com/t1", Client: web, User: alice, Scope: "openid"},
+			wantAlg: jose.EdDSA,
+		},
+		{
+			name:    "tenant algorithm must match the key",
+			key:     &SigningKey{Signer: ecKey, KeyID: "k1"},
+			opts:    []Option{WithAlgorithms(staticAlgorithm(jose.RS256))},
+			req:     IDTokenRequest{Issuer: "https://id.example.com/t1", Client: web, User: alice, Scope: "openid"},
+			wantErr: jose.ErrInvalidKey,
+		},
+		{
+			name:    "openid scope required",
+			key:     &SigningKey{Signer: ecKey, KeyID: "k1"},
+			req:     IDTokenRequest{Issuer: "https://id.example.com/t1", Client: web, User: alice, Scope: "profile"},
+			wantErr: ErrOpenIDScopeRequired,
+		},
+		{
+			name:    "issuer required",
+			key:     &SigningKey{Signer: ecKey, KeyID: "k1"},
+			req:     IDTokenRequest{Client: web, User: alice, Scope: "openid"},
+			wantErr: ErrIssuerRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithClock(clock.Fixed(now))}, tt.opts...)
+			issuer := NewIDTokenIssuer(StaticKey(*tt.key), opts...)
+
+			token, err := issuer.Issue(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Issue() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			jws, err := jose.Parse(token)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if err := jws.Verify(tt.key.Signer.Public()); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if jws.Header.Alg != tt.wantAlg || jws.Header.Kid != tt.key.KeyID || jws.Header.Typ != idTokenType {
+				t.Errorf("header = %+v, want alg %s kid %s", jws.Header, tt.wantAlg, tt.key.KeyID)
+			}
+
+			var claims map[string]any
+			if err := json.Unmarshal(jws.Payload, &claims); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if claims[name] != want {
+					t.Errorf("claim %s = %v, want %v", name, claims[name], want)
+				}
+			}
+			for _, name := range tt.absent {
+				if _, ok := claims[name]; ok {
+					t.Errorf("claim %s should not be released", name)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessTokenHash(t *testing.T) {
+	// Example from OpenID Connect Core 1.0 Appendix A.3.
+	got, err := AccessTokenHash(jose.RS256, "jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "77QmUPtjPfzWtF2AnpK9RQ" {
+		t.Errorf("AccessTokenHash() = %s", got)
+	}
+	if _, err := AccessTokenHash("HS256", "x"); !errors.Is(err, jose.ErrUnsupportedAlg) {
+		t.Errorf("expected ErrUnsupportedAlg, got %v", err)
+	}
+}
+
+func TestIssueAccessToken(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	web := &client.Client{ClientID: "web", TenantID: "t1", ClaimMapping: &client.ClaimMapping{
+		Rename: map[string]string{"scope": "scp"},
+		Static: map[string]any{"tenant_id": "t2", "department": "R&D"},
+	}}
+	at := func(audience, jkt string) *client.AccessToken {
+		return &client.AccessToken{
+			ID: "at1", TenantID: "t1", ClientID: "web", UserID: "u1", Scope: "openid api",
+			Audience: audience, DPoPJKT: jkt, CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		req     AccessTokenRequest
+		want    map[string]any
+		roles   []any
+		wantCnf string
+		wantErr error
+	}{
+		{
+			name: "audience falls back to the client",
+			req:  AccessTokenRequest{Issuer: "https://id.example.com/t1", Client: web, Token: at("", ""), Roles: []string{"admin"}},
+			want: map[string]any{
+				"iss": "https://id.example.com/t1", "sub": "u1", "aud": "web", "client_id": "web", "jti": "at1",
+				"scope": "openid api", "tenant_id": "t1", "department": "R&D",
+				"iat": float64(now.Unix()), "exp": float64(now.Add(time.Hour).Unix()),
+			},
+			roles: []any{"admin"},
+		},
+		{
+			name:    "resource audience, DPoP binding, and no roles",
+			req:     AccessTokenRequest{Issuer: "https://id.example.com/t1", Client: web, Token: at("https://api.example.com", "jkt1")},
+			want:    map[string]any{"aud": "https://api.example.com"},
+			roles:   []any{},
+			wantCnf: "jkt1",
+		},
+		{
+			name:    "issuer required",
+			req:     AccessTokenRequest{Client: web, Token: at("", "")},
+			wantErr: ErrIssuerRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := NewIDTokenIssuer(StaticKey(SigningKey{Signer: key, KeyID: "k1"}), WithClock(clock.Fixed(now)))
+
+			token, err := issuer.IssueAccessToken(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("IssueAccessToken() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IssueAccessToken() error = %v", err)
+			}
+
+			jws, err := jose.Parse(token)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if err := jws.Verify(key.Public()); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if jws.Header.Typ != accessTokenType || jws.Header.Kid != "k1" {
+				t.Errorf("header = %+v, want typ %s", jws.Header, accessTokenType)
+			}
+
+			var claims map[string]any
+			if err := json.Unmarshal(jws.Payload, &claims); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if claims[name] != want {
+					t.Errorf("claim %s = %v, want %v", name, claims[name], want)
+				}
+			}
+			roles, _ := claims[client.ClaimRoles].([]any)
+			if roles == nil || !slices.Equal(roles, tt.roles) {
+				t.Errorf("roles = %v, want %v", claims[client.ClaimRoles], tt.roles)
+			}
+			cnf, _ := claims["cnf"].(map[string]any)
+			if got, _ := cnf["jkt"].(string); got != tt.wantCnf {
+				t.Errorf("cnf = %v, want jkt %q", claims["cnf"], tt.wantCnf)
+			}
+		})
+	}
+}
+
+type mockAuthzClaims struct{ calls int }
+
+func (m *mockAuthzClaims) BuildTenantUserInfoClaims(ctx context.Context, tenantID, userID string) (*authz.UserInfoClaims, error) {
+	m.calls++
+	return &authz.UserInfoClaims{Roles: []string{"tenant_admin"}, Projects: []*authz.ProjectInfo{{ID: "p1"}}}, nil
+}
+
+func TestUserInfo(t *testing.T) {
+	email := "alice@example.com"
+	updated := time.Unix(1_700_000_000, 0)
+	alice := &user.User{
+		ID:            "u1",
+		EmailPlain:    &email,
+		EmailVerified: true,
+		UpdatedAt:     updated,
+		Profile: user.Profile{
+			FullName: "Alice Liddell", Nickname: "alice",
+			PhoneNumber: "+447700900123", PhoneNumberVerified: true,
+			Address: user.Address{Locality: "Oxford", Country: "GB"},
+		},
+	}
+	web := &client.Client{ClientID: "web", TenantID: "t1"}
+	mapped := &client.Client{ClientID: "app", TenantID: "t1", ClaimMapping: &client.ClaimMapping{
+		IncludeRoles: true, IncludeProjects: true, Rename: map[string]string{ClaimNickname: "username"},
+	}}
+
+	tests := []struct {
+		name      string
+		req       UserInfoRequest
+		want      map[string]any
+		absent    []string
+		wantRoles []string
+		wantErr   error
+	}{
+		{
+			name:   "openid only releases sub",
+			req:    UserInfoRequest{Client: web, User: alice, Scope: "openid"},
+			want:   map[string]any{"sub": "u1"},
+			absent: []string{ClaimName, ClaimEmail, ClaimAddress, ClaimPhoneNumber, ClaimUpdatedAt, client.ClaimRoles},
+		},
+		{
+			name: "every standard scope",
+			req:  UserInfoRequest{Client: web, User: alice, Scope: "openid profile email address phone"},
+			want: map[string]any{
+				ClaimName: "Alice Liddell", ClaimNickname: "alice", ClaimUpdatedAt: updated.Unix(),
+				ClaimEmail: email, ClaimEmailVerified: true,
+				ClaimAddress:     user.Address{Locality: "Oxford", Country: "GB"},
+				ClaimPhoneNumber: "+447700900123", ClaimPhoneNumberVerified: true,
+			},
+			absent: []string{ClaimGivenName, ClaimPicture},
+		},
+		{
+			name:      "client mapping adds authorization claims",
+			req:       UserInfoRequest{Client: mapped, User: alice, Scope: "openid profile"},
+			want:      map[string]any{"sub": "u1", "username": "alice"},
+			absent:    []string{ClaimNickname},
+			wantRoles: []string{"tenant_admin"},
+		},
+		{
+			name:    "openid scope required",
+			req:     UserInfoRequest{Client: web, User: alice, Scope: "profile"},
+			wantErr: ErrOpenIDScopeRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &mockAuthzClaims{}
+			claims, err := NewUserInfoResolver(source).Resolve(context.Background(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for name, want := range tt.want {
+				if claims[name] != want {
+					t.Errorf("claim %s = %v, want %v", name, claims[name], want)
+				}
+			}
+			for _, name := range tt.absent {
+				if _, ok := claims[name]; ok {
+					t.Errorf("claim %s should not be released", name)
+				}
+			}
+			roles, _ := claims[client.ClaimRoles].([]string)
+			if !slices.Equal(roles, tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", roles, tt.wantRoles)
+			}
+			if tt.wantRoles == nil && source.calls != 0 {
+				t.Error("authorization claims must not be loaded unless the client mapping includes them")
+			}
+		})
+	}
+}
+
+type mockSubjects struct{}
+
+func (mockSubjects) Subject(ctx context.Context, c *client.Client, userID string) (string, error) {
+	if c.IsPairwise() {
+		return "pairwise-" + c.Sector() + "-" + userID, nil
+	}
+	return userID, nil
+}
+
+func TestPairwiseSubject(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	alice := &user.User{ID: "u1"}
+	pairwise := &client.Client{ClientID: "app", TenantID: "t1", SubjectType: client.SubjectTypePairwise, SectorIdentifier: "example.com"}
+	public := &client.Client{ClientID: "web", TenantID: "t1", SubjectType: client.SubjectTypePublic}
+	issuer := NewIDTokenIssuer(StaticKey(SigningKey{Signer: key, KeyID: "k1"}), WithClock(clock.Fixed(now)), WithSubjects(mockSubjects{}))
+	resolver := NewUserInfoResolver(nil, WithUserInfoSubjects(mockSubjects{}))
+
+	subOf := func(token string) any {
+		t.Helper()
+		jws, err := jose.Parse(token)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		var claims map[string]any
+		if err := json.Unmarshal(jws.Payload, &claims); err != nil {
+			t.Fatal(err)
+		}
+		return claims["sub"]
+	}
+
+	for _, tt := range []struct {
+		client *client.Client
+		want   string
+	}{
+		{pairwise, "pairwise-example.com-u1"},
+		{public, "u1"},
+	} {
+		t.Run(tt.client.ClientID, func(t *testing.T) {
+			idToken, err := issuer.Issue(context.Background(), IDTokenRequest{Issuer: "https://id.example.com/t1", Client: tt.client, User: alice, Scope: "openid"})
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			if got := subOf(idToken); got != tt.want {
+				t.Errorf("ID token sub = %v, want %s", got, tt.want)
+			}
+
+			at := &client.AccessToken{ID: "at1", TenantID: "t1", ClientID: tt.client.ClientID, UserID: "u1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+			accessToken, err := issuer.IssueAccessToken(context.Background(), AccessTokenRequest{Issuer: "https://id.example.com/t1", Client: tt.client, Token: at})
+			if err != nil {
+				t.Fatalf("IssueAccessToken() error = %v", err)
+			}
+			if got := subOf(accessToken); got != tt.want {
+				t.Errorf("access token sub = %v, want %s", got, tt.want)
+			}
+
+			claims, err := resolver.Resolve(context.Background(), UserInfoRequest{Client: tt.client, User: alice, Scope: "openid"})
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if claims["sub"] != tt.want {
+				t.Errorf("UserInfo sub = %v, want %s", claims["sub"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/authz"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// Claims released by the phone and address scopes, and with profile (OpenID Connect Core 1.0 Section 5.4)
+const (
+	ClaimUpdatedAt           = "updated_at"
+	ClaimPhoneNumber         = "phone_number"
+	ClaimPhoneNumberVerified = "phone_number_verified"
+	ClaimAddress             = "address"
+)
+
+// AuthzClaims returns a user's role and project claims in a tenant; authz.Service implements it.
+type AuthzClaims interface {
+	BuildTenantUserInfoClaims(ctx context.Context, tenantID, userID string) (*authz.UserInfoClaims, error)
+}
+
+// UserInfoRequest is everything needed to answer one UserInfo request.
+//
+// Purpose: The subject, client and granted scope of the access token presented to UserInfo.
+// Domain: OIDC
+// Invariants: Scope is the scope granted to the access token, not one the caller asks for.
+type UserInfoRequest struct {
+	Client *client.Client
+	User   *user.User
+	Scope  string
+}
+
+// UserInfoResolver builds UserInfo responses (OpenID Connect Core 1.0 Section 5.3).
+//
+// Purpose: Scope-driven claim release for the UserInfo endpoint, shared with ID tokens.
+// Domain: OIDC
+// Invariants: Releases the same standard claims as an ID token for the same scope.
+type UserInfoResolver struct {
+	authz    AuthzClaims
+	subjects SubjectMapper
+}
+
+// UserInfoOption configures optional UserInfoResolver dependencies.
+type UserInfoOption func(*UserInfoResolver)
+
+// WithUserInfoSubjects takes sub from m, so pairwise clients get the same identifier
+// as in their ID tokens. Without it sub is always the user's ID.
+func WithUserInfoSubjects(m SubjectMapper) UserInfoOption {
+	return func(r *UserInfoResolver) { r.subjects = m }
+}
+
+// NewUserInfoResolver creates a resolver reading role and project claims from a.
+//
+// Purpose: Constructor for the UserInfo claims resolver.
+// Domain: OIDC
+// Audited: No
+// Errors: None
+func NewUserInfoResolver(a AuthzClaims, opts ...UserInfoOption) *UserInfoResolver {
+	r := &UserInfoResolver{authz: a}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve returns the UserInfo claims of req.User for req.Client.
+//
+// Purpose: Claims for the UserInfo response.
+// Domain: OIDC
+// Security: profile, email, address and phone claims are released only for the granted
+// scopes. Roles and projects are added only when the client's ClaimMapping includes them,
+// and roles are limited to the client's tenant. The mapping cannot alter sub, which is
+// the client's pairwise identifier for pairwise clients.
+// Audited: No
+// Errors: ErrOpenIDScopeRequired, System errors
+func (r *UserInfoResolver) Resolve(ctx context.Context, req UserInfoRequest) (map[string]any, error) {
+	scopes := strings.Fields(req.Scope)
+	if !slices.Contains(scopes, client.ScopeOpenID) {
+		return nil, ErrOpenIDScopeRequired
+	}
+
+	sub, err := clientSubject(ctx, r.subjects, req.Client, req.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	claims := scopeClaims(req.User, scopes)
+	claims["sub"] = sub
+
+	cc := client.ClaimContext{TenantID: req.Client.TenantID}
+	if m := req.Client.ClaimMapping; r.authz != nil && m != nil && (m.IncludeRoles || m.IncludeProjects) {
+		ac, err := r.authz.BuildTenantUserInfoClaims(ctx, req.Client.TenantID, req.User.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build authorization claims: %w", err)
+		}
+		cc.Roles = ac.Roles
+		for _, p := range ac.Projects {
+			cc.Projects = append(cc.Projects, p.ID)
+		}
+	}
+	return req.Client.ClaimMapping.Apply(claims, cc), nil
+}
+
+// scopeClaims returns the standard claims of u released by scopes: profile, email,
+// address and phone (OpenID Connect Core 1.0 Section 5.4). Empty values are omitted.
+func scopeClaims(u *user.User, scopes []string) map[string]any {
+	claims := make(map[string]any)
+	if slices.Contains(scopes, client.ScopeProfile) {
+		p := u.Profile
+		for name, value := range map[string]string{
+			ClaimName:       p.FullName,
+			ClaimGivenName:  p.GivenName,
+			ClaimFamilyName: p.FamilyName,
+			ClaimNickname:   p.Nickname,
+			ClaimPicture:    p.Picture,
+			ClaimLocale:     p.Locale,
+			ClaimZoneinfo:   p.Timezone,
+		} {
+			if value != "" {
+				claims[name] = value
+			}
+		}
+		if !u.UpdatedAt.IsZero() {
+			claims[ClaimUpdatedAt] = u.UpdatedAt.Unix()
+		}
+	}
+	if slices.Contains(scopes, client.ScopeEmail) && u.EmailPlain != nil {
+		claims[ClaimEmail] = *u.EmailPlain
+		claims[ClaimEmailVerified] = u.EmailVerified
+	}
+	if slices.Contains(scopes, client.ScopeAddress) && !u.Profile.Address.IsZero() {
+		claims[ClaimAddress] = u.Profile.Address
+	}
+	if slices.Contains(scopes, client.ScopePhone) && u.Profile.PhoneNumber != "" {
+		claims[ClaimPhoneNumber] = u.Profile.PhoneNumber
+		claims[ClaimPhoneNumberVerified] = u.Profile.PhoneNumberVerified
+	}
+	return claims
+}
@@ -0,0 +1,747 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package opentrusty is the composition root of the core library. It wires
+// repositories, services, hashers, audit logging, and background jobs from a
+// validated config.Config so host binaries do not assemble them by hand.
+package opentrusty
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/admintoken"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/authorize"
+	"github.com/opentrusty/opentrusty-core/authz"
+	"github.com/opentrusty/opentrusty-core/blob"
+	"github.com/opentrusty/opentrusty-core/bootstrap"
+	"github.com/opentrusty/opentrusty-core/bruteforce"
+	"github.com/opentrusty/opentrusty-core/changelog"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/config"
+	"github.com/opentrusty/opentrusty-core/consent"
+	"github.com/opentrusty/opentrusty-core/dashboard"
+	"github.com/opentrusty/opentrusty-core/delegation"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/feature"
+	"github.com/opentrusty/opentrusty-core/flow"
+	"github.com/opentrusty/opentrusty-core/grant"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/importer"
+	"github.com/opentrusty/opentrusty-core/integrity"
+	"github.com/opentrusty/opentrusty-core/introspection"
+	"github.com/opentrusty/opentrusty-core/issuance"
+	"github.com/opentrusty/opentrusty-core/keys"
+	"github.com/opentrusty/opentrusty-core/lifecycle"
+	"github.com/opentrusty/opentrusty-core/maintenance"
+	"github.com/opentrusty/opentrusty-core/metrics"
+	"github.com/opentrusty/opentrusty-core/notify"
+	"github.com/opentrusty/opentrusty-core/oidc"
+	"github.com/opentrusty/opentrusty-core/recovery"
+	"github.com/opentrusty/opentrusty-core/reporting"
+	"github.com/opentrusty/opentrusty-core/retention"
+	"github.com/opentrusty/opentrusty-core/risk"
+	"github.com/opentrusty/opentrusty-core/rolemap"
+	"github.com/opentrusty/opentrusty-core/scheduler"
+	"github.com/opentrusty/opentrusty-core/scim"
+	"github.com/opentrusty/opentrusty-core/search"
+	"github.com/opentrusty/opentrusty-core/seed"
+	"github.com/opentrusty/opentrusty-core/session"
+	"github.com/opentrusty/opentrusty-core/store/backup"
+	"github.com/opentrusty/opentrusty-core/store/postgres"
+	"github.com/opentrusty/opentrusty-core/subject"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/token"
+	"github.com/opentrusty/opentrusty-core/tracing"
+	"github.com/opentrusty/opentrusty-core/user"
+	"github.com/opentrusty/opentrusty-core/webhook"
+)
+
+// cleanupInterval is how often expired and aged-out records are purged.
+const cleanupInterval = 15 * time.Minute
+
+// usageFlushInterval is how often buffered client usage counters are written.
+const usageFlushInterval = time.Minute
+
+// webhookInterval is how often due webhook deliveries and SCIM operations are attempted.
+const webhookInterval = 15 * time.Second
+
+// integrityInterval is how often the data integrity check runs.
+const integrityInterval = 6 * time.Hour
+
+// keyRotationInterval is how often tenant signing keys are advanced along their rotation timeline.
+const keyRotationInterval = time.Hour
+
+// Core holds the fully wired core services.
+//
+// Purpose: Single handle through which host binaries reach every core service.
+// Domain: Platform
+// Invariants: Built only by New. Shutdown (or Close) releases everything New acquired.
+type Core struct {
+	Config     *config.Config
+	DB         *postgres.DB
+	Audit      audit.Logger
+	Users      *user.Service
+	Tenants    *tenant.Service
+	RoleMaps   *rolemap.Service
+	Clients    *client.Service
+	Consent    *consent.Service
+	Grants     *grant.Service
+	Tokens     *token.Service
+	Dashboard  *dashboard.Service
+	ChangeLog  *changelog.Service
+	Search     *search.Service
+	Reports    *reporting.Service
+	Integrity  *integrity.Service
+	Sessions   *session.Service
+	Flows      *flow.Service
+	Authz      *authz.Service
+	BruteForce *bruteforce.Service
+	Risk       *risk.Service
+	Issuance   *issuance.Service
+	Recovery   *recovery.Service
+	Retention  *retention.Service
+	Bootstrap  *bootstrap.Service
+	Webhooks   *webhook.Service
+	SCIM       *scim.Service
+	Importer   *importer.Service
+	Scheduler  *scheduler.Scheduler
+	Metrics    *metrics.Metrics
+	Events     *events.Dispatcher
+	Features   *feature.Service
+	Images     *blob.Images
+	Notify     *notify.Service
+	Lifecycle  *lifecycle.Manager
+
+	AccessTokens       *postgres.AccessTokenRepository
+	RefreshTokens      *postgres.RefreshTokenRepository
+	AuthorizationCodes client.AuthorizationCodeRepository
+	ClientUsage        *client.UsageRecorder
+	AdminTokens        *admintoken.Service
+	Maintenance        *maintenance.Controller
+	Backups            *backup.Service
+	Subjects           *subject.Service
+	Delegations        *delegation.Service
+	Introspection      *introspection.Service
+	Authorize          *authorize.Service
+	IDTokens           *oidc.IDTokenIssuer
+	UserInfo           *oidc.UserInfoResolver
+	Keys               *keys.Service
+}
+
+// Option customizes how New builds a Core.
+type Option func(*options)
+
+type options struct {
+	db        *postgres.DB
+	audit     audit.Logger
+	hasher    *user.PasswordHasher
+	scheduler *scheduler.Scheduler
+	metrics   *metrics.Metrics
+	tracer    tracing.Tracer
+	sender    webhook.Sender
+	scim      scim.Transport
+	lifecycle *lifecycle.Manager
+	seedSpec  *seed.Spec
+	usedCodes client.UsedCodeCache
+	blobs     blob.Store
+	notifier  notify.Sender
+	geo       risk.GeoProvider
+	repair    bool
+
+	receiptKey   crypto.Signer
+	receiptKeyID string
+	consentTTL   time.Duration
+	clientURIs   client.URIPolicy
+	idTokenKey   crypto.Signer
+	idTokenKeyID string
+	keyPolicy    *keys.Policy
+	clock        clock.Clock
+	ids          id.Generator
+}
+
+// WithDB uses an existing database handle instead of opening one from the
+// configuration. The caller keeps ownership and must close it.
+func WithDB(db *postgres.DB) Option {
+	return func(o *options) { o.db = db }
+}
+
+// WithAuditLogger replaces the default repository-backed audit logger.
+func WithAuditLogger(l audit.Logger) Option {
+	return func(o *options) { o.audit = l }
+}
+
+// WithPasswordHasher replaces the hasher built from config.PasswordConfig.
+func WithPasswordHasher(h *user.PasswordHasher) Option {
+	return func(o *options) { o.hasher = h }
+}
+
+// WithScheduler registers the core maintenance jobs on s instead of a new scheduler.
+func WithScheduler(s *scheduler.Scheduler) Option {
+	return func(o *options) { o.scheduler = s }
+}
+
+// WithMetrics instruments the services, audit logger, and database pool on m.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(o *options) { o.metrics = m }
+}
+
+// WithTracer emits spans from the services and database queries on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(o *options) { o.tracer = t }
+}
+
+// WithWebhookSender enables webhook delivery through s. Without it Core.Webhooks is nil.
+func WithWebhookSender(s webhook.Sender) Option {
+	return func(o *options) { o.sender = s }
+}
+
+// WithSCIMTransport enables outbound SCIM provisioning through t. Without it Core.SCIM is nil.
+func WithSCIMTransport(t scim.Transport) Option {
+	return func(o *options) { o.scim = t }
+}
+
+// WithBlobStore enables avatar and logo uploads stored in s. Without it Core.Images is nil.
+func WithBlobStore(s blob.Store) Option {
+	return func(o *options) { o.blobs = s }
+}
+
+// WithNotifier enables user notifications (such as account lockouts and recovery requests) sent through s.
+// Without it Core.Notify is nil.
+func WithNotifier(s notify.Sender) Option {
+	return func(o *options) { o.notifier = s }
+}
+
+// WithIntegrityRepair makes the scheduled integrity check repair orphaned and
+// inconsistent records instead of only logging them.
+func WithIntegrityRepair() Option {
+	return func(o *options) { o.repair = true }
+}
+
+// WithClientURIPolicy restricts the client_uri and logo_uri clients may register.
+// Without it URLs must use https on public hosts and logos are not fetched.
+func WithClientURIPolicy(p client.URIPolicy) Option {
+	return func(o *options) { o.clientURIs = p }
+}
+
+// WithConsentReceiptSigner signs consent receipts with key, published under keyID.
+// Without it receipts are stored unsigned.
+func WithConsentReceiptSigner(key crypto.Signer, keyID string) Option {
+	return func(o *options) { o.receiptKey, o.receiptKeyID = key, keyID }
+}
+
+// WithConsentLifetime expires remembered consent d after the user last approved it.
+// Without it consent is kept until revoked.
+func WithConsentLifetime(d time.Duration) Option {
+	return func(o *options) { o.consentTTL = d }
+}
+
+// WithIDTokenSigner signs ID tokens with key, published under keyID, in each
+// tenant's signing algorithm. Without it ID tokens are signed with each tenant's
+// active key from Core.Keys.
+func WithIDTokenSigner(key crypto.Signer, keyID string) Option {
+	return func(o *options) { o.idTokenKey, o.idTokenKeyID = key, keyID }
+}
+
+// WithKeyRotationPolicy replaces keys.DefaultPolicy for tenant signing keys.
+// New fails if p is invalid.
+func WithKeyRotationPolicy(p keys.Policy) Option {
+	return func(o *options) { o.keyPolicy = &p }
+}
+
+// WithClock drives the user, tenant, client, session, token, introspection,
+// ID token, signing key, and backup services from c instead of the wall clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// WithIDGenerator mints user, tenant, client, and token IDs with g instead of
+// id.UUIDv7. Generated IDs must be UUIDs.
+func WithIDGenerator(g id.Generator) Option {
+	return func(o *options) { o.ids = g }
+}
+
+// WithGeoProvider enables suspicious login detection using p for IP geolocation.
+// Without it Core.Risk is nil.
+func WithGeoProvider(p risk.GeoProvider) Option {
+	return func(o *options) { o.geo = p }
+}
+
+// WithLifecycle registers the core shutdown hooks on m instead of a new manager.
+// Hooks the host registers on m after New run before the core hooks.
+func WithLifecycle(m *lifecycle.Manager) Option {
+	return func(o *options) { o.lifecycle = m }
+}
+
+// WithSeedSpec reconciles roles, permissions, and system clients with spec
+// during New. New fails if the spec is invalid or cannot be applied.
+func WithSeedSpec(spec *seed.Spec) Option {
+	return func(o *options) { o.seedSpec = spec }
+}
+
+// WithStatelessCodes issues self-contained encrypted authorization codes instead of
+// authorization_codes rows, using used to reject redeemed codes. Share used across
+// instances (cache.Redis); a per-instance cache.Memory lets a code be redeemed once per instance.
+func WithStatelessCodes(used client.UsedCodeCache) Option {
+	return func(o *options) { o.usedCodes = used }
+}
+
+// New validates cfg and wires the core services with sane defaults.
+//
+// Purpose: Composition root replacing hand-assembled constructor chains.
+// Domain: Platform
+// Security: Refuses to start with an invalid configuration.
+// Audited: No
+// Errors: config.ErrInvalidConfig, database connectivity errors, scheduler registration errors
+func New(ctx context.Context, cfg *config.Config, opts ...Option) (*Core, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	o := options{clock: clock.System(), ids: id.UUIDv7()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Core{Config: cfg, DB: o.db, Metrics: o.metrics, Events: events.NewDispatcher(), Lifecycle: o.lifecycle}
+	if c.Lifecycle == nil {
+		c.Lifecycle = lifecycle.New()
+	}
+	if c.DB == nil {
+		dbCfg := cfg.PostgresConfig()
+		dbCfg.Tracer = o.tracer
+		db, err := postgres.New(ctx, dbCfg)
+		if err != nil {
+			return nil, err
+		}
+		c.DB = db
+		if err := c.Lifecycle.Register(lifecycle.Hook{Name: "database", Stop: func(context.Context) error {
+			db.Close()
+			return nil
+		}}); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to register shutdown hook: %w", err)
+		}
+	}
+	if c.Metrics != nil {
+		c.DB.RegisterMetrics(c.Metrics)
+	}
+	c.DB.RegisterEvents(c.Events)
+
+	c.Audit = o.audit
+	if c.Audit == nil {
+		c.Audit = audit.NewRepositoryLogger(postgres.NewAuditRepository(c.DB), audit.WithMetrics(c.Metrics))
+	}
+	if err := c.Lifecycle.Register(lifecycle.Hook{Name: "audit-flush", Stop: func(ctx context.Context) error {
+		return audit.Flush(ctx, c.Audit)
+	}}); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to register shutdown hook: %w", err)
+	}
+
+	hasher := o.hasher
+	if hasher == nil {
+		hasher = cfg.PasswordHasher()
+	}
+
+	c.Features = feature.NewService(postgres.NewFeatureRepository(c.DB), cfg.FeatureFlags(), c.Audit)
+	c.Maintenance = maintenance.NewController(cfg.PlatformMode(), c.Audit)
+
+	userRepo := postgres.NewUserRepository(c.DB)
+	clientRepo := postgres.NewClientRepository(c.DB)
+	usageRepo := postgres.NewUsageRepository(c.DB)
+
+	userOpts := []user.Option{
+		user.WithMetrics(c.Metrics),
+		user.WithTracer(o.tracer),
+		user.WithEvents(c.Events),
+		user.WithFeatures(c.Features),
+		user.WithIdentities(postgres.NewIdentityRepository(c.DB)),
+		user.WithMaintenance(c.Maintenance),
+		user.WithClock(o.clock),
+		user.WithIDGenerator(o.ids),
+	}
+	revoker := &credentialRevoker{grants: postgres.NewGrantRepository(c.DB)}
+	userOpts = append(userOpts,
+		user.WithCredentialRevoker(revoker),
+		user.WithPasswordChangeRevocation(!cfg.Identity.KeepSessionsOnPasswordChange),
+	)
+	var clientOpts []client.Option
+	if o.blobs != nil {
+		c.Images = blob.NewImages(o.blobs)
+		c.Events.Subscribe(events.NameUserDeleted, c.Images.HandleEvent)
+		c.Events.Subscribe(events.NameClientDeleted, c.Images.HandleEvent)
+		userOpts = append(userOpts, user.WithAvatars(c.Images))
+		clientOpts = append(clientOpts, client.WithLogos(c.Images))
+	}
+
+	c.Users = user.NewService(
+		userRepo,
+		hasher,
+		c.Audit,
+		cfg.Identity.LockoutMaxAttempts,
+		time.Duration(cfg.Identity.LockoutDuration),
+		string(cfg.Identity.Secret),
+		userOpts...,
+	)
+	c.Authz = authz.NewService(
+		postgres.NewProjectRepository(c.DB),
+		postgres.NewRoleRepository(c.DB),
+		postgres.NewAssignmentRepository(c.DB),
+		authz.WithMetrics(c.Metrics),
+		authz.WithTracer(o.tracer),
+		authz.WithHistory(postgres.NewRoleHistoryRepository(c.DB)),
+		authz.WithAuditLogger(c.Audit),
+	)
+	c.ChangeLog = changelog.NewService(postgres.NewChangeLogRepository(c.DB), c.Authz,
+		changelog.WithClock(o.clock),
+		changelog.WithIDGenerator(o.ids),
+	)
+	c.Tenants = tenant.NewService(
+		postgres.NewTenantRepository(c.DB),
+		postgres.NewTenantRoleRepository(c.DB),
+		postgres.NewPolicyAssignmentRepository(c.DB),
+		c.Users,
+		clientRepo,
+		postgres.NewMembershipRepository(c.DB),
+		c.Audit,
+		tenant.WithTracer(o.tracer),
+		tenant.WithEvents(c.Events),
+		tenant.WithClock(o.clock),
+		tenant.WithIDGenerator(o.ids),
+		tenant.WithChangeLog(c.ChangeLog),
+	)
+	c.RoleMaps = rolemap.NewService(postgres.NewRoleMappingRepository(c.DB), c.Tenants, c.Audit, rolemap.WithTracer(o.tracer))
+	c.Clients = client.NewService(
+		clientRepo,
+		c.Audit,
+		append([]client.Option{
+			client.WithTracer(o.tracer),
+			client.WithEvents(c.Events),
+			client.WithFeatures(c.Features),
+			client.WithPermissions(c.Authz),
+			client.WithUsage(usageRepo),
+			client.WithSigningAlgorithms(c.Tenants),
+			client.WithURIPolicy(o.clientURIs),
+			client.WithReasonPolicy(c.Tenants),
+			client.WithRegistrationPolicies(c.Tenants),
+			client.WithClock(o.clock),
+			client.WithIDGenerator(o.ids),
+			client.WithChangeLog(c.ChangeLog),
+		}, clientOpts...)...,
+	)
+	c.ClientUsage = client.NewUsageRecorder(usageRepo)
+	c.Events.Subscribe(events.NameTokenIssued, c.ClientUsage.HandleEvent)
+	consentOpts := []consent.Option{consent.WithGrantLifetime(o.consentTTL), consent.WithClock(o.clock)}
+	if o.receiptKey != nil {
+		consentOpts = append(consentOpts, consent.WithReceiptSigner(o.receiptKey, o.receiptKeyID))
+	}
+	c.Consent = consent.NewService(postgres.NewConsentRepository(c.DB), c.Authz, c.Audit, consentOpts...)
+	c.Grants = grant.NewService(revoker.grants, c.Authz, c.Audit)
+	c.Delegations = delegation.NewService(postgres.NewDelegationRepository(c.DB), c.Authz, c.Clients, revoker.grants, c.Audit,
+		delegation.WithReasonPolicy(c.Tenants),
+		delegation.WithClock(o.clock),
+		delegation.WithIDGenerator(o.ids),
+		delegation.WithTracer(o.tracer),
+	)
+	c.Dashboard = dashboard.NewService(postgres.NewDashboardRepository(c.DB), c.Authz, dashboard.WithTracer(o.tracer))
+	c.Search = search.NewService(postgres.NewSearchRepository(c.DB), c.Authz, search.WithTracer(o.tracer))
+	c.Reports = reporting.NewService(postgres.NewReportRepository(c.DB), c.Authz, reporting.WithTracer(o.tracer))
+	c.Events.Subscribe(events.NameSessionCreated, c.Reports.HandleEvent)
+	c.Events.Subscribe(events.NameLoginFailed, c.Reports.HandleEvent)
+	c.Events.Subscribe(events.NameTokenIssued, c.Reports.HandleEvent)
+	c.Retention = retention.NewService(postgres.NewRetentionRepository(c.DB), c.Authz, c.Audit, retention.WithTracer(o.tracer))
+	c.Integrity = integrity.NewService(postgres.NewIntegrityRepository(c.DB), c.Audit,
+		integrity.WithAutoRepair(o.repair),
+		integrity.WithHoldChecker(c.Retention),
+		integrity.WithTracer(o.tracer),
+	)
+	c.Backups = backup.NewService(c.DB, c.Audit, backup.WithClock(o.clock))
+	c.Sessions = session.NewService(
+		postgres.NewSessionRepository(c.DB),
+		time.Duration(cfg.Session.Lifetime),
+		time.Duration(cfg.Session.IdleTimeout),
+		session.WithMetrics(c.Metrics),
+		session.WithTracer(o.tracer),
+		session.WithEvents(c.Events),
+		session.WithClock(o.clock),
+	)
+	revoker.sessions = c.Sessions
+	c.Flows = flow.NewService(postgres.NewFlowRepository(c.DB), flow.WithTracer(o.tracer))
+
+	c.Bootstrap = bootstrap.NewService(postgres.NewBootstrapRepository(c.DB), c.Users, c.Audit, bootstrap.DefaultTokenTTL)
+	c.Importer = importer.NewService(c.Users, c.Tenants, c.Clients)
+	c.BruteForce = bruteforce.NewService(postgres.NewIPReputationRepository(c.DB), c.Audit, bruteforce.DefaultPolicy())
+	c.Recovery = recovery.NewService(postgres.NewRecoveryRepository(c.DB), c.Users, c.Authz, c.Audit,
+		recovery.WithCredentialRevoker(revoker),
+		recovery.WithEvents(c.Events),
+		recovery.WithTracer(o.tracer),
+	)
+
+	c.Issuance = issuance.NewService(postgres.NewIssuanceRepository(c.DB), c.Audit, issuance.WithMetrics(c.Metrics), issuance.WithTracer(o.tracer))
+	c.Events.Subscribe(events.NameTokenIssued, c.Issuance.HandleEvent)
+	c.Events.Subscribe(events.NameSessionCreated, c.Issuance.HandleEvent)
+	c.AdminTokens = admintoken.NewService(postgres.NewAdminTokenRepository(c.DB), c.Sessions, c.Authz, c.Audit, admintoken.WithTracer(o.tracer))
+	c.Events.Subscribe(events.NameSessionRevoked, c.AdminTokens.HandleEvent)
+	c.Events.Subscribe(events.NameUserLocked, c.AdminTokens.HandleEvent)
+	c.Events.Subscribe(events.NameUserDeleted, c.AdminTokens.HandleEvent)
+	if o.geo != nil {
+		c.Risk = risk.NewService(
+			postgres.NewLoginLocationRepository(c.DB),
+			o.geo,
+			c.Audit,
+			risk.WithEvents(c.Events),
+			risk.WithTracer(o.tracer),
+			risk.WithIssuanceFlags(c.Issuance),
+		)
+	}
+	if o.notifier != nil {
+		c.Notify = notify.NewService(o.notifier)
+		c.Events.Subscribe(events.NameUserLocked, c.Notify.HandleEvent)
+		c.Events.Subscribe(events.NameSuspiciousLogin, c.Notify.HandleEvent)
+		c.Events.Subscribe(events.NameRecoveryStarted, c.Notify.HandleEvent)
+		c.Events.Subscribe(events.NameUserRecovered, c.Notify.HandleEvent)
+	}
+	if o.sender != nil {
+		c.Webhooks = webhook.NewService(postgres.NewWebhookRepository(c.DB), o.sender, c.Audit, webhook.DefaultRetryPolicy())
+		c.Events.SubscribeAll(c.Webhooks.HandleEvent)
+	}
+	if o.scim != nil {
+		c.SCIM = scim.NewService(postgres.NewSCIMRepository(c.DB), userRepo, postgres.NewTenantRoleRepository(c.DB), o.scim, c.Audit, scim.DefaultRetryPolicy())
+		c.Events.SubscribeAll(c.SCIM.HandleEvent)
+	}
+
+	if o.seedSpec != nil {
+		seeder := seed.NewService(postgres.NewPermissionRepository(c.DB), postgres.NewRoleRepository(c.DB), clientRepo)
+		if _, err := seeder.Sync(ctx, o.seedSpec, false); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to sync seed spec: %w", err)
+		}
+	}
+
+	c.AccessTokens = postgres.NewAccessTokenRepository(c.DB)
+	c.RefreshTokens = postgres.NewRefreshTokenRepository(c.DB)
+	if o.usedCodes != nil {
+		codes, err := client.NewStatelessCodeRepository([]byte(cfg.Identity.Secret), o.usedCodes, client.WithSessionCheck(func(ctx context.Context, sessionID string) error {
+			_, err := c.Sessions.Get(ctx, sessionID)
+			return err
+		}))
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to configure stateless authorization codes: %w", err)
+		}
+		c.AuthorizationCodes = codes
+	} else {
+		c.AuthorizationCodes = postgres.NewAuthorizationCodeRepository(c.DB)
+	}
+	subjects, err := subject.NewService(postgres.NewSubjectRepository(c.DB), []byte(cfg.Identity.Secret), subject.WithClock(o.clock))
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to configure subject identifiers: %w", err)
+	}
+	c.Subjects = subjects
+	c.Introspection = introspection.NewService(c.Clients, c.Authz, c.AccessTokens, c.RefreshTokens,
+		introspection.WithSubjects(c.Clients, c.Subjects),
+		introspection.WithTracer(o.tracer),
+		introspection.WithClock(o.clock),
+	)
+	c.Authorize = authorize.NewService(c.Clients,
+		authorize.WithFeatures(c.Features),
+		authorize.WithTracer(o.tracer),
+	)
+	keyOpts := []keys.Option{keys.WithAlgorithms(c.Tenants), keys.WithTracer(o.tracer), keys.WithClock(o.clock)}
+	if o.keyPolicy != nil {
+		keyOpts = append(keyOpts, keys.WithPolicy(*o.keyPolicy))
+	}
+	signingKeys, err := keys.NewService(postgres.NewSigningKeyRepository(c.DB), []byte(cfg.Identity.Secret), c.Audit, keyOpts...)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.Keys = signingKeys
+	var idTokenKeys oidc.KeySource = c.Keys
+	if o.idTokenKey != nil {
+		idTokenKeys = oidc.StaticKey{Signer: o.idTokenKey, KeyID: o.idTokenKeyID}
+	}
+	c.IDTokens = oidc.NewIDTokenIssuer(idTokenKeys,
+		oidc.WithAlgorithms(c.Tenants),
+		oidc.WithSubjects(c.Subjects),
+		oidc.WithTracer(o.tracer),
+		oidc.WithClock(o.clock),
+	)
+	c.UserInfo = oidc.NewUserInfoResolver(c.Authz, oidc.WithUserInfoSubjects(c.Subjects))
+	c.Tokens = token.NewService(c.Clients, c.AuthorizationCodes, c.AccessTokens, c.RefreshTokens, c.Audit,
+		token.WithIssuanceGate(c.Issuance),
+		token.WithPasswordGrant(c.Users, postgres.NewMembershipRepository(c.DB), c.Tenants),
+		token.WithAttemptLimiter(c.BruteForce),
+		token.WithJWTAccessTokens(c.IDTokens, c.Tenants),
+		token.WithDelegations(c.Delegations),
+		token.WithTracer(o.tracer),
+		token.WithMaintenance(c.Maintenance),
+		token.WithFeatures(c.Features),
+		token.WithClock(o.clock),
+		token.WithIDGenerator(o.ids),
+	)
+
+	if err := c.Lifecycle.Register(lifecycle.Hook{Name: "client-usage-flush", Stop: c.ClientUsage.Flush}); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to register shutdown hook: %w", err)
+	}
+	if err := c.Lifecycle.Register(lifecycle.Hook{Name: "report-stats-flush", Stop: c.Reports.Flush}); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to register shutdown hook: %w", err)
+	}
+
+	c.Scheduler = o.scheduler
+	if c.Scheduler == nil {
+		c.Scheduler = scheduler.New()
+	}
+	if err := c.Lifecycle.Register(lifecycle.Hook{Name: "scheduler", Stop: func(context.Context) error {
+		c.Scheduler.Stop()
+		return nil
+	}}); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to register shutdown hook: %w", err)
+	}
+	if err := c.registerJobs(); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to register maintenance jobs: %w", err)
+	}
+
+	return c, nil
+}
+
+// Start begins background maintenance jobs.
+func (c *Core) Start(ctx context.Context) error {
+	return c.Scheduler.Start(ctx)
+}
+
+// Shutdown runs the lifecycle hooks: it stops background jobs (webhook and
+// SCIM deliveries included) and waits for running ones, flushes buffered client
+// usage and audit events, and releases the database handle if New opened it.
+//
+// Purpose: Graceful shutdown that does not lose in-flight audit events.
+// Domain: Platform
+// Audited: No
+// Errors: Joined hook errors, lifecycle.ErrHookTimeout
+func (c *Core) Shutdown(ctx context.Context) error {
+	return c.Lifecycle.Shutdown(ctx)
+}
+
+// Close is Shutdown without a deadline beyond the per-hook timeouts.
+func (c *Core) Close() {
+	_ = c.Shutdown(context.Background())
+}
+
+// Health statuses
+const (
+	// HealthOK reports full service.
+	HealthOK = "ok"
+	// HealthDegraded reports read-only or maintenance mode: existing credentials
+	// still validate, but logins and token issuance are refused.
+	HealthDegraded = "degraded"
+	// HealthUnavailable reports that the database cannot be reached.
+	HealthUnavailable = "unavailable"
+)
+
+// Health is the readiness report of a Core.
+//
+// Purpose: Body of the host's health endpoint.
+// Domain: Platform
+// Invariants: Status is HealthUnavailable whenever Database is not "ok".
+type Health struct {
+	Status   string             `json:"status"`
+	Database string             `json:"database"`
+	Platform maintenance.Status `json:"platform"`
+}
+
+// Health checks the database and reports the platform mode.
+//
+// Purpose: Readiness probe that lets load balancers and operators observe
+// read-only and maintenance mode.
+// Domain: Platform
+// Audited: No
+// Errors: None; failures are reported in the result
+func (c *Core) Health(ctx context.Context) Health {
+	h := Health{Status: HealthOK, Database: HealthOK, Platform: c.Maintenance.Status()}
+	if err := c.DB.Pool().Ping(ctx); err != nil {
+		h.Status, h.Database = HealthUnavailable, HealthUnavailable
+		return h
+	}
+	if h.Platform.Mode != maintenance.ModeNormal {
+		h.Status = HealthDegraded
+	}
+	return h
+}
+
+// credentialRevoker ends a user's sessions and tokens in every tenant when
+// user.Service invalidates their credentials.
+type credentialRevoker struct {
+	sessions *session.Service
+	grants   *postgres.GrantRepository
+}
+
+// RevokeUserCredentials destroys the user's sessions and revokes their tokens.
+func (r *credentialRevoker) RevokeUserCredentials(ctx context.Context, userID string) error {
+	if err := r.sessions.DestroyAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to destroy sessions: %w", err)
+	}
+	if _, err := r.grants.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokeOtherCredentials destroys the user's sessions except keepSessionID and
+// revokes their tokens.
+func (r *credentialRevoker) RevokeOtherCredentials(ctx context.Context, userID, keepSessionID string) error {
+	if err := r.sessions.DestroyOthersForUser(ctx, userID, keepSessionID); err != nil {
+		return fmt.Errorf("failed to destroy sessions: %w", err)
+	}
+	if _, err := r.grants.RevokeAllForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+func (c *Core) registerJobs() error {
+	jobs := []scheduler.Job{
+		{Name: "retention-purge", Interval: cleanupInterval, Run: c.Retention.Run},
+		{Name: "login-flow-cleanup", Interval: cleanupInterval, Run: c.Flows.CleanupExpired},
+		{Name: "bruteforce-prune", Interval: cleanupInterval, Run: c.BruteForce.Prune},
+		{Name: "recovery-cleanup", Interval: cleanupInterval, Run: c.Recovery.CleanupExpired},
+		{Name: "issuance-flag-cleanup", Interval: cleanupInterval, Run: c.Issuance.CleanupExpired},
+		{Name: "admin-token-cleanup", Interval: cleanupInterval, Run: c.AdminTokens.CleanupExpired},
+		{Name: "client-usage-flush", Interval: usageFlushInterval, Run: c.ClientUsage.Flush},
+		{Name: "report-stats-flush", Interval: usageFlushInterval, Run: c.Reports.Flush},
+		{Name: "integrity-check", Interval: integrityInterval, Run: c.Integrity.Run},
+		{Name: "signing-key-rotation", Interval: keyRotationInterval, Run: c.Keys.RotateDue},
+	}
+	if c.Webhooks != nil {
+		jobs = append(jobs, scheduler.Job{Name: "webhook-delivery", Interval: webhookInterval, Run: c.Webhooks.ProcessDue})
+	}
+	if c.SCIM != nil {
+		jobs = append(jobs, scheduler.Job{Name: "scim-delivery", Interval: webhookInterval, Run: c.SCIM.ProcessDue})
+	}
+	for _, job := range jobs {
+		if err := c.Scheduler.Register(job); err != nil {
+			return fmt.Errorf("%s: %w", job.Name, err)
+		}
+	}
+	return nil
+}
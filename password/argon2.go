@@ -17,11 +17,29 @@ package password
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/opentrusty/opentrusty-core/crypto"
 	"golang.org/x/crypto/argon2"
 )
 
+// Parameter bounds accepted when decoding a stored hash. Anything outside of
+// these limits is rejected before key derivation so that a malformed or
+// hostile hash cannot trigger a panic or an unbounded allocation.
+const (
+	maxMemory      = 1 << 20 // KiB (1 GiB)
+	maxIterations  = 64
+	minSaltLength  = 8
+	minKeyLength   = 16
+	maxKeyLength   = 128
+	maxSaltB64Size = 128
+)
+
+// ErrInvalidHash is returned when an encoded hash cannot be parsed.
+var ErrInvalidHash = errors.New("invalid hash format")
+
 // Hasher handles password hashing using Argon2id.
 //
 // Purpose: Primary mechanism for secure password storage and verification.
@@ -74,7 +92,7 @@ func (h *Hasher) Hash(password string) (string, error) {
 	)
 
 	return fmt.Sprintf(
-		"=%d=%d,t=%d,p=%d$%s$%s",
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
 		argon2.Version,
 		h.Memory,
 		h.Iterations,
@@ -92,25 +110,9 @@ func (h *Hasher) Hash(password string) (string, error) {
 // Audited: No
 // Errors: Invalid hash format, decoding errors
 func (h *Hasher) Verify(password, encodedHash string) (bool, error) {
-	var version int
-	var memory, iterations uint32
-	var parallelism uint8
-	var saltB64, hashB64 string
-
-	_, err := fmt.Sscanf(encodedHash, "=%d=%d,t=%d,p=%d$%s$%s",
-		&version, &memory, &iterations, &parallelism, &saltB64, &hashB64)
-	if err != nil {
-		return false, fmt.Errorf("invalid hash format: %w", err)
-	}
-
-	salt, err := base64.RawStdEncoding.DecodeString(saltB64)
-	if err != nil {
-		return false, fmt.Errorf("failed to decode salt: %w", err)
-	}
-
-	expectedHash, err := base64.RawStdEncoding.DecodeString(hashB64)
+	memory, iterations, parallelism, salt, expectedHash, err := decodeHash(encodedHash)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode hash: %w", err)
+		return false, err
 	}
 
 	actualHash := argon2.IDKey(
@@ -122,14 +124,51 @@ func (h *Hasher) Verify(password, encodedHash string) (bool, error) {
 		uint32(len(expectedHash)),
 	)
 
-	if len(actualHash) != len(expectedHash) {
-		return false, nil
+	return crypto.ConstantTimeEqual(actualHash, expectedHash), nil
+}
+
+// decodeHash parses an encoded hash of the form
+// $argon2id$v=19$m=65536,t=3,p=4$salt$hash and validates its parameters.
+func decodeHash(encodedHash string) (memory, iterations uint32, parallelism uint8, salt, key []byte, err error) {
+	parts := strings.Split(encodedHash, "$")
+	// Leading "$" yields an empty first element.
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return 0, 0, 0, nil, nil, ErrInvalidHash
 	}
 
-	var diff byte
-	for i := range actualHash {
-		diff |= actualHash[i] ^ expectedHash[i]
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("%w: invalid version", ErrInvalidHash)
+	}
+	if version != argon2.Version {
+		return 0, 0, 0, nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHash, version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("%w: invalid parameters", ErrInvalidHash)
+	}
+	if memory == 0 || memory > maxMemory || iterations == 0 || iterations > maxIterations || parallelism == 0 {
+		return 0, 0, 0, nil, nil, fmt.Errorf("%w: parameters out of range", ErrInvalidHash)
+	}
+
+	if len(parts[4]) > maxSaltB64Size {
+		return 0, 0, 0, nil, nil, fmt.Errorf("%w: salt too long", ErrInvalidHash)
+	}
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+	if len(salt) < minSaltLength {
+		return 0, 0, 0, nil, nil, fmt.Errorf("%w: salt too short", ErrInvalidHash)
+	}
+
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("failed to decode hash: %w", err)
+	}
+	if len(key) < minKeyLength || len(key) > maxKeyLength {
+		return 0, 0, 0, nil, nil, fmt.Errorf("%w: hash length out of range", ErrInvalidHash)
 	}
 
-	return diff == 0, nil
+	return memory, iterations, parallelism, salt, key, nil
 }
@@ -0,0 +1,98 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package password
+
+import (
+	"testing"
+)
+
+func TestHashVerifyRoundTrip(t *testing.T) {
+	h := NewHasher(1024, 1, 1, 16, 32)
+
+	encoded, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("failed to hash: %v", err)
+	}
+
+	ok, err := h.Verify("correct horse battery staple", encoded)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if !ok {
+		t.Error("expected password to verify")
+	}
+
+	ok, err = h.Verify("wrong password", encoded)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if ok {
+		t.Error("expected wrong password to be rejected")
+	}
+}
+
+func TestVerifyRejectsOutOfRangeParameters(t *testing.T) {
+	h := NewHasher(1024, 1, 1, 16, 32)
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"zero iterations", "$argon2id$v=19$m=1024,t=0,p=1$c29tZXNhbHRzb21lc2FsdA$MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"},
+		{"zero parallelism", "$argon2id$v=19$m=1024,t=1,p=0$c29tZXNhbHRzb21lc2FsdA$MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"},
+		{"huge memory", "$argon2id$v=19$m=4294967295,t=1,p=1$c29tZXNhbHRzb21lc2FsdA$MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"},
+		{"empty hash", "$argon2id$v=19$m=1024,t=1,p=1$c29tZXNhbHRzb21lc2FsdA$"},
+		{"wrong version", "$argon2id$v=16$m=1024,t=1,p=1$c29tZXNhbHRzb21lc2FsdA$MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"},
+		{"wrong variant", "$argon2i$v=19$m=1024,t=1,p=1$c29tZXNhbHRzb21lc2FsdA$MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := h.Verify("password", tt.encoded)
+			if err == nil || ok {
+				t.Errorf("expected rejection, got ok=%v err=%v", ok, err)
+			}
+		})
+	}
+}
+
+func FuzzVerify(f *testing.F) {
+	h := NewHasher(64, 1, 1, 8, 16)
+	valid, err := h.Hash("password")
+	if err != nil {
+		f.Fatalf("failed to hash seed: %v", err)
+	}
+
+	f.Add("password", valid)
+	f.Add("password", "$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$MDEyMzQ1Njc4OWFiY2RlZg")
+	f.Add("", "$argon2id$v=19$m=0,t=0,p=0$$")
+	f.Add("x", "$$$$$")
+	f.Add("x", "")
+
+	f.Fuzz(func(t *testing.T, password, encoded string) {
+		memory, _, _, _, _, err := decodeHash(encoded)
+		if err != nil {
+			return
+		}
+		// Keep the fuzzer fast; the bounds themselves are covered above.
+		if memory > 1024 {
+			return
+		}
+		ok, _ := h.Verify(password, encoded)
+		if ok && encoded == valid && password != "password" {
+			t.Errorf("Verify(%q) matched the hash of a different password", password)
+		}
+	})
+}
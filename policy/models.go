@@ -16,21 +16,22 @@ package policy
 
 import (
 	"context"
-	"errors"
 	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
 )
 
 // Domain errors
 var (
-	ErrProjectNotFound         = errors.New("project not found")
-	ErrProjectAlreadyExists    = errors.New("project already exists")
-	ErrAssignmentNotFound      = errors.New("assignment not found")
-	ErrAssignmentAlreadyExists = errors.New("assignment already exists")
-	ErrRoleNotFound            = errors.New("role not found")
-	ErrRoleAlreadyExists       = errors.New("role already exists")
-	ErrAccessDenied            = errors.New("access denied")
-	ErrInvalidPermission       = errors.New("invalid permission")
-	ErrInvalidScope            = errors.New("invalid scope")
+	ErrProjectNotFound         = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "project not found")
+	ErrProjectAlreadyExists    = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "project already exists")
+	ErrAssignmentNotFound      = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "assignment not found")
+	ErrAssignmentAlreadyExists = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "assignment already exists")
+	ErrRoleNotFound            = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "role not found")
+	ErrRoleAlreadyExists       = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "role already exists")
+	ErrAccessDenied            = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2AccessDenied, "access denied")
+	ErrInvalidPermission       = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid permission")
+	ErrInvalidScope            = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid scope")
 )
 
 // Scope defines the level at which a role is assigned
@@ -57,6 +57,14 @@ const (
 
 	// PermTenantViewAudit allows viewing tenant-scoped audit logs.
 	PermTenantViewAudit = "tenant:view_audit"
+
+	// PermTenantTrustClients allows marking OAuth2 clients as trusted first-party
+	// applications, which skips the consent screen for their users.
+	PermTenantTrustClients = "tenant:trust_clients"
+
+	// PermTenantApproveDelegations allows approving delegation agreements under which
+	// a service account mints tokens on a user's behalf for background jobs.
+	PermTenantApproveDelegations = "tenant:approve_delegations"
 )
 
 // -----------------------------------------------------------------------------
@@ -108,6 +116,8 @@ var AllPermissions = []string{
 	PermTenantViewUsers,
 	PermTenantView,
 	PermTenantViewAudit,
+	PermTenantTrustClients,
+	PermTenantApproveDelegations,
 	// User
 	PermUserReadProfile,
 	PermUserWriteProfile,
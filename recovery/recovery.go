@@ -0,0 +1,190 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package recovery restores access for users who lost both their password and
+// their second factor. A tenant enables time-delayed recovery (the account owner
+// is notified and can cancel during the delay) and/or recovery attested by a
+// tenant administrator who recently re-authenticated.
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrRequestNotFound       = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "recovery request not found")
+	ErrPolicyNotFound        = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "recovery policy not found")
+	ErrMethodDisabled        = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2AccessDenied, "recovery method is not enabled for this tenant")
+	ErrRecoveryPending       = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "a recovery request is already pending")
+	ErrNotReady              = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2AccessDenied, "recovery request cannot be completed yet")
+	ErrRequestClosed         = apperror.New(apperror.CodeInvalidRequest, apperror.StatusConflict, "", "recovery request is no longer open")
+	ErrInvalidToken          = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, "", "invalid recovery token")
+	ErrNotPermitted          = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to manage this user's recovery")
+	ErrSelfAttestation       = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "administrators cannot attest their own recovery")
+	ErrJustificationRequired = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "attestation requires a justification")
+	ErrStepUpRequired        = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2LoginRequired, "recent re-authentication is required")
+	ErrInvalidPolicy         = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid recovery policy")
+)
+
+// Method is how a recovery is authorized.
+type Method string
+
+// Recovery methods
+const (
+	// MethodDelayed completes after Policy.Delay unless the account owner cancels.
+	MethodDelayed Method = "delayed"
+	// MethodAdminAttested completes once a tenant administrator attests the requester's identity.
+	MethodAdminAttested Method = "admin_attested"
+)
+
+// Status is the lifecycle state of a recovery request.
+type Status string
+
+// Statuses
+const (
+	StatusPending   Status = "pending"
+	StatusApproved  Status = "approved"
+	StatusCancelled Status = "cancelled"
+	StatusCompleted Status = "completed"
+)
+
+// Bounds for policy values
+const (
+	MinDelay        = 24 * time.Hour
+	MaxDelay        = 30 * 24 * time.Hour
+	MinStepUpMaxAge = time.Minute
+	MaxStepUpMaxAge = time.Hour
+	// CompletionWindow is how long a ready request can be completed
+	CompletionWindow = 72 * time.Hour
+	// AttestationWindow is how long an admin-attested request waits for an administrator
+	AttestationWindow = 7 * 24 * time.Hour
+	// maxJustificationLength bounds the attestation justification
+	maxJustificationLength = 1000
+)
+
+// Request is one attempt to recover an account.
+//
+// Purpose: Persisted, auditable recovery workflow state.
+// Domain: Identity
+// Invariants: Only the SHA-256 of the recovery token is stored. At most one request
+// per user and tenant is pending or approved. A delayed request is ready at AvailableAt;
+// an attested one once approved. Nothing completes after ExpiresAt.
+type Request struct {
+	ID            string     `json:"id"`
+	TenantID      string     `json:"tenant_id"`
+	UserID        string     `json:"user_id"`
+	Method        Method     `json:"method"`
+	Status        Status     `json:"status"`
+	Reason        string     `json:"reason,omitempty"`
+	TokenHash     string     `json:"-"`
+	AvailableAt   time.Time  `json:"available_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	ApprovedBy    string     `json:"approved_by,omitempty"`
+	ApprovedAt    *time.Time `json:"approved_at,omitempty"`
+	Justification string     `json:"justification,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// IsOpen reports whether the request can still progress at now.
+func (r *Request) IsOpen(now time.Time) bool {
+	return (r.Status == StatusPending || r.Status == StatusApproved) && now.Before(r.ExpiresAt)
+}
+
+// IsReady reports whether the request can be completed at now.
+func (r *Request) IsReady(now time.Time) bool {
+	if !r.IsOpen(now) {
+		return false
+	}
+	switch r.Method {
+	case MethodDelayed:
+		return r.Status == StatusPending && !now.Before(r.AvailableAt)
+	case MethodAdminAttested:
+		return r.Status == StatusApproved
+	}
+	return false
+}
+
+// Policy is a tenant's recovery configuration.
+//
+// Purpose: Per-tenant choice of recovery methods and their safeguards.
+// Domain: Identity
+// Invariants: Delay is within [MinDelay, MaxDelay] and StepUpMaxAge within
+// [MinStepUpMaxAge, MaxStepUpMaxAge]. Both methods disabled turns recovery off.
+type Policy struct {
+	DelayedEnabled          bool          `json:"delayed_enabled"`
+	Delay                   time.Duration `json:"delay"`
+	AdminAttestationEnabled bool          `json:"admin_attestation_enabled"`
+	// StepUpMaxAge is how recently the attesting administrator must have re-authenticated.
+	StepUpMaxAge time.Duration `json:"step_up_max_age"`
+}
+
+// DefaultPolicy returns the configuration of tenants that never set one:
+// delayed recovery after 72 hours, no admin attestation.
+func DefaultPolicy() Policy {
+	return Policy{
+		DelayedEnabled: true,
+		Delay:          72 * time.Hour,
+		StepUpMaxAge:   5 * time.Minute,
+	}
+}
+
+// Validate checks the policy bounds.
+func (p *Policy) Validate() error {
+	if p.Delay < MinDelay || p.Delay > MaxDelay {
+		return fmt.Errorf("%w: delay must be between %s and %s", ErrInvalidPolicy, MinDelay, MaxDelay)
+	}
+	if p.StepUpMaxAge < MinStepUpMaxAge || p.StepUpMaxAge > MaxStepUpMaxAge {
+		return fmt.Errorf("%w: step-up max age must be between %s and %s", ErrInvalidPolicy, MinStepUpMaxAge, MaxStepUpMaxAge)
+	}
+	return nil
+}
+
+// Enabled reports whether m may be used under the policy.
+func (p *Policy) Enabled(m Method) bool {
+	switch m {
+	case MethodDelayed:
+		return p.DelayedEnabled
+	case MethodAdminAttested:
+		return p.AdminAttestationEnabled
+	}
+	return false
+}
+
+// Repository defines persistence for recovery requests and policies.
+//
+// Purpose: Shared storage for the recovery workflow.
+// Domain: Identity
+type Repository interface {
+	// Create persists a new request, or returns ErrRecoveryPending if the user has an open one
+	Create(ctx context.Context, r *Request) error
+	// Get returns a request of a tenant
+	Get(ctx context.Context, tenantID, id string) (*Request, error)
+	// ListOpen returns the tenant's pending and approved requests, oldest first
+	ListOpen(ctx context.Context, tenantID string, now time.Time) ([]*Request, error)
+	// Update saves r if its stored status still equals from, else returns ErrRequestClosed
+	Update(ctx context.Context, r *Request, from Status) error
+	// DeleteExpired removes requests that expired before now
+	DeleteExpired(ctx context.Context, now time.Time) error
+
+	// GetPolicy returns a tenant's policy, or ErrPolicyNotFound
+	GetPolicy(ctx context.Context, tenantID string) (*Policy, error)
+	// SetPolicy creates or replaces a tenant's policy
+	SetPolicy(ctx context.Context, tenantID string, p *Policy) error
+}
@@ -0,0 +1,433 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recovery
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Audit metadata keys
+const (
+	attrMethod        = "method"
+	attrAvailableAt   = "available_at"
+	attrJustification = "justification"
+	attrDelay         = "delay"
+)
+
+// PasswordSetter sets a new password; user.Service implements it.
+type PasswordSetter interface {
+	SetPassword(ctx context.Context, userID, password string) error
+}
+
+// CredentialRevoker ends a user's sessions and tokens; the composition root
+// passes the same revoker it gives user.Service.
+type CredentialRevoker interface {
+	RevokeUserCredentials(ctx context.Context, userID string) error
+}
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// Service runs the account recovery workflow.
+//
+// Purpose: Time-delayed and admin-attested recovery for users who lost every factor.
+// Domain: Identity
+// Invariants: Recovery tokens are returned once and stored hashed. Completion
+// revokes every session and token of the user.
+type Service struct {
+	repo        Repository
+	users       PasswordSetter
+	permissions PermissionChecker
+	auditLogger audit.Logger
+	revoker     CredentialRevoker
+	events      events.Publisher
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithCredentialRevoker revokes the user's sessions and tokens through r when a recovery completes.
+func WithCredentialRevoker(r CredentialRevoker) Option {
+	return func(s *Service) { s.revoker = r }
+}
+
+// WithEvents publishes RecoveryStarted and UserRecovered events on p.
+func WithEvents(p events.Publisher) Option {
+	return func(s *Service) { s.events = p }
+}
+
+// WithTracer emits spans for recovery completion on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new account recovery service.
+//
+// Purpose: Constructor for the account recovery service.
+// Domain: Identity
+// Audited: No
+// Errors: None
+func NewService(repo Repository, users PasswordSetter, permissions PermissionChecker, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		users:       users,
+		permissions: permissions,
+		auditLogger: auditLogger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetPolicy returns the tenant's recovery policy, or DefaultPolicy if none was set.
+func (s *Service) GetPolicy(ctx context.Context, tenantID string) (*Policy, error) {
+	p, err := s.repo.GetPolicy(ctx, tenantID)
+	if errors.Is(err, ErrPolicyNotFound) {
+		d := DefaultPolicy()
+		return &d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery policy: %w", err)
+	}
+	return p, nil
+}
+
+// SetPolicy configures recovery for a tenant.
+//
+// Purpose: Per-tenant choice of recovery methods, delay, and step-up window.
+// Domain: Identity
+// Security: Caller must hold policy.PermTenantManageUsers in the tenant.
+// Audited: Yes (TenantUpdated)
+// Errors: ErrNotPermitted, ErrInvalidPolicy, System errors
+func (s *Service) SetPolicy(ctx context.Context, tenantID string, p Policy, actorID string) error {
+	if err := s.requireAdmin(ctx, actorID, tenantID); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if err := s.repo.SetPolicy(ctx, tenantID, &p); err != nil {
+		return fmt.Errorf("failed to set recovery policy: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTenantUpdated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceTenant,
+		TargetID: tenantID,
+		Metadata: map[string]any{
+			"recovery_delayed":           p.DelayedEnabled,
+			attrDelay:                    p.Delay.String(),
+			"recovery_admin_attestation": p.AdminAttestationEnabled,
+			"recovery_step_up_max_age":   p.StepUpMaxAge.String(),
+		},
+	})
+	return nil
+}
+
+// StartRecovery opens a recovery request for userID and returns it with its
+// one-time recovery token.
+//
+// Purpose: Entry point for a user who can no longer authenticate.
+// Domain: Identity
+// Security: Transports call this after resolving the account, and must answer
+// identically whether or not the account exists. The token is handed to the
+// requester only; the account owner is notified through the RecoveryStarted
+// event so they can cancel a request they did not make.
+// Audited: Yes (RecoveryRequested)
+// Errors: ErrMethodDisabled, ErrRecoveryPending, System errors
+func (s *Service) StartRecovery(ctx context.Context, tenantID, userID string, method Method, reason string) (*Request, string, error) {
+	p, err := s.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !p.Enabled(method) {
+		return nil, "", ErrMethodDisabled
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate recovery token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	r := &Request{
+		ID:        id.NewUUIDv7(),
+		TenantID:  tenantID,
+		UserID:    userID,
+		Method:    method,
+		Status:    StatusPending,
+		Reason:    reason,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	switch method {
+	case MethodDelayed:
+		r.AvailableAt = now.Add(p.Delay)
+		r.ExpiresAt = r.AvailableAt.Add(CompletionWindow)
+	case MethodAdminAttested:
+		r.AvailableAt = now
+		r.ExpiresAt = now.Add(AttestationWindow)
+	}
+
+	if err := s.repo.Create(ctx, r); err != nil {
+		if errors.Is(err, ErrRecoveryPending) {
+			return nil, "", err
+		}
+		return nil, "", fmt.Errorf("failed to create recovery request: %w", err)
+	}
+
+	s.log(ctx, audit.TypeRecoveryRequested, userID, r, map[string]any{
+		attrMethod:       string(method),
+		attrAvailableAt:  r.AvailableAt,
+		audit.AttrReason: reason,
+	})
+	events.Emit(ctx, s.events, events.RecoveryStarted{
+		Meta:        events.NewMeta(tenantID, userID),
+		UserID:      userID,
+		RequestID:   r.ID,
+		Method:      string(method),
+		AvailableAt: r.AvailableAt,
+	})
+	return r, token, nil
+}
+
+// ListOpen returns the tenant's pending and approved recovery requests.
+//
+// Purpose: Queue of requests awaiting administrator attestation or their delay.
+// Domain: Identity
+// Security: Caller must hold policy.PermTenantManageUsers in the tenant.
+// Audited: No
+// Errors: ErrNotPermitted, System errors
+func (s *Service) ListOpen(ctx context.Context, tenantID, actorID string) ([]*Request, error) {
+	if err := s.requireAdmin(ctx, actorID, tenantID); err != nil {
+		return nil, err
+	}
+	reqs, err := s.repo.ListOpen(ctx, tenantID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recovery requests: %w", err)
+	}
+	return reqs, nil
+}
+
+// CancelRecovery cancels an open recovery request.
+//
+// Purpose: Lets the account owner stop a recovery they did not request, or an
+// administrator reject one.
+// Domain: Identity
+// Security: Self-service or policy.PermTenantManageUsers.
+// Audited: Yes (RecoveryCancelled)
+// Errors: ErrNotPermitted, ErrRequestNotFound, ErrRequestClosed, System errors
+func (s *Service) CancelRecovery(ctx context.Context, tenantID, requestID, actorID string) error {
+	r, err := s.repo.Get(ctx, tenantID, requestID)
+	if err != nil {
+		return err
+	}
+	if actorID == "" || actorID != r.UserID {
+		if err := s.requireAdmin(ctx, actorID, tenantID); err != nil {
+			return err
+		}
+	}
+	if !r.IsOpen(time.Now()) {
+		return ErrRequestClosed
+	}
+
+	from := r.Status
+	r.Status = StatusCancelled
+	r.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, r, from); err != nil {
+		return err
+	}
+
+	s.log(ctx, audit.TypeRecoveryCancelled, actorID, r, map[string]any{attrMethod: string(r.Method)})
+	return nil
+}
+
+// AttestRecovery approves an admin-attested recovery request.
+//
+// Purpose: A tenant administrator vouches for the requester's identity after
+// verifying it out of band.
+// Domain: Identity
+// Security: Caller must hold policy.PermTenantManageUsers, must not be the
+// recovering user, must give a justification, and must have re-authenticated
+// within Policy.StepUpMaxAge (authTime is the auth_time of the admin's session).
+// Audited: Yes (RecoveryAttested, with the justification)
+// Errors: ErrNotPermitted, ErrSelfAttestation, ErrJustificationRequired,
+// ErrStepUpRequired, ErrMethodDisabled, ErrRequestNotFound, ErrRequestClosed, System errors
+func (s *Service) AttestRecovery(ctx context.Context, tenantID, requestID, actorID string, authTime time.Time, justification string) error {
+	r, err := s.repo.Get(ctx, tenantID, requestID)
+	if err != nil {
+		return err
+	}
+	if actorID == r.UserID {
+		return ErrSelfAttestation
+	}
+	if err := s.requireAdmin(ctx, actorID, tenantID); err != nil {
+		return err
+	}
+	justification = strings.TrimSpace(justification)
+	if justification == "" || len(justification) > maxJustificationLength {
+		return ErrJustificationRequired
+	}
+
+	p, err := s.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if !p.AdminAttestationEnabled || r.Method != MethodAdminAttested {
+		return ErrMethodDisabled
+	}
+	now := time.Now()
+	if authTime.IsZero() || now.Sub(authTime) > p.StepUpMaxAge {
+		return ErrStepUpRequired
+	}
+	if r.Status != StatusPending || !r.IsOpen(now) {
+		return ErrRequestClosed
+	}
+
+	r.Status = StatusApproved
+	r.ApprovedBy = actorID
+	r.ApprovedAt = &now
+	r.Justification = justification
+	r.ExpiresAt = now.Add(CompletionWindow)
+	r.UpdatedAt = now
+	if err := s.repo.Update(ctx, r, StatusPending); err != nil {
+		return err
+	}
+
+	s.log(ctx, audit.TypeRecoveryAttested, actorID, r, map[string]any{
+		attrMethod:        string(r.Method),
+		attrJustification: justification,
+	})
+	return nil
+}
+
+// CompleteRecovery sets a new password on a ready request and closes it.
+//
+// Purpose: Final step of recovery; the user regains access with a fresh password.
+// Domain: Identity
+// Security: The token is compared in constant time. Every existing session and
+// token of the user is revoked. Second factors are not touched here; hosts
+// handle UserRecovered by requiring MFA re-enrollment.
+// Audited: Yes (RecoveryCompleted)
+// Errors: ErrInvalidToken, ErrNotReady, ErrRequestClosed, user.ErrWeakPassword, System errors
+func (s *Service) CompleteRecovery(ctx context.Context, tenantID, requestID, token, newPassword string) error {
+	ctx, span := tracing.Start(ctx, s.tracer, "recovery.CompleteRecovery", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	r, err := s.repo.Get(ctx, tenantID, requestID)
+	if err != nil {
+		if errors.Is(err, ErrRequestNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	if !crypto.ConstantTimeEqualString(hashToken(token), r.TokenHash) {
+		return ErrInvalidToken
+	}
+	now := time.Now()
+	if !r.IsOpen(now) {
+		return ErrRequestClosed
+	}
+	if !r.IsReady(now) {
+		return ErrNotReady
+	}
+
+	if err := s.users.SetPassword(ctx, r.UserID, newPassword); err != nil {
+		return err
+	}
+
+	from := r.Status
+	r.Status = StatusCompleted
+	r.UpdatedAt = now
+	if err := s.repo.Update(ctx, r, from); err != nil {
+		return err
+	}
+	if s.revoker != nil {
+		if err := s.revoker.RevokeUserCredentials(ctx, r.UserID); err != nil {
+			return fmt.Errorf("failed to revoke credentials: %w", err)
+		}
+	}
+
+	s.log(ctx, audit.TypeRecoveryCompleted, r.UserID, r, map[string]any{attrMethod: string(r.Method)})
+	events.Emit(ctx, s.events, events.UserRecovered{
+		Meta:      events.NewMeta(tenantID, r.UserID),
+		UserID:    r.UserID,
+		RequestID: r.ID,
+		Method:    string(r.Method),
+	})
+	return nil
+}
+
+// CleanupExpired removes expired recovery requests
+func (s *Service) CleanupExpired(ctx context.Context) error {
+	return s.repo.DeleteExpired(ctx, time.Now())
+}
+
+func (s *Service) requireAdmin(ctx context.Context, actorID, tenantID string) error {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	if actorID == "" || s.permissions == nil {
+		return ErrNotPermitted
+	}
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopeTenant, &tenantID, policy.PermTenantManageUsers)
+	if err != nil {
+		return fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !ok {
+		return ErrNotPermitted
+	}
+	return nil
+}
+
+func (s *Service) log(ctx context.Context, typ, actorID string, r *Request, metadata map[string]any) {
+	metadata["user_id"] = r.UserID
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     typ,
+		TenantID: r.TenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceRecovery,
+		TargetID: r.ID,
+		Metadata: metadata,
+	})
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
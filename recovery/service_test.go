@@ -0,0 +1,278 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type mockRepo struct {
+	requests map[string]*Request
+	policies map[string]*Policy
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{requests: map[string]*Request{}, policies: map[string]*Policy{}}
+}
+
+func (m *mockRepo) Create(ctx context.Context, r *Request) error {
+	for _, existing := range m.requests {
+		if existing.TenantID == r.TenantID && existing.UserID == r.UserID && existing.IsOpen(r.CreatedAt) {
+			return ErrRecoveryPending
+		}
+	}
+	c := *r
+	m.requests[r.ID] = &c
+	return nil
+}
+
+func (m *mockRepo) Get(ctx context.Context, tenantID, id string) (*Request, error) {
+	r, ok := m.requests[id]
+	if !ok || r.TenantID != tenantID {
+		return nil, ErrRequestNotFound
+	}
+	c := *r
+	return &c, nil
+}
+
+func (m *mockRepo) ListOpen(ctx context.Context, tenantID string, now time.Time) ([]*Request, error) {
+	var out []*Request
+	for _, r := range m.requests {
+		if r.TenantID == tenantID && r.IsOpen(now) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockRepo) Update(ctx context.Context, r *Request, from Status) error {
+	stored, ok := m.requests[r.ID]
+	if !ok || stored.Status != from {
+		return ErrRequestClosed
+	}
+	c := *r
+	m.requests[r.ID] = &c
+	return nil
+}
+
+func (m *mockRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	for id, r := range m.requests {
+		if r.ExpiresAt.Before(now) {
+			delete(m.requests, id)
+		}
+	}
+	return nil
+}
+
+func (m *mockRepo) GetPolicy(ctx context.Context, tenantID string) (*Policy, error) {
+	p, ok := m.policies[tenantID]
+	if !ok {
+		return nil, ErrPolicyNotFound
+	}
+	return p, nil
+}
+
+func (m *mockRepo) SetPolicy(ctx context.Context, tenantID string, p *Policy) error {
+	m.policies[tenantID] = p
+	return nil
+}
+
+type mockUsers struct {
+	passwords map[string]string
+}
+
+func (m *mockUsers) SetPassword(ctx context.Context, userID, password string) error {
+	if len(password) < 8 {
+		return errors.New("weak password")
+	}
+	m.passwords[userID] = password
+	return nil
+}
+
+type mockRevoker struct {
+	revoked []string
+}
+
+func (m *mockRevoker) RevokeUserCredentials(ctx context.Context, userID string) error {
+	m.revoked = append(m.revoked, userID)
+	return nil
+}
+
+type mockPermissions struct {
+	admins map[string]bool
+}
+
+func (m *mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return m.admins[userID], nil
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+func (m *mockAuditLogger) has(typ string) bool {
+	for _, e := range m.events {
+		if e.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAccountRecovery(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "t1"
+
+	setup := func(p Policy) (*Service, *mockRepo, *mockUsers, *mockRevoker, *mockAuditLogger) {
+		repo := newMockRepo()
+		repo.policies[tenantID] = &p
+		users := &mockUsers{passwords: map[string]string{}}
+		revoker := &mockRevoker{}
+		logger := &mockAuditLogger{}
+		perms := &mockPermissions{admins: map[string]bool{"admin": true, "victim-admin": true}}
+		return NewService(repo, users, perms, logger, WithCredentialRevoker(revoker)), repo, users, revoker, logger
+	}
+	attested := DefaultPolicy()
+	attested.AdminAttestationEnabled = true
+
+	t.Run("delayed recovery waits for the delay", func(t *testing.T) {
+		svc, repo, users, revoker, logger := setup(DefaultPolicy())
+		r, token, err := svc.StartRecovery(ctx, tenantID, "u1", MethodDelayed, "lost phone")
+		if err != nil {
+			t.Fatalf("StartRecovery: %v", err)
+		}
+		if token == "" || r.TokenHash == token {
+			t.Fatal("token must be returned and stored hashed")
+		}
+		if _, _, err := svc.StartRecovery(ctx, tenantID, "u1", MethodDelayed, ""); !errors.Is(err, ErrRecoveryPending) {
+			t.Errorf("second request err = %v, want ErrRecoveryPending", err)
+		}
+		if err := svc.CompleteRecovery(ctx, tenantID, r.ID, token, "N3w-Passw0rd!"); !errors.Is(err, ErrNotReady) {
+			t.Fatalf("early completion err = %v, want ErrNotReady", err)
+		}
+
+		repo.requests[r.ID].AvailableAt = time.Now().Add(-time.Minute)
+		if err := svc.CompleteRecovery(ctx, tenantID, r.ID, "wrong", "N3w-Passw0rd!"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("wrong token err = %v, want ErrInvalidToken", err)
+		}
+		if err := svc.CompleteRecovery(ctx, tenantID, r.ID, token, "N3w-Passw0rd!"); err != nil {
+			t.Fatalf("CompleteRecovery: %v", err)
+		}
+		if users.passwords["u1"] != "N3w-Passw0rd!" || len(revoker.revoked) != 1 {
+			t.Error("completion must set the password and revoke credentials")
+		}
+		if err := svc.CompleteRecovery(ctx, tenantID, r.ID, token, "An0ther-Pass!"); !errors.Is(err, ErrRequestClosed) {
+			t.Errorf("reuse err = %v, want ErrRequestClosed", err)
+		}
+		if !logger.has(audit.TypeRecoveryRequested) || !logger.has(audit.TypeRecoveryCompleted) {
+			t.Error("expected requested and completed audit events")
+		}
+	})
+
+	t.Run("owner cancels", func(t *testing.T) {
+		svc, _, _, _, logger := setup(DefaultPolicy())
+		r, _, _ := svc.StartRecovery(ctx, tenantID, "u1", MethodDelayed, "")
+		if err := svc.CancelRecovery(ctx, tenantID, r.ID, "stranger"); !errors.Is(err, ErrNotPermitted) {
+			t.Errorf("stranger cancel err = %v, want ErrNotPermitted", err)
+		}
+		if err := svc.CancelRecovery(ctx, tenantID, r.ID, "u1"); err != nil {
+			t.Fatalf("CancelRecovery: %v", err)
+		}
+		if err := svc.CancelRecovery(ctx, tenantID, r.ID, "u1"); !errors.Is(err, ErrRequestClosed) {
+			t.Errorf("second cancel err = %v, want ErrRequestClosed", err)
+		}
+		if !logger.has(audit.TypeRecoveryCancelled) {
+			t.Error("expected cancellation audit event")
+		}
+	})
+
+	t.Run("method disabled", func(t *testing.T) {
+		svc, _, _, _, _ := setup(DefaultPolicy())
+		if _, _, err := svc.StartRecovery(ctx, tenantID, "u1", MethodAdminAttested, ""); !errors.Is(err, ErrMethodDisabled) {
+			t.Errorf("err = %v, want ErrMethodDisabled", err)
+		}
+	})
+
+	t.Run("admin attestation", func(t *testing.T) {
+		now := time.Now()
+		tests := []struct {
+			name          string
+			actor         string
+			authTime      time.Time
+			justification string
+			wantErr       error
+		}{
+			{"not an admin", "u2", now, "verified by video call", ErrNotPermitted},
+			{"self attestation", "victim-admin", now, "verified by video call", ErrSelfAttestation},
+			{"missing justification", "admin", now, "  ", ErrJustificationRequired},
+			{"stale authentication", "admin", now.Add(-time.Hour), "verified by video call", ErrStepUpRequired},
+			{"attested", "admin", now, "verified by video call", nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				svc, _, users, _, logger := setup(attested)
+				r, token, err := svc.StartRecovery(ctx, tenantID, "victim-admin", MethodAdminAttested, "")
+				if err != nil {
+					t.Fatalf("StartRecovery: %v", err)
+				}
+				err = svc.AttestRecovery(ctx, tenantID, r.ID, tt.actor, tt.authTime, tt.justification)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AttestRecovery err = %v, want %v", err, tt.wantErr)
+				}
+				completeErr := svc.CompleteRecovery(ctx, tenantID, r.ID, token, "N3w-Passw0rd!")
+				if tt.wantErr != nil {
+					if !errors.Is(completeErr, ErrNotReady) {
+						t.Errorf("unattested completion err = %v, want ErrNotReady", completeErr)
+					}
+					return
+				}
+				if completeErr != nil {
+					t.Fatalf("CompleteRecovery: %v", completeErr)
+				}
+				if users.passwords["victim-admin"] == "" || !logger.has(audit.TypeRecoveryAttested) {
+					t.Error("expected password set and attestation audited")
+				}
+			})
+		}
+	})
+
+	t.Run("policy validation", func(t *testing.T) {
+		svc, repo, _, _, _ := setup(DefaultPolicy())
+		bad := DefaultPolicy()
+		bad.Delay = time.Hour
+		if err := svc.SetPolicy(ctx, tenantID, bad, "admin"); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("err = %v, want ErrInvalidPolicy", err)
+		}
+		if err := svc.SetPolicy(ctx, tenantID, attested, "u2"); !errors.Is(err, ErrNotPermitted) {
+			t.Errorf("err = %v, want ErrNotPermitted", err)
+		}
+		if err := svc.SetPolicy(ctx, tenantID, attested, "admin"); err != nil {
+			t.Fatalf("SetPolicy: %v", err)
+		}
+		if !repo.policies[tenantID].AdminAttestationEnabled {
+			t.Error("policy not stored")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reporting provides platform-wide usage reports across tenants.
+// Login and token activity is counted from domain events into daily
+// per-tenant rows, and member totals are snapshotted, by a scheduler job;
+// reports only ever read those pre-aggregated rows.
+package reporting
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrNotPermitted  = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to view platform reports")
+	ErrInvalidRange  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid report time range")
+	ErrInvalidBucket = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid report granularity")
+)
+
+// Bucket is the width of one report data point.
+type Bucket string
+
+// Buckets
+const (
+	BucketDay   Bucket = "day"
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
+
+// MaxRange is the longest time range one report may cover
+const MaxRange = 366 * 24 * time.Hour
+
+// Counters are activity deltas for one tenant and day.
+type Counters struct {
+	Logins        int64
+	LoginFailures int64
+	TokensIssued  int64
+}
+
+// Point is one bucket of a tenant's report.
+//
+// Purpose: Growth and activity figures over one time bucket.
+// Domain: Platform (Reporting)
+// Invariants: Members is the latest member total snapshotted within the bucket.
+type Point struct {
+	Start         time.Time `json:"start"`
+	Members       int64     `json:"members"`
+	NewMembers    int64     `json:"new_members"`
+	Logins        int64     `json:"logins"`
+	LoginFailures int64     `json:"login_failures"`
+	TokensIssued  int64     `json:"tokens_issued"`
+}
+
+// ErrorRate returns the share of login attempts that failed, or 0 without attempts.
+func (p *Point) ErrorRate() float64 {
+	total := p.Logins + p.LoginFailures
+	if total == 0 {
+		return 0
+	}
+	return float64(p.LoginFailures) / float64(total)
+}
+
+// Series is one tenant's report.
+type Series struct {
+	TenantID string   `json:"tenant_id"`
+	Points   []*Point `json:"points"`
+}
+
+// Query selects report data.
+//
+// Purpose: Report parameters validated by the service before reaching the store.
+// Domain: Platform (Reporting)
+// Invariants: From is before To and the range is at most MaxRange. An empty TenantID selects every tenant.
+type Query struct {
+	TenantID string
+	From     time.Time
+	To       time.Time
+	Bucket   Bucket
+}
+
+// Repository defines persistence for the pre-aggregated daily statistics.
+//
+// Purpose: Durable, instance-shared report tables.
+// Domain: Platform (Reporting)
+type Repository interface {
+	// AddCounters increments the counters of tenantID on day
+	AddCounters(ctx context.Context, tenantID string, day time.Time, c Counters) error
+	// SnapshotMembers records every tenant's member total and new members on day
+	SnapshotMembers(ctx context.Context, day time.Time) error
+	// Series returns per-tenant points for q, ordered by tenant and bucket start
+	Series(ctx context.Context, q Query) ([]*Series, error)
+}
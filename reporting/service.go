@@ -0,0 +1,190 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+type dayKey struct {
+	tenantID string
+	day      time.Time
+}
+
+// Service maintains and serves platform reports.
+//
+// Purpose: Cross-tenant growth and activity reporting for platform admins.
+// Domain: Platform (Reporting)
+// Invariants: Activity is buffered in memory and flushed in batches; deltas that
+// fail to flush are kept and retried. Untenanted activity is not reported.
+type Service struct {
+	repo        Repository
+	permissions PermissionChecker
+	tracer      tracing.Tracer
+	mu          sync.Mutex
+	pending     map[dayKey]Counters
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithTracer emits spans for report queries on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new reporting service.
+//
+// Purpose: Constructor for the platform reporting service.
+// Domain: Platform (Reporting)
+// Audited: No
+// Errors: None
+func NewService(repo Repository, permissions PermissionChecker, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		permissions: permissions,
+		pending:     make(map[dayKey]Counters),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// HandleEvent counts logins, failed logins, and issued access tokens; it is an events.Handler.
+func (s *Service) HandleEvent(_ context.Context, e events.Event) error {
+	var c Counters
+	switch ev := e.(type) {
+	case events.SessionCreated:
+		c.Logins = 1
+	case events.LoginFailed:
+		c.LoginFailures = 1
+	case events.TokenIssued:
+		if ev.Kind != events.TokenKindAccess {
+			return nil
+		}
+		c.TokensIssued = 1
+	default:
+		return nil
+	}
+	if e.EventTenantID() == "" {
+		return nil
+	}
+	s.record(dayKey{e.EventTenantID(), day(e.EventTime())}, c)
+	return nil
+}
+
+// Flush writes buffered activity and snapshots today's member totals.
+//
+// Purpose: Scheduler job that maintains the pre-aggregated report tables.
+// Domain: Platform (Reporting)
+// Audited: No
+// Errors: Joined repository errors (failed deltas are re-buffered)
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[dayKey]Counters)
+	s.mu.Unlock()
+
+	var errs []error
+	for k, c := range batch {
+		if err := s.repo.AddCounters(ctx, k.tenantID, k.day, c); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record activity of tenant %s: %w", k.tenantID, err))
+			s.record(k, c)
+		}
+	}
+	if err := s.repo.SnapshotMembers(ctx, day(time.Now())); err != nil {
+		errs = append(errs, fmt.Errorf("failed to snapshot members: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// Report returns per-tenant growth and activity over q.
+//
+// Purpose: Platform admin reporting across tenants.
+// Domain: Platform (Reporting)
+// Security: Requires policy.PermPlatformViewAudit at platform scope.
+// Audited: No
+// Errors: ErrNotPermitted, ErrInvalidRange, ErrInvalidBucket, System errors
+func (s *Service) Report(ctx context.Context, actorID string, q Query) ([]*Series, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "reporting.Report")
+	defer span.End()
+
+	if err := s.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+	switch q.Bucket {
+	case "":
+		q.Bucket = BucketDay
+	case BucketDay, BucketWeek, BucketMonth:
+	default:
+		return nil, ErrInvalidBucket
+	}
+	if !q.From.Before(q.To) || q.To.Sub(q.From) > MaxRange {
+		return nil, ErrInvalidRange
+	}
+
+	series, err := s.repo.Series(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report: %w", err)
+	}
+	return series, nil
+}
+
+func (s *Service) authorize(ctx context.Context, actorID string) error {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	if actorID == "" || s.permissions == nil {
+		return ErrNotPermitted
+	}
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopePlatform, nil, policy.PermPlatformViewAudit)
+	if err != nil {
+		return fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !ok {
+		return ErrNotPermitted
+	}
+	return nil
+}
+
+func (s *Service) record(k dayKey, c Counters) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur := s.pending[k]
+	cur.Logins += c.Logins
+	cur.LoginFailures += c.LoginFailures
+	cur.TokensIssued += c.TokensIssued
+	s.pending[k] = cur
+}
+
+// day truncates t to its UTC calendar day.
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type mockRepo struct {
+	counters  map[dayKey]Counters
+	snapshots int
+	failAdd   bool
+	query     Query
+}
+
+func (m *mockRepo) AddCounters(ctx context.Context, tenantID string, day time.Time, c Counters) error {
+	if m.failAdd {
+		return errors.New("db down")
+	}
+	k := dayKey{tenantID, day}
+	cur := m.counters[k]
+	cur.Logins += c.Logins
+	cur.LoginFailures += c.LoginFailures
+	cur.TokensIssued += c.TokensIssued
+	m.counters[k] = cur
+	return nil
+}
+
+func (m *mockRepo) SnapshotMembers(ctx context.Context, day time.Time) error {
+	m.snapshots++
+	return nil
+}
+
+func (m *mockRepo) Series(ctx context.Context, q Query) ([]*Series, error) {
+	m.query = q
+	return []*Series{{TenantID: "t1"}}, nil
+}
+
+type mockPermissions map[string]bool
+
+func (m mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return scope == role.ScopePlatform && permission == policy.PermPlatformViewAudit && m[userID], nil
+}
+
+func TestFlushAggregatesEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{counters: map[dayKey]Counters{}}
+	svc := NewService(repo, mockPermissions{})
+
+	at := time.Date(2026, 3, 4, 23, 30, 0, 0, time.UTC)
+	meta := events.Meta{TenantID: "t1", OccurredAt: at}
+	for _, e := range []events.Event{
+		events.SessionCreated{Meta: meta, UserID: "u1"},
+		events.SessionCreated{Meta: meta, UserID: "u2"},
+		events.LoginFailed{Meta: meta, Code: "invalid_credentials"},
+		events.TokenIssued{Meta: meta, Kind: events.TokenKindAccess},
+		events.TokenIssued{Meta: meta, Kind: events.TokenKindRefresh},
+		events.SessionCreated{Meta: events.Meta{OccurredAt: at}, UserID: "u3"},
+		events.UserUpdated{Meta: meta, UserID: "u1"},
+	} {
+		_ = svc.HandleEvent(ctx, e)
+	}
+
+	repo.failAdd = true
+	if err := svc.Flush(ctx); err == nil {
+		t.Fatal("expected flush error")
+	}
+	repo.failAdd = false
+	if err := svc.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	got := repo.counters[dayKey{"t1", time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}]
+	want := Counters{Logins: 2, LoginFailures: 1, TokensIssued: 1}
+	if got != want || len(repo.counters) != 1 {
+		t.Errorf("counters = %+v (%d rows), want %+v", got, len(repo.counters), want)
+	}
+	if repo.snapshots != 2 {
+		t.Errorf("snapshots = %d, want 2", repo.snapshots)
+	}
+}
+
+func TestReport(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		actor   string
+		query   Query
+		wantErr error
+	}{
+		{"platform auditor", "auditor", Query{From: now.Add(-48 * time.Hour), To: now}, nil},
+		{"not permitted", "tenant-admin", Query{From: now.Add(-time.Hour), To: now}, ErrNotPermitted},
+		{"inverted range", "auditor", Query{From: now, To: now.Add(-time.Hour)}, ErrInvalidRange},
+		{"range too long", "auditor", Query{From: now.Add(-2 * MaxRange), To: now}, ErrInvalidRange},
+		{"unknown bucket", "auditor", Query{From: now.Add(-time.Hour), To: now, Bucket: "year"}, ErrInvalidBucket},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{}
+			svc := NewService(repo, mockPermissions{"auditor": true})
+			_, err := svc.Report(context.Background(), tt.actor, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Report() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && repo.query.Bucket != BucketDay {
+				t.Errorf("bucket = %q, want default %q", repo.query.Bucket, BucketDay)
+			}
+		})
+	}
+}
+
+func TestPointErrorRate(t *testing.T) {
+	if r := (&Point{}).ErrorRate(); r != 0 {
+		t.Errorf("empty ErrorRate() = %v, want 0", r)
+	}
+	if r := (&Point{Logins: 3, LoginFailures: 1}).ErrorRate(); r != 0.25 {
+		t.Errorf("ErrorRate() = %v, want 0.25", r)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package requestctx carries the facts transports establish about a request:
+// who is acting, in which tenant and session, and from where. Transport middleware sets
+// them once; audit logging and role attribution read them, so services need not
+// thread actor IDs, addresses, and user agents through every call.
+//
+// Values set explicitly by a caller always win over the context. The package
+// has no dependencies so any core package may import it.
+package requestctx
+
+import "context"
+
+// ActorType classifies who is acting.
+type ActorType string
+
+// Actor types
+const (
+	// ActorUser is an authenticated end user or administrator.
+	ActorUser ActorType = "user"
+	// ActorClient is an OAuth2 client acting on its own behalf (client credentials).
+	ActorClient ActorType = "client"
+	// ActorSystem is core itself: scheduled jobs, bootstrap, and maintenance.
+	ActorSystem ActorType = "system"
+)
+
+// Actor identifies who is acting.
+//
+// Purpose: Authenticated principal of a request.
+// Domain: Platform
+// Invariants: ID is a user ID for ActorUser and a client_id for ActorClient; it may be
+// empty for ActorSystem.
+type Actor struct {
+	ID   string
+	Type ActorType
+}
+
+type actorKey struct{}
+
+type tenantKey struct{}
+
+type sessionKey struct{}
+
+type clientInfoKey struct{}
+
+type clientInfo struct {
+	ipAddress string
+	userAgent string
+}
+
+// WithActor returns ctx carrying the authenticated actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// WithUser returns ctx acting as the user userID.
+func WithUser(ctx context.Context, userID string) context.Context {
+	return WithActor(ctx, Actor{ID: userID, Type: ActorUser})
+}
+
+// WithClient returns ctx acting as the OAuth2 client clientID.
+func WithClient(ctx context.Context, clientID string) context.Context {
+	return WithActor(ctx, Actor{ID: clientID, Type: ActorClient})
+}
+
+// WithSystem returns ctx acting as core itself.
+func WithSystem(ctx context.Context) context.Context {
+	return WithActor(ctx, Actor{Type: ActorSystem})
+}
+
+// ActorFrom returns the actor in ctx and whether one is set.
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	a, ok := ctx.Value(actorKey{}).(Actor)
+	return a, ok
+}
+
+// ActorID returns the ID of the actor in ctx, or "".
+func ActorID(ctx context.Context) string {
+	a, _ := ActorFrom(ctx)
+	return a.ID
+}
+
+// UserID returns the ID of the acting user, or "" when the actor is not a user.
+func UserID(ctx context.Context) string {
+	if a, _ := ActorFrom(ctx); a.Type == ActorUser {
+		return a.ID
+	}
+	return ""
+}
+
+// ResolveActorID returns actorID if set, otherwise the ID of the actor in ctx.
+//
+// Purpose: Lets services that still take an actorID parameter accept "" from
+// callers that put the actor on the context.
+// Domain: Platform
+// Security: An explicit actorID wins; the context never overrides a caller's choice.
+// Audited: No
+// Errors: None
+func ResolveActorID(ctx context.Context, actorID string) string {
+	if actorID != "" {
+		return actorID
+	}
+	return ActorID(ctx)
+}
+
+// ResolveUserID returns userID if set, otherwise the acting user in ctx.
+//
+// Purpose: Resolves the subject of a permission check. Only users hold roles, so a
+// client or system actor on the context resolves to "" and is denied.
+// Domain: Platform
+// Security: An explicit userID wins; the context never overrides a caller's choice.
+// Audited: No
+// Errors: None
+func ResolveUserID(ctx context.Context, userID string) string {
+	if userID != "" {
+		return userID
+	}
+	return UserID(ctx)
+}
+
+// WithTenant returns ctx scoped to the tenant tenantID.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantID returns the tenant in ctx, or "" for platform requests.
+func TenantID(ctx context.Context) string {
+	v, _ := ctx.Value(tenantKey{}).(string)
+	return v
+}
+
+// WithSession returns ctx carrying the ID of the browser session the request was made from.
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// SessionID returns the session the request was made from, or "".
+func SessionID(ctx context.Context) string {
+	v, _ := ctx.Value(sessionKey{}).(string)
+	return v
+}
+
+// WithClientInfo returns ctx carrying the caller's network address and user agent.
+func WithClientInfo(ctx context.Context, ipAddress, userAgent string) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, clientInfo{ipAddress: ipAddress, userAgent: userAgent})
+}
+
+// IPAddress returns the caller's network address in ctx, or "".
+func IPAddress(ctx context.Context) string {
+	v, _ := ctx.Value(clientInfoKey{}).(clientInfo)
+	return v.ipAddress
+}
+
+// UserAgent returns the caller's user agent in ctx, or "".
+func UserAgent(ctx context.Context) string {
+	v, _ := ctx.Value(clientInfoKey{}).(clientInfo)
+	return v.userAgent
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requestctx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestActor(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		explicit     string
+		wantActor    string
+		wantUser     string
+		wantResolved string
+	}{
+		{"empty", context.Background(), "", "", "", ""},
+		{"user", WithUser(context.Background(), "u1"), "", "u1", "u1", "u1"},
+		{"client", WithClient(context.Background(), "app"), "", "app", "", ""},
+		{"system", WithSystem(context.Background()), "", "", "", ""},
+		{"explicit wins", WithUser(context.Background(), "u1"), "admin", "u1", "u1", "admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ActorID(tt.ctx); got != tt.wantActor {
+				t.Errorf("ActorID() = %q, want %q", got, tt.wantActor)
+			}
+			if got := UserID(tt.ctx); got != tt.wantUser {
+				t.Errorf("UserID() = %q, want %q", got, tt.wantUser)
+			}
+			if got := ResolveUserID(tt.ctx, tt.explicit); got != tt.wantResolved {
+				t.Errorf("ResolveUserID() = %q, want %q", got, tt.wantResolved)
+			}
+			if tt.explicit != "" {
+				if got := ResolveActorID(tt.ctx, tt.explicit); got != tt.explicit {
+					t.Errorf("ResolveActorID() = %q, want %q", got, tt.explicit)
+				}
+			}
+		})
+	}
+
+	if a, ok := ActorFrom(WithSystem(context.Background())); !ok || a.Type != ActorSystem {
+		t.Errorf("ActorFrom() = %+v, %v; want a system actor", a, ok)
+	}
+	if _, ok := ActorFrom(context.Background()); ok {
+		t.Error("ActorFrom() reported an actor on an empty context")
+	}
+}
+
+func TestTenantAndClientInfo(t *testing.T) {
+	ctx := WithClientInfo(WithTenant(context.Background(), "t1"), "203.0.113.7", "agent/1.0")
+	ctx = WithSession(ctx, "sess-1")
+	if TenantID(ctx) != "t1" || IPAddress(ctx) != "203.0.113.7" || UserAgent(ctx) != "agent/1.0" || SessionID(ctx) != "sess-1" {
+		t.Errorf("request facts = %q, %q, %q, %q", TenantID(ctx), IPAddress(ctx), UserAgent(ctx), SessionID(ctx))
+	}
+	if TenantID(context.Background()) != "" || IPAddress(context.Background()) != "" || SessionID(context.Background()) != "" {
+		t.Error("empty context reported request facts")
+	}
+}
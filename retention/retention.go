@@ -0,0 +1,161 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retention decides how long each category of record is kept and
+// purges what has aged out. Deployment defaults apply to every tenant unless
+// the tenant overrides a category within its bounds; records covered by an
+// active legal hold are never purged.
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrUnknownCategory  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "unknown retention category")
+	ErrOutOfBounds      = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "retention period is outside the allowed bounds")
+	ErrOverrideNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "retention override not found")
+	ErrHoldNotFound     = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "legal hold not found")
+	ErrInvalidHold      = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "legal hold requires a tenant and a reason")
+	ErrNotPermitted     = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to manage legal holds")
+)
+
+// Category names a class of purgeable records.
+type Category string
+
+// Categories
+const (
+	CategorySessions           Category = "sessions"
+	CategoryAccessTokens       Category = "access_tokens"
+	CategoryRefreshTokens      Category = "refresh_tokens"
+	CategoryAuthorizationCodes Category = "authorization_codes"
+	CategoryAuditEvents        Category = "audit_events"
+	CategoryLoginHistory       Category = "login_history"
+	CategoryWebhookDeliveries  Category = "webhook_deliveries"
+)
+
+// Definition describes a category.
+//
+// Purpose: Registry entry with a category's default and override bounds.
+// Domain: Platform (Retention)
+// Invariants: Min <= Default <= Max. For expiring records (sessions, tokens, codes)
+// the period counts from expiry, for everything else from creation.
+type Definition struct {
+	Category    Category
+	Description string
+	Default     time.Duration
+	Min         time.Duration
+	Max         time.Duration
+	// FromExpiry reports whether the period counts from the record's expiry.
+	FromExpiry bool
+}
+
+const day = 24 * time.Hour
+
+var definitions = []Definition{
+	{Category: CategorySessions, Description: "Expired sessions", Max: 90 * day, FromExpiry: true},
+	{Category: CategoryAccessTokens, Description: "Expired access tokens", Max: 90 * day, FromExpiry: true},
+	{Category: CategoryRefreshTokens, Description: "Expired refresh tokens", Max: 90 * day, FromExpiry: true},
+	{Category: CategoryAuthorizationCodes, Description: "Expired authorization codes", Max: 30 * day, FromExpiry: true},
+	{Category: CategoryAuditEvents, Description: "Audit events", Default: 365 * day, Min: 30 * day, Max: 10 * 365 * day},
+	// The default matches risk.DefaultPolicy().Retention.
+	{Category: CategoryLoginHistory, Description: "Login geography history", Default: 180 * day, Min: day, Max: 2 * 365 * day},
+	{Category: CategoryWebhookDeliveries, Description: "Finished webhook deliveries", Default: 30 * day, Min: day, Max: 365 * day},
+}
+
+// Definitions returns every category in purge order.
+func Definitions() []Definition {
+	out := make([]Definition, len(definitions))
+	copy(out, definitions)
+	return out
+}
+
+// Lookup returns the definition of c.
+func Lookup(c Category) (Definition, bool) {
+	for _, d := range definitions {
+		if d.Category == c {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Override is a tenant's retention period for one category.
+type Override struct {
+	TenantID  string        `json:"tenant_id"`
+	Category  Category      `json:"category"`
+	Period    time.Duration `json:"period"`
+	UpdatedBy string        `json:"updated_by"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// LegalHold suspends purging for a tenant or one of its users.
+//
+// Purpose: Preserves records under litigation or investigation.
+// Domain: Platform (Retention)
+// Invariants: TenantID and Reason are set. A hold with a UserID protects that
+// user's records in every tenant; without one it protects the whole tenant.
+// A hold is active until ReleasedAt is set.
+type LegalHold struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	UserID     string     `json:"user_id,omitempty"`
+	Reason     string     `json:"reason"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReleasedBy string     `json:"released_by,omitempty"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+// IsActive reports whether the hold is still in force.
+func (h *LegalHold) IsActive() bool {
+	return h.ReleasedAt == nil
+}
+
+// Scope narrows a purge.
+type Scope struct {
+	// TenantID limits the purge to one tenant; "" purges every tenant not excluded.
+	TenantID string
+	// ExcludeTenants are skipped entirely.
+	ExcludeTenants []string
+	// ExcludeUsers have their records skipped in every tenant.
+	ExcludeUsers []string
+}
+
+// Repository defines persistence for overrides and holds, and the purge itself.
+//
+// Purpose: Store-specific retention storage and deletion.
+// Domain: Platform (Retention)
+type Repository interface {
+	// Purge deletes records of category aged past cutoff within scope and returns how many it deleted
+	Purge(ctx context.Context, category Category, cutoff time.Time, scope Scope) (int64, error)
+
+	// ListOverrides returns every tenant override
+	ListOverrides(ctx context.Context) ([]*Override, error)
+	// SetOverride creates or replaces a tenant override
+	SetOverride(ctx context.Context, o *Override) error
+	// DeleteOverride removes a tenant override, or returns ErrOverrideNotFound
+	DeleteOverride(ctx context.Context, tenantID string, category Category) error
+
+	// CreateHold persists a new legal hold
+	CreateHold(ctx context.Context, h *LegalHold) error
+	// ReleaseHold marks an active hold released, or returns ErrHoldNotFound
+	ReleaseHold(ctx context.Context, id, releasedBy string, at time.Time) error
+	// ListActiveHolds returns every hold that has not been released
+	ListActiveHolds(ctx context.Context) ([]*LegalHold, error)
+}
@@ -0,0 +1,395 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// Audit metadata keys
+const (
+	attrCategory = "retention_category"
+	attrPeriod   = "retention_period"
+	attrUserID   = "user_id"
+)
+
+// Report is the outcome of one purge run.
+type Report struct {
+	StartedAt time.Time `json:"started_at"`
+	// Deleted counts purged records per category.
+	Deleted map[Category]int64 `json:"deleted"`
+	// HeldTenants and HeldUsers are the number of tenants and users skipped for legal holds.
+	HeldTenants int `json:"held_tenants"`
+	HeldUsers   int `json:"held_users"`
+}
+
+// Service is the retention policy engine and purge coordinator.
+//
+// Purpose: Single owner of record retention across categories.
+// Domain: Platform (Retention)
+// Invariants: Records of a tenant or user under an active legal hold are never purged.
+type Service struct {
+	repo        Repository
+	permissions PermissionChecker
+	auditLogger audit.Logger
+	defaults    map[Category]time.Duration
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithPeriod replaces the deployment default of category c with d.
+func WithPeriod(c Category, d time.Duration) Option {
+	return func(s *Service) { s.defaults[c] = d }
+}
+
+// WithTracer emits spans for purge runs on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new retention service.
+//
+// Purpose: Constructor for the retention engine.
+// Domain: Platform (Retention)
+// Audited: No
+// Errors: None
+func NewService(repo Repository, permissions PermissionChecker, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		permissions: permissions,
+		auditLogger: auditLogger,
+		defaults:    make(map[Category]time.Duration, len(definitions)),
+	}
+	for _, d := range definitions {
+		s.defaults[d.Category] = d.Default
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Period returns the retention period of category in tenantID, applying the tenant's override.
+func (s *Service) Period(ctx context.Context, tenantID string, category Category) (time.Duration, error) {
+	if _, ok := Lookup(category); !ok {
+		return 0, ErrUnknownCategory
+	}
+	overrides, err := s.repo.ListOverrides(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list retention overrides: %w", err)
+	}
+	for _, o := range overrides {
+		if o.TenantID == tenantID && o.Category == category {
+			return o.Period, nil
+		}
+	}
+	return s.defaults[category], nil
+}
+
+// SetOverride sets a tenant's retention period for one category.
+//
+// Purpose: Per-tenant retention configuration.
+// Domain: Platform (Retention)
+// Security: The period must lie within the category's bounds.
+// Audited: Yes (TenantUpdated)
+// Errors: ErrUnknownCategory, ErrOutOfBounds, System errors
+func (s *Service) SetOverride(ctx context.Context, tenantID string, category Category, period time.Duration, actorID string) error {
+	def, ok := Lookup(category)
+	if !ok {
+		return ErrUnknownCategory
+	}
+	if period < def.Min || period > def.Max {
+		return fmt.Errorf("%w: %s must be between %s and %s", ErrOutOfBounds, category, def.Min, def.Max)
+	}
+
+	o := &Override{TenantID: tenantID, Category: category, Period: period, UpdatedBy: actorID, UpdatedAt: time.Now()}
+	if err := s.repo.SetOverride(ctx, o); err != nil {
+		return fmt.Errorf("failed to set retention override: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTenantUpdated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceTenant,
+		TargetID: tenantID,
+		Metadata: map[string]any{attrCategory: string(category), attrPeriod: period.String()},
+	})
+	return nil
+}
+
+// RemoveOverride returns a tenant's category to the deployment default.
+//
+// Purpose: Undo a per-tenant retention override.
+// Domain: Platform (Retention)
+// Audited: Yes (TenantUpdated)
+// Errors: ErrUnknownCategory, ErrOverrideNotFound, System errors
+func (s *Service) RemoveOverride(ctx context.Context, tenantID string, category Category, actorID string) error {
+	if _, ok := Lookup(category); !ok {
+		return ErrUnknownCategory
+	}
+	if err := s.repo.DeleteOverride(ctx, tenantID, category); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTenantUpdated,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceTenant,
+		TargetID: tenantID,
+		Metadata: map[string]any{attrCategory: string(category), attrPeriod: "default"},
+	})
+	return nil
+}
+
+// PlaceHold suspends purging for a tenant, or for one user when userID is set.
+//
+// Purpose: Legal hold for litigation or investigation.
+// Domain: Platform (Retention)
+// Security: Requires PermPlatformManageTenants at platform scope.
+// Audited: Yes (LegalHoldPlaced)
+// Errors: ErrNotPermitted, ErrInvalidHold, System errors
+func (s *Service) PlaceHold(ctx context.Context, tenantID, userID, reason, actorID string) (*LegalHold, error) {
+	if err := s.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if tenantID == "" || reason == "" {
+		return nil, ErrInvalidHold
+	}
+
+	h := &LegalHold{
+		ID:        id.NewUUIDv7(),
+		TenantID:  tenantID,
+		UserID:    userID,
+		Reason:    reason,
+		CreatedBy: actorID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateHold(ctx, h); err != nil {
+		return nil, fmt.Errorf("failed to create legal hold: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeLegalHoldPlaced,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceLegalHold,
+		TargetID: h.ID,
+		Metadata: map[string]any{attrUserID: userID, audit.AttrReason: reason},
+	})
+	return h, nil
+}
+
+// ReleaseHold ends a legal hold; purging resumes on the next run.
+//
+// Purpose: Lift a legal hold once it is no longer required.
+// Domain: Platform (Retention)
+// Security: Requires PermPlatformManageTenants at platform scope.
+// Audited: Yes (LegalHoldReleased)
+// Errors: ErrNotPermitted, ErrHoldNotFound, System errors
+func (s *Service) ReleaseHold(ctx context.Context, holdID, actorID string) error {
+	if err := s.authorize(ctx, actorID); err != nil {
+		return err
+	}
+	holds, err := s.repo.ListActiveHolds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list legal holds: %w", err)
+	}
+	var hold *LegalHold
+	for _, h := range holds {
+		if h.ID == holdID {
+			hold = h
+		}
+	}
+	if hold == nil {
+		return ErrHoldNotFound
+	}
+	if err := s.repo.ReleaseHold(ctx, holdID, actorID, time.Now()); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeLegalHoldReleased,
+		TenantID: hold.TenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceLegalHold,
+		TargetID: holdID,
+		Metadata: map[string]any{attrUserID: hold.UserID},
+	})
+	return nil
+}
+
+// ListHolds returns every active legal hold.
+//
+// Purpose: Review of the holds currently in force.
+// Domain: Platform (Retention)
+// Security: Requires PermPlatformManageTenants at platform scope.
+// Audited: No
+// Errors: ErrNotPermitted, System errors
+func (s *Service) ListHolds(ctx context.Context, actorID string) ([]*LegalHold, error) {
+	if err := s.authorize(ctx, actorID); err != nil {
+		return nil, err
+	}
+	holds, err := s.repo.ListActiveHolds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legal holds: %w", err)
+	}
+	return holds, nil
+}
+
+// HeldIDs returns the tenants and users under an active legal hold. Jobs that
+// purge, anonymize, or finalize the deletion of records must skip them.
+func (s *Service) HeldIDs(ctx context.Context) (tenantIDs, userIDs []string, err error) {
+	holds, err := s.repo.ListActiveHolds(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list legal holds: %w", err)
+	}
+	tenants := map[string]bool{}
+	users := map[string]bool{}
+	for _, h := range holds {
+		if h.UserID != "" {
+			users[h.UserID] = true
+		} else {
+			tenants[h.TenantID] = true
+		}
+	}
+	return keys(tenants), keys(users), nil
+}
+
+// IsHeld reports whether records of userID in tenantID are under a legal hold.
+// Either ID may be empty.
+func (s *Service) IsHeld(ctx context.Context, tenantID, userID string) (bool, error) {
+	tenants, users, err := s.HeldIDs(ctx)
+	if err != nil {
+		return false, err
+	}
+	return (tenantID != "" && slices.Contains(tenants, tenantID)) ||
+		(userID != "" && slices.Contains(users, userID)), nil
+}
+
+// Purge deletes every record that has outlived its retention period.
+//
+// Purpose: The single purge coordinator for all categories.
+// Domain: Platform (Retention)
+// Security: Held tenants and users are excluded from every category. Tenants with an
+// override are purged separately with their own cutoff.
+// Audited: No
+// Errors: Joined per-category errors (other categories still run)
+func (s *Service) Purge(ctx context.Context) (*Report, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "retention.Purge")
+	defer span.End()
+
+	overrides, err := s.repo.ListOverrides(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention overrides: %w", err)
+	}
+	tenants, heldUsers, err := s.HeldIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	heldTenants := map[string]bool{}
+	for _, t := range tenants {
+		heldTenants[t] = true
+	}
+
+	now := time.Now()
+	report := &Report{StartedAt: now, Deleted: map[Category]int64{}, HeldTenants: len(heldTenants), HeldUsers: len(heldUsers)}
+	var errs []error
+	for _, def := range definitions {
+		exclude := keys(heldTenants)
+		var own []*Override
+		for _, o := range overrides {
+			if o.Category == def.Category {
+				exclude = append(exclude, o.TenantID)
+				if !heldTenants[o.TenantID] {
+					own = append(own, o)
+				}
+			}
+		}
+
+		n, err := s.repo.Purge(ctx, def.Category, now.Add(-s.defaults[def.Category]), Scope{ExcludeTenants: exclude, ExcludeUsers: heldUsers})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to purge %s: %w", def.Category, err))
+			continue
+		}
+		report.Deleted[def.Category] += n
+
+		for _, o := range own {
+			n, err := s.repo.Purge(ctx, def.Category, now.Add(-o.Period), Scope{TenantID: o.TenantID, ExcludeUsers: heldUsers})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to purge %s of tenant %s: %w", def.Category, o.TenantID, err))
+				continue
+			}
+			report.Deleted[def.Category] += n
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// Run is the scheduled purge job.
+func (s *Service) Run(ctx context.Context) error {
+	report, err := s.Purge(ctx)
+	if report != nil {
+		for c, n := range report.Deleted {
+			if n > 0 {
+				slog.InfoContext(ctx, "retention purge", "category", c, "deleted", n)
+			}
+		}
+	}
+	return err
+}
+
+// authorize requires the actor to manage tenants at platform scope.
+func (s *Service) authorize(ctx context.Context, actorID string) error {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopePlatform, nil, policy.PermPlatformManageTenants)
+	if err != nil {
+		return fmt.Errorf("failed to check permission: %w", err)
+	}
+	if !ok {
+		return ErrNotPermitted
+	}
+	return nil
+}
+
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,299 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type purgeCall struct {
+	category Category
+	cutoff   time.Time
+	scope    Scope
+}
+
+type mockRepo struct {
+	overrides []*Override
+	holds     []*LegalHold
+	purges    []purgeCall
+	failOn    Category
+}
+
+func (m *mockRepo) Purge(ctx context.Context, category Category, cutoff time.Time, scope Scope) (int64, error) {
+	if category == m.failOn {
+		return 0, errors.New("boom")
+	}
+	m.purges = append(m.purges, purgeCall{category, cutoff, scope})
+	return 1, nil
+}
+
+func (m *mockRepo) ListOverrides(ctx context.Context) ([]*Override, error) {
+	return m.overrides, nil
+}
+
+func (m *mockRepo) SetOverride(ctx context.Context, o *Override) error {
+	m.overrides = append(m.overrides, o)
+	return nil
+}
+
+func (m *mockRepo) DeleteOverride(ctx context.Context, tenantID string, category Category) error {
+	for i, o := range m.overrides {
+		if o.TenantID == tenantID && o.Category == category {
+			m.overrides = slices.Delete(m.overrides, i, i+1)
+			return nil
+		}
+	}
+	return ErrOverrideNotFound
+}
+
+func (m *mockRepo) CreateHold(ctx context.Context, h *LegalHold) error {
+	m.holds = append(m.holds, h)
+	return nil
+}
+
+func (m *mockRepo) ReleaseHold(ctx context.Context, id, releasedBy string, at time.Time) error {
+	for _, h := range m.holds {
+		if h.ID == id && h.IsActive() {
+			h.ReleasedBy, h.ReleasedAt = releasedBy, &at
+			return nil
+		}
+	}
+	return ErrHoldNotFound
+}
+
+func (m *mockRepo) ListActiveHolds(ctx context.Context) ([]*LegalHold, error) {
+	var out []*LegalHold
+	for _, h := range m.holds {
+		if h.IsActive() {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+type mockPermissions struct {
+	allowed map[string]bool
+}
+
+func (m *mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return m.allowed[userID] && scope == role.ScopePlatform && permission == policy.PermPlatformManageTenants, nil
+}
+
+var admins = &mockPermissions{allowed: map[string]bool{"admin": true}}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+func TestSetOverride(t *testing.T) {
+	tests := []struct {
+		name     string
+		category Category
+		period   time.Duration
+		wantErr  error
+	}{
+		{"within bounds", CategoryAuditEvents, 90 * day, nil},
+		{"below minimum", CategoryAuditEvents, day, ErrOutOfBounds},
+		{"above maximum", CategoryWebhookDeliveries, 2 * 365 * day, ErrOutOfBounds},
+		{"unknown category", Category("emails"), day, ErrUnknownCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{}
+			logger := &mockAuditLogger{}
+			svc := NewService(repo, admins, logger)
+
+			err := svc.SetOverride(context.Background(), "t1", tt.category, tt.period, "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetOverride() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.overrides) != 0 || len(logger.events) != 0 {
+					t.Error("rejected override was stored or audited")
+				}
+				return
+			}
+
+			got, err := svc.Period(context.Background(), "t1", tt.category)
+			if err != nil || got != tt.period {
+				t.Errorf("Period() = %v, %v; want %v", got, err, tt.period)
+			}
+			if len(logger.events) != 1 || logger.events[0].Type != audit.TypeTenantUpdated {
+				t.Errorf("expected one tenant update audit event, got %+v", logger.events)
+			}
+		})
+	}
+}
+
+func TestPeriodDefaults(t *testing.T) {
+	svc := NewService(&mockRepo{}, admins, &mockAuditLogger{}, WithPeriod(CategoryLoginHistory, 30*day))
+
+	if got, _ := svc.Period(context.Background(), "t1", CategoryAuditEvents); got != 365*day {
+		t.Errorf("audit period = %v, want built-in default", got)
+	}
+	if got, _ := svc.Period(context.Background(), "t1", CategoryLoginHistory); got != 30*day {
+		t.Errorf("login history period = %v, want deployment default", got)
+	}
+}
+
+func TestLegalHolds(t *testing.T) {
+	repo := &mockRepo{}
+	logger := &mockAuditLogger{}
+	svc := NewService(repo, admins, logger)
+	ctx := context.Background()
+
+	if _, err := svc.PlaceHold(ctx, "t1", "", "litigation", "member"); !errors.Is(err, ErrNotPermitted) {
+		t.Fatalf("PlaceHold() by non-admin error = %v, want ErrNotPermitted", err)
+	}
+	if _, err := svc.PlaceHold(ctx, "t1", "", "  ", "admin"); !errors.Is(err, ErrInvalidHold) {
+		t.Fatalf("PlaceHold() without reason error = %v, want ErrInvalidHold", err)
+	}
+
+	h, err := svc.PlaceHold(ctx, "t1", "", "litigation", "admin")
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+	if _, err := svc.PlaceHold(ctx, "t2", "u1", "investigation", "admin"); err != nil {
+		t.Fatalf("PlaceHold() for user error = %v", err)
+	}
+	if holds, _ := svc.ListHolds(ctx, "admin"); len(holds) != 2 {
+		t.Fatalf("ListHolds() = %d holds, want 2", len(holds))
+	}
+	if _, err := svc.ListHolds(ctx, "member"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("ListHolds() by non-admin error = %v, want ErrNotPermitted", err)
+	}
+
+	tests := []struct {
+		tenantID, userID string
+		want             bool
+	}{
+		{"t1", "", true},
+		{"t1", "u9", true},
+		{"t9", "u1", true},
+		{"t2", "u9", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got, err := svc.IsHeld(ctx, tt.tenantID, tt.userID); err != nil || got != tt.want {
+			t.Errorf("IsHeld(%q, %q) = %v, %v; want %v", tt.tenantID, tt.userID, got, err, tt.want)
+		}
+	}
+
+	if err := svc.ReleaseHold(ctx, h.ID, "member"); !errors.Is(err, ErrNotPermitted) {
+		t.Errorf("ReleaseHold() by non-admin error = %v, want ErrNotPermitted", err)
+	}
+	if err := svc.ReleaseHold(ctx, h.ID, "admin"); err != nil {
+		t.Fatalf("ReleaseHold() error = %v", err)
+	}
+	if err := svc.ReleaseHold(ctx, h.ID, "admin"); !errors.Is(err, ErrHoldNotFound) {
+		t.Errorf("second ReleaseHold() error = %v, want ErrHoldNotFound", err)
+	}
+	if held, _ := svc.IsHeld(ctx, "t1", ""); held {
+		t.Error("tenant still held after release")
+	}
+
+	want := []string{audit.TypeLegalHoldPlaced, audit.TypeLegalHoldPlaced, audit.TypeLegalHoldReleased}
+	if len(logger.events) != len(want) {
+		t.Fatalf("audit events = %+v, want %v", logger.events, want)
+	}
+	for i, e := range logger.events {
+		if e.Type != want[i] {
+			t.Errorf("audit event %d = %q, want %q", i, e.Type, want[i])
+		}
+	}
+	if logger.events[2].TenantID != "t1" {
+		t.Errorf("release audit tenant = %q, want t1", logger.events[2].TenantID)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	repo := &mockRepo{
+		overrides: []*Override{
+			{TenantID: "t-long", Category: CategoryAuditEvents, Period: 730 * day},
+			{TenantID: "t-held", Category: CategoryAuditEvents, Period: 30 * day},
+		},
+		holds: []*LegalHold{
+			{ID: "h1", TenantID: "t-held", Reason: "litigation"},
+			{ID: "h2", TenantID: "t-other", UserID: "u-held", Reason: "investigation"},
+		},
+	}
+	svc := NewService(repo, admins, &mockAuditLogger{})
+
+	report, err := svc.Purge(context.Background())
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if report.HeldTenants != 1 || report.HeldUsers != 1 {
+		t.Errorf("held = %d tenants, %d users; want 1 and 1", report.HeldTenants, report.HeldUsers)
+	}
+
+	var audits []purgeCall
+	for _, p := range repo.purges {
+		if !slices.Contains(p.scope.ExcludeUsers, "u-held") {
+			t.Errorf("%s purge does not exclude the held user", p.category)
+		}
+		if p.scope.TenantID == "t-held" {
+			t.Errorf("%s purge targets the held tenant", p.category)
+		}
+		if p.scope.TenantID == "" && !slices.Contains(p.scope.ExcludeTenants, "t-held") {
+			t.Errorf("%s default purge does not exclude the held tenant", p.category)
+		}
+		if p.category == CategoryAuditEvents {
+			audits = append(audits, p)
+		}
+	}
+
+	if len(repo.purges) != len(Definitions())+1 {
+		t.Fatalf("got %d purge calls, want one per category plus the override", len(repo.purges))
+	}
+	if len(audits) != 2 {
+		t.Fatalf("got %d audit purges, want default and override", len(audits))
+	}
+	if !slices.Contains(audits[0].scope.ExcludeTenants, "t-long") {
+		t.Error("default audit purge does not exclude the overriding tenant")
+	}
+	if audits[1].scope.TenantID != "t-long" || audits[1].cutoff.After(audits[0].cutoff) {
+		t.Errorf("override purge = %+v, want t-long with an older cutoff", audits[1])
+	}
+	if report.Deleted[CategoryAuditEvents] != 2 {
+		t.Errorf("audit deleted = %d, want 2", report.Deleted[CategoryAuditEvents])
+	}
+}
+
+func TestPurgeContinuesAfterFailure(t *testing.T) {
+	repo := &mockRepo{failOn: CategorySessions}
+	svc := NewService(repo, admins, &mockAuditLogger{})
+
+	report, err := svc.Purge(context.Background())
+	if err == nil {
+		t.Fatal("Purge() error = nil, want the session failure")
+	}
+	if len(repo.purges) != len(Definitions())-1 || report.Deleted[CategoryWebhookDeliveries] != 1 {
+		t.Errorf("other categories were not purged: %+v", report.Deleted)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package risk scores interactive logins for signs of account takeover, such
+// as a sign-in from a country the user has never used or travel faster than
+// an airliner since the previous sign-in. IP geolocation is host-provided
+// through GeoProvider.
+package risk
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	// ErrLocationUnknown is returned by a GeoProvider that cannot place an address (private ranges, unknown networks).
+	ErrLocationUnknown = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "location unknown")
+	// ErrNoLoginHistory is returned by Repository.LastLogin for a user without recorded logins.
+	ErrNoLoginHistory = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "no login history")
+)
+
+// Risk signals
+const (
+	SignalNewCountry       = "new_country"
+	SignalImpossibleTravel = "impossible_travel"
+	// SignalExcessiveIssuance is raised while the user is flagged by issuance monitoring
+	SignalExcessiveIssuance = "excessive_issuance"
+)
+
+// Location is the geographic origin of an IP address.
+//
+// Purpose: Output of IP geolocation.
+// Domain: Identity (Security)
+// Invariants: Country is an upper-case ISO 3166-1 alpha-2 code. Coordinates are
+// meaningful only when HasCoordinates is true.
+type Location struct {
+	Country   string  `json:"country"`
+	City      string  `json:"city,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+}
+
+// HasCoordinates reports whether the provider resolved a position.
+// Providers that only know the country leave both coordinates zero.
+func (l *Location) HasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+// GeoProvider resolves IP addresses to locations.
+//
+// Purpose: Host adapter boundary for IP geolocation (MaxMind, IP2Location, a CDN header).
+// Domain: Identity (Security)
+type GeoProvider interface {
+	// Lookup returns the location of ip, or ErrLocationUnknown
+	Lookup(ctx context.Context, ip string) (*Location, error)
+}
+
+// LoginRecord is the geography of one successful login.
+//
+// Purpose: History against which later logins are compared.
+// Domain: Identity (Security)
+// Invariants: Holds no IP address; only the derived country and coordinates are kept.
+type LoginRecord struct {
+	ID        string
+	UserID    string
+	TenantID  string
+	Country   string
+	Latitude  float64
+	Longitude float64
+	CreatedAt time.Time
+}
+
+// Login describes a successful authentication to assess.
+type Login struct {
+	TenantID string
+	UserID   string
+	IP       string
+	At       time.Time
+}
+
+// Assessment is the outcome of evaluating a login.
+//
+// Purpose: Risk input for step-up, alerting, and adaptive policies.
+// Domain: Identity (Security)
+// Invariants: Score is between 0 and 100. Signals is empty when nothing was flagged.
+type Assessment struct {
+	Score    int       `json:"score"`
+	Signals  []string  `json:"signals,omitempty"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// Suspicious reports whether any risk signal was raised.
+func (a *Assessment) Suspicious() bool {
+	return len(a.Signals) > 0
+}
+
+// Has reports whether signal was raised.
+func (a *Assessment) Has(signal string) bool {
+	for _, s := range a.Signals {
+		if s == signal {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy holds the geography heuristics.
+//
+// Purpose: Tunable thresholds for suspicious login detection.
+// Domain: Identity (Security)
+// Invariants: A zero score disables that signal.
+type Policy struct {
+	// MaxSpeedKmh is the fastest plausible travel speed between two logins.
+	MaxSpeedKmh float64
+	// MinDistanceKm ignores jumps shorter than this, absorbing geolocation inaccuracy.
+	MinDistanceKm float64
+	// NewCountryScore is added when the user has never logged in from the country.
+	NewCountryScore int
+	// ImpossibleTravelScore is added when the jump from the previous login is too fast.
+	ImpossibleTravelScore int
+	// Retention is how long login history is kept.
+	Retention time.Duration
+
+	// ExcessiveIssuanceScore is added while the user is flagged for excessive token or session issuance.
+	ExcessiveIssuanceScore int
+}
+
+// DefaultPolicy returns conservative heuristics: airliner speed and a 500 km noise floor.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxSpeedKmh:           1000,
+		MinDistanceKm:         500,
+		NewCountryScore:       40,
+		ImpossibleTravelScore: 70,
+		Retention:             180 * 24 * time.Hour,
+
+		ExcessiveIssuanceScore: 50,
+	}
+}
+
+// Repository defines persistence for login history.
+//
+// Purpose: Per-user geography baseline shared across instances.
+// Domain: Identity (Security)
+type Repository interface {
+	// RecordLogin persists a login record
+	RecordLogin(ctx context.Context, r *LoginRecord) error
+	// LastLogin returns the user's most recent record, or ErrNoLoginHistory
+	LastLogin(ctx context.Context, userID string) (*LoginRecord, error)
+	// KnownCountry reports whether the user has logged in from country before
+	KnownCountry(ctx context.Context, userID, country string) (bool, error)
+	// DeleteBefore removes records created before t
+	DeleteBefore(ctx context.Context, t time.Time) error
+}
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances.
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two coordinates.
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := func(d float64) float64 { return d * math.Pi / 180 }
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
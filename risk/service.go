@@ -0,0 +1,255 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Audit metadata keys
+const (
+	attrSignals         = "signals"
+	attrScore           = "score"
+	attrCountry         = "country"
+	attrPreviousCountry = "previous_country"
+	attrDistanceKm      = "distance_km"
+)
+
+// Service evaluates logins against each user's geography history.
+//
+// Purpose: Suspicious login detection (new country, impossible travel).
+// Domain: Identity (Security)
+// Invariants: Only successful logins are recorded; the first recorded login sets the baseline and is never flagged for geography.
+type Service struct {
+	repo        Repository
+	geo         GeoProvider
+	auditLogger audit.Logger
+	policy      Policy
+	events      events.Publisher
+	tracer      tracing.Tracer
+	issuance    IssuanceFlags
+}
+
+// IssuanceFlags reports users flagged for excessive token or session issuance;
+// issuance.Service implements it.
+type IssuanceFlags interface {
+	Flagged(ctx context.Context, userID string) (bool, error)
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithPolicy replaces DefaultPolicy.
+func WithPolicy(p Policy) Option {
+	return func(s *Service) { s.policy = p }
+}
+
+// WithEvents publishes SuspiciousLogin events on p.
+func WithEvents(p events.Publisher) Option {
+	return func(s *Service) { s.events = p }
+}
+
+// WithTracer emits spans for login evaluation on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithIssuanceFlags raises SignalExcessiveIssuance for users f has flagged.
+func WithIssuanceFlags(f IssuanceFlags) Option {
+	return func(s *Service) { s.issuance = f }
+}
+
+// NewService creates a new login risk service.
+//
+// Purpose: Constructor for the login risk service.
+// Domain: Identity (Security)
+// Audited: No
+// Errors: None
+func NewService(repo Repository, geo GeoProvider, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		geo:         geo,
+		auditLogger: auditLogger,
+		policy:      DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// EvaluateLogin scores a successful login and records it in the user's history.
+//
+// Purpose: Called by transports after authentication, before a session is issued, so
+// the result can drive step-up or denial.
+// Domain: Identity (Security)
+// Security: Fails open on geolocation and issuance flag errors: the login is assessed
+// without that input.
+// Audited: Yes (SuspiciousLogin, only when a signal is raised)
+// Errors: System errors
+func (s *Service) EvaluateLogin(ctx context.Context, login Login) (*Assessment, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "risk.EvaluateLogin", tracing.String(tracing.AttrUserID, login.UserID))
+	defer span.End()
+
+	if login.At.IsZero() {
+		login.At = time.Now()
+	}
+
+	assessment := &Assessment{}
+	if s.issuance != nil && s.policy.ExcessiveIssuanceScore > 0 {
+		flagged, err := s.issuance.Flagged(ctx, login.UserID)
+		if err != nil {
+			slog.WarnContext(ctx, "issuance flag lookup failed", "error", err)
+		} else if flagged {
+			assessment.flag(SignalExcessiveIssuance, s.policy.ExcessiveIssuanceScore)
+		}
+	}
+
+	loc, err := s.geo.Lookup(ctx, login.IP)
+	if err != nil {
+		if !errors.Is(err, ErrLocationUnknown) {
+			slog.WarnContext(ctx, "geolocation lookup failed", "error", err)
+		}
+		s.report(ctx, login, assessment, nil, 0)
+		return assessment, nil
+	}
+	if loc.Country == "" {
+		s.report(ctx, login, assessment, nil, 0)
+		return assessment, nil
+	}
+	assessment.Location = loc
+
+	last, err := s.repo.LastLogin(ctx, login.UserID)
+	if err != nil && !errors.Is(err, ErrNoLoginHistory) {
+		return nil, fmt.Errorf("failed to load login history: %w", err)
+	}
+
+	var distance float64
+	if last != nil {
+		known, err := s.repo.KnownCountry(ctx, login.UserID, loc.Country)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load login history: %w", err)
+		}
+		if !known && s.policy.NewCountryScore > 0 {
+			assessment.flag(SignalNewCountry, s.policy.NewCountryScore)
+		}
+		if s.policy.ImpossibleTravelScore > 0 {
+			var impossible bool
+			distance, impossible = s.impossibleTravel(last, loc, login.At)
+			if impossible {
+				assessment.flag(SignalImpossibleTravel, s.policy.ImpossibleTravelScore)
+			}
+		}
+	}
+
+	if err := s.repo.RecordLogin(ctx, &LoginRecord{
+		ID:        id.NewUUIDv7(),
+		UserID:    login.UserID,
+		TenantID:  login.TenantID,
+		Country:   loc.Country,
+		Latitude:  loc.Latitude,
+		Longitude: loc.Longitude,
+		CreatedAt: login.At,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record login: %w", err)
+	}
+
+	s.report(ctx, login, assessment, last, distance)
+	return assessment, nil
+}
+
+// Prune removes login history older than the policy retention.
+//
+// Purpose: Periodic maintenance keeping location history bounded.
+// Domain: Identity (Security)
+// Audited: No
+// Errors: System errors
+func (s *Service) Prune(ctx context.Context) error {
+	if s.policy.Retention <= 0 {
+		return nil
+	}
+	return s.repo.DeleteBefore(ctx, time.Now().Add(-s.policy.Retention))
+}
+
+// report audits and publishes an assessment that raised a signal.
+func (s *Service) report(ctx context.Context, login Login, assessment *Assessment, last *LoginRecord, distance float64) {
+	if !assessment.Suspicious() {
+		return
+	}
+	metadata := map[string]any{
+		attrSignals: assessment.Signals,
+		attrScore:   assessment.Score,
+	}
+	var country string
+	if assessment.Location != nil {
+		country = assessment.Location.Country
+		metadata[attrCountry] = country
+	}
+	if last != nil {
+		metadata[attrPreviousCountry] = last.Country
+	}
+	if distance > 0 {
+		metadata[attrDistanceKm] = math.Round(distance)
+	}
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:      audit.TypeSuspiciousLogin,
+		TenantID:  login.TenantID,
+		ActorID:   login.UserID,
+		Resource:  audit.ResourceUser,
+		TargetID:  login.UserID,
+		IPAddress: login.IP,
+		Metadata:  metadata,
+	})
+	events.Emit(ctx, s.events, events.SuspiciousLogin{
+		Meta:    events.NewMeta(login.TenantID, login.UserID),
+		UserID:  login.UserID,
+		Signals: assessment.Signals,
+		Score:   assessment.Score,
+		Country: country,
+	})
+}
+
+// impossibleTravel returns the distance from the previous login and whether
+// covering it by at exceeds the maximum plausible speed.
+func (s *Service) impossibleTravel(last *LoginRecord, loc *Location, at time.Time) (float64, bool) {
+	if !loc.HasCoordinates() || (last.Latitude == 0 && last.Longitude == 0) {
+		return 0, false
+	}
+	distance := DistanceKm(last.Latitude, last.Longitude, loc.Latitude, loc.Longitude)
+	if distance < s.policy.MinDistanceKm {
+		return distance, false
+	}
+	hours := at.Sub(last.CreatedAt).Hours()
+	if hours <= 0 {
+		return distance, true
+	}
+	return distance, distance/hours > s.policy.MaxSpeedKmh
+}
+
+// flag records a signal and adds its weight, capping the score at 100.
+func (a *Assessment) flag(signal string, score int) {
+	a.Signals = append(a.Signals, signal)
+	a.Score = min(a.Score+score, 100)
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+type mockRepo struct {
+	records []*LoginRecord
+}
+
+func (m *mockRepo) RecordLogin(ctx context.Context, r *LoginRecord) error {
+	m.records = append(m.records, r)
+	return nil
+}
+
+func (m *mockRepo) LastLogin(ctx context.Context, userID string) (*LoginRecord, error) {
+	var last *LoginRecord
+	for _, r := range m.records {
+		if r.UserID == userID && (last == nil || r.CreatedAt.After(last.CreatedAt)) {
+			last = r
+		}
+	}
+	if last == nil {
+		return nil, ErrNoLoginHistory
+	}
+	return last, nil
+}
+
+func (m *mockRepo) KnownCountry(ctx context.Context, userID, country string) (bool, error) {
+	for _, r := range m.records {
+		if r.UserID == userID && r.Country == country {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRepo) DeleteBefore(ctx context.Context, t time.Time) error {
+	var kept []*LoginRecord
+	for _, r := range m.records {
+		if !r.CreatedAt.Before(t) {
+			kept = append(kept, r)
+		}
+	}
+	m.records = kept
+	return nil
+}
+
+type mapGeo map[string]*Location
+
+func (g mapGeo) Lookup(ctx context.Context, ip string) (*Location, error) {
+	if loc, ok := g[ip]; ok {
+		return loc, nil
+	}
+	return nil, ErrLocationUnknown
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+var geo = mapGeo{
+	"berlin":  {Country: "DE", Latitude: 52.52, Longitude: 13.40},
+	"hamburg": {Country: "DE", Latitude: 53.55, Longitude: 9.99},
+	"vienna":  {Country: "AT", Latitude: 48.21, Longitude: 16.37},
+	"sydney":  {Country: "AU", Latitude: -33.87, Longitude: 151.21},
+	"country": {Country: "AU"},
+}
+
+func TestEvaluateLogin(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		history     bool
+		ip          string
+		after       time.Duration
+		wantSignals []string
+	}{
+		{"first login sets baseline", false, "sydney", time.Hour, nil},
+		{"same city", true, "berlin", time.Hour, nil},
+		{"nearby city within noise floor", true, "hamburg", 10 * time.Minute, nil},
+		{"new country by road", true, "vienna", 24 * time.Hour, []string{SignalNewCountry}},
+		{"new country impossibly fast", true, "sydney", 2 * time.Hour, []string{SignalNewCountry, SignalImpossibleTravel}},
+		{"new country by plane", true, "sydney", 30 * time.Hour, []string{SignalNewCountry}},
+		{"country without coordinates", true, "country", time.Minute, []string{SignalNewCountry}},
+		{"unknown location", true, "10.0.0.1", time.Minute, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{}
+			if tt.history {
+				repo.records = append(repo.records, &LoginRecord{UserID: "u1", Country: "DE", Latitude: 52.52, Longitude: 13.40, CreatedAt: start})
+			}
+			logger := &mockAuditLogger{}
+			svc := NewService(repo, geo, logger)
+
+			got, err := svc.EvaluateLogin(context.Background(), Login{TenantID: "t1", UserID: "u1", IP: tt.ip, At: start.Add(tt.after)})
+			if err != nil {
+				t.Fatalf("EvaluateLogin() error = %v", err)
+			}
+			if len(got.Signals) != len(tt.wantSignals) {
+				t.Fatalf("Signals = %v, want %v", got.Signals, tt.wantSignals)
+			}
+			for _, s := range tt.wantSignals {
+				if !got.Has(s) {
+					t.Errorf("Signals = %v, missing %s", got.Signals, s)
+				}
+			}
+			if got.Suspicious() != (len(logger.events) == 1) {
+				t.Errorf("audited %d events for suspicious=%v", len(logger.events), got.Suspicious())
+			}
+			if got.Score > 100 {
+				t.Errorf("Score = %d, want <= 100", got.Score)
+			}
+		})
+	}
+}
+
+type flaggedUsers map[string]bool
+
+func (f flaggedUsers) Flagged(ctx context.Context, userID string) (bool, error) {
+	return f[userID], nil
+}
+
+func TestEvaluateLoginIssuanceFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		ip          string
+		wantSignals []string
+	}{
+		{"flagged user", "u1", "berlin", []string{SignalExcessiveIssuance}},
+		{"flagged user without location", "u1", "10.0.0.1", []string{SignalExcessiveIssuance}},
+		{"other user", "u2", "berlin", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &mockAuditLogger{}
+			svc := NewService(&mockRepo{}, geo, logger, WithIssuanceFlags(flaggedUsers{"u1": true}))
+			got, err := svc.EvaluateLogin(context.Background(), Login{TenantID: "t1", UserID: tt.userID, IP: tt.ip})
+			if err != nil {
+				t.Fatalf("EvaluateLogin() error = %v", err)
+			}
+			if len(got.Signals) != len(tt.wantSignals) || (len(tt.wantSignals) > 0 && !got.Has(tt.wantSignals[0])) {
+				t.Errorf("Signals = %v, want %v", got.Signals, tt.wantSignals)
+			}
+			if got.Suspicious() != (len(logger.events) == 1) {
+				t.Errorf("audited %d events for suspicious=%v", len(logger.events), got.Suspicious())
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	repo := &mockRepo{records: []*LoginRecord{
+		{UserID: "u1", Country: "DE", CreatedAt: time.Now().Add(-365 * 24 * time.Hour)},
+		{UserID: "u1", Country: "AT", CreatedAt: time.Now()},
+	}}
+	if err := NewService(repo, geo, &mockAuditLogger{}).Prune(context.Background()); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(repo.records) != 1 || repo.records[0].Country != "AT" {
+		t.Errorf("records = %+v, want only the recent one", repo.records)
+	}
+	if _, err := repo.LastLogin(context.Background(), "u2"); !errors.Is(err, ErrNoLoginHistory) {
+		t.Errorf("LastLogin() error = %v, want ErrNoLoginHistory", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/requestctx"
+)
+
+// ChangeAction is the kind of an assignment change.
+type ChangeAction string
+
+// Change actions
+const (
+	ChangeGrant  ChangeAction = "grant"
+	ChangeRevoke ChangeAction = "revoke"
+)
+
+// AssignmentChange is one entry of the role assignment history.
+//
+// Purpose: Durable record of a role being granted or revoked, kept after the assignment itself is deleted.
+// Domain: Authz
+// Invariants: Append-only; entries are never updated or deleted. Every insert into
+// or delete from the assignment table produces exactly one entry, including
+// cascades from user, role, and tenant deletion. ActorID and Reason are empty when
+// the change was not attributed.
+type AssignmentChange struct {
+	ID             int64        `json:"id"`
+	Action         ChangeAction `json:"action"`
+	UserID         string       `json:"user_id"`
+	RoleID         string       `json:"role_id"`
+	Scope          Scope        `json:"scope"`
+	ScopeContextID *string      `json:"scope_context_id,omitempty"`
+	ActorID        string       `json:"actor_id,omitempty"`
+	Reason         string       `json:"reason,omitempty"`
+	OccurredAt     time.Time    `json:"occurred_at"`
+}
+
+// HistoryFilter selects assignment history entries. Zero fields match everything.
+type HistoryFilter struct {
+	UserID         string
+	RoleID         string
+	Scope          Scope
+	ScopeContextID *string
+	Since          time.Time
+	Until          time.Time
+	// Limit caps the number of entries returned; zero selects the repository default.
+	Limit int
+}
+
+// HistoryRepository defines read access to the role assignment history.
+// Entries are written by the store itself whenever an assignment changes.
+//
+// Purpose: Answers "who had access to X on date Y" after assignments were revoked.
+// Domain: Authz
+type HistoryRepository interface {
+	// ListChanges returns matching entries, newest first
+	ListChanges(ctx context.Context, filter HistoryFilter) ([]*AssignmentChange, error)
+	// AssignmentsAt returns the assignments in force at scope and context at time at
+	AssignmentsAt(ctx context.Context, scope Scope, scopeContextID *string, at time.Time) ([]*Assignment, error)
+}
+
+type changeAttributionKey struct{}
+
+type changeAttribution struct {
+	actorID string
+	reason  string
+}
+
+// WithChangeAttribution returns ctx attributing assignment changes made with it
+// to actorID, for reason. Stores record both in the assignment history.
+func WithChangeAttribution(ctx context.Context, actorID, reason string) context.Context {
+	return context.WithValue(ctx, changeAttributionKey{}, changeAttribution{actorID: actorID, reason: reason})
+}
+
+// ChangeAttribution returns the actor and reason attributed in ctx, or "".
+// Without an explicit attribution the actor is the request's (requestctx.ActorID).
+func ChangeAttribution(ctx context.Context) (actorID, reason string) {
+	v, _ := ctx.Value(changeAttributionKey{}).(changeAttribution)
+	return requestctx.ResolveActorID(ctx, v.actorID), v.reason
+}
+
+// AttributeActor returns ctx attributed to actorID, keeping any reason already set.
+// An actor attributed with WithChangeAttribution wins; actorID in turn wins over
+// the request actor (requestctx).
+func AttributeActor(ctx context.Context, actorID string) context.Context {
+	current, _ := ctx.Value(changeAttributionKey{}).(changeAttribution)
+	if current.actorID != "" || actorID == "" {
+		return ctx
+	}
+	return WithChangeAttribution(ctx, actorID, current.reason)
+}
@@ -15,9 +15,11 @@
 package role
 
 import (
+	"context"
 	"testing"
 
 	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
 )
 
 func TestRoleHasPermission(t *testing.T) {
@@ -90,3 +92,29 @@ func TestDefaultRoleMappings(t *testing.T) {
 		t.Error("Tenant member should NOT have tenant:manage_users permission")
 	}
 }
+
+func TestAttributeActor(t *testing.T) {
+	ctx := context.Background()
+
+	if actor, reason := ChangeAttribution(AttributeActor(ctx, "u1")); actor != "u1" || reason != "" {
+		t.Errorf("AttributeActor() = (%q, %q), want (u1, \"\")", actor, reason)
+	}
+
+	ctx = WithChangeAttribution(ctx, "", "offboarding")
+	if actor, reason := ChangeAttribution(AttributeActor(ctx, "u1")); actor != "u1" || reason != "offboarding" {
+		t.Errorf("AttributeActor() kept reason = (%q, %q), want (u1, offboarding)", actor, reason)
+	}
+
+	ctx = WithChangeAttribution(context.Background(), "admin", "audit finding")
+	if actor, _ := ChangeAttribution(AttributeActor(ctx, "u1")); actor != "admin" {
+		t.Errorf("AttributeActor() overrode actor: got %q, want admin", actor)
+	}
+
+	ctx = requestctx.WithUser(context.Background(), "requester")
+	if actor, _ := ChangeAttribution(ctx); actor != "requester" {
+		t.Errorf("ChangeAttribution() = %q, want the request actor", actor)
+	}
+	if actor, _ := ChangeAttribution(AttributeActor(ctx, "u1")); actor != "u1" {
+		t.Errorf("AttributeActor() lost to the request actor: got %q, want u1", actor)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rolemap translates claims asserted by an upstream identity provider
+// (for example directory groups) into tenant roles. The transport that
+// completes a federated login passes the upstream claims to Reconcile, which
+// grants the roles the rules call for and revokes roles it granted earlier
+// that the claims no longer support. Roles granted by administrators are
+// never touched.
+package rolemap
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/tenant"
+)
+
+// Domain errors
+var (
+	ErrRuleNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "role mapping rule not found")
+	ErrInvalidRule  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid role mapping rule")
+)
+
+// ActorRoleMapping is the GrantedBy and audit actor of roles granted by rules.
+// Reconcile only revokes roles carrying this marker.
+const ActorRoleMapping = "system:role_mapping"
+
+// ReasonRoleMapping justifies rule-driven grants in tenants that require a reason
+// for audit.OpRoleGrant.
+const ReasonRoleMapping = "identity provider role mapping"
+
+// Rule grants a tenant role to users whose upstream claim contains a value.
+//
+// Purpose: Persisted, tenant-scoped claim-to-role mapping.
+// Domain: Tenant
+// Invariants: Claim and Value are non-empty and matched exactly. Role is a
+// mappable tenant role; tenant_owner is never granted from upstream claims.
+type Rule struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Claim     string    `json:"claim"`
+	Value     string    `json:"value"`
+	Role      string    `json:"role"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether claims satisfy the rule. A claim may be a single
+// string or a list of strings, such as a groups claim.
+func (r *Rule) Matches(claims map[string]any) bool {
+	switch v := claims[r.Claim].(type) {
+	case string:
+		return v == r.Value
+	case []string:
+		for _, s := range v {
+			if s == r.Value {
+				return true
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if s, ok := e.(string); ok && s == r.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Result lists the role changes made by one reconciliation.
+type Result struct {
+	Granted []string `json:"granted"`
+	Revoked []string `json:"revoked"`
+}
+
+// Repository defines persistence for mapping rules.
+//
+// Purpose: Storage abstraction for claim-to-role rules.
+// Domain: Tenant
+type Repository interface {
+	// Create persists a new rule
+	Create(ctx context.Context, rule *Rule) error
+	// List returns every rule of a tenant
+	List(ctx context.Context, tenantID string) ([]*Rule, error)
+	// Delete removes a rule of a tenant
+	Delete(ctx context.Context, tenantID, id string) error
+}
+
+// RoleManager grants and revokes tenant roles.
+//
+// Purpose: The tenant role operations reconciliation needs; satisfied by tenant.Service.
+// Domain: Tenant
+// Invariants: AssignRole and RevokeRole audit and publish the change themselves.
+type RoleManager interface {
+	AssignRole(ctx context.Context, tenantID, userID, roleName, grantedBy string) error
+	RevokeRole(ctx context.Context, tenantID, userID, roleName, actorID string) error
+	GetUserRoles(ctx context.Context, tenantID, userID string) ([]*tenant.TenantUserRole, error)
+}
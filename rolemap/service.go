@@ -0,0 +1,192 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolemap
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Service manages mapping rules and reconciles users' roles against them.
+//
+// Purpose: Just-in-time tenant role assignment from upstream identity claims.
+// Domain: Tenant
+// Invariants: Reconcile grants with ActorRoleMapping and revokes only roles
+// granted with ActorRoleMapping.
+type Service struct {
+	repo        Repository
+	roles       RoleManager
+	auditLogger audit.Logger
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithTracer emits spans for reconciliation on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new role mapping service.
+//
+// Purpose: Constructor for the role mapping service.
+// Domain: Tenant
+// Audited: No
+// Errors: None
+func NewService(repo Repository, roles RoleManager, auditLogger audit.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		roles:       roles,
+		auditLogger: auditLogger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateRule adds a claim-to-role rule to a tenant.
+//
+// Purpose: Tenant administration of federated role mapping.
+// Domain: Tenant
+// Security: tenant_owner cannot be mapped, so an upstream directory can never
+// take ownership of a tenant.
+// Audited: Yes (RoleMappingCreated)
+// Errors: ErrInvalidRule, System errors
+func (s *Service) CreateRule(ctx context.Context, tenantID, claim, value, roleName, actorID string) (*Rule, error) {
+	claim, value = strings.TrimSpace(claim), strings.TrimSpace(value)
+	if claim == "" || value == "" {
+		return nil, fmt.Errorf("%w: claim and value are required", ErrInvalidRule)
+	}
+	if roleName != role.RoleTenantAdmin && roleName != role.RoleTenantMember {
+		return nil, fmt.Errorf("%w: role %q cannot be mapped", ErrInvalidRule, roleName)
+	}
+
+	rule := &Rule{
+		ID:        id.NewUUIDv7(),
+		TenantID:  tenantID,
+		Claim:     claim,
+		Value:     value,
+		Role:      roleName,
+		CreatedBy: actorID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create role mapping rule: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeRoleMappingCreated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceRoleMapping,
+		TargetID:   rule.ID,
+		TargetName: roleName,
+		Metadata:   map[string]any{"claim": claim, "value": value},
+	})
+	return rule, nil
+}
+
+// ListRules returns every rule of a tenant.
+func (s *Service) ListRules(ctx context.Context, tenantID string) ([]*Rule, error) {
+	return s.repo.List(ctx, tenantID)
+}
+
+// DeleteRule removes a rule. Roles it granted are revoked at each user's next
+// reconciliation.
+//
+// Purpose: Tenant administration of federated role mapping.
+// Domain: Tenant
+// Audited: Yes (RoleMappingDeleted)
+// Errors: ErrRuleNotFound, System errors
+func (s *Service) DeleteRule(ctx context.Context, tenantID, ruleID, actorID string) error {
+	if err := s.repo.Delete(ctx, tenantID, ruleID); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeRoleMappingDeleted,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceRoleMapping,
+		TargetID: ruleID,
+	})
+	return nil
+}
+
+// Reconcile brings a user's mapped roles in line with upstream claims. Call it
+// on every federated login, after the user is resolved and before the session
+// is issued.
+//
+// Purpose: Just-in-time grant and revocation of tenant roles from IdP claims.
+// Domain: Tenant
+// Security: Roles granted by administrators are never revoked, and a role the
+// user already holds is never re-granted under the mapping marker.
+// Audited: Yes (RoleAssigned / RoleRevoked with actor ActorRoleMapping)
+// Errors: System errors; changes made before a failure are kept and reported
+func (s *Service) Reconcile(ctx context.Context, tenantID, userID string, claims map[string]any) (*Result, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "rolemap.Reconcile", tracing.String(tracing.AttrUserID, userID))
+	defer span.End()
+	if audit.Reason(ctx) == "" {
+		ctx = audit.WithReason(ctx, ReasonRoleMapping)
+	}
+
+	rules, err := s.repo.List(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role mapping rules: %w", err)
+	}
+	var desired []string
+	for _, r := range rules {
+		if r.Matches(claims) && !slices.Contains(desired, r.Role) {
+			desired = append(desired, r.Role)
+		}
+	}
+
+	current, err := s.roles.GetUserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	held := make(map[string]bool, len(current))
+	res := &Result{}
+	for _, r := range current {
+		held[r.Role] = true
+		if r.GrantedBy != ActorRoleMapping || slices.Contains(desired, r.Role) {
+			continue
+		}
+		if err := s.roles.RevokeRole(ctx, tenantID, userID, r.Role, ActorRoleMapping); err != nil {
+			return res, fmt.Errorf("failed to revoke mapped role: %w", err)
+		}
+		res.Revoked = append(res.Revoked, r.Role)
+	}
+	for _, name := range desired {
+		if held[name] {
+			continue
+		}
+		if err := s.roles.AssignRole(ctx, tenantID, userID, name, ActorRoleMapping); err != nil {
+			return res, fmt.Errorf("failed to assign mapped role: %w", err)
+		}
+		res.Granted = append(res.Granted, name)
+	}
+	return res, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rolemap
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tenant"
+)
+
+type mockRepo struct {
+	rules []*Rule
+}
+
+func (m *mockRepo) Create(ctx context.Context, rule *Rule) error {
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+func (m *mockRepo) List(ctx context.Context, tenantID string) ([]*Rule, error) {
+	var res []*Rule
+	for _, r := range m.rules {
+		if r.TenantID == tenantID {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) Delete(ctx context.Context, tenantID, id string) error {
+	for i, r := range m.rules {
+		if r.TenantID == tenantID && r.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return nil
+		}
+	}
+	return ErrRuleNotFound
+}
+
+type mockRoles struct {
+	roles map[string]string // role -> granted by
+}
+
+func (m *mockRoles) AssignRole(ctx context.Context, tenantID, userID, roleName, grantedBy string) error {
+	m.roles[roleName] = grantedBy
+	return nil
+}
+
+func (m *mockRoles) RevokeRole(ctx context.Context, tenantID, userID, roleName, actorID string) error {
+	delete(m.roles, roleName)
+	return nil
+}
+
+func (m *mockRoles) GetUserRoles(ctx context.Context, tenantID, userID string) ([]*tenant.TenantUserRole, error) {
+	var res []*tenant.TenantUserRole
+	for name, by := range m.roles {
+		res = append(res, &tenant.TenantUserRole{TenantID: tenantID, UserID: userID, Role: name, GrantedBy: by})
+	}
+	return res, nil
+}
+
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Log(_ context.Context, e audit.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestRuleMatches(t *testing.T) {
+	rule := &Rule{Claim: "groups", Value: "Domain Admins"}
+	tests := []struct {
+		name   string
+		claims map[string]any
+		want   bool
+	}{
+		{"string", map[string]any{"groups": "Domain Admins"}, true},
+		{"string slice", map[string]any{"groups": []string{"Users", "Domain Admins"}}, true},
+		{"decoded JSON list", map[string]any{"groups": []any{"Users", "Domain Admins"}}, true},
+		{"case sensitive", map[string]any{"groups": "domain admins"}, false},
+		{"other claim", map[string]any{"roles": "Domain Admins"}, false},
+		{"non-string", map[string]any{"groups": []any{1, true}}, false},
+		{"missing", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rule.Matches(tt.claims); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_CreateRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		claim   string
+		value   string
+		role    string
+		wantErr error
+	}{
+		{"admin", "groups", "Admins", role.RoleTenantAdmin, nil},
+		{"member", "department", "Sales", role.RoleTenantMember, nil},
+		{"owner", "groups", "Owners", role.RoleTenantOwner, ErrInvalidRule},
+		{"unknown role", "groups", "Admins", "superuser", ErrInvalidRule},
+		{"empty claim", " ", "Admins", role.RoleTenantAdmin, ErrInvalidRule},
+		{"empty value", "groups", "", role.RoleTenantAdmin, ErrInvalidRule},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingAuditLogger{}
+			svc := NewService(&mockRepo{}, &mockRoles{}, logger)
+
+			_, err := svc.CreateRule(context.Background(), "t1", tt.claim, tt.value, tt.role, "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (len(logger.events) != 1 || logger.events[0].Type != audit.TypeRoleMappingCreated) {
+				t.Errorf("audit events = %v", logger.events)
+			}
+		})
+	}
+}
+
+func TestService_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{}
+	roles := &mockRoles{roles: map[string]string{}}
+	svc := NewService(repo, roles, &recordingAuditLogger{})
+
+	if _, err := svc.CreateRule(ctx, "t1", "groups", "Admins", role.RoleTenantAdmin, "owner"); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	if _, err := svc.CreateRule(ctx, "t1", "groups", "Staff", role.RoleTenantMember, "owner"); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	if _, err := svc.CreateRule(ctx, "t2", "groups", "Staff", role.RoleTenantAdmin, "owner"); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	steps := []struct {
+		name        string
+		groups      []any
+		wantGranted []string
+		wantRevoked []string
+		wantRoles   map[string]string
+	}{
+		{
+			name:        "first login grants",
+			groups:      []any{"Admins", "Staff"},
+			wantGranted: []string{role.RoleTenantAdmin, role.RoleTenantMember},
+			wantRoles:   map[string]string{role.RoleTenantAdmin: ActorRoleMapping, role.RoleTenantMember: ActorRoleMapping},
+		},
+		{
+			name:      "unchanged claims are a no-op",
+			groups:    []any{"Admins", "Staff"},
+			wantRoles: map[string]string{role.RoleTenantAdmin: ActorRoleMapping, role.RoleTenantMember: ActorRoleMapping},
+		},
+		{
+			name:        "removed group revokes",
+			groups:      []any{"Staff"},
+			wantRevoked: []string{role.RoleTenantAdmin},
+			wantRoles:   map[string]string{role.RoleTenantMember: ActorRoleMapping},
+		},
+	}
+	for _, st := range steps {
+		res, err := svc.Reconcile(ctx, "t1", "u1", map[string]any{"groups": st.groups})
+		if err != nil {
+			t.Fatalf("%s: Reconcile: %v", st.name, err)
+		}
+		slices.Sort(res.Granted)
+		if !slices.Equal(res.Granted, st.wantGranted) || !slices.Equal(res.Revoked, st.wantRevoked) {
+			t.Errorf("%s: result = %+v, want granted %v revoked %v", st.name, res, st.wantGranted, st.wantRevoked)
+		}
+		if len(roles.roles) != len(st.wantRoles) {
+			t.Errorf("%s: roles = %v, want %v", st.name, roles.roles, st.wantRoles)
+		}
+		for name, by := range st.wantRoles {
+			if roles.roles[name] != by {
+				t.Errorf("%s: %s granted by %q, want %q", st.name, name, roles.roles[name], by)
+			}
+		}
+	}
+
+	// An administrator's grant survives losing the matching group.
+	roles.roles = map[string]string{role.RoleTenantAdmin: "admin-user"}
+	res, err := svc.Reconcile(ctx, "t1", "u1", map[string]any{"groups": []any{"Admins"}})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if len(res.Granted) != 0 || roles.roles[role.RoleTenantAdmin] != "admin-user" {
+		t.Errorf("manual grant was taken over: result %+v, roles %v", res, roles.roles)
+	}
+	if _, err := svc.Reconcile(ctx, "t1", "u1", nil); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if roles.roles[role.RoleTenantAdmin] != "admin-user" {
+		t.Errorf("manual grant was revoked: %v", roles.roles)
+	}
+}
+
+func TestService_DeleteRule(t *testing.T) {
+	logger := &recordingAuditLogger{}
+	svc := NewService(&mockRepo{}, &mockRoles{}, logger)
+
+	if err := svc.DeleteRule(context.Background(), "t1", "missing", "admin"); !errors.Is(err, ErrRuleNotFound) {
+		t.Fatalf("err = %v, want ErrRuleNotFound", err)
+	}
+	if len(logger.events) != 0 {
+		t.Errorf("failed delete was audited: %v", logger.events)
+	}
+}
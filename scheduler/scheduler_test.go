@@ -0,0 +1,82 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+
+	s := New()
+	if err := s.Register(Job{Name: "a", Interval: time.Second, Run: noop}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := s.Register(Job{Name: "a", Interval: time.Second, Run: noop}); !errors.Is(err, ErrDuplicateJob) {
+		t.Errorf("duplicate Register() error = %v, want ErrDuplicateJob", err)
+	}
+	if err := s.Register(Job{Name: "b", Run: noop}); !errors.Is(err, ErrInvalidJob) {
+		t.Errorf("zero interval Register() error = %v, want ErrInvalidJob", err)
+	}
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer s.Stop()
+
+	if err := s.Register(Job{Name: "c", Interval: time.Second, Run: noop}); !errors.Is(err, ErrAlreadyStarted) {
+		t.Errorf("Register() after Start error = %v, want ErrAlreadyStarted", err)
+	}
+	if err := s.Start(context.Background()); !errors.Is(err, ErrAlreadyStarted) {
+		t.Errorf("second Start() error = %v, want ErrAlreadyStarted", err)
+	}
+}
+
+func TestRunAndStop(t *testing.T) {
+	var runs atomic.Int32
+	s := New()
+	_ = s.Register(Job{
+		Name:     "tick",
+		Interval: 5 * time.Millisecond,
+		Run: func(context.Context) error {
+			runs.Add(1)
+			return errors.New("failure is logged, not fatal")
+		},
+	})
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	s.Stop()
+
+	if runs.Load() < 2 {
+		t.Fatalf("job ran %d times, want at least 2", runs.Load())
+	}
+	after := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != after {
+		t.Errorf("job kept running after Stop")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// SCIM schema URNs
+const (
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+)
+
+// Source fields that AttributeMapping values may reference
+const (
+	SourceID         = "id"
+	SourceEmail      = "email"
+	SourceGivenName  = "given_name"
+	SourceFamilyName = "family_name"
+	SourceFullName   = "full_name"
+	SourceNickname   = "nickname"
+	SourcePicture    = "picture"
+	SourceLocale     = "locale"
+	SourceTimezone   = "timezone"
+)
+
+// SourceFields returns every field an attribute mapping may reference.
+func SourceFields() []string {
+	return []string{
+		SourceID, SourceEmail, SourceGivenName, SourceFamilyName, SourceFullName,
+		SourceNickname, SourcePicture, SourceLocale, SourceTimezone,
+	}
+}
+
+// sourceValue returns the value of a source field for u.
+func sourceValue(u *user.User, field string) string {
+	switch field {
+	case SourceID:
+		return u.ID
+	case SourceEmail:
+		if u.EmailPlain != nil {
+			return *u.EmailPlain
+		}
+	case SourceGivenName:
+		return u.Profile.GivenName
+	case SourceFamilyName:
+		return u.Profile.FamilyName
+	case SourceFullName:
+		return u.Profile.FullName
+	case SourceNickname:
+		return u.Profile.Nickname
+	case SourcePicture:
+		return u.Profile.Picture
+	case SourceLocale:
+		return u.Profile.Locale
+	case SourceTimezone:
+		return u.Profile.Timezone
+	}
+	return ""
+}
+
+// BuildUser renders u as a SCIM User resource.
+//
+// Purpose: Default core-schema mapping, overridden per target by mapping.
+// Domain: Tenant
+// Security: Only profile fields are exported; credentials and hashes never leave core.
+// Audited: No
+// Errors: None
+//
+// Mapping keys are attribute paths ("userName", "name.givenName", or an
+// extension path such as "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber").
+// A value names a Source field; an empty value removes the attribute.
+func BuildUser(u *user.User, mapping map[string]string) map[string]any {
+	email := sourceValue(u, SourceEmail)
+	displayName := u.Profile.FullName
+	if displayName == "" {
+		displayName = u.Profile.Nickname
+	}
+
+	res := map[string]any{
+		"schemas":    []string{SchemaUser},
+		"externalId": u.ID,
+		"userName":   email,
+		"active":     u.DeletedAt == nil,
+	}
+	name := map[string]any{}
+	setIfNotEmpty(name, "givenName", u.Profile.GivenName)
+	setIfNotEmpty(name, "familyName", u.Profile.FamilyName)
+	setIfNotEmpty(name, "formatted", u.Profile.FullName)
+	if len(name) > 0 {
+		res["name"] = name
+	}
+	setIfNotEmpty(res, "displayName", displayName)
+	setIfNotEmpty(res, "nickName", u.Profile.Nickname)
+	setIfNotEmpty(res, "locale", u.Profile.Locale)
+	setIfNotEmpty(res, "timezone", u.Profile.Timezone)
+	if email != "" {
+		res["emails"] = []map[string]any{{"value": email, "type": "work", "primary": true}}
+	}
+	if u.Profile.Picture != "" {
+		res["photos"] = []map[string]any{{"value": u.Profile.Picture, "type": "photo"}}
+	}
+
+	// Apply overrides in a stable order so nested paths behave deterministically.
+	paths := make([]string, 0, len(mapping))
+	for p := range mapping {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	for _, p := range paths {
+		applyMapping(res, p, sourceValue(u, mapping[p]))
+	}
+	return res
+}
+
+// validateMapping checks that every key is a usable path and every value a known source field.
+func validateMapping(mapping map[string]string) error {
+	for p, field := range mapping {
+		if field != "" && !slices.Contains(SourceFields(), field) {
+			return fmt.Errorf("%w: unknown source field %q", ErrInvalidTarget, field)
+		}
+		schema, path := splitPath(p)
+		if path == "" || p == "schemas" || (schema == "" && strings.HasPrefix(p, "urn:")) {
+			return fmt.Errorf("%w: invalid attribute path %q", ErrInvalidTarget, p)
+		}
+		for _, part := range strings.Split(path, ".") {
+			if part == "" {
+				return fmt.Errorf("%w: invalid attribute path %q", ErrInvalidTarget, p)
+			}
+		}
+	}
+	return nil
+}
+
+// splitPath separates an extension schema URN from the attribute path.
+// Core attributes return an empty schema.
+func splitPath(p string) (schema, path string) {
+	if !strings.HasPrefix(p, "urn:") {
+		return "", p
+	}
+	i := strings.LastIndex(p, ":")
+	if i <= len("urn:") {
+		return "", ""
+	}
+	return p[:i], p[i+1:]
+}
+
+func applyMapping(res map[string]any, p, value string) {
+	schema, path := splitPath(p)
+	obj := res
+	if schema != "" && schema != SchemaUser {
+		ext, ok := res[schema].(map[string]any)
+		if !ok {
+			ext = map[string]any{}
+		}
+		obj = ext
+		if value != "" {
+			res[schema] = ext
+			if schemas, _ := res["schemas"].([]string); !slices.Contains(schemas, schema) {
+				res["schemas"] = append(schemas, schema)
+			}
+		}
+	}
+
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := obj[part].(map[string]any)
+		if !ok {
+			if value == "" {
+				return
+			}
+			next = map[string]any{}
+			obj[part] = next
+		}
+		obj = next
+	}
+	leaf := parts[len(parts)-1]
+	if value == "" {
+		delete(obj, leaf)
+		return
+	}
+	obj[leaf] = value
+}
+
+func setIfNotEmpty(m map[string]any, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scim provisions users into downstream applications over SCIM 2.0
+// (RFC 7643/7644). Tenants register targets; membership and profile changes
+// from the event bus are queued as per-target operations and delivered with
+// retries. Like webhook, the host supplies the network transport so this
+// repository stays free of HTTP code.
+package scim
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrTargetNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "scim target not found")
+	ErrInvalidTarget  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid scim target")
+	ErrLinkNotFound   = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "scim link not found")
+)
+
+// Operation statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Operation actions, recorded as the request the last attempt resolved to
+const (
+	ActionCreate  = "create"
+	ActionReplace = "replace"
+	ActionDelete  = "delete"
+	ActionNone    = "none"
+)
+
+// Target is a tenant-registered downstream SCIM service provider.
+//
+// Purpose: Destination for outbound user provisioning.
+// Domain: Tenant
+// Invariants: BaseURL is absolute https without credentials. Token is only accepted, never returned.
+// AttributeMapping keys are SCIM attribute paths and values are Source fields.
+type Target struct {
+	ID       string
+	TenantID string
+	Name     string
+	BaseURL  string
+	// Token is the bearer credential for the target. It is stored as-is because requests need the raw value.
+	Token            string
+	AttributeMapping map[string]string
+	Enabled          bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Operation is a pending "bring this user in sync" request for one target.
+//
+// Purpose: Durable outbox row driving retries and exposing per-target delivery status.
+// Domain: Tenant
+// Invariants: The desired state is resolved at delivery time, so replaying an operation is idempotent.
+// Status is terminal once succeeded or failed.
+type Operation struct {
+	ID             string
+	TargetID       string
+	TenantID       string
+	UserID         string
+	Action         string
+	Status         string
+	Attempts       int
+	LastStatusCode int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// Link maps a local user to the resource ID assigned by a target.
+//
+// Purpose: Remembers downstream identifiers so updates and deletes address the right resource.
+// Domain: Tenant
+// Invariants: At most one link per (TargetID, UserID).
+type Link struct {
+	TargetID   string
+	TenantID   string
+	UserID     string
+	ExternalID string
+	CreatedAt  time.Time
+}
+
+// RetryPolicy controls redelivery of failed operations.
+//
+// Purpose: Exponential backoff schedule for SCIM delivery.
+// Domain: Tenant
+// Invariants: MaxAttempts >= 1. Backoff doubles from InitialBackoff up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns a schedule spanning roughly a day.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     6 * time.Hour,
+	}
+}
+
+// Backoff returns the delay before the attempt following attempt number n (1-based).
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < n; i++ {
+		d *= 2
+		if d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return d
+}
+
+// Transport performs SCIM HTTP requests.
+//
+// Purpose: Transport boundary; implemented by the host (typically with net/http).
+// Domain: Tenant
+type Transport interface {
+	// Do sends a request with headers and body (nil for GET and DELETE) and
+	// returns the response status code and body.
+	Do(ctx context.Context, method, url string, headers map[string]string, body []byte) (int, []byte, error)
+}
+
+// Repository defines persistence for targets, operations, and links.
+//
+// Purpose: Storage abstraction for SCIM configuration and delivery state.
+// Domain: Tenant
+type Repository interface {
+	// CreateTarget persists a new target
+	CreateTarget(ctx context.Context, t *Target) error
+	// GetTarget retrieves a target within a tenant
+	GetTarget(ctx context.Context, tenantID, id string) (*Target, error)
+	// ListTargets returns all targets of a tenant
+	ListTargets(ctx context.Context, tenantID string) ([]*Target, error)
+	// UpdateTarget updates name, URL, token, mapping, and enabled state
+	UpdateTarget(ctx context.Context, t *Target) error
+	// DeleteTarget removes a target with its operations and links
+	DeleteTarget(ctx context.Context, tenantID, id string) error
+
+	// CreateOperation persists a pending operation
+	CreateOperation(ctx context.Context, op *Operation) error
+	// UpdateOperation records the outcome of an attempt
+	UpdateOperation(ctx context.Context, op *Operation) error
+	// ListDueOperations returns pending operations whose next attempt is at or before now
+	ListDueOperations(ctx context.Context, now time.Time, limit int) ([]*Operation, error)
+	// ListOperations returns the most recent operations for a target
+	ListOperations(ctx context.Context, tenantID, targetID string, limit int) ([]*Operation, error)
+
+	// GetLink retrieves the downstream resource ID of a user on a target
+	GetLink(ctx context.Context, targetID, userID string) (*Link, error)
+	// SaveLink creates or replaces a link
+	SaveLink(ctx context.Context, l *Link) error
+	// DeleteLink removes a link
+	DeleteLink(ctx context.Context, targetID, userID string) error
+	// ListLinksForUser returns every link of a user across targets
+	ListLinksForUser(ctx context.Context, userID string) ([]*Link, error)
+}
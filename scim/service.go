@@ -0,0 +1,526 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// processBatchSize bounds how many due operations one ProcessDue call attempts.
+const processBatchSize = 100
+
+// historyLimit bounds ListOperations results.
+const historyLimit = 100
+
+// contentType is the SCIM media type (RFC 7644 Section 3.1).
+const contentType = "application/scim+json"
+
+// Response codes with specific handling. Core does not import net/http.
+const (
+	statusRequestTimeout  = 408
+	statusNotFound        = 404
+	statusConflict        = 409
+	statusTooManyRequests = 429
+)
+
+// Service manages SCIM targets and provisions users into them.
+//
+// Purpose: Outbound SCIM 2.0 provisioning driven by membership and profile events.
+// Domain: Tenant
+// Invariants: HandleEvent never performs network I/O; ProcessDue does, via Transport.
+// A user is provisioned to a tenant's targets while they hold any role in that tenant.
+type Service struct {
+	repo        Repository
+	users       user.UserRepository
+	roles       tenant.RoleRepository
+	transport   Transport
+	auditLogger audit.Logger
+	retry       RetryPolicy
+}
+
+// NewService creates a new SCIM provisioning service.
+//
+// Purpose: Constructor for the SCIM service.
+// Domain: Tenant
+// Audited: No
+// Errors: None
+func NewService(repo Repository, users user.UserRepository, roles tenant.RoleRepository, transport Transport, auditLogger audit.Logger, retry RetryPolicy) *Service {
+	return &Service{
+		repo:        repo,
+		users:       users,
+		roles:       roles,
+		transport:   transport,
+		auditLogger: auditLogger,
+		retry:       retry,
+	}
+}
+
+// RegisterTarget adds a downstream SCIM service provider for a tenant.
+//
+// Purpose: Tenant admin provisioning configuration.
+// Domain: Tenant
+// Security: Only https base URLs are accepted. The token is never returned or audited.
+// Audited: Yes (SCIMTargetCreated)
+// Errors: ErrInvalidTarget, System errors
+func (s *Service) RegisterTarget(ctx context.Context, tenantID, name, baseURL, token string, mapping map[string]string, actorID string) (*Target, error) {
+	if err := validateTarget(name, baseURL, token, mapping); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	t := &Target{
+		ID:               id.NewUUIDv7(),
+		TenantID:         tenantID,
+		Name:             name,
+		BaseURL:          strings.TrimRight(baseURL, "/"),
+		Token:            token,
+		AttributeMapping: mapping,
+		Enabled:          true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := s.repo.CreateTarget(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to create scim target: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeSCIMTargetCreated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceSCIMTarget,
+		TargetName: t.Name,
+		TargetID:   t.ID,
+		Metadata:   map[string]any{"base_url": t.BaseURL},
+	})
+	t.Token = ""
+	return t, nil
+}
+
+// UpdateTarget changes a target's name, URL, token, mapping, or enabled state.
+//
+// Purpose: Tenant admin provisioning configuration.
+// Domain: Tenant
+// Security: An empty token keeps the stored one. The token is never returned or audited.
+// Audited: Yes (SCIMTargetUpdated)
+// Errors: ErrTargetNotFound, ErrInvalidTarget, System errors
+func (s *Service) UpdateTarget(ctx context.Context, tenantID, targetID, name, baseURL, token string, mapping map[string]string, enabled bool, actorID string) (*Target, error) {
+	t, err := s.repo.GetTarget(ctx, tenantID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		token = t.Token
+	}
+	if err := validateTarget(name, baseURL, token, mapping); err != nil {
+		return nil, err
+	}
+
+	t.Name = name
+	t.BaseURL = strings.TrimRight(baseURL, "/")
+	t.Token = token
+	t.AttributeMapping = mapping
+	t.Enabled = enabled
+	t.UpdatedAt = time.Now()
+	if err := s.repo.UpdateTarget(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update scim target: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeSCIMTargetUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceSCIMTarget,
+		TargetName: t.Name,
+		TargetID:   t.ID,
+		Metadata:   map[string]any{"base_url": t.BaseURL, "enabled": enabled},
+	})
+	t.Token = ""
+	return t, nil
+}
+
+// DeleteTarget removes a target with its operations and links.
+// Users already provisioned downstream are left in place.
+//
+// Purpose: Tenant admin provisioning configuration.
+// Domain: Tenant
+// Audited: Yes (SCIMTargetDeleted)
+// Errors: ErrTargetNotFound, System errors
+func (s *Service) DeleteTarget(ctx context.Context, tenantID, targetID, actorID string) error {
+	if err := s.repo.DeleteTarget(ctx, tenantID, targetID); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeSCIMTargetDeleted,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceSCIMTarget,
+		TargetID: targetID,
+	})
+	return nil
+}
+
+// ListTargets returns a tenant's targets with tokens redacted.
+func (s *Service) ListTargets(ctx context.Context, tenantID string) ([]*Target, error) {
+	targets, err := s.repo.ListTargets(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		t.Token = ""
+	}
+	return targets, nil
+}
+
+// ListOperations returns recent delivery status for a target.
+func (s *Service) ListOperations(ctx context.Context, tenantID, targetID string) ([]*Operation, error) {
+	return s.repo.ListOperations(ctx, tenantID, targetID, historyLimit)
+}
+
+// Resync queues every current member of the tenant for a target.
+//
+// Purpose: Initial load after registering a target, or recovery after drift.
+// Domain: Tenant
+// Audited: No
+// Errors: ErrTargetNotFound, System errors
+func (s *Service) Resync(ctx context.Context, tenantID, targetID string) error {
+	t, err := s.repo.GetTarget(ctx, tenantID, targetID)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.roles.GetTenantUsers(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list tenant users: %w", err)
+	}
+	seen := make(map[string]bool, len(members))
+	for _, m := range members {
+		if seen[m.UserID] {
+			continue
+		}
+		seen[m.UserID] = true
+		if err := s.enqueue(ctx, t.TenantID, t.ID, m.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleEvent queues provisioning work from the event bus.
+// Role changes sync the user to every target of the tenant; profile updates
+// sync the user to every target they are already provisioned on.
+func (s *Service) HandleEvent(ctx context.Context, e events.Event) error {
+	switch ev := e.(type) {
+	case events.RoleAssigned:
+		return s.enqueueTenant(ctx, ev.TenantID, ev.UserID)
+	case events.RoleRevoked:
+		return s.enqueueTenant(ctx, ev.TenantID, ev.UserID)
+	case events.UserUpdated:
+		links, err := s.repo.ListLinksForUser(ctx, ev.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to list scim links: %w", err)
+		}
+		for _, l := range links {
+			if err := s.enqueue(ctx, l.TenantID, l.TargetID, l.UserID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) enqueueTenant(ctx context.Context, tenantID, userID string) error {
+	if tenantID == "" {
+		return nil
+	}
+	targets, err := s.repo.ListTargets(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list scim targets: %w", err)
+	}
+	for _, t := range targets {
+		if !t.Enabled {
+			continue
+		}
+		if err := s.enqueue(ctx, tenantID, t.ID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) enqueue(ctx context.Context, tenantID, targetID, userID string) error {
+	now := time.Now()
+	op := &Operation{
+		ID:            id.NewUUIDv7(),
+		TargetID:      targetID,
+		TenantID:      tenantID,
+		UserID:        userID,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := s.repo.CreateOperation(ctx, op); err != nil {
+		return fmt.Errorf("failed to queue scim operation: %w", err)
+	}
+	return nil
+}
+
+// ProcessDue attempts every pending operation whose retry time has arrived.
+//
+// Purpose: Periodic delivery job for the scheduler.
+// Domain: Tenant
+// Security: Requests carry the target's bearer token; user data is resolved at send time.
+// Audited: No
+// Errors: System errors (individual delivery failures are recorded, not returned)
+func (s *Service) ProcessDue(ctx context.Context) error {
+	ops, err := s.repo.ListDueOperations(ctx, time.Now(), processBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list due scim operations: %w", err)
+	}
+
+	for _, op := range ops {
+		if err := s.attempt(ctx, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) attempt(ctx context.Context, op *Operation) error {
+	t, err := s.repo.GetTarget(ctx, op.TenantID, op.TargetID)
+	if err != nil && !errors.Is(err, ErrTargetNotFound) {
+		return fmt.Errorf("failed to load scim target: %w", err)
+	}
+
+	now := time.Now()
+	op.Attempts++
+	op.LastError = ""
+
+	if t == nil || !t.Enabled {
+		op.Status = StatusFailed
+		op.LastError = "target removed or disabled"
+		return s.repo.UpdateOperation(ctx, op)
+	}
+
+	code, syncErr := s.sync(ctx, t, op)
+	op.LastStatusCode = code
+
+	switch {
+	case syncErr == nil:
+		op.Status = StatusSucceeded
+		op.DeliveredAt = &now
+	case isPermanent(code), op.Attempts >= s.retry.MaxAttempts:
+		op.Status = StatusFailed
+	default:
+		op.NextAttemptAt = now.Add(s.retry.Backoff(op.Attempts))
+	}
+	if syncErr != nil {
+		op.LastError = syncErr.Error()
+	}
+
+	if err := s.repo.UpdateOperation(ctx, op); err != nil {
+		return fmt.Errorf("failed to record scim operation: %w", err)
+	}
+	return nil
+}
+
+// sync brings the user of op to its desired state on t and returns the last
+// response status. The desired state is "provisioned" while the user exists
+// and holds a role in the tenant, and "absent" otherwise.
+func (s *Service) sync(ctx context.Context, t *Target, op *Operation) (int, error) {
+	link, err := s.repo.GetLink(ctx, t.ID, op.UserID)
+	if err != nil && !errors.Is(err, ErrLinkNotFound) {
+		return 0, fmt.Errorf("failed to load scim link: %w", err)
+	}
+
+	u, err := s.users.GetByID(ctx, op.UserID)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		return 0, fmt.Errorf("failed to load user: %w", err)
+	}
+	member := false
+	if u != nil && u.DeletedAt == nil {
+		roles, err := s.roles.GetUserRoles(ctx, t.TenantID, op.UserID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load tenant roles: %w", err)
+		}
+		member = len(roles) > 0
+	}
+
+	if !member {
+		if link == nil {
+			op.Action = ActionNone
+			return 0, nil
+		}
+		op.Action = ActionDelete
+		code, _, err := s.call(ctx, t, "DELETE", "/Users/"+url.PathEscape(link.ExternalID), nil)
+		if err != nil {
+			return code, err
+		}
+		if !isSuccess(code) && code != statusNotFound {
+			return code, unexpectedStatus(code)
+		}
+		if err := s.repo.DeleteLink(ctx, t.ID, op.UserID); err != nil {
+			return code, fmt.Errorf("failed to delete scim link: %w", err)
+		}
+		return code, nil
+	}
+
+	body, err := json.Marshal(BuildUser(u, t.AttributeMapping))
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode scim user: %w", err)
+	}
+
+	if link != nil {
+		op.Action = ActionReplace
+		code, _, err := s.call(ctx, t, "PUT", "/Users/"+url.PathEscape(link.ExternalID), body)
+		if err != nil || code != statusNotFound {
+			return code, statusError(code, err)
+		}
+		// The resource was removed downstream; forget the link and recreate it.
+		if err := s.repo.DeleteLink(ctx, t.ID, op.UserID); err != nil {
+			return code, fmt.Errorf("failed to delete scim link: %w", err)
+		}
+	}
+
+	op.Action = ActionCreate
+	code, resp, err := s.call(ctx, t, "POST", "/Users", body)
+	if err != nil {
+		return code, err
+	}
+	var externalID string
+	switch {
+	case isSuccess(code):
+		externalID = resourceID(resp)
+	case code == statusConflict:
+		// Already provisioned (e.g. by a previous system); adopt it by userName.
+		var lookupErr error
+		code, externalID, lookupErr = s.findByUserName(ctx, t, BuildUser(u, t.AttributeMapping)["userName"])
+		if lookupErr != nil {
+			return code, lookupErr
+		}
+		op.Action = ActionReplace
+		code, _, err = s.call(ctx, t, "PUT", "/Users/"+url.PathEscape(externalID), body)
+		if err := statusError(code, err); err != nil {
+			return code, err
+		}
+	default:
+		return code, unexpectedStatus(code)
+	}
+	if externalID == "" {
+		return code, errors.New("target response did not include a resource id")
+	}
+
+	if err := s.repo.SaveLink(ctx, &Link{
+		TargetID:   t.ID,
+		TenantID:   t.TenantID,
+		UserID:     op.UserID,
+		ExternalID: externalID,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		return code, fmt.Errorf("failed to save scim link: %w", err)
+	}
+	return code, nil
+}
+
+func (s *Service) findByUserName(ctx context.Context, t *Target, userName any) (int, string, error) {
+	name, _ := userName.(string)
+	if name == "" {
+		return 0, "", errors.New("conflict on a user without userName")
+	}
+	filter := fmt.Sprintf(`userName eq "%s"`, strings.ReplaceAll(name, `"`, `\"`))
+	code, resp, err := s.call(ctx, t, "GET", "/Users?filter="+url.QueryEscape(filter), nil)
+	if err := statusError(code, err); err != nil {
+		return code, "", err
+	}
+
+	var list struct {
+		Resources []struct {
+			ID string `json:"id"`
+		} `json:"Resources"`
+	}
+	if err := json.Unmarshal(resp, &list); err != nil || len(list.Resources) != 1 {
+		return code, "", fmt.Errorf("conflicting user not found by userName")
+	}
+	return code, list.Resources[0].ID, nil
+}
+
+func (s *Service) call(ctx context.Context, t *Target, method, path string, body []byte) (int, []byte, error) {
+	headers := map[string]string{
+		"Authorization": "Bearer " + t.Token,
+		"Accept":        contentType,
+	}
+	if body != nil {
+		headers["Content-Type"] = contentType
+	}
+	return s.transport.Do(ctx, method, t.BaseURL+path, headers, body)
+}
+
+func resourceID(resp []byte) string {
+	var r struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(resp, &r)
+	return r.ID
+}
+
+func isSuccess(code int) bool {
+	return code >= 200 && code < 300
+}
+
+// isPermanent reports whether retrying cannot help: client errors other than
+// throttling and timeouts mean the request itself is wrong.
+func isPermanent(code int) bool {
+	return code >= 400 && code < 500 && code != statusTooManyRequests && code != statusRequestTimeout
+}
+
+func statusError(code int, err error) error {
+	if err != nil {
+		return err
+	}
+	if !isSuccess(code) {
+		return unexpectedStatus(code)
+	}
+	return nil
+}
+
+func unexpectedStatus(code int) error {
+	return fmt.Errorf("unexpected status %d", code)
+}
+
+func validateTarget(name, baseURL, token string, mapping map[string]string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTarget)
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("%w: base URL must be an absolute https URL", ErrInvalidTarget)
+	}
+	if token == "" {
+		return fmt.Errorf("%w: token is required", ErrInvalidTarget)
+	}
+	return validateMapping(mapping)
+}
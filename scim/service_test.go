@@ -0,0 +1,415 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+type mockRepo struct {
+	targets map[string]*Target
+	ops     []*Operation
+	links   map[string]*Link
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{targets: make(map[string]*Target), links: make(map[string]*Link)}
+}
+
+func (m *mockRepo) CreateTarget(ctx context.Context, t *Target) error {
+	cp := *t
+	m.targets[t.ID] = &cp
+	return nil
+}
+
+func (m *mockRepo) GetTarget(ctx context.Context, tenantID, id string) (*Target, error) {
+	t, ok := m.targets[id]
+	if !ok || t.TenantID != tenantID {
+		return nil, ErrTargetNotFound
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (m *mockRepo) ListTargets(ctx context.Context, tenantID string) ([]*Target, error) {
+	var res []*Target
+	for _, t := range m.targets {
+		if t.TenantID == tenantID {
+			cp := *t
+			res = append(res, &cp)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) UpdateTarget(ctx context.Context, t *Target) error {
+	cp := *t
+	m.targets[t.ID] = &cp
+	return nil
+}
+
+func (m *mockRepo) DeleteTarget(ctx context.Context, tenantID, id string) error {
+	if _, err := m.GetTarget(ctx, tenantID, id); err != nil {
+		return err
+	}
+	delete(m.targets, id)
+	return nil
+}
+
+func (m *mockRepo) CreateOperation(ctx context.Context, op *Operation) error {
+	m.ops = append(m.ops, op)
+	return nil
+}
+
+func (m *mockRepo) UpdateOperation(ctx context.Context, op *Operation) error {
+	return nil
+}
+
+func (m *mockRepo) ListDueOperations(ctx context.Context, now time.Time, limit int) ([]*Operation, error) {
+	var res []*Operation
+	for _, op := range m.ops {
+		if op.Status == StatusPending && !op.NextAttemptAt.After(now) {
+			res = append(res, op)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) ListOperations(ctx context.Context, tenantID, targetID string, limit int) ([]*Operation, error) {
+	return m.ops, nil
+}
+
+func (m *mockRepo) GetLink(ctx context.Context, targetID, userID string) (*Link, error) {
+	l, ok := m.links[targetID+"/"+userID]
+	if !ok {
+		return nil, ErrLinkNotFound
+	}
+	cp := *l
+	return &cp, nil
+}
+
+func (m *mockRepo) SaveLink(ctx context.Context, l *Link) error {
+	cp := *l
+	m.links[l.TargetID+"/"+l.UserID] = &cp
+	return nil
+}
+
+func (m *mockRepo) DeleteLink(ctx context.Context, targetID, userID string) error {
+	delete(m.links, targetID+"/"+userID)
+	return nil
+}
+
+func (m *mockRepo) ListLinksForUser(ctx context.Context, userID string) ([]*Link, error) {
+	var res []*Link
+	for _, l := range m.links {
+		if l.UserID == userID {
+			cp := *l
+			res = append(res, &cp)
+		}
+	}
+	return res, nil
+}
+
+type mockUserRepo struct {
+	user.UserRepository
+	users map[string]*user.User
+}
+
+func (m *mockUserRepo) GetByID(ctx context.Context, id string) (*user.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return u, nil
+}
+
+type mockRoleRepo struct {
+	tenant.RoleRepository
+	roles map[string][]string // userID -> roles in "t1"
+}
+
+func (m *mockRoleRepo) GetUserRoles(ctx context.Context, tenantID, userID string) ([]*tenant.TenantUserRole, error) {
+	var res []*tenant.TenantUserRole
+	for _, r := range m.roles[userID] {
+		res = append(res, &tenant.TenantUserRole{TenantID: tenantID, UserID: userID, Role: r})
+	}
+	return res, nil
+}
+
+func (m *mockRoleRepo) GetTenantUsers(ctx context.Context, tenantID string) ([]*tenant.TenantUserRole, error) {
+	var res []*tenant.TenantUserRole
+	for userID := range m.roles {
+		r, _ := m.GetUserRoles(ctx, tenantID, userID)
+		res = append(res, r...)
+	}
+	return res, nil
+}
+
+type request struct {
+	method string
+	url    string
+	body   map[string]any
+}
+
+// mockTransport answers from a fixed list of status codes, or behaves like a
+// minimal SCIM server when codes is empty.
+type mockTransport struct {
+	codes    []int
+	requests []request
+	existing string // ID returned by a userName filter search
+}
+
+func (m *mockTransport) Do(ctx context.Context, method, url string, headers map[string]string, body []byte) (int, []byte, error) {
+	req := request{method: method, url: url}
+	if body != nil {
+		_ = json.Unmarshal(body, &req.body)
+	}
+	m.requests = append(m.requests, req)
+
+	if len(m.codes) > 0 {
+		code := m.codes[0]
+		m.codes = m.codes[1:]
+		if code == 0 {
+			return 0, nil, errors.New("connection refused")
+		}
+		if method == "GET" {
+			return code, []byte(`{"Resources":[{"id":"` + m.existing + `"}]}`), nil
+		}
+		return code, []byte(`{"id":"ext-1"}`), nil
+	}
+
+	switch method {
+	case "POST":
+		return 201, []byte(`{"id":"ext-1"}`), nil
+	case "DELETE":
+		return 204, nil, nil
+	default:
+		return 200, nil, nil
+	}
+}
+
+type nopAuditLogger struct{}
+
+func (nopAuditLogger) Log(context.Context, audit.Event) {}
+
+func newTestService(t *testing.T, transport *mockTransport) (*Service, *mockRepo, *mockUserRepo, *mockRoleRepo, *Target) {
+	t.Helper()
+	email := "alice@example.com"
+	repo := newMockRepo()
+	users := &mockUserRepo{users: map[string]*user.User{
+		"u1": {ID: "u1", EmailPlain: &email, Profile: user.Profile{GivenName: "Alice", FamilyName: "Smith", FullName: "Alice Smith"}},
+	}}
+	roles := &mockRoleRepo{roles: map[string][]string{}}
+	svc := NewService(repo, users, roles, transport, nopAuditLogger{}, DefaultRetryPolicy())
+
+	target, err := svc.RegisterTarget(context.Background(), "t1", "CRM", "https://crm.example.com/scim/v2/", "tok", nil, "admin")
+	if err != nil {
+		t.Fatalf("RegisterTarget() error = %v", err)
+	}
+	return svc, repo, users, roles, target
+}
+
+func TestRegisterTargetValidation(t *testing.T) {
+	svc := NewService(newMockRepo(), &mockUserRepo{}, &mockRoleRepo{}, &mockTransport{}, nopAuditLogger{}, DefaultRetryPolicy())
+
+	tests := []struct {
+		name    string
+		baseURL string
+		token   string
+		mapping map[string]string
+		wantErr error
+	}{
+		{name: "valid", baseURL: "https://crm.example.com/scim/v2", token: "tok"},
+		{name: "valid mapping", baseURL: "https://crm.example.com", token: "tok", mapping: map[string]string{"userName": SourceID, "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber": SourceID}},
+		{name: "plain http rejected", baseURL: "http://crm.example.com", token: "tok", wantErr: ErrInvalidTarget},
+		{name: "credentials in url rejected", baseURL: "https://u:p@crm.example.com", token: "tok", wantErr: ErrInvalidTarget},
+		{name: "token required", baseURL: "https://crm.example.com", wantErr: ErrInvalidTarget},
+		{name: "unknown source field", baseURL: "https://crm.example.com", token: "tok", mapping: map[string]string{"userName": "password"}, wantErr: ErrInvalidTarget},
+		{name: "empty path segment", baseURL: "https://crm.example.com", token: "tok", mapping: map[string]string{"name..givenName": SourceGivenName}, wantErr: ErrInvalidTarget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := svc.RegisterTarget(context.Background(), "t1", "CRM", tt.baseURL, tt.token, tt.mapping, "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RegisterTarget() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && target.Token != "" {
+				t.Error("RegisterTarget() returned the token")
+			}
+		})
+	}
+}
+
+func TestProvisioningLifecycle(t *testing.T) {
+	ctx := context.Background()
+	transport := &mockTransport{}
+	svc, repo, users, roles, target := newTestService(t, transport)
+
+	// Membership provisions the user.
+	roles.roles["u1"] = []string{"tenant_member"}
+	if err := svc.HandleEvent(ctx, events.RoleAssigned{Meta: events.NewMeta("t1", "admin"), UserID: "u1", Role: "tenant_member"}); err != nil {
+		t.Fatalf("HandleEvent() error = %v", err)
+	}
+	if err := svc.ProcessDue(ctx); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	if got := transport.requests[0]; got.method != "POST" || got.url != "https://crm.example.com/scim/v2/Users" {
+		t.Fatalf("first request = %s %s, want POST .../Users", got.method, got.url)
+	}
+	if got := transport.requests[0].body["userName"]; got != "alice@example.com" {
+		t.Errorf("userName = %v, want alice@example.com", got)
+	}
+	if op := repo.ops[0]; op.Status != StatusSucceeded || op.Action != ActionCreate {
+		t.Errorf("operation = %s/%s, want succeeded/create", op.Status, op.Action)
+	}
+	if l, err := repo.GetLink(ctx, target.ID, "u1"); err != nil || l.ExternalID != "ext-1" {
+		t.Fatalf("link = %v, %v; want ext-1", l, err)
+	}
+
+	// Profile changes replace the linked resource.
+	users.users["u1"].Profile.Nickname = "Al"
+	if err := svc.HandleEvent(ctx, events.UserUpdated{Meta: events.NewMeta("", "u1"), UserID: "u1"}); err != nil {
+		t.Fatalf("HandleEvent() error = %v", err)
+	}
+	if err := svc.ProcessDue(ctx); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	if got := transport.requests[1]; got.method != "PUT" || !strings.HasSuffix(got.url, "/Users/ext-1") || got.body["nickName"] != "Al" {
+		t.Fatalf("second request = %s %s %v, want PUT .../Users/ext-1 with nickName", got.method, got.url, got.body)
+	}
+
+	// Losing the last role deprovisions the user.
+	roles.roles["u1"] = nil
+	if err := svc.HandleEvent(ctx, events.RoleRevoked{Meta: events.NewMeta("t1", "admin"), UserID: "u1", Role: "tenant_member"}); err != nil {
+		t.Fatalf("HandleEvent() error = %v", err)
+	}
+	if err := svc.ProcessDue(ctx); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	if got := transport.requests[2]; got.method != "DELETE" || !strings.HasSuffix(got.url, "/Users/ext-1") {
+		t.Fatalf("third request = %s %s, want DELETE .../Users/ext-1", got.method, got.url)
+	}
+	if _, err := repo.GetLink(ctx, target.ID, "u1"); !errors.Is(err, ErrLinkNotFound) {
+		t.Errorf("GetLink() error = %v, want ErrLinkNotFound", err)
+	}
+	if len(transport.requests) != 3 {
+		t.Errorf("requests = %d, want 3", len(transport.requests))
+	}
+}
+
+func TestProcessDueRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		wantStatus string
+	}{
+		{name: "server error is retried", code: 503, wantStatus: StatusPending},
+		{name: "throttling is retried", code: 429, wantStatus: StatusPending},
+		{name: "transport error is retried", code: 0, wantStatus: StatusPending},
+		{name: "client error is permanent", code: 400, wantStatus: StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			transport := &mockTransport{codes: []int{tt.code}}
+			svc, repo, _, roles, target := newTestService(t, transport)
+			roles.roles["u1"] = []string{"tenant_member"}
+			if err := svc.Resync(ctx, "t1", target.ID); err != nil {
+				t.Fatalf("Resync() error = %v", err)
+			}
+
+			if err := svc.ProcessDue(ctx); err != nil {
+				t.Fatalf("ProcessDue() error = %v", err)
+			}
+			op := repo.ops[0]
+			if op.Status != tt.wantStatus {
+				t.Fatalf("Status = %s, want %s", op.Status, tt.wantStatus)
+			}
+			if op.LastError == "" {
+				t.Error("LastError not recorded")
+			}
+			if tt.wantStatus == StatusPending && !op.NextAttemptAt.After(time.Now()) {
+				t.Error("NextAttemptAt not pushed into the future")
+			}
+		})
+	}
+}
+
+func TestConflictAdoptsExistingUser(t *testing.T) {
+	ctx := context.Background()
+	transport := &mockTransport{codes: []int{409, 200, 200}, existing: "legacy-7"}
+	svc, repo, _, roles, target := newTestService(t, transport)
+	roles.roles["u1"] = []string{"tenant_admin"}
+	if err := svc.Resync(ctx, "t1", target.ID); err != nil {
+		t.Fatalf("Resync() error = %v", err)
+	}
+
+	if err := svc.ProcessDue(ctx); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	if got := transport.requests[1]; got.method != "GET" || !strings.Contains(got.url, "filter=userName+eq+%22alice%40example.com%22") {
+		t.Fatalf("lookup request = %s %s", got.method, got.url)
+	}
+	if got := transport.requests[2]; got.method != "PUT" || !strings.HasSuffix(got.url, "/Users/legacy-7") {
+		t.Fatalf("adopt request = %s %s, want PUT .../Users/legacy-7", got.method, got.url)
+	}
+	if l, err := repo.GetLink(ctx, target.ID, "u1"); err != nil || l.ExternalID != "legacy-7" {
+		t.Fatalf("link = %v, %v; want legacy-7", l, err)
+	}
+	if repo.ops[0].Status != StatusSucceeded {
+		t.Errorf("Status = %s, want succeeded", repo.ops[0].Status)
+	}
+}
+
+func TestBuildUserMapping(t *testing.T) {
+	email := "alice@example.com"
+	u := &user.User{ID: "u1", EmailPlain: &email, Profile: user.Profile{GivenName: "Alice", Nickname: "Al"}}
+	enterprise := "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+
+	res := BuildUser(u, map[string]string{
+		"userName":                     SourceID,
+		"nickName":                     "",
+		enterprise + ":employeeNumber": SourceID,
+	})
+
+	if res["userName"] != "u1" {
+		t.Errorf("userName = %v, want u1", res["userName"])
+	}
+	if _, ok := res["nickName"]; ok {
+		t.Error("nickName not removed by empty mapping")
+	}
+	if res["name"].(map[string]any)["givenName"] != "Alice" {
+		t.Errorf("name.givenName = %v, want Alice", res["name"])
+	}
+	ext, ok := res[enterprise].(map[string]any)
+	if !ok || ext["employeeNumber"] != "u1" {
+		t.Errorf("enterprise extension = %v", res[enterprise])
+	}
+	if schemas := res["schemas"].([]string); len(schemas) != 2 || schemas[1] != enterprise {
+		t.Errorf("schemas = %v", schemas)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package search is the admin console type-ahead across a tenant's users,
+// clients and roles. One call returns every category the caller may see.
+package search
+
+import (
+	"context"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrNotPermitted  = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "not permitted to search this tenant")
+	ErrQueryTooShort = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "search query is too short")
+)
+
+const (
+	// DefaultLimit is the number of hits returned per category
+	DefaultLimit = 10
+	// MinQueryLength is the shortest query, in characters, that is searched
+	MinQueryLength = 2
+)
+
+// Result categories
+const (
+	CategoryUsers   = "users"
+	CategoryClients = "clients"
+	CategoryRoles   = "roles"
+)
+
+// UserHit is a tenant member whose name or nickname matched.
+type UserHit struct {
+	ID       string `json:"id"`
+	FullName string `json:"full_name,omitempty"`
+	Nickname string `json:"nickname,omitempty"`
+	Picture  string `json:"picture,omitempty"`
+}
+
+// ClientHit is a client of the tenant whose name or client_id matched.
+type ClientHit struct {
+	ID         string `json:"id"`
+	ClientID   string `json:"client_id"`
+	ClientName string `json:"client_name"`
+	LogoURI    string `json:"logo_uri,omitempty"`
+}
+
+// RoleHit is a tenant-scoped role whose name or description matched.
+type RoleHit struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Results are the hits of one search, grouped by category.
+//
+// Purpose: Typed response for the admin console type-ahead.
+// Domain: Tenant
+// Invariants: A category the caller may not see is omitted from Categories and its hits are nil.
+// Each category holds at most the service's per-category limit.
+type Results struct {
+	Query      string      `json:"query"`
+	Categories []string    `json:"categories"`
+	Users      []UserHit   `json:"users,omitempty"`
+	Clients    []ClientHit `json:"clients,omitempty"`
+	Roles      []RoleHit   `json:"roles,omitempty"`
+}
+
+// Repository defines the per-category search queries.
+//
+// Purpose: Case-insensitive substring matching over tenant resources.
+// Domain: Tenant
+type Repository interface {
+	// SearchUsers returns active members of the tenant whose name or nickname contains query
+	SearchUsers(ctx context.Context, tenantID, query string, limit int) ([]UserHit, error)
+	// SearchClients returns clients of the tenant whose name or client_id contains query
+	SearchClients(ctx context.Context, tenantID, query string, limit int) ([]ClientHit, error)
+	// SearchRoles returns tenant-scoped roles whose name or description contains query
+	SearchRoles(ctx context.Context, query string, limit int) ([]RoleHit, error)
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// PermissionChecker answers RBAC questions; authz.Service implements it.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error)
+}
+
+// categoryPermissions is the tenant permission required to see each category.
+var categoryPermissions = []struct {
+	category   string
+	permission string
+}{
+	{CategoryUsers, policy.PermTenantViewUsers},
+	{CategoryClients, policy.PermTenantManageClients},
+	{CategoryRoles, policy.PermTenantView},
+}
+
+// Service searches a tenant's resources.
+//
+// Purpose: Permission-filtered type-ahead for the tenant admin console.
+// Domain: Tenant
+type Service struct {
+	repo        Repository
+	permissions PermissionChecker
+	limit       int
+	tracer      tracing.Tracer
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithLimit returns up to n hits per category instead of DefaultLimit.
+func WithLimit(n int) Option {
+	return func(s *Service) { s.limit = n }
+}
+
+// WithTracer emits spans for search queries on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// NewService creates a new search service.
+//
+// Purpose: Constructor for the tenant search service.
+// Domain: Tenant
+// Audited: No
+// Errors: None
+func NewService(repo Repository, permissions PermissionChecker, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		permissions: permissions,
+		limit:       DefaultLimit,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Search returns the users, clients and roles of tenantID matching query, as seen by actorID.
+//
+// Purpose: Single call behind the admin console type-ahead.
+// Domain: Tenant
+// Security: Each category is searched only when the actor holds its permission in the tenant:
+// policy.PermTenantViewUsers for users, policy.PermTenantManageClients for clients and
+// policy.PermTenantView for roles. An actor holding none of them is refused.
+// Audited: No
+// Errors: ErrQueryTooShort, ErrNotPermitted, System errors
+func (s *Service) Search(ctx context.Context, tenantID, actorID, query string) (*Results, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "search.Search", tracing.String(tracing.AttrTenantID, tenantID))
+	defer span.End()
+
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < MinQueryLength {
+		return nil, ErrQueryTooShort
+	}
+
+	res := &Results{Query: query}
+	for _, cp := range categoryPermissions {
+		ok, err := s.can(ctx, actorID, tenantID, cp.permission)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		res.Categories = append(res.Categories, cp.category)
+
+		switch cp.category {
+		case CategoryUsers:
+			res.Users, err = s.repo.SearchUsers(ctx, tenantID, query, s.limit)
+		case CategoryClients:
+			res.Clients, err = s.repo.SearchClients(ctx, tenantID, query, s.limit)
+		case CategoryRoles:
+			res.Roles, err = s.repo.SearchRoles(ctx, query, s.limit)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", cp.category, err)
+		}
+	}
+	if len(res.Categories) == 0 {
+		return nil, ErrNotPermitted
+	}
+	return res, nil
+}
+
+func (s *Service) can(ctx context.Context, actorID, tenantID, permission string) (bool, error) {
+	actorID = requestctx.ResolveUserID(ctx, actorID)
+	if actorID == "" || s.permissions == nil {
+		return false, nil
+	}
+	ok, err := s.permissions.HasPermission(ctx, actorID, role.ScopeTenant, &tenantID, permission)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/policy"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type mockRepo struct {
+	users   []UserHit
+	clients []ClientHit
+	roles   []RoleHit
+	queried []string
+}
+
+func (m *mockRepo) SearchUsers(ctx context.Context, tenantID, query string, limit int) ([]UserHit, error) {
+	m.queried = append(m.queried, CategoryUsers)
+	return m.users[:min(limit, len(m.users))], nil
+}
+
+func (m *mockRepo) SearchClients(ctx context.Context, tenantID, query string, limit int) ([]ClientHit, error) {
+	m.queried = append(m.queried, CategoryClients)
+	return m.clients[:min(limit, len(m.clients))], nil
+}
+
+func (m *mockRepo) SearchRoles(ctx context.Context, query string, limit int) ([]RoleHit, error) {
+	m.queried = append(m.queried, CategoryRoles)
+	return m.roles[:min(limit, len(m.roles))], nil
+}
+
+type mockPermissions map[string][]string
+
+func (m mockPermissions) HasPermission(ctx context.Context, userID string, scope role.Scope, scopeContextID *string, permission string) (bool, error) {
+	return slices.Contains(m[userID], permission), nil
+}
+
+func TestSearch(t *testing.T) {
+	perms := mockPermissions{
+		"admin":   {policy.PermTenantView, policy.PermTenantViewUsers, policy.PermTenantManageClients},
+		"support": {policy.PermTenantViewUsers},
+		"viewer":  {policy.PermTenantView},
+	}
+
+	tests := []struct {
+		name           string
+		actor          string
+		query          string
+		wantErr        error
+		wantCategories []string
+	}{
+		{"admin sees every category", "admin", "al", nil, []string{CategoryUsers, CategoryClients, CategoryRoles}},
+		{"support sees users only", "support", "al", nil, []string{CategoryUsers}},
+		{"viewer sees roles only", "viewer", " al ", nil, []string{CategoryRoles}},
+		{"outsider", "stranger", "al", ErrNotPermitted, nil},
+		{"anonymous", "", "al", ErrNotPermitted, nil},
+		{"query too short", "admin", " a ", ErrQueryTooShort, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				users:   []UserHit{{ID: "u1", FullName: "Alice"}, {ID: "u2", FullName: "Alan"}, {ID: "u3", Nickname: "Al"}},
+				clients: []ClientHit{{ID: "c1", ClientID: "portal", ClientName: "Alpha Portal"}},
+				roles:   []RoleHit{{ID: role.RoleIDTenantAdmin, Name: role.RoleTenantAdmin}},
+			}
+			svc := NewService(repo, perms, WithLimit(2))
+
+			res, err := svc.Search(context.Background(), "t1", tt.actor, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Search() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !slices.Equal(res.Categories, tt.wantCategories) || !slices.Equal(repo.queried, tt.wantCategories) {
+				t.Errorf("categories = %v, queried = %v, want %v", res.Categories, repo.queried, tt.wantCategories)
+			}
+			if res.Query != "al" {
+				t.Errorf("query = %q, want trimmed %q", res.Query, "al")
+			}
+			if slices.Contains(tt.wantCategories, CategoryUsers) && len(res.Users) != 2 {
+				t.Errorf("users = %d, want limit 2", len(res.Users))
+			}
+			if !slices.Contains(tt.wantCategories, CategoryClients) && res.Clients != nil {
+				t.Errorf("clients = %v, want nil without permission", res.Clients)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// Change kinds
+const (
+	KindPermission = "permission"
+	KindRole       = "role"
+	KindClient     = "client"
+)
+
+// Change actions
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	// ActionUnmanaged marks database objects absent from the spec. They are reported, never deleted.
+	ActionUnmanaged = "unmanaged"
+)
+
+// Change is one difference between the spec and the database.
+type Change struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Report summarizes a Sync run.
+type Report struct {
+	DryRun  bool     `json:"dry_run"`
+	Changes []Change `json:"changes"`
+}
+
+// InSync reports whether the database already matched the spec.
+func (r *Report) InSync() bool {
+	for _, c := range r.Changes {
+		if c.Action != ActionUnmanaged {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Report) add(kind, name, action, detail string) {
+	r.Changes = append(r.Changes, Change{Kind: kind, Name: name, Action: action, Detail: detail})
+}
+
+// PermissionRepository defines persistence for the permission catalog.
+//
+// Purpose: Storage abstraction for permissions and role-permission links.
+// Domain: Authz
+type PermissionRepository interface {
+	// ListPermissions returns all permission names
+	ListPermissions(ctx context.Context) ([]string, error)
+	// CreatePermission adds a permission; existing names are ignored
+	CreatePermission(ctx context.Context, name string) error
+	// SetRolePermissions replaces a role's permissions with exactly permissions
+	SetRolePermissions(ctx context.Context, roleID string, permissions []string) error
+}
+
+// Service reconciles a Spec with the database.
+//
+// Purpose: Idempotent startup seeding and drift detection.
+// Domain: Authz
+// Invariants: Sync never deletes. Objects not in the spec are reported as unmanaged.
+type Service struct {
+	permissions PermissionRepository
+	roles       role.RoleRepository
+	clients     client.ClientRepository
+}
+
+// NewService creates a new seed reconciliation service.
+//
+// Purpose: Constructor for the seed service.
+// Domain: Authz
+// Audited: No
+// Errors: None
+func NewService(permissions PermissionRepository, roles role.RoleRepository, clients client.ClientRepository) *Service {
+	return &Service{
+		permissions: permissions,
+		roles:       roles,
+		clients:     clients,
+	}
+}
+
+// Sync brings the database in line with spec, or only reports drift when dryRun is set.
+//
+// Purpose: Replaces ad-hoc seeding with a declarative, repeatable reconciliation.
+// Domain: Authz
+// Security: Client secrets are only ever written as hashes supplied by the spec.
+// Audited: No
+// Errors: ErrInvalidSpec, System errors
+func (s *Service) Sync(ctx context.Context, spec *Spec, dryRun bool) (*Report, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: dryRun}
+	if err := s.syncPermissions(ctx, spec, report, dryRun); err != nil {
+		return nil, err
+	}
+	if err := s.syncRoles(ctx, spec, report, dryRun); err != nil {
+		return nil, err
+	}
+	if err := s.syncClients(ctx, spec, report, dryRun); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) syncPermissions(ctx context.Context, spec *Spec, report *Report, dryRun bool) error {
+	existing, err := s.permissions.ListPermissions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	want := spec.Permissions
+	if spec.usesWildcard() && !slices.Contains(want, WildcardPermission) {
+		want = append(slices.Clone(want), WildcardPermission)
+	}
+
+	for _, p := range want {
+		if slices.Contains(existing, p) {
+			continue
+		}
+		report.add(KindPermission, p, ActionCreate, "")
+		if !dryRun {
+			if err := s.permissions.CreatePermission(ctx, p); err != nil {
+				return fmt.Errorf("failed to create permission %s: %w", p, err)
+			}
+		}
+	}
+	for _, p := range existing {
+		if !slices.Contains(want, p) {
+			report.add(KindPermission, p, ActionUnmanaged, "")
+		}
+	}
+	return nil
+}
+
+func (s *Service) syncRoles(ctx context.Context, spec *Spec, report *Report, dryRun bool) error {
+	existing, err := s.roles.List(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list roles: %w", err)
+	}
+	byID := make(map[string]*role.Role, len(existing))
+	for _, r := range existing {
+		byID[r.ID] = r
+	}
+
+	managed := make(map[string]bool, len(spec.Roles))
+	for _, rs := range spec.Roles {
+		managed[rs.ID] = true
+		name := string(rs.Scope) + "/" + rs.Name
+
+		current, ok := byID[rs.ID]
+		if !ok {
+			report.add(KindRole, name, ActionCreate, "")
+			if dryRun {
+				continue
+			}
+			r := &role.Role{ID: rs.ID, Name: rs.Name, Scope: rs.Scope, Description: rs.Description, Permissions: rs.Permissions}
+			if err := s.roles.Create(ctx, r); err != nil {
+				return fmt.Errorf("failed to create role %s: %w", name, err)
+			}
+			continue
+		}
+
+		if current.Name != rs.Name || current.Scope != rs.Scope {
+			// Renames and scope moves change authorization semantics; surface, don't apply.
+			report.add(KindRole, name, ActionUpdate, fmt.Sprintf("identity differs: database has %s/%s (not applied)", current.Scope, current.Name))
+			continue
+		}
+
+		if current.Description != rs.Description {
+			report.add(KindRole, name, ActionUpdate, "description")
+			if !dryRun {
+				current.Description = rs.Description
+				if err := s.roles.Update(ctx, current); err != nil {
+					return fmt.Errorf("failed to update role %s: %w", name, err)
+				}
+			}
+		}
+
+		if !sameSet(current.Permissions, rs.Permissions) {
+			report.add(KindRole, name, ActionUpdate, fmt.Sprintf("permissions %v -> %v", sorted(current.Permissions), sorted(rs.Permissions)))
+			if !dryRun {
+				if err := s.permissions.SetRolePermissions(ctx, rs.ID, sorted(rs.Permissions)); err != nil {
+					return fmt.Errorf("failed to set permissions of role %s: %w", name, err)
+				}
+			}
+		}
+	}
+
+	for _, r := range existing {
+		if !managed[r.ID] {
+			report.add(KindRole, string(r.Scope)+"/"+r.Name, ActionUnmanaged, "")
+		}
+	}
+	return nil
+}
+
+func (s *Service) syncClients(ctx context.Context, spec *Spec, report *Report, dryRun bool) error {
+	for _, cs := range spec.Clients {
+		name := cs.TenantID + "/" + cs.ClientID
+
+		current, err := s.clients.GetByClientID(ctx, cs.TenantID, cs.ClientID)
+		if err != nil && !errors.Is(err, client.ErrClientNotFound) {
+			return fmt.Errorf("failed to get client %s: %w", name, err)
+		}
+
+		if current == nil {
+			report.add(KindClient, name, ActionCreate, "")
+			if dryRun {
+				continue
+			}
+			now := time.Now()
+			c := &client.Client{ID: id.NewUUIDv7(), IsActive: true, CreatedAt: now, UpdatedAt: now}
+			applyClientSpec(c, cs)
+			if err := s.clients.Create(ctx, c); err != nil {
+				return fmt.Errorf("failed to create client %s: %w", name, err)
+			}
+			continue
+		}
+
+		if diff := clientDrift(current, cs); diff != "" {
+			report.add(KindClient, name, ActionUpdate, diff)
+			if dryRun {
+				continue
+			}
+			applyClientSpec(current, cs)
+			current.UpdatedAt = time.Now()
+			if err := s.clients.Update(ctx, current); err != nil {
+				return fmt.Errorf("failed to update client %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Spec) usesWildcard() bool {
+	for _, r := range s.Roles {
+		if slices.Contains(r.Permissions, WildcardPermission) {
+			return true
+		}
+	}
+	return false
+}
+
+func applyClientSpec(c *client.Client, cs ClientSpec) {
+	c.TenantID = cs.TenantID
+	c.ClientID = cs.ClientID
+	c.ClientName = cs.Name
+	c.RedirectURIs = cs.RedirectURIs
+	c.AllowedScopes = cs.AllowedScopes
+	c.GrantTypes = cs.GrantTypes
+	c.ResponseTypes = cs.ResponseTypes
+	c.TokenEndpointAuthMethod = cs.TokenEndpointAuthMethod
+	c.IsTrusted = cs.Trusted
+	if cs.SecretHash != "" {
+		c.ClientSecretHash = cs.SecretHash
+	}
+}
+
+// clientDrift describes which managed fields of c differ from cs, or "" if none.
+func clientDrift(c *client.Client, cs ClientSpec) string {
+	var fields []string
+	if c.ClientName != cs.Name {
+		fields = append(fields, "name")
+	}
+	if !sameSet(c.RedirectURIs, cs.RedirectURIs) {
+		fields = append(fields, "redirect_uris")
+	}
+	if !sameSet(c.AllowedScopes, cs.AllowedScopes) {
+		fields = append(fields, "allowed_scopes")
+	}
+	if !sameSet(c.GrantTypes, cs.GrantTypes) {
+		fields = append(fields, "grant_types")
+	}
+	if !sameSet(c.ResponseTypes, cs.ResponseTypes) {
+		fields = append(fields, "response_types")
+	}
+	if c.TokenEndpointAuthMethod != cs.TokenEndpointAuthMethod {
+		fields = append(fields, "token_endpoint_auth_method")
+	}
+	if c.IsTrusted != cs.Trusted {
+		fields = append(fields, "trusted")
+	}
+	if cs.SecretHash != "" && c.ClientSecretHash != cs.SecretHash {
+		fields = append(fields, "secret")
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%v", fields)
+}
+
+func sameSet(a, b []string) bool {
+	return slices.Equal(sorted(a), sorted(b))
+}
+
+func sorted(s []string) []string {
+	out := slices.Clone(s)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
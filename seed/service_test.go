@@ -0,0 +1,220 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seed
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+type mockPermissionRepo struct {
+	names    []string
+	rolePerm map[string][]string
+}
+
+func (m *mockPermissionRepo) ListPermissions(ctx context.Context) ([]string, error) {
+	return m.names, nil
+}
+
+func (m *mockPermissionRepo) CreatePermission(ctx context.Context, name string) error {
+	m.names = append(m.names, name)
+	return nil
+}
+
+func (m *mockPermissionRepo) SetRolePermissions(ctx context.Context, roleID string, permissions []string) error {
+	m.rolePerm[roleID] = permissions
+	return nil
+}
+
+type mockRoleRepo struct {
+	role.RoleRepository
+	perms *mockPermissionRepo
+	roles map[string]*role.Role
+}
+
+func (m *mockRoleRepo) List(ctx context.Context, scope *role.Scope) ([]*role.Role, error) {
+	var res []*role.Role
+	for _, r := range m.roles {
+		cp := *r
+		cp.Permissions = m.perms.rolePerm[r.ID]
+		res = append(res, &cp)
+	}
+	return res, nil
+}
+
+func (m *mockRoleRepo) Create(ctx context.Context, r *role.Role) error {
+	m.roles[r.ID] = r
+	m.perms.rolePerm[r.ID] = r.Permissions
+	return nil
+}
+
+func (m *mockRoleRepo) Update(ctx context.Context, r *role.Role) error {
+	m.roles[r.ID].Description = r.Description
+	return nil
+}
+
+type mockClientRepo struct {
+	client.ClientRepository
+	clients map[string]*client.Client
+}
+
+func (m *mockClientRepo) GetByClientID(ctx context.Context, tenantID, clientID string) (*client.Client, error) {
+	c, ok := m.clients[tenantID+"/"+clientID]
+	if !ok {
+		return nil, client.ErrClientNotFound
+	}
+	return c, nil
+}
+
+func (m *mockClientRepo) Create(ctx context.Context, c *client.Client) error {
+	m.clients[c.TenantID+"/"+c.ClientID] = c
+	return nil
+}
+
+func (m *mockClientRepo) Update(ctx context.Context, c *client.Client) error {
+	m.clients[c.TenantID+"/"+c.ClientID] = c
+	return nil
+}
+
+func testSpec() *Spec {
+	return &Spec{
+		Permissions: []string{"tenant:view", "tenant:manage_users"},
+		Scopes:      []string{"api:read"},
+		Roles: []RoleSpec{
+			{ID: "r-admin", Name: "platform_admin", Scope: role.ScopePlatform, Permissions: []string{WildcardPermission}},
+			{ID: "r-member", Name: "tenant_member", Scope: role.ScopeTenant, Description: "Member", Permissions: []string{"tenant:view"}},
+		},
+		Clients: []ClientSpec{
+			{
+				TenantID: "t1", ClientID: "console", Name: "Console",
+				RedirectURIs:  []string{"https://console.example.com/callback"},
+				AllowedScopes: []string{"openid", "api:read"},
+				GrantTypes:    []string{"authorization_code"},
+				Trusted:       true,
+			},
+		},
+	}
+}
+
+func TestSyncIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	perms := &mockPermissionRepo{names: []string{"legacy:perm"}, rolePerm: map[string][]string{}}
+	roles := &mockRoleRepo{perms: perms, roles: map[string]*role.Role{}}
+	clients := &mockClientRepo{clients: map[string]*client.Client{}}
+	svc := NewService(perms, roles, clients)
+
+	dry, err := svc.Sync(ctx, testSpec(), true)
+	if err != nil {
+		t.Fatalf("Sync(dry run) error = %v", err)
+	}
+	if dry.InSync() || len(perms.names) != 1 || len(roles.roles) != 0 {
+		t.Fatalf("dry run must report but not apply: report=%+v perms=%v", dry, perms.names)
+	}
+
+	first, err := svc.Sync(ctx, testSpec(), false)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if first.InSync() {
+		t.Error("first Sync() reported no changes")
+	}
+	if len(roles.roles) != 2 || clients.clients["t1/console"] == nil {
+		t.Errorf("Sync() did not create roles/clients: roles=%d clients=%v", len(roles.roles), clients.clients)
+	}
+
+	second, err := svc.Sync(ctx, testSpec(), false)
+	if err != nil {
+		t.Fatalf("second Sync() error = %v", err)
+	}
+	if !second.InSync() {
+		t.Errorf("second Sync() changes = %+v, want none", second.Changes)
+	}
+
+	var unmanaged bool
+	for _, c := range second.Changes {
+		if c.Kind == KindPermission && c.Name == "legacy:perm" && c.Action == ActionUnmanaged {
+			unmanaged = true
+		}
+	}
+	if !unmanaged {
+		t.Error("expected legacy:perm to be reported as unmanaged")
+	}
+}
+
+func TestSyncCorrectsDrift(t *testing.T) {
+	ctx := context.Background()
+	perms := &mockPermissionRepo{rolePerm: map[string][]string{}}
+	roles := &mockRoleRepo{perms: perms, roles: map[string]*role.Role{}}
+	clients := &mockClientRepo{clients: map[string]*client.Client{}}
+	svc := NewService(perms, roles, clients)
+
+	if _, err := svc.Sync(ctx, testSpec(), false); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// Simulate manual edits in the database.
+	perms.rolePerm["r-member"] = []string{"tenant:view", "tenant:manage_users"}
+	clients.clients["t1/console"].RedirectURIs = []string{"https://evil.example.com/cb"}
+
+	report, err := svc.Sync(ctx, testSpec(), false)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	var roleDrift, clientDrift bool
+	for _, c := range report.Changes {
+		if c.Kind == KindRole && c.Action == ActionUpdate {
+			roleDrift = true
+		}
+		if c.Kind == KindClient && c.Action == ActionUpdate {
+			clientDrift = true
+		}
+	}
+	if !roleDrift || !clientDrift {
+		t.Errorf("drift not reported: %+v", report.Changes)
+	}
+	if got := perms.rolePerm["r-member"]; len(got) != 1 || got[0] != "tenant:view" {
+		t.Errorf("role permissions = %v, want [tenant:view]", got)
+	}
+	if got := clients.clients["t1/console"].RedirectURIs; got[0] != "https://console.example.com/callback" {
+		t.Errorf("redirect URIs = %v", got)
+	}
+}
+
+func TestSpecValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Spec)
+	}{
+		{name: "undeclared permission", mutate: func(s *Spec) { s.Roles[1].Permissions = []string{"nope"} }},
+		{name: "invalid scope", mutate: func(s *Spec) { s.Roles[0].Scope = "galaxy" }},
+		{name: "duplicate role", mutate: func(s *Spec) { s.Roles = append(s.Roles, s.Roles[0]) }},
+		{name: "undeclared client scope", mutate: func(s *Spec) { s.Clients[0].AllowedScopes = []string{"api:write"} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := testSpec()
+			tt.mutate(spec)
+			if err := spec.Validate(); !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("Validate() error = %v, want ErrInvalidSpec", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package seed reconciles a declarative description of roles, permissions,
+// scopes, and system clients with the database. Sync is idempotent: running it
+// on every startup creates what is missing, corrects what has drifted, and
+// reports anything in the database the spec does not manage.
+package seed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// ErrInvalidSpec is returned when a spec is internally inconsistent.
+var ErrInvalidSpec = errors.New("invalid seed spec")
+
+// WildcardPermission grants every permission.
+const WildcardPermission = "*"
+
+// Spec is the desired state.
+//
+// Purpose: Declarative source of truth for authorization catalog and system clients.
+// Domain: Authz
+// Invariants: Role permissions are declared in Permissions (or are the wildcard).
+// Client scopes are OIDC standard scopes or declared in Scopes.
+type Spec struct {
+	Permissions []string     `json:"permissions"`
+	Scopes      []string     `json:"scopes,omitempty"`
+	Roles       []RoleSpec   `json:"roles"`
+	Clients     []ClientSpec `json:"clients,omitempty"`
+}
+
+// RoleSpec is the desired state of one role.
+type RoleSpec struct {
+	// ID pins the role ID. Required so references (e.g. role.RoleIDTenantOwner) stay stable.
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Scope       role.Scope `json:"scope"`
+	Description string     `json:"description,omitempty"`
+	Permissions []string   `json:"permissions"`
+}
+
+// ClientSpec is the desired state of one system client.
+type ClientSpec struct {
+	TenantID                string   `json:"tenant_id"`
+	ClientID                string   `json:"client_id"`
+	Name                    string   `json:"name"`
+	RedirectURIs            []string `json:"redirect_uris,omitempty"`
+	AllowedScopes           []string `json:"allowed_scopes"`
+	GrantTypes              []string `json:"grant_types"`
+	ResponseTypes           []string `json:"response_types,omitempty"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	// SecretHash is the stored form of the client secret (client.HashClientSecret). Never the plaintext.
+	SecretHash string `json:"secret_hash,omitempty"`
+	Trusted    bool   `json:"trusted"`
+}
+
+// LoadSpec reads a JSON spec file. YAML is not supported directly to keep core
+// dependency-free; convert YAML to JSON (or build a Spec in Go) instead.
+//
+// Purpose: File-based spec for deployments that keep seed data in configuration.
+// Domain: Authz
+// Audited: No
+// Errors: File and decoding errors, ErrInvalidSpec
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed spec: %w", err)
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse seed spec: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks the spec for internal consistency.
+func (s *Spec) Validate() error {
+	roleKeys := make(map[string]bool)
+	for _, r := range s.Roles {
+		if r.ID == "" || r.Name == "" {
+			return fmt.Errorf("%w: role requires id and name", ErrInvalidSpec)
+		}
+		if r.Scope != role.ScopePlatform && r.Scope != role.ScopeTenant && r.Scope != role.ScopeClient {
+			return fmt.Errorf("%w: role %s has invalid scope %q", ErrInvalidSpec, r.Name, r.Scope)
+		}
+		key := string(r.Scope) + "/" + r.Name
+		if roleKeys[key] {
+			return fmt.Errorf("%w: duplicate role %s", ErrInvalidSpec, key)
+		}
+		roleKeys[key] = true
+
+		for _, p := range r.Permissions {
+			if p != WildcardPermission && !slices.Contains(s.Permissions, p) {
+				return fmt.Errorf("%w: role %s references undeclared permission %s", ErrInvalidSpec, r.Name, p)
+			}
+		}
+	}
+
+	clientKeys := make(map[string]bool)
+	for _, c := range s.Clients {
+		if c.TenantID == "" || c.ClientID == "" {
+			return fmt.Errorf("%w: client requires tenant_id and client_id", ErrInvalidSpec)
+		}
+		key := c.TenantID + "/" + c.ClientID
+		if clientKeys[key] {
+			return fmt.Errorf("%w: duplicate client %s", ErrInvalidSpec, key)
+		}
+		clientKeys[key] = true
+
+		for _, sc := range c.AllowedScopes {
+			if !client.OIDCScopes[sc] && !slices.Contains(s.Scopes, sc) {
+				return fmt.Errorf("%w: client %s references undeclared scope %s", ErrInvalidSpec, c.ClientID, sc)
+			}
+		}
+	}
+	return nil
+}
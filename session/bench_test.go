@@ -0,0 +1,135 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// benchSessions is the number of live sessions held by the benchmark repository.
+const benchSessions = 10000
+
+// memoryRepository is an in-memory Repository keyed by session ID.
+type memoryRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+func (m *memoryRepository) Create(ctx context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memoryRepository) Get(ctx context.Context, sessionID string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (m *memoryRepository) Update(ctx context.Context, session *Session) error {
+	return m.Create(ctx, session)
+}
+
+func (m *memoryRepository) Delete(ctx context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, sessionID)
+	return nil
+}
+
+func (m *memoryRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, s := range m.sessions {
+		if s.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepository) DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, s := range m.sessions {
+		if s.UserID == userID && id != keepSessionID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepository) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+// newBenchService returns a service over benchSessions live sessions and their IDs.
+func newBenchService(b *testing.B) (*Service, []string) {
+	b.Helper()
+	repo := &memoryRepository{sessions: make(map[string]*Session, benchSessions)}
+	svc := NewService(repo, 24*time.Hour, time.Hour)
+
+	tenantID := "t1"
+	ids := make([]string, benchSessions)
+	for i := range ids {
+		s, err := svc.Create(context.Background(), &tenantID, fmt.Sprintf("user-%d", i), "192.0.2.1", "bench", "auth")
+		if err != nil {
+			b.Fatalf("failed to create session: %v", err)
+		}
+		ids[i] = s.ID
+	}
+	return svc, ids
+}
+
+func BenchmarkGet(b *testing.B) {
+	svc, ids := newBenchService(b)
+	ctx := context.Background()
+
+	b.Run("serial", func(b *testing.B) {
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			if _, err := svc.Get(ctx, ids[i%len(ids)]); err != nil {
+				b.Fatalf("Get() error = %v", err)
+			}
+			i++
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				if _, err := svc.Get(ctx, ids[i%len(ids)]); err != nil {
+					b.Errorf("Get() error = %v", err)
+					return
+				}
+				i++
+			}
+		})
+	})
+}
@@ -20,6 +20,11 @@ import (
 	"encoding/base64"
 	"fmt"
 	"time"
+
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/metrics"
+	"github.com/opentrusty/opentrusty-core/tracing"
 )
 
 // Service provides session management business logic.
@@ -30,6 +35,34 @@ type Service struct {
 	repo        Repository
 	lifetime    time.Duration
 	idleTimeout time.Duration
+	metrics     *metrics.Metrics
+	tracer      tracing.Tracer
+	events      events.Publisher
+	clock       clock.Clock
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithMetrics records session creations on m.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *Service) { s.metrics = m }
+}
+
+// WithTracer emits spans for session creation and lookup on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithEvents publishes session creation and revocation events on p.
+func WithEvents(p events.Publisher) Option {
+	return func(s *Service) { s.events = p }
+}
+
+// WithClock reads the current time from c for expiry and idle checks.
+// Session IDs are bearer secrets and always come from crypto/rand.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
 }
 
 // NewService creates a new session service.
@@ -38,12 +71,17 @@ type Service struct {
 // Domain: Session
 // Audited: No
 // Errors: None
-func NewService(repo Repository, lifetime, idleTimeout time.Duration) *Service {
-	return &Service{
+func NewService(repo Repository, lifetime, idleTimeout time.Duration, opts ...Option) *Service {
+	s := &Service{
 		repo:        repo,
 		lifetime:    lifetime,
 		idleTimeout: idleTimeout,
+		clock:       clock.System(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // Create creates a new session for a user.
@@ -53,6 +91,10 @@ func NewService(repo Repository, lifetime, idleTimeout time.Duration) *Service {
 // Audited: No
 // Errors: System errors
 func (s *Service) Create(ctx context.Context, tenantID *string, userID, ipAddress, userAgent, namespace string) (*Session, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "session.Create", tracing.String(tracing.AttrUserID, userID))
+	defer span.End()
+
+	now := s.clock.Now()
 	session := &Session{
 		ID:         generateSessionID(),
 		TenantID:   tenantID,
@@ -60,33 +102,40 @@ func (s *Service) Create(ctx context.Context, tenantID *string, userID, ipAddres
 		IPAddress:  ipAddress,
 		UserAgent:  userAgent,
 		Namespace:  namespace,
-		ExpiresAt:  time.Now().Add(s.lifetime),
-		CreatedAt:  time.Now(),
-		LastSeenAt: time.Now(),
+		ExpiresAt:  now.Add(s.lifetime),
+		CreatedAt:  now,
+		LastSeenAt: now,
 	}
 
 	if err := s.repo.Create(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	s.metrics.SessionCreated()
+	events.Emit(ctx, s.events, events.SessionCreated{Meta: events.NewMeta(tenantOrEmpty(tenantID), userID), UserID: userID})
 
 	return session, nil
 }
 
 // Get retrieves and validates a session
 func (s *Service) Get(ctx context.Context, sessionID string) (*Session, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "session.Get")
+	defer span.End()
+
 	session, err := s.repo.Get(ctx, sessionID)
 	if err != nil {
 		return nil, ErrSessionNotFound
 	}
 
+	now := s.clock.Now()
+
 	// Check if session is expired
-	if session.IsExpired() {
+	if session.IsExpiredAt(now) {
 		s.repo.Delete(ctx, sessionID)
 		return nil, ErrSessionExpired
 	}
 
 	// Check if session is idle
-	if session.IsIdle(s.idleTimeout) {
+	if session.IsIdleAt(now, s.idleTimeout) {
 		s.repo.Delete(ctx, sessionID)
 		return nil, ErrSessionExpired
 	}
@@ -106,18 +155,42 @@ func (s *Service) Refresh(ctx context.Context, sessionID string) error {
 		return err
 	}
 
-	session.LastSeenAt = time.Now()
+	session.LastSeenAt = s.clock.Now()
 	return s.repo.Update(ctx, session)
 }
 
 // Destroy destroys a session
 func (s *Service) Destroy(ctx context.Context, sessionID string) error {
-	return s.repo.Delete(ctx, sessionID)
+	var sess *Session
+	if s.events != nil {
+		sess, _ = s.repo.Get(ctx, sessionID)
+	}
+	if err := s.repo.Delete(ctx, sessionID); err != nil {
+		return err
+	}
+	if sess != nil {
+		events.Emit(ctx, s.events, events.SessionRevoked{Meta: events.NewMeta(tenantOrEmpty(sess.TenantID), sess.UserID), UserID: sess.UserID})
+	}
+	return nil
 }
 
 // DestroyAllForUser destroys all sessions for a user
 func (s *Service) DestroyAllForUser(ctx context.Context, userID string) error {
-	return s.repo.DeleteByUserID(ctx, userID)
+	if err := s.repo.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	events.Emit(ctx, s.events, events.SessionRevoked{Meta: events.NewMeta("", ""), UserID: userID, All: true})
+	return nil
+}
+
+// DestroyOthersForUser destroys all sessions for a user except keepSessionID.
+// An empty keepSessionID destroys them all.
+func (s *Service) DestroyOthersForUser(ctx context.Context, userID, keepSessionID string) error {
+	if err := s.repo.DeleteOthersForUser(ctx, userID, keepSessionID); err != nil {
+		return err
+	}
+	events.Emit(ctx, s.events, events.SessionRevoked{Meta: events.NewMeta("", ""), UserID: userID, All: keepSessionID == ""})
+	return nil
 }
 
 // CleanupExpired removes all expired sessions
@@ -125,6 +198,13 @@ func (s *Service) CleanupExpired(ctx context.Context) error {
 	return s.repo.DeleteExpired(ctx)
 }
 
+func tenantOrEmpty(tenantID *string) string {
+	if tenantID == nil {
+		return ""
+	}
+	return *tenantID
+}
+
 // generateSessionID generates a cryptographically secure session ID
 func generateSessionID() string {
 	b := make([]byte, 32)
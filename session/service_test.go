@@ -0,0 +1,65 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/clock"
+)
+
+func TestGetExpiry(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		refreshes []time.Duration
+		at        time.Duration
+		wantErr   error
+	}{
+		{"fresh", nil, time.Minute, nil},
+		{"idle", nil, 31 * time.Minute, ErrSessionExpired},
+		{"kept alive", []time.Duration{20 * time.Minute}, 45 * time.Minute, nil},
+		{"past lifetime", []time.Duration{25 * time.Minute, 50 * time.Minute, 75 * time.Minute, 100 * time.Minute}, 2*time.Hour + time.Second, ErrSessionExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start
+			repo := &memoryRepository{sessions: make(map[string]*Session)}
+			svc := NewService(repo, 2*time.Hour, 30*time.Minute, WithClock(clock.Func(func() time.Time { return now })))
+
+			s, err := svc.Create(context.Background(), nil, "u1", "203.0.113.1", "test", "auth")
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if !s.CreatedAt.Equal(start) || !s.ExpiresAt.Equal(start.Add(2*time.Hour)) {
+				t.Errorf("session timestamps = %v..%v, want from the injected clock", s.CreatedAt, s.ExpiresAt)
+			}
+			for _, at := range tt.refreshes {
+				now = start.Add(at)
+				if err := svc.Refresh(context.Background(), s.ID); err != nil {
+					t.Fatalf("Refresh() error = %v", err)
+				}
+			}
+
+			now = start.Add(tt.at)
+			if _, err := svc.Get(context.Background(), s.ID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
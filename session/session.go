@@ -16,15 +16,22 @@ package session
 
 import (
 	"context"
-	"errors"
 	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
 )
 
 // Domain errors
 var (
-	ErrSessionNotFound = errors.New("session not found")
-	ErrSessionExpired  = errors.New("session expired")
-	ErrSessionInvalid  = errors.New("session invalid")
+	ErrSessionNotFound = apperror.New(apperror.CodeSessionExpired, apperror.StatusUnauthorized, apperror.OAuth2LoginRequired, "session not found")
+	ErrSessionExpired  = apperror.New(apperror.CodeSessionExpired, apperror.StatusUnauthorized, apperror.OAuth2LoginRequired, "session expired")
+	ErrSessionInvalid  = apperror.New(apperror.CodeSessionExpired, apperror.StatusUnauthorized, apperror.OAuth2LoginRequired, "session invalid")
+)
+
+// Session namespaces
+const (
+	NamespaceAuth  = "auth"
+	NamespaceAdmin = "admin"
 )
 
 // Session represents a user session.
@@ -46,12 +53,22 @@ type Session struct {
 
 // IsExpired checks if the session has expired
 func (s *Session) IsExpired() bool {
-	return time.Now().After(s.ExpiresAt)
+	return s.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt checks if the session has expired at now
+func (s *Session) IsExpiredAt(now time.Time) bool {
+	return now.After(s.ExpiresAt)
 }
 
 // IsIdle checks if the session has been idle for too long
 func (s *Session) IsIdle(idleTimeout time.Duration) bool {
-	return time.Since(s.LastSeenAt) > idleTimeout
+	return s.IsIdleAt(time.Now(), idleTimeout)
+}
+
+// IsIdleAt checks if the session has been idle for too long at now
+func (s *Session) IsIdleAt(now time.Time, idleTimeout time.Duration) bool {
+	return now.Sub(s.LastSeenAt) > idleTimeout
 }
 
 // Repository defines the interface for session persistence.
@@ -74,6 +91,9 @@ type Repository interface {
 	// DeleteByUserID deletes all sessions for a user
 	DeleteByUserID(ctx context.Context, userID string) error
 
+	// DeleteOthersForUser deletes all sessions for a user except keepSessionID
+	DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) error
+
 	// DeleteExpired deletes all expired sessions
 	DeleteExpired(ctx context.Context) error
 }
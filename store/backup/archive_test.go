@@ -0,0 +1,133 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+const (
+	tenantA = "00000000-0000-0000-0000-00000000000a"
+	userA   = "00000000-0000-0000-0000-0000000000a1"
+	roleA   = "00000000-0000-0000-0000-0000000000b1"
+	permA   = "00000000-0000-0000-0000-0000000000c1"
+)
+
+func testArchive(t *testing.T, overrides map[string]any) *Archive {
+	t.Helper()
+	rows := map[string]any{
+		"tenants":               []map[string]any{{"id": tenantA}},
+		"users":                 []map[string]any{{"id": userA}},
+		"credentials":           []map[string]any{{"user_id": userA, "password_hash": "$argon2id$..."}},
+		"identities":            []map[string]any{{"id": "i1", "user_id": userA}},
+		"rbac_permissions":      []map[string]any{{"id": permA}},
+		"rbac_roles":            []map[string]any{{"id": roleA}},
+		"rbac_role_permissions": []map[string]any{{"role_id": roleA, "permission_id": permA}},
+		"rbac_assignments": []map[string]any{
+			{"id": "a1", "user_id": userA, "role_id": roleA, "scope": "tenant", "scope_context_id": tenantA},
+			{"id": "a2", "user_id": userA, "role_id": roleA, "scope": "platform", "granted_by": nil},
+		},
+		"tenant_members": []map[string]any{{"id": "m1", "tenant_id": tenantA, "user_id": userA}},
+		"oauth2_clients": []map[string]any{{"id": "c1", "tenant_id": tenantA, "owner_id": nil}},
+	}
+	for k, v := range overrides {
+		rows[k] = v
+	}
+	a := &Archive{FormatVersion: FormatVersion, SchemaVersion: 37, CreatedAt: time.Unix(1700000000, 0).UTC(), Rows: map[string]json.RawMessage{}}
+	for k, v := range rows {
+		if v == nil {
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Rows[k] = raw
+	}
+	return a
+}
+
+func TestArchiveCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]any
+		wantErr   error
+	}{
+		{"consistent", nil, nil},
+		{"orphan credential", map[string]any{
+			"credentials": []map[string]any{{"user_id": "missing"}},
+		}, ErrInconsistent},
+		{"duplicate user", map[string]any{
+			"users": []map[string]any{{"id": userA}, {"id": userA}},
+		}, ErrInconsistent},
+		{"assignment to unknown tenant", map[string]any{
+			"rbac_assignments": []map[string]any{{"id": "a1", "user_id": userA, "role_id": roleA, "scope": "tenant", "scope_context_id": "missing"}},
+		}, ErrInconsistent},
+		{"client owned by unknown user", map[string]any{
+			"oauth2_clients": []map[string]any{{"id": "c1", "tenant_id": tenantA, "owner_id": "missing"}},
+		}, ErrInconsistent},
+		{"missing table", map[string]any{"tenant_members": nil}, ErrInvalidArchive},
+		{"malformed table", map[string]any{"users": "not rows"}, ErrInvalidArchive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testArchive(t, tt.overrides).Check()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	a := testArchive(t, nil)
+
+	data, err := Seal(key, a)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if bytes.Contains(data, []byte("argon2id")) {
+		t.Fatal("sealed archive contains plaintext credentials")
+	}
+
+	got, err := Open(key, data)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := got.Check(); err != nil {
+		t.Errorf("Check() after round trip error = %v", err)
+	}
+	counts, err := got.Counts()
+	if err != nil || counts["rbac_assignments"] != 2 || counts["users"] != 1 {
+		t.Errorf("Counts() = %v, %v", counts, err)
+	}
+
+	if _, err := Open(bytes.Repeat([]byte{8}, 32), data); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Open() with wrong key error = %v, want ErrInvalidArchive", err)
+	}
+	if _, err := Open(key[:16], data); !errors.Is(err, jose.ErrInvalidKey) {
+		t.Errorf("Open() with short key error = %v, want jose.ErrInvalidKey", err)
+	}
+	if _, err := Open(key, []byte("garbage")); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Open() of garbage error = %v, want ErrInvalidArchive", err)
+	}
+}
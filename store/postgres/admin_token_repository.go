@@ -0,0 +1,153 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/admintoken"
+)
+
+const adminTokenColumns = `id, token_hash, user_id, session_id, scope, scope_context_id::text, permissions, description, expires_at, created_at, revoked_at`
+
+// AdminTokenRepository implements admintoken.Repository
+type AdminTokenRepository struct {
+	db *DB
+}
+
+// NewAdminTokenRepository creates a new admin token repository
+func NewAdminTokenRepository(db *DB) *AdminTokenRepository {
+	return &AdminTokenRepository{db: db}
+}
+
+// Create stores a new token
+func (r *AdminTokenRepository) Create(ctx context.Context, t *admintoken.Token) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO admin_tokens (id, token_hash, user_id, session_id, scope, scope_context_id, permissions, description, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, t.ID, t.TokenHash, t.UserID, t.SessionID, t.Scope, t.ScopeContextID, nonNil(t.Permissions), t.Description, t.ExpiresAt, t.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create admin token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash returns the token with the given hash
+func (r *AdminTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*admintoken.Token, error) {
+	row := r.db.pool.QueryRow(ctx, `
+		SELECT `+adminTokenColumns+` FROM admin_tokens WHERE token_hash = $1
+	`, tokenHash)
+	return scanAdminToken(row)
+}
+
+// Get returns the token with the given ID
+func (r *AdminTokenRepository) Get(ctx context.Context, id string) (*admintoken.Token, error) {
+	row := r.db.pool.QueryRow(ctx, `
+		SELECT `+adminTokenColumns+` FROM admin_tokens WHERE id = $1
+	`, id)
+	return scanAdminToken(row)
+}
+
+// ListByUser returns the user's unexpired tokens, newest first
+func (r *AdminTokenRepository) ListByUser(ctx context.Context, userID string, now time.Time) ([]*admintoken.Token, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+adminTokenColumns+` FROM admin_tokens
+		WHERE user_id = $1 AND expires_at > $2
+		ORDER BY created_at DESC
+	`, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*admintoken.Token
+	for rows.Next() {
+		t, err := scanAdminToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list admin tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke marks the token revoked
+func (r *AdminTokenRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE admin_tokens SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1
+	`, id, revokedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to revoke admin token: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return admintoken.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeByUser revokes every active token of the user
+func (r *AdminTokenRepository) RevokeByUser(ctx context.Context, userID string, revokedAt time.Time) (int, error) {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE admin_tokens SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+	`, userID, revokedAt)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke admin tokens: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// DeleteExpired removes tokens that expired before now
+func (r *AdminTokenRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `
+		DELETE FROM admin_tokens WHERE expires_at < $1
+	`, now)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete expired admin tokens: %w", err)
+	}
+
+	return nil
+}
+
+// scanAdminToken reads one admin_tokens row selected with adminTokenColumns.
+func scanAdminToken(row pgx.Row) (*admintoken.Token, error) {
+	var t admintoken.Token
+	err := row.Scan(&t.ID, &t.TokenHash, &t.UserID, &t.SessionID, &t.Scope, &t.ScopeContextID,
+		&t.Permissions, &t.Description, &t.ExpiresAt, &t.CreatedAt, &t.RevokedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, admintoken.ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get admin token: %w", err)
+	}
+
+	return &t, nil
+}
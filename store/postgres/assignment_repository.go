@@ -39,7 +39,7 @@ func (r *AssignmentRepository) Grant(ctx context.Context, a *role.Assignment) er
 		grantedBy = nil
 	}
 
-	_, err := r.db.pool.Exec(ctx, `
+	err := r.db.execAttributed(ctx, `
 		INSERT INTO rbac_assignments (
 			id, user_id, role_id, scope, scope_context_id, granted_at, granted_by
 		) VALUES ($1, $2, $3, $4, $5, $6, $7)
@@ -71,7 +71,7 @@ func (r *AssignmentRepository) Revoke(ctx context.Context, userID, roleID string
 		args = []interface{}{userID, roleID, string(scope), *scopeContextID}
 	}
 
-	_, err := r.db.pool.Exec(ctx, query, args...)
+	err := r.db.execAttributed(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to revoke role: %w", err)
 	}
@@ -177,7 +177,7 @@ func (r *AssignmentRepository) CheckExists(ctx context.Context, roleID string, s
 
 // DeleteByContextID removes all assignments for a specific scope and context
 func (r *AssignmentRepository) DeleteByContextID(ctx context.Context, scope role.Scope, contextID string) error {
-	_, err := r.db.pool.Exec(ctx, `
+	err := r.db.execAttributed(ctx, `
 		DELETE FROM rbac_assignments
 		WHERE scope = $1 AND scope_context_id = $2
 	`, string(scope), contextID)
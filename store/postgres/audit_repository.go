@@ -45,9 +45,10 @@ func (r *AuditRepository) Log(ctx context.Context, event audit.Event) error {
 
 	_, err := r.db.pool.Exec(ctx, `
 		INSERT INTO audit_events (
-			id, type, tenant_id, actor_id, resource, target_name, target_id, ip_address, user_agent, metadata, created_at
+			id, type, tenant_id, actor_id, resource, target_name, target_id, ip_address, user_agent, metadata, created_at, trace_id,
+			severity, category, correlation_id
 		) VALUES (
-			gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, '')
 		)
 	`,
 		event.Type,
@@ -60,6 +61,10 @@ func (r *AuditRepository) Log(ctx context.Context, event audit.Event) error {
 		event.UserAgent,
 		event.Metadata,
 		event.Timestamp,
+		event.TraceID,
+		event.Severity,
+		event.Category,
+		event.CorrelationID,
 	)
 
 	if err != nil {
@@ -94,6 +99,30 @@ func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]audi
 		args = append(args, *filter.Type)
 		argIdx++
 	}
+	if filter.GrantID != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("e.metadata->>'grant_id' = $%d", argIdx))
+		args = append(args, *filter.GrantID)
+		argIdx++
+	}
+	if filter.MinSeverity != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("e.severity = ANY($%d)", argIdx))
+		var severities []string
+		for _, sev := range audit.SeveritiesFrom(*filter.MinSeverity) {
+			severities = append(severities, string(sev))
+		}
+		args = append(args, severities)
+		argIdx++
+	}
+	if filter.Category != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("e.category = $%d", argIdx))
+		args = append(args, *filter.Category)
+		argIdx++
+	}
+	if filter.CorrelationID != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("e.correlation_id = $%d", argIdx))
+		args = append(args, *filter.CorrelationID)
+		argIdx++
+	}
 	if filter.StartDate != nil {
 		whereClauses = append(whereClauses, fmt.Sprintf("e.created_at >= $%d", argIdx))
 		args = append(args, *filter.StartDate)
@@ -122,7 +151,8 @@ func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]audi
 	query := `
 		SELECT e.id, e.type, COALESCE(e.tenant_id, ''), COALESCE(e.actor_id, ''), 
                COALESCE(NULLIF(u.full_name, ''), NULLIF(u.email_plain, ''), e.actor_id, ''), e.resource, 
-               COALESCE(e.target_name, ''), COALESCE(e.target_id, ''), COALESCE(e.ip_address, ''), COALESCE(e.user_agent, ''), e.metadata, e.created_at
+               COALESCE(e.target_name, ''), COALESCE(e.target_id, ''), COALESCE(e.ip_address, ''), COALESCE(e.user_agent, ''), e.metadata, e.created_at,
+               COALESCE(e.trace_id, ''), e.severity, e.category, COALESCE(e.correlation_id, '')
 		FROM audit_events e
 		LEFT JOIN users u ON e.actor_id = u.id::text
 	` + whereSQL + fmt.Sprintf(" ORDER BY e.created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
@@ -142,6 +172,7 @@ func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]audi
 		if err := rows.Scan(
 			&e.ID, &e.Type, &e.TenantID, &e.ActorID, &e.ActorName, &e.Resource,
 			&e.TargetName, &e.TargetID, &e.IPAddress, &e.UserAgent, &e.Metadata, &e.Timestamp,
+			&e.TraceID, &e.Severity, &e.Category, &e.CorrelationID,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan audit event: %w", err)
 		}
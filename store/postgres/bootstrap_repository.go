@@ -0,0 +1,152 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/bootstrap"
+	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// BootstrapRepository implements bootstrap.Repository
+type BootstrapRepository struct {
+	db *DB
+}
+
+// NewBootstrapRepository creates a new bootstrap repository
+func NewBootstrapRepository(db *DB) *BootstrapRepository {
+	return &BootstrapRepository{db: db}
+}
+
+// GetState returns the current state (zero State if none was recorded)
+func (r *BootstrapRepository) GetState(ctx context.Context) (*bootstrap.State, error) {
+	var s bootstrap.State
+	var tokenHash, adminUserID *string
+
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT token_hash, token_expires_at, completed_at, admin_user_id::text
+		FROM bootstrap_state
+		WHERE id = 1
+	`).Scan(&tokenHash, &s.TokenExpiresAt, &s.CompletedAt, &adminUserID)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return &s, nil
+		}
+		return nil, fmt.Errorf("failed to get bootstrap state: %w", err)
+	}
+
+	if tokenHash != nil {
+		s.TokenHash = *tokenHash
+	}
+	if adminUserID != nil {
+		s.AdminUserID = *adminUserID
+	}
+	return &s, nil
+}
+
+// HasPlatformAdmin reports whether any platform admin assignment exists
+func (r *BootstrapRepository) HasPlatformAdmin(ctx context.Context) (bool, error) {
+	var exists bool
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM rbac_assignments
+			WHERE role_id = $1 AND scope = 'platform'
+		)
+	`, role.RoleIDPlatformAdmin).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check platform admin: %w", err)
+	}
+	return exists, nil
+}
+
+// SaveToken stores a new token hash; ErrAlreadyBootstrapped if completed
+func (r *BootstrapRepository) SaveToken(ctx context.Context, tokenHash string, expiresAt time.Time) error {
+	result, err := r.db.pool.Exec(ctx, `
+		INSERT INTO bootstrap_state (id, token_hash, token_expires_at)
+		VALUES (1, $1, $2)
+		ON CONFLICT (id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, token_expires_at = EXCLUDED.token_expires_at
+		WHERE bootstrap_state.completed_at IS NULL
+	`, tokenHash, expiresAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save bootstrap token: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return bootstrap.ErrAlreadyBootstrapped
+	}
+
+	return nil
+}
+
+// Complete consumes tokenHash and persists admin in one transaction
+func (r *BootstrapRepository) Complete(ctx context.Context, tokenHash string, admin bootstrap.Admin, completedAt time.Time) error {
+	tx, err := r.db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var completed bool
+	err = tx.QueryRow(ctx, `
+		SELECT completed_at IS NOT NULL FROM bootstrap_state WHERE id = 1 FOR UPDATE
+	`).Scan(&completed)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return bootstrap.ErrInvalidToken
+		}
+		return fmt.Errorf("failed to lock bootstrap state: %w", err)
+	}
+	if completed {
+		return bootstrap.ErrAlreadyBootstrapped
+	}
+
+	if err := insertUser(ctx, tx, admin.User); err != nil {
+		return err
+	}
+	if err := insertCredentials(ctx, tx, &user.Credentials{UserID: admin.User.ID, PasswordHash: admin.PasswordHash}); err != nil {
+		return err
+	}
+
+	a := admin.Assignment
+	_, err = tx.Exec(ctx, `
+		INSERT INTO rbac_assignments (id, user_id, role_id, scope, scope_context_id, granted_at, granted_by)
+		VALUES ($1, $2, $3, $4, NULL, $5, NULL)
+	`, a.ID, a.UserID, a.RoleID, string(a.Scope), a.GrantedAt)
+	if err != nil {
+		return fmt.Errorf("failed to grant platform admin: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE bootstrap_state
+		SET completed_at = $2, admin_user_id = $3, token_hash = NULL, token_expires_at = NULL
+		WHERE id = 1 AND token_hash = $1
+	`, tokenHash, completedAt, admin.User.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark bootstrap complete: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return bootstrap.ErrInvalidToken
+	}
+
+	return tx.Commit(ctx)
+}
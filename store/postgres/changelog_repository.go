@@ -0,0 +1,81 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/changelog"
+)
+
+// ChangeLogRepository implements changelog.Repository
+type ChangeLogRepository struct {
+	db *DB
+}
+
+// NewChangeLogRepository creates a new change log repository
+func NewChangeLogRepository(db *DB) *ChangeLogRepository {
+	return &ChangeLogRepository{db: db}
+}
+
+// Append stores a new entry
+func (r *ChangeLogRepository) Append(ctx context.Context, e *changelog.Entry) error {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal changes: %w", err)
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		INSERT INTO config_changes (id, tenant_id, resource, resource_id, actor_id, changes, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+	`, e.ID, e.TenantID, e.Resource, e.ResourceID, e.ActorID, changes, e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to append configuration change: %w", err)
+	}
+
+	return nil
+}
+
+// ListByResource returns the newest entries of one resource of a tenant, newest first
+func (r *ChangeLogRepository) ListByResource(ctx context.Context, tenantID, resource, resourceID string, limit int) ([]*changelog.Entry, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, tenant_id, resource, resource_id, COALESCE(actor_id, ''), changes, created_at
+		FROM config_changes
+		WHERE tenant_id = $1 AND resource = $2 AND resource_id = $3
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`, tenantID, resource, resourceID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configuration changes: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*changelog.Entry
+	for rows.Next() {
+		var e changelog.Entry
+		var changes []byte
+		if err := rows.Scan(&e.ID, &e.TenantID, &e.Resource, &e.ResourceID, &e.ActorID, &changes, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan configuration change: %w", err)
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal changes: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
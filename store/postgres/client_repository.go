@@ -57,6 +57,31 @@ func (r *ClientRepository) Create(ctx context.Context, c *client.Client) error {
 		return fmt.Errorf("failed to marshal response types: %w", err)
 	}
 
+	allowedOrigins, err := json.Marshal(nonNil(c.AllowedOrigins))
+	if err != nil {
+		return fmt.Errorf("failed to marshal allowed origins: %w", err)
+	}
+
+	contacts, err := json.Marshal(nonNil(c.Contacts))
+	if err != nil {
+		return fmt.Errorf("failed to marshal contacts: %w", err)
+	}
+
+	allowedCIDRs, err := json.Marshal(nonNil(c.AllowedCIDRs))
+	if err != nil {
+		return fmt.Errorf("failed to marshal allowed CIDRs: %w", err)
+	}
+
+	claimMapping, err := marshalClaimMapping(c.ClaimMapping)
+	if err != nil {
+		return err
+	}
+
+	resources, err := json.Marshal(nonNil(c.Resources))
+	if err != nil {
+		return fmt.Errorf("failed to marshal resources: %w", err)
+	}
+
 	var ownerID sql.NullString
 	if c.OwnerID != "" {
 		ownerID = sql.NullString{String: c.OwnerID, Valid: true}
@@ -69,31 +94,45 @@ func (r *ClientRepository) Create(ctx context.Context, c *client.Client) error {
 		c.UpdatedAt = c.CreatedAt
 	}
 
-	_, err = r.db.pool.Exec(ctx, `
+	result, err := r.db.pool.Exec(ctx, `
 		INSERT INTO oauth2_clients (
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
+			allowed_origins, application_type, contacts,
+			allowed_cidrs, dpop_bound_access_tokens, tls_client_certificate_bound_access_tokens, claim_mapping, id_token_signed_response_alg,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+			owner_id, is_trusted, is_active, created_at, updated_at, resources, registration_token_hash, password_grant_enabled, jwt_access_tokens, subject_type, sector_identifier
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE(NULLIF($13, ''), 'web'), $14,
+			$15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, COALESCE(NULLIF($33, ''), 'public'), $34)
+		ON CONFLICT (client_id) DO NOTHING
 	`,
 		c.ID, c.ClientID, c.TenantID, c.ClientSecretHash, c.ClientName, c.ClientURI, c.LogoURI,
 		redirectURIs, allowedScopes, grantTypes, responseTypes,
+		allowedOrigins, c.ApplicationType, contacts,
+		allowedCIDRs, c.DPoPBoundAccessTokens, c.TLSClientCertificateBoundAccessTokens, claimMapping, c.IDTokenSignedResponseAlg,
 		c.TokenEndpointAuthMethod, c.AccessTokenLifetime, c.RefreshTokenLifetime, c.IDTokenLifetime,
-		ownerID, c.IsTrusted, c.IsActive, c.CreatedAt, c.UpdatedAt,
+		ownerID, c.IsTrusted, c.IsActive, c.CreatedAt, c.UpdatedAt, resources, c.RegistrationTokenHash, c.PasswordGrantEnabled, c.JWTAccessTokens, c.SubjectType, c.SectorIdentifier,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
+	if result.RowsAffected() == 0 {
+		return &client.DuplicateClientError{TenantID: c.TenantID, ClientID: c.ClientID}
+	}
+
 	return nil
 }
 
 // GetByClientID retrieves a client by client_id and tenant_id
 func (r *ClientRepository) GetByClientID(ctx context.Context, tenantID string, clientID string) (*client.Client, error) {
+	if tenantID == "" {
+		return nil, client.ErrClientNotFound
+	}
+
 	var c client.Client
-	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON []byte
+	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, allowedOriginsJSON, contactsJSON, allowedCIDRsJSON, claimMappingJSON, resourcesJSON []byte
 	var clientURI, logoURI, ownerID sql.NullString
 	var deletedAt sql.NullTime
 
@@ -101,15 +140,19 @@ func (r *ClientRepository) GetByClientID(ctx context.Context, tenantID string, c
 		SELECT 
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
+			allowed_origins, application_type, contacts,
+			allowed_cidrs, dpop_bound_access_tokens, tls_client_certificate_bound_access_tokens, claim_mapping, id_token_signed_response_alg,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at
+			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at, resources, registration_token_hash, password_grant_enabled, jwt_access_tokens, subject_type, sector_identifier
 		FROM oauth2_clients
-		WHERE client_id = $2 AND ($1 = '' OR tenant_id::text = $1) AND deleted_at IS NULL
+		WHERE client_id = $2 AND tenant_id::text = $1 AND deleted_at IS NULL
 	`, tenantID, clientID).Scan(
 		&c.ID, &c.ClientID, &c.TenantID, &c.ClientSecretHash, &c.ClientName, &clientURI, &logoURI,
 		&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
+		&allowedOriginsJSON, &c.ApplicationType, &contactsJSON,
+		&allowedCIDRsJSON, &c.DPoPBoundAccessTokens, &c.TLSClientCertificateBoundAccessTokens, &claimMappingJSON, &c.IDTokenSignedResponseAlg,
 		&c.TokenEndpointAuthMethod, &c.AccessTokenLifetime, &c.RefreshTokenLifetime, &c.IDTokenLifetime,
-		&ownerID, &c.IsTrusted, &c.IsActive, &c.CreatedAt, &c.UpdatedAt, &deletedAt,
+		&ownerID, &c.IsTrusted, &c.IsActive, &c.CreatedAt, &c.UpdatedAt, &deletedAt, &resourcesJSON, &c.RegistrationTokenHash, &c.PasswordGrantEnabled, &c.JWTAccessTokens, &c.SubjectType, &c.SectorIdentifier,
 	)
 
 	if err != nil {
@@ -132,6 +175,21 @@ func (r *ClientRepository) GetByClientID(ctx context.Context, tenantID string, c
 	if err := json.Unmarshal(responseTypesJSON, &c.ResponseTypes); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response types: %w", err)
 	}
+	if err := json.Unmarshal(allowedOriginsJSON, &c.AllowedOrigins); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed origins: %w", err)
+	}
+	if err := json.Unmarshal(contactsJSON, &c.Contacts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal contacts: %w", err)
+	}
+	if err := json.Unmarshal(allowedCIDRsJSON, &c.AllowedCIDRs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed CIDRs: %w", err)
+	}
+	if c.ClaimMapping, err = unmarshalClaimMapping(claimMappingJSON); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(resourcesJSON, &c.Resources); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resources: %w", err)
+	}
 
 	if clientURI.Valid {
 		c.ClientURI = clientURI.String
@@ -152,7 +210,7 @@ func (r *ClientRepository) GetByClientID(ctx context.Context, tenantID string, c
 // GetByID retrieves a client by tenant_id and internal ID
 func (r *ClientRepository) GetByID(ctx context.Context, tenantID string, id string) (*client.Client, error) {
 	var c client.Client
-	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON []byte
+	var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, allowedOriginsJSON, contactsJSON, allowedCIDRsJSON, claimMappingJSON, resourcesJSON []byte
 	var ownerID sql.NullString
 	var deletedAt sql.NullTime
 
@@ -160,15 +218,19 @@ func (r *ClientRepository) GetByID(ctx context.Context, tenantID string, id stri
 		SELECT 
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
+			allowed_origins, application_type, contacts,
+			allowed_cidrs, dpop_bound_access_tokens, tls_client_certificate_bound_access_tokens, claim_mapping, id_token_signed_response_alg,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at
+			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at, resources, registration_token_hash, password_grant_enabled, jwt_access_tokens, subject_type, sector_identifier
 		FROM oauth2_clients
 		WHERE id = $2 AND tenant_id = $1 AND deleted_at IS NULL
 	`, tenantID, id).Scan(
 		&c.ID, &c.ClientID, &c.TenantID, &c.ClientSecretHash, &c.ClientName, &c.ClientURI, &c.LogoURI,
 		&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
+		&allowedOriginsJSON, &c.ApplicationType, &contactsJSON,
+		&allowedCIDRsJSON, &c.DPoPBoundAccessTokens, &c.TLSClientCertificateBoundAccessTokens, &claimMappingJSON, &c.IDTokenSignedResponseAlg,
 		&c.TokenEndpointAuthMethod, &c.AccessTokenLifetime, &c.RefreshTokenLifetime, &c.IDTokenLifetime,
-		&ownerID, &c.IsTrusted, &c.IsActive, &c.CreatedAt, &c.UpdatedAt, &deletedAt,
+		&ownerID, &c.IsTrusted, &c.IsActive, &c.CreatedAt, &c.UpdatedAt, &deletedAt, &resourcesJSON, &c.RegistrationTokenHash, &c.PasswordGrantEnabled, &c.JWTAccessTokens, &c.SubjectType, &c.SectorIdentifier,
 	)
 
 	if err != nil {
@@ -191,6 +253,21 @@ func (r *ClientRepository) GetByID(ctx context.Context, tenantID string, id stri
 	if err := json.Unmarshal(responseTypesJSON, &c.ResponseTypes); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response types: %w", err)
 	}
+	if err := json.Unmarshal(allowedOriginsJSON, &c.AllowedOrigins); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed origins: %w", err)
+	}
+	if err := json.Unmarshal(contactsJSON, &c.Contacts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal contacts: %w", err)
+	}
+	if err := json.Unmarshal(allowedCIDRsJSON, &c.AllowedCIDRs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed CIDRs: %w", err)
+	}
+	if c.ClaimMapping, err = unmarshalClaimMapping(claimMappingJSON); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(resourcesJSON, &c.Resources); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resources: %w", err)
+	}
 
 	if ownerID.Valid {
 		c.OwnerID = ownerID.String
@@ -224,6 +301,31 @@ func (r *ClientRepository) Update(ctx context.Context, c *client.Client) error {
 		return fmt.Errorf("failed to marshal response types: %w", err)
 	}
 
+	allowedOrigins, err := json.Marshal(nonNil(c.AllowedOrigins))
+	if err != nil {
+		return fmt.Errorf("failed to marshal allowed origins: %w", err)
+	}
+
+	contacts, err := json.Marshal(nonNil(c.Contacts))
+	if err != nil {
+		return fmt.Errorf("failed to marshal contacts: %w", err)
+	}
+
+	allowedCIDRs, err := json.Marshal(nonNil(c.AllowedCIDRs))
+	if err != nil {
+		return fmt.Errorf("failed to marshal allowed CIDRs: %w", err)
+	}
+
+	claimMapping, err := marshalClaimMapping(c.ClaimMapping)
+	if err != nil {
+		return err
+	}
+
+	resources, err := json.Marshal(nonNil(c.Resources))
+	if err != nil {
+		return fmt.Errorf("failed to marshal resources: %w", err)
+	}
+
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE oauth2_clients SET
 			client_name = $2,
@@ -239,6 +341,20 @@ func (r *ClientRepository) Update(ctx context.Context, c *client.Client) error {
 			id_token_lifetime = $12,
 			is_trusted = $13,
 			is_active = $14,
+			allowed_origins = $16,
+			application_type = COALESCE(NULLIF($17, ''), 'web'),
+			contacts = $18,
+			allowed_cidrs = $19,
+			dpop_bound_access_tokens = $20,
+			tls_client_certificate_bound_access_tokens = $21,
+			claim_mapping = $22,
+			id_token_signed_response_alg = $23,
+			resources = $24,
+			registration_token_hash = $25,
+			password_grant_enabled = $26,
+			jwt_access_tokens = $27,
+			subject_type = COALESCE(NULLIF($28, ''), 'public'),
+			sector_identifier = $29,
 			updated_at = NOW()
 		WHERE id = $1 AND tenant_id = $15 AND deleted_at IS NULL
 	`,
@@ -246,6 +362,9 @@ func (r *ClientRepository) Update(ctx context.Context, c *client.Client) error {
 		redirectURIs, allowedScopes, grantTypes, responseTypes,
 		c.TokenEndpointAuthMethod, c.AccessTokenLifetime, c.RefreshTokenLifetime, c.IDTokenLifetime,
 		c.IsTrusted, c.IsActive, c.TenantID,
+		allowedOrigins, c.ApplicationType, contacts,
+		allowedCIDRs, c.DPoPBoundAccessTokens, c.TLSClientCertificateBoundAccessTokens, claimMapping,
+		c.IDTokenSignedResponseAlg, resources, c.RegistrationTokenHash, c.PasswordGrantEnabled, c.JWTAccessTokens, c.SubjectType, c.SectorIdentifier,
 	)
 
 	if err != nil {
@@ -283,8 +402,10 @@ func (r *ClientRepository) ListByOwner(ctx context.Context, ownerID string) ([]*
 		SELECT 
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
+			allowed_origins, application_type, contacts,
+			allowed_cidrs, dpop_bound_access_tokens, tls_client_certificate_bound_access_tokens, claim_mapping, id_token_signed_response_alg,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at
+			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at, resources, registration_token_hash, password_grant_enabled, jwt_access_tokens, subject_type, sector_identifier
 		FROM oauth2_clients
 		WHERE owner_id = $1 AND deleted_at IS NULL
 	`, ownerID)
@@ -297,15 +418,17 @@ func (r *ClientRepository) ListByOwner(ctx context.Context, ownerID string) ([]*
 	var clients []*client.Client
 	for rows.Next() {
 		var c client.Client
-		var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON []byte
+		var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, allowedOriginsJSON, contactsJSON, allowedCIDRsJSON, claimMappingJSON, resourcesJSON []byte
 		var ownerID sql.NullString
 		var deletedAt sql.NullTime
 
 		err := rows.Scan(
 			&c.ID, &c.ClientID, &c.TenantID, &c.ClientSecretHash, &c.ClientName, &c.ClientURI, &c.LogoURI,
 			&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
+			&allowedOriginsJSON, &c.ApplicationType, &contactsJSON,
+			&allowedCIDRsJSON, &c.DPoPBoundAccessTokens, &c.TLSClientCertificateBoundAccessTokens, &claimMappingJSON, &c.IDTokenSignedResponseAlg,
 			&c.TokenEndpointAuthMethod, &c.AccessTokenLifetime, &c.RefreshTokenLifetime, &c.IDTokenLifetime,
-			&ownerID, &c.IsTrusted, &c.IsActive, &c.CreatedAt, &c.UpdatedAt, &deletedAt,
+			&ownerID, &c.IsTrusted, &c.IsActive, &c.CreatedAt, &c.UpdatedAt, &deletedAt, &resourcesJSON, &c.RegistrationTokenHash, &c.PasswordGrantEnabled, &c.JWTAccessTokens, &c.SubjectType, &c.SectorIdentifier,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan client: %w", err)
@@ -323,6 +446,21 @@ func (r *ClientRepository) ListByOwner(ctx context.Context, ownerID string) ([]*
 		if err := json.Unmarshal(responseTypesJSON, &c.ResponseTypes); err != nil {
 			continue
 		}
+		if err := json.Unmarshal(allowedOriginsJSON, &c.AllowedOrigins); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(contactsJSON, &c.Contacts); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(allowedCIDRsJSON, &c.AllowedCIDRs); err != nil {
+			continue
+		}
+		if c.ClaimMapping, err = unmarshalClaimMapping(claimMappingJSON); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(resourcesJSON, &c.Resources); err != nil {
+			continue
+		}
 
 		if ownerID.Valid {
 			c.OwnerID = ownerID.String
@@ -343,8 +481,10 @@ func (r *ClientRepository) ListByTenant(ctx context.Context, tenantID string) ([
 		SELECT 
 			id, client_id, tenant_id, client_secret_hash, client_name, client_uri, logo_uri,
 			redirect_uris, allowed_scopes, grant_types, response_types,
+			allowed_origins, application_type, contacts,
+			allowed_cidrs, dpop_bound_access_tokens, tls_client_certificate_bound_access_tokens, claim_mapping, id_token_signed_response_alg,
 			token_endpoint_auth_method, access_token_lifetime, refresh_token_lifetime, id_token_lifetime,
-			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at
+			owner_id, is_trusted, is_active, created_at, updated_at, deleted_at, resources, registration_token_hash, password_grant_enabled, jwt_access_tokens, subject_type, sector_identifier
 		FROM oauth2_clients
 		WHERE tenant_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -359,15 +499,17 @@ func (r *ClientRepository) ListByTenant(ctx context.Context, tenantID string) ([
 	var clients []*client.Client
 	for rows.Next() {
 		var c client.Client
-		var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON []byte
+		var redirectURIsJSON, allowedScopesJSON, grantTypesJSON, responseTypesJSON, allowedOriginsJSON, contactsJSON, allowedCIDRsJSON, claimMappingJSON, resourcesJSON []byte
 		var ownerID sql.NullString
 		var deletedAt sql.NullTime
 
 		err := rows.Scan(
 			&c.ID, &c.ClientID, &c.TenantID, &c.ClientSecretHash, &c.ClientName, &c.ClientURI, &c.LogoURI,
 			&redirectURIsJSON, &allowedScopesJSON, &grantTypesJSON, &responseTypesJSON,
+			&allowedOriginsJSON, &c.ApplicationType, &contactsJSON,
+			&allowedCIDRsJSON, &c.DPoPBoundAccessTokens, &c.TLSClientCertificateBoundAccessTokens, &claimMappingJSON, &c.IDTokenSignedResponseAlg,
 			&c.TokenEndpointAuthMethod, &c.AccessTokenLifetime, &c.RefreshTokenLifetime, &c.IDTokenLifetime,
-			&ownerID, &c.IsTrusted, &c.IsActive, &c.CreatedAt, &c.UpdatedAt, &deletedAt,
+			&ownerID, &c.IsTrusted, &c.IsActive, &c.CreatedAt, &c.UpdatedAt, &deletedAt, &resourcesJSON, &c.RegistrationTokenHash, &c.PasswordGrantEnabled, &c.JWTAccessTokens, &c.SubjectType, &c.SectorIdentifier,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan client: %w", err)
@@ -385,6 +527,21 @@ func (r *ClientRepository) ListByTenant(ctx context.Context, tenantID string) ([
 		if err := json.Unmarshal(responseTypesJSON, &c.ResponseTypes); err != nil {
 			continue
 		}
+		if err := json.Unmarshal(allowedOriginsJSON, &c.AllowedOrigins); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(contactsJSON, &c.Contacts); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(allowedCIDRsJSON, &c.AllowedCIDRs); err != nil {
+			continue
+		}
+		if c.ClaimMapping, err = unmarshalClaimMapping(claimMappingJSON); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(resourcesJSON, &c.Resources); err != nil {
+			continue
+		}
 
 		if ownerID.Valid {
 			c.OwnerID = ownerID.String
@@ -411,3 +568,35 @@ func (r *ClientRepository) DeleteByTenantID(ctx context.Context, tenantID string
 	}
 	return nil
 }
+
+// nonNil returns values, or an empty slice when it is nil, so JSON columns hold [] rather than null
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
+
+// marshalClaimMapping encodes m for the nullable claim_mapping column.
+func marshalClaimMapping(m *client.ClaimMapping) ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal claim mapping: %w", err)
+	}
+	return data, nil
+}
+
+// unmarshalClaimMapping decodes the nullable claim_mapping column.
+func unmarshalClaimMapping(data []byte) (*client.ClaimMapping, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var m client.ClaimMapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal claim mapping: %w", err)
+	}
+	return &m, nil
+}
@@ -44,16 +44,16 @@ func (r *AuthorizationCodeRepository) Create(c *client.AuthorizationCode) error
 
 	_, err := r.db.pool.Exec(ctx, `
 		INSERT INTO authorization_codes (
-			id, code, client_id, user_id, 
+			id, code, tenant_id, session_id, client_id, user_id, 
 			redirect_uri, scope, state, nonce,
 			code_challenge, code_challenge_method,
-			expires_at, used_at, is_used, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			expires_at, used_at, is_used, created_at, grant_id
+		) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NULLIF($17, '')::uuid)
 	`,
-		c.ID, c.Code, c.ClientID, c.UserID,
+		c.ID, c.Code, c.TenantID, c.SessionID, c.ClientID, c.UserID,
 		c.RedirectURI, c.Scope, c.State, c.Nonce,
 		c.CodeChallenge, c.CodeChallengeMethod,
-		c.ExpiresAt, usedAt, c.IsUsed, c.CreatedAt,
+		c.ExpiresAt, usedAt, c.IsUsed, c.CreatedAt, c.GrantID,
 	)
 
 	if err != nil {
@@ -63,8 +63,8 @@ func (r *AuthorizationCodeRepository) Create(c *client.AuthorizationCode) error
 	return nil
 }
 
-// GetByCode retrieves an authorization code
-func (r *AuthorizationCodeRepository) GetByCode(codeStr string) (*client.AuthorizationCode, error) {
+// GetByCode retrieves an authorization code issued in tenantID
+func (r *AuthorizationCodeRepository) GetByCode(tenantID, codeStr string) (*client.AuthorizationCode, error) {
 	ctx := context.Background()
 
 	var c client.AuthorizationCode
@@ -72,17 +72,17 @@ func (r *AuthorizationCodeRepository) GetByCode(codeStr string) (*client.Authori
 
 	err := r.db.pool.QueryRow(ctx, `
 		SELECT 
-			id, code, client_id, user_id, 
+			id, code, tenant_id, COALESCE(session_id, ''), client_id, user_id, 
 			redirect_uri, scope, state, nonce,
 			code_challenge, code_challenge_method,
-			expires_at, used_at, is_used, created_at
+			expires_at, used_at, is_used, created_at, COALESCE(grant_id::text, '')
 		FROM authorization_codes
-		WHERE code = $1
-	`, codeStr).Scan(
-		&c.ID, &c.Code, &c.ClientID, &c.UserID,
+		WHERE tenant_id = $1 AND code = $2
+	`, tenantID, codeStr).Scan(
+		&c.ID, &c.Code, &c.TenantID, &c.SessionID, &c.ClientID, &c.UserID,
 		&c.RedirectURI, &c.Scope, &c.State, &c.Nonce,
 		&c.CodeChallenge, &c.CodeChallengeMethod,
-		&c.ExpiresAt, &usedAt, &c.IsUsed, &c.CreatedAt,
+		&c.ExpiresAt, &usedAt, &c.IsUsed, &c.CreatedAt, &c.GrantID,
 	)
 
 	if err != nil {
@@ -99,13 +99,13 @@ func (r *AuthorizationCodeRepository) GetByCode(codeStr string) (*client.Authori
 	return &c, nil
 }
 
-// MarkAsUsed marks the code as used
+// MarkAsUsed marks the code as used; a second call fails with client.ErrCodeAlreadyUsed
 func (r *AuthorizationCodeRepository) MarkAsUsed(code string) error {
 	ctx := context.Background()
 
 	result, err := r.db.pool.Exec(ctx, `
 		UPDATE authorization_codes SET is_used = true, used_at = NOW()
-		WHERE code = $1
+		WHERE code = $1 AND is_used = false
 	`, code)
 
 	if err != nil {
@@ -113,6 +113,15 @@ func (r *AuthorizationCodeRepository) MarkAsUsed(code string) error {
 	}
 
 	if result.RowsAffected() == 0 {
+		var exists bool
+		if err := r.db.pool.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM authorization_codes WHERE code = $1)
+		`, code).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to mark code as used: %w", err)
+		}
+		if exists {
+			return client.ErrCodeAlreadyUsed
+		}
 		return client.ErrCodeNotFound
 	}
 
@@ -0,0 +1,212 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/consent"
+)
+
+// ConsentRepository implements consent.Repository
+type ConsentRepository struct {
+	db *DB
+}
+
+// NewConsentRepository creates a new consent repository
+func NewConsentRepository(db *DB) *ConsentRepository {
+	return &ConsentRepository{db: db}
+}
+
+// Get returns the grant of a user to a client
+func (r *ConsentRepository) Get(ctx context.Context, tenantID, userID, clientID string) (*consent.Grant, error) {
+	g, err := scanGrant(r.db.pool.QueryRow(ctx, `
+		SELECT tenant_id, user_id, client_id, scopes, created_at, updated_at, expires_at
+		FROM consent_grants
+		WHERE tenant_id = $1 AND user_id = $2 AND client_id = $3
+	`, tenantID, userID, clientID))
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consent.ErrGrantNotFound
+		}
+		return nil, fmt.Errorf("failed to get consent grant: %w", err)
+	}
+
+	return g, nil
+}
+
+// Save creates or replaces a grant
+func (r *ConsentRepository) Save(ctx context.Context, g *consent.Grant) error {
+	scopes, err := json.Marshal(nonNil(g.Scopes))
+	if err != nil {
+		return fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		INSERT INTO consent_grants (tenant_id, user_id, client_id, scopes, created_at, updated_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, user_id, client_id) DO UPDATE
+		SET scopes = EXCLUDED.scopes, created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at, expires_at = EXCLUDED.expires_at
+	`, g.TenantID, g.UserID, g.ClientID, scopes, g.CreatedAt, g.UpdatedAt, g.ExpiresAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save consent grant: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a grant
+func (r *ConsentRepository) Delete(ctx context.Context, tenantID, userID, clientID string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM consent_grants WHERE tenant_id = $1 AND user_id = $2 AND client_id = $3
+	`, tenantID, userID, clientID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete consent grant: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return consent.ErrGrantNotFound
+	}
+
+	return nil
+}
+
+// ListByUser returns every grant of a user in a tenant
+func (r *ConsentRepository) ListByUser(ctx context.Context, tenantID, userID string) ([]*consent.Grant, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT tenant_id, user_id, client_id, scopes, created_at, updated_at, expires_at
+		FROM consent_grants
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY updated_at DESC
+	`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consent grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []*consent.Grant
+	for rows.Next() {
+		g, err := scanGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan consent grant: %w", err)
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
+
+// CreateReceipt persists a consent receipt
+func (r *ConsentRepository) CreateReceipt(ctx context.Context, rc *consent.Receipt) error {
+	scopes, err := json.Marshal(nonNil(rc.Scopes))
+	if err != nil {
+		return fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		INSERT INTO consent_receipts (id, version, tenant_id, user_id, client_id, client_name, scopes, policy_version, collected_at, key_id, jws)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, rc.ID, rc.Version, rc.TenantID, rc.UserID, rc.ClientID, rc.ClientName, scopes, rc.PolicyVersion, rc.CollectedAt, rc.KeyID, rc.JWS)
+
+	if err != nil {
+		return fmt.Errorf("failed to create consent receipt: %w", err)
+	}
+
+	return nil
+}
+
+// GetReceipt returns a receipt of a tenant
+func (r *ConsentRepository) GetReceipt(ctx context.Context, tenantID, receiptID string) (*consent.Receipt, error) {
+	rc, err := scanReceipt(r.db.pool.QueryRow(ctx, `
+		SELECT `+receiptColumns+`
+		FROM consent_receipts
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, receiptID))
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, consent.ErrReceiptNotFound
+		}
+		return nil, fmt.Errorf("failed to get consent receipt: %w", err)
+	}
+
+	return rc, nil
+}
+
+// ListReceipts returns the receipts of a tenant collected in [since, until), optionally of one user
+func (r *ConsentRepository) ListReceipts(ctx context.Context, tenantID, userID string, since, until time.Time) ([]*consent.Receipt, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+receiptColumns+`
+		FROM consent_receipts
+		WHERE tenant_id = $1 AND ($2 = '' OR user_id::text = $2)
+			AND collected_at >= $3 AND collected_at < $4
+		ORDER BY collected_at, id
+	`, tenantID, userID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consent receipts: %w", err)
+	}
+	defer rows.Close()
+
+	var receipts []*consent.Receipt
+	for rows.Next() {
+		rc, err := scanReceipt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan consent receipt: %w", err)
+		}
+		receipts = append(receipts, rc)
+	}
+
+	return receipts, rows.Err()
+}
+
+const receiptColumns = `id, version, tenant_id, user_id, client_id, client_name, scopes, policy_version, collected_at, key_id, jws`
+
+func scanReceipt(row pgx.Row) (*consent.Receipt, error) {
+	var rc consent.Receipt
+	var scopesJSON []byte
+
+	if err := row.Scan(&rc.ID, &rc.Version, &rc.TenantID, &rc.UserID, &rc.ClientID, &rc.ClientName,
+		&scopesJSON, &rc.PolicyVersion, &rc.CollectedAt, &rc.KeyID, &rc.JWS); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopesJSON, &rc.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scopes: %w", err)
+	}
+	rc.CollectedAt = rc.CollectedAt.UTC()
+
+	return &rc, nil
+}
+
+func scanGrant(row pgx.Row) (*consent.Grant, error) {
+	var g consent.Grant
+	var scopesJSON []byte
+
+	if err := row.Scan(&g.TenantID, &g.UserID, &g.ClientID, &scopesJSON, &g.CreatedAt, &g.UpdatedAt, &g.ExpiresAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopesJSON, &g.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scopes: %w", err)
+	}
+
+	return &g, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/store/storetest"
+)
+
+// TestRepositoryContract runs the shared conformance suite against the reference implementation.
+func TestRepositoryContract(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) *storetest.Stores {
+		db, cleanup := SetupTestDB(t)
+		t.Cleanup(cleanup)
+		return &storetest.Stores{
+			Users:         NewUserRepository(db),
+			Tenants:       NewTenantRepository(db),
+			Clients:       NewClientRepository(db),
+			Codes:         NewAuthorizationCodeRepository(db),
+			AccessTokens:  NewAccessTokenRepository(db),
+			RefreshTokens: NewRefreshTokenRepository(db),
+		}
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/dashboard"
+)
+
+// DashboardRepository implements dashboard.Repository
+type DashboardRepository struct {
+	db *DB
+}
+
+// NewDashboardRepository creates a new dashboard repository
+func NewDashboardRepository(db *DB) *DashboardRepository {
+	return &DashboardRepository{db: db}
+}
+
+// Counts returns the tenant's headline numbers in a single round trip
+func (r *DashboardRepository) Counts(ctx context.Context, tenantID string, now time.Time) (*dashboard.Counts, error) {
+	var c dashboard.Counts
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM tenant_members m JOIN users u ON u.id = m.user_id
+				WHERE m.tenant_id = $1 AND u.deleted_at IS NULL),
+			(SELECT COUNT(*) FROM oauth2_clients
+				WHERE tenant_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM sessions
+				WHERE tenant_id = $1 AND expires_at > $2),
+			(SELECT COUNT(*) FROM tenant_members m JOIN users u ON u.id = m.user_id
+				WHERE m.tenant_id = $1 AND u.locked_until > $2 AND u.deleted_at IS NULL),
+			(SELECT COUNT(*) FROM recovery_requests
+				WHERE tenant_id = $1 AND status IN ('pending', 'approved') AND expires_at > $2)
+	`, tenantID, now).Scan(&c.Members, &c.Clients, &c.ActiveSessions, &c.LockedMembers, &c.PendingRecoveries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant resources: %w", err)
+	}
+	return &c, nil
+}
+
+// RecentEvents returns the newest events of the given types in the tenant.
+// Untenanted events (logins, lockouts) are included when their actor is a member.
+func (r *DashboardRepository) RecentEvents(ctx context.Context, tenantID string, types []string, limit int) ([]audit.Event, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT e.id, e.type, COALESCE(e.tenant_id, ''), COALESCE(e.actor_id, ''),
+			COALESCE(NULLIF(u.full_name, ''), NULLIF(u.email_plain, ''), e.actor_id, ''), e.resource,
+			COALESCE(e.target_name, ''), COALESCE(e.target_id, ''), COALESCE(e.ip_address, ''), COALESCE(e.user_agent, ''),
+			e.metadata, e.created_at, COALESCE(e.trace_id, '')
+		FROM audit_events e
+		LEFT JOIN users u ON e.actor_id = u.id::text
+		WHERE e.type = ANY($2)
+			AND (e.tenant_id = $1 OR (e.tenant_id IS NULL AND e.actor_id IN (
+				SELECT user_id::text FROM tenant_members WHERE tenant_id::text = $1)))
+		ORDER BY e.created_at DESC
+		LIMIT $3
+	`, tenantID, types, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent security events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []audit.Event
+	for rows.Next() {
+		var e audit.Event
+		if err := rows.Scan(
+			&e.ID, &e.Type, &e.TenantID, &e.ActorID, &e.ActorName, &e.Resource,
+			&e.TargetName, &e.TargetID, &e.IPAddress, &e.UserAgent, &e.Metadata, &e.Timestamp,
+			&e.TraceID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
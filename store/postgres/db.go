@@ -16,21 +16,62 @@ package postgres
 
 import (
 	"context"
-	_ "embed"
+	"embed"
 	"fmt"
+	"io/fs"
+	"sort"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/metrics"
+	"github.com/opentrusty/opentrusty-core/tracing"
 )
 
 //go:embed migrations/001_initial_schema.up.sql
 var InitialSchema string
 
+//go:embed migrations/*.up.sql
+var migrationFiles embed.FS
+
+// Migrations returns all embedded schema scripts in apply order.
+//
+// Purpose: Ordered access to the reference schema for tests and tooling.
+// Domain: Platform (Infrastructure)
+// Audited: No
+// Errors: Embedded filesystem read errors
+func Migrations() ([]string, error) {
+	names, err := fs.Glob(migrationFiles, "migrations/*.up.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	scripts := make([]string, 0, len(names))
+	for _, name := range names {
+		data, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		scripts = append(scripts, string(data))
+	}
+	return scripts, nil
+}
+
+// execer is satisfied by both the pool and a transaction, so insert helpers
+// can be shared between standalone writes and multi-statement transactions.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
 // DB wraps the PostgreSQL connection pool.
 //
 // Purpose: Primary handle for PostgreSQL database interactions.
 // Domain: Platform (Infrastructure)
 type DB struct {
-	pool *pgxpool.Pool
+	pool    *pgxpool.Pool
+	metrics *metrics.Metrics
+	events  events.Publisher
 }
 
 // Config holds database configuration.
@@ -46,6 +87,8 @@ type Config struct {
 	SSLMode      string
 	MaxOpenConns int
 	MaxIdleConns int
+	// Tracer, if set, records a span for every query.
+	Tracer tracing.Tracer
 }
 
 // New creates a new database connection.
@@ -77,6 +120,9 @@ func New(ctx context.Context, cfg Config) (*DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
+	if cfg.Tracer != nil {
+		poolConfig.ConnConfig.Tracer = &queryTracer{tracer: cfg.Tracer}
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -116,6 +162,13 @@ func (db *DB) Close() {
 	db.pool.Close()
 }
 
+// RegisterEvents publishes token issuance from the token repositories on p.
+// Token creation happens in the transport, so the store is the only place
+// core observes it.
+func (db *DB) RegisterEvents(p events.Publisher) {
+	db.events = p
+}
+
 // Pool returns the underlying connection pool
 func (db *DB) Pool() *pgxpool.Pool {
 	return db.pool
@@ -0,0 +1,137 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/delegation"
+)
+
+// DelegationRepository implements delegation.Repository
+type DelegationRepository struct {
+	db *DB
+}
+
+// NewDelegationRepository creates a new delegation agreement repository
+func NewDelegationRepository(db *DB) *DelegationRepository {
+	return &DelegationRepository{db: db}
+}
+
+// agreementColumns are the delegation_agreements columns scanAgreement reads
+const agreementColumns = `id, tenant_id, client_id, user_id, scopes, purpose, approved_by,
+	created_at, expires_at, last_used_at, revoked_at, COALESCE(revoked_by, '')`
+
+// Create stores a new agreement
+func (r *DelegationRepository) Create(ctx context.Context, a *delegation.Agreement) error {
+	scopes, err := json.Marshal(nonNil(a.Scopes))
+	if err != nil {
+		return fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		INSERT INTO delegation_agreements (id, tenant_id, client_id, user_id, scopes, purpose, approved_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, a.ID, a.TenantID, a.ClientID, a.UserID, scopes, a.Purpose, a.ApprovedBy, a.CreatedAt, a.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create delegation agreement: %w", err)
+	}
+	return nil
+}
+
+// Get returns an agreement of a tenant
+func (r *DelegationRepository) Get(ctx context.Context, tenantID, id string) (*delegation.Agreement, error) {
+	a, err := scanAgreement(r.db.pool.QueryRow(ctx, `
+		SELECT `+agreementColumns+`
+		FROM delegation_agreements
+		WHERE tenant_id::text = $1 AND id::text = $2
+	`, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, delegation.ErrAgreementNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegation agreement: %w", err)
+	}
+	return a, nil
+}
+
+// ListByUser returns the agreements of a user in a tenant, newest first
+func (r *DelegationRepository) ListByUser(ctx context.Context, tenantID, userID string) ([]*delegation.Agreement, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+agreementColumns+`
+		FROM delegation_agreements
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY created_at DESC, id DESC
+	`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegation agreements: %w", err)
+	}
+	defer rows.Close()
+
+	var agreements []*delegation.Agreement
+	for rows.Next() {
+		a, err := scanAgreement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delegation agreement: %w", err)
+		}
+		agreements = append(agreements, a)
+	}
+	return agreements, rows.Err()
+}
+
+// Revoke marks an unrevoked agreement revoked
+func (r *DelegationRepository) Revoke(ctx context.Context, tenantID, id, revokedBy string, at time.Time) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE delegation_agreements SET revoked_at = $3, revoked_by = NULLIF($4, '')
+		WHERE tenant_id = $1 AND id = $2 AND revoked_at IS NULL
+	`, tenantID, id, at, revokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to revoke delegation agreement: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return delegation.ErrAgreementNotFound
+	}
+	return nil
+}
+
+// MarkUsed records when tokens were last issued under an agreement
+func (r *DelegationRepository) MarkUsed(ctx context.Context, tenantID, id string, at time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `
+		UPDATE delegation_agreements SET last_used_at = $3
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark delegation agreement used: %w", err)
+	}
+	return nil
+}
+
+func scanAgreement(row pgx.Row) (*delegation.Agreement, error) {
+	var a delegation.Agreement
+	var scopesJSON []byte
+	if err := row.Scan(&a.ID, &a.TenantID, &a.ClientID, &a.UserID, &scopesJSON, &a.Purpose, &a.ApprovedBy,
+		&a.CreatedAt, &a.ExpiresAt, &a.LastUsedAt, &a.RevokedAt, &a.RevokedBy); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopesJSON, &a.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scopes: %w", err)
+	}
+	return &a, nil
+}
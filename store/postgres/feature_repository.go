@@ -0,0 +1,111 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/feature"
+)
+
+// FeatureRepository implements feature.Repository
+type FeatureRepository struct {
+	db *DB
+}
+
+// NewFeatureRepository creates a new feature flag repository
+func NewFeatureRepository(db *DB) *FeatureRepository {
+	return &FeatureRepository{db: db}
+}
+
+// ListOverrides returns every override of a tenant
+func (r *FeatureRepository) ListOverrides(ctx context.Context, tenantID string) ([]*feature.Override, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT tenant_id, flag, enabled, COALESCE(updated_by, ''), updated_at
+		FROM tenant_feature_flags
+		WHERE tenant_id = $1
+		ORDER BY flag
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*feature.Override
+	for rows.Next() {
+		var o feature.Override
+		if err := rows.Scan(&o.TenantID, &o.Flag, &o.Enabled, &o.UpdatedBy, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag override: %w", err)
+		}
+		overrides = append(overrides, &o)
+	}
+
+	return overrides, rows.Err()
+}
+
+// GetOverride returns a tenant's override of one flag
+func (r *FeatureRepository) GetOverride(ctx context.Context, tenantID string, f feature.Flag) (*feature.Override, error) {
+	var o feature.Override
+
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT tenant_id, flag, enabled, COALESCE(updated_by, ''), updated_at
+		FROM tenant_feature_flags
+		WHERE tenant_id = $1 AND flag = $2
+	`, tenantID, f).Scan(&o.TenantID, &o.Flag, &o.Enabled, &o.UpdatedBy, &o.UpdatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, feature.ErrOverrideNotFound
+		}
+		return nil, fmt.Errorf("failed to get feature flag override: %w", err)
+	}
+
+	return &o, nil
+}
+
+// SetOverride creates or replaces an override
+func (r *FeatureRepository) SetOverride(ctx context.Context, o *feature.Override) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO tenant_feature_flags (tenant_id, flag, enabled, updated_by, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (tenant_id, flag) DO UPDATE
+		SET enabled = EXCLUDED.enabled, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, o.TenantID, o.Flag, o.Enabled, o.UpdatedBy, o.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to set feature flag override: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOverride removes an override
+func (r *FeatureRepository) DeleteOverride(ctx context.Context, tenantID string, f feature.Flag) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM tenant_feature_flags WHERE tenant_id = $1 AND flag = $2
+	`, tenantID, f)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag override: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return feature.ErrOverrideNotFound
+	}
+
+	return nil
+}
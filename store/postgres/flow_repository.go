@@ -0,0 +1,234 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/flow"
+)
+
+// FlowRepository implements flow.Repository
+type FlowRepository struct {
+	db *DB
+}
+
+// NewFlowRepository creates a new login flow repository
+func NewFlowRepository(db *DB) *FlowRepository {
+	return &FlowRepository{db: db}
+}
+
+// Create persists a new flow
+func (r *FlowRepository) Create(ctx context.Context, f *flow.Flow) error {
+	steps, amr, data, err := marshalFlow(f)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		INSERT INTO login_flows (
+			id, tenant_id, client_id, user_id, state, steps, amr, data, failure_reason,
+			version, step_expires_at, expires_at, created_at, updated_at
+		) VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`,
+		f.ID, f.TenantID, f.ClientID, f.UserID, f.State, steps, amr, data, f.FailureReason,
+		f.Version, f.StepExpiresAt, f.ExpiresAt, f.CreatedAt, f.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create login flow: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns a flow of a tenant
+func (r *FlowRepository) Get(ctx context.Context, tenantID, id string) (*flow.Flow, error) {
+	var f flow.Flow
+	var stepsJSON, amrJSON, dataJSON []byte
+
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT id, tenant_id, client_id, COALESCE(user_id::text, ''), state, steps, amr, data, failure_reason,
+			version, step_expires_at, expires_at, created_at, updated_at
+		FROM login_flows
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id).Scan(
+		&f.ID, &f.TenantID, &f.ClientID, &f.UserID, &f.State, &stepsJSON, &amrJSON, &dataJSON, &f.FailureReason,
+		&f.Version, &f.StepExpiresAt, &f.ExpiresAt, &f.CreatedAt, &f.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, flow.ErrFlowNotFound
+		}
+		return nil, fmt.Errorf("failed to get login flow: %w", err)
+	}
+
+	if err := json.Unmarshal(stepsJSON, &f.Steps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal steps: %w", err)
+	}
+	if err := json.Unmarshal(amrJSON, &f.AMR); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal amr: %w", err)
+	}
+	if err := json.Unmarshal(dataJSON, &f.Data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return &f, nil
+}
+
+// Update saves f if its stored version still equals f.Version, then increments f.Version
+func (r *FlowRepository) Update(ctx context.Context, f *flow.Flow) error {
+	steps, amr, data, err := marshalFlow(f)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE login_flows SET
+			user_id = NULLIF($3, '')::uuid,
+			state = $4,
+			steps = $5,
+			amr = $6,
+			data = $7,
+			failure_reason = $8,
+			step_expires_at = $9,
+			updated_at = $10,
+			version = version + 1
+		WHERE tenant_id = $1 AND id = $2 AND version = $11
+	`,
+		f.TenantID, f.ID, f.UserID, f.State, steps, amr, data, f.FailureReason,
+		f.StepExpiresAt, f.UpdatedAt, f.Version,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update login flow: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return flow.ErrConcurrentUpdate
+	}
+
+	f.Version++
+	return nil
+}
+
+// DeleteExpired removes flows whose expires_at is before now
+func (r *FlowRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `
+		DELETE FROM login_flows WHERE expires_at < $1
+	`, now)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete expired login flows: %w", err)
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		DELETE FROM pending_authorizations WHERE expires_at < $1
+	`, now)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete expired pending authorizations: %w", err)
+	}
+
+	return nil
+}
+
+// CreateAuthorization persists a pending authorization
+func (r *FlowRepository) CreateAuthorization(ctx context.Context, a *flow.PendingAuthorization) error {
+	params, err := marshalParams(a.Params)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		INSERT INTO pending_authorizations (handle, tenant_id, client_id, params, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, a.Handle, a.TenantID, a.ClientID, params, a.ExpiresAt, a.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create pending authorization: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuthorization returns a pending authorization of a tenant
+func (r *FlowRepository) GetAuthorization(ctx context.Context, tenantID, handle string) (*flow.PendingAuthorization, error) {
+	return r.scanAuthorization(r.db.pool.QueryRow(ctx, `
+		SELECT handle, tenant_id, client_id, params, expires_at, created_at
+		FROM pending_authorizations
+		WHERE tenant_id = $1 AND handle = $2
+	`, tenantID, handle))
+}
+
+// ConsumeAuthorization deletes and returns a pending authorization in one statement
+func (r *FlowRepository) ConsumeAuthorization(ctx context.Context, tenantID, handle string) (*flow.PendingAuthorization, error) {
+	return r.scanAuthorization(r.db.pool.QueryRow(ctx, `
+		DELETE FROM pending_authorizations
+		WHERE tenant_id = $1 AND handle = $2
+		RETURNING handle, tenant_id, client_id, params, expires_at, created_at
+	`, tenantID, handle))
+}
+
+// scanAuthorization reads one pending authorization row.
+func (r *FlowRepository) scanAuthorization(row pgx.Row) (*flow.PendingAuthorization, error) {
+	var a flow.PendingAuthorization
+	var paramsJSON []byte
+
+	err := row.Scan(&a.Handle, &a.TenantID, &a.ClientID, &paramsJSON, &a.ExpiresAt, &a.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, flow.ErrAuthorizationNotFound
+		}
+		return nil, fmt.Errorf("failed to get pending authorization: %w", err)
+	}
+
+	if err := json.Unmarshal(paramsJSON, &a.Params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal params: %w", err)
+	}
+
+	return &a, nil
+}
+
+// marshalFlow encodes the JSONB columns of f.
+func marshalFlow(f *flow.Flow) (steps, amr, data []byte, err error) {
+	if steps, err = json.Marshal(nonNil(f.Steps)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal steps: %w", err)
+	}
+	if amr, err = json.Marshal(nonNil(f.AMR)); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal amr: %w", err)
+	}
+	if data, err = marshalParams(f.Data); err != nil {
+		return nil, nil, nil, err
+	}
+	return steps, amr, data, nil
+}
+
+// marshalParams encodes a string map as a JSONB object, never null.
+func marshalParams(m map[string]string) ([]byte, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/grant"
+)
+
+// GrantRepository implements grant.Repository
+type GrantRepository struct {
+	db *DB
+}
+
+// NewGrantRepository creates a new grant repository
+func NewGrantRepository(db *DB) *GrantRepository {
+	return &GrantRepository{db: db}
+}
+
+// ListActive returns the unrevoked, unexpired tokens of a user, newest first
+func (r *GrantRepository) ListActive(ctx context.Context, tenantID, userID string) ([]*grant.Token, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT t.id, 'access', t.tenant_id, t.user_id, t.client_id, COALESCE(c.client_name, ''),
+			COALESCE(t.grant_id::text, ''), COALESCE(t.scope, ''), t.created_at, t.expires_at
+		FROM access_tokens t
+		LEFT JOIN oauth2_clients c ON c.client_id = t.client_id
+		WHERE t.tenant_id = $1 AND t.user_id = $2 AND t.is_revoked = false AND t.expires_at > NOW()
+		UNION ALL
+		SELECT t.id, 'refresh', t.tenant_id, t.user_id, t.client_id, COALESCE(c.client_name, ''),
+			COALESCE(t.grant_id::text, ''), COALESCE(t.scope, ''), t.created_at, t.expires_at
+		FROM refresh_tokens t
+		LEFT JOIN oauth2_clients c ON c.client_id = t.client_id
+		WHERE t.tenant_id = $1 AND t.user_id = $2 AND t.is_revoked = false AND t.expires_at > NOW()
+		ORDER BY 9 DESC
+	`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*grant.Token
+	for rows.Next() {
+		var t grant.Token
+		var scope string
+		if err := rows.Scan(
+			&t.ID, &t.Kind, &t.TenantID, &t.UserID, &t.ClientID, &t.ClientName,
+			&t.GrantID, &scope, &t.IssuedAt, &t.ExpiresAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+		t.Scopes = strings.Fields(scope)
+		tokens = append(tokens, &t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// ListConnections returns a user's consents, active tokens, and the active sessions
+// that issued authorization codes, with client names, in one query, newest first
+func (r *GrantRepository) ListConnections(ctx context.Context, tenantID, userID string) ([]*grant.Connection, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT x.kind, x.id, x.client_id, COALESCE(c.client_name, ''), COALESCE(c.logo_uri, ''),
+			x.grant_id, x.scope, x.user_agent, x.issued_at, x.expires_at, x.last_seen_at
+		FROM (
+			SELECT 'consent' AS kind, '' AS id, g.client_id::text AS client_id, '' AS grant_id,
+				COALESCE((SELECT string_agg(s, ' ') FROM jsonb_array_elements_text(g.scopes) AS s), '') AS scope,
+				'' AS user_agent, g.created_at AS issued_at, g.expires_at, g.updated_at AS last_seen_at
+			FROM consent_grants g
+			WHERE g.tenant_id = $1 AND g.user_id = $2 AND (g.expires_at IS NULL OR g.expires_at > NOW())
+			UNION ALL
+			SELECT 'access', t.id::text, t.client_id::text, COALESCE(t.grant_id::text, ''), COALESCE(t.scope, ''),
+				'', t.created_at, t.expires_at, t.created_at
+			FROM access_tokens t
+			WHERE t.tenant_id = $1 AND t.user_id = $2 AND t.is_revoked = false AND t.expires_at > NOW()
+			UNION ALL
+			SELECT 'refresh', t.id::text, t.client_id::text, COALESCE(t.grant_id::text, ''), COALESCE(t.scope, ''),
+				'', t.created_at, t.expires_at, t.created_at
+			FROM refresh_tokens t
+			WHERE t.tenant_id = $1 AND t.user_id = $2 AND t.is_revoked = false AND t.expires_at > NOW()
+			UNION ALL
+			SELECT DISTINCT 'session', s.id, a.client_id::text, '', '',
+				COALESCE(s.user_agent, ''), s.created_at, s.expires_at, s.last_seen_at
+			FROM sessions s
+			JOIN authorization_codes a ON a.session_id = s.id
+			WHERE a.tenant_id = $1 AND s.user_id = $2 AND s.expires_at > NOW()
+		) x
+		LEFT JOIN oauth2_clients c ON c.client_id::text = x.client_id AND c.tenant_id = $1
+		ORDER BY x.issued_at DESC
+	`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	defer rows.Close()
+
+	var conns []*grant.Connection
+	for rows.Next() {
+		var c grant.Connection
+		var scope string
+		var expiresAt *time.Time
+		if err := rows.Scan(
+			&c.Kind, &c.ID, &c.ClientID, &c.ClientName, &c.LogoURI,
+			&c.GrantID, &scope, &c.UserAgent, &c.IssuedAt, &expiresAt, &c.LastSeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan connection: %w", err)
+		}
+		c.Scopes = strings.Fields(scope)
+		if expiresAt != nil {
+			c.ExpiresAt = *expiresAt
+		}
+		conns = append(conns, &c)
+	}
+
+	return conns, rows.Err()
+}
+
+// RevokeToken revokes one access or refresh token of a user
+func (r *GrantRepository) RevokeToken(ctx context.Context, tenantID, userID, tokenID string) (*grant.Token, error) {
+	for _, kind := range []string{grant.KindAccess, grant.KindRefresh} {
+		t := grant.Token{Kind: kind}
+		var scope string
+		err := r.db.pool.QueryRow(ctx, `
+			UPDATE `+tokenTable(kind)+` SET is_revoked = true, revoked_at = NOW()
+			WHERE tenant_id = $1 AND user_id = $2 AND id = $3 AND is_revoked = false
+			RETURNING id, tenant_id, user_id, client_id, COALESCE(grant_id::text, ''), COALESCE(scope, ''), created_at, expires_at
+		`, tenantID, userID, tokenID).Scan(
+			&t.ID, &t.TenantID, &t.UserID, &t.ClientID, &t.GrantID, &scope, &t.IssuedAt, &t.ExpiresAt,
+		)
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to revoke %s token: %w", kind, err)
+		}
+		t.Scopes = strings.Fields(scope)
+		r.db.metrics.TokenRevoked(kind)
+		events.Emit(ctx, r.db.events, events.TokenRevoked{
+			Meta: events.NewMeta(t.TenantID, ""), TokenID: t.ID, ClientID: t.ClientID, UserID: t.UserID, GrantID: t.GrantID,
+		})
+		return &t, nil
+	}
+	return nil, grant.ErrTokenNotFound
+}
+
+// RevokeGrant revokes every token of a user's grant, and the refresh token families it spans
+func (r *GrantRepository) RevokeGrant(ctx context.Context, tenantID, userID, grantID string) (int, error) {
+	return r.revoke(ctx, "tenant_id = $1 AND user_id = $2 AND grant_id = $3", tenantID, userID, grantID)
+}
+
+// RevokeAll revokes every token of a user in a tenant, and all of the user's refresh token families
+func (r *GrantRepository) RevokeAll(ctx context.Context, tenantID, userID string) (int, error) {
+	return r.revoke(ctx, "tenant_id = $1 AND user_id = $2", tenantID, userID)
+}
+
+// RevokeAllForUser revokes every token of a user in every tenant, and all of the user's refresh token families
+func (r *GrantRepository) RevokeAllForUser(ctx context.Context, userID string) (int, error) {
+	return r.revoke(ctx, "user_id = $1", userID)
+}
+
+// revoke revokes the tokens matching where in both token tables. Families of the
+// revoked refresh tokens are revoked too, so rotation cannot revive the grant.
+func (r *GrantRepository) revoke(ctx context.Context, where string, args ...any) (int, error) {
+	tx, err := r.db.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE refresh_token_families SET revoked_at = NOW(), revoke_reason = '`+client.FamilyRevokedAdmin+`'
+		WHERE revoked_at IS NULL AND id IN (
+			SELECT family_id FROM refresh_tokens WHERE `+where+` AND family_id IS NOT NULL
+		)
+	`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh token families: %w", err)
+	}
+
+	counts := make(map[string]int64, 2)
+	for _, kind := range []string{grant.KindAccess, grant.KindRefresh} {
+		result, err := tx.Exec(ctx, `
+			UPDATE `+tokenTable(kind)+` SET is_revoked = true, revoked_at = NOW()
+			WHERE `+where+` AND is_revoked = false
+		`, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to revoke %s tokens: %w", kind, err)
+		}
+		counts[kind] = result.RowsAffected()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	total := 0
+	for kind, n := range counts {
+		for range n {
+			r.db.metrics.TokenRevoked(kind)
+		}
+		total += int(n)
+	}
+	return total, nil
+}
+
+// tokenTable maps a token kind to its table.
+func tokenTable(kind string) string {
+	if kind == grant.KindRefresh {
+		return "refresh_tokens"
+	}
+	return "access_tokens"
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// IdentityRepository implements user.IdentityRepository
+type IdentityRepository struct {
+	db *DB
+}
+
+// NewIdentityRepository creates a new linked identity repository
+func NewIdentityRepository(db *DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// Create links an identity
+func (r *IdentityRepository) Create(ctx context.Context, i *user.LinkedIdentity) error {
+	metadata := []byte("{}")
+	if len(i.Metadata) > 0 {
+		var err error
+		if metadata, err = json.Marshal(i.Metadata); err != nil {
+			return fmt.Errorf("failed to encode identity metadata: %w", err)
+		}
+	}
+
+	result, err := r.db.pool.Exec(ctx, `
+		INSERT INTO identities (id, user_id, kind, issuer, subject, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (kind, issuer, subject) DO NOTHING
+	`, i.ID, i.UserID, string(i.Kind), i.Issuer, i.Subject, metadata, i.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return user.ErrIdentityAlreadyLinked
+	}
+
+	return nil
+}
+
+// Get returns the identity with kind, issuer, and subject
+func (r *IdentityRepository) Get(ctx context.Context, kind user.IdentityKind, issuer, subject string) (*user.LinkedIdentity, error) {
+	i, err := scanIdentity(r.db.pool.QueryRow(ctx, `
+		SELECT id, user_id, kind, issuer, subject, metadata, created_at, last_used_at
+		FROM identities
+		WHERE kind = $1 AND issuer = $2 AND subject = $3
+	`, string(kind), issuer, subject))
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, user.ErrIdentityNotFound
+		}
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return i, nil
+}
+
+// ListByUser returns a user's identities, oldest first
+func (r *IdentityRepository) ListByUser(ctx context.Context, userID string) ([]*user.LinkedIdentity, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, user_id, kind, issuer, subject, metadata, created_at, last_used_at
+		FROM identities
+		WHERE user_id = $1
+		ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []*user.LinkedIdentity
+	for rows.Next() {
+		i, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %w", err)
+		}
+		identities = append(identities, i)
+	}
+
+	return identities, rows.Err()
+}
+
+// Delete unlinks an identity of a user, removing the credentials behind a password identity
+func (r *IdentityRepository) Delete(ctx context.Context, userID, id string) error {
+	tx, err := r.db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var kind string
+	err = tx.QueryRow(ctx, `
+		DELETE FROM identities WHERE user_id = $1 AND id = $2 RETURNING kind
+	`, userID, id).Scan(&kind)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return user.ErrIdentityNotFound
+		}
+		return fmt.Errorf("failed to delete identity: %w", err)
+	}
+
+	if user.IdentityKind(kind) == user.IdentityPassword {
+		if _, err := tx.Exec(ctx, `DELETE FROM credentials WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("failed to delete credentials: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Touch records that the identity was used to log in at
+func (r *IdentityRepository) Touch(ctx context.Context, kind user.IdentityKind, issuer, subject string, at time.Time) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE identities SET last_used_at = $4
+		WHERE kind = $1 AND issuer = $2 AND subject = $3
+	`, string(kind), issuer, subject, at)
+
+	if err != nil {
+		return fmt.Errorf("failed to touch identity: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return user.ErrIdentityNotFound
+	}
+
+	return nil
+}
+
+// scanIdentity reads one identities row.
+func scanIdentity(row pgx.Row) (*user.LinkedIdentity, error) {
+	var i user.LinkedIdentity
+	var kind string
+	var metadata []byte
+	if err := row.Scan(&i.ID, &i.UserID, &kind, &i.Issuer, &i.Subject, &metadata, &i.CreatedAt, &i.LastUsedAt); err != nil {
+		return nil, err
+	}
+	i.Kind = user.IdentityKind(kind)
+	if err := json.Unmarshal(metadata, &i.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode identity metadata: %w", err)
+	}
+	return &i, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/integrity"
+)
+
+// integrityCheck is the SQL of one integrity.Kind. selectSQL yields
+// (table, record_id, reference) rows; repairSQL fixes all of them except
+// records of the held tenants ($1) and users ($2).
+type integrityCheck struct {
+	selectSQL string
+	repairSQL string
+}
+
+const orphanedAssignmentWhere = `
+	EXISTS (SELECT 1 FROM users u WHERE u.id = a.user_id AND u.deleted_at IS NOT NULL)
+	OR (a.scope = 'tenant' AND NOT EXISTS (
+		SELECT 1 FROM tenants t WHERE t.id = a.scope_context_id AND t.deleted_at IS NULL))
+	OR (a.scope = 'client' AND NOT EXISTS (
+		SELECT 1 FROM oauth2_clients c
+		WHERE (c.id = a.scope_context_id OR c.client_id = a.scope_context_id) AND c.deleted_at IS NULL))`
+
+const orphanedMembershipWhere = `
+	EXISTS (SELECT 1 FROM users u WHERE u.id = m.user_id AND u.deleted_at IS NOT NULL)
+	OR EXISTS (SELECT 1 FROM tenants t WHERE t.id = m.tenant_id AND t.deleted_at IS NOT NULL)`
+
+// notHeld excludes rows whose tenant or user is under a legal hold.
+func notHeld(tenantExpr, userExpr string) string {
+	return ` AND NOT (` + tenantExpr + ` = ANY($1) OR ` + userExpr + ` = ANY($2))`
+}
+
+var integrityChecks = map[integrity.Kind]integrityCheck{
+	integrity.KindOrphanedAssignment: {
+		selectSQL: `SELECT 'rbac_assignments', a.id::text, COALESCE(a.scope_context_id::text, a.user_id::text)
+			FROM rbac_assignments a WHERE ` + orphanedAssignmentWhere,
+		repairSQL: `DELETE FROM rbac_assignments a WHERE (` + orphanedAssignmentWhere + `)` +
+			notHeld(`COALESCE(a.scope_context_id::text, '')`, `a.user_id::text`),
+	},
+	integrity.KindOrphanedMembership: {
+		selectSQL: `SELECT 'tenant_members', m.id::text, m.user_id::text
+			FROM tenant_members m WHERE ` + orphanedMembershipWhere,
+		repairSQL: `DELETE FROM tenant_members m WHERE (` + orphanedMembershipWhere + `)` +
+			notHeld(`m.tenant_id::text`, `m.user_id::text`),
+	},
+	integrity.KindTokenOfDeletedClient: {
+		selectSQL: `SELECT 'access_tokens', t.id::text, t.client_id::text
+			FROM access_tokens t JOIN oauth2_clients c ON c.client_id = t.client_id
+			WHERE c.deleted_at IS NOT NULL AND NOT t.is_revoked
+			UNION ALL
+			SELECT 'refresh_tokens', t.id::text, t.client_id::text
+			FROM refresh_tokens t JOIN oauth2_clients c ON c.client_id = t.client_id
+			WHERE c.deleted_at IS NOT NULL AND NOT t.is_revoked`,
+		repairSQL: `WITH deleted AS (SELECT client_id FROM oauth2_clients WHERE deleted_at IS NOT NULL),
+			a AS (
+				UPDATE access_tokens SET is_revoked = TRUE, revoked_at = NOW()
+				WHERE client_id IN (SELECT client_id FROM deleted) AND NOT is_revoked` +
+			notHeld(`tenant_id::text`, `user_id::text`) + `
+				RETURNING 1
+			),
+			r AS (
+				UPDATE refresh_tokens SET is_revoked = TRUE, revoked_at = NOW()
+				WHERE client_id IN (SELECT client_id FROM deleted) AND NOT is_revoked` +
+			notHeld(`tenant_id::text`, `user_id::text`) + `
+				RETURNING 1
+			)
+			SELECT (SELECT COUNT(*) FROM a) + (SELECT COUNT(*) FROM r)`,
+	},
+	integrity.KindCodeOfDeletedUser: {
+		selectSQL: `SELECT 'authorization_codes', ac.id::text, ac.user_id::text
+			FROM authorization_codes ac JOIN users u ON u.id = ac.user_id
+			WHERE u.deleted_at IS NOT NULL AND NOT ac.is_used`,
+		repairSQL: `DELETE FROM authorization_codes ac USING users u
+			WHERE u.id = ac.user_id AND u.deleted_at IS NOT NULL AND NOT ac.is_used` +
+			notHeld(`COALESCE((SELECT c.tenant_id::text FROM oauth2_clients c WHERE c.client_id = ac.client_id), '')`, `ac.user_id::text`),
+	},
+}
+
+// IntegrityRepository implements integrity.Repository
+type IntegrityRepository struct {
+	db *DB
+}
+
+// NewIntegrityRepository creates a new integrity repository
+func NewIntegrityRepository(db *DB) *IntegrityRepository {
+	return &IntegrityRepository{db: db}
+}
+
+// Find returns up to limit findings of kind and the total number found
+func (r *IntegrityRepository) Find(ctx context.Context, kind integrity.Kind, limit int) ([]integrity.Finding, int, error) {
+	check, ok := integrityChecks[kind]
+	if !ok {
+		return nil, 0, integrity.ErrUnknownKind
+	}
+
+	var total int
+	if err := r.db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM (`+check.selectSQL+`) f`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count %s: %w", kind, err)
+	}
+	if total == 0 || limit <= 0 {
+		return nil, total, nil
+	}
+
+	rows, err := r.db.pool.Query(ctx, check.selectSQL+` LIMIT $1`, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find %s: %w", kind, err)
+	}
+	defer rows.Close()
+
+	var findings []integrity.Finding
+	for rows.Next() {
+		f := integrity.Finding{Kind: kind}
+		if err := rows.Scan(&f.Table, &f.RecordID, &f.Reference); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan %s: %w", kind, err)
+		}
+		findings = append(findings, f)
+	}
+
+	return findings, total, rows.Err()
+}
+
+// Repair fixes every finding of kind outside exclude and returns how many records it changed
+func (r *IntegrityRepository) Repair(ctx context.Context, kind integrity.Kind, exclude integrity.Exclusions) (int64, error) {
+	check, ok := integrityChecks[kind]
+	if !ok {
+		return 0, integrity.ErrUnknownKind
+	}
+
+	tenants := exclude.TenantIDs
+	if tenants == nil {
+		tenants = []string{}
+	}
+	users := exclude.UserIDs
+	if users == nil {
+		users = []string{}
+	}
+
+	if kind == integrity.KindTokenOfDeletedClient {
+		var n int64
+		if err := r.db.pool.QueryRow(ctx, check.repairSQL, tenants, users).Scan(&n); err != nil {
+			return 0, fmt.Errorf("failed to repair %s: %w", kind, err)
+		}
+		return n, nil
+	}
+
+	tag, err := r.db.pool.Exec(ctx, check.repairSQL, tenants, users)
+	if err != nil {
+		return 0, fmt.Errorf("failed to repair %s: %w", kind, err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/bruteforce"
+)
+
+// IPReputationRepository implements bruteforce.Repository
+type IPReputationRepository struct {
+	db *DB
+}
+
+// NewIPReputationRepository creates a new IP reputation repository
+func NewIPReputationRepository(db *DB) *IPReputationRepository {
+	return &IPReputationRepository{db: db}
+}
+
+// CreateBlock persists a new block
+func (r *IPReputationRepository) CreateBlock(ctx context.Context, b *bruteforce.Block) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO ip_blocks (id, cidr, reason, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+	`, b.ID, b.CIDR, b.Reason, b.CreatedBy, b.ExpiresAt, b.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create ip block: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveBlocks returns blocks that have not expired at now
+func (r *IPReputationRepository) ListActiveBlocks(ctx context.Context, now time.Time) ([]*bruteforce.Block, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, cidr::text, reason, COALESCE(created_by, ''), expires_at, created_at
+		FROM ip_blocks
+		WHERE expires_at > $1
+		ORDER BY created_at
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ip blocks: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []*bruteforce.Block
+	for rows.Next() {
+		var b bruteforce.Block
+		if err := rows.Scan(&b.ID, &b.CIDR, &b.Reason, &b.CreatedBy, &b.ExpiresAt, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ip block: %w", err)
+		}
+		blocks = append(blocks, &b)
+	}
+
+	return blocks, rows.Err()
+}
+
+// DeleteBlock removes a block by ID
+func (r *IPReputationRepository) DeleteBlock(ctx context.Context, id string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM ip_blocks WHERE id = $1
+	`, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete ip block: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return bruteforce.ErrBlockNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredBlocks removes blocks that expired before now
+func (r *IPReputationRepository) DeleteExpiredBlocks(ctx context.Context, now time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `
+		DELETE FROM ip_blocks WHERE expires_at <= $1
+	`, now)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete expired ip blocks: %w", err)
+	}
+
+	return nil
+}
+
+// AddAllow persists an allowlist entry
+func (r *IPReputationRepository) AddAllow(ctx context.Context, e *bruteforce.AllowEntry) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO ip_allowlist (id, cidr, description, created_by, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (cidr) DO UPDATE SET description = EXCLUDED.description
+	`, e.ID, e.CIDR, e.Description, e.CreatedBy, e.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to add allowlist entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListAllow returns all allowlist entries
+func (r *IPReputationRepository) ListAllow(ctx context.Context) ([]*bruteforce.AllowEntry, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, cidr::text, COALESCE(description, ''), COALESCE(created_by, ''), created_at
+		FROM ip_allowlist
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list allowlist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*bruteforce.AllowEntry
+	for rows.Next() {
+		var e bruteforce.AllowEntry
+		if err := rows.Scan(&e.ID, &e.CIDR, &e.Description, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan allowlist entry: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// DeleteAllow removes an allowlist entry by ID
+func (r *IPReputationRepository) DeleteAllow(ctx context.Context, id string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM ip_allowlist WHERE id = $1
+	`, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete allowlist entry: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return bruteforce.ErrAllowNotFound
+	}
+
+	return nil
+}
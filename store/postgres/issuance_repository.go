@@ -0,0 +1,130 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/issuance"
+)
+
+// issuanceTables maps each issuance.Kind to the table its credentials are stored in.
+var issuanceTables = map[issuance.Kind]string{
+	issuance.KindToken:   "access_tokens",
+	issuance.KindSession: "sessions",
+}
+
+// IssuanceRepository implements issuance.Repository
+type IssuanceRepository struct {
+	db *DB
+}
+
+// NewIssuanceRepository creates a new issuance repository
+func NewIssuanceRepository(db *DB) *IssuanceRepository {
+	return &IssuanceRepository{db: db}
+}
+
+// CountIssued returns how many credentials of kind were issued to the user since t
+func (r *IssuanceRepository) CountIssued(ctx context.Context, userID string, kind issuance.Kind, since time.Time) (int, error) {
+	table, ok := issuanceTables[kind]
+	if !ok {
+		return 0, fmt.Errorf("unknown issuance kind %q", kind)
+	}
+
+	var count int
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM `+table+` WHERE user_id = $1 AND created_at >= $2
+	`, userID, since).Scan(&count)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count issued %s: %w", table, err)
+	}
+
+	return count, nil
+}
+
+// SaveFlag creates or replaces the user's flag
+func (r *IssuanceRepository) SaveFlag(ctx context.Context, f *issuance.Flag) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO issuance_flags (user_id, tenant_id, kind, count, flag_limit, throttled_until, expires_at, created_at)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			tenant_id = EXCLUDED.tenant_id,
+			kind = EXCLUDED.kind,
+			count = EXCLUDED.count,
+			flag_limit = EXCLUDED.flag_limit,
+			throttled_until = EXCLUDED.throttled_until,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+	`, f.UserID, f.TenantID, f.Kind, f.Count, f.Limit, f.ThrottledUntil, f.ExpiresAt, f.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save issuance flag: %w", err)
+	}
+
+	return nil
+}
+
+// GetFlag returns the user's flag
+func (r *IssuanceRepository) GetFlag(ctx context.Context, userID string) (*issuance.Flag, error) {
+	var f issuance.Flag
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT user_id, COALESCE(tenant_id::text, ''), kind, count, flag_limit, throttled_until, expires_at, created_at
+		FROM issuance_flags
+		WHERE user_id = $1
+	`, userID).Scan(&f.UserID, &f.TenantID, &f.Kind, &f.Count, &f.Limit, &f.ThrottledUntil, &f.ExpiresAt, &f.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, issuance.ErrFlagNotFound
+		}
+		return nil, fmt.Errorf("failed to get issuance flag: %w", err)
+	}
+
+	return &f, nil
+}
+
+// DeleteFlag removes the user's flag
+func (r *IssuanceRepository) DeleteFlag(ctx context.Context, userID string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM issuance_flags WHERE user_id = $1
+	`, userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete issuance flag: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return issuance.ErrFlagNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredFlags removes flags that expired before now
+func (r *IssuanceRepository) DeleteExpiredFlags(ctx context.Context, now time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `
+		DELETE FROM issuance_flags WHERE expires_at < $1
+	`, now)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete expired issuance flags: %w", err)
+	}
+
+	return nil
+}
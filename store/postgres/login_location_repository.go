@@ -0,0 +1,92 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/risk"
+)
+
+// LoginLocationRepository implements risk.Repository
+type LoginLocationRepository struct {
+	db *DB
+}
+
+// NewLoginLocationRepository creates a new login location repository
+func NewLoginLocationRepository(db *DB) *LoginLocationRepository {
+	return &LoginLocationRepository{db: db}
+}
+
+// RecordLogin persists a login record
+func (r *LoginLocationRepository) RecordLogin(ctx context.Context, rec *risk.LoginRecord) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO login_locations (id, user_id, tenant_id, country, latitude, longitude, created_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7)
+	`, rec.ID, rec.UserID, rec.TenantID, rec.Country, rec.Latitude, rec.Longitude, rec.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record login location: %w", err)
+	}
+
+	return nil
+}
+
+// LastLogin returns the user's most recent login record
+func (r *LoginLocationRepository) LastLogin(ctx context.Context, userID string) (*risk.LoginRecord, error) {
+	var rec risk.LoginRecord
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT id, user_id, COALESCE(tenant_id::text, ''), country, latitude, longitude, created_at
+		FROM login_locations
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID).Scan(&rec.ID, &rec.UserID, &rec.TenantID, &rec.Country, &rec.Latitude, &rec.Longitude, &rec.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, risk.ErrNoLoginHistory
+		}
+		return nil, fmt.Errorf("failed to get last login location: %w", err)
+	}
+
+	return &rec, nil
+}
+
+// KnownCountry reports whether the user has logged in from country before
+func (r *LoginLocationRepository) KnownCountry(ctx context.Context, userID, country string) (bool, error) {
+	var known bool
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM login_locations WHERE user_id = $1 AND country = $2)
+	`, userID, country).Scan(&known)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check login country: %w", err)
+	}
+
+	return known, nil
+}
+
+// DeleteBefore removes records created before t
+func (r *LoginLocationRepository) DeleteBefore(ctx context.Context, t time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `DELETE FROM login_locations WHERE created_at < $1`, t)
+	if err != nil {
+		return fmt.Errorf("failed to prune login locations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"github.com/opentrusty/opentrusty-core/metrics"
+)
+
+// RegisterMetrics exposes connection pool statistics on m and enables token
+// issuance/revocation counters in the token repositories.
+//
+// Purpose: Database observability for the host's metrics endpoint.
+// Domain: Platform (Infrastructure)
+// Audited: No
+// Errors: None (panics if called twice with the same registry)
+func (db *DB) RegisterMetrics(m *metrics.Metrics) {
+	db.metrics = m
+
+	reg := m.Registry
+	reg.NewGaugeFunc("opentrusty_db_pool_total_conns",
+		"Total connections in the pool.", func() float64 {
+			return float64(db.pool.Stat().TotalConns())
+		})
+	reg.NewGaugeFunc("opentrusty_db_pool_acquired_conns",
+		"Connections currently acquired.", func() float64 {
+			return float64(db.pool.Stat().AcquiredConns())
+		})
+	reg.NewGaugeFunc("opentrusty_db_pool_idle_conns",
+		"Idle connections in the pool.", func() float64 {
+			return float64(db.pool.Stat().IdleConns())
+		})
+	reg.NewGaugeFunc("opentrusty_db_pool_max_conns",
+		"Maximum pool size.", func() float64 {
+			return float64(db.pool.Stat().MaxConns())
+		})
+	reg.NewGaugeFunc("opentrusty_db_pool_acquire_wait_seconds_total",
+		"Cumulative time spent waiting for a connection.", func() float64 {
+			return db.pool.Stat().AcquireDuration().Seconds()
+		})
+	reg.NewGaugeFunc("opentrusty_db_pool_empty_acquire_total",
+		"Acquires that had to wait for a connection.", func() float64 {
+			return float64(db.pool.Stat().EmptyAcquireCount())
+		})
+}
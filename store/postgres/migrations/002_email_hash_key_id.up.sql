@@ -0,0 +1,7 @@
+-- 002_email_hash_key_id.up.sql
+-- Records which HMAC key produced each email hash so lookups and re-keying
+-- can distinguish legacy master-key hashes from purpose-derived ones.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_hash_key_id VARCHAR(64) NOT NULL DEFAULT 'legacy';
+
+CREATE INDEX IF NOT EXISTS idx_users_email_hash_key_id ON users(email_hash_key_id);
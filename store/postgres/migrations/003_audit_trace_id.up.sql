@@ -0,0 +1,6 @@
+-- 003_audit_trace_id.up.sql
+-- Correlates audit events with distributed traces.
+
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS trace_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_trace_id ON audit_events(trace_id) WHERE trace_id IS NOT NULL;
@@ -0,0 +1,21 @@
+-- 004_ip_reputation.up.sql
+-- Source-level brute-force protection: temporary IP/CIDR blocks and allowlist.
+
+CREATE TABLE IF NOT EXISTS ip_blocks (
+    id VARCHAR(255) PRIMARY KEY,
+    cidr CIDR NOT NULL,
+    reason VARCHAR(64) NOT NULL,
+    created_by VARCHAR(255),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ip_blocks_expires_at ON ip_blocks(expires_at);
+
+CREATE TABLE IF NOT EXISTS ip_allowlist (
+    id VARCHAR(255) PRIMARY KEY,
+    cidr CIDR NOT NULL UNIQUE,
+    description TEXT,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
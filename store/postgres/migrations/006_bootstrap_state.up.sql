@@ -0,0 +1,10 @@
+-- 006_bootstrap_state.up.sql
+-- Singleton row tracking the one-time first-admin bootstrap.
+
+CREATE TABLE IF NOT EXISTS bootstrap_state (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    token_hash VARCHAR(64),
+    token_expires_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    admin_user_id UUID REFERENCES users(id) ON DELETE SET NULL
+);
@@ -0,0 +1,45 @@
+-- 007_scim.up.sql
+-- Tenant SCIM provisioning targets, their operation outbox, and downstream resource links.
+
+CREATE TABLE IF NOT EXISTS scim_targets (
+    id VARCHAR(255) PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    base_url TEXT NOT NULL,
+    token TEXT NOT NULL,
+    attribute_mapping JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scim_targets_tenant_id ON scim_targets(tenant_id);
+
+CREATE TABLE IF NOT EXISTS scim_operations (
+    id VARCHAR(255) PRIMARY KEY,
+    target_id VARCHAR(255) NOT NULL REFERENCES scim_targets(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    action VARCHAR(20),
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_scim_operations_due ON scim_operations(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_scim_operations_target ON scim_operations(target_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS scim_links (
+    target_id VARCHAR(255) NOT NULL REFERENCES scim_targets(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (target_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scim_links_user_id ON scim_links(user_id);
@@ -0,0 +1,11 @@
+-- 008_feature_flags.up.sql
+-- Per-tenant feature flag overrides. Defaults and deployment values live in code and configuration.
+
+CREATE TABLE IF NOT EXISTS tenant_feature_flags (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    flag VARCHAR(100) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, flag)
+);
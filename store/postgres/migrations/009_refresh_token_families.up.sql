@@ -0,0 +1,24 @@
+-- 009_refresh_token_families.up.sql
+-- Refresh token families: one row per original grant and device. Rotated tokens link
+-- to their family and parent so reuse of a spent token can revoke the whole family.
+
+CREATE TABLE IF NOT EXISTS refresh_token_families (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id VARCHAR(255),
+    device_name VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoke_reason VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_families_user ON refresh_token_families(tenant_id, user_id) WHERE revoked_at IS NULL;
+
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id UUID REFERENCES refresh_token_families(id) ON DELETE CASCADE;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_parent_id ON refresh_tokens(parent_id);
@@ -0,0 +1,15 @@
+-- 010_authorization_code_binding.up.sql
+-- Binds authorization codes to the tenant they were issued in and the session that
+-- approved them. Deleting the session deletes its outstanding codes.
+
+ALTER TABLE authorization_codes ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE authorization_codes ADD COLUMN IF NOT EXISTS session_id TEXT REFERENCES sessions(id) ON DELETE CASCADE;
+
+-- Outstanding codes belong to the tenant of the client they were issued to.
+UPDATE authorization_codes ac SET tenant_id = c.tenant_id
+FROM oauth2_clients c
+WHERE ac.client_id = c.client_id AND ac.tenant_id IS NULL;
+
+ALTER TABLE authorization_codes ALTER COLUMN tenant_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_authorization_codes_session_id ON authorization_codes(session_id);
@@ -0,0 +1,6 @@
+-- 011_client_origins.up.sql
+-- Per-client browser origins for CORS, application type for security defaults, and contacts.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS allowed_origins JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS application_type VARCHAR(20) NOT NULL DEFAULT 'web';
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS contacts JSONB NOT NULL DEFAULT '[]'::jsonb;
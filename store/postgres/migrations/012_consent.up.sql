@@ -0,0 +1,21 @@
+-- 012_consent.up.sql
+-- Remembered user consent per client, and the permission to mark clients as trusted.
+
+CREATE TABLE IF NOT EXISTS consent_grants (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+    scopes JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, user_id, client_id)
+);
+
+-- Only tenant owners may mark clients trusted by default; platform admins hold '*'.
+INSERT INTO rbac_permissions (id, name, created_at) VALUES
+('00000000-0000-0000-0000-000000000008', 'tenant:trust_clients', NOW())
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO rbac_role_permissions (role_id, permission_id)
+SELECT '00000000-0000-0000-0000-000000000002', id FROM rbac_permissions WHERE name = 'tenant:trust_clients'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,11 @@
+-- 013_client_usage.up.sql
+-- Per-client token issuance counters, flushed in batches by the usage recorder.
+
+CREATE TABLE IF NOT EXISTS oauth2_client_usage (
+    client_id UUID PRIMARY KEY REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    tokens_issued BIGINT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth2_client_usage_tenant ON oauth2_client_usage(tenant_id);
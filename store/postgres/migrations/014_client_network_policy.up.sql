@@ -0,0 +1,10 @@
+-- 014_client_network_policy.up.sql
+-- Per-client source network allowlist and sender-constrained token requirements,
+-- and the confirmation (cnf) of access tokens issued under them.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS allowed_cidrs JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS dpop_bound_access_tokens BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS tls_client_certificate_bound_access_tokens BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS cnf_jkt TEXT;
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS cnf_x5t_s256 TEXT;
@@ -0,0 +1,4 @@
+-- 015_client_claim_mapping.up.sql
+-- Per-client claim mapping applied by the ID and access token builders.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS claim_mapping JSONB;
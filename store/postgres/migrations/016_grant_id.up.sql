@@ -0,0 +1,11 @@
+-- 016_grant_id.up.sql
+-- Grant ID linking an authorization code to the tokens issued from it, and the
+-- audit events recorded for them. Rows issued before this migration have none.
+
+ALTER TABLE authorization_codes ADD COLUMN IF NOT EXISTS grant_id UUID;
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS grant_id UUID;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS grant_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_access_tokens_grant ON access_tokens(tenant_id, grant_id) WHERE grant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_grant ON refresh_tokens(tenant_id, grant_id) WHERE grant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_events_grant ON audit_events((metadata->>'grant_id')) WHERE metadata ? 'grant_id';
@@ -0,0 +1,6 @@
+-- 017_signing_alg.up.sql
+-- Per-tenant token signing algorithm and the algorithm each client expects for ID tokens.
+-- An empty value selects the default (RS256 for tenants, the tenant's algorithm for clients).
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS signing_alg VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS id_token_signed_response_alg VARCHAR(16) NOT NULL DEFAULT '';
@@ -0,0 +1,21 @@
+-- 018_login_flows.up.sql
+-- Persisted multi-step login flows (password, MFA, consent, step-up).
+
+CREATE TABLE IF NOT EXISTS login_flows (
+    id TEXT PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL DEFAULT '',
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    state VARCHAR(32) NOT NULL,
+    steps JSONB NOT NULL DEFAULT '[]'::jsonb,
+    amr JSONB NOT NULL DEFAULT '[]'::jsonb,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    failure_reason TEXT NOT NULL DEFAULT '',
+    version INTEGER NOT NULL DEFAULT 0,
+    step_expires_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_flows_expires_at ON login_flows(expires_at);
@@ -0,0 +1,13 @@
+-- 019_role_mapping_rules.up.sql
+-- Per-tenant rules that map upstream identity provider claims to tenant roles.
+
+CREATE TABLE IF NOT EXISTS role_mapping_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    claim VARCHAR(255) NOT NULL,
+    value TEXT NOT NULL,
+    role VARCHAR(100) NOT NULL,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, claim, value, role)
+);
@@ -0,0 +1,10 @@
+-- 020_password_expiry.up.sql
+-- Password age tracking and the per-tenant password max-age (0 disables expiry).
+-- Existing credentials start their age from their last update.
+
+ALTER TABLE credentials ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP;
+UPDATE credentials SET password_changed_at = updated_at WHERE password_changed_at IS NULL;
+ALTER TABLE credentials ALTER COLUMN password_changed_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE credentials ALTER COLUMN password_changed_at SET NOT NULL;
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS password_max_age_days INTEGER NOT NULL DEFAULT 0;
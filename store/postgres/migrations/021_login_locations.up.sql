@@ -0,0 +1,17 @@
+-- 021_login_locations.up.sql
+-- Geography of successful logins, the baseline for suspicious login detection.
+-- Only the derived country and coordinates are stored, never the IP address.
+
+CREATE TABLE IF NOT EXISTS login_locations (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    country CHAR(2) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL DEFAULT 0,
+    longitude DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_locations_user ON login_locations(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_locations_user_country ON login_locations(user_id, country);
+CREATE INDEX IF NOT EXISTS idx_login_locations_created_at ON login_locations(created_at);
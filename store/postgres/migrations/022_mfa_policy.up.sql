@@ -0,0 +1,8 @@
+-- 022_mfa_policy.up.sql
+-- Per-tenant MFA enforcement: mode (optional, required, roles), covered roles,
+-- enrollment grace period, and when the covered set of members last changed.
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS mfa_mode VARCHAR(20) NOT NULL DEFAULT 'optional';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS mfa_roles TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS mfa_grace_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS mfa_enforced_at TIMESTAMP;
@@ -0,0 +1,4 @@
+-- 023_password_reset_required.up.sql
+-- Set when an administrator invalidates a password; cleared by the next password update.
+
+ALTER TABLE credentials ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN NOT NULL DEFAULT false;
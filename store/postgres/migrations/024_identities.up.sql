@@ -0,0 +1,22 @@
+-- 024_identities.up.sql
+-- Login methods per user (password, federated subject, passkey, phone).
+-- Password identities mirror the credentials table and are backfilled from it.
+
+CREATE TABLE IF NOT EXISTS identities (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    issuer VARCHAR(512) NOT NULL DEFAULT '',
+    subject VARCHAR(512) NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP,
+    UNIQUE (kind, issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_identities_user_id ON identities(user_id);
+
+INSERT INTO identities (id, user_id, kind, issuer, subject, created_at)
+SELECT gen_random_uuid(), user_id, 'password', '', user_id::text, updated_at
+FROM credentials
+ON CONFLICT (kind, issuer, subject) DO NOTHING;
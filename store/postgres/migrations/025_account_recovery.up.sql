@@ -0,0 +1,34 @@
+-- 025_account_recovery.up.sql
+-- Account recovery for users who lost every factor: per-tenant policy and the
+-- requests themselves. Only the SHA-256 of a recovery token is stored.
+
+CREATE TABLE IF NOT EXISTS recovery_policies (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    delayed_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    delay_seconds BIGINT NOT NULL,
+    admin_attestation_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    step_up_max_age_seconds BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS recovery_requests (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    token_hash VARCHAR(64) NOT NULL,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_at TIMESTAMP WITH TIME ZONE,
+    justification TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- At most one open request per user and tenant.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recovery_requests_open
+    ON recovery_requests(tenant_id, user_id) WHERE status IN ('pending', 'approved');
+CREATE INDEX IF NOT EXISTS idx_recovery_requests_expires_at ON recovery_requests(expires_at);
@@ -0,0 +1,7 @@
+-- 026_dashboard_indexes.up.sql
+-- Supports the tenant dashboard aggregates: recent events per tenant and
+-- active sessions per tenant.
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant_created_at ON audit_events(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_created_at ON audit_events(actor_id, created_at DESC) WHERE tenant_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_sessions_tenant_expires_at ON sessions(tenant_id, expires_at);
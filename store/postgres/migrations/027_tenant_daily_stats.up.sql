@@ -0,0 +1,17 @@
+-- 027_tenant_daily_stats.up.sql
+-- Pre-aggregated per-tenant daily statistics behind platform reports, maintained
+-- by the reporting scheduler job. Member totals are snapshots; the other columns
+-- are counters incremented from domain events.
+
+CREATE TABLE IF NOT EXISTS tenant_daily_stats (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    members BIGINT NOT NULL DEFAULT 0,
+    new_members BIGINT NOT NULL DEFAULT 0,
+    logins BIGINT NOT NULL DEFAULT 0,
+    login_failures BIGINT NOT NULL DEFAULT 0,
+    tokens_issued BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_daily_stats_day ON tenant_daily_stats(day);
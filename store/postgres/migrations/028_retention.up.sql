@@ -0,0 +1,26 @@
+-- 028_retention.up.sql
+-- Per-tenant retention overrides and legal holds for the retention purge coordinator.
+
+CREATE TABLE IF NOT EXISTS retention_overrides (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    category VARCHAR(50) NOT NULL,
+    period_seconds BIGINT NOT NULL,
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, category)
+);
+
+-- Holds are kept after release as a record; tenant_id is not a foreign key so a
+-- hold outlives the tenant it protected.
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    user_id UUID,
+    reason TEXT NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    released_by VARCHAR(255),
+    released_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_holds_active ON legal_holds(tenant_id) WHERE released_at IS NULL;
@@ -0,0 +1,20 @@
+-- 029_consent_receipts.up.sql
+-- Immutable receipts of consent acts. User and client are not foreign keys so a
+-- receipt outlives the user or client it names.
+
+CREATE TABLE IF NOT EXISTS consent_receipts (
+    id UUID PRIMARY KEY,
+    version VARCHAR(16) NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    client_id UUID NOT NULL,
+    client_name VARCHAR(255) NOT NULL DEFAULT '',
+    scopes JSONB NOT NULL DEFAULT '[]'::jsonb,
+    policy_version VARCHAR(255) NOT NULL DEFAULT '',
+    collected_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    key_id VARCHAR(255) NOT NULL DEFAULT '',
+    jws TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_consent_receipts_tenant ON consent_receipts(tenant_id, collected_at);
+CREATE INDEX IF NOT EXISTS idx_consent_receipts_user ON consent_receipts(tenant_id, user_id, collected_at);
@@ -0,0 +1,56 @@
+-- 030_audit_severity.up.sql
+-- Severity and category of audit events, backfilled for events written before
+-- the columns existed. Types not listed stay info/admin.
+
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS severity VARCHAR(16) NOT NULL DEFAULT 'info';
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS category VARCHAR(16) NOT NULL DEFAULT 'admin';
+
+UPDATE audit_events e
+SET severity = c.severity, category = c.category
+FROM (VALUES
+    ('audit.read', 'warn', 'data'),
+    ('audit.read.cross_tenant', 'critical', 'data'),
+    ('brute_force_detected', 'critical', 'authn'),
+    ('client_deleted', 'warn', 'admin'),
+    ('client_trust_changed', 'critical', 'authz'),
+    ('consent_granted', 'info', 'authz'),
+    ('consent_receipts_exported', 'warn', 'data'),
+    ('consent_revoked', 'info', 'authz'),
+    ('credential_reset_required', 'warn', 'authn'),
+    ('credential_stuffing_detected', 'critical', 'authn'),
+    ('feature_flag_updated', 'warn', 'admin'),
+    ('identity_linked', 'warn', 'authn'),
+    ('identity_unlinked', 'warn', 'authn'),
+    ('integrity_repaired', 'warn', 'admin'),
+    ('legal_hold_placed', 'warn', 'data'),
+    ('legal_hold_released', 'warn', 'data'),
+    ('login_failed', 'warn', 'authn'),
+    ('login_success', 'info', 'authn'),
+    ('logout', 'info', 'authn'),
+    ('password_changed', 'info', 'authn'),
+    ('platform_admin_bootstrap', 'critical', 'authz'),
+    ('recovery_attested', 'critical', 'authn'),
+    ('recovery_cancelled', 'info', 'authn'),
+    ('recovery_completed', 'critical', 'authn'),
+    ('recovery_requested', 'warn', 'authn'),
+    ('role_assigned', 'warn', 'authz'),
+    ('role_mapping_created', 'warn', 'authz'),
+    ('role_mapping_deleted', 'warn', 'authz'),
+    ('role_revoked', 'warn', 'authz'),
+    ('secret_rotated', 'warn', 'admin'),
+    ('source_allowlist_removed', 'info', 'authn'),
+    ('source_allowlisted', 'warn', 'authn'),
+    ('source_blocked', 'warn', 'authn'),
+    ('source_unblocked', 'info', 'authn'),
+    ('suspicious_login', 'critical', 'authn'),
+    ('tenant_deleted', 'critical', 'admin'),
+    ('token_issued', 'info', 'authz'),
+    ('token_revoked', 'info', 'authz'),
+    ('user_deleted', 'warn', 'admin'),
+    ('user_locked', 'warn', 'authn'),
+    ('user_unlocked', 'info', 'authn')
+) AS c(type, severity, category)
+WHERE e.type = c.type;
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_severity ON audit_events(tenant_id, severity, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_category ON audit_events(tenant_id, category, created_at DESC);
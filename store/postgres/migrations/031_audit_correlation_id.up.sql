@@ -0,0 +1,6 @@
+-- 031_audit_correlation_id.up.sql
+-- Correlation ID of the user action that produced each audit event.
+
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(128);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_correlation_id ON audit_events(correlation_id) WHERE correlation_id IS NOT NULL;
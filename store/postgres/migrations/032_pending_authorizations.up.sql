@@ -0,0 +1,13 @@
+-- 032_pending_authorizations.up.sql
+-- Authorization requests parked while the user logs in, keyed by an opaque handle.
+
+CREATE TABLE IF NOT EXISTS pending_authorizations (
+    handle TEXT PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL DEFAULT '',
+    params JSONB NOT NULL DEFAULT '{}'::jsonb,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_authorizations_expires_at ON pending_authorizations(expires_at);
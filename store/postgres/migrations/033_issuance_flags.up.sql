@@ -0,0 +1,18 @@
+-- 033_issuance_flags.up.sql
+-- Users flagged for excessive token or session issuance, and the indexes that
+-- make per-user issuance counts cheap.
+
+CREATE TABLE IF NOT EXISTS issuance_flags (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    count INTEGER NOT NULL,
+    flag_limit INTEGER NOT NULL,
+    throttled_until TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_issuance_flags_expires_at ON issuance_flags(expires_at);
+CREATE INDEX IF NOT EXISTS idx_access_tokens_user_created_at ON access_tokens(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_created_at ON sessions(user_id, created_at);
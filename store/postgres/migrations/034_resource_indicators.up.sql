@@ -0,0 +1,6 @@
+-- 034_resource_indicators.up.sql
+-- Per-client resource (audience) registrations and the audience of each access token.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS resources JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS audience TEXT;
@@ -0,0 +1,20 @@
+-- 035_admin_tokens.up.sql
+-- Short-lived, narrowly-scoped control-plane tokens minted from admin sessions.
+-- Only the SHA-256 of each bearer value is stored.
+
+CREATE TABLE IF NOT EXISTS admin_tokens (
+    id UUID PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL,
+    scope VARCHAR(16) NOT NULL,
+    scope_context_id UUID,
+    permissions TEXT[] NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_tokens_user_id ON admin_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_admin_tokens_expires_at ON admin_tokens(expires_at);
@@ -0,0 +1,61 @@
+-- 036_assignment_history.up.sql
+-- Append-only history of role assignment grants and revocations. A trigger on
+-- rbac_assignments writes it, so every path that changes assignments (including
+-- cascades from user, role, and tenant deletion) is recorded. The actor and
+-- reason come from the transaction-local settings opentrusty.actor_id and
+-- opentrusty.change_reason when the store sets them.
+
+CREATE TABLE IF NOT EXISTS rbac_assignment_history (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(16) NOT NULL CHECK (action IN ('grant', 'revoke')),
+    user_id UUID NOT NULL,
+    role_id UUID NOT NULL,
+    scope VARCHAR(50) NOT NULL,
+    scope_context_id UUID,
+    actor_id TEXT,
+    reason TEXT,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rbac_assignment_history_user ON rbac_assignment_history(user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_rbac_assignment_history_context ON rbac_assignment_history(scope, scope_context_id, occurred_at);
+
+CREATE OR REPLACE FUNCTION record_rbac_assignment_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    actor TEXT := NULLIF(current_setting('opentrusty.actor_id', true), '');
+    why TEXT := NULLIF(current_setting('opentrusty.change_reason', true), '');
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO rbac_assignment_history (action, user_id, role_id, scope, scope_context_id, actor_id, reason)
+        VALUES ('grant', NEW.user_id, NEW.role_id, NEW.scope, NEW.scope_context_id, COALESCE(actor, NEW.granted_by::text), why);
+        RETURN NEW;
+    END IF;
+    INSERT INTO rbac_assignment_history (action, user_id, role_id, scope, scope_context_id, actor_id, reason)
+    VALUES ('revoke', OLD.user_id, OLD.role_id, OLD.scope, OLD.scope_context_id, actor, why);
+    RETURN OLD;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS rbac_assignments_history ON rbac_assignments;
+CREATE TRIGGER rbac_assignments_history
+    AFTER INSERT OR DELETE ON rbac_assignments
+    FOR EACH ROW EXECUTE FUNCTION record_rbac_assignment_change();
+
+CREATE OR REPLACE FUNCTION reject_rbac_assignment_history_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'rbac_assignment_history is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS rbac_assignment_history_append_only ON rbac_assignment_history;
+CREATE TRIGGER rbac_assignment_history_append_only
+    BEFORE UPDATE OR DELETE ON rbac_assignment_history
+    FOR EACH ROW EXECUTE FUNCTION reject_rbac_assignment_history_change();
+
+-- Existing assignments start the history as grants at their original time.
+INSERT INTO rbac_assignment_history (action, user_id, role_id, scope, scope_context_id, actor_id, reason, occurred_at)
+SELECT 'grant', a.user_id, a.role_id, a.scope, a.scope_context_id, a.granted_by::text, 'backfill', a.granted_at
+FROM rbac_assignments a
+WHERE NOT EXISTS (SELECT 1 FROM rbac_assignment_history);
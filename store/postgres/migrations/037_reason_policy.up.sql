@@ -0,0 +1,4 @@
+-- 037_reason_policy.up.sql
+-- Per-tenant list of privileged operations that require a justification.
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS reason_required_for TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,19 @@
+-- 038_signing_keys.up.sql
+-- Per-tenant token signing keys and their rotation state. Private keys are
+-- stored as compact JWEs under a key derived from the deployment secret.
+
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    algorithm VARCHAR(16) NOT NULL,
+    state VARCHAR(16) NOT NULL,
+    public_key JSONB NOT NULL,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    activated_at TIMESTAMP,
+    retired_at TIMESTAMP
+);
+
+-- At most one next and one active key per tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_tenant_state ON signing_keys(tenant_id, state) WHERE state <> 'retired';
+CREATE INDEX IF NOT EXISTS idx_signing_keys_retired_at ON signing_keys(retired_at) WHERE state = 'retired';
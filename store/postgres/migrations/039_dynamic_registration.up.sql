@@ -0,0 +1,10 @@
+-- 039_dynamic_registration.up.sql
+-- Per-tenant dynamic client registration policy (RFC 7591) and the hashed
+-- registration access token of dynamically registered clients.
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS registration_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS registration_grant_types TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS registration_scopes TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS registration_auth_methods TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS registration_token_hash TEXT NOT NULL DEFAULT '';
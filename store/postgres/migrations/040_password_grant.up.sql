@@ -0,0 +1,5 @@
+-- 040_password_grant.up.sql
+-- Per-client opt-in to the resource owner password credentials grant for
+-- legacy integrations. Tenants must also enable the password_grant_allowed flag.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS password_grant_enabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- 041_jwt_access_tokens.up.sql
+-- Per-client opt-in to self-contained JWT access tokens (RFC 9068) signed with
+-- the tenant's signing keys. Opaque access tokens remain the default.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS jwt_access_tokens BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,7 @@
+-- 042_client_id_namespace.up.sql
+-- client_id is addressed within its tenant: every lookup is keyed by
+-- (tenant_id, client_id), and this index is the constraint behind that key.
+-- The global UNIQUE (client_id) stays, as token and consent tables reference it;
+-- server-generated client_ids are redrawn on collision.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth2_clients_tenant_client_id ON oauth2_clients(tenant_id, client_id);
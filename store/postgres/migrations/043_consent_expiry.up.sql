@@ -0,0 +1,4 @@
+-- 043_consent_expiry.up.sql
+-- Optional expiry of remembered consent. NULL keeps a grant until it is revoked.
+
+ALTER TABLE consent_grants ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
@@ -0,0 +1,7 @@
+-- 044_profile_phone_address.up.sql
+-- Phone number and postal address profile claims, released by the OIDC phone and
+-- address scopes (OpenID Connect Core 1.0 Section 5.4).
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_number VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_number_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS address JSONB NOT NULL DEFAULT '{}';
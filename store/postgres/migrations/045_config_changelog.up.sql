@@ -0,0 +1,27 @@
+-- 045_config_changelog.up.sql
+-- Append-only field-level change log of client and tenant configuration. Rows
+-- outlive the resource they describe, so there is no foreign key.
+
+CREATE TABLE IF NOT EXISTS config_changes (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    resource VARCHAR(32) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    actor_id TEXT,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_changes_resource ON config_changes(tenant_id, resource, resource_id, created_at DESC);
+
+CREATE OR REPLACE FUNCTION reject_config_change_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'config_changes is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS config_changes_append_only ON config_changes;
+CREATE TRIGGER config_changes_append_only
+    BEFORE UPDATE OR DELETE ON config_changes
+    FOR EACH ROW EXECUTE FUNCTION reject_config_change_modification();
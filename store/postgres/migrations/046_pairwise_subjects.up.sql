@@ -0,0 +1,19 @@
+-- 046_pairwise_subjects.up.sql
+-- Pairwise subject identifiers (OpenID Connect Core 1.0 Section 8.1). Clients
+-- choose a subject type and optionally the sector their identifiers are derived
+-- for; issued pairwise identifiers are recorded so they can be resolved back to
+-- the user.
+
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS subject_type VARCHAR(16) NOT NULL DEFAULT 'public';
+ALTER TABLE oauth2_clients ADD COLUMN IF NOT EXISTS sector_identifier VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS pairwise_subjects (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    sector VARCHAR(255) NOT NULL,
+    subject VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, sector, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pairwise_subjects_user ON pairwise_subjects(user_id);
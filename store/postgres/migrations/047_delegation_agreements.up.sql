@@ -0,0 +1,30 @@
+-- 047_delegation_agreements.up.sql
+-- Delegation agreements: persistent grants under which a service account mints
+-- user-scoped access tokens for approved background jobs. Tokens issued under an
+-- agreement carry its id as their grant_id. Approving agreements is a separate
+-- permission, held by tenant owners by default.
+
+CREATE TABLE IF NOT EXISTS delegation_agreements (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES oauth2_clients(client_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scopes JSONB NOT NULL DEFAULT '[]'::jsonb,
+    purpose VARCHAR(255) NOT NULL,
+    approved_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoked_by TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_delegation_agreements_user ON delegation_agreements(tenant_id, user_id, created_at DESC);
+
+INSERT INTO rbac_permissions (id, name, created_at) VALUES
+('00000000-0000-0000-0000-000000000009', 'tenant:approve_delegations', NOW())
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO rbac_role_permissions (role_id, permission_id)
+SELECT '00000000-0000-0000-0000-000000000002', id FROM rbac_permissions WHERE name = 'tenant:approve_delegations'
+ON CONFLICT DO NOTHING;
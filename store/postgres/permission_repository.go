@@ -0,0 +1,93 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/id"
+)
+
+// PermissionRepository implements seed.PermissionRepository
+type PermissionRepository struct {
+	db *DB
+}
+
+// NewPermissionRepository creates a new permission repository
+func NewPermissionRepository(db *DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+// ListPermissions returns all permission names
+func (r *PermissionRepository) ListPermissions(ctx context.Context) ([]string, error) {
+	rows, err := r.db.pool.Query(ctx, `SELECT name FROM rbac_permissions ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// CreatePermission adds a permission; existing names are ignored
+func (r *PermissionRepository) CreatePermission(ctx context.Context, name string) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO rbac_permissions (id, name, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (name) DO NOTHING
+	`, id.NewUUIDv7(), name)
+
+	if err != nil {
+		return fmt.Errorf("failed to create permission: %w", err)
+	}
+
+	return nil
+}
+
+// SetRolePermissions replaces a role's permissions with exactly permissions
+func (r *PermissionRepository) SetRolePermissions(ctx context.Context, roleID string, permissions []string) error {
+	tx, err := r.db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM rbac_role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return fmt.Errorf("failed to clear role permissions: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO rbac_role_permissions (role_id, permission_id)
+		SELECT $1, id FROM rbac_permissions WHERE name = ANY($2)
+	`, roleID, permissions)
+	if err != nil {
+		return fmt.Errorf("failed to set role permissions: %w", err)
+	}
+	if int(result.RowsAffected()) != len(permissions) {
+		return fmt.Errorf("failed to set role permissions: %d of %d permissions exist", result.RowsAffected(), len(permissions))
+	}
+
+	return tx.Commit(ctx)
+}
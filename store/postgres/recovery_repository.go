@@ -0,0 +1,186 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/recovery"
+)
+
+// RecoveryRepository implements recovery.Repository
+type RecoveryRepository struct {
+	db *DB
+}
+
+// NewRecoveryRepository creates a new recovery repository
+func NewRecoveryRepository(db *DB) *RecoveryRepository {
+	return &RecoveryRepository{db: db}
+}
+
+const recoveryColumns = `id, tenant_id, user_id, method, status, reason, token_hash, available_at, expires_at,
+	COALESCE(approved_by::text, ''), approved_at, justification, created_at, updated_at`
+
+// Create persists a new request. Open requests of the user that already
+// expired are closed first so they do not block a new one.
+func (r *RecoveryRepository) Create(ctx context.Context, req *recovery.Request) error {
+	tx, err := r.db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE recovery_requests SET status = $4, updated_at = $3
+		WHERE tenant_id = $1 AND user_id = $2 AND status IN ('pending', 'approved') AND expires_at <= $3
+	`, req.TenantID, req.UserID, req.CreatedAt, recovery.StatusCancelled)
+	if err != nil {
+		return fmt.Errorf("failed to close expired recovery requests: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO recovery_requests (id, tenant_id, user_id, method, status, reason, token_hash,
+			available_at, expires_at, justification, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT DO NOTHING
+	`, req.ID, req.TenantID, req.UserID, req.Method, req.Status, req.Reason, req.TokenHash,
+		req.AvailableAt, req.ExpiresAt, req.Justification, req.CreatedAt, req.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create recovery request: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return recovery.ErrRecoveryPending
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Get returns a request of a tenant
+func (r *RecoveryRepository) Get(ctx context.Context, tenantID, id string) (*recovery.Request, error) {
+	req, err := scanRecoveryRequest(r.db.pool.QueryRow(ctx, `
+		SELECT `+recoveryColumns+`
+		FROM recovery_requests
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, recovery.ErrRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get recovery request: %w", err)
+	}
+	return req, nil
+}
+
+// ListOpen returns the tenant's pending and approved requests, oldest first
+func (r *RecoveryRepository) ListOpen(ctx context.Context, tenantID string, now time.Time) ([]*recovery.Request, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+recoveryColumns+`
+		FROM recovery_requests
+		WHERE tenant_id = $1 AND status IN ('pending', 'approved') AND expires_at > $2
+		ORDER BY created_at
+	`, tenantID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recovery requests: %w", err)
+	}
+	defer rows.Close()
+
+	var reqs []*recovery.Request
+	for rows.Next() {
+		req, err := scanRecoveryRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recovery request: %w", err)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, rows.Err()
+}
+
+// Update saves req if its stored status still equals from
+func (r *RecoveryRepository) Update(ctx context.Context, req *recovery.Request, from recovery.Status) error {
+	tag, err := r.db.pool.Exec(ctx, `
+		UPDATE recovery_requests
+		SET status = $3, approved_by = NULLIF($4, '')::uuid, approved_at = $5, justification = $6,
+			expires_at = $7, updated_at = $8
+		WHERE tenant_id = $1 AND id = $2 AND status = $9
+	`, req.TenantID, req.ID, req.Status, req.ApprovedBy, req.ApprovedAt, req.Justification,
+		req.ExpiresAt, req.UpdatedAt, from)
+	if err != nil {
+		return fmt.Errorf("failed to update recovery request: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return recovery.ErrRequestClosed
+	}
+	return nil
+}
+
+// DeleteExpired removes requests that expired before now
+func (r *RecoveryRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `DELETE FROM recovery_requests WHERE expires_at < $1`, now)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired recovery requests: %w", err)
+	}
+	return nil
+}
+
+// GetPolicy returns a tenant's recovery policy
+func (r *RecoveryRepository) GetPolicy(ctx context.Context, tenantID string) (*recovery.Policy, error) {
+	var p recovery.Policy
+	var delay, stepUp int64
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT delayed_enabled, delay_seconds, admin_attestation_enabled, step_up_max_age_seconds
+		FROM recovery_policies
+		WHERE tenant_id = $1
+	`, tenantID).Scan(&p.DelayedEnabled, &delay, &p.AdminAttestationEnabled, &stepUp)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, recovery.ErrPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get recovery policy: %w", err)
+	}
+	p.Delay = time.Duration(delay) * time.Second
+	p.StepUpMaxAge = time.Duration(stepUp) * time.Second
+	return &p, nil
+}
+
+// SetPolicy creates or replaces a tenant's recovery policy
+func (r *RecoveryRepository) SetPolicy(ctx context.Context, tenantID string, p *recovery.Policy) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO recovery_policies (tenant_id, delayed_enabled, delay_seconds, admin_attestation_enabled, step_up_max_age_seconds, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			delayed_enabled = EXCLUDED.delayed_enabled,
+			delay_seconds = EXCLUDED.delay_seconds,
+			admin_attestation_enabled = EXCLUDED.admin_attestation_enabled,
+			step_up_max_age_seconds = EXCLUDED.step_up_max_age_seconds,
+			updated_at = EXCLUDED.updated_at
+	`, tenantID, p.DelayedEnabled, int64(p.Delay/time.Second), p.AdminAttestationEnabled, int64(p.StepUpMaxAge/time.Second))
+	if err != nil {
+		return fmt.Errorf("failed to set recovery policy: %w", err)
+	}
+	return nil
+}
+
+func scanRecoveryRequest(row pgx.Row) (*recovery.Request, error) {
+	var req recovery.Request
+	err := row.Scan(&req.ID, &req.TenantID, &req.UserID, &req.Method, &req.Status, &req.Reason, &req.TokenHash,
+		&req.AvailableAt, &req.ExpiresAt, &req.ApprovedBy, &req.ApprovedAt, &req.Justification, &req.CreatedAt, &req.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
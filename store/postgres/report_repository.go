@@ -0,0 +1,107 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/reporting"
+)
+
+// ReportRepository implements reporting.Repository
+type ReportRepository struct {
+	db *DB
+}
+
+// NewReportRepository creates a new report repository
+func NewReportRepository(db *DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// AddCounters increments a tenant's counters for day
+func (r *ReportRepository) AddCounters(ctx context.Context, tenantID string, day time.Time, c reporting.Counters) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO tenant_daily_stats (tenant_id, day, logins, login_failures, tokens_issued)
+		VALUES ($1, $2::date, $3, $4, $5)
+		ON CONFLICT (tenant_id, day) DO UPDATE
+		SET logins = tenant_daily_stats.logins + EXCLUDED.logins,
+		    login_failures = tenant_daily_stats.login_failures + EXCLUDED.login_failures,
+		    tokens_issued = tenant_daily_stats.tokens_issued + EXCLUDED.tokens_issued
+	`, tenantID, day, c.Logins, c.LoginFailures, c.TokensIssued)
+
+	if err != nil {
+		return fmt.Errorf("failed to add tenant counters: %w", err)
+	}
+
+	return nil
+}
+
+// SnapshotMembers records every live tenant's member total and new members on day
+func (r *ReportRepository) SnapshotMembers(ctx context.Context, day time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO tenant_daily_stats (tenant_id, day, members, new_members)
+		SELECT t.id, $1::date,
+			COUNT(m.id),
+			COUNT(m.id) FILTER (WHERE m.created_at >= $1::date)
+		FROM tenants t
+		LEFT JOIN tenant_members m ON m.tenant_id = t.id AND m.created_at < $1::date + 1
+		WHERE t.deleted_at IS NULL
+		GROUP BY t.id
+		ON CONFLICT (tenant_id, day) DO UPDATE
+		SET members = EXCLUDED.members, new_members = EXCLUDED.new_members
+	`, day)
+
+	if err != nil {
+		return fmt.Errorf("failed to snapshot tenant members: %w", err)
+	}
+
+	return nil
+}
+
+// Series returns per-tenant points for q, ordered by tenant and bucket start
+func (r *ReportRepository) Series(ctx context.Context, q reporting.Query) ([]*reporting.Series, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT tenant_id::text, date_trunc($4::text, day::timestamp) AS bucket,
+			(array_agg(members ORDER BY day DESC))[1],
+			SUM(new_members)::bigint, SUM(logins)::bigint, SUM(login_failures)::bigint, SUM(tokens_issued)::bigint
+		FROM tenant_daily_stats
+		WHERE day >= $1::date AND day < $2 AND ($3 = '' OR tenant_id::text = $3)
+		GROUP BY tenant_id, bucket
+		ORDER BY tenant_id, bucket
+	`, q.From, q.To, q.TenantID, string(q.Bucket))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant stats: %w", err)
+	}
+	defer rows.Close()
+
+	var series []*reporting.Series
+	var cur *reporting.Series
+	for rows.Next() {
+		var tenantID string
+		var p reporting.Point
+		if err := rows.Scan(&tenantID, &p.Start, &p.Members, &p.NewMembers, &p.Logins, &p.LoginFailures, &p.TokensIssued); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant stats: %w", err)
+		}
+		if cur == nil || cur.TenantID != tenantID {
+			cur = &reporting.Series{TenantID: tenantID}
+			series = append(series, cur)
+		}
+		cur.Points = append(cur.Points, &p)
+	}
+
+	return series, rows.Err()
+}
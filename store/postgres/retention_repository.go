@@ -0,0 +1,218 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/retention"
+)
+
+// retentionTarget describes where the records of a retention.Category live.
+// tenant and user are text expressions; extra further restricts what may be purged.
+type retentionTarget struct {
+	table  string
+	age    string
+	tenant string
+	user   string
+	extra  string
+}
+
+var retentionTargets = map[retention.Category]retentionTarget{
+	retention.CategorySessions: {
+		table: "sessions", age: "expires_at",
+		tenant: "COALESCE(tenant_id::text, '')", user: "user_id::text",
+	},
+	retention.CategoryAccessTokens: {
+		table: "access_tokens", age: "expires_at",
+		tenant: "tenant_id::text", user: "user_id::text",
+	},
+	retention.CategoryRefreshTokens: {
+		table: "refresh_tokens", age: "expires_at",
+		tenant: "tenant_id::text", user: "user_id::text",
+	},
+	retention.CategoryAuthorizationCodes: {
+		table: "authorization_codes", age: "expires_at",
+		tenant: "COALESCE((SELECT c.tenant_id::text FROM oauth2_clients c WHERE c.client_id = authorization_codes.client_id), '')",
+		user:   "user_id::text",
+	},
+	retention.CategoryAuditEvents: {
+		table: "audit_events", age: "created_at",
+		tenant: "COALESCE(tenant_id, '')", user: "COALESCE(actor_id, '')",
+	},
+	retention.CategoryLoginHistory: {
+		table: "login_locations", age: "created_at",
+		tenant: "COALESCE(tenant_id::text, '')", user: "user_id::text",
+	},
+	retention.CategoryWebhookDeliveries: {
+		table: "webhook_deliveries", age: "created_at",
+		tenant: "tenant_id::text", user: "''",
+		extra: "status <> 'pending'",
+	},
+}
+
+// RetentionRepository implements retention.Repository
+type RetentionRepository struct {
+	db *DB
+}
+
+// NewRetentionRepository creates a new retention repository
+func NewRetentionRepository(db *DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// Purge deletes records of category aged past cutoff within scope
+func (r *RetentionRepository) Purge(ctx context.Context, category retention.Category, cutoff time.Time, scope retention.Scope) (int64, error) {
+	t, ok := retentionTargets[category]
+	if !ok {
+		return 0, retention.ErrUnknownCategory
+	}
+
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE %s < $1
+			AND ($2 = '' OR %s = $2)
+			AND NOT (%s = ANY($3))
+			AND NOT (%s = ANY($4))`, t.table, t.age, t.tenant, t.tenant, t.user)
+	if t.extra != "" {
+		query += " AND " + t.extra
+	}
+
+	excludeTenants := scope.ExcludeTenants
+	if excludeTenants == nil {
+		excludeTenants = []string{}
+	}
+	excludeUsers := scope.ExcludeUsers
+	if excludeUsers == nil {
+		excludeUsers = []string{}
+	}
+
+	tag, err := r.db.pool.Exec(ctx, query, cutoff, scope.TenantID, excludeTenants, excludeUsers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", category, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListOverrides returns every tenant override
+func (r *RetentionRepository) ListOverrides(ctx context.Context) ([]*retention.Override, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT tenant_id, category, period_seconds, updated_by, updated_at
+		FROM retention_overrides
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*retention.Override
+	for rows.Next() {
+		var o retention.Override
+		var seconds int64
+		if err := rows.Scan(&o.TenantID, &o.Category, &seconds, &o.UpdatedBy, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan retention override: %w", err)
+		}
+		o.Period = time.Duration(seconds) * time.Second
+		overrides = append(overrides, &o)
+	}
+
+	return overrides, rows.Err()
+}
+
+// SetOverride creates or replaces a tenant override
+func (r *RetentionRepository) SetOverride(ctx context.Context, o *retention.Override) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO retention_overrides (tenant_id, category, period_seconds, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, category) DO UPDATE
+		SET period_seconds = EXCLUDED.period_seconds, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, o.TenantID, o.Category, int64(o.Period/time.Second), o.UpdatedBy, o.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to set retention override: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOverride removes a tenant override
+func (r *RetentionRepository) DeleteOverride(ctx context.Context, tenantID string, category retention.Category) error {
+	tag, err := r.db.pool.Exec(ctx, `
+		DELETE FROM retention_overrides WHERE tenant_id = $1 AND category = $2
+	`, tenantID, category)
+	if err != nil {
+		return fmt.Errorf("failed to delete retention override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return retention.ErrOverrideNotFound
+	}
+	return nil
+}
+
+// CreateHold persists a new legal hold
+func (r *RetentionRepository) CreateHold(ctx context.Context, h *retention.LegalHold) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO legal_holds (id, tenant_id, user_id, reason, created_by, created_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6)
+	`, h.ID, h.TenantID, h.UserID, h.Reason, h.CreatedBy, h.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create legal hold: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseHold marks an active hold released
+func (r *RetentionRepository) ReleaseHold(ctx context.Context, id, releasedBy string, at time.Time) error {
+	tag, err := r.db.pool.Exec(ctx, `
+		UPDATE legal_holds SET released_by = $2, released_at = $3
+		WHERE id = $1 AND released_at IS NULL
+	`, id, releasedBy, at)
+	if err != nil {
+		return fmt.Errorf("failed to release legal hold: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return retention.ErrHoldNotFound
+	}
+	return nil
+}
+
+// ListActiveHolds returns every hold that has not been released
+func (r *RetentionRepository) ListActiveHolds(ctx context.Context) ([]*retention.LegalHold, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, tenant_id, COALESCE(user_id::text, ''), reason, created_by, created_at
+		FROM legal_holds
+		WHERE released_at IS NULL
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legal holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []*retention.LegalHold
+	for rows.Next() {
+		var h retention.LegalHold
+		if err := rows.Scan(&h.ID, &h.TenantID, &h.UserID, &h.Reason, &h.CreatedBy, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan legal hold: %w", err)
+		}
+		holds = append(holds, &h)
+	}
+
+	return holds, rows.Err()
+}
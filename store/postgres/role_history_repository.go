@@ -0,0 +1,162 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// defaultHistoryLimit caps ListChanges when the filter sets no limit.
+const defaultHistoryLimit = 500
+
+// RoleHistoryRepository implements role.HistoryRepository
+type RoleHistoryRepository struct {
+	db *DB
+}
+
+// NewRoleHistoryRepository creates a new role history repository
+func NewRoleHistoryRepository(db *DB) *RoleHistoryRepository {
+	return &RoleHistoryRepository{db: db}
+}
+
+// ListChanges returns matching entries, newest first
+func (r *RoleHistoryRepository) ListChanges(ctx context.Context, f role.HistoryFilter) ([]*role.AssignmentChange, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.UserID != "" {
+		add("user_id = $%d", f.UserID)
+	}
+	if f.RoleID != "" {
+		add("role_id = $%d", f.RoleID)
+	}
+	if f.Scope != "" {
+		add("scope = $%d", string(f.Scope))
+	}
+	if f.ScopeContextID != nil {
+		add("scope_context_id = $%d", *f.ScopeContextID)
+	}
+	if !f.Since.IsZero() {
+		add("occurred_at >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("occurred_at < $%d", f.Until)
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > defaultHistoryLimit {
+		limit = defaultHistoryLimit
+	}
+
+	query := `
+		SELECT id, action, user_id, role_id, scope, scope_context_id, COALESCE(actor_id, ''), COALESCE(reason, ''), occurred_at
+		FROM rbac_assignment_history`
+	if len(where) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf("\n\t\tORDER BY occurred_at DESC, id DESC\n\t\tLIMIT $%d", len(args))
+
+	rows, err := r.db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assignment history: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*role.AssignmentChange
+	for rows.Next() {
+		var c role.AssignmentChange
+		if err := rows.Scan(&c.ID, &c.Action, &c.UserID, &c.RoleID, &c.Scope, &c.ScopeContextID, &c.ActorID, &c.Reason, &c.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment history: %w", err)
+		}
+		changes = append(changes, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list assignment history: %w", err)
+	}
+	return changes, nil
+}
+
+// AssignmentsAt returns the assignments in force at scope and context at time at.
+// An assignment is in force when its latest history entry at or before at is a grant.
+func (r *RoleHistoryRepository) AssignmentsAt(ctx context.Context, scope role.Scope, scopeContextID *string, at time.Time) ([]*role.Assignment, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT user_id, role_id, scope, scope_context_id, occurred_at, COALESCE(actor_id, '')
+		FROM (
+			SELECT DISTINCT ON (user_id, role_id) user_id, role_id, scope, scope_context_id, occurred_at, actor_id, action
+			FROM rbac_assignment_history
+			WHERE scope = $1 AND scope_context_id IS NOT DISTINCT FROM $2 AND occurred_at <= $3
+			ORDER BY user_id, role_id, occurred_at DESC, id DESC
+		) latest
+		WHERE action = 'grant'
+		ORDER BY occurred_at
+	`, string(scope), scopeContextID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list historical assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*role.Assignment
+	for rows.Next() {
+		var a role.Assignment
+		if err := rows.Scan(&a.UserID, &a.RoleID, &a.Scope, &a.ScopeContextID, &a.GrantedAt, &a.GrantedBy); err != nil {
+			return nil, fmt.Errorf("failed to scan historical assignment: %w", err)
+		}
+		assignments = append(assignments, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list historical assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+// execAttributed runs sql in a transaction carrying the change attribution of
+// ctx (role.WithChangeAttribution), which the assignment history trigger
+// records. The reason falls back to the operation's audit.Reason. Without
+// attribution it runs sql directly.
+func (db *DB) execAttributed(ctx context.Context, sql string, args ...any) error {
+	actorID, reason := role.ChangeAttribution(ctx)
+	if reason == "" {
+		reason = audit.Reason(ctx)
+	}
+	if actorID == "" && reason == "" {
+		_, err := db.pool.Exec(ctx, sql, args...)
+		return err
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		SELECT set_config('opentrusty.actor_id', $1, true), set_config('opentrusty.change_reason', $2, true)
+	`, actorID, reason); err != nil {
+		return fmt.Errorf("failed to attribute change: %w", err)
+	}
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/rolemap"
+)
+
+// RoleMappingRepository implements rolemap.Repository
+type RoleMappingRepository struct {
+	db *DB
+}
+
+// NewRoleMappingRepository creates a new role mapping rule repository
+func NewRoleMappingRepository(db *DB) *RoleMappingRepository {
+	return &RoleMappingRepository{db: db}
+}
+
+// Create persists a new rule
+func (r *RoleMappingRepository) Create(ctx context.Context, rule *rolemap.Rule) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO role_mapping_rules (id, tenant_id, claim, value, role, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+	`, rule.ID, rule.TenantID, rule.Claim, rule.Value, rule.Role, rule.CreatedBy, rule.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create role mapping rule: %w", err)
+	}
+
+	return nil
+}
+
+// List returns every rule of a tenant
+func (r *RoleMappingRepository) List(ctx context.Context, tenantID string) ([]*rolemap.Rule, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, tenant_id, claim, value, role, COALESCE(created_by, ''), created_at
+		FROM role_mapping_rules
+		WHERE tenant_id = $1
+		ORDER BY created_at
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role mapping rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*rolemap.Rule
+	for rows.Next() {
+		var rule rolemap.Rule
+		if err := rows.Scan(&rule.ID, &rule.TenantID, &rule.Claim, &rule.Value, &rule.Role, &rule.CreatedBy, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan role mapping rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// Delete removes a rule of a tenant
+func (r *RoleMappingRepository) Delete(ctx context.Context, tenantID, id string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM role_mapping_rules WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete role mapping rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return rolemap.ErrRuleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/scim"
+)
+
+// SCIMRepository implements scim.Repository
+type SCIMRepository struct {
+	db *DB
+}
+
+// NewSCIMRepository creates a new SCIM repository
+func NewSCIMRepository(db *DB) *SCIMRepository {
+	return &SCIMRepository{db: db}
+}
+
+// CreateTarget persists a new target
+func (r *SCIMRepository) CreateTarget(ctx context.Context, t *scim.Target) error {
+	mapping, err := json.Marshal(t.AttributeMapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal attribute mapping: %w", err)
+	}
+
+	_, err = r.db.pool.Exec(ctx, `
+		INSERT INTO scim_targets (id, tenant_id, name, base_url, token, attribute_mapping, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, t.ID, t.TenantID, t.Name, t.BaseURL, t.Token, mapping, t.Enabled, t.CreatedAt, t.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create scim target: %w", err)
+	}
+
+	return nil
+}
+
+// GetTarget retrieves a target within a tenant
+func (r *SCIMRepository) GetTarget(ctx context.Context, tenantID, id string) (*scim.Target, error) {
+	t, err := scanTarget(r.db.pool.QueryRow(ctx, `
+		SELECT id, tenant_id, name, base_url, token, attribute_mapping, enabled, created_at, updated_at
+		FROM scim_targets
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id))
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, scim.ErrTargetNotFound
+		}
+		return nil, fmt.Errorf("failed to get scim target: %w", err)
+	}
+
+	return t, nil
+}
+
+// ListTargets returns all targets of a tenant
+func (r *SCIMRepository) ListTargets(ctx context.Context, tenantID string) ([]*scim.Target, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, tenant_id, name, base_url, token, attribute_mapping, enabled, created_at, updated_at
+		FROM scim_targets
+		WHERE tenant_id = $1
+		ORDER BY created_at
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scim targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []*scim.Target
+	for rows.Next() {
+		t, err := scanTarget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scim target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+
+	return targets, rows.Err()
+}
+
+// UpdateTarget updates name, URL, token, mapping, and enabled state
+func (r *SCIMRepository) UpdateTarget(ctx context.Context, t *scim.Target) error {
+	mapping, err := json.Marshal(t.AttributeMapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal attribute mapping: %w", err)
+	}
+
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE scim_targets
+		SET name = $3, base_url = $4, token = $5, attribute_mapping = $6, enabled = $7, updated_at = $8
+		WHERE tenant_id = $1 AND id = $2
+	`, t.TenantID, t.ID, t.Name, t.BaseURL, t.Token, mapping, t.Enabled, t.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update scim target: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return scim.ErrTargetNotFound
+	}
+
+	return nil
+}
+
+// DeleteTarget removes a target with its operations and links
+func (r *SCIMRepository) DeleteTarget(ctx context.Context, tenantID, id string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM scim_targets WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete scim target: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return scim.ErrTargetNotFound
+	}
+
+	return nil
+}
+
+// CreateOperation persists a pending operation
+func (r *SCIMRepository) CreateOperation(ctx context.Context, op *scim.Operation) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO scim_operations (id, target_id, tenant_id, user_id, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, op.ID, op.TargetID, op.TenantID, op.UserID, op.Status, op.Attempts, op.NextAttemptAt, op.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create scim operation: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateOperation records the outcome of an attempt
+func (r *SCIMRepository) UpdateOperation(ctx context.Context, op *scim.Operation) error {
+	_, err := r.db.pool.Exec(ctx, `
+		UPDATE scim_operations
+		SET action = NULLIF($2, ''), status = $3, attempts = $4, last_status_code = $5,
+		    last_error = NULLIF($6, ''), next_attempt_at = $7, delivered_at = $8
+		WHERE id = $1
+	`, op.ID, op.Action, op.Status, op.Attempts, op.LastStatusCode, op.LastError, op.NextAttemptAt, op.DeliveredAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update scim operation: %w", err)
+	}
+
+	return nil
+}
+
+// ListDueOperations returns pending operations whose next attempt is at or before now
+func (r *SCIMRepository) ListDueOperations(ctx context.Context, now time.Time, limit int) ([]*scim.Operation, error) {
+	return r.listOperations(ctx, `
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $2
+	`, now, limit)
+}
+
+// ListOperations returns the most recent operations for a target
+func (r *SCIMRepository) ListOperations(ctx context.Context, tenantID, targetID string, limit int) ([]*scim.Operation, error) {
+	return r.listOperations(ctx, `
+		WHERE tenant_id = $1 AND target_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, tenantID, targetID, limit)
+}
+
+func (r *SCIMRepository) listOperations(ctx context.Context, where string, args ...any) ([]*scim.Operation, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, target_id, tenant_id, user_id, COALESCE(action, ''), status, attempts,
+		       last_status_code, COALESCE(last_error, ''), next_attempt_at, created_at, delivered_at
+		FROM scim_operations
+	`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scim operations: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []*scim.Operation
+	for rows.Next() {
+		var op scim.Operation
+		if err := rows.Scan(
+			&op.ID, &op.TargetID, &op.TenantID, &op.UserID, &op.Action, &op.Status, &op.Attempts,
+			&op.LastStatusCode, &op.LastError, &op.NextAttemptAt, &op.CreatedAt, &op.DeliveredAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan scim operation: %w", err)
+		}
+		ops = append(ops, &op)
+	}
+
+	return ops, rows.Err()
+}
+
+// GetLink retrieves the downstream resource ID of a user on a target
+func (r *SCIMRepository) GetLink(ctx context.Context, targetID, userID string) (*scim.Link, error) {
+	var l scim.Link
+
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT target_id, tenant_id, user_id, external_id, created_at
+		FROM scim_links
+		WHERE target_id = $1 AND user_id = $2
+	`, targetID, userID).Scan(&l.TargetID, &l.TenantID, &l.UserID, &l.ExternalID, &l.CreatedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, scim.ErrLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get scim link: %w", err)
+	}
+
+	return &l, nil
+}
+
+// SaveLink creates or replaces a link
+func (r *SCIMRepository) SaveLink(ctx context.Context, l *scim.Link) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO scim_links (target_id, tenant_id, user_id, external_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (target_id, user_id) DO UPDATE SET external_id = EXCLUDED.external_id
+	`, l.TargetID, l.TenantID, l.UserID, l.ExternalID, l.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save scim link: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteLink removes a link
+func (r *SCIMRepository) DeleteLink(ctx context.Context, targetID, userID string) error {
+	_, err := r.db.pool.Exec(ctx, `
+		DELETE FROM scim_links WHERE target_id = $1 AND user_id = $2
+	`, targetID, userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete scim link: %w", err)
+	}
+
+	return nil
+}
+
+// ListLinksForUser returns every link of a user across targets
+func (r *SCIMRepository) ListLinksForUser(ctx context.Context, userID string) ([]*scim.Link, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT target_id, tenant_id, user_id, external_id, created_at
+		FROM scim_links
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scim links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*scim.Link
+	for rows.Next() {
+		var l scim.Link
+		if err := rows.Scan(&l.TargetID, &l.TenantID, &l.UserID, &l.ExternalID, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scim link: %w", err)
+		}
+		links = append(links, &l)
+	}
+
+	return links, rows.Err()
+}
+
+func scanTarget(row pgx.Row) (*scim.Target, error) {
+	var t scim.Target
+	var mapping []byte
+	if err := row.Scan(&t.ID, &t.TenantID, &t.Name, &t.BaseURL, &t.Token, &mapping, &t.Enabled, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mapping, &t.AttributeMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal attribute mapping: %w", err)
+	}
+	return &t, nil
+}
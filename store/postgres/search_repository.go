@@ -0,0 +1,123 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/search"
+)
+
+// likeEscaper escapes LIKE wildcards so a query matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchRepository implements search.Repository
+type SearchRepository struct {
+	db *DB
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// SearchUsers returns active members of the tenant whose name or nickname contains query,
+// prefix matches first
+func (r *SearchRepository) SearchUsers(ctx context.Context, tenantID, query string, limit int) ([]search.UserHit, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT u.id, COALESCE(u.full_name, ''), COALESCE(u.nickname, ''), COALESCE(u.picture, '')
+		FROM tenant_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id = $1 AND u.deleted_at IS NULL
+			AND (u.full_name ILIKE '%' || $2 || '%' ESCAPE '\' OR u.given_name ILIKE '%' || $2 || '%' ESCAPE '\'
+				OR u.family_name ILIKE '%' || $2 || '%' ESCAPE '\' OR u.nickname ILIKE '%' || $2 || '%' ESCAPE '\')
+		ORDER BY (u.full_name ILIKE $2 || '%' ESCAPE '\' OR u.nickname ILIKE $2 || '%' ESCAPE '\') DESC,
+			u.full_name, u.id
+		LIMIT $3
+	`, tenantID, likeEscaper.Replace(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []search.UserHit
+	for rows.Next() {
+		var h search.UserHit
+		if err := rows.Scan(&h.ID, &h.FullName, &h.Nickname, &h.Picture); err != nil {
+			return nil, fmt.Errorf("failed to scan user hit: %w", err)
+		}
+		hits = append(hits, h)
+	}
+
+	return hits, rows.Err()
+}
+
+// SearchClients returns clients of the tenant whose name or client_id contains query,
+// prefix matches first
+func (r *SearchRepository) SearchClients(ctx context.Context, tenantID, query string, limit int) ([]search.ClientHit, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, client_id, client_name, COALESCE(logo_uri, '')
+		FROM oauth2_clients
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+			AND (client_name ILIKE '%' || $2 || '%' ESCAPE '\' OR client_id ILIKE '%' || $2 || '%' ESCAPE '\')
+		ORDER BY (client_name ILIKE $2 || '%' ESCAPE '\') DESC, client_name, id
+		LIMIT $3
+	`, tenantID, likeEscaper.Replace(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search clients: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []search.ClientHit
+	for rows.Next() {
+		var h search.ClientHit
+		if err := rows.Scan(&h.ID, &h.ClientID, &h.ClientName, &h.LogoURI); err != nil {
+			return nil, fmt.Errorf("failed to scan client hit: %w", err)
+		}
+		hits = append(hits, h)
+	}
+
+	return hits, rows.Err()
+}
+
+// SearchRoles returns tenant-scoped roles whose name or description contains query,
+// prefix matches first
+func (r *SearchRepository) SearchRoles(ctx context.Context, query string, limit int) ([]search.RoleHit, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, name, COALESCE(description, '')
+		FROM rbac_roles
+		WHERE scope = 'tenant'
+			AND (name ILIKE '%' || $1 || '%' ESCAPE '\' OR description ILIKE '%' || $1 || '%' ESCAPE '\')
+		ORDER BY (name ILIKE $1 || '%' ESCAPE '\') DESC, name
+		LIMIT $2
+	`, likeEscaper.Replace(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search roles: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []search.RoleHit
+	for rows.Next() {
+		var h search.RoleHit
+		if err := rows.Scan(&h.ID, &h.Name, &h.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan role hit: %w", err)
+		}
+		hits = append(hits, h)
+	}
+
+	return hits, rows.Err()
+}
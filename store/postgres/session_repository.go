@@ -117,6 +117,19 @@ func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID string) e
 	return nil
 }
 
+// DeleteOthersForUser deletes all sessions for a user except keepSessionID
+func (r *SessionRepository) DeleteOthersForUser(ctx context.Context, userID, keepSessionID string) error {
+	_, err := r.db.pool.Exec(ctx, `
+		DELETE FROM sessions WHERE user_id = $1 AND id <> $2
+	`, userID, keepSessionID)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteExpired deletes all expired sessions
 func (r *SessionRepository) DeleteExpired(ctx context.Context) error {
 	_, err := r.db.pool.Exec(ctx, `
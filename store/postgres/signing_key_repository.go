@@ -0,0 +1,185 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/keys"
+)
+
+const signingKeyColumns = `id, tenant_id::text, algorithm, state, public_key, private_key, created_at, activated_at, retired_at`
+
+// SigningKeyRepository implements keys.Repository
+type SigningKeyRepository struct {
+	db *DB
+}
+
+// NewSigningKeyRepository creates a new signing key repository
+func NewSigningKeyRepository(db *DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+// Create stores a new key
+func (r *SigningKeyRepository) Create(ctx context.Context, k *keys.Key) error {
+	publicKey, err := json.Marshal(k.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	result, err := r.db.pool.Exec(ctx, `
+		INSERT INTO signing_keys (id, tenant_id, algorithm, state, public_key, private_key, created_at, activated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT DO NOTHING
+	`, k.ID, k.TenantID, k.Algorithm, string(k.State), publicKey, k.PrivateKey, k.CreatedAt, k.ActivatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create signing key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return keys.ErrKeyConflict
+	}
+
+	return nil
+}
+
+// List returns the tenant's stored keys, newest first
+func (r *SigningKeyRepository) List(ctx context.Context, tenantID string) ([]*keys.Key, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+signingKeyColumns+` FROM signing_keys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*keys.Key
+	for rows.Next() {
+		var k keys.Key
+		var state string
+		var publicKey []byte
+		if err := rows.Scan(&k.ID, &k.TenantID, &k.Algorithm, &state, &publicKey, &k.PrivateKey, &k.CreatedAt, &k.ActivatedAt, &k.RetiredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		if err := json.Unmarshal(publicKey, &k.PublicKey); err != nil {
+			return nil, fmt.Errorf("failed to decode public key: %w", err)
+		}
+		k.State = keys.State(state)
+		result = append(result, &k)
+	}
+
+	return result, rows.Err()
+}
+
+// ListTenants returns the IDs of tenants holding at least one key
+func (r *SigningKeyRepository) ListTenants(ctx context.Context) ([]string, error) {
+	rows, err := r.db.pool.Query(ctx, `SELECT DISTINCT tenant_id::text FROM signing_keys`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing key tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []string
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant id: %w", err)
+		}
+		tenants = append(tenants, tenantID)
+	}
+
+	return tenants, rows.Err()
+}
+
+// Activate retires the tenant's active key and activates its next key in one transaction
+func (r *SigningKeyRepository) Activate(ctx context.Context, tenantID, keyID string, at time.Time) error {
+	tx, err := r.db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE signing_keys SET state = 'retired', retired_at = $2
+		WHERE tenant_id = $1 AND state = 'active'
+	`, tenantID, at); err != nil {
+		return fmt.Errorf("failed to retire active signing key: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE signing_keys SET state = 'active', activated_at = $3
+		WHERE tenant_id = $1 AND id = $2 AND state = 'next'
+	`, tenantID, keyID, at)
+	if err != nil {
+		return fmt.Errorf("failed to activate signing key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return keys.ErrKeyConflict
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Retire retires a key that is not yet retired
+func (r *SigningKeyRepository) Retire(ctx context.Context, keyID string, at time.Time) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE signing_keys SET state = 'retired', retired_at = $2
+		WHERE id = $1 AND state <> 'retired'
+	`, keyID, at)
+
+	if err != nil {
+		return fmt.Errorf("failed to retire signing key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return keys.ErrKeyNotFound
+	}
+
+	return nil
+}
+
+// DeleteRetired removes the tenant's keys retired before cutoff and returns their IDs
+func (r *SigningKeyRepository) DeleteRetired(ctx context.Context, tenantID string, cutoff time.Time) ([]string, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		DELETE FROM signing_keys
+		WHERE tenant_id = $1 AND state = 'retired' AND retired_at < $2
+		RETURNING id
+	`, tenantID, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete retired signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	var deleted []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan signing key id: %w", err)
+		}
+		deleted = append(deleted, id)
+	}
+
+	return deleted, rows.Err()
+}
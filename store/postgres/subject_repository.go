@@ -0,0 +1,64 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/subject"
+)
+
+// SubjectRepository implements subject.Repository
+type SubjectRepository struct {
+	db *DB
+}
+
+// NewSubjectRepository creates a new pairwise subject repository
+func NewSubjectRepository(db *DB) *SubjectRepository {
+	return &SubjectRepository{db: db}
+}
+
+// Save records a pairwise subject mapping unless it already exists
+func (r *SubjectRepository) Save(ctx context.Context, m *subject.Mapping) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO pairwise_subjects (tenant_id, sector, subject, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, sector, subject) DO NOTHING
+	`, m.TenantID, m.Sector, m.Subject, m.UserID, m.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save pairwise subject: %w", err)
+	}
+	return nil
+}
+
+// GetBySubject returns the mapping of a pairwise subject in a tenant's sector
+func (r *SubjectRepository) GetBySubject(ctx context.Context, tenantID, sector, sub string) (*subject.Mapping, error) {
+	var m subject.Mapping
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT tenant_id, sector, subject, user_id, created_at
+		FROM pairwise_subjects
+		WHERE tenant_id::text = $1 AND sector = $2 AND subject = $3
+	`, tenantID, sector, sub).Scan(&m.TenantID, &m.Sector, &m.Subject, &m.UserID, &m.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, subject.ErrSubjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pairwise subject: %w", err)
+	}
+	return &m, nil
+}
@@ -44,9 +44,13 @@ func (r *TenantRepository) Create(ctx context.Context, t *tenant.Tenant) error {
 	}
 
 	_, err := r.db.pool.Exec(ctx, `
-		INSERT INTO tenants (id, name, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
-	`, t.ID, t.Name, t.Status, t.CreatedAt, t.UpdatedAt)
+		INSERT INTO tenants (id, name, status, signing_alg, password_max_age_days,
+			mfa_mode, mfa_roles, mfa_grace_days, mfa_enforced_at, reason_required_for,
+			registration_enabled, registration_grant_types, registration_scopes, registration_auth_methods, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`, t.ID, t.Name, t.Status, t.SigningAlg, t.PasswordMaxAgeDays,
+		mfaMode(t.MFA.Mode), mfaRoles(t.MFA.Roles), t.MFA.GraceDays, t.MFA.EnforcedAt, nonNil(t.ReasonRequiredFor),
+		t.Registration.Enabled, nonNil(t.Registration.GrantTypes), nonNil(t.Registration.Scopes), nonNil(t.Registration.AuthMethods), t.CreatedAt, t.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create tenant: %w", err)
@@ -60,11 +64,13 @@ func (r *TenantRepository) GetByID(ctx context.Context, id string) (*tenant.Tena
 	var deletedAt sql.NullTime
 
 	err := r.db.pool.QueryRow(ctx, `
-		SELECT id, name, status, created_at, updated_at, deleted_at
+		SELECT id, name, status, signing_alg, password_max_age_days, mfa_mode, mfa_roles, mfa_grace_days, mfa_enforced_at, reason_required_for,
+			registration_enabled, registration_grant_types, registration_scopes, registration_auth_methods, created_at, updated_at, deleted_at
 		FROM tenants
 		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(
-		&t.ID, &t.Name, &t.Status, &t.CreatedAt, &t.UpdatedAt, &deletedAt,
+		&t.ID, &t.Name, &t.Status, &t.SigningAlg, &t.PasswordMaxAgeDays, &t.MFA.Mode, &t.MFA.Roles, &t.MFA.GraceDays, &t.MFA.EnforcedAt, &t.ReasonRequiredFor,
+		&t.Registration.Enabled, &t.Registration.GrantTypes, &t.Registration.Scopes, &t.Registration.AuthMethods, &t.CreatedAt, &t.UpdatedAt, &deletedAt,
 	)
 
 	if err != nil {
@@ -83,11 +89,13 @@ func (r *TenantRepository) GetByName(ctx context.Context, name string) (*tenant.
 	var deletedAt sql.NullTime
 
 	err := r.db.pool.QueryRow(ctx, `
-		SELECT id, name, status, created_at, updated_at, deleted_at
+		SELECT id, name, status, signing_alg, password_max_age_days, mfa_mode, mfa_roles, mfa_grace_days, mfa_enforced_at, reason_required_for,
+			registration_enabled, registration_grant_types, registration_scopes, registration_auth_methods, created_at, updated_at, deleted_at
 		FROM tenants
 		WHERE name = $1 AND deleted_at IS NULL
 	`, name).Scan(
-		&t.ID, &t.Name, &t.Status, &t.CreatedAt, &t.UpdatedAt, &deletedAt,
+		&t.ID, &t.Name, &t.Status, &t.SigningAlg, &t.PasswordMaxAgeDays, &t.MFA.Mode, &t.MFA.Roles, &t.MFA.GraceDays, &t.MFA.EnforcedAt, &t.ReasonRequiredFor,
+		&t.Registration.Enabled, &t.Registration.GrantTypes, &t.Registration.Scopes, &t.Registration.AuthMethods, &t.CreatedAt, &t.UpdatedAt, &deletedAt,
 	)
 
 	if err != nil {
@@ -104,9 +112,15 @@ func (r *TenantRepository) GetByName(ctx context.Context, name string) (*tenant.
 func (r *TenantRepository) Update(ctx context.Context, t *tenant.Tenant) error {
 	t.UpdatedAt = time.Now()
 	result, err := r.db.pool.Exec(ctx, `
-		UPDATE tenants SET name = $2, status = $3, updated_at = $4
+		UPDATE tenants SET name = $2, status = $3, signing_alg = $4, password_max_age_days = $5,
+			mfa_mode = $6, mfa_roles = $7, mfa_grace_days = $8, mfa_enforced_at = $9,
+			reason_required_for = $10, registration_enabled = $11, registration_grant_types = $12,
+			registration_scopes = $13, registration_auth_methods = $14, updated_at = $15
 		WHERE id = $1 AND deleted_at IS NULL
-	`, t.ID, t.Name, t.Status, t.UpdatedAt)
+	`, t.ID, t.Name, t.Status, t.SigningAlg, t.PasswordMaxAgeDays,
+		mfaMode(t.MFA.Mode), mfaRoles(t.MFA.Roles), t.MFA.GraceDays, t.MFA.EnforcedAt,
+		nonNil(t.ReasonRequiredFor), t.Registration.Enabled, nonNil(t.Registration.GrantTypes),
+		nonNil(t.Registration.Scopes), nonNil(t.Registration.AuthMethods), t.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to update tenant: %w", err)
@@ -140,7 +154,8 @@ func (r *TenantRepository) Delete(ctx context.Context, id string) error {
 // List lists tenants
 func (r *TenantRepository) List(ctx context.Context, limit, offset int) ([]*tenant.Tenant, error) {
 	rows, err := r.db.pool.Query(ctx, `
-		SELECT id, name, status, created_at, updated_at
+		SELECT id, name, status, signing_alg, password_max_age_days, mfa_mode, mfa_roles, mfa_grace_days, mfa_enforced_at, reason_required_for,
+			registration_enabled, registration_grant_types, registration_scopes, registration_auth_methods, created_at, updated_at
 		FROM tenants
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -154,7 +169,8 @@ func (r *TenantRepository) List(ctx context.Context, limit, offset int) ([]*tena
 	var tenants []*tenant.Tenant
 	for rows.Next() {
 		var t tenant.Tenant
-		if err := rows.Scan(&t.ID, &t.Name, &t.Status, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.Name, &t.Status, &t.SigningAlg, &t.PasswordMaxAgeDays, &t.MFA.Mode, &t.MFA.Roles, &t.MFA.GraceDays, &t.MFA.EnforcedAt, &t.ReasonRequiredFor,
+			&t.Registration.Enabled, &t.Registration.GrantTypes, &t.Registration.Scopes, &t.Registration.AuthMethods, &t.CreatedAt, &t.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan tenant: %w", err)
 		}
 		tenants = append(tenants, &t)
@@ -162,3 +178,19 @@ func (r *TenantRepository) List(ctx context.Context, limit, offset int) ([]*tena
 
 	return tenants, nil
 }
+
+// mfaMode stores the zero mode as optional
+func mfaMode(m tenant.MFAMode) string {
+	if m == "" {
+		return string(tenant.MFAOptional)
+	}
+	return string(m)
+}
+
+// mfaRoles stores a nil role list as an empty array
+func mfaRoles(roles []string) []string {
+	if roles == nil {
+		return []string{}
+	}
+	return roles
+}
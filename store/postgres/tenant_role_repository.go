@@ -58,7 +58,7 @@ func (r *TenantRoleRepository) AssignRole(ctx context.Context, tenantID, userID,
 		grantedByUUID = sql.NullString{String: grantedBy, Valid: true}
 	}
 
-	_, err := r.db.pool.Exec(ctx, `
+	err := r.db.execAttributed(ctx, `
 		INSERT INTO rbac_assignments (id, user_id, role_id, scope, scope_context_id, granted_at, granted_by)
 		VALUES ($1, $2, $3, 'tenant', $4, NOW(), $5)
 		ON CONFLICT (user_id, role_id, scope, scope_context_id) DO NOTHING
@@ -74,7 +74,7 @@ func (r *TenantRoleRepository) AssignRole(ctx context.Context, tenantID, userID,
 // RevokeRole revokes a role from a user in a tenant
 func (r *TenantRoleRepository) RevokeRole(ctx context.Context, tenantID, userID, roleName string) error {
 	roleID := MapTenantRole(roleName)
-	_, err := r.db.pool.Exec(ctx, `
+	err := r.db.execAttributed(ctx, `
 		DELETE FROM rbac_assignments
 		WHERE user_id = $1 AND role_id = $2 AND scope = 'tenant' AND scope_context_id = $3
 	`, userID, roleID, tenantID)
@@ -162,7 +162,7 @@ func (r *TenantRoleRepository) GetTenantUsers(ctx context.Context, tenantID stri
 
 // DeleteByTenantID removes all role assignments for a specific tenant
 func (r *TenantRoleRepository) DeleteByTenantID(ctx context.Context, tenantID string) error {
-	_, err := r.db.pool.Exec(ctx, `
+	err := r.db.execAttributed(ctx, `
 		DELETE FROM rbac_assignments
 		WHERE scope = 'tenant' AND scope_context_id = $1
 	`, tenantID)
@@ -20,7 +20,9 @@ import (
 	"os"
 	"testing"
 
+	"github.com/opentrusty/opentrusty-core/policy"
 	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/seed"
 )
 
 // SetupTestDB creates a connection to the test database and runs migrations.
@@ -78,14 +80,22 @@ func SetupTestDB(t *testing.T) (*DB, func()) {
 		_, _ = db.pool.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE %s CASCADE", table))
 	}
 
-	// Run initial schema
-	if err := db.Migrate(ctx, InitialSchema); err != nil {
+	// Run schema migrations
+	scripts, err := Migrations()
+	if err != nil {
 		db.Close()
-		t.Fatalf("failed to run migrations: %v", err)
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	for _, script := range scripts {
+		if err := db.Migrate(ctx, script); err != nil {
+			db.Close()
+			t.Fatalf("failed to run migrations: %v", err)
+		}
 	}
 
 	// Seed RBAC (Permissions & Roles)
-	if err := seedRBAC(ctx, db); err != nil {
+	seeder := seed.NewService(NewPermissionRepository(db), NewRoleRepository(db), NewClientRepository(db))
+	if _, err := seeder.Sync(ctx, testSeedSpec(), false); err != nil {
 		db.Close()
 		t.Fatalf("failed to seed RBAC: %v", err)
 	}
@@ -113,71 +123,41 @@ func SetupTestDB(t *testing.T) (*DB, func()) {
 	return db, cleanup
 }
 
-func seedRBAC(ctx context.Context, db *DB) error {
-	perms := []struct {
-		ID   string
-		Name string
-	}{
-		{"00000000-0000-0000-0000-000000000001", "platform:manage_tenants"},
-		{"00000000-0000-0000-0000-000000000002", "tenant:manage_users"},
-		{"00000000-0000-0000-0000-000000000003", "user:read_profile"},
-	}
-
-	for _, p := range perms {
-		_, err := db.pool.Exec(ctx, `
-			INSERT INTO rbac_permissions (id, name, created_at)
-			VALUES ($1, $2, NOW())
-			ON CONFLICT (name) DO NOTHING
-		`, p.ID, p.Name)
-		if err != nil {
-			return err
-		}
-	}
-
-	roles := []struct {
-		ID    string
-		Name  string
-		Scope role.Scope
-	}{
-		{role.RoleIDPlatformAdmin, role.RolePlatformAdmin, role.ScopePlatform},
-		{role.RoleIDTenantOwner, role.RoleTenantOwner, role.ScopeTenant},
-		{role.RoleIDTenantAdmin, role.RoleTenantAdmin, role.ScopeTenant},
-		{role.RoleIDMember, role.RoleTenantMember, role.ScopeTenant},
-	}
-
-	for _, r := range roles {
-		_, err := db.pool.Exec(ctx, `
-			INSERT INTO rbac_roles (id, name, scope, created_at, updated_at)
-			VALUES ($1, $2, $3, NOW(), NOW())
-			ON CONFLICT (scope, name) DO NOTHING
-		`, r.ID, r.Name, string(r.Scope))
-		if err != nil {
-			return err
-		}
-	}
-
-	// Link Permissions
-	// 1. Platform Admin -> *
-	// Ensure * permission exists
-	_, err := db.pool.Exec(ctx, `INSERT INTO rbac_permissions (id, name, created_at) VALUES ($1, '*', NOW()) ON CONFLICT (name) DO NOTHING`, "00000000-0000-0000-0000-000000000099")
-	if err != nil {
-		return err
-	}
-	// Retrieve ID of * (in case it existed)
-	var wildcardPermID string
-	err = db.pool.QueryRow(ctx, "SELECT id FROM rbac_permissions WHERE name = '*'").Scan(&wildcardPermID)
-	if err != nil {
-		return err
+// testSeedSpec mirrors the RBAC catalog seeded by the initial migration.
+func testSeedSpec() *seed.Spec {
+	return &seed.Spec{
+		Permissions: []string{
+			policy.PermPlatformManageTenants,
+			policy.PermTenantManageUsers,
+			policy.PermUserReadProfile,
+			policy.PermControlPlaneLogin,
+			policy.PermTenantView,
+			policy.PermTenantViewAudit,
+			policy.PermTenantManageClients,
+		},
+		Roles: []seed.RoleSpec{
+			{
+				ID: role.RoleIDPlatformAdmin, Name: role.RolePlatformAdmin, Scope: role.ScopePlatform,
+				Permissions: []string{seed.WildcardPermission},
+			},
+			{
+				ID: role.RoleIDTenantOwner, Name: role.RoleTenantOwner, Scope: role.ScopeTenant,
+				Permissions: []string{
+					policy.PermControlPlaneLogin, policy.PermTenantManageUsers, policy.PermUserReadProfile,
+					policy.PermTenantView, policy.PermTenantViewAudit, policy.PermTenantManageClients,
+				},
+			},
+			{
+				ID: role.RoleIDTenantAdmin, Name: role.RoleTenantAdmin, Scope: role.ScopeTenant,
+				Permissions: []string{
+					policy.PermControlPlaneLogin, policy.PermTenantManageUsers, policy.PermUserReadProfile,
+					policy.PermTenantView, policy.PermTenantViewAudit, policy.PermTenantManageClients,
+				},
+			},
+			{
+				ID: role.RoleIDMember, Name: role.RoleTenantMember, Scope: role.ScopeTenant,
+				Permissions: []string{policy.PermTenantView, policy.PermControlPlaneLogin, policy.PermUserReadProfile},
+			},
+		},
 	}
-
-	_, err = db.pool.Exec(ctx, `
-		INSERT INTO rbac_role_permissions (role_id, permission_id)
-		VALUES ($1, $2)
-		ON CONFLICT DO NOTHING
-	`, role.RoleIDPlatformAdmin, wildcardPermID)
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
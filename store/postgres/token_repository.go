@@ -18,9 +18,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/metrics"
 )
 
 // AccessTokenRepository implements client.AccessTokenRepository
@@ -45,37 +48,59 @@ func (r *AccessTokenRepository) Create(t *client.AccessToken) error {
 	_, err := r.db.pool.Exec(ctx, `
 		INSERT INTO access_tokens (
 			id, tenant_id, token_hash, client_id, user_id, 
-			scope, token_type, expires_at, revoked_at, is_revoked, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			scope, token_type, expires_at, revoked_at, is_revoked, created_at,
+			cnf_jkt, cnf_x5t_s256, grant_id, audience
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, '')::uuid, NULLIF($15, ''))
 	`,
 		t.ID, t.TenantID, t.TokenHash, t.ClientID, t.UserID,
 		t.Scope, t.TokenType, t.ExpiresAt, revokedAt, t.IsRevoked, t.CreatedAt,
+		t.DPoPJKT, t.CertThumbprint, t.GrantID, t.Audience,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create access token: %w", err)
 	}
+	r.db.metrics.TokenIssued(metrics.TokenAccess)
+	events.Emit(ctx, r.db.events, events.TokenIssued{
+		Meta: events.NewMeta(t.TenantID, t.UserID), TokenID: t.ID, Kind: events.TokenKindAccess, ClientID: t.ClientID, UserID: t.UserID, GrantID: t.GrantID,
+	})
 
 	return nil
 }
 
-// GetByTokenHash retrieves an access token
-func (r *AccessTokenRepository) GetByTokenHash(tokenHash string) (*client.AccessToken, error) {
-	ctx := context.Background()
+const accessTokenColumns = `
+	id, tenant_id, token_hash, client_id, user_id,
+	scope, token_type, expires_at, revoked_at, is_revoked, created_at,
+	COALESCE(cnf_jkt, ''), COALESCE(cnf_x5t_s256, ''), COALESCE(grant_id::text, ''), COALESCE(audience, '')`
 
+func scanAccessToken(row pgx.Row) (*client.AccessToken, error) {
 	var t client.AccessToken
 	var revokedAt sql.NullTime
 
-	err := r.db.pool.QueryRow(ctx, `
-		SELECT 
-			id, tenant_id, token_hash, client_id, user_id, 
-			scope, token_type, expires_at, revoked_at, is_revoked, created_at
-		FROM access_tokens
-		WHERE token_hash = $1
-	`, tokenHash).Scan(
+	if err := row.Scan(
 		&t.ID, &t.TenantID, &t.TokenHash, &t.ClientID, &t.UserID,
 		&t.Scope, &t.TokenType, &t.ExpiresAt, &revokedAt, &t.IsRevoked, &t.CreatedAt,
-	)
+		&t.DPoPJKT, &t.CertThumbprint, &t.GrantID, &t.Audience,
+	); err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+
+	return &t, nil
+}
+
+// GetByTokenHash retrieves an access token
+func (r *AccessTokenRepository) GetByTokenHash(tokenHash string) (*client.AccessToken, error) {
+	ctx := context.Background()
+
+	t, err := scanAccessToken(r.db.pool.QueryRow(ctx, `
+		SELECT `+accessTokenColumns+`
+		FROM access_tokens
+		WHERE token_hash = $1
+	`, tokenHash))
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -84,29 +109,56 @@ func (r *AccessTokenRepository) GetByTokenHash(tokenHash string) (*client.Access
 		return nil, fmt.Errorf("failed to get access token: %w", err)
 	}
 
-	if revokedAt.Valid {
-		t.RevokedAt = &revokedAt.Time
+	return t, nil
+}
+
+// ListByGrant retrieves the access tokens issued under a grant, oldest first
+func (r *AccessTokenRepository) ListByGrant(tenantID, grantID string) ([]*client.AccessToken, error) {
+	ctx := context.Background()
+
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+accessTokenColumns+`
+		FROM access_tokens
+		WHERE tenant_id = $1 AND grant_id = $2
+		ORDER BY created_at
+	`, tenantID, grantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access tokens: %w", err)
 	}
+	defer rows.Close()
 
-	return &t, nil
+	var tokens []*client.AccessToken
+	for rows.Next() {
+		t, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan access token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
 }
 
 // Revoke revokes an access token
 func (r *AccessTokenRepository) Revoke(tokenHash string) error {
 	ctx := context.Background()
 
-	result, err := r.db.pool.Exec(ctx, `
+	var e events.TokenRevoked
+	err := r.db.pool.QueryRow(ctx, `
 		UPDATE access_tokens SET is_revoked = true, revoked_at = NOW()
 		WHERE token_hash = $1
-	`, tokenHash)
+		RETURNING tenant_id, id, client_id, user_id, COALESCE(grant_id::text, '')
+	`, tokenHash).Scan(&e.TenantID, &e.TokenID, &e.ClientID, &e.UserID, &e.GrantID)
 
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return client.ErrTokenNotFound
+		}
 		return fmt.Errorf("failed to revoke access token: %w", err)
 	}
-
-	if result.RowsAffected() == 0 {
-		return client.ErrTokenNotFound
-	}
+	r.db.metrics.TokenRevoked(metrics.TokenAccess)
+	e.OccurredAt = time.Now()
+	events.Emit(ctx, r.db.events, e)
 
 	return nil
 }
@@ -151,38 +203,61 @@ func (r *RefreshTokenRepository) Create(t *client.RefreshToken) error {
 	_, err := r.db.pool.Exec(ctx, `
 		INSERT INTO refresh_tokens (
 			id, tenant_id, token_hash, access_token_id, client_id, user_id, 
-			scope, expires_at, revoked_at, is_revoked, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			scope, family_id, parent_id, expires_at, revoked_at, is_revoked, created_at, grant_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid, NULLIF($9, '')::uuid, $10, $11, $12, $13, NULLIF($14, '')::uuid)
 	`,
 		t.ID, t.TenantID, t.TokenHash, accessTokenID, t.ClientID, t.UserID,
-		t.Scope, t.ExpiresAt, revokedAt, t.IsRevoked, t.CreatedAt,
+		t.Scope, t.FamilyID, t.ParentID, t.ExpiresAt, revokedAt, t.IsRevoked, t.CreatedAt, t.GrantID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create refresh token: %w", err)
 	}
+	r.db.metrics.TokenIssued(metrics.TokenRefresh)
+	events.Emit(ctx, r.db.events, events.TokenIssued{
+		Meta: events.NewMeta(t.TenantID, t.UserID), TokenID: t.ID, Kind: events.TokenKindRefresh, ClientID: t.ClientID, UserID: t.UserID, GrantID: t.GrantID,
+	})
 
 	return nil
 }
 
-// GetByTokenHash retrieves a refresh token
-func (r *RefreshTokenRepository) GetByTokenHash(tokenHash string) (*client.RefreshToken, error) {
-	ctx := context.Background()
+const refreshTokenColumns = `
+	id, tenant_id, token_hash, access_token_id, client_id, user_id,
+	scope, COALESCE(family_id::text, ''), COALESCE(parent_id::text, ''),
+	expires_at, revoked_at, is_revoked, created_at, COALESCE(grant_id::text, '')`
 
+func scanRefreshToken(row pgx.Row) (*client.RefreshToken, error) {
 	var t client.RefreshToken
 	var revokedAt sql.NullTime
 	var accessTokenID sql.NullString
 
-	err := r.db.pool.QueryRow(ctx, `
-		SELECT 
-			id, tenant_id, token_hash, access_token_id, client_id, user_id, 
-			scope, expires_at, revoked_at, is_revoked, created_at
+	if err := row.Scan(
+		&t.ID, &t.TenantID, &t.TokenHash, &accessTokenID, &t.ClientID, &t.UserID,
+		&t.Scope, &t.FamilyID, &t.ParentID,
+		&t.ExpiresAt, &revokedAt, &t.IsRevoked, &t.CreatedAt, &t.GrantID,
+	); err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	if accessTokenID.Valid {
+		t.AccessTokenID = accessTokenID.String
+	}
+
+	return &t, nil
+}
+
+// GetByTokenHash retrieves a refresh token
+func (r *RefreshTokenRepository) GetByTokenHash(tokenHash string) (*client.RefreshToken, error) {
+	ctx := context.Background()
+
+	t, err := scanRefreshToken(r.db.pool.QueryRow(ctx, `
+		SELECT `+refreshTokenColumns+`
 		FROM refresh_tokens
 		WHERE token_hash = $1
-	`, tokenHash).Scan(
-		&t.ID, &t.TenantID, &t.TokenHash, &accessTokenID, &t.ClientID, &t.UserID,
-		&t.Scope, &t.ExpiresAt, &revokedAt, &t.IsRevoked, &t.CreatedAt,
-	)
+	`, tokenHash))
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -191,32 +266,56 @@ func (r *RefreshTokenRepository) GetByTokenHash(tokenHash string) (*client.Refre
 		return nil, fmt.Errorf("failed to get refresh token: %w", err)
 	}
 
-	if revokedAt.Valid {
-		t.RevokedAt = &revokedAt.Time
+	return t, nil
+}
+
+// ListByGrant retrieves the refresh tokens issued under a grant, oldest first
+func (r *RefreshTokenRepository) ListByGrant(tenantID, grantID string) ([]*client.RefreshToken, error) {
+	ctx := context.Background()
+
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+refreshTokenColumns+`
+		FROM refresh_tokens
+		WHERE tenant_id = $1 AND grant_id = $2
+		ORDER BY created_at
+	`, tenantID, grantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refresh tokens: %w", err)
 	}
-	if accessTokenID.Valid {
-		t.AccessTokenID = accessTokenID.String
+	defer rows.Close()
+
+	var tokens []*client.RefreshToken
+	for rows.Next() {
+		t, err := scanRefreshToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan refresh token: %w", err)
+		}
+		tokens = append(tokens, t)
 	}
 
-	return &t, nil
+	return tokens, rows.Err()
 }
 
 // Revoke revokes a refresh token
 func (r *RefreshTokenRepository) Revoke(tokenHash string) error {
 	ctx := context.Background()
 
-	result, err := r.db.pool.Exec(ctx, `
+	var e events.TokenRevoked
+	err := r.db.pool.QueryRow(ctx, `
 		UPDATE refresh_tokens SET is_revoked = true, revoked_at = NOW()
 		WHERE token_hash = $1
-	`, tokenHash)
+		RETURNING tenant_id, id, client_id, user_id, COALESCE(grant_id::text, '')
+	`, tokenHash).Scan(&e.TenantID, &e.TokenID, &e.ClientID, &e.UserID, &e.GrantID)
 
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return client.ErrTokenNotFound
+		}
 		return fmt.Errorf("failed to revoke refresh token: %w", err)
 	}
-
-	if result.RowsAffected() == 0 {
-		return client.ErrTokenNotFound
-	}
+	r.db.metrics.TokenRevoked(metrics.TokenRefresh)
+	e.OccurredAt = time.Now()
+	events.Emit(ctx, r.db.events, e)
 
 	return nil
 }
@@ -231,5 +330,197 @@ func (r *RefreshTokenRepository) DeleteExpired() error {
 		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
 	}
 
+	// Families are created just before their first token; the grace period keeps
+	// cleanup from racing an issuance in progress.
+	_, err = r.db.pool.Exec(ctx, `
+		DELETE FROM refresh_token_families f
+		WHERE f.created_at < NOW() - INTERVAL '1 hour'
+		AND NOT EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.family_id = f.id)
+	`)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete empty refresh token families: %w", err)
+	}
+
+	return nil
+}
+
+// CreateFamily creates a new refresh token family
+func (r *RefreshTokenRepository) CreateFamily(f *client.RefreshTokenFamily) error {
+	ctx := context.Background()
+
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO refresh_token_families (
+			id, tenant_id, client_id, user_id, device_id, device_name, created_at, last_used_at
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $7)
+	`, f.ID, f.TenantID, f.ClientID, f.UserID, f.DeviceID, f.DeviceName, f.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token family: %w", err)
+	}
+
 	return nil
 }
+
+const familyColumns = `
+	id, tenant_id, client_id, user_id, COALESCE(device_id, ''), COALESCE(device_name, ''),
+	created_at, last_used_at, revoked_at, COALESCE(revoke_reason, '')
+`
+
+func scanFamily(row pgx.Row) (*client.RefreshTokenFamily, error) {
+	var f client.RefreshTokenFamily
+	var revokedAt sql.NullTime
+
+	if err := row.Scan(
+		&f.ID, &f.TenantID, &f.ClientID, &f.UserID, &f.DeviceID, &f.DeviceName,
+		&f.CreatedAt, &f.LastUsedAt, &revokedAt, &f.RevokeReason,
+	); err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		f.RevokedAt = &revokedAt.Time
+	}
+
+	return &f, nil
+}
+
+// GetFamily retrieves a family by ID
+func (r *RefreshTokenRepository) GetFamily(tenantID, familyID string) (*client.RefreshTokenFamily, error) {
+	ctx := context.Background()
+
+	f, err := scanFamily(r.db.pool.QueryRow(ctx, `
+		SELECT `+familyColumns+`
+		FROM refresh_token_families
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, familyID))
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, client.ErrFamilyNotFound
+		}
+		return nil, fmt.Errorf("failed to get refresh token family: %w", err)
+	}
+
+	return f, nil
+}
+
+// TouchFamily records that a token of the family was redeemed
+func (r *RefreshTokenRepository) TouchFamily(familyID string) error {
+	ctx := context.Background()
+
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE refresh_token_families SET last_used_at = NOW() WHERE id = $1
+	`, familyID)
+
+	if err != nil {
+		return fmt.Errorf("failed to touch refresh token family: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return client.ErrFamilyNotFound
+	}
+
+	return nil
+}
+
+// ListFamiliesByUser retrieves the active families of a user, most recently used first
+func (r *RefreshTokenRepository) ListFamiliesByUser(tenantID, userID string) ([]*client.RefreshTokenFamily, error) {
+	ctx := context.Background()
+
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT `+familyColumns+`
+		FROM refresh_token_families
+		WHERE tenant_id = $1 AND user_id = $2 AND revoked_at IS NULL
+		ORDER BY last_used_at DESC
+	`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refresh token families: %w", err)
+	}
+	defer rows.Close()
+
+	var families []*client.RefreshTokenFamily
+	for rows.Next() {
+		f, err := scanFamily(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan refresh token family: %w", err)
+		}
+		families = append(families, f)
+	}
+
+	return families, rows.Err()
+}
+
+// RevokeFamily revokes a family and every token in it
+func (r *RefreshTokenRepository) RevokeFamily(tenantID, familyID, reason string) error {
+	n, err := r.revokeFamilies(`tenant_id = $1 AND id = $2`, tenantID, familyID, reason)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		if _, err := r.GetFamily(tenantID, familyID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokeFamiliesByUser revokes every active family of a user and their tokens
+func (r *RefreshTokenRepository) RevokeFamiliesByUser(tenantID, userID, reason string) (int, error) {
+	return r.revokeFamilies(`tenant_id = $1 AND user_id = $2`, tenantID, userID, reason)
+}
+
+// revokeFamilies revokes the active families matching where (with $1, $2 bound
+// to a and b, and $3 to reason) and their unrevoked tokens in one transaction.
+func (r *RefreshTokenRepository) revokeFamilies(where, a, b, reason string) (int, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE refresh_token_families SET revoked_at = NOW(), revoke_reason = $3
+		WHERE `+where+` AND revoked_at IS NULL
+		RETURNING id
+	`, a, b, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh token families: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan refresh token family: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh token families: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE refresh_tokens SET is_revoked = true, revoked_at = NOW()
+		WHERE family_id = ANY($1::uuid[]) AND is_revoked = false
+	`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for range result.RowsAffected() {
+		r.db.metrics.TokenRevoked(metrics.TokenRefresh)
+	}
+
+	return len(ids), nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// queryTracer adapts tracing.Tracer to pgx.QueryTracer.
+// Spans carry only the SQL verb; statements and arguments are never recorded
+// because they may contain PII or secrets.
+type queryTracer struct {
+	tracer tracing.Tracer
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op := sqlOperation(data.SQL)
+	ctx, _ = tracing.Start(ctx, t.tracer, "postgres."+op,
+		tracing.String(tracing.AttrDBSystem, "postgresql"),
+		tracing.String(tracing.AttrDBOperation, op),
+	)
+	return ctx
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := tracing.SpanFromContext(ctx)
+	if data.Err != nil && data.Err != pgx.ErrNoRows {
+		span.RecordError(data.Err)
+	}
+	span.End()
+}
+
+// sqlOperation returns the upper-cased leading keyword of a statement.
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/client"
+)
+
+// UsageRepository implements client.UsageRepository
+type UsageRepository struct {
+	db *DB
+}
+
+// NewUsageRepository creates a new client usage repository
+func NewUsageRepository(db *DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// AddUsage increments a client's counter and advances its last-used time
+func (r *UsageRepository) AddUsage(ctx context.Context, tenantID, clientID string, issued int64, at time.Time) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO oauth2_client_usage (tenant_id, client_id, tokens_issued, last_used_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (client_id) DO UPDATE
+		SET tokens_issued = oauth2_client_usage.tokens_issued + EXCLUDED.tokens_issued,
+		    last_used_at = GREATEST(oauth2_client_usage.last_used_at, EXCLUDED.last_used_at)
+	`, tenantID, clientID, issued, at)
+
+	if err != nil {
+		return fmt.Errorf("failed to add client usage: %w", err)
+	}
+
+	return nil
+}
+
+// ListUsage retrieves the usage of every used client in a tenant
+func (r *UsageRepository) ListUsage(ctx context.Context, tenantID string) ([]*client.Usage, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT tenant_id, client_id, tokens_issued, last_used_at
+		FROM oauth2_client_usage
+		WHERE tenant_id = $1
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list client usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*client.Usage
+	for rows.Next() {
+		var u client.Usage
+		if err := rows.Scan(&u.TenantID, &u.ClientID, &u.TokensIssued, &u.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan client usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+
+	return usage, rows.Err()
+}
@@ -21,6 +21,8 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/id"
 	"github.com/opentrusty/opentrusty-core/user"
 )
 
@@ -49,17 +51,24 @@ func NewUserRepository(db *DB) *UserRepository {
 // Audited: No
 // Errors: System errors
 func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
+	return insertUser(ctx, r.db.pool, u)
+}
+
+// insertUser writes a new user row through q.
+func insertUser(ctx context.Context, q execer, u *user.User) error {
 	now := time.Now()
-	_, err := r.db.pool.Exec(ctx, `
+	_, err := q.Exec(ctx, `
 		INSERT INTO users (
-			id, email_hash, email_plain, email_verified,
+			id, email_hash, email_hash_key_id, email_plain, email_verified,
 			given_name, family_name, full_name, nickname, picture, locale, timezone,
+			phone_number, phone_number_verified, address,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`,
-		u.ID, u.EmailHash, u.EmailPlain, u.EmailVerified,
+		u.ID, u.EmailHash, emailHashKeyID(u), u.EmailPlain, u.EmailVerified,
 		u.Profile.GivenName, u.Profile.FamilyName, u.Profile.FullName,
 		u.Profile.Nickname, u.Profile.Picture, u.Profile.Locale, u.Profile.Timezone,
+		u.Profile.PhoneNumber, u.Profile.PhoneNumberVerified, u.Profile.Address,
 		now, now,
 	)
 	if err != nil {
@@ -72,17 +81,40 @@ func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
 	return nil
 }
 
+// emailHashKeyID returns the key ID to persist, defaulting to the legacy key.
+func emailHashKeyID(u *user.User) string {
+	if u.EmailHashKeyID == "" {
+		return crypto.LegacyKeyID
+	}
+	return u.EmailHashKeyID
+}
+
 // AddCredentials adds credentials for a user
 func (r *UserRepository) AddCredentials(ctx context.Context, c *user.Credentials) error {
+	return insertCredentials(ctx, r.db.pool, c)
+}
+
+// insertCredentials writes a new credentials row, and its password identity, through q.
+func insertCredentials(ctx context.Context, q execer, c *user.Credentials) error {
 	now := time.Now()
-	_, err := r.db.pool.Exec(ctx, `
-		INSERT INTO credentials (user_id, password_hash, updated_at)
-		VALUES ($1, $2, $3)
+	_, err := q.Exec(ctx, `
+		INSERT INTO credentials (user_id, password_hash, password_changed_at, updated_at)
+		VALUES ($1, $2, $3, $3)
 	`, c.UserID, c.PasswordHash, now)
 	if err != nil {
 		return fmt.Errorf("failed to insert credentials: %w", err)
 	}
 
+	_, err = q.Exec(ctx, `
+		INSERT INTO identities (id, user_id, kind, issuer, subject, created_at)
+		VALUES ($1, $2, $3, '', $2, $4)
+		ON CONFLICT (kind, issuer, subject) DO NOTHING
+	`, id.NewUUIDv7(), c.UserID, string(user.IdentityPassword), now)
+	if err != nil {
+		return fmt.Errorf("failed to insert password identity: %w", err)
+	}
+
+	c.PasswordChangedAt = now
 	c.UpdatedAt = now
 
 	return nil
@@ -94,16 +126,18 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*user.User, er
 	var deletedAt sql.NullTime
 
 	err := r.db.pool.QueryRow(ctx, `
-		SELECT id, email_hash, email_plain, email_verified,
+		SELECT id, email_hash, email_hash_key_id, email_plain, email_verified,
 			given_name, family_name, full_name, nickname, picture, locale, timezone,
-			created_at, updated_at, deleted_at
+			phone_number, phone_number_verified, address,
+			failed_login_attempts, locked_until, created_at, updated_at, deleted_at
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(
-		&u.ID, &u.EmailHash, &u.EmailPlain, &u.EmailVerified,
+		&u.ID, &u.EmailHash, &u.EmailHashKeyID, &u.EmailPlain, &u.EmailVerified,
 		&u.Profile.GivenName, &u.Profile.FamilyName, &u.Profile.FullName,
 		&u.Profile.Nickname, &u.Profile.Picture, &u.Profile.Locale, &u.Profile.Timezone,
-		&u.CreatedAt, &u.UpdatedAt, &deletedAt,
+		&u.Profile.PhoneNumber, &u.Profile.PhoneNumberVerified, &u.Profile.Address,
+		&u.FailedLoginAttempts, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt, &deletedAt,
 	)
 
 	if err != nil {
@@ -126,16 +160,18 @@ func (r *UserRepository) GetByHash(ctx context.Context, hash string) (*user.User
 	var deletedAt sql.NullTime
 
 	err := r.db.pool.QueryRow(ctx, `
-		SELECT id, email_hash, email_plain, email_verified,
+		SELECT id, email_hash, email_hash_key_id, email_plain, email_verified,
 			given_name, family_name, full_name, nickname, picture, locale, timezone,
-			created_at, updated_at, deleted_at
+			phone_number, phone_number_verified, address,
+			failed_login_attempts, locked_until, created_at, updated_at, deleted_at
 		FROM users
 		WHERE email_hash = $1 AND deleted_at IS NULL
 	`, hash).Scan(
-		&u.ID, &u.EmailHash, &u.EmailPlain, &u.EmailVerified,
+		&u.ID, &u.EmailHash, &u.EmailHashKeyID, &u.EmailPlain, &u.EmailVerified,
 		&u.Profile.GivenName, &u.Profile.FamilyName, &u.Profile.FullName,
 		&u.Profile.Nickname, &u.Profile.Picture, &u.Profile.Locale, &u.Profile.Timezone,
-		&u.CreatedAt, &u.UpdatedAt, &deletedAt,
+		&u.Profile.PhoneNumber, &u.Profile.PhoneNumberVerified, &u.Profile.Address,
+		&u.FailedLoginAttempts, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt, &deletedAt,
 	)
 
 	if err != nil {
@@ -165,12 +201,16 @@ func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
 			picture = $8,
 			locale = $9,
 			timezone = $10,
+			phone_number = $11,
+			phone_number_verified = $12,
+			address = $13,
 			updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL
 	`,
 		u.ID, u.EmailPlain, u.EmailVerified,
 		u.Profile.GivenName, u.Profile.FamilyName, u.Profile.FullName,
 		u.Profile.Nickname, u.Profile.Picture, u.Profile.Locale, u.Profile.Timezone,
+		u.Profile.PhoneNumber, u.Profile.PhoneNumberVerified, u.Profile.Address,
 	)
 
 	if err != nil {
@@ -197,6 +237,41 @@ func (r *UserRepository) UpdateLockout(ctx context.Context, userID string, faile
 	return nil
 }
 
+// ListLocked returns members of tenantID whose lockout has not expired at now
+func (r *UserRepository) ListLocked(ctx context.Context, tenantID string, now time.Time) ([]*user.User, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT u.id, u.email_hash, u.email_hash_key_id, u.email_plain, u.email_verified,
+			u.given_name, u.family_name, u.full_name, u.nickname, u.picture, u.locale, u.timezone,
+			u.phone_number, u.phone_number_verified, u.address,
+			u.failed_login_attempts, u.locked_until, u.created_at, u.updated_at
+		FROM users u
+		JOIN tenant_members m ON m.user_id = u.id
+		WHERE m.tenant_id = $1 AND u.locked_until > $2 AND u.deleted_at IS NULL
+		ORDER BY u.locked_until
+	`, tenantID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locked users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*user.User
+	for rows.Next() {
+		var u user.User
+		if err := rows.Scan(
+			&u.ID, &u.EmailHash, &u.EmailHashKeyID, &u.EmailPlain, &u.EmailVerified,
+			&u.Profile.GivenName, &u.Profile.FamilyName, &u.Profile.FullName,
+			&u.Profile.Nickname, &u.Profile.Picture, &u.Profile.Locale, &u.Profile.Timezone,
+			&u.Profile.PhoneNumber, &u.Profile.PhoneNumberVerified, &u.Profile.Address,
+			&u.FailedLoginAttempts, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan locked user: %w", err)
+		}
+		users = append(users, &u)
+	}
+
+	return users, rows.Err()
+}
+
 // Delete soft-deletes a user
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	result, err := r.db.pool.Exec(ctx, `
@@ -219,10 +294,10 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 func (r *UserRepository) GetCredentials(ctx context.Context, userID string) (*user.Credentials, error) {
 	var c user.Credentials
 	err := r.db.pool.QueryRow(ctx, `
-		SELECT user_id, password_hash, updated_at
+		SELECT user_id, password_hash, password_changed_at, password_reset_required, updated_at
 		FROM credentials
 		WHERE user_id = $1
-	`, userID).Scan(&c.UserID, &c.PasswordHash, &c.UpdatedAt)
+	`, userID).Scan(&c.UserID, &c.PasswordHash, &c.PasswordChangedAt, &c.ResetRequired, &c.UpdatedAt)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -234,10 +309,10 @@ func (r *UserRepository) GetCredentials(ctx context.Context, userID string) (*us
 	return &c, nil
 }
 
-// UpdatePassword updates user password
+// UpdatePassword updates user password and clears a pending reset
 func (r *UserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
 	result, err := r.db.pool.Exec(ctx, `
-		UPDATE credentials SET password_hash = $2, updated_at = NOW()
+		UPDATE credentials SET password_hash = $2, password_changed_at = NOW(), password_reset_required = false, updated_at = NOW()
 		WHERE user_id = $1
 	`, userID, passwordHash)
 
@@ -251,3 +326,39 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, userID string, pass
 
 	return nil
 }
+
+// InvalidatePassword replaces the hash with an unusable one and requires a reset
+func (r *UserRepository) InvalidatePassword(ctx context.Context, userID string, unusableHash string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE credentials SET password_hash = $2, password_reset_required = true, updated_at = NOW()
+		WHERE user_id = $1
+	`, userID, unusableHash)
+
+	if err != nil {
+		return fmt.Errorf("failed to invalidate password: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// RehashPassword replaces the hash of an unchanged password, keeping password_changed_at
+func (r *UserRepository) RehashPassword(ctx context.Context, userID string, passwordHash string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE credentials SET password_hash = $2, updated_at = NOW()
+		WHERE user_id = $1
+	`, userID, passwordHash)
+
+	if err != nil {
+		return fmt.Errorf("failed to rehash password: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/opentrusty/opentrusty-core/webhook"
+)
+
+// WebhookRepository implements webhook.Repository
+type WebhookRepository struct {
+	db *DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateEndpoint persists a new endpoint
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, e *webhook.Endpoint) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO webhook_endpoints (id, tenant_id, url, secret, events, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, e.ID, e.TenantID, e.URL, e.Secret, e.Events, e.Enabled, e.CreatedAt, e.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// GetEndpoint retrieves an endpoint within a tenant
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, tenantID, id string) (*webhook.Endpoint, error) {
+	var e webhook.Endpoint
+
+	err := r.db.pool.QueryRow(ctx, `
+		SELECT id, tenant_id, url, secret, events, enabled, created_at, updated_at
+		FROM webhook_endpoints
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id).Scan(
+		&e.ID, &e.TenantID, &e.URL, &e.Secret, &e.Events, &e.Enabled, &e.CreatedAt, &e.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, webhook.ErrEndpointNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	return &e, nil
+}
+
+// ListEndpoints returns all endpoints of a tenant
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, tenantID string) ([]*webhook.Endpoint, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, tenant_id, url, secret, events, enabled, created_at, updated_at
+		FROM webhook_endpoints
+		WHERE tenant_id = $1
+		ORDER BY created_at
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*webhook.Endpoint
+	for rows.Next() {
+		var e webhook.Endpoint
+		if err := rows.Scan(&e.ID, &e.TenantID, &e.URL, &e.Secret, &e.Events, &e.Enabled, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, &e)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// UpdateEndpoint updates URL, events, and enabled state
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, e *webhook.Endpoint) error {
+	result, err := r.db.pool.Exec(ctx, `
+		UPDATE webhook_endpoints SET url = $3, events = $4, enabled = $5, updated_at = $6
+		WHERE tenant_id = $1 AND id = $2
+	`, e.TenantID, e.ID, e.URL, e.Events, e.Enabled, e.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return webhook.ErrEndpointNotFound
+	}
+
+	return nil
+}
+
+// DeleteEndpoint removes an endpoint and its delivery history
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, tenantID, id string) error {
+	result, err := r.db.pool.Exec(ctx, `
+		DELETE FROM webhook_endpoints WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return webhook.ErrEndpointNotFound
+	}
+
+	return nil
+}
+
+// CreateDelivery persists a pending delivery
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	_, err := r.db.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (id, endpoint_id, tenant_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, d.ID, d.EndpointID, d.TenantID, d.EventType, d.Payload, d.Status, d.Attempts, d.NextAttemptAt, d.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDelivery records the outcome of an attempt
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	_, err := r.db.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, last_status_code = $4, last_error = NULLIF($5, ''),
+		    next_attempt_at = $6, delivered_at = $7
+		WHERE id = $1
+	`, d.ID, d.Status, d.Attempts, d.LastStatusCode, d.LastError, d.NextAttemptAt, d.DeliveredAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListDueDeliveries returns pending deliveries whose next attempt is at or before now
+func (r *WebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+	return r.listDeliveries(ctx, `
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $2
+	`, now, limit)
+}
+
+// ListDeliveries returns the most recent deliveries for an endpoint
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, tenantID, endpointID string, limit int) ([]*webhook.Delivery, error) {
+	return r.listDeliveries(ctx, `
+		WHERE tenant_id = $1 AND endpoint_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, tenantID, endpointID, limit)
+}
+
+func (r *WebhookRepository) listDeliveries(ctx context.Context, where string, args ...any) ([]*webhook.Delivery, error) {
+	rows, err := r.db.pool.Query(ctx, `
+		SELECT id, endpoint_id, tenant_id, event_type, payload, status, attempts,
+		       last_status_code, COALESCE(last_error, ''), next_attempt_at, created_at, delivered_at
+		FROM webhook_deliveries
+	`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*webhook.Delivery
+	for rows.Next() {
+		var d webhook.Delivery
+		if err := rows.Scan(
+			&d.ID, &d.EndpointID, &d.TenantID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.LastStatusCode, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
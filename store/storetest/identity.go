@@ -0,0 +1,207 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// newUser returns an unsaved user with a unique email hash.
+func newUser() *user.User {
+	email := id.NewUUIDv7() + "@example.com"
+	return &user.User{
+		ID:         id.NewUUIDv7(),
+		EmailHash:  id.NewUUIDv7(),
+		EmailPlain: &email,
+		Profile:    user.Profile{GivenName: "Ada", FamilyName: "Lovelace"},
+	}
+}
+
+// newTenant returns an unsaved active tenant with a unique name.
+func newTenant() *tenant.Tenant {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return &tenant.Tenant{
+		ID:        id.NewUUIDv7(),
+		Name:      "tenant-" + id.NewUUIDv7(),
+		Status:    tenant.StatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func testUsers(t *testing.T, s *Stores) {
+	require(t, map[string]bool{"Users": s.Users != nil})
+	ctx := context.Background()
+	repo := s.Users
+
+	u := newUser()
+	if err := repo.Create(ctx, u); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	t.Run("GetByID and GetByHash", func(t *testing.T) {
+		got, err := repo.GetByID(ctx, u.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got.EmailHash != u.EmailHash || got.Profile.GivenName != u.Profile.GivenName {
+			t.Errorf("GetByID() = %+v, want %+v", got, u)
+		}
+		got, err = repo.GetByHash(ctx, u.EmailHash)
+		if err != nil || got.ID != u.ID {
+			t.Errorf("GetByHash() = %v, %v, want user %s", got, err, u.ID)
+		}
+	})
+
+	t.Run("missing user", func(t *testing.T) {
+		if _, err := repo.GetByID(ctx, id.NewUUIDv7()); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("GetByID() error = %v, want ErrUserNotFound", err)
+		}
+		if _, err := repo.GetByHash(ctx, id.NewUUIDv7()); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("GetByHash() error = %v, want ErrUserNotFound", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		u.Profile.FullName = "Ada King"
+		if err := repo.Update(ctx, u); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		got, err := repo.GetByID(ctx, u.ID)
+		if err != nil || got.Profile.FullName != "Ada King" {
+			t.Errorf("GetByID() after Update = %v, %v", got, err)
+		}
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		if _, err := repo.GetCredentials(ctx, u.ID); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("GetCredentials() before AddCredentials error = %v, want ErrUserNotFound", err)
+		}
+		if err := repo.AddCredentials(ctx, &user.Credentials{UserID: u.ID, PasswordHash: "hash-1"}); err != nil {
+			t.Fatalf("AddCredentials() error = %v", err)
+		}
+		if err := repo.UpdatePassword(ctx, u.ID, "hash-2"); err != nil {
+			t.Fatalf("UpdatePassword() error = %v", err)
+		}
+		c, err := repo.GetCredentials(ctx, u.ID)
+		if err != nil || c.PasswordHash != "hash-2" || c.ResetRequired {
+			t.Errorf("GetCredentials() = %+v, %v, want hash-2 without reset", c, err)
+		}
+		if err := repo.UpdatePassword(ctx, id.NewUUIDv7(), "hash"); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("UpdatePassword() of missing user error = %v, want ErrUserNotFound", err)
+		}
+	})
+
+	t.Run("UpdateLockout", func(t *testing.T) {
+		until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		if err := repo.UpdateLockout(ctx, u.ID, 5, &until); err != nil {
+			t.Fatalf("UpdateLockout() error = %v", err)
+		}
+		got, err := repo.GetByID(ctx, u.ID)
+		if err != nil || got.FailedLoginAttempts != 5 || got.LockedUntil == nil || !got.LockedUntil.Equal(until) {
+			t.Errorf("GetByID() after UpdateLockout = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("Delete hides the user", func(t *testing.T) {
+		if err := repo.Delete(ctx, u.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.GetByID(ctx, u.ID); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("GetByID() after Delete error = %v, want ErrUserNotFound", err)
+		}
+		if _, err := repo.GetByHash(ctx, u.EmailHash); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("GetByHash() after Delete error = %v, want ErrUserNotFound", err)
+		}
+		if err := repo.Delete(ctx, u.ID); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("second Delete() error = %v, want ErrUserNotFound", err)
+		}
+	})
+}
+
+func testTenants(t *testing.T, s *Stores) {
+	require(t, map[string]bool{"Tenants": s.Tenants != nil})
+	ctx := context.Background()
+	repo := s.Tenants
+
+	tn := newTenant()
+	if err := repo.Create(ctx, tn); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	t.Run("GetByID and GetByName", func(t *testing.T) {
+		got, err := repo.GetByID(ctx, tn.ID)
+		if err != nil || got.Name != tn.Name || got.Status != tenant.StatusActive {
+			t.Errorf("GetByID() = %+v, %v", got, err)
+		}
+		got, err = repo.GetByName(ctx, tn.Name)
+		if err != nil || got.ID != tn.ID {
+			t.Errorf("GetByName() = %+v, %v", got, err)
+		}
+		if _, err := repo.GetByID(ctx, id.NewUUIDv7()); !errors.Is(err, tenant.ErrTenantNotFound) {
+			t.Errorf("GetByID() of missing tenant error = %v, want ErrTenantNotFound", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		tn.Name = tn.Name + "-renamed"
+		tn.ReasonRequiredFor = []string{"role_grant"}
+		tn.Registration = client.RegistrationPolicy{Enabled: true, GrantTypes: []string{client.GrantTypeAuthorizationCode}}
+		if err := repo.Update(ctx, tn); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		got, err := repo.GetByID(ctx, tn.ID)
+		if err != nil || got.Name != tn.Name || len(got.ReasonRequiredFor) != 1 || !got.Registration.Enabled || len(got.Registration.GrantTypes) != 1 {
+			t.Errorf("GetByID() after Update = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		list, err := repo.List(ctx, 1000, 0)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		found := false
+		for _, l := range list {
+			found = found || l.ID == tn.ID
+		}
+		if !found {
+			t.Errorf("List() does not include tenant %s", tn.ID)
+		}
+	})
+
+	t.Run("Delete hides the tenant", func(t *testing.T) {
+		if err := repo.Delete(ctx, tn.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.GetByName(ctx, tn.Name); !errors.Is(err, tenant.ErrTenantNotFound) {
+			t.Errorf("GetByName() after Delete error = %v, want ErrTenantNotFound", err)
+		}
+		if err := repo.Update(ctx, tn); !errors.Is(err, tenant.ErrTenantNotFound) {
+			t.Errorf("Update() after Delete error = %v, want ErrTenantNotFound", err)
+		}
+		if err := repo.Delete(ctx, tn.ID); !errors.Is(err, tenant.ErrTenantNotFound) {
+			t.Errorf("second Delete() error = %v, want ErrTenantNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,337 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/id"
+)
+
+// owner is a saved tenant, user, and client that OAuth2 rows can reference.
+type owner struct {
+	tenantID string
+	userID   string
+	client   *client.Client
+}
+
+// newOwner saves a tenant, a user, and a confidential client of that tenant.
+func newOwner(t *testing.T, s *Stores) *owner {
+	t.Helper()
+	require(t, map[string]bool{"Tenants": s.Tenants != nil, "Users": s.Users != nil, "Clients": s.Clients != nil})
+	ctx := context.Background()
+
+	tn := newTenant()
+	if err := s.Tenants.Create(ctx, tn); err != nil {
+		t.Fatalf("Tenants.Create() error = %v", err)
+	}
+	u := newUser()
+	if err := s.Users.Create(ctx, u); err != nil {
+		t.Fatalf("Users.Create() error = %v", err)
+	}
+	c := newClient(tn.ID, u.ID)
+	if err := s.Clients.Create(ctx, c); err != nil {
+		t.Fatalf("Clients.Create() error = %v", err)
+	}
+	return &owner{tenantID: tn.ID, userID: u.ID, client: c}
+}
+
+// newClient returns an unsaved confidential web client.
+func newClient(tenantID, ownerID string) *client.Client {
+	return &client.Client{
+		ID:                      id.NewUUIDv7(),
+		ClientID:                id.NewUUIDv7(),
+		TenantID:                tenantID,
+		ClientSecretHash:        client.HashClientSecret("secret"),
+		ClientName:              "Contract Client",
+		RedirectURIs:            []string{"https://app.example.com/cb"},
+		AllowedScopes:           []string{client.ScopeOpenID, client.ScopeOfflineAccess},
+		GrantTypes:              []string{"authorization_code", "refresh_token"},
+		ResponseTypes:           []string{"code"},
+		ApplicationType:         client.ApplicationTypeWeb,
+		TokenEndpointAuthMethod: client.AuthMethodClientSecretBasic,
+		OwnerID:                 ownerID,
+		IsActive:                true,
+	}
+}
+
+func testClients(t *testing.T, s *Stores) {
+	o := newOwner(t, s)
+	ctx := context.Background()
+	repo := s.Clients
+	c := o.client
+
+	t.Run("GetByClientID and GetByID", func(t *testing.T) {
+		got, err := repo.GetByClientID(ctx, o.tenantID, c.ClientID)
+		if err != nil || got.ID != c.ID || got.ClientName != c.ClientName || len(got.RedirectURIs) != 1 || !got.VerifySecret("secret") {
+			t.Errorf("GetByClientID() = %+v, %v", got, err)
+		}
+		got, err = repo.GetByID(ctx, o.tenantID, c.ID)
+		if err != nil || got.ClientID != c.ClientID || got.OwnerID != o.userID {
+			t.Errorf("GetByID() = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("tenant isolation", func(t *testing.T) {
+		other := newTenant()
+		if err := s.Tenants.Create(ctx, other); err != nil {
+			t.Fatalf("Tenants.Create() error = %v", err)
+		}
+		if _, err := repo.GetByClientID(ctx, other.ID, c.ClientID); !errors.Is(err, client.ErrClientNotFound) {
+			t.Errorf("GetByClientID() from another tenant error = %v, want ErrClientNotFound", err)
+		}
+		if _, err := repo.GetByID(ctx, other.ID, c.ID); !errors.Is(err, client.ErrClientNotFound) {
+			t.Errorf("GetByID() from another tenant error = %v, want ErrClientNotFound", err)
+		}
+		list, err := repo.ListByTenant(ctx, other.ID)
+		if err != nil || len(list) != 0 {
+			t.Errorf("ListByTenant() of another tenant = %v, %v, want empty", list, err)
+		}
+		if _, err := repo.GetByClientID(ctx, "", c.ClientID); !errors.Is(err, client.ErrClientNotFound) {
+			t.Errorf("GetByClientID() without a tenant error = %v, want ErrClientNotFound", err)
+		}
+	})
+
+	t.Run("duplicate client_id", func(t *testing.T) {
+		dup := newClient(o.tenantID, o.userID)
+		dup.ClientID = c.ClientID
+		var dupErr *client.DuplicateClientError
+		if err := repo.Create(ctx, dup); !errors.As(err, &dupErr) || dupErr.ClientID != c.ClientID {
+			t.Errorf("Create() with a taken client_id error = %v, want *DuplicateClientError", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		c.ClientName = "Renamed"
+		c.AllowedCIDRs = []string{"10.0.0.0/8"}
+		c.RegistrationTokenHash = client.HashClientSecret("registration")
+		c.PasswordGrantEnabled = true
+		c.JWTAccessTokens = true
+		c.SubjectType, c.SectorIdentifier = client.SubjectTypePairwise, "example.com"
+		if err := repo.Update(ctx, c); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		got, err := repo.GetByID(ctx, o.tenantID, c.ID)
+		if err != nil || got.ClientName != "Renamed" || len(got.AllowedCIDRs) != 1 || !client.VerifyClientSecret("registration", got.RegistrationTokenHash) || !got.PasswordGrantEnabled || !got.JWTAccessTokens || got.SubjectType != client.SubjectTypePairwise || got.SectorIdentifier != "example.com" {
+			t.Errorf("GetByID() after Update = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("ListByTenant and ListByOwner", func(t *testing.T) {
+		list, err := repo.ListByTenant(ctx, o.tenantID)
+		if err != nil || len(list) != 1 || list[0].ID != c.ID {
+			t.Errorf("ListByTenant() = %v, %v", list, err)
+		}
+		list, err = repo.ListByOwner(ctx, o.userID)
+		if err != nil || len(list) != 1 || list[0].ID != c.ID {
+			t.Errorf("ListByOwner() = %v, %v", list, err)
+		}
+	})
+
+	t.Run("Delete hides the client", func(t *testing.T) {
+		if err := repo.Delete(ctx, o.tenantID, c.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.GetByClientID(ctx, o.tenantID, c.ClientID); !errors.Is(err, client.ErrClientNotFound) {
+			t.Errorf("GetByClientID() after Delete error = %v, want ErrClientNotFound", err)
+		}
+		if err := repo.Update(ctx, c); !errors.Is(err, client.ErrClientNotFound) {
+			t.Errorf("Update() after Delete error = %v, want ErrClientNotFound", err)
+		}
+		if err := repo.Delete(ctx, o.tenantID, c.ID); !errors.Is(err, client.ErrClientNotFound) {
+			t.Errorf("second Delete() error = %v, want ErrClientNotFound", err)
+		}
+	})
+}
+
+func testCodes(t *testing.T, s *Stores) {
+	require(t, map[string]bool{"Codes": s.Codes != nil})
+	o := newOwner(t, s)
+	repo := s.Codes
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	code := &client.AuthorizationCode{
+		ID:                  id.NewUUIDv7(),
+		Code:                id.NewUUIDv7(),
+		TenantID:            o.tenantID,
+		ClientID:            o.client.ClientID,
+		UserID:              o.userID,
+		RedirectURI:         "https://app.example.com/cb",
+		Scope:               "openid",
+		Nonce:               "n-0S6_WzA2Mj",
+		CodeChallenge:       "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		CodeChallengeMethod: client.CodeChallengeMethodS256,
+		GrantID:             client.NewGrantID(),
+		ExpiresAt:           now.Add(time.Minute),
+		CreatedAt:           now,
+	}
+	if err := repo.Create(code); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := repo.GetByCode(o.tenantID, code.Code)
+	if err != nil {
+		t.Fatalf("GetByCode() error = %v", err)
+	}
+	if got.ClientID != code.ClientID || got.GrantID != code.GrantID || got.CodeChallenge != code.CodeChallenge || got.IsUsed {
+		t.Errorf("GetByCode() = %+v, want %+v", got, code)
+	}
+	if _, err := repo.GetByCode(id.NewUUIDv7(), code.Code); !errors.Is(err, client.ErrCodeNotFound) {
+		t.Errorf("GetByCode() from another tenant error = %v, want ErrCodeNotFound", err)
+	}
+
+	if err := repo.MarkAsUsed(code.Code); err != nil {
+		t.Fatalf("MarkAsUsed() error = %v", err)
+	}
+	if err := repo.MarkAsUsed(code.Code); !errors.Is(err, client.ErrCodeAlreadyUsed) {
+		t.Errorf("second MarkAsUsed() error = %v, want ErrCodeAlreadyUsed", err)
+	}
+	if err := repo.MarkAsUsed(id.NewUUIDv7()); !errors.Is(err, client.ErrCodeNotFound) {
+		t.Errorf("MarkAsUsed() of unknown code error = %v, want ErrCodeNotFound", err)
+	}
+	if got, err := repo.GetByCode(o.tenantID, code.Code); err != nil || !got.IsUsed {
+		t.Errorf("GetByCode() after MarkAsUsed = %+v, %v", got, err)
+	}
+
+	if err := repo.Delete(code.Code); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByCode(o.tenantID, code.Code); !errors.Is(err, client.ErrCodeNotFound) {
+		t.Errorf("GetByCode() after Delete error = %v, want ErrCodeNotFound", err)
+	}
+}
+
+func testAccessTokens(t *testing.T, s *Stores) {
+	require(t, map[string]bool{"AccessTokens": s.AccessTokens != nil})
+	o := newOwner(t, s)
+	repo := s.AccessTokens
+
+	grantID := client.NewGrantID()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	var tokens []*client.AccessToken
+	for i, g := range []string{grantID, grantID, client.NewGrantID()} {
+		at := &client.AccessToken{
+			ID:        id.NewUUIDv7(),
+			TenantID:  o.tenantID,
+			TokenHash: id.NewUUIDv7(),
+			ClientID:  o.client.ClientID,
+			UserID:    o.userID,
+			Scope:     "openid",
+			TokenType: "Bearer",
+			GrantID:   g,
+			ExpiresAt: now.Add(time.Hour),
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}
+		if err := repo.Create(at); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		tokens = append(tokens, at)
+	}
+
+	got, err := repo.GetByTokenHash(tokens[0].TokenHash)
+	if err != nil || got.ID != tokens[0].ID || got.GrantID != grantID || got.IsRevoked {
+		t.Errorf("GetByTokenHash() = %+v, %v", got, err)
+	}
+	if _, err := repo.GetByTokenHash(id.NewUUIDv7()); !errors.Is(err, client.ErrTokenNotFound) {
+		t.Errorf("GetByTokenHash() of unknown token error = %v, want ErrTokenNotFound", err)
+	}
+
+	list, err := repo.ListByGrant(o.tenantID, grantID)
+	if err != nil || len(list) != 2 || list[0].ID != tokens[0].ID || list[1].ID != tokens[1].ID {
+		t.Errorf("ListByGrant() = %v, %v, want the grant's two tokens oldest first", list, err)
+	}
+	if list, err := repo.ListByGrant(id.NewUUIDv7(), grantID); err != nil || len(list) != 0 {
+		t.Errorf("ListByGrant() from another tenant = %v, %v, want empty", list, err)
+	}
+
+	if err := repo.Revoke(tokens[0].TokenHash); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if got, err := repo.GetByTokenHash(tokens[0].TokenHash); err != nil || !got.IsRevoked {
+		t.Errorf("GetByTokenHash() after Revoke = %+v, %v", got, err)
+	}
+	if got, err := repo.GetByTokenHash(tokens[1].TokenHash); err != nil || got.IsRevoked {
+		t.Errorf("Revoke() affected another token: %+v, %v", got, err)
+	}
+	if err := repo.Revoke(id.NewUUIDv7()); !errors.Is(err, client.ErrTokenNotFound) {
+		t.Errorf("Revoke() of unknown token error = %v, want ErrTokenNotFound", err)
+	}
+}
+
+func testRefreshTokens(t *testing.T, s *Stores) {
+	require(t, map[string]bool{"RefreshTokens": s.RefreshTokens != nil})
+	o := newOwner(t, s)
+	repo := s.RefreshTokens
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	family := &client.RefreshTokenFamily{
+		ID:         id.NewUUIDv7(),
+		TenantID:   o.tenantID,
+		ClientID:   o.client.ClientID,
+		UserID:     o.userID,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	if err := repo.CreateFamily(family); err != nil {
+		t.Fatalf("CreateFamily() error = %v", err)
+	}
+	if got, err := repo.GetFamily(o.tenantID, family.ID); err != nil || got.UserID != o.userID || got.IsRevoked() {
+		t.Errorf("GetFamily() = %+v, %v", got, err)
+	}
+	if _, err := repo.GetFamily(id.NewUUIDv7(), family.ID); !errors.Is(err, client.ErrFamilyNotFound) {
+		t.Errorf("GetFamily() from another tenant error = %v, want ErrFamilyNotFound", err)
+	}
+
+	rt := &client.RefreshToken{
+		ID:        id.NewUUIDv7(),
+		TenantID:  o.tenantID,
+		TokenHash: id.NewUUIDv7(),
+		ClientID:  o.client.ClientID,
+		UserID:    o.userID,
+		Scope:     "openid offline_access",
+		FamilyID:  family.ID,
+		GrantID:   client.NewGrantID(),
+		ExpiresAt: now.Add(24 * time.Hour),
+		CreatedAt: now,
+	}
+	if err := repo.Create(rt); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := repo.GetByTokenHash(rt.TokenHash)
+	if err != nil || got.ID != rt.ID || got.FamilyID != family.ID || got.GrantID != rt.GrantID || !got.MatchesHash(rt.TokenHash) {
+		t.Errorf("GetByTokenHash() = %+v, %v", got, err)
+	}
+	if _, err := repo.GetByTokenHash(id.NewUUIDv7()); !errors.Is(err, client.ErrTokenNotFound) {
+		t.Errorf("GetByTokenHash() of unknown token error = %v, want ErrTokenNotFound", err)
+	}
+	if list, err := repo.ListByGrant(o.tenantID, rt.GrantID); err != nil || len(list) != 1 || list[0].ID != rt.ID {
+		t.Errorf("ListByGrant() = %v, %v", list, err)
+	}
+
+	if err := repo.Revoke(rt.TokenHash); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if got, err := repo.GetByTokenHash(rt.TokenHash); err != nil || !got.IsRevoked {
+		t.Errorf("GetByTokenHash() after Revoke = %+v, %v", got, err)
+	}
+	if err := repo.Revoke(id.NewUUIDv7()); !errors.Is(err, client.ErrTokenNotFound) {
+		t.Errorf("Revoke() of unknown token error = %v, want ErrTokenNotFound", err)
+	}
+}
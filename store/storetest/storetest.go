@@ -0,0 +1,68 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storetest is a conformance suite for repository implementations. A
+// store passes when it behaves like the PostgreSQL reference implementation:
+// the same sentinel errors for missing rows, the same soft-delete visibility,
+// the same single-use and revocation semantics. New stores (SQLite, MySQL,
+// in-memory, Redis) call Run from their own tests to prove equivalence.
+package storetest
+
+import (
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// Stores is the set of repositories under test. Nil fields are skipped, along
+// with every test that needs them to create its fixtures.
+type Stores struct {
+	Users         user.UserRepository
+	Tenants       tenant.Repository
+	Clients       client.ClientRepository
+	Codes         client.AuthorizationCodeRepository
+	AccessTokens  client.AccessTokenRepository
+	RefreshTokens client.RefreshTokenRepository
+}
+
+// Factory returns repositories over empty storage. It is called once per test;
+// implementations register their own teardown with t.Cleanup.
+type Factory func(t *testing.T) *Stores
+
+// Run runs the conformance suite against the stores newStores returns.
+//
+// Purpose: One behavioral contract shared by every storage backend.
+// Domain: Platform (Infrastructure)
+// Audited: No
+// Errors: Reported through t
+func Run(t *testing.T, newStores Factory) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newStores(t)) })
+	t.Run("Tenants", func(t *testing.T) { testTenants(t, newStores(t)) })
+	t.Run("Clients", func(t *testing.T) { testClients(t, newStores(t)) })
+	t.Run("AuthorizationCodes", func(t *testing.T) { testCodes(t, newStores(t)) })
+	t.Run("AccessTokens", func(t *testing.T) { testAccessTokens(t, newStores(t)) })
+	t.Run("RefreshTokens", func(t *testing.T) { testRefreshTokens(t, newStores(t)) })
+}
+
+// require skips the test unless every named repository is present.
+func require(t *testing.T, present map[string]bool) {
+	t.Helper()
+	for name, ok := range present {
+		if !ok {
+			t.Skipf("%s repository not provided", name)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subject
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/crypto"
+)
+
+// Service derives and resolves client-facing subject identifiers.
+//
+// Purpose: The single place "sub" values are computed, shared by ID tokens, JWT access
+// tokens, UserInfo and introspection.
+// Domain: Identity
+// Invariants: A pairwise identifier is deterministic per tenant, sector and user, and is
+// recorded before it is handed out.
+type Service struct {
+	repo      Repository
+	masterKey []byte
+	clock     clock.Clock
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithClock reads the current time from c for mapping timestamps.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// NewService creates a subject service deriving pairwise keys from masterKey.
+//
+// Purpose: Constructor for the subject identifier service.
+// Domain: Identity
+// Audited: No
+// Errors: crypto.ErrEmptyMasterKey
+func NewService(repo Repository, masterKey []byte, opts ...Option) (*Service, error) {
+	if len(masterKey) == 0 {
+		return nil, crypto.ErrEmptyMasterKey
+	}
+	s := &Service{
+		repo:      repo,
+		masterKey: masterKey,
+		clock:     clock.System(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Subject returns the "sub" c knows userID by.
+//
+// Purpose: Subject claim of tokens and UserInfo responses issued to c.
+// Domain: Identity
+// Security: Public clients get userID. Pairwise clients get an HMAC of their sector and
+// userID under a tenant-specific key (OpenID Connect Core 1.0 Section 8.1), so clients
+// of different sectors or tenants see unrelated identifiers. An empty userID (a token
+// without a user) stays empty.
+// Audited: No
+// Errors: ErrNoSector, System errors
+func (s *Service) Subject(ctx context.Context, c *client.Client, userID string) (string, error) {
+	if userID == "" || !c.IsPairwise() {
+		return userID, nil
+	}
+	sector := c.Sector()
+	if sector == "" {
+		return "", ErrNoSector
+	}
+	key, err := crypto.DeriveHMACKey(s.masterKey, crypto.PurposePairwiseSubject, c.TenantID)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive pairwise key: %w", err)
+	}
+	m := &Mapping{
+		TenantID:  c.TenantID,
+		Sector:    sector,
+		Subject:   crypto.PairwiseSubject(key, sector, userID),
+		UserID:    userID,
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.repo.Save(ctx, m); err != nil {
+		return "", fmt.Errorf("failed to record pairwise subject: %w", err)
+	}
+	return m.Subject, nil
+}
+
+// ResolveUser returns the ID of the user c knows by sub.
+//
+// Purpose: Reverse lookup of a client-facing "sub", e.g. for introspection, logout
+// hints, or support requests quoting a pairwise identifier.
+// Domain: Identity
+// Security: A pairwise sub resolves only within c's tenant and sector, so an identifier
+// issued to one sector is unknown to clients of another.
+// Audited: No
+// Errors: ErrSubjectNotFound, ErrNoSector, System errors
+func (s *Service) ResolveUser(ctx context.Context, c *client.Client, sub string) (string, error) {
+	if sub == "" {
+		return "", ErrSubjectNotFound
+	}
+	if !c.IsPairwise() {
+		return sub, nil
+	}
+	sector := c.Sector()
+	if sector == "" {
+		return "", ErrNoSector
+	}
+	m, err := s.repo.GetBySubject(ctx, c.TenantID, sector, sub)
+	if err != nil {
+		return "", err
+	}
+	return m.UserID, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subject
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/crypto"
+)
+
+type mockRepo struct {
+	mappings map[string]*Mapping
+}
+
+func (m *mockRepo) Save(ctx context.Context, mp *Mapping) error {
+	key := mp.TenantID + "/" + mp.Sector + "/" + mp.Subject
+	if _, ok := m.mappings[key]; !ok {
+		m.mappings[key] = mp
+	}
+	return nil
+}
+
+func (m *mockRepo) GetBySubject(ctx context.Context, tenantID, sector, subject string) (*Mapping, error) {
+	if mp, ok := m.mappings[tenantID+"/"+sector+"/"+subject]; ok {
+		return mp, nil
+	}
+	return nil, ErrSubjectNotFound
+}
+
+func TestNewService(t *testing.T) {
+	if _, err := NewService(&mockRepo{}, nil); !errors.Is(err, crypto.ErrEmptyMasterKey) {
+		t.Errorf("NewService(nil key) error = %v, want ErrEmptyMasterKey", err)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{mappings: map[string]*Mapping{}}
+	s, err := NewService(repo, []byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	public := &client.Client{TenantID: "t1", SubjectType: client.SubjectTypePublic}
+	legacy := &client.Client{TenantID: "t1"}
+	app := &client.Client{TenantID: "t1", SubjectType: client.SubjectTypePairwise, RedirectURIs: []string{"https://app.example.com/cb", "https://APP.example.com/other"}}
+	sibling := &client.Client{TenantID: "t1", SubjectType: client.SubjectTypePairwise, SectorIdentifier: "app.example.com"}
+	crm := &client.Client{TenantID: "t1", SubjectType: client.SubjectTypePairwise, RedirectURIs: []string{"https://crm.example.com/cb"}}
+	otherTenant := &client.Client{TenantID: "t2", SubjectType: client.SubjectTypePairwise, SectorIdentifier: "app.example.com"}
+	noSector := &client.Client{TenantID: "t1", SubjectType: client.SubjectTypePairwise, RedirectURIs: []string{"https://a.example.com/cb", "https://b.example.com/cb"}}
+
+	subject := func(c *client.Client, userID string) string {
+		t.Helper()
+		sub, err := s.Subject(ctx, c, userID)
+		if err != nil {
+			t.Fatalf("Subject() error = %v", err)
+		}
+		return sub
+	}
+
+	t.Run("public clients see the user ID", func(t *testing.T) {
+		if got := subject(public, "u1"); got != "u1" {
+			t.Errorf("Subject(public) = %q, want u1", got)
+		}
+		if got := subject(legacy, "u1"); got != "u1" {
+			t.Errorf("Subject(no subject type) = %q, want u1", got)
+		}
+		if got := subject(app, ""); got != "" {
+			t.Errorf("Subject(pairwise, no user) = %q, want empty", got)
+		}
+	})
+
+	t.Run("pairwise subjects are per sector and tenant", func(t *testing.T) {
+		sub := subject(app, "u1")
+		if sub == "u1" || sub != subject(app, "u1") {
+			t.Fatalf("Subject(pairwise) = %q, want a stable pairwise identifier", sub)
+		}
+		if got := subject(sibling, "u1"); got != sub {
+			t.Errorf("same sector = %q, want %q", got, sub)
+		}
+		if got := subject(crm, "u1"); got == sub {
+			t.Errorf("other sector produced the same subject")
+		}
+		if got := subject(otherTenant, "u1"); got == sub {
+			t.Errorf("other tenant produced the same subject")
+		}
+		if got := subject(app, "u2"); got == sub {
+			t.Errorf("other user produced the same subject")
+		}
+	})
+
+	t.Run("pairwise client without a sector", func(t *testing.T) {
+		if _, err := s.Subject(ctx, noSector, "u1"); !errors.Is(err, ErrNoSector) {
+			t.Errorf("Subject() error = %v, want ErrNoSector", err)
+		}
+	})
+
+	t.Run("ResolveUser", func(t *testing.T) {
+		sub := subject(app, "u1")
+		tests := []struct {
+			name    string
+			c       *client.Client
+			sub     string
+			want    string
+			wantErr error
+		}{
+			{"pairwise", app, sub, "u1", nil},
+			{"same sector", sibling, sub, "u1", nil},
+			{"other sector", crm, sub, "", ErrSubjectNotFound},
+			{"other tenant", otherTenant, sub, "", ErrSubjectNotFound},
+			{"unknown", app, "unknown", "", ErrSubjectNotFound},
+			{"public", public, "u1", "u1", nil},
+			{"empty", public, "", "", ErrSubjectNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := s.ResolveUser(ctx, tt.c, tt.sub)
+				if !errors.Is(err, tt.wantErr) || got != tt.want {
+					t.Errorf("ResolveUser() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package subject maps users to the "sub" values clients see. Public clients see
+// the user's ID; pairwise clients see an identifier derived for their sector, so
+// clients of different sectors cannot correlate a user across applications.
+package subject
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+)
+
+// Domain errors
+var (
+	ErrSubjectNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "subject not found")
+	ErrNoSector        = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, apperror.OAuth2InvalidClientMetadata, "pairwise client has no sector")
+)
+
+// Mapping records a pairwise subject identifier issued for a user.
+//
+// Purpose: The reverse lookup of a pairwise "sub", which cannot be inverted from the
+// identifier itself.
+// Domain: Identity
+// Invariants: Subject is crypto.PairwiseSubject of UserID for Sector under the tenant's
+// pairwise key. (TenantID, Sector, Subject) is unique.
+type Mapping struct {
+	TenantID  string
+	Sector    string
+	Subject   string
+	UserID    string
+	CreatedAt time.Time
+}
+
+// Repository persists pairwise subject mappings.
+type Repository interface {
+	// Save records m; saving an existing mapping is a no-op.
+	Save(ctx context.Context, m *Mapping) error
+	// GetBySubject returns the mapping of subject in a tenant's sector, or
+	// ErrSubjectNotFound.
+	GetBySubject(ctx context.Context, tenantID, sector, subject string) (*Mapping, error)
+}
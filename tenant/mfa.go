@@ -0,0 +1,211 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+// MFAMode selects which members of a tenant must use multi-factor authentication.
+type MFAMode string
+
+// MFA modes
+const (
+	// MFAOptional lets members choose; the zero value behaves the same.
+	MFAOptional MFAMode = "optional"
+	// MFARequired requires MFA from every member.
+	MFARequired MFAMode = "required"
+	// MFARequiredForRoles requires MFA from members holding one of MFAPolicy.Roles.
+	MFARequiredForRoles MFAMode = "roles"
+)
+
+// MaxMFAGraceDays bounds the enrollment grace period
+const MaxMFAGraceDays = 90
+
+// MFAPolicy is a tenant's multi-factor authentication requirement.
+//
+// Purpose: Per-tenant MFA enforcement with an enrollment grace period.
+// Domain: Tenant
+// Invariants: Roles is non-empty only for MFARequiredForRoles and holds tenant roles.
+// GraceDays is between 0 and MaxMFAGraceDays. EnforcedAt is set when the set of
+// members the policy covers last changed, and nil for MFAOptional.
+type MFAPolicy struct {
+	Mode       MFAMode    `json:"mode"`
+	Roles      []string   `json:"roles,omitempty"`
+	GraceDays  int        `json:"grace_days,omitempty"`
+	EnforcedAt *time.Time `json:"enforced_at,omitempty"`
+}
+
+// Validate checks the mode, roles, and grace period.
+func (p *MFAPolicy) Validate() error {
+	switch p.Mode {
+	case "", MFAOptional, MFARequired:
+		if len(p.Roles) > 0 {
+			return fmt.Errorf("%w: roles apply only to mode %q", ErrInvalidMFAPolicy, MFARequiredForRoles)
+		}
+	case MFARequiredForRoles:
+		if len(p.Roles) == 0 {
+			return fmt.Errorf("%w: mode %q needs at least one role", ErrInvalidMFAPolicy, MFARequiredForRoles)
+		}
+		for _, r := range p.Roles {
+			if r != role.RoleTenantOwner && r != role.RoleTenantAdmin && r != role.RoleTenantMember {
+				return fmt.Errorf("%w: unknown role %q", ErrInvalidMFAPolicy, r)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidMFAPolicy, p.Mode)
+	}
+	if p.GraceDays < 0 || p.GraceDays > MaxMFAGraceDays {
+		return fmt.Errorf("%w: grace period must be between 0 and %d days", ErrInvalidMFAPolicy, MaxMFAGraceDays)
+	}
+	return nil
+}
+
+// Enforced reports whether the policy requires MFA from anyone.
+func (p *MFAPolicy) Enforced() bool {
+	return p.Mode == MFARequired || p.Mode == MFARequiredForRoles
+}
+
+// requiredSince reports whether the policy covers a member with roles, and since
+// when: the later of the policy taking effect and the member gaining a covered role.
+func (p *MFAPolicy) requiredSince(roles []*TenantUserRole) (time.Time, bool) {
+	var since time.Time
+	covered := false
+	for _, r := range roles {
+		if p.Mode == MFARequiredForRoles && !slices.Contains(p.Roles, r.Role) {
+			continue
+		}
+		if !covered || r.GrantedAt.Before(since) {
+			since = r.GrantedAt
+		}
+		covered = true
+	}
+	if !covered {
+		return time.Time{}, false
+	}
+	if p.EnforcedAt != nil && p.EnforcedAt.After(since) {
+		since = *p.EnforcedAt
+	}
+	return since, true
+}
+
+// SetMFAPolicy replaces the tenant's MFA policy.
+//
+// Purpose: Tenant admin control over MFA enforcement.
+// Domain: Tenant
+// Security: Changing who is covered restarts the grace period for everyone covered;
+// changing only the grace period does not. Existing sessions are not terminated.
+// Audited: Yes (TypeTenantUpdated)
+// Errors: ErrInvalidMFAPolicy, ErrTenantNotFound
+func (s *Service) SetMFAPolicy(ctx context.Context, tenantID string, policy MFAPolicy, actorID string) (*Tenant, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if policy.Mode == "" {
+		policy.Mode = MFAOptional
+	}
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+	old := t.MFA
+	if old.Mode == "" {
+		old.Mode = MFAOptional
+	}
+
+	switch {
+	case !policy.Enforced():
+		policy.EnforcedAt = nil
+	case policy.Mode == old.Mode && slices.Equal(policy.Roles, old.Roles) && old.EnforcedAt != nil:
+		policy.EnforcedAt = old.EnforcedAt
+	default:
+		now := s.clock.Now()
+		policy.EnforcedAt = &now
+	}
+
+	t.MFA = policy
+	if err := s.repo.Update(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeTenantUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceTenant,
+		TargetName: t.Name,
+		TargetID:   t.ID,
+		Metadata: map[string]any{
+			audit.AttrTenantID:   tenantID,
+			audit.AttrTenantName: t.Name,
+			"changes": map[string]any{
+				"mfa_mode_from":       old.Mode,
+				"mfa_mode_to":         policy.Mode,
+				"mfa_roles_from":      old.Roles,
+				"mfa_roles_to":        policy.Roles,
+				"mfa_grace_days_from": old.GraceDays,
+				"mfa_grace_days_to":   policy.GraceDays,
+			},
+		},
+	})
+	s.recordChange(ctx, &before, t, actorID)
+	events.Emit(ctx, s.events, events.TenantUpdated{Meta: events.NewMeta(t.ID, actorID)})
+	return t, nil
+}
+
+// CheckMFA applies the tenant's MFA policy to a member who just passed the
+// password step. enrolled reports whether the user has registered a factor.
+// During the enrollment grace period an unenrolled member gets a nil error and
+// the deadline, so the transport can prompt enrollment without forcing it.
+//
+// Purpose: Login-time MFA enforcement; the transport maps the errors to
+// flow.Service.RequireMFA (ErrMFARequired) or RequireMFA with enroll
+// (ErrMFAEnrollmentRequired).
+// Domain: Tenant
+// Audited: No
+// Errors: ErrMFARequired, ErrMFAEnrollmentRequired, ErrTenantNotFound, System errors
+func (s *Service) CheckMFA(ctx context.Context, tenantID, userID string, enrolled bool) (time.Time, error) {
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !t.MFA.Enforced() {
+		return time.Time{}, nil
+	}
+	roles, err := s.roleRepo.GetUserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	since, ok := t.MFA.requiredSince(roles)
+	if !ok {
+		return time.Time{}, nil
+	}
+	if enrolled {
+		return time.Time{}, ErrMFARequired
+	}
+	deadline := since.Add(time.Duration(t.MFA.GraceDays) * 24 * time.Hour)
+	if s.clock.Now().Before(deadline) {
+		return deadline, nil
+	}
+	return time.Time{}, ErrMFAEnrollmentRequired
+}
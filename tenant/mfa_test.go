@@ -0,0 +1,79 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/role"
+)
+
+func TestMFAPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  MFAPolicy
+		wantErr bool
+	}{
+		{"zero value", MFAPolicy{}, false},
+		{"required with grace", MFAPolicy{Mode: MFARequired, GraceDays: 14}, false},
+		{"admin roles", MFAPolicy{Mode: MFARequiredForRoles, Roles: []string{role.RoleTenantOwner, role.RoleTenantAdmin}}, false},
+		{"roles without list", MFAPolicy{Mode: MFARequiredForRoles}, true},
+		{"roles with other mode", MFAPolicy{Mode: MFARequired, Roles: []string{role.RoleTenantAdmin}}, true},
+		{"platform role", MFAPolicy{Mode: MFARequiredForRoles, Roles: []string{role.RolePlatformAdmin}}, true},
+		{"unknown mode", MFAPolicy{Mode: "always"}, true},
+		{"grace too long", MFAPolicy{Mode: MFARequired, GraceDays: MaxMFAGraceDays + 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidMFAPolicy) {
+				t.Errorf("Validate() error = %v, want ErrInvalidMFAPolicy", err)
+			}
+		})
+	}
+}
+
+func TestMFAPolicyRequiredSince(t *testing.T) {
+	enforced := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	before, after := enforced.Add(-24*time.Hour), enforced.Add(24*time.Hour)
+	member := &TenantUserRole{Role: role.RoleTenantMember, GrantedAt: before}
+	promoted := &TenantUserRole{Role: role.RoleTenantAdmin, GrantedAt: after}
+
+	tests := []struct {
+		name      string
+		policy    MFAPolicy
+		roles     []*TenantUserRole
+		wantOK    bool
+		wantSince time.Time
+	}{
+		{"all members since enforcement", MFAPolicy{Mode: MFARequired, EnforcedAt: &enforced}, []*TenantUserRole{member}, true, enforced},
+		{"role outside policy", MFAPolicy{Mode: MFARequiredForRoles, Roles: []string{role.RoleTenantAdmin}, EnforcedAt: &enforced}, []*TenantUserRole{member}, false, time.Time{}},
+		{"grace starts at promotion", MFAPolicy{Mode: MFARequiredForRoles, Roles: []string{role.RoleTenantAdmin}, EnforcedAt: &enforced}, []*TenantUserRole{member, promoted}, true, after},
+		{"not a member", MFAPolicy{Mode: MFARequired, EnforcedAt: &enforced}, nil, false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, ok := tt.policy.requiredSince(tt.roles)
+			if ok != tt.wantOK || !since.Equal(tt.wantSince) {
+				t.Errorf("requiredSince() = %v, %v, want %v, %v", since, ok, tt.wantSince, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+// RequiresReason reports whether the tenant requires a justification for op
+func (t *Tenant) RequiresReason(op string) bool {
+	return slices.Contains(t.ReasonRequiredFor, op)
+}
+
+// ReasonRequired reports whether tenantID requires a justification for operation.
+// It implements audit.ReasonPolicy.
+func (s *Service) ReasonRequired(ctx context.Context, tenantID, operation string) (bool, error) {
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+	return t.RequiresReason(operation), nil
+}
+
+// SetReasonPolicy replaces the set of privileged operations that require a
+// justification in the tenant.
+//
+// Purpose: Tenant admin control over mandatory reasons on privileged operations.
+// Domain: Tenant
+// Security: Takes effect on the next privileged call; the reasons are recorded in
+// the audit metadata of the operation they justify.
+// Audited: Yes (TypeTenantUpdated)
+// Errors: ErrInvalidReasonPolicy, ErrTenantNotFound
+func (s *Service) SetReasonPolicy(ctx context.Context, tenantID string, operations []string, actorID string) (*Tenant, error) {
+	ops := make([]string, 0, len(operations))
+	for _, op := range operations {
+		if !audit.IsPrivilegedOperation(op) {
+			return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidReasonPolicy, op)
+		}
+		if !slices.Contains(ops, op) {
+			ops = append(ops, op)
+		}
+	}
+	slices.Sort(ops)
+
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+	if slices.Equal(t.ReasonRequiredFor, ops) {
+		return t, nil
+	}
+	old := t.ReasonRequiredFor
+
+	t.ReasonRequiredFor = ops
+	if err := s.repo.Update(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeTenantUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceTenant,
+		TargetName: t.Name,
+		TargetID:   t.ID,
+		Metadata: map[string]any{
+			audit.AttrTenantID:   tenantID,
+			audit.AttrTenantName: t.Name,
+			"changes": map[string]any{
+				"reason_required_for_from": old,
+				"reason_required_for_to":   ops,
+			},
+		},
+	})
+	s.recordChange(ctx, &before, t, actorID)
+	events.Emit(ctx, s.events, events.TenantUpdated{Meta: events.NewMeta(t.ID, actorID)})
+	return t, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+// RegistrationPolicy returns the dynamic client registration policy of tenantID.
+// It implements client.RegistrationPolicies.
+func (s *Service) RegistrationPolicy(ctx context.Context, tenantID string) (client.RegistrationPolicy, error) {
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return client.RegistrationPolicy{}, err
+	}
+	return t.Registration, nil
+}
+
+// SetRegistrationPolicy replaces the tenant's dynamic client registration policy.
+//
+// Purpose: Tenant admin control over RFC 7591 self-service registration.
+// Domain: Tenant
+// Security: Registration stays off until enabled. Narrowing the policy does not
+// affect clients already registered.
+// Audited: Yes (TypeTenantUpdated)
+// Errors: client.ErrInvalidRegistrationPolicy, ErrTenantNotFound
+func (s *Service) SetRegistrationPolicy(ctx context.Context, tenantID string, policy client.RegistrationPolicy, actorID string) (*Tenant, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+	old := t.Registration
+
+	t.Registration = policy
+	if err := s.repo.Update(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeTenantUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceTenant,
+		TargetName: t.Name,
+		TargetID:   t.ID,
+		Metadata: map[string]any{
+			audit.AttrTenantID:   tenantID,
+			audit.AttrTenantName: t.Name,
+			"changes": map[string]any{
+				"registration_from": old,
+				"registration_to":   policy,
+			},
+		},
+	})
+	s.recordChange(ctx, &before, t, actorID)
+	events.Emit(ctx, s.events, events.TenantUpdated{Meta: events.NewMeta(t.ID, actorID)})
+	return t, nil
+}
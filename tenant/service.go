@@ -19,13 +19,16 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/opentrusty/opentrusty-core/audit"
 	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/events"
 	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/jose"
 	"github.com/opentrusty/opentrusty-core/policy"
 	"github.com/opentrusty/opentrusty-core/role"
+	"github.com/opentrusty/opentrusty-core/tracing"
 	"github.com/opentrusty/opentrusty-core/user"
 )
 
@@ -38,6 +41,44 @@ type Service struct {
 	clientRepo      client.ClientRepository
 	membershipRepo  MembershipRepository
 	auditLogger     audit.Logger
+	tracer          tracing.Tracer
+	events          events.Publisher
+	clock           clock.Clock
+	ids             id.Generator
+	changes         ChangeLog
+}
+
+// ChangeLog records field-level configuration diffs; changelog.Service implements it.
+type ChangeLog interface {
+	Record(ctx context.Context, tenantID, resource, resourceID, actorID string, before, after any)
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithTracer emits spans for tenant lifecycle operations on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithEvents publishes tenant and role assignment events on p.
+func WithEvents(p events.Publisher) Option {
+	return func(s *Service) { s.events = p }
+}
+
+// WithClock reads the current time from c for timestamps and MFA grace deadlines.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// WithChangeLog records the before/after diff of every tenant settings update on l.
+func WithChangeLog(l ChangeLog) Option {
+	return func(s *Service) { s.changes = l }
+}
+
+// WithIDGenerator mints tenant, membership, and assignment IDs with g. Defaults to id.UUIDv7.
+func WithIDGenerator(g id.Generator) Option {
+	return func(s *Service) { s.ids = g }
 }
 
 // NewService creates a new tenant service
@@ -49,8 +90,9 @@ func NewService(
 	clientRepo client.ClientRepository,
 	membershipRepo MembershipRepository,
 	auditLogger audit.Logger,
+	opts ...Option,
 ) *Service {
-	return &Service{
+	s := &Service{
 		repo:            repo,
 		roleRepo:        roleRepo,
 		authzRepo:       authzRepo,
@@ -58,12 +100,21 @@ func NewService(
 		clientRepo:      clientRepo,
 		membershipRepo:  membershipRepo,
 		auditLogger:     auditLogger,
+		clock:           clock.System(),
+		ids:             id.UUIDv7(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // CreateTenant creates a new tenant and provisions an initial tenant_owner.
 // If ownerPassword is empty, a one-time bootstrap secret should be generated (handled by caller or here).
 func (s *Service) CreateTenant(ctx context.Context, name string, ownerEmail string, ownerPassword string, creatorUserID string) (*Tenant, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "tenant.CreateTenant")
+	defer span.End()
+
 	// 1. Validate name
 	name = strings.TrimSpace(name)
 	if name == "" {
@@ -107,9 +158,9 @@ func (s *Service) CreateTenant(ctx context.Context, name string, ownerEmail stri
 	}
 
 	// 4. Generate UUID v7 (RFC 9562) for tenant
-	tenantID := id.NewUUIDv7()
+	tenantID := s.ids.NewID()
 
-	now := time.Now()
+	now := s.clock.Now()
 	tenant := &Tenant{
 		ID:        tenantID,
 		Name:      name,
@@ -146,6 +197,7 @@ func (s *Service) CreateTenant(ctx context.Context, name string, ownerEmail stri
 		TargetID:   tenantID,
 		Metadata:   auditMetadata,
 	})
+	events.Emit(ctx, s.events, events.TenantCreated{Meta: events.NewMeta(tenantID, creatorUserID)})
 
 	return tenant, nil
 }
@@ -165,12 +217,20 @@ func (s *Service) ListTenants(ctx context.Context, limit, offset int) ([]*Tenant
 	return s.repo.List(ctx, limit, offset)
 }
 
+// recordChange appends the diff between before and after to the change log.
+func (s *Service) recordChange(ctx context.Context, before, after *Tenant, actorID string) {
+	if s.changes != nil {
+		s.changes.Record(ctx, after.ID, audit.ResourceTenant, after.ID, actorID, before, after)
+	}
+}
+
 // UpdateTenant updates a tenant
 func (s *Service) UpdateTenant(ctx context.Context, tenantID string, name string, actorID string) (*Tenant, error) {
 	t, err := s.repo.GetByID(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
+	before := *t
 
 	oldName := t.Name
 	if name != "" {
@@ -208,9 +268,139 @@ func (s *Service) UpdateTenant(ctx context.Context, tenantID string, name string
 		TargetID:   t.ID,
 		Metadata:   metadata,
 	})
+	s.recordChange(ctx, &before, t, actorID)
+	events.Emit(ctx, s.events, events.TenantUpdated{Meta: events.NewMeta(t.ID, actorID)})
+	return t, nil
+}
+
+// SetSigningAlgorithm changes the algorithm the tenant's tokens are signed with.
+//
+// Purpose: Per-tenant signing algorithm agility (RS256, PS256, ES256, EdDSA).
+// Domain: Tenant
+// Security: Refused while a client is registered for a different algorithm, so no
+// relying party starts receiving tokens it cannot verify.
+// Audited: Yes (TypeTenantUpdated)
+// Errors: ErrUnsupportedAlg, ErrAlgInUse, ErrTenantNotFound
+func (s *Service) SetSigningAlgorithm(ctx context.Context, tenantID, alg, actorID string) (*Tenant, error) {
+	if !jose.Supported(alg) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
+	}
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+	if t.SigningAlg == alg {
+		return t, nil
+	}
+	oldAlg := t.SigningAlgorithm()
+
+	clients, err := s.clientRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+	var conflicting []string
+	for _, c := range clients {
+		if c.IDTokenSignedResponseAlg != "" && c.IDTokenSignedResponseAlg != alg {
+			conflicting = append(conflicting, c.ClientID)
+		}
+	}
+	if len(conflicting) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrAlgInUse, strings.Join(conflicting, ", "))
+	}
+
+	t.SigningAlg = alg
+	if err := s.repo.Update(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeTenantUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceTenant,
+		TargetName: t.Name,
+		TargetID:   t.ID,
+		Metadata: map[string]any{
+			audit.AttrTenantID:   tenantID,
+			audit.AttrTenantName: t.Name,
+			"changes": map[string]string{
+				"signing_alg_from": oldAlg,
+				"signing_alg_to":   alg,
+			},
+		},
+	})
+	s.recordChange(ctx, &before, t, actorID)
+	events.Emit(ctx, s.events, events.TenantUpdated{Meta: events.NewMeta(t.ID, actorID)})
+	return t, nil
+}
+
+// SetPasswordMaxAge sets how many days a password stays valid in the tenant; 0 disables expiry.
+//
+// Purpose: Per-tenant forced password rotation policy.
+// Domain: Tenant
+// Security: Takes effect at each user's next login via user.Service.CheckPasswordExpiry;
+// existing sessions are not terminated.
+// Audited: Yes (TypeTenantUpdated)
+// Errors: ErrInvalidPasswordAge, ErrTenantNotFound
+func (s *Service) SetPasswordMaxAge(ctx context.Context, tenantID string, days int, actorID string) (*Tenant, error) {
+	if days < 0 || days > MaxPasswordMaxAgeDays {
+		return nil, ErrInvalidPasswordAge
+	}
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+	if t.PasswordMaxAgeDays == days {
+		return t, nil
+	}
+	oldDays := t.PasswordMaxAgeDays
+
+	t.PasswordMaxAgeDays = days
+	if err := s.repo.Update(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeTenantUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceTenant,
+		TargetName: t.Name,
+		TargetID:   t.ID,
+		Metadata: map[string]any{
+			audit.AttrTenantID:   tenantID,
+			audit.AttrTenantName: t.Name,
+			"changes": map[string]int{
+				"password_max_age_days_from": oldDays,
+				"password_max_age_days_to":   days,
+			},
+		},
+	})
+	s.recordChange(ctx, &before, t, actorID)
+	events.Emit(ctx, s.events, events.TenantUpdated{Meta: events.NewMeta(t.ID, actorID)})
 	return t, nil
 }
 
+// SigningAlgorithm returns the algorithm tenantID's tokens are signed with.
+func (s *Service) SigningAlgorithm(ctx context.Context, tenantID string) (string, error) {
+	t, err := s.repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	return t.SigningAlgorithm(), nil
+}
+
+// Discovery returns the signing algorithm metadata parameters advertised for tenantID.
+func (s *Service) Discovery(ctx context.Context, tenantID string) (map[string]any, error) {
+	alg, err := s.SigningAlgorithm(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"id_token_signing_alg_values_supported": []string{alg}}, nil
+}
+
 // DeleteTenant deletes a tenant and performs cascading soft-deletion of associated data
 func (s *Service) DeleteTenant(ctx context.Context, tenantID string, actorID string) error {
 	// 1. Fetch tenant first to get name for audit
@@ -239,6 +429,7 @@ func (s *Service) DeleteTenant(ctx context.Context, tenantID string, actorID str
 	}
 
 	// 3. Delete role assignments (Tenant internal table)
+	ctx = role.AttributeActor(ctx, actorID)
 	if s.roleRepo != nil {
 		if err := s.roleRepo.DeleteByTenantID(ctx, tenantID); err != nil {
 			return fmt.Errorf("failed to cascade tenant role deletion: %w", err)
@@ -268,17 +459,24 @@ func (s *Service) DeleteTenant(ctx context.Context, tenantID string, actorID str
 			audit.AttrTenantName: tenantName,
 		},
 	})
+	events.Emit(ctx, s.events, events.TenantDeleted{Meta: events.NewMeta(tenantID, actorID)})
 	return nil
 }
 
-// AssignRole assigns a role to a user in a tenant
+// AssignRole assigns a role to a user in a tenant. When the tenant requires a
+// reason for audit.OpRoleGrant, ctx must carry one (audit.WithReason).
 func (s *Service) AssignRole(ctx context.Context, tenantID, userID, roleName string, grantedBy string) error {
 	// 1. Persist in tenant_user_roles (Legacy/Primary)
 	// Validate role
 	if roleName != role.RoleTenantOwner && roleName != role.RoleTenantAdmin && roleName != role.RoleTenantMember {
-		return fmt.Errorf("invalid role: %s", roleName)
+		return fmt.Errorf("%w: %s", ErrInvalidRole, roleName)
+	}
+	reason, err := audit.RequireReason(ctx, s, tenantID, audit.OpRoleGrant)
+	if err != nil {
+		return err
 	}
 
+	ctx = role.AttributeActor(ctx, grantedBy)
 	if err := s.roleRepo.AssignRole(ctx, tenantID, userID, roleName, grantedBy); err != nil {
 		return err
 	}
@@ -287,10 +485,10 @@ func (s *Service) AssignRole(ctx context.Context, tenantID, userID, roleName str
 	if s.membershipRepo != nil {
 		// Just try to create, ignore if already exists (unique constraint handles it)
 		_ = s.membershipRepo.AddMember(ctx, &Membership{
-			ID:        id.NewUUIDv7(),
+			ID:        s.ids.NewID(),
 			TenantID:  tenantID,
 			UserID:    userID,
-			CreatedAt: time.Now(),
+			CreatedAt: s.clock.Now(),
 		})
 	}
 
@@ -310,12 +508,12 @@ func (s *Service) AssignRole(ctx context.Context, tenantID, userID, roleName str
 
 	if s.authzRepo != nil && authzRoleID != "" {
 		authzAssignment := &policy.Assignment{
-			ID:             id.NewUUIDv7(),
+			ID:             s.ids.NewID(),
 			UserID:         userID,
 			RoleID:         authzRoleID,
 			Scope:          policy.ScopeTenant,
 			ScopeContextID: &tenantID,
-			GrantedAt:      time.Now(),
+			GrantedAt:      s.clock.Now(),
 			GrantedBy:      grantedBy,
 		}
 		if err := s.authzRepo.Grant(ctx, authzAssignment); err != nil {
@@ -335,6 +533,10 @@ func (s *Service) AssignRole(ctx context.Context, tenantID, userID, roleName str
 		}
 	}
 
+	metadata := map[string]any{audit.AttrActorID: userID}
+	if reason != "" {
+		metadata[audit.AttrReason] = reason
+	}
 	s.auditLogger.Log(ctx, audit.Event{
 		Type:       audit.TypeRoleAssigned,
 		TenantID:   tenantID,
@@ -342,8 +544,9 @@ func (s *Service) AssignRole(ctx context.Context, tenantID, userID, roleName str
 		Resource:   roleName,
 		TargetName: targetName,
 		TargetID:   userID,
-		Metadata:   map[string]any{audit.AttrActorID: userID},
+		Metadata:   metadata,
 	})
+	events.Emit(ctx, s.events, events.RoleAssigned{Meta: events.NewMeta(tenantID, grantedBy), UserID: userID, Role: roleName})
 
 	return nil
 }
@@ -352,9 +555,10 @@ func (s *Service) AssignRole(ctx context.Context, tenantID, userID, roleName str
 func (s *Service) RevokeRole(ctx context.Context, tenantID, userID, roleName string, actorID string) error {
 	// 1. Security Check: Prevent self-revocation of tenant_owner role to avoid accidental lockouts.
 	if userID == actorID && roleName == role.RoleTenantOwner {
-		return fmt.Errorf("security violation: tenant owners cannot revoke their own owner role")
+		return ErrSelfRevocation
 	}
 
+	ctx = role.AttributeActor(ctx, actorID)
 	if err := s.roleRepo.RevokeRole(ctx, tenantID, userID, roleName); err != nil {
 		return err
 	}
@@ -370,6 +574,10 @@ func (s *Service) RevokeRole(ctx context.Context, tenantID, userID, roleName str
 		}
 	}
 
+	metadata := map[string]any{audit.AttrActorID: userID}
+	if reason := audit.Reason(ctx); reason != "" {
+		metadata[audit.AttrReason] = reason
+	}
 	s.auditLogger.Log(ctx, audit.Event{
 		Type:       audit.TypeRoleRevoked,
 		TenantID:   tenantID,
@@ -377,8 +585,9 @@ func (s *Service) RevokeRole(ctx context.Context, tenantID, userID, roleName str
 		Resource:   roleName,
 		TargetName: targetName,
 		TargetID:   userID,
-		Metadata:   map[string]any{audit.AttrActorID: userID},
+		Metadata:   metadata,
 	})
+	events.Emit(ctx, s.events, events.RoleRevoked{Meta: events.NewMeta(tenantID, actorID), UserID: userID, Role: roleName})
 
 	return nil
 }
@@ -388,11 +597,48 @@ func (s *Service) GetUserRoles(ctx context.Context, tenantID, userID string) ([]
 	return s.roleRepo.GetUserRoles(ctx, tenantID, userID)
 }
 
+// RoleNames returns the names of the roles a user has in a tenant, for token claims.
+func (s *Service) RoleNames(ctx context.Context, tenantID, userID string) ([]string, error) {
+	roles, err := s.roleRepo.GetUserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		names = append(names, r.Role)
+	}
+	return names, nil
+}
+
 // GetTenantUsers retrieves all users with roles in a tenant
 func (s *Service) GetTenantUsers(ctx context.Context, tenantID string) ([]*TenantUserRole, error) {
 	return s.roleRepo.GetTenantUsers(ctx, tenantID)
 }
 
+// ListLockedMembers returns the tenant's members that are currently locked out.
+func (s *Service) ListLockedMembers(ctx context.Context, tenantID string) ([]user.LockedAccount, error) {
+	return s.identityService.ListLockedAccounts(ctx, tenantID)
+}
+
+// UnlockMember lifts the lockout of one of the tenant's members.
+//
+// Purpose: Tenant admin recovery for a locked-out member.
+// Domain: Tenant
+// Security: Refused for users outside the tenant, so a tenant admin cannot
+// unlock accounts they do not administer.
+// Audited: Yes (UserUnlocked)
+// Errors: ErrNotMember, user.ErrUserNotFound, System errors
+func (s *Service) UnlockMember(ctx context.Context, tenantID, userID, actorID string) error {
+	ok, err := s.membershipRepo.CheckMembership(ctx, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check membership: %w", err)
+	}
+	if !ok {
+		return ErrNotMember
+	}
+	return s.identityService.Unlock(ctx, tenantID, userID, actorID)
+}
+
 // UpdateUser updates a user's profile information
 func (s *Service) UpdateUser(ctx context.Context, tenantID, userID string, profile user.Profile, actorID string) error {
 	// 2. Update profile in identity service
@@ -16,15 +16,28 @@ package tenant
 
 import (
 	"context"
-	"errors"
 	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/jose"
 )
 
 // Domain errors
 var (
-	ErrTenantNotFound      = errors.New("tenant not found")
-	ErrTenantAlreadyExists = errors.New("tenant already exists")
-	ErrInvalidTenantName   = errors.New("invalid tenant name")
+	ErrTenantNotFound        = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "tenant not found")
+	ErrTenantAlreadyExists   = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "tenant already exists")
+	ErrInvalidTenantName     = apperror.New(apperror.CodeInvalidTenantName, apperror.StatusBadRequest, "", "invalid tenant name")
+	ErrInvalidRole           = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid role")
+	ErrSelfRevocation        = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2AccessDenied, "tenant owners cannot revoke their own owner role")
+	ErrUnsupportedAlg        = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "unsupported signing algorithm")
+	ErrAlgInUse              = apperror.New(apperror.CodeInvalidRequest, apperror.StatusConflict, "", "clients are registered for a different signing algorithm")
+	ErrNotMember             = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "user is not a member of the tenant")
+	ErrInvalidPasswordAge    = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "password max age must be between 0 and 3650 days")
+	ErrInvalidMFAPolicy      = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid MFA policy")
+	ErrMFARequired           = apperror.New(apperror.CodeMFARequired, apperror.StatusForbidden, apperror.OAuth2InteractionRequired, "multi-factor authentication is required")
+	ErrMFAEnrollmentRequired = apperror.New(apperror.CodeMFAEnrollmentRequired, apperror.StatusForbidden, apperror.OAuth2InteractionRequired, "multi-factor authentication enrollment is required")
+	ErrInvalidReasonPolicy   = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid reason policy")
 )
 
 // TenantUserRole represents a user's role assignment in a tenant
@@ -45,18 +58,46 @@ type TenantUserRole struct {
 //
 // Purpose: Root container for data isolation in multi-tenant architecture.
 // Domain: Tenant
-// Invariants: ID must be unique. Status must be Active or Inactive.
+// Invariants: ID must be unique. Status must be Active or Inactive. SigningAlg is a
+// jose algorithm, or "" for DefaultSigningAlg. PasswordMaxAgeDays is zero (no
+// expiry) or positive. MFA is valid per MFAPolicy.Validate. ReasonRequiredFor holds
+// only audit.PrivilegedOperations. Registration is valid per
+// client.RegistrationPolicy.Validate.
 type Tenant struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 string                    `json:"id"`
+	Name               string                    `json:"name"`
+	Status             string                    `json:"status"`
+	SigningAlg         string                    `json:"signing_alg,omitempty"`
+	PasswordMaxAgeDays int                       `json:"password_max_age_days,omitempty"`
+	MFA                MFAPolicy                 `json:"mfa"`
+	ReasonRequiredFor  []string                  `json:"reason_required_for,omitempty"`
+	Registration       client.RegistrationPolicy `json:"registration"`
+	CreatedAt          time.Time                 `json:"created_at"`
+	UpdatedAt          time.Time                 `json:"updated_at"`
+}
+
+// SigningAlgorithm returns the algorithm the tenant's tokens are signed with
+func (t *Tenant) SigningAlgorithm() string {
+	if t.SigningAlg == "" {
+		return DefaultSigningAlg
+	}
+	return t.SigningAlg
+}
+
+// PasswordMaxAge returns how long a password stays valid, or 0 if passwords never expire
+func (t *Tenant) PasswordMaxAge() time.Duration {
+	return time.Duration(t.PasswordMaxAgeDays) * 24 * time.Hour
 }
 
 // DefaultTenantID is the ID of the default tenant
 const DefaultTenantID = "default"
 
+// DefaultSigningAlg signs tokens of tenants that have not chosen an algorithm
+const DefaultSigningAlg = jose.RS256
+
+// MaxPasswordMaxAgeDays bounds a tenant's password max-age
+const MaxPasswordMaxAgeDays = 3650
+
 // Status constants
 const (
 	StatusActive   = "active"
@@ -0,0 +1,69 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/client"
+)
+
+// benchCodes creates n unused authorization codes for the fixture's web client.
+func benchCodes(f *fixture, n int, scope string) []string {
+	codes := make([]string, n)
+	for i := range codes {
+		code := fmt.Sprintf("bench-%d", i)
+		f.codes.codes[code] = &client.AuthorizationCode{
+			Code:        code,
+			TenantID:    "t1",
+			ClientID:    "web",
+			UserID:      fmt.Sprintf("u%d", i%1000),
+			RedirectURI: "https://app.example.com/cb",
+			GrantID:     "grant-" + code,
+			Scope:       scope,
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		codes[i] = code
+	}
+	return codes
+}
+
+func BenchmarkExchangeCode(b *testing.B) {
+	cases := []struct {
+		name  string
+		scope string
+	}{
+		{"access_token", "openid read"},
+		{"with_refresh_token", "openid offline_access"},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			f := newFixture()
+			codes := benchCodes(f, b.N, tc.scope)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := f.svc.ExchangeCode(ctx, exchange(codes[i])); err != nil {
+					b.Fatalf("ExchangeCode() error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/issuance"
+	"github.com/opentrusty/opentrusty-core/maintenance"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// DelegatedGrant issues an access token for the user of a delegation agreement.
+//
+// Purpose: User-scoped tokens for approved background jobs that run without the user.
+// Domain: OAuth2
+// Security: Off unless the service has WithDelegations. The client is authenticated
+// and its network and binding policy enforced first; it must be confidential and
+// registered for GrantTypeDelegated. The agreement must be active and held by the
+// client, and the requested scopes must have been delegated. Only an access token is
+// issued, never a refresh token, and it carries the agreement ID as its grant ID, so
+// revoking the agreement revokes it.
+// Audited: Yes (TokenIssued with grant_type GrantTypeDelegated and the agreement as grant_id)
+// Errors: client.ErrDomainInvalidClient, client.ErrDomainInvalidGrantType,
+// client.ErrDomainInvalidScope, delegation.ErrAgreementInactive, issuance.ErrThrottled,
+// maintenance.ErrReadOnly, maintenance.ErrMaintenance, client network and binding
+// errors, System errors
+func (s *Service) DelegatedGrant(ctx context.Context, req DelegatedGrant) (*Response, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "token.DelegatedGrant")
+	defer span.End()
+
+	if s.maintenance != nil {
+		if err := s.maintenance.Allow(ctx, maintenance.OpTokenIssuance); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := s.clients.AuthenticateClient(ctx, req.TenantID, req.ClientID, req.ClientSecret, req.Request)
+	if err != nil {
+		return nil, err
+	}
+	if s.delegations == nil || c.IsPublic() || !slices.Contains(c.GrantTypes, GrantTypeDelegated) {
+		return nil, client.ErrDomainInvalidGrantType
+	}
+
+	a, err := s.delegations.Use(ctx, req.TenantID, c.ClientID, req.AgreementID)
+	if err != nil {
+		return nil, err
+	}
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = a.Scopes
+	}
+	if !a.Covers(scopes) || !c.ValidateScope(strings.Join(scopes, " ")) {
+		return nil, client.ErrDomainInvalidScope
+	}
+	scope := strings.Join(scopes, " ")
+
+	if s.issuance != nil {
+		if err := s.issuance.Check(ctx, a.UserID, issuance.KindToken); err != nil {
+			return nil, err
+		}
+	}
+
+	g := grant{issuer: req.Issuer, tenantID: req.TenantID, userID: a.UserID, grantID: a.ID, scope: scope, grantType: GrantTypeDelegated}
+	return s.issue(ctx, c, g, scope, "", req.Request)
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/delegation"
+	"github.com/opentrusty/opentrusty-core/issuance"
+)
+
+type mockDelegations map[string]*delegation.Agreement
+
+func (m mockDelegations) Use(ctx context.Context, tenantID, clientID, agreementID string) (*delegation.Agreement, error) {
+	a, ok := m[agreementID]
+	if !ok || a.TenantID != tenantID || a.ClientID != clientID {
+		return nil, delegation.ErrAgreementInactive
+	}
+	return a, nil
+}
+
+func TestDelegatedGrant(t *testing.T) {
+	delegations := mockDelegations{
+		"a1":        {ID: "a1", TenantID: "t1", ClientID: "reports", UserID: "u1", Scopes: []string{"read", "write"}},
+		"throttled": {ID: "throttled", TenantID: "t1", ClientID: "reports", UserID: "u-throttled", Scopes: []string{"read"}},
+	}
+	tests := []struct {
+		name      string
+		opts      []Option
+		modify    func(*DelegatedGrant)
+		wantErr   error
+		wantScope string
+	}{
+		{name: "agreement scopes by default", wantScope: "read write"},
+		{name: "narrowed scope", modify: func(r *DelegatedGrant) { r.Scope = "read" }, wantScope: "read"},
+		{name: "scope not delegated", modify: func(r *DelegatedGrant) { r.Scope = "read offline_access" }, wantErr: client.ErrDomainInvalidScope},
+		{name: "unknown agreement", modify: func(r *DelegatedGrant) { r.AgreementID = "missing" }, wantErr: delegation.ErrAgreementInactive},
+		{name: "client without grant type", modify: func(r *DelegatedGrant) { r.ClientID = "web" }, wantErr: client.ErrDomainInvalidGrantType},
+		{name: "public client", modify: func(r *DelegatedGrant) { r.ClientID = "spa" }, wantErr: client.ErrDomainInvalidGrantType},
+		{name: "other tenant", modify: func(r *DelegatedGrant) { r.TenantID = "t2" }, wantErr: client.ErrDomainInvalidClient},
+		{name: "throttled user", modify: func(r *DelegatedGrant) { r.AgreementID = "throttled" }, wantErr: issuance.ErrThrottled},
+		{name: "disabled without WithDelegations", opts: []Option{WithDelegations(nil)}, wantErr: client.ErrDomainInvalidGrantType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(append([]Option{WithDelegations(delegations)}, tt.opts...)...)
+			req := DelegatedGrant{TenantID: "t1", ClientID: "reports", AgreementID: "a1"}
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+
+			resp, err := f.svc.DelegatedGrant(context.Background(), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DelegatedGrant() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(f.access.tokens) != 0 || len(f.logger.events) != 0 {
+					t.Errorf("failed DelegatedGrant() stored %d tokens, audited %d events", len(f.access.tokens), len(f.logger.events))
+				}
+				return
+			}
+
+			if resp.UserID != "u1" || resp.GrantID != "a1" || resp.Scope != tt.wantScope || resp.RefreshToken != "" {
+				t.Errorf("response = %+v", resp)
+			}
+			if len(f.logger.events) != 1 || f.logger.events[0].Type != audit.TypeTokenIssued || f.logger.events[0].Metadata[attrGrantType] != GrantTypeDelegated {
+				t.Errorf("audit events = %+v", f.logger.events)
+			}
+		})
+	}
+}
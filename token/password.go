@@ -0,0 +1,128 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/bruteforce"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/feature"
+	"github.com/opentrusty/opentrusty-core/issuance"
+	"github.com/opentrusty/opentrusty-core/maintenance"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/tracing"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// Password grant errors
+var (
+	ErrMFARequired = apperror.New(apperror.CodeInvalidGrant, apperror.StatusBadRequest, apperror.OAuth2InvalidGrant, "multi-factor authentication is required for this user")
+)
+
+// PasswordGrant issues tokens for a user's username and password.
+//
+// Purpose: The resource owner password credentials grant (RFC 6749 Section 4.3) for
+// legacy integrations that cannot redirect users.
+// Domain: OAuth2
+// Security: Off unless the service has WithPasswordGrant, the client has
+// PasswordGrantEnabled, and feature.PasswordGrantAllowed is on for the tenant. The
+// client is authenticated and its network and binding policy enforced before the
+// user's credentials are checked. Sources blocked by the AttemptLimiter are refused
+// and every credential outcome is recorded with it. Failures are answered through
+// user.PublicError, so the response does not reveal whether the account exists; users
+// who are not members of the tenant are answered the same way. The grant has no second
+// step, so members the tenant's MFA policy covers are refused, even during the
+// enrollment grace period. Each request starts a new grant.
+// Audited: Yes (TokenIssued with grant_type password; LoginSuccess and LoginFailed by user.Service)
+// Errors: client.ErrDomainInvalidClient, client.ErrDomainInvalidGrantType,
+// client.ErrDomainInvalidScope, user.ErrInvalidCredentials, *user.LockoutError,
+// ErrMFARequired, bruteforce.ErrSourceBlocked, issuance.ErrThrottled, maintenance.ErrReadOnly,
+// maintenance.ErrMaintenance, client network and binding errors, System errors
+func (s *Service) PasswordGrant(ctx context.Context, req PasswordGrant) (*Response, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "token.PasswordGrant")
+	defer span.End()
+
+	if s.maintenance != nil {
+		if err := s.maintenance.Allow(ctx, maintenance.OpTokenIssuance); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := s.clients.AuthenticateClient(ctx, req.TenantID, req.ClientID, req.ClientSecret, req.Request)
+	if err != nil {
+		return nil, err
+	}
+	if s.users == nil || !c.PasswordGrantEnabled || !s.features.Enabled(ctx, req.TenantID, feature.PasswordGrantAllowed) {
+		return nil, client.ErrDomainInvalidGrantType
+	}
+	if !c.ValidateScope(req.Scope) {
+		return nil, client.ErrDomainInvalidScope
+	}
+
+	if s.limiter != nil {
+		if err := s.limiter.Check(ctx, req.Request.RemoteIP); err != nil {
+			return nil, err
+		}
+	}
+	u, err := s.users.AuthenticateInTenant(ctx, req.TenantID, req.Username, req.Password)
+	s.recordAttempt(ctx, req.Request.RemoteIP, err)
+	if err != nil {
+		return nil, user.PublicError(user.FlowLogin, err)
+	}
+	member, err := s.members.CheckMembership(ctx, req.TenantID, u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if !member {
+		return nil, user.PublicError(user.FlowLogin, user.ErrInvalidCredentials)
+	}
+	// Reporting the member as enrolled makes CheckMFA refuse anyone the policy covers.
+	if _, err := s.mfa.CheckMFA(ctx, req.TenantID, u.ID, true); err != nil {
+		if errors.Is(err, tenant.ErrMFARequired) || errors.Is(err, tenant.ErrMFAEnrollmentRequired) {
+			return nil, ErrMFARequired
+		}
+		return nil, fmt.Errorf("failed to check mfa policy: %w", err)
+	}
+
+	if s.issuance != nil {
+		if err := s.issuance.Check(ctx, u.ID, issuance.KindToken); err != nil {
+			return nil, err
+		}
+	}
+
+	g := grant{issuer: req.Issuer, tenantID: req.TenantID, userID: u.ID, grantID: s.ids.NewID(), scope: req.Scope, grantType: GrantTypePassword}
+	return s.issue(ctx, c, g, req.Scope, "", req.Request)
+}
+
+// recordAttempt feeds the outcome of a credential check to the limiter. Errors that
+// are not about the credentials, such as maintenance mode, are not attempts.
+func (s *Service) recordAttempt(ctx context.Context, ip string, err error) {
+	if s.limiter == nil {
+		return
+	}
+	failed := errors.Is(err, user.ErrInvalidCredentials) ||
+		errors.Is(err, user.ErrUserNotFound) ||
+		errors.Is(err, user.ErrAccountLocked) ||
+		errors.Is(err, user.ErrPasswordResetRequired)
+	if err != nil && !failed {
+		return
+	}
+	// A limiter failure must not decide the grant; the source check already ran.
+	_ = s.limiter.RecordAttempt(ctx, bruteforce.Attempt{IP: ip, Success: err == nil, At: s.clock.Now()})
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/bruteforce"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/feature"
+	"github.com/opentrusty/opentrusty-core/issuance"
+	"github.com/opentrusty/opentrusty-core/tenant"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+type mockUsers struct{}
+
+func (m *mockUsers) AuthenticateInTenant(ctx context.Context, tenantID, emailPlain, password string) (*user.User, error) {
+	switch {
+	case emailPlain == "locked@example.com":
+		return nil, user.ErrAccountLocked
+	case emailPlain == "throttled@example.com" && password == "secret":
+		return &user.User{ID: "u-throttled"}, nil
+	case emailPlain == "outsider@example.com" && password == "secret":
+		return &user.User{ID: "u-outsider"}, nil
+	case emailPlain == "mfa@example.com" && password == "secret":
+		return &user.User{ID: "u-mfa"}, nil
+	case emailPlain == "enrolling@example.com" && password == "secret":
+		return &user.User{ID: "u-enrolling"}, nil
+	case emailPlain == "alice@example.com" && password == "secret":
+		return &user.User{ID: "u1"}, nil
+	case emailPlain == "alice@example.com":
+		return nil, user.ErrInvalidCredentials
+	}
+	return nil, user.ErrUserNotFound
+}
+
+type mockMembers struct{}
+
+func (m mockMembers) CheckMembership(ctx context.Context, tenantID, userID string) (bool, error) {
+	return tenantID == "t1" && userID != "u-outsider", nil
+}
+
+// mockMFA covers u-mfa, who has enrolled, and u-enrolling, who is still within the
+// enrollment grace period.
+type mockMFA struct{}
+
+func (m mockMFA) CheckMFA(ctx context.Context, tenantID, userID string, enrolled bool) (time.Time, error) {
+	switch {
+	case userID == "u-mfa":
+		return time.Time{}, tenant.ErrMFARequired
+	case userID == "u-enrolling" && enrolled:
+		return time.Time{}, tenant.ErrMFARequired
+	case userID == "u-enrolling":
+		return time.Now().Add(24 * time.Hour), nil
+	}
+	return time.Time{}, nil
+}
+
+type mockLimiter struct {
+	blocked  string
+	attempts []bruteforce.Attempt
+}
+
+func (m *mockLimiter) Check(ctx context.Context, ip string) error {
+	if ip == m.blocked {
+		return bruteforce.ErrSourceBlocked
+	}
+	return nil
+}
+
+func (m *mockLimiter) RecordAttempt(ctx context.Context, a bruteforce.Attempt) error {
+	m.attempts = append(m.attempts, a)
+	return nil
+}
+
+func TestPasswordGrant(t *testing.T) {
+	allowed := feature.Static{feature.PasswordGrantAllowed: true}
+	tests := []struct {
+		name         string
+		features     feature.Static
+		modify       func(*PasswordGrant)
+		wantErr      error
+		wantRefresh  bool
+		wantAttempts int
+	}{
+		{name: "valid", features: allowed, wantAttempts: 1},
+		{name: "offline access", features: allowed, modify: func(r *PasswordGrant) { r.Scope = "openid offline_access" }, wantRefresh: true, wantAttempts: 1},
+		{name: "tenant flag off", features: nil, wantErr: client.ErrDomainInvalidGrantType},
+		{name: "client not opted in", features: allowed, modify: func(r *PasswordGrant) { r.ClientID = "web" }, wantErr: client.ErrDomainInvalidGrantType},
+		{name: "scope not allowed", features: allowed, modify: func(r *PasswordGrant) { r.Scope = "admin" }, wantErr: client.ErrDomainInvalidScope},
+		{name: "blocked source", features: allowed, modify: func(r *PasswordGrant) { r.Request.RemoteIP = "198.51.100.9" }, wantErr: bruteforce.ErrSourceBlocked},
+		{name: "wrong password", features: allowed, modify: func(r *PasswordGrant) { r.Password = "wrong" }, wantErr: user.ErrInvalidCredentials, wantAttempts: 1},
+		{name: "unknown user answered like a wrong password", features: allowed, modify: func(r *PasswordGrant) { r.Username = "nobody@example.com" }, wantErr: user.ErrInvalidCredentials, wantAttempts: 1},
+		{name: "locked user answered like a wrong password", features: allowed, modify: func(r *PasswordGrant) { r.Username = "locked@example.com" }, wantErr: user.ErrInvalidCredentials, wantAttempts: 1},
+		{name: "throttled user", features: allowed, modify: func(r *PasswordGrant) { r.Username = "throttled@example.com" }, wantErr: issuance.ErrThrottled, wantAttempts: 1},
+		{name: "not a tenant member answered like a wrong password", features: allowed, modify: func(r *PasswordGrant) { r.Username = "outsider@example.com" }, wantErr: user.ErrInvalidCredentials, wantAttempts: 1},
+		{name: "mfa required", features: allowed, modify: func(r *PasswordGrant) { r.Username = "mfa@example.com" }, wantErr: ErrMFARequired, wantAttempts: 1},
+		{name: "mfa enrollment grace period", features: allowed, modify: func(r *PasswordGrant) { r.Username = "enrolling@example.com" }, wantErr: ErrMFARequired, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := &mockLimiter{blocked: "198.51.100.9"}
+			f := newFixture(WithFeatures(tt.features), WithPasswordGrant(&mockUsers{}, mockMembers{}, mockMFA{}), WithAttemptLimiter(limiter))
+			req := PasswordGrant{TenantID: "t1", ClientID: "legacy", Username: "alice@example.com", Password: "secret", Scope: "openid"}
+			req.Request.RemoteIP = "203.0.113.7"
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+
+			resp, err := f.svc.PasswordGrant(context.Background(), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PasswordGrant() error = %v, want %v", err, tt.wantErr)
+			}
+			if errors.Is(err, user.ErrAccountLocked) {
+				t.Errorf("PasswordGrant() must not disclose the lockout, got %v", err)
+			}
+			if len(limiter.attempts) != tt.wantAttempts {
+				t.Errorf("recorded %d attempts, want %d", len(limiter.attempts), tt.wantAttempts)
+			}
+			if tt.wantErr != nil {
+				if len(f.access.tokens) != 0 || len(f.logger.events) != 0 {
+					t.Errorf("failed PasswordGrant() stored %d tokens, audited %d events", len(f.access.tokens), len(f.logger.events))
+				}
+				return
+			}
+
+			if !limiter.attempts[0].Success || resp.UserID != "u1" || resp.GrantID == "" {
+				t.Errorf("response = %+v, attempts = %+v", resp, limiter.attempts)
+			}
+			if got := resp.RefreshToken != ""; got != tt.wantRefresh {
+				t.Errorf("refresh token issued = %v, want %v", got, tt.wantRefresh)
+			}
+			if len(f.logger.events) != 1 || f.logger.events[0].Type != audit.TypeTokenIssued || f.logger.events[0].Metadata[attrGrantType] != GrantTypePassword {
+				t.Errorf("audit events = %+v", f.logger.events)
+			}
+		})
+	}
+}
+
+func TestPasswordGrantDisabledByDefault(t *testing.T) {
+	f := newFixture(WithFeatures(feature.Static{feature.PasswordGrantAllowed: true}))
+	req := PasswordGrant{TenantID: "t1", ClientID: "legacy", Username: "alice@example.com", Password: "secret"}
+	if _, err := f.svc.PasswordGrant(context.Background(), req); !errors.Is(err, client.ErrDomainInvalidGrantType) {
+		t.Errorf("PasswordGrant() without WithPasswordGrant error = %v, want ErrDomainInvalidGrantType", err)
+	}
+}
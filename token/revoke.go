@@ -0,0 +1,210 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Revocation errors
+var (
+	ErrNotTokenOwner = apperror.New(apperror.CodeAccessDenied, apperror.StatusBadRequest, apperror.OAuth2UnauthorizedClient, "token was not issued to this client")
+)
+
+// reasonClientRequest marks revocations requested by the token's client.
+const reasonClientRequest = "client_request"
+
+// Revoke revokes an access or refresh token at its client's request.
+//
+// Purpose: Token revocation (RFC 7009).
+// Domain: OAuth2
+// Security: The client is authenticated with its network and binding policy, and the
+// token must have been issued to it in its tenant. Revoking a refresh token revokes every
+// access and refresh token of its grant (RFC 7009 Section 2.1); revoking an access token
+// also revokes the grant's refresh tokens, so it cannot be silently replaced. The grant's
+// tokens are revoked before the requested one, so a failed cascade leaves that token live
+// and the client can retry. Unknown, already revoked, and other-tenant tokens succeed
+// without effect (RFC 7009 Section 2.2), so the response does not reveal whether a token
+// exists.
+// Audited: Yes (TokenRevoked)
+// Errors: client.ErrDomainInvalidClient, ErrNotTokenOwner, client network and binding
+// errors, System errors
+func (s *Service) Revoke(ctx context.Context, req Revocation) error {
+	ctx, span := tracing.Start(ctx, s.tracer, "token.Revoke")
+	defer span.End()
+
+	c, err := s.clients.AuthenticateClient(ctx, req.TenantID, req.ClientID, req.ClientSecret, req.Request)
+	if err != nil {
+		return err
+	}
+	if req.Token == "" {
+		return nil
+	}
+	hash := HashToken(req.Token)
+
+	revokeFns := []func(context.Context, *client.Client, string) (bool, error){s.revokeAccessToken, s.revokeRefreshToken}
+	if req.TokenTypeHint == HintRefreshToken {
+		revokeFns[0], revokeFns[1] = revokeFns[1], revokeFns[0]
+	}
+	for _, revoke := range revokeFns {
+		found, err := revoke(ctx, c, hash)
+		if err != nil || found {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeAccessToken revokes an access token and the refresh tokens of its grant.
+// It reports whether the hash named an access token of the client's tenant.
+func (s *Service) revokeAccessToken(ctx context.Context, c *client.Client, hash string) (bool, error) {
+	t, err := s.access.GetByTokenHash(hash)
+	if errors.Is(err, client.ErrTokenNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up access token: %w", err)
+	}
+	if t.TenantID != c.TenantID {
+		return false, nil
+	}
+	if t.ClientID != c.ClientID {
+		return true, ErrNotTokenOwner
+	}
+	if t.IsRevoked {
+		return true, nil
+	}
+
+	revoked := 1
+	if t.GrantID != "" {
+		n, err := s.revokeRefreshByGrant(t.TenantID, t.GrantID, "")
+		if err != nil {
+			return true, fmt.Errorf("failed to revoke refresh tokens of grant: %w", err)
+		}
+		revoked += n
+	}
+	if err := s.access.Revoke(t.TokenHash); err != nil {
+		return true, fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	s.logRevocation(ctx, c, t.ID, t.UserID, t.GrantID, HintAccessToken, revoked)
+	return true, nil
+}
+
+// revokeRefreshToken revokes a refresh token and every token of its grant.
+// It reports whether the hash named a refresh token of the client's tenant.
+func (s *Service) revokeRefreshToken(ctx context.Context, c *client.Client, hash string) (bool, error) {
+	t, err := s.refresh.GetByTokenHash(hash)
+	if errors.Is(err, client.ErrTokenNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up refresh token: %w", err)
+	}
+	if t.TenantID != c.TenantID {
+		return false, nil
+	}
+	if t.ClientID != c.ClientID {
+		return true, ErrNotTokenOwner
+	}
+	if t.IsRevoked {
+		return true, nil
+	}
+
+	revoked := 1
+	if t.GrantID != "" {
+		access, accessErr := s.revokeAccessByGrant(t.TenantID, t.GrantID)
+		refresh, refreshErr := s.revokeRefreshByGrant(t.TenantID, t.GrantID, t.TokenHash)
+		if err := errors.Join(accessErr, refreshErr); err != nil {
+			return true, fmt.Errorf("failed to revoke tokens of grant: %w", err)
+		}
+		revoked += access + refresh
+	}
+	if err := s.refresh.Revoke(t.TokenHash); err != nil {
+		return true, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	s.logRevocation(ctx, c, t.ID, t.UserID, t.GrantID, HintRefreshToken, revoked)
+	return true, nil
+}
+
+// revokeAccessByGrant revokes the live access tokens of a grant and returns how many
+// it revoked. A failed token does not stop the cascade; the failures are joined.
+func (s *Service) revokeAccessByGrant(tenantID, grantID string) (int, error) {
+	tokens, err := s.access.ListByGrant(tenantID, grantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list access tokens: %w", err)
+	}
+	revoked := 0
+	var errs []error
+	for _, t := range tokens {
+		if t.IsRevoked {
+			continue
+		}
+		if err := s.access.Revoke(t.TokenHash); err != nil {
+			errs = append(errs, fmt.Errorf("failed to revoke access token %s: %w", t.ID, err))
+			continue
+		}
+		revoked++
+	}
+	return revoked, errors.Join(errs...)
+}
+
+// revokeRefreshByGrant revokes the live refresh tokens of a grant other than except and
+// returns how many it revoked. A failed token does not stop the cascade; the failures
+// are joined.
+func (s *Service) revokeRefreshByGrant(tenantID, grantID, except string) (int, error) {
+	tokens, err := s.refresh.ListByGrant(tenantID, grantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list refresh tokens: %w", err)
+	}
+	revoked := 0
+	var errs []error
+	for _, t := range tokens {
+		if t.IsRevoked || t.TokenHash == except {
+			continue
+		}
+		if err := s.refresh.Revoke(t.TokenHash); err != nil {
+			errs = append(errs, fmt.Errorf("failed to revoke refresh token %s: %w", t.ID, err))
+			continue
+		}
+		revoked++
+	}
+	return revoked, errors.Join(errs...)
+}
+
+// logRevocation records a client-requested revocation.
+func (s *Service) logRevocation(ctx context.Context, c *client.Client, tokenID, userID, grantID, kind string, revoked int) {
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTokenRevoked,
+		TenantID: c.TenantID,
+		ActorID:  c.ClientID,
+		Resource: audit.ResourceToken,
+		TargetID: tokenID,
+		Metadata: map[string]any{
+			audit.AttrGrantID: grantID,
+			audit.AttrReason:  reasonClientRequest,
+			attrClientID:      c.ClientID,
+			attrTokenKind:     kind,
+			attrSubject:       userID,
+			attrRevoked:       revoked,
+		},
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+)
+
+func TestRevoke(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         func(*Response) string
+		hint          string
+		clientID      string
+		tenantID      string
+		wantErr       error
+		wantAccess    bool
+		wantRefresh   bool
+		wantAuditKind string
+	}{
+		{"refresh token cascades to access token", func(r *Response) string { return r.RefreshToken }, "", "web", "t1", nil, true, true, HintRefreshToken},
+		{"refresh token with hint", func(r *Response) string { return r.RefreshToken }, HintRefreshToken, "web", "t1", nil, true, true, HintRefreshToken},
+		{"access token revokes refresh tokens", func(r *Response) string { return r.AccessToken }, HintRefreshToken, "web", "t1", nil, true, true, HintAccessToken},
+		{"unknown token", func(*Response) string { return "unknown" }, "", "web", "t1", nil, false, false, ""},
+		{"empty token", func(*Response) string { return "" }, "", "web", "t1", nil, false, false, ""},
+		{"other client", func(r *Response) string { return r.AccessToken }, "", "m2m", "t1", ErrNotTokenOwner, false, false, ""},
+		{"other tenant", func(r *Response) string { return r.RefreshToken }, "", "t2", "t2", nil, false, false, ""},
+		{"unauthenticated client", func(r *Response) string { return r.AccessToken }, "", "ghost", "t1", client.ErrDomainInvalidClient, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture()
+			resp, err := f.svc.ExchangeCode(ctx, exchange("offline"))
+			if err != nil {
+				t.Fatalf("ExchangeCode() error = %v", err)
+			}
+			f.logger.events = nil
+
+			err = f.svc.Revoke(ctx, Revocation{TenantID: tt.tenantID, ClientID: tt.clientID, Token: tt.token(resp), TokenTypeHint: tt.hint})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Revoke() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := f.access.tokens[0].IsRevoked; got != tt.wantAccess {
+				t.Errorf("access token revoked = %v, want %v", got, tt.wantAccess)
+			}
+			if got := f.refresh.tokens[0].IsRevoked; got != tt.wantRefresh {
+				t.Errorf("refresh token revoked = %v, want %v", got, tt.wantRefresh)
+			}
+
+			if tt.wantAuditKind == "" {
+				if len(f.logger.events) != 0 {
+					t.Errorf("audit events = %+v, want none", f.logger.events)
+				}
+				return
+			}
+			if len(f.logger.events) != 1 {
+				t.Fatalf("audit events = %+v, want one", f.logger.events)
+			}
+			e := f.logger.events[0]
+			if e.Type != audit.TypeTokenRevoked || e.ActorID != "web" || e.Metadata[attrTokenKind] != tt.wantAuditKind || e.Metadata[attrRevoked] != 2 {
+				t.Errorf("audit event = %+v", e)
+			}
+		})
+	}
+}
+
+func TestRevokeIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	resp, err := f.svc.ExchangeCode(ctx, exchange("offline"))
+	if err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+	req := Revocation{TenantID: "t1", ClientID: "web", Token: resp.RefreshToken}
+	if err := f.svc.Revoke(ctx, req); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	f.logger.events = nil
+	if err := f.svc.Revoke(ctx, req); err != nil {
+		t.Fatalf("second Revoke() error = %v", err)
+	}
+	if len(f.logger.events) != 0 {
+		t.Errorf("second Revoke() audit events = %+v, want none", f.logger.events)
+	}
+}
+
+func TestRevokeCascadeFailure(t *testing.T) {
+	errDB := errors.New("connection reset")
+	tests := []struct {
+		name  string
+		token func(*Response) string
+		fail  func(*fixture) *error
+	}{
+		{"refresh token, access tokens unavailable", func(r *Response) string { return r.RefreshToken }, func(f *fixture) *error { return &f.access.err }},
+		{"access token, refresh tokens unavailable", func(r *Response) string { return r.AccessToken }, func(f *fixture) *error { return &f.refresh.err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture()
+			resp, err := f.svc.ExchangeCode(ctx, exchange("offline"))
+			if err != nil {
+				t.Fatalf("ExchangeCode() error = %v", err)
+			}
+			f.logger.events = nil
+			req := Revocation{TenantID: "t1", ClientID: "web", Token: tt.token(resp)}
+
+			failure := tt.fail(f)
+			*failure = errDB
+			if err := f.svc.Revoke(ctx, req); !errors.Is(err, errDB) {
+				t.Fatalf("Revoke() error = %v, want %v", err, errDB)
+			}
+			if f.access.tokens[0].IsRevoked && f.refresh.tokens[0].IsRevoked {
+				t.Errorf("failed cascade revoked both tokens")
+			}
+			if len(f.logger.events) != 0 {
+				t.Errorf("failed Revoke() audit events = %+v, want none", f.logger.events)
+			}
+
+			*failure = nil
+			if err := f.svc.Revoke(ctx, req); err != nil {
+				t.Fatalf("retried Revoke() error = %v", err)
+			}
+			if !f.access.tokens[0].IsRevoked || !f.refresh.tokens[0].IsRevoked {
+				t.Errorf("retried Revoke() left tokens live: access %v, refresh %v", f.access.tokens[0].IsRevoked, f.refresh.tokens[0].IsRevoked)
+			}
+			if len(f.logger.events) != 1 {
+				t.Errorf("retried Revoke() audit events = %+v, want one", f.logger.events)
+			}
+		})
+	}
+}
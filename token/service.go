@@ -0,0 +1,446 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/feature"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/issuance"
+	"github.com/opentrusty/opentrusty-core/maintenance"
+	"github.com/opentrusty/opentrusty-core/oidc"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// Audit metadata keys and values
+const (
+	attrClientID  = "client_id"
+	attrScope     = "scope"
+	attrGrantType = "grant_type"
+	attrAudience  = "audience"
+	attrRefresh   = "refresh_token"
+	attrRevoked   = "revoked"
+	attrTokenKind = "token_type"
+	attrSubject   = "user_id"
+
+	reasonCodeReplay = "code_replay"
+)
+
+// ClientAuthenticator authenticates clients at the token endpoint; client.Service implements it.
+type ClientAuthenticator interface {
+	AuthenticateClient(ctx context.Context, tenantID, clientID, secret string, req client.TokenRequest) (*client.Client, error)
+}
+
+// Service issues tokens for OAuth2 grants.
+//
+// Purpose: The token endpoint's business logic, so transports do not hand-roll it.
+// Domain: OAuth2
+// Invariants: A code yields tokens at most once; redeeming it again revokes every
+// token issued under its grant. Token values are returned once and only their
+// hashes (HashToken) are stored. Every issuance is audited with its grant ID.
+type Service struct {
+	clients     ClientAuthenticator
+	codes       client.AuthorizationCodeRepository
+	access      client.AccessTokenRepository
+	refresh     client.RefreshTokenRepository
+	auditLogger audit.Logger
+	issuance    IssuanceGate
+	users       PasswordAuthenticator
+	members     MembershipChecker
+	mfa         MFAChecker
+	limiter     AttemptLimiter
+	jwtSigner   AccessTokenSigner
+	roles       RoleSource
+	delegations DelegationSource
+	tracer      tracing.Tracer
+	maintenance maintenance.Gate
+	features    feature.Checker
+	clock       clock.Clock
+	ids         id.Generator
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithIssuanceGate refuses tokens to users throttled by g.
+func WithIssuanceGate(g IssuanceGate) Option {
+	return func(s *Service) { s.issuance = g }
+}
+
+// WithPasswordGrant enables PasswordGrant, verifying credentials with users, tenant
+// membership with members, and the tenant MFA policy with mfa. Clients still need
+// PasswordGrantEnabled and their tenant feature.PasswordGrantAllowed.
+func WithPasswordGrant(users PasswordAuthenticator, members MembershipChecker, mfa MFAChecker) Option {
+	return func(s *Service) { s.users, s.members, s.mfa = users, members, mfa }
+}
+
+// WithAttemptLimiter refuses password grant attempts from sources blocked by l and
+// feeds it every credential outcome.
+func WithAttemptLimiter(l AttemptLimiter) Option {
+	return func(s *Service) { s.limiter = l }
+}
+
+// WithJWTAccessTokens mints JWT access tokens with signer, carrying the roles from
+// roles, for clients with JWTAccessTokens. Without it such clients cannot get tokens.
+func WithJWTAccessTokens(signer AccessTokenSigner, roles RoleSource) Option {
+	return func(s *Service) { s.jwtSigner, s.roles = signer, roles }
+}
+
+// WithDelegations enables DelegatedGrant, checking agreements with d. Clients still
+// need client.GrantTypeDelegated.
+func WithDelegations(d DelegationSource) Option {
+	return func(s *Service) { s.delegations = d }
+}
+
+// WithMaintenance refuses token requests while g's platform mode does not accept them.
+func WithMaintenance(g maintenance.Gate) Option {
+	return func(s *Service) { s.maintenance = g }
+}
+
+// WithFeatures consults c for feature.PKCEPlainAllowed. Without it the built-in
+// defaults apply and only S256 code challenges are accepted.
+func WithFeatures(c feature.Checker) Option {
+	return func(s *Service) { s.features = c }
+}
+
+// WithTracer emits spans for token requests on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithClock reads the current time from c for code expiry and token lifetimes.
+// Token values are bearer secrets and always come from crypto/rand.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// WithIDGenerator mints token and refresh family IDs with g. Defaults to id.UUIDv7.
+func WithIDGenerator(g id.Generator) Option {
+	return func(s *Service) { s.ids = g }
+}
+
+// NewService creates a new token service.
+//
+// Purpose: Constructor for the token issuance service.
+// Domain: OAuth2
+// Audited: No
+// Errors: None
+func NewService(
+	clients ClientAuthenticator,
+	codes client.AuthorizationCodeRepository,
+	access client.AccessTokenRepository,
+	refresh client.RefreshTokenRepository,
+	auditLogger audit.Logger,
+	opts ...Option,
+) *Service {
+	s := &Service{
+		clients:     clients,
+		codes:       codes,
+		access:      access,
+		refresh:     refresh,
+		auditLogger: auditLogger,
+		features:    feature.Defaults,
+		clock:       clock.System(),
+		ids:         id.UUIDv7(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ExchangeCode redeems an authorization code for an access token and, when
+// offline_access was granted, a refresh token.
+//
+// Purpose: The authorization_code grant (RFC 6749 Section 4.1.3).
+// Domain: OAuth2
+// Security: Requests are refused before the code is read while the platform mode does
+// not accept token issuance. The client is authenticated and its network and binding
+// policy enforced first. The code must belong to the tenant and client, match the
+// redirect URI, carry a matching PKCE verifier (S256, or plain where
+// feature.PKCEPlainAllowed is on for the tenant; compared in constant time), be
+// unexpired, and be unused. A client that requires PKCE must present a code carrying
+// a challenge, however the code was issued. The code is marked used before any token
+// is minted, so concurrent redemptions cannot both succeed. A replayed code revokes every token of
+// its grant (RFC 6749 Section 4.1.2).
+// Audited: Yes (TokenIssued; TokenRevoked on replay)
+// Errors: client.ErrDomainInvalidClient, client.ErrDomainInvalidGrantType,
+// client.ErrCodeNotFound, client.ErrCodeAlreadyUsed, client.ErrCodeExpired,
+// client.ErrCodeRedirectMismatch, *client.VerifierError (client.ErrInvalidCodeVerifier),
+// client.ErrUnsupportedChallengeMode, client.ErrPKCERequired, client.ErrInvalidTarget,
+// client.ErrInvalidResource, issuance.ErrThrottled, maintenance.ErrReadOnly,
+// maintenance.ErrMaintenance, client network and binding errors, System errors
+func (s *Service) ExchangeCode(ctx context.Context, req CodeExchange) (*Response, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "token.ExchangeCode")
+	defer span.End()
+
+	if s.maintenance != nil {
+		if err := s.maintenance.Allow(ctx, maintenance.OpTokenIssuance); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := s.clients.AuthenticateClient(ctx, req.TenantID, req.ClientID, req.ClientSecret, req.Request)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(c.GrantTypes, GrantTypeAuthorizationCode) {
+		return nil, client.ErrDomainInvalidGrantType
+	}
+
+	code, err := s.codes.GetByCode(req.TenantID, req.Code)
+	if err != nil {
+		return nil, client.ErrCodeNotFound
+	}
+	pkce := client.PKCEPolicy{AllowPlain: s.features.Enabled(ctx, req.TenantID, feature.PKCEPlainAllowed)}
+	if err := code.ValidateAt(s.clock.Now(), pkce, req.TenantID, c.ClientID, req.RedirectURI, req.CodeVerifier); err != nil {
+		if errors.Is(err, client.ErrCodeAlreadyUsed) {
+			s.revokeGrant(ctx, code)
+		}
+		return nil, err
+	}
+	if c.RequiresPKCE() && code.CodeChallenge == "" {
+		return nil, client.ErrPKCERequired
+	}
+	if err := s.codes.MarkAsUsed(req.Code); err != nil {
+		if errors.Is(err, client.ErrCodeAlreadyUsed) {
+			s.revokeGrant(ctx, code)
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+
+	scope, audience := code.Scope, ""
+	if req.Resource != "" {
+		planned, err := c.ResourceTokens(code.Scope, []string{req.Resource})
+		if err != nil {
+			return nil, err
+		}
+		scope, audience = planned[0].Scope, planned[0].Audience
+	}
+
+	if s.issuance != nil {
+		if err := s.issuance.Check(ctx, code.UserID, issuance.KindToken); err != nil {
+			return nil, err
+		}
+	}
+
+	g := codeGrant(code)
+	g.issuer = req.Issuer
+	resp, err := s.issue(ctx, c, g, scope, audience, req.Request)
+	if err != nil {
+		return nil, err
+	}
+	resp.Nonce = code.Nonce
+	return resp, nil
+}
+
+// grant is the user authorization tokens are issued under.
+type grant struct {
+	// issuer is the tenant's issuer identifier, the iss of JWT access tokens
+	issuer    string
+	tenantID  string
+	userID    string
+	grantID   string
+	scope     string
+	grantType string
+}
+
+// codeGrant returns the grant an authorization code was issued under.
+func codeGrant(code *client.AuthorizationCode) grant {
+	return grant{tenantID: code.TenantID, userID: code.UserID, grantID: code.GrantID, scope: code.Scope, grantType: GrantTypeAuthorizationCode}
+}
+
+// issue mints and persists the tokens of one grant. scope and audience may narrow
+// g.scope to one resource; the refresh token keeps the whole grant.
+func (s *Service) issue(ctx context.Context, c *client.Client, g grant, scope, audience string, req client.TokenRequest) (*Response, error) {
+	now := s.clock.Now()
+	lifetime := lifetimeOf(c.AccessTokenLifetime, DefaultAccessTokenLifetime)
+
+	at := &client.AccessToken{
+		ID:        s.ids.NewID(),
+		TenantID:  g.tenantID,
+		ClientID:  c.ClientID,
+		UserID:    g.userID,
+		Scope:     scope,
+		TokenType: TypeBearer,
+		GrantID:   g.grantID,
+		ExpiresAt: now.Add(lifetime),
+		CreatedAt: now,
+		Audience:  audience,
+	}
+	at.Bind(req)
+	if at.DPoPJKT != "" {
+		at.TokenType = TypeDPoP
+	}
+	accessValue, err := s.accessTokenValue(ctx, c, g, at)
+	if err != nil {
+		return nil, err
+	}
+	at.TokenHash = HashToken(accessValue)
+	if err := s.access.Create(at); err != nil {
+		return nil, fmt.Errorf("failed to create access token: %w", err)
+	}
+
+	resp := &Response{
+		AccessToken:   accessValue,
+		AccessTokenID: at.ID,
+		TokenType:     at.TokenType,
+		ExpiresIn:     int(lifetime / time.Second),
+		Scope:         scope,
+		Audience:      audience,
+		GrantID:       g.grantID,
+		UserID:        g.userID,
+	}
+
+	if slices.Contains(strings.Fields(g.scope), client.ScopeOfflineAccess) && slices.Contains(c.GrantTypes, GrantTypeRefreshToken) {
+		refreshValue, err := s.issueRefresh(c, g, at, now)
+		if err != nil {
+			return nil, err
+		}
+		resp.RefreshToken = refreshValue
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTokenIssued,
+		TenantID: g.tenantID,
+		ActorID:  g.userID,
+		Resource: audit.ResourceToken,
+		TargetID: at.ID,
+		Metadata: map[string]any{
+			audit.AttrGrantID: g.grantID,
+			attrClientID:      c.ClientID,
+			attrGrantType:     g.grantType,
+			attrScope:         scope,
+			attrAudience:      audience,
+			attrRefresh:       resp.RefreshToken != "",
+		},
+	})
+	return resp, nil
+}
+
+// accessTokenValue returns the value of at: a JWT for clients with JWTAccessTokens,
+// otherwise an opaque random token.
+func (s *Service) accessTokenValue(ctx context.Context, c *client.Client, g grant, at *client.AccessToken) (string, error) {
+	if !c.JWTAccessTokens {
+		return newTokenValue()
+	}
+	if s.jwtSigner == nil {
+		return "", ErrJWTAccessTokensUnavailable
+	}
+	var roles []string
+	if s.roles != nil {
+		var err error
+		if roles, err = s.roles.RoleNames(ctx, g.tenantID, g.userID); err != nil {
+			return "", fmt.Errorf("failed to get roles for access token: %w", err)
+		}
+	}
+	value, err := s.jwtSigner.IssueAccessToken(ctx, oidc.AccessTokenRequest{Issuer: g.issuer, Client: c, Token: at, Roles: roles})
+	if err != nil {
+		return "", fmt.Errorf("failed to mint jwt access token: %w", err)
+	}
+	return value, nil
+}
+
+// issueRefresh starts a refresh token family for the grant and mints its first token.
+func (s *Service) issueRefresh(c *client.Client, g grant, at *client.AccessToken, now time.Time) (string, error) {
+	family := &client.RefreshTokenFamily{
+		ID:         s.ids.NewID(),
+		TenantID:   g.tenantID,
+		ClientID:   c.ClientID,
+		UserID:     g.userID,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	if err := s.refresh.CreateFamily(family); err != nil {
+		return "", fmt.Errorf("failed to create refresh token family: %w", err)
+	}
+
+	value, err := newTokenValue()
+	if err != nil {
+		return "", err
+	}
+	rt := &client.RefreshToken{
+		ID:            s.ids.NewID(),
+		TenantID:      g.tenantID,
+		TokenHash:     HashToken(value),
+		AccessTokenID: at.ID,
+		ClientID:      c.ClientID,
+		UserID:        g.userID,
+		Scope:         g.scope,
+		FamilyID:      family.ID,
+		GrantID:       g.grantID,
+		ExpiresAt:     now.Add(lifetimeOf(c.RefreshTokenLifetime, DefaultRefreshTokenLifetime)),
+		CreatedAt:     now,
+	}
+	if err := s.refresh.Create(rt); err != nil {
+		return "", fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return value, nil
+}
+
+// revokeGrant revokes every token issued under the grant of a replayed code.
+// Revocation failures are not returned, since the replay is rejected regardless;
+// the audit record carries how many tokens were revoked.
+func (s *Service) revokeGrant(ctx context.Context, code *client.AuthorizationCode) {
+	if code.GrantID == "" {
+		return
+	}
+	access, _ := s.revokeAccessByGrant(code.TenantID, code.GrantID)
+	refresh, _ := s.revokeRefreshByGrant(code.TenantID, code.GrantID, "")
+	revoked := access + refresh
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeTokenRevoked,
+		TenantID: code.TenantID,
+		ActorID:  code.ClientID,
+		Resource: audit.ResourceToken,
+		TargetID: code.UserID,
+		Metadata: map[string]any{
+			audit.AttrGrantID: code.GrantID,
+			audit.AttrReason:  reasonCodeReplay,
+			attrClientID:      code.ClientID,
+			attrRevoked:       revoked,
+		},
+	})
+}
+
+// lifetimeOf converts a client lifetime in seconds, falling back to def when unset.
+func lifetimeOf(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// newTokenValue returns a fresh 256-bit token value.
+func newTokenValue() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,508 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/clock"
+	"github.com/opentrusty/opentrusty-core/feature"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/issuance"
+	"github.com/opentrusty/opentrusty-core/maintenance"
+	"github.com/opentrusty/opentrusty-core/oidc"
+)
+
+const testVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+func testChallenge() string {
+	sum := sha256.Sum256([]byte(testVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type mockClients struct {
+	clients map[string]*client.Client
+}
+
+func (m *mockClients) AuthenticateClient(ctx context.Context, tenantID, clientID, secret string, req client.TokenRequest) (*client.Client, error) {
+	c, ok := m.clients[clientID]
+	if !ok || c.TenantID != tenantID {
+		return nil, client.ErrDomainInvalidClient
+	}
+	return c, nil
+}
+
+type mockCodes struct {
+	client.AuthorizationCodeRepository
+	codes map[string]*client.AuthorizationCode
+}
+
+func (m *mockCodes) GetByCode(tenantID, code string) (*client.AuthorizationCode, error) {
+	c, ok := m.codes[code]
+	if !ok || c.TenantID != tenantID {
+		return nil, client.ErrCodeNotFound
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (m *mockCodes) MarkAsUsed(code string) error {
+	c, ok := m.codes[code]
+	if !ok {
+		return client.ErrCodeNotFound
+	}
+	if c.IsUsed {
+		return client.ErrCodeAlreadyUsed
+	}
+	c.IsUsed = true
+	return nil
+}
+
+type mockAccess struct {
+	client.AccessTokenRepository
+	tokens []*client.AccessToken
+	// err fails ListByGrant and Revoke when set
+	err error
+}
+
+func (m *mockAccess) Create(t *client.AccessToken) error {
+	m.tokens = append(m.tokens, t)
+	return nil
+}
+
+func (m *mockAccess) GetByTokenHash(tokenHash string) (*client.AccessToken, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, client.ErrTokenNotFound
+}
+
+func (m *mockAccess) ListByGrant(tenantID, grantID string) ([]*client.AccessToken, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var res []*client.AccessToken
+	for _, t := range m.tokens {
+		if t.TenantID == tenantID && t.GrantID == grantID {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockAccess) Revoke(tokenHash string) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			t.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+type mockRefresh struct {
+	client.RefreshTokenRepository
+	tokens   []*client.RefreshToken
+	families []*client.RefreshTokenFamily
+	// err fails ListByGrant and Revoke when set
+	err error
+}
+
+func (m *mockRefresh) Create(t *client.RefreshToken) error {
+	m.tokens = append(m.tokens, t)
+	return nil
+}
+
+func (m *mockRefresh) CreateFamily(f *client.RefreshTokenFamily) error {
+	m.families = append(m.families, f)
+	return nil
+}
+
+func (m *mockRefresh) GetByTokenHash(tokenHash string) (*client.RefreshToken, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, client.ErrTokenNotFound
+}
+
+func (m *mockRefresh) ListByGrant(tenantID, grantID string) ([]*client.RefreshToken, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var res []*client.RefreshToken
+	for _, t := range m.tokens {
+		if t.TenantID == tenantID && t.GrantID == grantID {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRefresh) Revoke(tokenHash string) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			t.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+type mockAuditLogger struct {
+	events []audit.Event
+}
+
+func (m *mockAuditLogger) Log(ctx context.Context, e audit.Event) {
+	m.events = append(m.events, e)
+}
+
+type mockGate struct {
+	throttled map[string]bool
+}
+
+func (m *mockGate) Check(ctx context.Context, userID string, kind issuance.Kind) error {
+	if m.throttled[userID] {
+		return issuance.ErrThrottled
+	}
+	return nil
+}
+
+type fixture struct {
+	svc     *Service
+	codes   *mockCodes
+	access  *mockAccess
+	refresh *mockRefresh
+	logger  *mockAuditLogger
+}
+
+func newFixture(opts ...Option) *fixture {
+	clients := &mockClients{clients: map[string]*client.Client{
+		"web": {
+			ClientID:   "web",
+			TenantID:   "t1",
+			GrantTypes: []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken},
+			Resources:  []client.Resource{{URI: "https://api.example.com", Scopes: []string{"read"}}},
+		},
+		"m2m": {ClientID: "m2m", TenantID: "t1", GrantTypes: []string{"client_credentials"}},
+		"t2":  {ClientID: "t2", TenantID: "t2", GrantTypes: []string{GrantTypeAuthorizationCode}},
+		"legacy": {
+			ClientID:             "legacy",
+			TenantID:             "t1",
+			GrantTypes:           []string{GrantTypeRefreshToken},
+			AllowedScopes:        []string{"openid", "offline_access"},
+			PasswordGrantEnabled: true,
+		},
+		"jwt":    {ClientID: "jwt", TenantID: "t1", GrantTypes: []string{GrantTypeAuthorizationCode}, JWTAccessTokens: true},
+		"native": {ClientID: "native", TenantID: "t1", ApplicationType: client.ApplicationTypeNative, GrantTypes: []string{GrantTypeAuthorizationCode}},
+		"reports": {
+			ClientID:      "reports",
+			TenantID:      "t1",
+			GrantTypes:    []string{GrantTypeDelegated},
+			AllowedScopes: []string{"read", "write", "offline_access"},
+		},
+		"spa": {ClientID: "spa", TenantID: "t1", TokenEndpointAuthMethod: client.AuthMethodNone, GrantTypes: []string{GrantTypeDelegated}},
+	}}
+	codes := &mockCodes{codes: make(map[string]*client.AuthorizationCode)}
+	for _, c := range []*client.AuthorizationCode{
+		{Code: "good", Scope: "openid read"},
+		{Code: "offline", Scope: "openid offline_access"},
+		{Code: "pkce", Scope: "openid", CodeChallenge: testChallenge(), CodeChallengeMethod: client.CodeChallengeMethodS256},
+		{Code: "plain", Scope: "openid", CodeChallenge: testVerifier, CodeChallengeMethod: client.CodeChallengeMethodPlain},
+		{Code: "expired", Scope: "openid", ExpiresAt: time.Now().Add(-time.Second)},
+		{Code: "throttled", Scope: "openid", UserID: "u-throttled"},
+	} {
+		c.TenantID, c.ClientID, c.RedirectURI, c.GrantID, c.Nonce = "t1", "web", "https://app.example.com/cb", "grant-"+c.Code, "n-"+c.Code
+		if c.UserID == "" {
+			c.UserID = "u1"
+		}
+		if c.ExpiresAt.IsZero() {
+			c.ExpiresAt = time.Now().Add(time.Minute)
+		}
+		codes.codes[c.Code] = c
+	}
+	access, refresh, logger := &mockAccess{}, &mockRefresh{}, &mockAuditLogger{}
+	gate := &mockGate{throttled: map[string]bool{"u-throttled": true}}
+	return &fixture{
+		svc:     NewService(clients, codes, access, refresh, logger, append([]Option{WithIssuanceGate(gate)}, opts...)...),
+		codes:   codes,
+		access:  access,
+		refresh: refresh,
+		logger:  logger,
+	}
+}
+
+func exchange(code string) CodeExchange {
+	return CodeExchange{TenantID: "t1", ClientID: "web", Code: code, RedirectURI: "https://app.example.com/cb"}
+}
+
+func TestExchangeCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*CodeExchange)
+		code        string
+		wantErr     error
+		wantRefresh bool
+	}{
+		{"valid", nil, "good", nil, false},
+		{"offline access", nil, "offline", nil, true},
+		{"pkce", func(r *CodeExchange) { r.CodeVerifier = testVerifier }, "pkce", nil, false},
+		{"pkce wrong verifier", func(r *CodeExchange) { r.CodeVerifier = strings.Repeat("a", 43) }, "pkce", client.ErrInvalidCodeVerifier, false},
+		{"pkce missing verifier", nil, "pkce", client.ErrInvalidCodeVerifier, false},
+		{"unknown code", nil, "missing", client.ErrCodeNotFound, false},
+		{"expired", nil, "expired", client.ErrCodeExpired, false},
+		{"redirect mismatch", func(r *CodeExchange) { r.RedirectURI = "https://evil.example.com/cb" }, "good", client.ErrCodeRedirectMismatch, false},
+		{"other tenant", func(r *CodeExchange) { r.TenantID = "t2" }, "good", client.ErrDomainInvalidClient, false},
+		{"client without grant type", func(r *CodeExchange) { r.ClientID = "m2m" }, "good", client.ErrDomainInvalidGrantType, false},
+		{"throttled user", nil, "throttled", issuance.ErrThrottled, false},
+		{"unregistered resource", func(r *CodeExchange) { r.Resource = "https://other.example.com" }, "good", client.ErrInvalidTarget, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			req := exchange(tt.code)
+			if tt.modify != nil {
+				tt.modify(&req)
+			}
+			resp, err := f.svc.ExchangeCode(context.Background(), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExchangeCode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(f.access.tokens) != 0 {
+					t.Errorf("failed ExchangeCode() stored %d access tokens", len(f.access.tokens))
+				}
+				return
+			}
+
+			if len(f.access.tokens) != 1 {
+				t.Fatalf("stored %d access tokens, want 1", len(f.access.tokens))
+			}
+			at := f.access.tokens[0]
+			if at.TokenHash != HashToken(resp.AccessToken) || at.TokenHash == resp.AccessToken {
+				t.Errorf("access token must be stored as its hash")
+			}
+			if at.GrantID != "grant-"+tt.code || resp.Nonce != "n-"+tt.code || resp.TokenType != TypeBearer {
+				t.Errorf("response = %+v, token = %+v", resp, at)
+			}
+			if got := resp.RefreshToken != ""; got != tt.wantRefresh {
+				t.Errorf("refresh token issued = %v, want %v", got, tt.wantRefresh)
+			}
+			if tt.wantRefresh && (len(f.refresh.families) != 1 || f.refresh.tokens[0].FamilyID != f.refresh.families[0].ID || f.refresh.tokens[0].TokenHash != HashToken(resp.RefreshToken)) {
+				t.Errorf("refresh token not stored in a new family")
+			}
+			if len(f.logger.events) != 1 || f.logger.events[0].Type != audit.TypeTokenIssued || f.logger.events[0].Metadata[audit.AttrGrantID] != at.GrantID {
+				t.Errorf("audit events = %+v", f.logger.events)
+			}
+		})
+	}
+}
+
+func TestExchangeCodeRequiresPKCE(t *testing.T) {
+	f := newFixture()
+	for _, code := range []string{"good", "pkce"} {
+		f.codes.codes[code].ClientID = "native"
+	}
+
+	req := exchange("good")
+	req.ClientID = "native"
+	if _, err := f.svc.ExchangeCode(context.Background(), req); !errors.Is(err, client.ErrPKCERequired) {
+		t.Fatalf("ExchangeCode() without a challenge error = %v, want ErrPKCERequired", err)
+	}
+	if f.codes.codes["good"].IsUsed || len(f.access.tokens) != 0 {
+		t.Errorf("refused code was redeemed")
+	}
+
+	req = exchange("pkce")
+	req.ClientID, req.CodeVerifier = "native", testVerifier
+	if _, err := f.svc.ExchangeCode(context.Background(), req); err != nil {
+		t.Errorf("ExchangeCode() with a challenge error = %v", err)
+	}
+}
+
+func TestExchangeCodeResource(t *testing.T) {
+	f := newFixture()
+	req := exchange("good")
+	req.Resource = "https://api.example.com"
+
+	resp, err := f.svc.ExchangeCode(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+	if resp.Audience != req.Resource || f.access.tokens[0].Audience != req.Resource || resp.Scope != "read" {
+		t.Errorf("resource token = %+v, want audience %q scope %q", resp, req.Resource, "read")
+	}
+}
+
+func TestExchangeCodeClock(t *testing.T) {
+	now := time.Now().Add(30 * time.Second)
+	f := newFixture(WithClock(clock.Fixed(now)), WithIDGenerator(id.Sequence()))
+	if _, err := f.svc.ExchangeCode(context.Background(), exchange("offline")); err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+	at, family, rt := f.access.tokens[0], f.refresh.families[0], f.refresh.tokens[0]
+	if at.ID != "00000000-0000-7000-8000-000000000001" || family.ID != "00000000-0000-7000-8000-000000000002" || rt.ID != "00000000-0000-7000-8000-000000000003" {
+		t.Errorf("IDs = %s, %s, %s, want the generator's sequence", at.ID, family.ID, rt.ID)
+	}
+	if !at.CreatedAt.Equal(now) || !at.ExpiresAt.Equal(now.Add(DefaultAccessTokenLifetime)) || !rt.ExpiresAt.Equal(now.Add(DefaultRefreshTokenLifetime)) {
+		t.Errorf("token lifetimes not measured from the injected clock: %+v, %+v", at, rt)
+	}
+
+	// Codes in the fixture expire a minute from the wall clock.
+	f = newFixture(WithClock(clock.Fixed(time.Now().Add(2 * time.Minute))))
+	if _, err := f.svc.ExchangeCode(context.Background(), exchange("good")); !errors.Is(err, client.ErrCodeExpired) {
+		t.Errorf("ExchangeCode() error = %v, want %v", err, client.ErrCodeExpired)
+	}
+}
+
+func TestExchangeCodeReplay(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+
+	if _, err := f.svc.ExchangeCode(ctx, exchange("offline")); err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+	if _, err := f.svc.ExchangeCode(ctx, exchange("offline")); !errors.Is(err, client.ErrCodeAlreadyUsed) {
+		t.Fatalf("replayed ExchangeCode() error = %v, want %v", err, client.ErrCodeAlreadyUsed)
+	}
+
+	if !f.access.tokens[0].IsRevoked || !f.refresh.tokens[0].IsRevoked {
+		t.Errorf("tokens of a replayed code's grant must be revoked")
+	}
+	last := f.logger.events[len(f.logger.events)-1]
+	if last.Type != audit.TypeTokenRevoked || last.Metadata[audit.AttrReason] != reasonCodeReplay || last.Metadata[attrRevoked] != 2 {
+		t.Errorf("replay audit event = %+v", last)
+	}
+}
+
+type mockSigner struct {
+	req oidc.AccessTokenRequest
+}
+
+func (m *mockSigner) IssueAccessToken(ctx context.Context, req oidc.AccessTokenRequest) (string, error) {
+	m.req = req
+	return "jwt-" + req.Token.ID, nil
+}
+
+type mockRoles map[string][]string
+
+func (m mockRoles) RoleNames(ctx context.Context, tenantID, userID string) ([]string, error) {
+	return m[userID], nil
+}
+
+func TestExchangeCodeJWTAccessToken(t *testing.T) {
+	ctx := context.Background()
+	signer := &mockSigner{}
+	f := newFixture(WithJWTAccessTokens(signer, mockRoles{"u1": {"admin"}}))
+	f.codes.codes["jwt"] = &client.AuthorizationCode{
+		Code: "jwt", TenantID: "t1", ClientID: "jwt", UserID: "u1", RedirectURI: "https://app.example.com/cb",
+		GrantID: "grant-jwt", Scope: "openid", ExpiresAt: time.Now().Add(time.Minute),
+	}
+	req := CodeExchange{TenantID: "t1", ClientID: "jwt", Code: "jwt", RedirectURI: "https://app.example.com/cb", Issuer: "https://id.example.com/t1"}
+
+	resp, err := f.svc.ExchangeCode(ctx, req)
+	if err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+	at := f.access.tokens[0]
+	if resp.AccessToken != "jwt-"+at.ID || at.TokenHash != HashToken(resp.AccessToken) {
+		t.Errorf("access token = %q with hash %q, want the signed JWT and its hash", resp.AccessToken, at.TokenHash)
+	}
+	if signer.req.Issuer != req.Issuer || signer.req.Token != at || !slices.Equal(signer.req.Roles, []string{"admin"}) {
+		t.Errorf("signer request = %+v", signer.req)
+	}
+
+	// Opaque tokens remain the default for other clients.
+	if resp, err = f.svc.ExchangeCode(ctx, exchange("good")); err != nil || strings.HasPrefix(resp.AccessToken, "jwt-") {
+		t.Errorf("ExchangeCode() = %+v, %v, want an opaque token", resp, err)
+	}
+
+	f = newFixture()
+	f.codes.codes["jwt"] = &client.AuthorizationCode{
+		Code: "jwt", TenantID: "t1", ClientID: "jwt", UserID: "u1", RedirectURI: "https://app.example.com/cb",
+		Scope: "openid", ExpiresAt: time.Now().Add(time.Minute),
+	}
+	if _, err := f.svc.ExchangeCode(ctx, req); !errors.Is(err, ErrJWTAccessTokensUnavailable) {
+		t.Errorf("ExchangeCode() error = %v, want %v", err, ErrJWTAccessTokensUnavailable)
+	}
+}
+
+func TestExchangeCodeDPoP(t *testing.T) {
+	f := newFixture()
+	req := exchange("good")
+	req.Request.DPoPJKT = "jkt"
+
+	resp, err := f.svc.ExchangeCode(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+	if resp.TokenType != TypeDPoP || f.access.tokens[0].DPoPJKT != "jkt" {
+		t.Errorf("DPoP-bound token = %+v", f.access.tokens[0])
+	}
+}
+
+func TestExchangeCodeMaintenance(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	mode := maintenance.NewController(maintenance.ModeReadOnly, f.logger)
+	WithMaintenance(mode)(f.svc)
+
+	if _, err := f.svc.ExchangeCode(ctx, exchange("good")); !errors.Is(err, maintenance.ErrReadOnly) {
+		t.Fatalf("ExchangeCode() in read-only mode error = %v, want %v", err, maintenance.ErrReadOnly)
+	}
+	if f.codes.codes["good"].IsUsed {
+		t.Fatal("refused ExchangeCode() must leave the code unredeemed")
+	}
+
+	if err := mode.Set(ctx, maintenance.ModeNormal, "", "admin"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, err := f.svc.ExchangeCode(ctx, exchange("good")); err != nil {
+		t.Errorf("ExchangeCode() after leaving read-only mode error = %v", err)
+	}
+}
+
+func TestExchangeCodePlainPKCE(t *testing.T) {
+	ctx := context.Background()
+	req := exchange("plain")
+	req.CodeVerifier = testVerifier
+
+	f := newFixture()
+	if _, err := f.svc.ExchangeCode(ctx, req); !errors.Is(err, client.ErrUnsupportedChallengeMode) {
+		t.Fatalf("ExchangeCode() with plain disabled error = %v, want %v", err, client.ErrUnsupportedChallengeMode)
+	}
+
+	f = newFixture()
+	WithFeatures(feature.Static{feature.PKCEPlainAllowed: true})(f.svc)
+	if _, err := f.svc.ExchangeCode(ctx, req); err != nil {
+		t.Fatalf("ExchangeCode() with plain allowed error = %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package token issues OAuth2 tokens. It implements the authorization_code
+// grant end to end: client authentication, single-use code redemption with
+// PKCE, and minting of opaque or, per client, JWT (RFC 9068) access tokens and
+// opaque refresh tokens, of which only hashes are persisted. The resource
+// owner password credentials grant is available to legacy integrations that
+// opt in, and the delegated grant to service accounts acting under a
+// delegation agreement. Transports mint ID tokens from the returned Response
+// with oidc.IDTokenIssuer.
+package token
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/bruteforce"
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/delegation"
+	"github.com/opentrusty/opentrusty-core/issuance"
+	"github.com/opentrusty/opentrusty-core/oidc"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+// Domain errors
+var (
+	ErrJWTAccessTokensUnavailable = apperror.New(apperror.CodeInternal, apperror.StatusInternalServerError, "", "jwt access tokens are not configured")
+)
+
+// Default lifetimes for clients that do not set their own
+const (
+	DefaultAccessTokenLifetime  = time.Hour
+	DefaultRefreshTokenLifetime = 30 * 24 * time.Hour
+)
+
+// Grant types
+const (
+	GrantTypeAuthorizationCode = client.GrantTypeAuthorizationCode
+	GrantTypeRefreshToken      = client.GrantTypeRefreshToken
+	GrantTypePassword          = "password"
+	GrantTypeDelegated         = client.GrantTypeDelegated
+)
+
+// Token types (RFC 6750, RFC 9449)
+const (
+	TypeBearer = "Bearer"
+	TypeDPoP   = "DPoP"
+)
+
+// Token type hints (RFC 7009 Section 2.1), also used by introspection (RFC 7662)
+const (
+	HintAccessToken  = "access_token"
+	HintRefreshToken = "refresh_token"
+)
+
+// CodeExchange is an authorization_code token request.
+//
+// Purpose: Everything the token endpoint received, after transport-level parsing.
+// Domain: OAuth2
+// Invariants: Request carries only values the transport verified (remote IP, DPoP
+// and certificate thumbprints); ClientSecret is empty for public clients.
+type CodeExchange struct {
+	// Issuer is the tenant's issuer identifier; required for clients with JWTAccessTokens.
+	Issuer       string
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	// Resource optionally restricts the access token to one registered resource (RFC 8707).
+	Resource string
+	Request  client.TokenRequest
+}
+
+// PasswordGrant is a resource owner password credentials token request (RFC 6749 Section 4.3).
+//
+// Purpose: Everything the token endpoint received for the password grant, after transport-level parsing.
+// Domain: OAuth2
+// Invariants: Password is only passed to the PasswordAuthenticator and never stored or
+// logged; ClientSecret is empty for public clients.
+type PasswordGrant struct {
+	// Issuer is the tenant's issuer identifier; required for clients with JWTAccessTokens.
+	Issuer       string
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	Username     string
+	Password     string
+	Scope        string
+	Request      client.TokenRequest
+}
+
+// DelegatedGrant is a delegated token request from a service account.
+//
+// Purpose: Everything the token endpoint received for the delegated grant, after transport-level parsing.
+// Domain: OAuth2
+// Invariants: AgreementID names the delegation agreement the client acts under. Scope
+// may narrow the agreement's scopes; empty means all of them.
+type DelegatedGrant struct {
+	// Issuer is the tenant's issuer identifier; required for clients with JWTAccessTokens.
+	Issuer       string
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	AgreementID  string
+	Scope        string
+	Request      client.TokenRequest
+}
+
+// Response is the result of a successful token request.
+//
+// Purpose: Values the transport returns to the client and uses to sign an ID token.
+// Domain: OAuth2
+// Invariants: AccessToken and RefreshToken are the only copies of the token values;
+// they are never persisted or logged. RefreshToken is empty unless offline_access was
+// granted and the client may use the refresh_token grant.
+type Response struct {
+	AccessToken   string
+	AccessTokenID string
+	TokenType     string
+	ExpiresIn     int
+	RefreshToken  string
+	Scope         string
+	Audience      string
+	GrantID       string
+	UserID        string
+	// Nonce is the OIDC nonce of the authorization request, for the ID token.
+	Nonce string
+}
+
+// Revocation is a token revocation request (RFC 7009).
+//
+// Purpose: Everything the revocation endpoint received, after transport-level parsing.
+// Domain: OAuth2
+// Invariants: TokenTypeHint is only an optimization of the lookup order; ClientSecret is
+// empty for public clients.
+type Revocation struct {
+	TenantID      string
+	ClientID      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+	Request       client.TokenRequest
+}
+
+// IssuanceGate refuses issuance to throttled users; issuance.Service implements it.
+type IssuanceGate interface {
+	Check(ctx context.Context, userID string, kind issuance.Kind) error
+}
+
+// PasswordAuthenticator verifies resource owner credentials; user.Service implements it.
+type PasswordAuthenticator interface {
+	AuthenticateInTenant(ctx context.Context, tenantID, emailPlain, password string) (*user.User, error)
+}
+
+// MembershipChecker reports tenant membership; tenant.MembershipRepository implements it.
+type MembershipChecker interface {
+	CheckMembership(ctx context.Context, tenantID, userID string) (bool, error)
+}
+
+// MFAChecker applies a tenant's MFA policy to a user; tenant.Service implements it.
+type MFAChecker interface {
+	CheckMFA(ctx context.Context, tenantID, userID string, enrolled bool) (time.Time, error)
+}
+
+// AttemptLimiter gates and records password grant attempts by source address;
+// bruteforce.Service implements it.
+type AttemptLimiter interface {
+	Check(ctx context.Context, ip string) error
+	RecordAttempt(ctx context.Context, a bruteforce.Attempt) error
+}
+
+// AccessTokenSigner mints JWT access tokens; oidc.IDTokenIssuer implements it.
+type AccessTokenSigner interface {
+	IssueAccessToken(ctx context.Context, req oidc.AccessTokenRequest) (string, error)
+}
+
+// DelegationSource checks and records the use of a delegation agreement;
+// delegation.Service implements it.
+type DelegationSource interface {
+	Use(ctx context.Context, tenantID, clientID, agreementID string) (*delegation.Agreement, error)
+}
+
+// RoleSource returns a user's role names in a tenant; tenant.Service implements it.
+type RoleSource interface {
+	RoleNames(ctx context.Context, tenantID, userID string) ([]string, error)
+}
+
+// HashToken returns the stored form of an access or refresh token value.
+// Transports hash presented tokens with it before lookup.
+func HashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
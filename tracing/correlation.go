@@ -0,0 +1,136 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/opentrusty/opentrusty-core/id"
+)
+
+// Header names transports read and write the identifiers under.
+const (
+	HeaderRequestID     = "X-Request-ID"
+	HeaderCorrelationID = "X-Correlation-ID"
+)
+
+// Log attribute keys of the identifiers.
+const (
+	LogRequestID     = "request_id"
+	LogCorrelationID = "correlation_id"
+	LogTraceID       = "trace_id"
+)
+
+// maxIDLength bounds inbound identifiers accepted from clients.
+const maxIDLength = 128
+
+type requestIDKey struct{}
+
+type correlationIDKey struct{}
+
+// WithRequestID returns ctx carrying the identifier of one transport request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request identifier in ctx, or "".
+func RequestID(ctx context.Context) string {
+	v, _ := ctx.Value(requestIDKey{}).(string)
+	return v
+}
+
+// WithCorrelationID returns ctx carrying the identifier of one user action,
+// which may span several requests and background work.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationID returns the correlation identifier in ctx, or "".
+func CorrelationID(ctx context.Context) string {
+	v, _ := ctx.Value(correlationIDKey{}).(string)
+	return v
+}
+
+// Incoming prepares the context of an inbound request from the values of the
+// request and correlation headers.
+//
+// Purpose: The single entry point transport middleware calls for every request.
+// Domain: Platform (Observability)
+// Security: Inbound values are client-controlled; only short printable tokens are
+// kept, anything else is replaced by a generated identifier.
+// Audited: No
+// Errors: None
+func Incoming(ctx context.Context, requestID, correlationID string) context.Context {
+	if !ValidID(requestID) {
+		requestID = id.NewUUIDv7()
+	}
+	if !ValidID(correlationID) {
+		correlationID = requestID
+	}
+	return WithCorrelationID(WithRequestID(ctx, requestID), correlationID)
+}
+
+// ValidID reports whether s is acceptable as a request or correlation identifier:
+// 1 to 128 characters of letters, digits, and "-_.:".
+func ValidID(s string) bool {
+	if s == "" || len(s) > maxIDLength {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// LogHandler wraps h so every record logged with a context carries that
+// context's request, correlation, and trace identifiers. Attributes the record
+// already sets are left alone.
+func LogHandler(h slog.Handler) slog.Handler {
+	return logHandler{h}
+}
+
+type logHandler struct{ slog.Handler }
+
+func (h logHandler) Handle(ctx context.Context, r slog.Record) error {
+	set := map[string]bool{}
+	r.Attrs(func(a slog.Attr) bool {
+		set[a.Key] = true
+		return true
+	})
+	for _, a := range []slog.Attr{
+		slog.String(LogRequestID, RequestID(ctx)),
+		slog.String(LogCorrelationID, CorrelationID(ctx)),
+		slog.String(LogTraceID, TraceID(ctx)),
+	} {
+		if a.Value.String() != "" && !set[a.Key] {
+			r.AddAttrs(a)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{h.Handler.WithGroup(name)}
+}
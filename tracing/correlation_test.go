@@ -0,0 +1,80 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestIncoming(t *testing.T) {
+	tests := []struct {
+		name            string
+		requestID       string
+		correlationID   string
+		wantRequest     string
+		wantCorrelation string
+	}{
+		{"both supplied", "req-1", "corr-1", "req-1", "corr-1"},
+		{"correlation defaults to request", "req-1", "", "req-1", "req-1"},
+		{"invalid correlation replaced", "req-1", "bad value\n", "req-1", "req-1"},
+		{"request generated", "", "corr-1", "", "corr-1"},
+		{"oversized request replaced", strings.Repeat("a", 129), "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := Incoming(context.Background(), tt.requestID, tt.correlationID)
+			req, corr := RequestID(ctx), CorrelationID(ctx)
+			if !ValidID(req) || !ValidID(corr) {
+				t.Fatalf("Incoming() ids = %q, %q; want valid identifiers", req, corr)
+			}
+			if tt.wantRequest != "" && req != tt.wantRequest {
+				t.Errorf("RequestID() = %q, want %q", req, tt.wantRequest)
+			}
+			if tt.wantCorrelation != "" && corr != tt.wantCorrelation {
+				t.Errorf("CorrelationID() = %q, want %q", corr, tt.wantCorrelation)
+			}
+			if tt.wantCorrelation == "" && corr != req {
+				t.Errorf("CorrelationID() = %q, want the request ID %q", corr, req)
+			}
+		})
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(LogHandler(slog.NewJSONHandler(&buf, nil)))
+	ctx := Incoming(context.Background(), "req-1", "corr-1")
+
+	logger.InfoContext(ctx, "hello", LogRequestID, "explicit")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid log line %q: %v", buf.String(), err)
+	}
+	if line[LogCorrelationID] != "corr-1" {
+		t.Errorf("correlation_id = %v, want corr-1", line[LogCorrelationID])
+	}
+	if line[LogRequestID] != "explicit" || strings.Count(buf.String(), LogRequestID) != 1 {
+		t.Errorf("request_id overridden or duplicated: %s", buf.String())
+	}
+	if _, ok := line[LogTraceID]; ok {
+		t.Error("empty trace_id logged")
+	}
+}
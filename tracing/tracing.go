@@ -0,0 +1,120 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing defines the minimal span API the core services emit into.
+// Host applications adapt it to OpenTelemetry (or any other tracer); core
+// itself has no tracing dependency and defaults to a no-op tracer. It also
+// carries the request and correlation IDs transport middleware sets, so one
+// user action can be followed through logs, audit events, and webhooks.
+package tracing
+
+import "context"
+
+// Attribute keys used by core spans. Values MUST NOT contain PII.
+const (
+	AttrTenantID    = "opentrusty.tenant_id"
+	AttrClientID    = "opentrusty.client_id"
+	AttrUserID      = "opentrusty.user_id"
+	AttrResult      = "opentrusty.result"
+	AttrDBSystem    = "db.system"
+	AttrDBOperation = "db.operation"
+)
+
+// Attribute is a key/value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String creates a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer starts spans.
+//
+// Purpose: Adapter boundary between core and the host's tracing SDK.
+// Domain: Platform (Observability)
+type Tracer interface {
+	// Start begins a span as a child of any span in ctx.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is an in-progress unit of traced work.
+//
+// Purpose: Records attributes and errors for one operation.
+// Domain: Platform (Observability)
+// Invariants: End is called exactly once.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+	// TraceID returns the hex trace identifier, or "" if the span is not recording.
+	TraceID() string
+}
+
+type spanKey struct{}
+
+// Start begins a span with t (or the no-op tracer if t is nil) and stores it
+// in the returned context so TraceID can find it.
+func Start(ctx context.Context, t Tracer, name string, attrs ...Attribute) (context.Context, Span) {
+	if t == nil {
+		t = Noop()
+	}
+	ctx, span := t.Start(ctx, name, attrs...)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the current span, or a no-op span if none is set.
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// TraceID returns the trace identifier of the current span in ctx, or "".
+func TraceID(ctx context.Context) string {
+	return SpanFromContext(ctx).TraceID()
+}
+
+// Noop returns a tracer that records nothing.
+func Noop() Tracer {
+	return noopTracer{}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	// Keep the parent span visible so trace IDs still propagate.
+	if parent, ok := ctx.Value(spanKey{}).(Span); ok {
+		return ctx, childOf{parent}
+	}
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+func (noopSpan) TraceID() string            { return "" }
+
+// childOf is a no-op span that reports its parent's trace ID.
+type childOf struct{ parent Span }
+
+func (childOf) SetAttributes(...Attribute) {}
+func (childOf) RecordError(error)          {}
+func (childOf) End()                       {}
+func (c childOf) TraceID() string          { return c.parent.TraceID() }
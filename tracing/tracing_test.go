@@ -0,0 +1,62 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+type recordingTracer struct{ started []string }
+
+type recordingSpan struct {
+	traceID string
+	ended   bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, _ ...Attribute) (context.Context, Span) {
+	t.started = append(t.started, name)
+	return ctx, &recordingSpan{traceID: "4bf92f3577b34da6a3ce929d0e0e4736"}
+}
+
+func (s *recordingSpan) SetAttributes(...Attribute) {}
+func (s *recordingSpan) RecordError(error)          {}
+func (s *recordingSpan) End()                       { s.ended = true }
+func (s *recordingSpan) TraceID() string            { return s.traceID }
+
+func TestTraceIDPropagation(t *testing.T) {
+	if id := TraceID(context.Background()); id != "" {
+		t.Errorf("TraceID() on empty context = %q, want empty", id)
+	}
+
+	tracer := &recordingTracer{}
+	ctx, span := Start(context.Background(), tracer, "outer")
+	defer span.End()
+
+	if got := TraceID(ctx); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceID() = %q", got)
+	}
+
+	// A nil tracer in a nested call must not hide the parent's trace.
+	inner, innerSpan := Start(ctx, nil, "inner")
+	innerSpan.End()
+	if got := TraceID(inner); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("nested TraceID() = %q, want parent trace", got)
+	}
+
+	if len(tracer.started) != 1 || tracer.started[0] != "outer" {
+		t.Errorf("started spans = %v", tracer.started)
+	}
+}
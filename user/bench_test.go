@@ -0,0 +1,93 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// benchUsers is the number of identities seeded for authentication benchmarks.
+const benchUsers = 10000
+
+// indexedUserRepository answers GetByHash from an index, as the unique
+// email_hash index does in PostgreSQL, so lookups stay O(1) at volume.
+type indexedUserRepository struct {
+	*MockUserRepository
+	byHash map[string]*User
+}
+
+func (m *indexedUserRepository) Create(ctx context.Context, user *User) error {
+	m.byHash[user.EmailHash] = user
+	return m.MockUserRepository.Create(ctx, user)
+}
+
+func (m *indexedUserRepository) GetByHash(ctx context.Context, hash string) (*User, error) {
+	u, ok := m.byHash[hash]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return u, nil
+}
+
+// newBenchService seeds benchUsers identities and sets a password on the one
+// that is authenticated. Hashing uses the production Argon2id parameters.
+func newBenchService(b *testing.B) (*Service, string, string) {
+	b.Helper()
+	repo := &indexedUserRepository{MockUserRepository: NewMockUserRepository(), byHash: make(map[string]*User)}
+	hasher := NewPasswordHasher(64*1024, 3, 2, 16, 32)
+	svc := NewService(repo, hasher, &MockAuditLogger{}, 5, time.Hour, "bench-key")
+
+	ctx := context.Background()
+	for i := 0; i < benchUsers; i++ {
+		if _, err := svc.ProvisionIdentity(ctx, fmt.Sprintf("user%d@example.com", i), Profile{}); err != nil {
+			b.Fatalf("failed to provision identity: %v", err)
+		}
+	}
+	email, password := "bench@example.com", "correct-horse-battery"
+	u, err := svc.ProvisionIdentity(ctx, email, Profile{})
+	if err != nil {
+		b.Fatalf("failed to provision identity: %v", err)
+	}
+	if err := svc.AddPassword(ctx, u.ID, password); err != nil {
+		b.Fatalf("failed to add password: %v", err)
+	}
+	return svc, email, password
+}
+
+func BenchmarkAuthenticate(b *testing.B) {
+	svc, email, password := newBenchService(b)
+	ctx := context.Background()
+
+	b.Run("success", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := svc.Authenticate(ctx, email, password); err != nil {
+				b.Fatalf("authentication failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("unknown_user", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := svc.Authenticate(ctx, "nobody@example.com", password); err != ErrInvalidCredentials {
+				b.Fatalf("expected ErrInvalidCredentials, got %v", err)
+			}
+		}
+	})
+}
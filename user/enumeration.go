@@ -0,0 +1,65 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import "errors"
+
+// Flow names a flow addressed by email address, whose answers must not reveal
+// whether an account exists.
+type Flow string
+
+// Email-addressed flows
+const (
+	FlowLogin         Flow = "login"
+	FlowPasswordReset Flow = "password_reset"
+	FlowInvitation    Flow = "invitation"
+)
+
+// PublicError returns the error a transport may show the client for err, returned
+// while serving flow.
+//
+// Purpose: Enumeration-resistant response policy for login, password reset, and invitation.
+// Domain: Identity
+// Security: Logins answer unknown, locked, and reset-required accounts alike with
+// ErrInvalidCredentials; only the *LockoutError and *AttemptsError a tenant opted
+// into with feature.LoginAttemptFeedback pass through. Password reset and invitation
+// requests answer nil whether or not the account exists, so the transport sends
+// the same acknowledgement and decides from err alone whether to deliver mail.
+// Authenticate already pays a password verification on every failure, so answers
+// also take the same time; transports must equally not skip work on unknown accounts.
+// Audited: No
+// Errors: ErrInvalidCredentials, or err unchanged when it says nothing about the account
+func PublicError(flow Flow, err error) error {
+	if err == nil {
+		return nil
+	}
+	disclosing := errors.Is(err, ErrUserNotFound) ||
+		errors.Is(err, ErrUserAlreadyExists) ||
+		errors.Is(err, ErrAccountLocked) ||
+		errors.Is(err, ErrPasswordResetRequired)
+
+	switch flow {
+	case FlowLogin:
+		var lockout *LockoutError
+		if disclosing && !errors.As(err, &lockout) {
+			return ErrInvalidCredentials
+		}
+	case FlowPasswordReset, FlowInvitation:
+		if disclosing || errors.Is(err, ErrInvalidCredentials) {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/audit"
+)
+
+// Identity linking errors
+var (
+	ErrIdentityNotFound      = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "identity not found")
+	ErrIdentityAlreadyLinked = apperror.New(apperror.CodeAlreadyExists, apperror.StatusConflict, "", "identity is already linked to a user")
+	ErrInvalidIdentity       = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid identity")
+	ErrLastIdentity          = apperror.New(apperror.CodeInvalidRequest, apperror.StatusConflict, "", "cannot remove the last login method")
+	ErrIdentitiesDisabled    = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "identity linking is not configured")
+)
+
+// IdentityKind is a login method.
+type IdentityKind string
+
+// Identity kinds
+const (
+	// IdentityPassword is backed by the user's Credentials; Subject is the user ID.
+	IdentityPassword IdentityKind = "password"
+	// IdentityFederated is an upstream IdP account; Issuer is the IdP issuer, Subject its sub.
+	IdentityFederated IdentityKind = "federated"
+	// IdentityPasskey is a WebAuthn credential; Issuer is the RP ID, Subject the base64url credential ID.
+	IdentityPasskey IdentityKind = "passkey"
+	// IdentityPhone is a phone number verified by the host; Subject is the E.164 number.
+	IdentityPhone IdentityKind = "phone"
+)
+
+// e164Pattern matches an E.164 phone number
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// LinkedIdentity is one way a user can log in.
+//
+// Purpose: Uniform model for a user's login methods.
+// Domain: Identity
+// Invariants: (Kind, Issuer, Subject) identifies at most one user. Metadata carries
+// display information (device name, provider label), never secrets or key material.
+// Password identities exist exactly while the user has Credentials.
+type LinkedIdentity struct {
+	ID         string            `json:"id"`
+	UserID     string            `json:"user_id"`
+	Kind       IdentityKind      `json:"kind"`
+	Issuer     string            `json:"issuer,omitempty"`
+	Subject    string            `json:"subject"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	LastUsedAt *time.Time        `json:"last_used_at,omitempty"`
+}
+
+// Validate checks that the identity is well-formed for its kind.
+func (i *LinkedIdentity) Validate() error {
+	switch i.Kind {
+	case IdentityFederated, IdentityPasskey:
+		if i.Issuer == "" || i.Subject == "" {
+			return fmt.Errorf("%w: %s identities need an issuer and a subject", ErrInvalidIdentity, i.Kind)
+		}
+	case IdentityPhone:
+		if i.Issuer != "" || !e164Pattern.MatchString(i.Subject) {
+			return fmt.Errorf("%w: phone identities need an E.164 number", ErrInvalidIdentity)
+		}
+	case IdentityPassword:
+		return fmt.Errorf("%w: password identities are created with the password", ErrInvalidIdentity)
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidIdentity, i.Kind)
+	}
+	if len(i.Subject) > 512 || len(i.Issuer) > 512 {
+		return fmt.Errorf("%w: issuer and subject are limited to 512 bytes", ErrInvalidIdentity)
+	}
+	return nil
+}
+
+// IdentityRepository defines persistence for linked identities.
+//
+// Purpose: Storage for a user's login methods.
+// Domain: Identity
+type IdentityRepository interface {
+	// Create links an identity, or returns ErrIdentityAlreadyLinked
+	Create(ctx context.Context, identity *LinkedIdentity) error
+	// Get returns the identity with kind, issuer, and subject, or ErrIdentityNotFound
+	Get(ctx context.Context, kind IdentityKind, issuer, subject string) (*LinkedIdentity, error)
+	// ListByUser returns a user's identities, oldest first
+	ListByUser(ctx context.Context, userID string) ([]*LinkedIdentity, error)
+	// Delete unlinks an identity of a user; unlinking a password identity deletes the Credentials too
+	Delete(ctx context.Context, userID, id string) error
+	// Touch records that the identity was used to log in at
+	Touch(ctx context.Context, kind IdentityKind, issuer, subject string, at time.Time) error
+}
+
+// WithIdentities manages login methods in repo. Without it the identity
+// linking methods fail with ErrIdentitiesDisabled.
+func WithIdentities(repo IdentityRepository) Option {
+	return func(s *Service) { s.identities = repo }
+}
+
+// LinkIdentity adds a login method to a user.
+//
+// Purpose: Lets a user sign in with several methods (federated accounts, passkeys, phone).
+// Domain: Identity
+// Security: The caller must have verified the identity (upstream login, WebAuthn
+// registration ceremony, phone OTP) before linking it.
+// Audited: Yes (IdentityLinked)
+// Errors: ErrIdentitiesDisabled, ErrInvalidIdentity, ErrUserNotFound, ErrIdentityAlreadyLinked, System errors
+func (s *Service) LinkIdentity(ctx context.Context, userID, actorID string, identity LinkedIdentity) (*LinkedIdentity, error) {
+	if s.identities == nil {
+		return nil, ErrIdentitiesDisabled
+	}
+	if err := identity.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	identity.ID = s.ids.NewID()
+	identity.UserID = userID
+	identity.CreatedAt = s.clock.Now()
+	identity.LastUsedAt = nil
+	if err := s.identities.Create(ctx, &identity); err != nil {
+		return nil, err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeIdentityLinked,
+		ActorID:  actorID,
+		Resource: audit.ResourceIdentity,
+		TargetID: userID,
+		Metadata: map[string]any{"identity_id": identity.ID, "kind": identity.Kind, "issuer": identity.Issuer},
+	})
+	return &identity, nil
+}
+
+// ListIdentities returns a user's login methods.
+func (s *Service) ListIdentities(ctx context.Context, userID string) ([]*LinkedIdentity, error) {
+	if s.identities == nil {
+		return nil, ErrIdentitiesDisabled
+	}
+	return s.identities.ListByUser(ctx, userID)
+}
+
+// UnlinkIdentity removes a login method from a user.
+//
+// Purpose: Uniform removal of login methods, the password included.
+// Domain: Identity
+// Security: The last remaining method cannot be removed, so users are never locked out
+// of their own account this way.
+// Audited: Yes (IdentityUnlinked)
+// Errors: ErrIdentitiesDisabled, ErrIdentityNotFound, ErrLastIdentity, System errors
+func (s *Service) UnlinkIdentity(ctx context.Context, userID, identityID, actorID string) error {
+	if s.identities == nil {
+		return ErrIdentitiesDisabled
+	}
+	identities, err := s.identities.ListByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list identities: %w", err)
+	}
+	var target *LinkedIdentity
+	for _, i := range identities {
+		if i.ID == identityID {
+			target = i
+		}
+	}
+	if target == nil {
+		return ErrIdentityNotFound
+	}
+	if len(identities) == 1 {
+		return ErrLastIdentity
+	}
+	if err := s.identities.Delete(ctx, userID, identityID); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeIdentityUnlinked,
+		ActorID:  actorID,
+		Resource: audit.ResourceIdentity,
+		TargetID: userID,
+		Metadata: map[string]any{"identity_id": identityID, "kind": target.Kind, "issuer": target.Issuer},
+	})
+	return nil
+}
+
+// ResolveIdentity returns the user a login method belongs to and records its use.
+//
+// Purpose: Login lookup for federated, passkey, and phone sign-in.
+// Domain: Identity
+// Security: Only call after the method itself was verified; this is a lookup, not authentication.
+// Audited: No
+// Errors: ErrIdentitiesDisabled, ErrIdentityNotFound, ErrUserNotFound, System errors
+func (s *Service) ResolveIdentity(ctx context.Context, kind IdentityKind, issuer, subject string) (*User, error) {
+	if s.identities == nil {
+		return nil, ErrIdentitiesDisabled
+	}
+	identity, err := s.identities.Get(ctx, kind, issuer, subject)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.repo.GetByID(ctx, identity.UserID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if err := s.identities.Touch(ctx, kind, issuer, subject, s.clock.Now()); err != nil {
+		return nil, fmt.Errorf("failed to record identity use: %w", err)
+	}
+	return u, nil
+}
+
+// touchPassword records a successful password login on the password identity.
+func (s *Service) touchPassword(ctx context.Context, userID string) {
+	if s.identities != nil {
+		_ = s.identities.Touch(ctx, IdentityPassword, "", userID, s.clock.Now())
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/crypto"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Legacy password hash schemes accepted for verification only. Users carrying
+// one are rehashed with Argon2id on their next successful login.
+const (
+	SchemePBKDF2SHA1   = "pbkdf2-sha1"
+	SchemePBKDF2SHA256 = "pbkdf2-sha256"
+	SchemePBKDF2SHA512 = "pbkdf2-sha512"
+)
+
+// Bounds for legacy hashes, so an imported record cannot make a login
+// arbitrarily expensive.
+const (
+	maxBcryptCost       = 14
+	maxPBKDF2Iterations = 1_000_000
+)
+
+// ErrUnsupportedHash is returned for password hashes in an unknown format.
+var ErrUnsupportedHash = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "unsupported password hash format")
+
+// FormatPBKDF2Hash encodes a PBKDF2 hash as
+// $<scheme>$i=<iterations>$<salt>$<key>, with unpadded standard base64.
+func FormatPBKDF2Hash(scheme string, iterations int, salt, key []byte) string {
+	return fmt.Sprintf("$%s$i=%d$%s$%s",
+		scheme,
+		iterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+// isArgon2idHash reports whether encodedHash uses the native scheme.
+func isArgon2idHash(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$argon2id$")
+}
+
+// isBcryptHash reports whether encodedHash is a bcrypt hash.
+func isBcryptHash(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
+
+// verifyLegacy verifies password against a bcrypt or PBKDF2 hash.
+func verifyLegacy(password, encodedHash string) (bool, error) {
+	if isBcryptHash(encodedHash) {
+		cost, err := bcrypt.Cost([]byte(encodedHash))
+		if err != nil {
+			return false, fmt.Errorf("invalid bcrypt hash: %w", err)
+		}
+		if cost > maxBcryptCost {
+			return false, fmt.Errorf("invalid bcrypt hash: cost %d out of range", cost)
+		}
+		err = bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("invalid bcrypt hash: %w", err)
+		}
+		return true, nil
+	}
+
+	newHash, iterations, salt, expected, err := decodePBKDF2Hash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+	actual, err := pbkdf2.Key(newHash, password, salt, iterations, len(expected))
+	if err != nil {
+		return false, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return crypto.ConstantTimeEqual(actual, expected), nil
+}
+
+// checkLegacy validates the structure and bounds of a legacy hash without a password.
+func checkLegacy(encodedHash string) error {
+	if isBcryptHash(encodedHash) {
+		cost, err := bcrypt.Cost([]byte(encodedHash))
+		if err != nil || cost > maxBcryptCost {
+			return fmt.Errorf("%w: bcrypt cost", ErrUnsupportedHash)
+		}
+		return nil
+	}
+	_, _, _, _, err := decodePBKDF2Hash(encodedHash)
+	return err
+}
+
+func decodePBKDF2Hash(encodedHash string) (func() hash.Hash, int, []byte, []byte, error) {
+	// "$pbkdf2-sha256$i=27500$salt$key" splits into ["", scheme, params, salt, key].
+	sections := strings.Split(encodedHash, "$")
+	if len(sections) != 5 || sections[0] != "" {
+		return nil, 0, nil, nil, ErrUnsupportedHash
+	}
+
+	var newHash func() hash.Hash
+	switch sections[1] {
+	case SchemePBKDF2SHA1:
+		newHash = sha1.New
+	case SchemePBKDF2SHA256:
+		newHash = sha256.New
+	case SchemePBKDF2SHA512:
+		newHash = sha512.New
+	default:
+		return nil, 0, nil, nil, ErrUnsupportedHash
+	}
+
+	var iterations int
+	if _, err := fmt.Sscanf(sections[2], "i=%d", &iterations); err != nil {
+		return nil, 0, nil, nil, fmt.Errorf("%w: invalid parameters", ErrUnsupportedHash)
+	}
+	if iterations <= 0 || iterations > maxPBKDF2Iterations {
+		return nil, 0, nil, nil, fmt.Errorf("%w: iterations out of range", ErrUnsupportedHash)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(sections[3])
+	if err != nil || len(salt) == 0 {
+		return nil, 0, nil, nil, fmt.Errorf("%w: invalid salt", ErrUnsupportedHash)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(sections[4])
+	if err != nil || len(key) < minHashKeyLength || len(key) > maxHashKeyLength {
+		return nil, 0, nil, nil, fmt.Errorf("%w: invalid key", ErrUnsupportedHash)
+	}
+	return newHash, iterations, salt, key, nil
+}
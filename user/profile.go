@@ -0,0 +1,253 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+// ProfileField names an editable profile attribute. Values match the OIDC
+// standard claim names.
+type ProfileField string
+
+// Profile fields
+const (
+	FieldGivenName  ProfileField = "given_name"
+	FieldFamilyName ProfileField = "family_name"
+	FieldFullName   ProfileField = "name"
+	FieldNickname   ProfileField = "nickname"
+	FieldPicture    ProfileField = "picture"
+	FieldLocale     ProfileField = "locale"
+	FieldTimezone   ProfileField = "zoneinfo"
+	// FieldPhoneNumber changes clear the number's verified flag.
+	FieldPhoneNumber ProfileField = "phone_number"
+	// FieldEmail is never editable through a profile patch; it changes only
+	// through the verified email change flow.
+	FieldEmail ProfileField = "email"
+)
+
+// FieldAccess says who may edit a profile field.
+type FieldAccess int
+
+// Field access levels
+const (
+	AccessNone FieldAccess = iota
+	AccessSelf
+	AccessAdmin
+	AccessSelfOrAdmin
+)
+
+// allows reports whether an editor may change a field with access a.
+func (a FieldAccess) allows(self bool) bool {
+	if self {
+		return a == AccessSelf || a == AccessSelfOrAdmin
+	}
+	return a == AccessAdmin || a == AccessSelfOrAdmin
+}
+
+// ProfilePolicy maps each profile field to who may edit it.
+//
+// Purpose: Deployment-wide field-level permissions for profile patches.
+// Domain: Identity
+// Invariants: Fields missing from the policy are not editable. FieldEmail is
+// never editable, whatever the policy says.
+type ProfilePolicy map[ProfileField]FieldAccess
+
+// DefaultProfilePolicy lets users and administrators edit every profile field.
+// Deployments whose names come from a directory typically lower the name
+// fields to AccessAdmin.
+func DefaultProfilePolicy() ProfilePolicy {
+	return ProfilePolicy{
+		FieldGivenName:   AccessSelfOrAdmin,
+		FieldFamilyName:  AccessSelfOrAdmin,
+		FieldFullName:    AccessSelfOrAdmin,
+		FieldNickname:    AccessSelfOrAdmin,
+		FieldPicture:     AccessSelfOrAdmin,
+		FieldLocale:      AccessSelfOrAdmin,
+		FieldTimezone:    AccessSelfOrAdmin,
+		FieldPhoneNumber: AccessSelfOrAdmin,
+	}
+}
+
+// WithProfilePolicy replaces DefaultProfilePolicy for PatchProfile.
+func WithProfilePolicy(p ProfilePolicy) Option {
+	return func(s *Service) { s.profilePolicy = p }
+}
+
+// ProfilePatch holds the fields to change. An empty value clears the field.
+type ProfilePatch map[ProfileField]string
+
+// maxProfileFieldLength bounds every free-text profile field
+const maxProfileFieldLength = 255
+
+// maxPictureLength bounds the picture URL
+const maxPictureLength = 2048
+
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})*$`)
+
+// phonePattern is an E.164 telephone number
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// validateProfileField checks the format of one patched value.
+func validateProfileField(f ProfileField, v string) error {
+	if v == "" {
+		return nil
+	}
+	switch f {
+	case FieldPicture:
+		u, err := url.Parse(v)
+		if len(v) > maxPictureLength || err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+			return fmt.Errorf("%w: picture must be an https URL", ErrInvalidProfile)
+		}
+	case FieldLocale:
+		if !localePattern.MatchString(v) {
+			return fmt.Errorf("%w: locale must be a BCP 47 language tag", ErrInvalidProfile)
+		}
+	case FieldTimezone:
+		if _, err := time.LoadLocation(v); err != nil || v == "Local" {
+			return fmt.Errorf("%w: zoneinfo must be an IANA time zone", ErrInvalidProfile)
+		}
+	case FieldPhoneNumber:
+		if !phonePattern.MatchString(v) {
+			return fmt.Errorf("%w: phone_number must be in E.164 format", ErrInvalidProfile)
+		}
+	default:
+		if !utf8.ValidString(v) || utf8.RuneCountInString(v) > maxProfileFieldLength {
+			return fmt.Errorf("%w: %s is too long", ErrInvalidProfile, f)
+		}
+	}
+	return nil
+}
+
+// PatchProfile changes selected profile fields, leaving the others untouched.
+// actorID equal to userID is a self-service edit; any other actor is an
+// administrator whose authority over the user the caller has already checked.
+//
+// Purpose: Partial profile updates with field-level permissions and validation.
+// Domain: Identity
+// Security: The whole patch is rejected if any field is not editable by the
+// actor or fails validation. Email is never patched. Picture accepts only https
+// URLs, so a user cannot store script-capable data URIs.
+// Audited: Yes (UserUpdated, field names only)
+// Errors: ErrFieldNotEditable, ErrInvalidProfile, ErrUserNotFound, System errors
+func (s *Service) PatchProfile(ctx context.Context, userID, actorID string, patch ProfilePatch) (*User, error) {
+	self := actorID == userID
+	fields := make([]string, 0, len(patch))
+	for f, v := range patch {
+		if f == FieldEmail || !s.profilePolicy[f].allows(self) {
+			return nil, fmt.Errorf("%w: %s", ErrFieldNotEditable, f)
+		}
+		if err := validateProfileField(f, v); err != nil {
+			return nil, err
+		}
+		fields = append(fields, string(f))
+	}
+	slices.Sort(fields)
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if len(patch) == 0 {
+		return u, nil
+	}
+	for f, v := range patch {
+		switch f {
+		case FieldGivenName:
+			u.Profile.GivenName = v
+		case FieldFamilyName:
+			u.Profile.FamilyName = v
+		case FieldFullName:
+			u.Profile.FullName = v
+		case FieldNickname:
+			u.Profile.Nickname = v
+		case FieldPicture:
+			if v == "" && u.EmailPlain != nil && s.avatars == nil {
+				v = GenerateRandomAvatar(*u.EmailPlain)
+			}
+			u.Profile.Picture = v
+		case FieldLocale:
+			u.Profile.Locale = v
+		case FieldTimezone:
+			u.Profile.Timezone = v
+		case FieldPhoneNumber:
+			if v != u.Profile.PhoneNumber {
+				u.Profile.PhoneNumber, u.Profile.PhoneNumberVerified = v, false
+			}
+		}
+	}
+	if err := s.repo.Update(ctx, u); err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeUserUpdated,
+		ActorID:  actorID,
+		Resource: audit.ResourceUser,
+		TargetID: userID,
+		Metadata: map[string]any{"fields": fields},
+	})
+	events.Emit(ctx, s.events, events.UserUpdated{Meta: events.NewMeta("", actorID), UserID: userID})
+	return u, nil
+}
+
+// UploadAvatar stores an uploaded image as the user's picture.
+//
+// Purpose: Avatar upload through the configured AvatarStore.
+// Domain: Identity
+// Security: Subject to the FieldPicture policy like PatchProfile; the store
+// validates the image type and size.
+// Audited: Yes (UserUpdated)
+// Errors: ErrUploadsDisabled, ErrFieldNotEditable, ErrUserNotFound, blob validation errors, System errors
+func (s *Service) UploadAvatar(ctx context.Context, userID, actorID, contentType string, data []byte) (*User, error) {
+	if s.avatars == nil {
+		return nil, ErrUploadsDisabled
+	}
+	if !s.profilePolicy[FieldPicture].allows(actorID == userID) {
+		return nil, fmt.Errorf("%w: %s", ErrFieldNotEditable, FieldPicture)
+	}
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	picture, err := s.avatars.PutUserAvatar(ctx, userID, contentType, data)
+	if err != nil {
+		return nil, err
+	}
+	u.Profile.Picture = picture
+	if err := s.repo.Update(ctx, u); err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeUserUpdated,
+		ActorID:  actorID,
+		Resource: audit.ResourceUser,
+		TargetID: userID,
+		Metadata: map[string]any{"fields": []string{string(FieldPicture)}},
+	})
+	events.Emit(ctx, s.events, events.UserUpdated{Meta: events.NewMeta("", actorID), UserID: userID})
+	return u, nil
+}
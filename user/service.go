@@ -18,16 +18,34 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/opentrusty/opentrusty-core/apperror"
 	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/clock"
 	"github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/feature"
 	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/maintenance"
+	"github.com/opentrusty/opentrusty-core/metrics"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"github.com/opentrusty/opentrusty-core/tracing"
 	"golang.org/x/crypto/argon2"
 )
 
+// Bounds for parameters decoded from a stored hash.
+const (
+	maxHashMemory     = 1 << 20 // KiB (1 GiB)
+	maxHashIterations = 64
+	minHashKeyLength  = 16
+	maxHashKeyLength  = 128
+)
+
 // PasswordHasher handles password hashing using Argon2id
 type PasswordHasher struct {
 	memory      uint32
@@ -80,8 +98,13 @@ func (h *PasswordHasher) Hash(password string) (string, error) {
 	return encoded, nil
 }
 
-// Verify verifies a password against a hash
+// Verify verifies a password against a hash. Imported bcrypt and PBKDF2
+// hashes are verified too; see NeedsRehash.
 func (h *PasswordHasher) Verify(password, encodedHash string) (bool, error) {
+	if !isArgon2idHash(encodedHash) {
+		return verifyLegacy(password, encodedHash)
+	}
+
 	// Parse the encoded hash format: $argon2id$v=19$m=65536,t=3,p=4$salt$hash
 	// Split by $ - format produces: ["argon2id", "v=19", "m=65536,t=3,p=4", "salt", "hash"]
 	parts := []byte(encodedHash)
@@ -117,6 +140,12 @@ func (h *PasswordHasher) Verify(password, encodedHash string) (bool, error) {
 		return false, fmt.Errorf("invalid parameters: %w", err)
 	}
 
+	// Reject parameters that would panic or allocate unbounded memory in argon2.IDKey
+	if version != argon2.Version || memory == 0 || memory > maxHashMemory ||
+		iterations == 0 || iterations > maxHashIterations || parallelism == 0 {
+		return false, fmt.Errorf("invalid parameters: out of range")
+	}
+
 	saltB64 := sections[3]
 	hashB64 := sections[4]
 
@@ -130,6 +159,9 @@ func (h *PasswordHasher) Verify(password, encodedHash string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to decode hash: %w", err)
 	}
+	if len(expectedHash) < minHashKeyLength || len(expectedHash) > maxHashKeyLength {
+		return false, fmt.Errorf("invalid hash length: %d", len(expectedHash))
+	}
 
 	// Hash the password with the same parameters
 	actualHash := argon2.IDKey(
@@ -142,26 +174,119 @@ func (h *PasswordHasher) Verify(password, encodedHash string) (bool, error) {
 	)
 
 	// Compare hashes using constant-time comparison
-	if len(actualHash) != len(expectedHash) {
-		return false, nil
-	}
+	return crypto.ConstantTimeEqual(actualHash, expectedHash), nil
+}
 
-	var diff byte
-	for i := range actualHash {
-		diff |= actualHash[i] ^ expectedHash[i]
-	}
+// NeedsRehash reports whether encodedHash uses a legacy scheme and should be
+// replaced with an Argon2id hash once the password is known.
+func (h *PasswordHasher) NeedsRehash(encodedHash string) bool {
+	return !isArgon2idHash(encodedHash)
+}
 
-	return diff == 0, nil
+// CheckHash reports whether encodedHash is in a format Verify accepts,
+// without verifying any password.
+func (h *PasswordHasher) CheckHash(encodedHash string) error {
+	if isArgon2idHash(encodedHash) {
+		return nil
+	}
+	return checkLegacy(encodedHash)
 }
 
 // Service provides identity-related business logic
 type Service struct {
-	repo               UserRepository
-	hasher             *PasswordHasher
-	auditLogger        audit.Logger
-	lockoutMaxAttempts int
-	lockoutDuration    time.Duration
-	hmacKey            string
+	repo                   UserRepository
+	hasher                 *PasswordHasher
+	auditLogger            audit.Logger
+	lockoutMaxAttempts     int
+	lockoutDuration        time.Duration
+	hmacKey                string
+	emailKeys              []crypto.HMACKey
+	metrics                *metrics.Metrics
+	tracer                 tracing.Tracer
+	events                 events.Publisher
+	features               feature.Checker
+	profilePolicy          ProfilePolicy
+	avatars                AvatarStore
+	revoker                CredentialRevoker
+	revokeOnPasswordChange bool
+	identities             IdentityRepository
+	maintenance            maintenance.Gate
+	clock                  clock.Clock
+	ids                    id.Generator
+
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithMetrics records authentication outcomes on m.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *Service) { s.metrics = m }
+}
+
+// WithTracer emits spans for authentication on t.
+func WithTracer(t tracing.Tracer) Option {
+	return func(s *Service) { s.tracer = t }
+}
+
+// WithEvents publishes user lifecycle events on p.
+func WithEvents(p events.Publisher) Option {
+	return func(s *Service) { s.events = p }
+}
+
+// WithFeatures consults c for deployment capability flags such as
+// feature.LegacyHashLogin. Without it the built-in defaults apply.
+func WithFeatures(c feature.Checker) Option {
+	return func(s *Service) { s.features = c }
+}
+
+// AvatarStore stores uploaded avatars; blob.Images implements it.
+type AvatarStore interface {
+	PutUserAvatar(ctx context.Context, userID, contentType string, data []byte) (string, error)
+}
+
+// WithAvatars stores uploaded avatars in a. Without it UploadAvatar fails and
+// identities get a generated inline avatar instead.
+func WithAvatars(a AvatarStore) Option {
+	return func(s *Service) { s.avatars = a }
+}
+
+// CredentialRevoker ends the sessions and tokens a user obtained with their
+// old credentials, in every tenant.
+type CredentialRevoker interface {
+	RevokeUserCredentials(ctx context.Context, userID string) error
+	// RevokeOtherCredentials is RevokeUserCredentials sparing the session keepSessionID.
+	RevokeOtherCredentials(ctx context.Context, userID, keepSessionID string) error
+}
+
+// WithCredentialRevoker revokes sessions and tokens through r when credentials
+// are invalidated or the password changes. Without it RequireCredentialReset only
+// invalidates the password and password changes leave sessions and tokens valid.
+func WithCredentialRevoker(r CredentialRevoker) Option {
+	return func(s *Service) { s.revoker = r }
+}
+
+// WithPasswordChangeRevocation sets whether ChangePassword and SetPassword revoke the
+// user's other sessions and their tokens. Enabled by default.
+func WithPasswordChangeRevocation(enabled bool) Option {
+	return func(s *Service) { s.revokeOnPasswordChange = enabled }
+}
+
+// WithMaintenance refuses logins while g's platform mode does not accept them.
+func WithMaintenance(g maintenance.Gate) Option {
+	return func(s *Service) { s.maintenance = g }
+}
+
+// WithClock reads the current time from c for lockouts and identity timestamps.
+func WithClock(c clock.Clock) Option {
+	return func(s *Service) { s.clock = c }
+}
+
+// WithIDGenerator mints user and linked identity IDs with g. Defaults to id.UUIDv7.
+func WithIDGenerator(g id.Generator) Option {
+	return func(s *Service) { s.ids = g }
 }
 
 // NewService creates a new identity service
@@ -172,35 +297,167 @@ func NewService(
 	lockoutMaxAttempts int,
 	lockoutDuration time.Duration,
 	hmacKey string,
+	opts ...Option,
 ) *Service {
-	return &Service{
-		repo:               repo,
-		hasher:             hasher,
-		auditLogger:        auditLogger,
-		lockoutMaxAttempts: lockoutMaxAttempts,
-		lockoutDuration:    lockoutDuration,
-		hmacKey:            hmacKey,
+	s := &Service{
+		repo:                   repo,
+		hasher:                 hasher,
+		auditLogger:            auditLogger,
+		lockoutMaxAttempts:     lockoutMaxAttempts,
+		lockoutDuration:        lockoutDuration,
+		hmacKey:                hmacKey,
+		emailKeys:              emailHashKeys(hmacKey),
+		features:               feature.Defaults,
+		profilePolicy:          DefaultProfilePolicy(),
+		clock:                  clock.System(),
+		ids:                    id.UUIDv7(),
+		revokeOnPasswordChange: true,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// emailHashKeys returns the keys used for email hashing, current key first.
+// The purpose-derived key is used for new identities; the legacy master key is
+// kept for lookups of identities hashed before derivation was introduced.
+func emailHashKeys(hmacKey string) []crypto.HMACKey {
+	legacy := crypto.LegacyHMACKey([]byte(hmacKey))
+	derived, err := crypto.DeriveHMACKey([]byte(hmacKey), crypto.PurposeEmailHash, "")
+	if err != nil {
+		return []crypto.HMACKey{legacy}
+	}
+	return []crypto.HMACKey{derived, legacy}
+}
+
+// lookupByEmail resolves a user by trying each email hash key in order.
+// It returns the hash under the current key for diagnostics on failure. Every key
+// is queried even after a match, so the lookup makes the same repository calls
+// whether or not the account exists.
+func (s *Service) lookupByEmail(ctx context.Context, emailPlain string) (*User, string, error) {
+	var found *User
+	var foundHash, currentHash string
+	var lastErr error
+	for i, key := range s.emailKeys {
+		hash := crypto.ComputeEmailHashWithKey(key, emailPlain)
+		if i == 0 {
+			currentHash = hash
+		}
+		u, err := s.repo.GetByHash(ctx, hash)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if found == nil {
+			found, foundHash = u, hash
+		}
+	}
+	if found != nil {
+		return found, foundHash, nil
+	}
+	return nil, currentHash, lastErr
+}
+
+// verifyDummy verifies password against the hash of a random secret, so login
+// failures decided before the real verification cost as much as a wrong password
+// and response times do not reveal whether an account exists.
+func (s *Service) verifyDummy(password string) {
+	s.dummyOnce.Do(func() {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return
+		}
+		s.dummyHash, _ = s.hasher.Hash(base64.RawURLEncoding.EncodeToString(secret))
+	})
+	if s.dummyHash != "" {
+		_, _ = s.hasher.Verify(password, s.dummyHash)
 	}
 }
 
 // ProvisionIdentity creates a new user identity without credentials
 func (s *Service) ProvisionIdentity(ctx context.Context, emailPlain string, profile Profile) (*User, error) {
-	// Validate email
-	if !isValidEmail(emailPlain) {
-		return nil, ErrInvalidEmail
+	// Check if user already exists under any known key
+	existing, _, err := s.lookupByEmail(ctx, emailPlain)
+	if err == nil && existing != nil {
+		return nil, ErrUserAlreadyExists
 	}
 
-	// Compute Identity Key
-	emailHash := crypto.ComputeEmailHash(s.hmacKey, emailPlain)
+	user, err := s.NewIdentity(emailPlain, profile)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check if user already exists
-	existing, err := s.repo.GetByHash(ctx, emailHash)
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	events.Emit(ctx, s.events, events.UserCreated{Meta: events.NewMeta("", ""), UserID: user.ID})
+	return user, nil
+}
+
+// ImportIdentity creates a user migrated from another identity provider,
+// keeping the password hash exported from it.
+//
+// Purpose: Lets users keep their passwords across a migration.
+// Domain: Identity
+// Security: passwordHash must be Argon2id, bcrypt, or PBKDF2. Legacy hashes are
+// rehashed with Argon2id on the user's next successful login.
+// Audited: Yes (UserCreated)
+// Errors: ErrUserAlreadyExists, ErrInvalidEmail, ErrUnsupportedHash, System errors
+func (s *Service) ImportIdentity(ctx context.Context, emailPlain string, profile Profile, emailVerified bool, passwordHash, actorID string) (*User, error) {
+	if passwordHash != "" {
+		if err := s.CheckPasswordHash(passwordHash); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, _, err := s.lookupByEmail(ctx, emailPlain)
 	if err == nil && existing != nil {
 		return nil, ErrUserAlreadyExists
 	}
 
+	user, err := s.NewIdentity(emailPlain, profile)
+	if err != nil {
+		return nil, err
+	}
+	user.EmailVerified = emailVerified
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create identity: %w", err)
+	}
+	if passwordHash != "" {
+		if err := s.repo.AddCredentials(ctx, &Credentials{UserID: user.ID, PasswordHash: passwordHash}); err != nil {
+			return nil, fmt.Errorf("failed to add credentials: %w", err)
+		}
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeUserCreated,
+		ActorID:  actorID,
+		Resource: audit.ResourceUser,
+		TargetID: user.ID,
+		Metadata: map[string]any{audit.AttrReason: "import"},
+	})
+	events.Emit(ctx, s.events, events.UserCreated{Meta: events.NewMeta("", actorID), UserID: user.ID})
+	return user, nil
+}
+
+// NewIdentity builds a new, unpersisted user with its email hash and profile
+// defaults filled in. Callers that persist users in their own transaction
+// (such as bootstrap) use it instead of ProvisionIdentity.
+func (s *Service) NewIdentity(emailPlain string, profile Profile) (*User, error) {
+	// Validate email
+	if !isValidEmail(emailPlain) {
+		return nil, ErrInvalidEmail
+	}
+
+	// Compute Identity Key under the current key
+	emailKey := s.emailKeys[0]
+	emailHash := crypto.ComputeEmailHashWithKey(emailKey, emailPlain)
+
 	// Create user
-	if profile.Picture == "" {
+	if profile.Picture == "" && s.avatars == nil {
 		profile.Picture = GenerateRandomAvatar(emailPlain)
 	}
 	if profile.Nickname == "" {
@@ -211,19 +468,33 @@ func (s *Service) ProvisionIdentity(ctx context.Context, emailPlain string, prof
 		}
 	}
 
-	user := &User{
-		ID:            id.NewUUIDv7(),
-		EmailHash:     emailHash,
-		EmailPlain:    &emailPlain,
-		EmailVerified: false,
-		Profile:       profile,
-	}
+	return &User{
+		ID:             s.ids.NewID(),
+		EmailHash:      emailHash,
+		EmailHashKeyID: emailKey.ID,
+		EmailPlain:     &emailPlain,
+		EmailVerified:  false,
+		Profile:        profile,
+	}, nil
+}
 
-	if err := s.repo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create identity: %w", err)
+// HashPassword validates password strength and returns its Argon2id hash
+// without storing it.
+func (s *Service) HashPassword(password string) (string, error) {
+	if !isStrongPassword(password) {
+		return "", ErrWeakPassword
 	}
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return passwordHash, nil
+}
 
-	return user, nil
+// CheckPasswordHash reports whether passwordHash is in a format this service
+// can verify, without verifying any password.
+func (s *Service) CheckPasswordHash(passwordHash string) error {
+	return s.hasher.CheckHash(passwordHash)
 }
 
 // AddPassword adds a password credential to an existing user
@@ -251,7 +522,15 @@ func (s *Service) AddPassword(ctx context.Context, userID, password string) erro
 	return nil
 }
 
-// SetPassword sets or updates a user's password without requiring the old password (administrative action)
+// SetPassword sets or updates a user's password without requiring the old password.
+//
+// Purpose: Administrative password assignment.
+// Domain: Identity
+// Security: Replacing an existing password revokes the user's sessions and tokens,
+// sparing only the session in ctx (requestctx.SessionID), unless revocation on
+// password change is disabled.
+// Audited: Yes (PasswordChanged)
+// Errors: ErrWeakPassword, System errors
 func (s *Service) SetPassword(ctx context.Context, userID, password string) error {
 	// Validate password strength
 	if !isStrongPassword(password) {
@@ -273,7 +552,11 @@ func (s *Service) SetPassword(ctx context.Context, userID, password string) erro
 				UserID:       userID,
 				PasswordHash: passwordHash,
 			}
-			return s.repo.AddCredentials(ctx, credentials)
+			if err := s.repo.AddCredentials(ctx, credentials); err != nil {
+				return err
+			}
+			events.Emit(ctx, s.events, events.PasswordChanged{Meta: events.NewMeta("", ""), UserID: userID})
+			return nil
 		}
 		return fmt.Errorf("failed to check existing credentials: %w", err)
 	}
@@ -283,18 +566,58 @@ func (s *Service) SetPassword(ctx context.Context, userID, password string) erro
 		return fmt.Errorf("failed to update credentials: %w", err)
 	}
 
+	if err := s.passwordChanged(ctx, userID, ""); err != nil {
+		return err
+	}
+	events.Emit(ctx, s.events, events.PasswordChanged{Meta: events.NewMeta("", ""), UserID: userID})
 	return nil
 }
 
 // Authenticate authenticates a user with email and password.
-// It uses the global HMAC key to derive the user's identity hash.
+// It derives the user's identity hash under each known email hash key.
 func (s *Service) Authenticate(ctx context.Context, emailPlain, password string) (*User, error) {
-	// 1. Compute Hash from EmailPlain
-	emailHash := crypto.ComputeEmailHash(s.hmacKey, emailPlain)
+	return s.AuthenticateInTenant(ctx, "", emailPlain, password)
+}
+
+// AuthenticateInTenant authenticates a user signing in to tenantID.
+//
+// Purpose: Authenticate with the tenant's login feedback policy applied.
+// Domain: Identity
+// Security: With feature.LoginAttemptFeedback on for tenantID, wrong passwords return
+// an *AttemptsError and locked accounts a *LockoutError; both disclose that the
+// account exists, so unknown accounts keep returning the bare ErrInvalidCredentials.
+// Every failure costs one password verification, against a dummy hash when the
+// real one is not checked; transports pass errors through PublicError(FlowLogin).
+// In read-only and maintenance mode logins are refused before credentials are checked.
+// Audited: Yes (LoginSuccess, LoginFailed, UserLocked)
+// Errors: ErrInvalidCredentials, ErrAccountLocked, ErrPasswordResetRequired,
+// maintenance.ErrReadOnly, maintenance.ErrMaintenance
+func (s *Service) AuthenticateInTenant(ctx context.Context, tenantID, emailPlain, password string) (*User, error) {
+	ctx, span := tracing.Start(ctx, s.tracer, "user.Authenticate")
+	defer span.End()
+
+	if s.maintenance != nil {
+		if err := s.maintenance.Allow(ctx, maintenance.OpLogin); err != nil {
+			return nil, err
+		}
+	}
 
-	// 2. Lookup by Hash
-	user, err := s.repo.GetByHash(ctx, emailHash)
+	u, err := s.authenticate(ctx, tenantID, emailPlain, password)
 	if err != nil {
+		span.RecordError(err)
+		events.Emit(ctx, s.events, events.LoginFailed{Meta: events.NewMeta(tenantID, ""), Code: string(apperror.CodeOf(err))})
+		return nil, err
+	}
+	span.SetAttributes(tracing.String(tracing.AttrUserID, u.ID))
+	return u, nil
+}
+
+// authenticate implements Authenticate.
+func (s *Service) authenticate(ctx context.Context, tenantID, emailPlain, password string) (*User, error) {
+	// 1. Lookup by Hash computed from EmailPlain
+	user, emailHash, err := s.lookupByEmail(ctx, emailPlain)
+	if err != nil {
+		s.verifyDummy(password)
 		// Audit failed attempt (unknown user)
 		// SECURITY: We log the HASH, never the plaintext email
 		s.auditLogger.Log(ctx, audit.Event{
@@ -305,23 +628,57 @@ func (s *Service) Authenticate(ctx context.Context, emailPlain, password string)
 				"target_hash":    emailHash, // Safe to log internal hash for debugging
 			},
 		})
+		s.metrics.LoginAttempt(metrics.LoginFailed)
 		return nil, ErrInvalidCredentials
 	}
 
 	// Check if locked out
-	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+	if user.LockedUntil != nil && user.LockedUntil.After(s.clock.Now()) {
+		s.verifyDummy(password)
 		s.auditLogger.Log(ctx, audit.Event{
 			Type:     audit.TypeLoginFailed,
 			ActorID:  user.ID,
 			Resource: "login",
 			Metadata: map[string]any{audit.AttrReason: "locked_out"},
 		})
+		s.metrics.LoginAttempt(metrics.LoginLocked)
+		if s.features.Enabled(ctx, tenantID, feature.LoginAttemptFeedback) {
+			return nil, newLockoutError(*user.LockedUntil, s.clock.Now())
+		}
 		return nil, ErrAccountLocked
 	}
 
 	// Get credentials
 	credentials, err := s.repo.GetCredentials(ctx, user.ID)
 	if err != nil {
+		s.verifyDummy(password)
+		s.metrics.LoginAttempt(metrics.LoginFailed)
+		return nil, ErrInvalidCredentials
+	}
+
+	// An invalidated password cannot be verified; only a reset gets the user back in
+	if credentials.ResetRequired {
+		s.verifyDummy(password)
+		s.auditLogger.Log(ctx, audit.Event{
+			Type:     audit.TypeLoginFailed,
+			ActorID:  user.ID,
+			Resource: "login",
+			Metadata: map[string]any{audit.AttrReason: "credential_reset_required"},
+		})
+		s.metrics.LoginAttempt(metrics.LoginFailed)
+		return nil, ErrPasswordResetRequired
+	}
+
+	// Imported legacy hashes are only usable while the deployment allows it
+	if s.hasher.NeedsRehash(credentials.PasswordHash) && !s.features.Enabled(ctx, "", feature.LegacyHashLogin) {
+		s.verifyDummy(password)
+		s.auditLogger.Log(ctx, audit.Event{
+			Type:     audit.TypeLoginFailed,
+			ActorID:  user.ID,
+			Resource: "login",
+			Metadata: map[string]any{audit.AttrReason: "legacy_hash_disabled"},
+		})
+		s.metrics.LoginAttempt(metrics.LoginFailed)
 		return nil, ErrInvalidCredentials
 	}
 
@@ -333,7 +690,7 @@ func (s *Service) Authenticate(ctx context.Context, emailPlain, password string)
 		var newLockedUntil *time.Time
 
 		if newAttempts >= s.lockoutMaxAttempts {
-			until := time.Now().Add(s.lockoutDuration)
+			until := s.clock.Now().Add(s.lockoutDuration)
 			newLockedUntil = &until
 			// Audit lockout
 			s.auditLogger.Log(ctx, audit.Event{
@@ -342,6 +699,7 @@ func (s *Service) Authenticate(ctx context.Context, emailPlain, password string)
 				Resource: "login",
 				Metadata: map[string]any{audit.AttrAttempts: newAttempts},
 			})
+			events.Emit(ctx, s.events, events.UserLocked{Meta: events.NewMeta("", ""), UserID: user.ID, LockedUntil: until})
 		}
 
 		// Update lockout status
@@ -357,7 +715,14 @@ func (s *Service) Authenticate(ctx context.Context, emailPlain, password string)
 				audit.AttrAttempts: newAttempts,
 			},
 		})
+		s.metrics.LoginAttempt(metrics.LoginFailed)
 
+		if s.features.Enabled(ctx, tenantID, feature.LoginAttemptFeedback) {
+			if newLockedUntil != nil {
+				return nil, newLockoutError(*newLockedUntil, s.clock.Now())
+			}
+			return nil, &AttemptsError{Remaining: s.lockoutMaxAttempts - newAttempts}
+		}
 		return nil, ErrInvalidCredentials
 	}
 
@@ -366,6 +731,15 @@ func (s *Service) Authenticate(ctx context.Context, emailPlain, password string)
 		_ = s.repo.UpdateLockout(ctx, user.ID, 0, nil)
 	}
 
+	// Upgrade imported legacy hashes now that the password is known
+	if s.hasher.NeedsRehash(credentials.PasswordHash) {
+		if upgraded, err := s.hasher.Hash(password); err == nil {
+			_ = s.repo.RehashPassword(ctx, user.ID, upgraded)
+		}
+	}
+
+	s.touchPassword(ctx, user.ID)
+
 	// Audit success
 	s.auditLogger.Log(ctx, audit.Event{
 		Type:     audit.TypeLoginSuccess,
@@ -374,15 +748,101 @@ func (s *Service) Authenticate(ctx context.Context, emailPlain, password string)
 		TargetID: user.ID,
 		// TargetName deliberately omitted if PII is sensitive, or use ID
 	})
+	s.metrics.LoginAttempt(metrics.LoginSuccess)
 
 	return user, nil
 }
 
+// RequireCredentialReset invalidates a user's password without knowing it,
+// revokes their sessions and tokens, and makes the next login fail with
+// ErrPasswordResetRequired until the password is reset (SetPassword).
+//
+// Purpose: Administrative response to suspected credential compromise.
+// Domain: Identity
+// Security: The stored hash is replaced by the hash of a random secret nobody
+// holds, so neither the old password nor ChangePassword works. Authenticate
+// reports ErrPasswordResetRequired before verifying the password; transports must
+// answer it like an unknown account (PublicError) and only start the out-of-band reset, never
+// an in-band password change. Users without a password only lose their sessions.
+// Audited: Yes (CredentialResetRequired)
+// Errors: ErrUserNotFound, System errors
+func (s *Service) RequireCredentialReset(ctx context.Context, userID, actorID string) error {
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return ErrUserNotFound
+	}
+
+	hasPassword := true
+	if _, err := s.repo.GetCredentials(ctx, userID); err != nil {
+		if !errors.Is(err, ErrUserNotFound) {
+			return fmt.Errorf("failed to get credentials: %w", err)
+		}
+		hasPassword = false
+	}
+	if hasPassword {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate secret: %w", err)
+		}
+		unusable, err := s.hasher.Hash(base64.RawURLEncoding.EncodeToString(secret))
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		if err := s.repo.InvalidatePassword(ctx, userID, unusable); err != nil {
+			return fmt.Errorf("failed to invalidate password: %w", err)
+		}
+	}
+
+	if s.revoker != nil {
+		if err := s.revoker.RevokeUserCredentials(ctx, userID); err != nil {
+			return fmt.Errorf("failed to revoke sessions and tokens: %w", err)
+		}
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeCredentialResetRequired,
+		ActorID:  actorID,
+		Resource: audit.ResourceUserCredentials,
+		TargetID: userID,
+		Metadata: map[string]any{
+			"password_invalidated": hasPassword,
+			"sessions_revoked":     s.revoker != nil,
+		},
+	})
+	return nil
+}
+
+// CheckPasswordExpiry reports whether a user must change their password
+// before a session is issued. A maxAge of zero or less disables expiry, and
+// users without a password (federated or passwordless) are exempt.
+//
+// Purpose: Enforces a tenant's password max-age after a successful login.
+// Domain: Identity
+// Security: Call after Authenticate and before session issuance; on
+// ErrPasswordExpired the transport must require a password change (see
+// flow.Service.RequirePasswordChange) instead of issuing a session.
+// Audited: No
+// Errors: ErrPasswordExpired, System errors
+func (s *Service) CheckPasswordExpiry(ctx context.Context, userID string, maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	credentials, err := s.repo.GetCredentials(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if time.Since(credentials.PasswordChangedAt) >= maxAge {
+		return ErrPasswordExpired
+	}
+	return nil
+}
+
 // GetByEmail retrieves a user by email globally (convenience wrapper around Hash lookup)
 func (s *Service) GetByEmail(ctx context.Context, emailPlain string) (*User, error) {
-	// Compute Hash
-	hash := crypto.ComputeEmailHash(s.hmacKey, emailPlain)
-	return s.repo.GetByHash(ctx, hash)
+	user, _, err := s.lookupByEmail(ctx, emailPlain)
+	return user, err
 }
 
 // GetUser retrieves a user by ID
@@ -394,7 +854,8 @@ func (s *Service) GetUser(ctx context.Context, userID string) (*User, error) {
 	return user, nil
 }
 
-// UpdateProfile updates user profile information
+// UpdateProfile replaces user profile information. Self-service and
+// field-restricted edits use PatchProfile instead.
 func (s *Service) UpdateProfile(ctx context.Context, userID string, profile Profile) error {
 	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
@@ -402,10 +863,104 @@ func (s *Service) UpdateProfile(ctx context.Context, userID string, profile Prof
 	}
 
 	user.Profile = profile
-	return s.repo.Update(ctx, user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	events.Emit(ctx, s.events, events.UserUpdated{Meta: events.NewMeta("", userID), UserID: userID})
+	return nil
 }
 
-// ChangePassword changes user password
+// ListLockedAccounts returns members of tenantID that are currently locked out.
+//
+// Purpose: Tenant admin visibility into lockouts.
+// Domain: Identity
+// Audited: No
+// Errors: System errors
+func (s *Service) ListLockedAccounts(ctx context.Context, tenantID string) ([]LockedAccount, error) {
+	now := s.clock.Now()
+	users, err := s.repo.ListLocked(ctx, tenantID, now)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]LockedAccount, 0, len(users))
+	for _, u := range users {
+		if u.LockedUntil == nil || !u.LockedUntil.After(now) {
+			continue
+		}
+		a := LockedAccount{
+			UserID:         u.ID,
+			Nickname:       u.Profile.Nickname,
+			FailedAttempts: u.FailedLoginAttempts,
+			LockedUntil:    *u.LockedUntil,
+			Remaining:      u.LockedUntil.Sub(now),
+		}
+		if u.EmailPlain != nil {
+			a.Email = *u.EmailPlain
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, nil
+}
+
+// Unlock lifts a lockout and resets the failed attempt counter. tenantID
+// records the tenant the administrator acted in, or "" for platform admins.
+//
+// Purpose: Administrative recovery for a locked-out user.
+// Domain: Identity
+// Audited: Yes (UserUnlocked)
+// Errors: ErrUserNotFound, System errors
+func (s *Service) Unlock(ctx context.Context, tenantID, userID, actorID string) error {
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.repo.UpdateLockout(ctx, userID, 0, nil); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeUserUnlocked,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceUser,
+		TargetID: userID,
+	})
+	events.Emit(ctx, s.events, events.UserUnlocked{Meta: events.NewMeta(tenantID, actorID), UserID: userID})
+	return nil
+}
+
+// DeleteUser removes a user identity.
+//
+// Purpose: Account removal; subscribers clean up dependent data such as avatars.
+// Domain: Identity
+// Audited: Yes (UserDeleted)
+// Errors: ErrUserNotFound, System errors
+func (s *Service) DeleteUser(ctx context.Context, userID, actorID string) error {
+	if _, err := s.repo.GetByID(ctx, userID); err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeUserDeleted,
+		ActorID:  actorID,
+		Resource: audit.ResourceUser,
+		TargetID: userID,
+	})
+	events.Emit(ctx, s.events, events.UserDeleted{Meta: events.NewMeta("", actorID), UserID: userID})
+	return nil
+}
+
+// ChangePassword changes a user's password after verifying the current one.
+//
+// Purpose: Self-service password change.
+// Domain: Identity
+// Security: Revokes the user's other sessions and their tokens, sparing the session in
+// ctx (requestctx.SessionID), unless revocation on password change is disabled.
+// Audited: Yes (PasswordChanged)
+// Errors: ErrUserNotFound, ErrInvalidCredentials, ErrWeakPassword, System errors
 func (s *Service) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) error {
 	// Get credentials
 	credentials, err := s.repo.GetCredentials(ctx, userID)
@@ -430,7 +985,41 @@ func (s *Service) ChangePassword(ctx context.Context, userID, oldPassword, newPa
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	return s.repo.UpdatePassword(ctx, userID, newHash)
+	if err := s.repo.UpdatePassword(ctx, userID, newHash); err != nil {
+		return err
+	}
+
+	if err := s.passwordChanged(ctx, userID, userID); err != nil {
+		return err
+	}
+	events.Emit(ctx, s.events, events.PasswordChanged{Meta: events.NewMeta("", userID), UserID: userID})
+	return nil
+}
+
+// passwordChanged revokes the user's other sessions and tokens per policy and
+// audits the change. The new password is already stored, so a revocation failure
+// is reported for the caller to retry; it does not undo the change.
+func (s *Service) passwordChanged(ctx context.Context, userID, actorID string) error {
+	revoke := s.revokeOnPasswordChange && s.revoker != nil
+	keepSessionID := requestctx.SessionID(ctx)
+	var revokeErr error
+	if revoke {
+		if err := s.revoker.RevokeOtherCredentials(ctx, userID, keepSessionID); err != nil {
+			revokeErr = fmt.Errorf("failed to revoke sessions and tokens: %w", err)
+		}
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypePasswordChanged,
+		ActorID:  actorID,
+		Resource: audit.ResourceUserCredentials,
+		TargetID: userID,
+		Metadata: map[string]any{
+			"sessions_revoked": revoke && revokeErr == nil,
+			"session_kept":     revoke && keepSessionID != "",
+		},
+	})
+	return revokeErr
 }
 
 // Helper functions
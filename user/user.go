@@ -16,20 +16,69 @@ package user
 
 import (
 	"context"
-	"errors"
 	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
 )
 
 // Domain errors
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrInvalidEmail       = errors.New("invalid email address")
-	ErrWeakPassword       = errors.New("password does not meet security requirements")
-	ErrAccountLocked      = errors.New("account is locked")
+	ErrUserNotFound          = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "user not found")
+	ErrUserAlreadyExists     = apperror.New(apperror.CodeUserAlreadyExists, apperror.StatusConflict, "", "user already exists")
+	ErrInvalidCredentials    = apperror.New(apperror.CodeInvalidCredentials, apperror.StatusUnauthorized, apperror.OAuth2InvalidGrant, "invalid credentials")
+	ErrInvalidEmail          = apperror.New(apperror.CodeInvalidEmail, apperror.StatusBadRequest, "", "invalid email address")
+	ErrWeakPassword          = apperror.New(apperror.CodeWeakPassword, apperror.StatusBadRequest, "", "password does not meet security requirements")
+	ErrAccountLocked         = apperror.New(apperror.CodeAccountLocked, apperror.StatusLocked, apperror.OAuth2InvalidGrant, "account is locked")
+	ErrInvalidProfile        = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid profile field")
+	ErrFieldNotEditable      = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, "", "profile field cannot be edited by this actor")
+	ErrUploadsDisabled       = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "image uploads are not configured")
+	ErrPasswordExpired       = apperror.New(apperror.CodePasswordExpired, apperror.StatusForbidden, apperror.OAuth2InteractionRequired, "password has expired and must be changed")
+	ErrPasswordResetRequired = apperror.New(apperror.CodePasswordResetRequired, apperror.StatusForbidden, apperror.OAuth2InteractionRequired, "password must be reset")
 )
 
+// LockoutError reports a locked account together with when it unlocks.
+//
+// Purpose: Lockout countdown for login UIs and SDKs.
+// Domain: Identity
+// Invariants: Unwraps to ErrAccountLocked carrying RetryAfter, so errors.Is and the
+// apperror helpers, including apperror.RetryAfterOf, see the sentinel and the delay.
+// RetryAfter is jittered past LockedUntil. Only returned when the tenant enables
+// feature.LoginAttemptFeedback.
+type LockoutError struct {
+	LockedUntil time.Time
+	RetryAfter  time.Duration
+}
+
+// newLockoutError returns the lockout feedback for an account locked until until.
+func newLockoutError(until, now time.Time) *LockoutError {
+	return &LockoutError{LockedUntil: until, RetryAfter: apperror.RetryDelay(until.Sub(now))}
+}
+
+// Error returns the sentinel's safe message.
+func (e *LockoutError) Error() string { return ErrAccountLocked.Error() }
+
+// Unwrap returns ErrAccountLocked annotated with RetryAfter.
+func (e *LockoutError) Unwrap() error {
+	return &apperror.RetryAfterError{Err: ErrAccountLocked, After: e.RetryAfter}
+}
+
+// AttemptsError reports a wrong password together with the attempts left before lockout.
+//
+// Purpose: Attempt feedback for login UIs and SDKs.
+// Domain: Identity
+// Invariants: Unwraps to ErrInvalidCredentials. Remaining is at least 1; the attempt
+// that locks the account returns a LockoutError instead. Only returned when the
+// tenant enables feature.LoginAttemptFeedback.
+type AttemptsError struct {
+	Remaining int
+}
+
+// Error returns the sentinel's safe message.
+func (e *AttemptsError) Error() string { return ErrInvalidCredentials.Error() }
+
+// Unwrap returns ErrInvalidCredentials.
+func (e *AttemptsError) Unwrap() error { return ErrInvalidCredentials }
+
 // Platform Authorization Principles:
 // 1. No tenant represents the platform
 // 2. Platform authorization is expressed only via scoped roles
@@ -43,11 +92,12 @@ var (
 //
 // Purpose: Core identity entity representing a digital actor.
 // Domain: Identity
-// Invariants: ID must be a UUIDv7. EmailHash must be a valid HMAC-SHA256 of the normalized email.
+// Invariants: ID must be a UUIDv7. EmailHash must be a valid HMAC-SHA256 of the normalized email under the key named by EmailHashKeyID.
 type User struct {
-	ID         string
-	EmailHash  string  // Global Identity Key (HMAC-SHA256)
-	EmailPlain *string // Nullable PII Metadata
+	ID             string
+	EmailHash      string  // Global Identity Key (HMAC-SHA256)
+	EmailHashKeyID string  // ID of the HMAC key that produced EmailHash
+	EmailPlain     *string // Nullable PII Metadata
 
 	EmailVerified       bool
 	Profile             Profile
@@ -58,6 +108,19 @@ type User struct {
 	DeletedAt           *time.Time
 }
 
+// LockedAccount is a user currently locked out after repeated login failures.
+//
+// Purpose: Admin view of a lockout with its remaining duration.
+// Domain: Identity
+type LockedAccount struct {
+	UserID         string        `json:"user_id"`
+	Email          string        `json:"email"`
+	Nickname       string        `json:"nickname"`
+	FailedAttempts int           `json:"failed_attempts"`
+	LockedUntil    time.Time     `json:"locked_until"`
+	Remaining      time.Duration `json:"remaining"`
+}
+
 // Profile represents user profile information.
 //
 // Purpose: PII metadata associated with a user identity.
@@ -70,13 +133,37 @@ type Profile struct {
 	Picture    string
 	Locale     string
 	Timezone   string
+	// PhoneNumber is in E.164 format; PhoneNumberVerified is cleared whenever it changes.
+	PhoneNumber         string
+	PhoneNumberVerified bool
+	Address             Address
 }
 
-// Credentials represents user authentication credentials
+// Address is a postal address (OpenID Connect Core 1.0 Section 5.1.1).
+type Address struct {
+	Formatted     string `json:"formatted,omitempty"`
+	StreetAddress string `json:"street_address,omitempty"`
+	Locality      string `json:"locality,omitempty"`
+	Region        string `json:"region,omitempty"`
+	PostalCode    string `json:"postal_code,omitempty"`
+	Country       string `json:"country,omitempty"`
+}
+
+// IsZero reports whether no address component is set
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// Credentials represents user authentication credentials.
+// PasswordChangedAt moves only when the password itself changes, not when its
+// hash is upgraded.
 type Credentials struct {
-	UserID       string
-	PasswordHash string
-	UpdatedAt    time.Time
+	UserID            string
+	PasswordHash      string
+	PasswordChangedAt time.Time
+	// ResetRequired is set when an administrator invalidated the password; only a reset clears it
+	ResetRequired bool
+	UpdatedAt     time.Time
 }
 
 // UserRepository defines the interface for user persistence.
@@ -102,12 +189,21 @@ type UserRepository interface {
 	// UpdateLockout updates user lockout status
 	UpdateLockout(ctx context.Context, userID string, failedAttempts int, lockedUntil *time.Time) error
 
+	// ListLocked returns members of tenantID whose lockout has not expired at now
+	ListLocked(ctx context.Context, tenantID string, now time.Time) ([]*User, error)
+
 	// Delete soft-deletes a user
 	Delete(ctx context.Context, id string) error
 
 	// GetCredentials retrieves user credentials
 	GetCredentials(ctx context.Context, userID string) (*Credentials, error)
 
-	// UpdatePassword updates user password
+	// UpdatePassword updates user password and clears ResetRequired
 	UpdatePassword(ctx context.Context, userID string, passwordHash string) error
+
+	// RehashPassword replaces the hash of an unchanged password, keeping PasswordChangedAt
+	RehashPassword(ctx context.Context, userID string, passwordHash string) error
+
+	// InvalidatePassword replaces the hash with an unusable one and sets ResetRequired
+	InvalidatePassword(ctx context.Context, userID string, unusableHash string) error
 }
@@ -16,17 +16,27 @@ package user
 
 import (
 	"context"
+	stdpbkdf2 "crypto/pbkdf2"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/opentrusty/opentrusty-core/apperror"
 	"github.com/opentrusty/opentrusty-core/audit"
 	"github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/feature"
+	"github.com/opentrusty/opentrusty-core/requestctx"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // MockUserRepository implements UserRepository for testing
 type MockUserRepository struct {
 	users       map[string]*User
 	credentials map[string]*Credentials
+	lookups     int
 }
 
 func NewMockUserRepository() *MockUserRepository {
@@ -42,6 +52,9 @@ func (m *MockUserRepository) Create(ctx context.Context, user *User) error {
 }
 
 func (m *MockUserRepository) AddCredentials(ctx context.Context, credentials *Credentials) error {
+	if credentials.PasswordChangedAt.IsZero() {
+		credentials.PasswordChangedAt = time.Now()
+	}
 	m.credentials[credentials.UserID] = credentials
 	return nil
 }
@@ -55,6 +68,7 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*User, err
 }
 
 func (m *MockUserRepository) GetByHash(ctx context.Context, hash string) (*User, error) {
+	m.lookups++
 	for _, u := range m.users {
 		if u.EmailHash == hash {
 			return u, nil
@@ -78,6 +92,16 @@ func (m *MockUserRepository) UpdateLockout(ctx context.Context, userID string, f
 	return nil
 }
 
+func (m *MockUserRepository) ListLocked(ctx context.Context, tenantID string, now time.Time) ([]*User, error) {
+	var locked []*User
+	for _, u := range m.users {
+		if u.LockedUntil != nil && u.LockedUntil.After(now) {
+			locked = append(locked, u)
+		}
+	}
+	return locked, nil
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 	delete(m.users, id)
 	return nil
@@ -92,6 +116,27 @@ func (m *MockUserRepository) GetCredentials(ctx context.Context, userID string)
 }
 
 func (m *MockUserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	c, ok := m.credentials[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	c.PasswordHash = passwordHash
+	c.PasswordChangedAt = time.Now()
+	c.ResetRequired = false
+	return nil
+}
+
+func (m *MockUserRepository) InvalidatePassword(ctx context.Context, userID string, unusableHash string) error {
+	c, ok := m.credentials[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	c.PasswordHash = unusableHash
+	c.ResetRequired = true
+	return nil
+}
+
+func (m *MockUserRepository) RehashPassword(ctx context.Context, userID string, passwordHash string) error {
 	c, ok := m.credentials[userID]
 	if !ok {
 		return ErrUserNotFound
@@ -105,6 +150,15 @@ type MockAuditLogger struct{}
 
 func (m *MockAuditLogger) Log(ctx context.Context, event audit.Event) {}
 
+// recordingAuditLogger keeps the events it is given
+type recordingAuditLogger struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditLogger) Log(_ context.Context, e audit.Event) {
+	r.events = append(r.events, e)
+}
+
 func TestEmailNormalizationAndHashing(t *testing.T) {
 	hmacKey := "test-key"
 	email1 := "User@Example.Com "
@@ -183,3 +237,624 @@ func TestAuthentication(t *testing.T) {
 		t.Errorf("expected ErrAccountLocked after max attempts, got %v", err)
 	}
 }
+
+func TestEnumerationResistance(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockUserRepository()
+	svc := NewService(repo, NewPasswordHasher(1024, 1, 1, 16, 32), &MockAuditLogger{}, 3, time.Hour, "test-key")
+	u, _ := svc.ProvisionIdentity(ctx, "known@example.com", Profile{})
+	_ = svc.AddPassword(ctx, u.ID, "secure-password")
+
+	for _, email := range []string{"known@example.com", "unknown@example.com"} {
+		repo.lookups = 0
+		if _, err := svc.Authenticate(ctx, email, "wrong-password"); err != ErrInvalidCredentials {
+			t.Fatalf("Authenticate(%s) error = %v, want ErrInvalidCredentials", email, err)
+		}
+		if repo.lookups != len(svc.emailKeys) {
+			t.Errorf("Authenticate(%s) made %d lookups, want %d", email, repo.lookups, len(svc.emailKeys))
+		}
+	}
+	if svc.dummyHash == "" || svc.hasher.CheckHash(svc.dummyHash) != nil {
+		t.Errorf("unknown account login must verify against a dummy hash, got %q", svc.dummyHash)
+	}
+
+	lockout := newLockoutError(time.Now().Add(time.Minute), time.Now())
+	attempts := &AttemptsError{Remaining: 2}
+	tests := []struct {
+		name string
+		flow Flow
+		err  error
+		want error
+	}{
+		{name: "login unknown account", flow: FlowLogin, err: ErrUserNotFound, want: ErrInvalidCredentials},
+		{name: "login locked account", flow: FlowLogin, err: ErrAccountLocked, want: ErrInvalidCredentials},
+		{name: "login reset required", flow: FlowLogin, err: ErrPasswordResetRequired, want: ErrInvalidCredentials},
+		{name: "login opted-in lockout feedback", flow: FlowLogin, err: lockout, want: lockout},
+		{name: "login opted-in attempts feedback", flow: FlowLogin, err: attempts, want: attempts},
+		{name: "login unrelated error", flow: FlowLogin, err: ErrWeakPassword, want: ErrWeakPassword},
+		{name: "reset unknown account", flow: FlowPasswordReset, err: ErrUserNotFound, want: nil},
+		{name: "reset locked account", flow: FlowPasswordReset, err: lockout, want: nil},
+		{name: "invitation existing account", flow: FlowInvitation, err: ErrUserAlreadyExists, want: nil},
+		{name: "invitation invalid email", flow: FlowInvitation, err: ErrInvalidEmail, want: ErrInvalidEmail},
+		{name: "success", flow: FlowLogin, err: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PublicError(tt.flow, tt.err); got != tt.want {
+				t.Errorf("PublicError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticationFeedback(t *testing.T) {
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(1024, 1, 1, 16, 32)
+	svc := NewService(repo, hasher, &MockAuditLogger{}, 3, time.Hour, "test-key",
+		WithFeatures(feature.Static{feature.LoginAttemptFeedback: true}))
+	ctx := context.Background()
+
+	email := "feedback@example.com"
+	u, _ := svc.ProvisionIdentity(ctx, email, Profile{})
+	_ = svc.AddPassword(ctx, u.ID, "secure-password")
+
+	if _, err := svc.AuthenticateInTenant(ctx, "t1", "unknown@example.com", "x"); err != ErrInvalidCredentials {
+		t.Errorf("unknown account: expected bare ErrInvalidCredentials, got %v", err)
+	}
+
+	for _, want := range []int{2, 1} {
+		_, err := svc.AuthenticateInTenant(ctx, "t1", email, "wrong-password")
+		var attempts *AttemptsError
+		if !errors.As(err, &attempts) || attempts.Remaining != want || !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("expected AttemptsError{Remaining: %d}, got %v", want, err)
+		}
+	}
+
+	for _, password := range []string{"wrong-password", "secure-password"} {
+		_, err := svc.AuthenticateInTenant(ctx, "t1", email, password)
+		var lockout *LockoutError
+		// The countdown is jittered by up to 10% past the lock's expiry
+		if !errors.As(err, &lockout) || lockout.RetryAfter <= 0 || lockout.RetryAfter > time.Hour+time.Hour/10 {
+			t.Fatalf("expected LockoutError with countdown, got %v", err)
+		}
+		if !errors.Is(err, ErrAccountLocked) || apperror.CodeOf(err) != apperror.CodeAccountLocked {
+			t.Errorf("LockoutError must classify as account_locked, got %v", apperror.CodeOf(err))
+		}
+		if d, ok := apperror.RetryAfterOf(err); !ok || d != lockout.RetryAfter || apperror.StatusOf(err) != apperror.StatusLocked {
+			t.Errorf("RetryAfterOf() = %v, %v; status %d", d, ok, apperror.StatusOf(err))
+		}
+	}
+}
+
+func TestImportIdentityLegacyHashes(t *testing.T) {
+	password := "legacy-password"
+	salt := []byte("0123456789abcdef")
+	key, err := stdpbkdf2.Key(sha256.New, password, salt, 1000, 32)
+	if err != nil {
+		t.Fatalf("failed to derive key: %v", err)
+	}
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to generate bcrypt hash: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr error
+	}{
+		{"bcrypt", string(bcryptHash), nil},
+		{"pbkdf2-sha256", FormatPBKDF2Hash(SchemePBKDF2SHA256, 1000, salt, key), nil},
+		{"unknown scheme", "$md5$abc", ErrUnsupportedHash},
+		{"pbkdf2 too many iterations", FormatPBKDF2Hash(SchemePBKDF2SHA256, maxPBKDF2Iterations+1, salt, key), ErrUnsupportedHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepository()
+			hasher := NewPasswordHasher(1024, 1, 1, 16, 32)
+			svc := NewService(repo, hasher, &MockAuditLogger{}, 3, time.Hour, "test-key")
+			ctx := context.Background()
+
+			u, err := svc.ImportIdentity(ctx, "legacy@example.com", Profile{}, true, tt.hash, "importer")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImportIdentity() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !u.EmailVerified {
+				t.Error("expected imported email verification state to be kept")
+			}
+
+			if _, err := svc.Authenticate(ctx, "legacy@example.com", "wrong-password"); err != ErrInvalidCredentials {
+				t.Errorf("expected ErrInvalidCredentials, got %v", err)
+			}
+			if _, err := svc.Authenticate(ctx, "legacy@example.com", password); err != nil {
+				t.Fatalf("authentication with legacy hash failed: %v", err)
+			}
+
+			// The legacy hash is replaced with Argon2id after a successful login.
+			creds, _ := repo.GetCredentials(ctx, u.ID)
+			if hasher.NeedsRehash(creds.PasswordHash) {
+				t.Errorf("expected hash to be upgraded, got %s", creds.PasswordHash)
+			}
+			if _, err := svc.Authenticate(ctx, "legacy@example.com", password); err != nil {
+				t.Errorf("authentication after upgrade failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthenticateLegacyHashDisabled(t *testing.T) {
+	password := "legacy-password"
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to generate bcrypt hash: %v", err)
+	}
+
+	repo := NewMockUserRepository()
+	hasher := NewPasswordHasher(1024, 1, 1, 16, 32)
+	svc := NewService(repo, hasher, &MockAuditLogger{}, 3, time.Hour, "test-key",
+		WithFeatures(feature.Static{feature.LegacyHashLogin: false}))
+	ctx := context.Background()
+
+	u, err := svc.ImportIdentity(ctx, "legacy@example.com", Profile{}, true, string(bcryptHash), "importer")
+	if err != nil {
+		t.Fatalf("ImportIdentity() error = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, "legacy@example.com", password); err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	// The hash is left untouched and the account is not locked.
+	creds, _ := repo.GetCredentials(ctx, u.ID)
+	if creds.PasswordHash != string(bcryptHash) {
+		t.Error("expected legacy hash to be kept")
+	}
+	if stored, _ := repo.GetByID(ctx, u.ID); stored.FailedLoginAttempts != 0 {
+		t.Errorf("expected no failed attempts, got %d", stored.FailedLoginAttempts)
+	}
+}
+
+func TestCheckPasswordExpiry(t *testing.T) {
+	const maxAge = 90 * 24 * time.Hour
+	tests := []struct {
+		name        string
+		hasPassword bool
+		changedAgo  time.Duration
+		maxAge      time.Duration
+		wantErr     error
+	}{
+		{"fresh", true, time.Hour, maxAge, nil},
+		{"expired", true, maxAge + time.Hour, maxAge, ErrPasswordExpired},
+		{"expiry disabled", true, 10 * maxAge, 0, nil},
+		{"no password", false, 0, maxAge, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepository()
+			svc := NewService(repo, NewPasswordHasher(1024, 1, 1, 16, 32), &MockAuditLogger{}, 3, time.Hour, "test-key")
+			if tt.hasPassword {
+				repo.AddCredentials(context.Background(), &Credentials{UserID: "u1", PasswordHash: "x", PasswordChangedAt: time.Now().Add(-tt.changedAgo)})
+			}
+
+			if err := svc.CheckPasswordExpiry(context.Background(), "u1", tt.maxAge); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckPasswordExpiry() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLegacyHashUpgradeKeepsPasswordAge(t *testing.T) {
+	password := "legacy-password"
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to generate bcrypt hash: %v", err)
+	}
+	repo := NewMockUserRepository()
+	svc := NewService(repo, NewPasswordHasher(1024, 1, 1, 16, 32), &MockAuditLogger{}, 3, time.Hour, "test-key")
+	ctx := context.Background()
+
+	u, err := svc.ImportIdentity(ctx, "legacy@example.com", Profile{}, true, string(bcryptHash), "importer")
+	if err != nil {
+		t.Fatalf("ImportIdentity() error = %v", err)
+	}
+	creds, _ := repo.GetCredentials(ctx, u.ID)
+	changedAt := time.Now().Add(-100 * 24 * time.Hour)
+	creds.PasswordChangedAt = changedAt
+
+	if _, err := svc.Authenticate(ctx, "legacy@example.com", password); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if !creds.PasswordChangedAt.Equal(changedAt) {
+		t.Error("hash upgrade must not reset the password age")
+	}
+	if err := svc.CheckPasswordExpiry(ctx, u.ID, 90*24*time.Hour); !errors.Is(err, ErrPasswordExpired) {
+		t.Errorf("CheckPasswordExpiry() error = %v, want ErrPasswordExpired", err)
+	}
+}
+
+func TestPatchProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		actorID string
+		policy  ProfilePolicy
+		patch   ProfilePatch
+		wantErr error
+		check   func(p Profile) bool
+	}{
+		{
+			name:    "self edits name and locale",
+			actorID: "u1",
+			patch:   ProfilePatch{FieldGivenName: "Ada", FieldLocale: "en-GB", FieldTimezone: "Europe/London"},
+			check:   func(p Profile) bool { return p.GivenName == "Ada" && p.Locale == "en-GB" && p.FamilyName == "Lovelace" },
+		},
+		{
+			name:    "clear picture regenerates avatar",
+			actorID: "u1",
+			patch:   ProfilePatch{FieldPicture: ""},
+			check:   func(p Profile) bool { return strings.HasPrefix(p.Picture, "data:image/svg+xml;base64,") },
+		},
+		{name: "email is managed", actorID: "u1", patch: ProfilePatch{FieldEmail: "new@example.com"}, wantErr: ErrFieldNotEditable},
+		{name: "unknown field", actorID: "u1", patch: ProfilePatch{"email_verified": "true"}, wantErr: ErrFieldNotEditable},
+		{
+			name:    "directory-managed name",
+			actorID: "u1",
+			policy:  ProfilePolicy{FieldGivenName: AccessAdmin, FieldLocale: AccessSelfOrAdmin},
+			patch:   ProfilePatch{FieldGivenName: "Ada", FieldLocale: "fr"},
+			wantErr: ErrFieldNotEditable,
+		},
+		{
+			name:    "admin edits directory-managed name",
+			actorID: "admin",
+			policy:  ProfilePolicy{FieldGivenName: AccessAdmin},
+			patch:   ProfilePatch{FieldGivenName: "Augusta"},
+			check:   func(p Profile) bool { return p.GivenName == "Augusta" },
+		},
+		{name: "picture must be https", actorID: "u1", patch: ProfilePatch{FieldPicture: "javascript:alert(1)"}, wantErr: ErrInvalidProfile},
+		{name: "picture data URI", actorID: "u1", patch: ProfilePatch{FieldPicture: "data:image/svg+xml;base64,PHN2Zz4="}, wantErr: ErrInvalidProfile},
+		{name: "bad locale", actorID: "u1", patch: ProfilePatch{FieldLocale: "english please"}, wantErr: ErrInvalidProfile},
+		{name: "bad timezone", actorID: "u1", patch: ProfilePatch{FieldTimezone: "Mars/Olympus_Mons"}, wantErr: ErrInvalidProfile},
+		{name: "name too long", actorID: "u1", patch: ProfilePatch{FieldNickname: strings.Repeat("x", 256)}, wantErr: ErrInvalidProfile},
+		{
+			name:    "new phone number is unverified",
+			actorID: "u1",
+			patch:   ProfilePatch{FieldPhoneNumber: "+447700900123"},
+			check:   func(p Profile) bool { return p.PhoneNumber == "+447700900123" && !p.PhoneNumberVerified },
+		},
+		{
+			name:    "unchanged phone number stays verified",
+			actorID: "u1",
+			patch:   ProfilePatch{FieldPhoneNumber: "+14155550100"},
+			check:   func(p Profile) bool { return p.PhoneNumberVerified },
+		},
+		{name: "phone number not E.164", actorID: "u1", patch: ProfilePatch{FieldPhoneNumber: "415-555-0100"}, wantErr: ErrInvalidProfile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockUserRepository()
+			email := "ada@example.com"
+			repo.users["u1"] = &User{ID: "u1", EmailPlain: &email, Profile: Profile{
+				GivenName: "A", FamilyName: "Lovelace", Picture: "https://cdn.example.com/a.png",
+				PhoneNumber: "+14155550100", PhoneNumberVerified: true,
+			}}
+			var opts []Option
+			if tt.policy != nil {
+				opts = append(opts, WithProfilePolicy(tt.policy))
+			}
+			svc := NewService(repo, NewPasswordHasher(1024, 1, 1, 16, 32), &MockAuditLogger{}, 3, time.Hour, "test-key", opts...)
+
+			u, err := svc.PatchProfile(context.Background(), "u1", tt.actorID, tt.patch)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PatchProfile() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if repo.users["u1"].Profile.GivenName != "A" {
+					t.Error("rejected patch must not change the profile")
+				}
+				return
+			}
+			if !tt.check(u.Profile) {
+				t.Errorf("profile = %+v", u.Profile)
+			}
+		})
+	}
+}
+
+func FuzzPasswordHasherVerify(f *testing.F) {
+	hasher := NewPasswordHasher(64, 1, 1, 8, 16)
+	valid, err := hasher.Hash("password")
+	if err != nil {
+		f.Fatalf("failed to hash seed: %v", err)
+	}
+
+	f.Add("password", valid)
+	f.Add("password", "$argon2id$v=19$m=64,t=0,p=0$c29tZXNhbHQ$MDEyMzQ1Njc4OWFiY2RlZg")
+	f.Add("password", "$argon2id$v=19$m=4294967295,t=1,p=1$c29tZXNhbHQ$MDEyMzQ1Njc4OWFiY2RlZg")
+	f.Add("", "$argon2id$$$$")
+	f.Add("", "")
+
+	f.Fuzz(func(t *testing.T, password, encoded string) {
+		var memory uint32
+		var iterations uint32
+		var parallelism uint8
+		// Skip well-formed but expensive parameter sets to keep the fuzzer fast.
+		for _, section := range strings.Split(encoded, "$") {
+			if _, err := fmt.Sscanf(section, "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err == nil {
+				if memory > 1024 || iterations > 2 {
+					return
+				}
+			}
+		}
+		_, _ = hasher.Verify(password, encoded)
+	})
+}
+
+func FuzzDecodePBKDF2Hash(f *testing.F) {
+	f.Add(FormatPBKDF2Hash(SchemePBKDF2SHA256, 27500, []byte("somesalt"), []byte("0123456789abcdef")))
+	f.Add("$pbkdf2-sha256$i=0$c29tZXNhbHQ$MDEyMzQ1Njc4OWFiY2RlZg")
+	f.Add("$pbkdf2-sha512$i=-1$c29tZXNhbHQ$MDEyMzQ1Njc4OWFiY2RlZg")
+	f.Add("$pbkdf2-sha1$i=1$$")
+	f.Add("$pbkdf2-md5$i=1$c29tZXNhbHQ$MDEyMzQ1Njc4OWFiY2RlZg")
+	f.Add("$$$$")
+
+	f.Fuzz(func(t *testing.T, encoded string) {
+		newHash, iterations, salt, key, err := decodePBKDF2Hash(encoded)
+		if err != nil {
+			if !errors.Is(err, ErrUnsupportedHash) {
+				t.Errorf("decodePBKDF2Hash() error = %v, want ErrUnsupportedHash", err)
+			}
+			return
+		}
+		if newHash == nil || iterations <= 0 || iterations > maxPBKDF2Iterations || len(salt) == 0 ||
+			len(key) < minHashKeyLength || len(key) > maxHashKeyLength {
+			t.Errorf("decodePBKDF2Hash(%q) accepted out-of-range parameters", encoded)
+		}
+	})
+}
+
+func TestLockedAccounts(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockUserRepository()
+	svc := NewService(repo, NewPasswordHasher(1024, 1, 1, 16, 32), &MockAuditLogger{}, 3, time.Hour, "test-key")
+
+	active := time.Now().Add(30 * time.Minute)
+	expired := time.Now().Add(-time.Minute)
+	repo.users["locked"] = &User{ID: "locked", FailedLoginAttempts: 3, LockedUntil: &active}
+	repo.users["expired"] = &User{ID: "expired", FailedLoginAttempts: 3, LockedUntil: &expired}
+	repo.users["ok"] = &User{ID: "ok"}
+
+	accounts, err := svc.ListLockedAccounts(ctx, "t1")
+	if err != nil {
+		t.Fatalf("ListLockedAccounts() error = %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].UserID != "locked" {
+		t.Fatalf("ListLockedAccounts() = %+v, want only the active lock", accounts)
+	}
+	if r := accounts[0].Remaining; r <= 29*time.Minute || r > 30*time.Minute {
+		t.Errorf("Remaining = %v, want about 30m", r)
+	}
+
+	if err := svc.Unlock(ctx, "t1", "locked", "admin"); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if u := repo.users["locked"]; u.LockedUntil != nil || u.FailedLoginAttempts != 0 {
+		t.Errorf("after Unlock: attempts = %d, locked until %v", u.FailedLoginAttempts, u.LockedUntil)
+	}
+	if err := svc.Unlock(ctx, "t1", "missing", "admin"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Unlock(missing) error = %v, want ErrUserNotFound", err)
+	}
+}
+
+type mockRevoker struct {
+	revoked []string
+	kept    []string
+}
+
+func (m *mockRevoker) RevokeUserCredentials(ctx context.Context, userID string) error {
+	m.revoked = append(m.revoked, userID)
+	return nil
+}
+
+func (m *mockRevoker) RevokeOtherCredentials(ctx context.Context, userID, keepSessionID string) error {
+	m.revoked = append(m.revoked, userID)
+	m.kept = append(m.kept, keepSessionID)
+	return nil
+}
+
+func TestPasswordChangeRevocation(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		session     string
+		admin       bool
+		wantRevoked bool
+		wantKept    string
+	}{
+		{"change revokes other sessions", nil, "sess-1", false, true, "sess-1"},
+		{"change without session revokes all", nil, "", false, true, ""},
+		{"admin set revokes", nil, "", true, true, ""},
+		{"disabled", []Option{WithPasswordChangeRevocation(false)}, "sess-1", false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.session != "" {
+				ctx = requestctx.WithSession(ctx, tt.session)
+			}
+			repo := NewMockUserRepository()
+			revoker := &mockRevoker{}
+			logger := &recordingAuditLogger{}
+			opts := append([]Option{WithCredentialRevoker(revoker)}, tt.opts...)
+			svc := NewService(repo, NewPasswordHasher(1024, 1, 1, 16, 32), logger, 3, time.Hour, "test-key", opts...)
+
+			u, _ := svc.ProvisionIdentity(ctx, "change@example.com", Profile{})
+			if err := svc.AddPassword(ctx, u.ID, "old-secure-password"); err != nil {
+				t.Fatalf("AddPassword() error = %v", err)
+			}
+			var err error
+			if tt.admin {
+				err = svc.SetPassword(ctx, u.ID, "new-secure-password")
+			} else {
+				err = svc.ChangePassword(ctx, u.ID, "old-secure-password", "new-secure-password")
+			}
+			if err != nil {
+				t.Fatalf("password change error = %v", err)
+			}
+
+			if got := len(revoker.revoked) == 1; got != tt.wantRevoked {
+				t.Fatalf("revoked = %v, want revocation %v", revoker.revoked, tt.wantRevoked)
+			}
+			if tt.wantRevoked && (revoker.revoked[0] != u.ID || revoker.kept[0] != tt.wantKept) {
+				t.Errorf("revoked %v keeping %v, want %s keeping %q", revoker.revoked, revoker.kept, u.ID, tt.wantKept)
+			}
+			var changed *audit.Event
+			for i := range logger.events {
+				if logger.events[i].Type == audit.TypePasswordChanged {
+					changed = &logger.events[i]
+				}
+			}
+			if changed == nil || changed.TargetID != u.ID || changed.Metadata["sessions_revoked"] != tt.wantRevoked {
+				t.Errorf("password_changed audit event = %+v", changed)
+			}
+		})
+	}
+}
+
+func TestRequireCredentialReset(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockUserRepository()
+	revoker := &mockRevoker{}
+	svc := NewService(repo, NewPasswordHasher(1024, 1, 1, 16, 32), &MockAuditLogger{}, 3, time.Hour, "test-key", WithCredentialRevoker(revoker))
+
+	email, password := "reset@example.com", "secure-password"
+	u, _ := svc.ProvisionIdentity(ctx, email, Profile{})
+	_ = svc.AddPassword(ctx, u.ID, password)
+
+	if err := svc.RequireCredentialReset(ctx, u.ID, "admin"); err != nil {
+		t.Fatalf("RequireCredentialReset() error = %v", err)
+	}
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != u.ID {
+		t.Errorf("revoked = %v, want [%s]", revoker.revoked, u.ID)
+	}
+	if _, err := svc.Authenticate(ctx, email, password); !errors.Is(err, ErrPasswordResetRequired) {
+		t.Errorf("Authenticate() with old password error = %v, want ErrPasswordResetRequired", err)
+	}
+	if err := svc.ChangePassword(ctx, u.ID, password, "another-secure-password"); err == nil {
+		t.Error("ChangePassword() with old password succeeded after reset was required")
+	}
+
+	if err := svc.SetPassword(ctx, u.ID, "another-secure-password"); err != nil {
+		t.Fatalf("SetPassword() error = %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, email, "another-secure-password"); err != nil {
+		t.Errorf("Authenticate() after reset error = %v", err)
+	}
+
+	if err := svc.RequireCredentialReset(ctx, "missing", "admin"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("RequireCredentialReset(missing) error = %v, want ErrUserNotFound", err)
+	}
+}
+
+type mockIdentityRepo struct {
+	identities []*LinkedIdentity
+}
+
+func (m *mockIdentityRepo) Create(ctx context.Context, i *LinkedIdentity) error {
+	for _, e := range m.identities {
+		if e.Kind == i.Kind && e.Issuer == i.Issuer && e.Subject == i.Subject {
+			return ErrIdentityAlreadyLinked
+		}
+	}
+	m.identities = append(m.identities, i)
+	return nil
+}
+
+func (m *mockIdentityRepo) Get(ctx context.Context, kind IdentityKind, issuer, subject string) (*LinkedIdentity, error) {
+	for _, e := range m.identities {
+		if e.Kind == kind && e.Issuer == issuer && e.Subject == subject {
+			return e, nil
+		}
+	}
+	return nil, ErrIdentityNotFound
+}
+
+func (m *mockIdentityRepo) ListByUser(ctx context.Context, userID string) ([]*LinkedIdentity, error) {
+	var res []*LinkedIdentity
+	for _, e := range m.identities {
+		if e.UserID == userID {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockIdentityRepo) Delete(ctx context.Context, userID, id string) error {
+	for i, e := range m.identities {
+		if e.UserID == userID && e.ID == id {
+			m.identities = append(m.identities[:i], m.identities[i+1:]...)
+			return nil
+		}
+	}
+	return ErrIdentityNotFound
+}
+
+func (m *mockIdentityRepo) Touch(ctx context.Context, kind IdentityKind, issuer, subject string, at time.Time) error {
+	e, err := m.Get(ctx, kind, issuer, subject)
+	if err != nil {
+		return err
+	}
+	e.LastUsedAt = &at
+	return nil
+}
+
+func TestLinkedIdentities(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockUserRepository()
+	identities := &mockIdentityRepo{}
+	svc := NewService(repo, NewPasswordHasher(1024, 1, 1, 16, 32), &MockAuditLogger{}, 3, time.Hour, "test-key", WithIdentities(identities))
+	u, _ := svc.ProvisionIdentity(ctx, "link@example.com", Profile{})
+
+	invalid := []LinkedIdentity{
+		{Kind: IdentityFederated, Subject: "123"},
+		{Kind: IdentityPhone, Subject: "555-1234"},
+		{Kind: IdentityPassword, Subject: u.ID},
+		{Kind: "sms", Subject: "x"},
+	}
+	for _, i := range invalid {
+		if _, err := svc.LinkIdentity(ctx, u.ID, u.ID, i); !errors.Is(err, ErrInvalidIdentity) {
+			t.Errorf("LinkIdentity(%+v) error = %v, want ErrInvalidIdentity", i, err)
+		}
+	}
+
+	google, err := svc.LinkIdentity(ctx, u.ID, u.ID, LinkedIdentity{Kind: IdentityFederated, Issuer: "https://accounts.google.com", Subject: "1234"})
+	if err != nil {
+		t.Fatalf("LinkIdentity() error = %v", err)
+	}
+	phone, err := svc.LinkIdentity(ctx, u.ID, u.ID, LinkedIdentity{Kind: IdentityPhone, Subject: "+4915112345678"})
+	if err != nil {
+		t.Fatalf("LinkIdentity(phone) error = %v", err)
+	}
+	other, _ := svc.ProvisionIdentity(ctx, "other@example.com", Profile{})
+	if _, err := svc.LinkIdentity(ctx, other.ID, other.ID, LinkedIdentity{Kind: IdentityPhone, Subject: "+4915112345678"}); !errors.Is(err, ErrIdentityAlreadyLinked) {
+		t.Errorf("linking a taken phone error = %v, want ErrIdentityAlreadyLinked", err)
+	}
+
+	resolved, err := svc.ResolveIdentity(ctx, IdentityFederated, "https://accounts.google.com", "1234")
+	if err != nil || resolved.ID != u.ID {
+		t.Fatalf("ResolveIdentity() = %v, %v", resolved, err)
+	}
+	if google.LastUsedAt == nil {
+		t.Error("ResolveIdentity() did not record LastUsedAt")
+	}
+
+	if err := svc.UnlinkIdentity(ctx, u.ID, google.ID, u.ID); err != nil {
+		t.Fatalf("UnlinkIdentity() error = %v", err)
+	}
+	if err := svc.UnlinkIdentity(ctx, u.ID, phone.ID, u.ID); !errors.Is(err, ErrLastIdentity) {
+		t.Errorf("UnlinkIdentity(last) error = %v, want ErrLastIdentity", err)
+	}
+	if err := svc.UnlinkIdentity(ctx, u.ID, google.ID, u.ID); !errors.Is(err, ErrIdentityNotFound) {
+		t.Errorf("UnlinkIdentity(unlinked) error = %v, want ErrIdentityNotFound", err)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/crypto"
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+// DefaultDPoPMaxAge is how old a DPoP proof's iat may be.
+const DefaultDPoPMaxAge = 5 * time.Minute
+
+// DPoPRequest describes the request a DPoP proof must be bound to.
+type DPoPRequest struct {
+	// Method is the HTTP method of the request, e.g. "GET".
+	Method string
+	// URL is the absolute request URL; query and fragment are ignored.
+	URL string
+	// AccessToken is the token presented with the proof. Empty for token requests.
+	AccessToken string
+}
+
+// dpopClaims are the claims of a DPoP proof (RFC 9449, Section 4.2).
+type dpopClaims struct {
+	ID       string `json:"jti"`
+	Method   string `json:"htm"`
+	URL      string `json:"htu"`
+	IssuedAt int64  `json:"iat"`
+	ATHash   string `json:"ath,omitempty"`
+}
+
+// ReplayCache remembers DPoP proof identifiers.
+//
+// Purpose: Single-use enforcement for DPoP proofs; share one across instances for full protection.
+// cache.Memory and cache.Redis implement it.
+// Domain: OAuth2
+type ReplayCache interface {
+	// Seen records key until expiresAt and reports whether it was already recorded
+	Seen(ctx context.Context, key string, expiresAt time.Time) (bool, error)
+}
+
+// VerifyDPoP validates a DPoP proof for req and returns the thumbprint of its key.
+//
+// Purpose: Proof-of-possession check for sender-constrained tokens (RFC 9449).
+// Domain: OAuth2
+// Security: Verifies the proof signature with the embedded public key, binds it to
+// method, URL, and access token, bounds its age, and rejects replays when a
+// ReplayCache is configured.
+// Audited: No
+// Errors: ErrInvalidDPoP, System errors
+func (v *Verifier) VerifyDPoP(ctx context.Context, proof string, req DPoPRequest) (string, error) {
+	jws, err := jose.Parse(proof)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidDPoP, err)
+	}
+	if !strings.EqualFold(jws.Header.Typ, typDPoP) {
+		return "", fmt.Errorf("%w: wrong typ", ErrInvalidDPoP)
+	}
+	if jws.Header.JWK == nil {
+		return "", fmt.Errorf("%w: missing jwk header", ErrInvalidDPoP)
+	}
+	key, err := jws.Header.JWK.PublicKey()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidDPoP, err)
+	}
+	if err := jws.Verify(key); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidDPoP, err)
+	}
+
+	var c dpopClaims
+	if err := json.Unmarshal(jws.Payload, &c); err != nil {
+		return "", fmt.Errorf("%w: malformed claims", ErrInvalidDPoP)
+	}
+	if c.ID == "" {
+		return "", fmt.Errorf("%w: missing jti", ErrInvalidDPoP)
+	}
+	if c.Method != req.Method {
+		return "", fmt.Errorf("%w: htm mismatch", ErrInvalidDPoP)
+	}
+	if !sameTargetURI(c.URL, req.URL) {
+		return "", fmt.Errorf("%w: htu mismatch", ErrInvalidDPoP)
+	}
+
+	now := time.Now()
+	issuedAt := time.Unix(c.IssuedAt, 0)
+	if issuedAt.After(now.Add(v.cfg.Leeway)) || issuedAt.Before(now.Add(-v.cfg.DPoPMaxAge-v.cfg.Leeway)) {
+		return "", fmt.Errorf("%w: iat outside acceptable window", ErrInvalidDPoP)
+	}
+
+	if req.AccessToken != "" {
+		sum := sha256.Sum256([]byte(req.AccessToken))
+		if !crypto.ConstantTimeEqualString(c.ATHash, base64.RawURLEncoding.EncodeToString(sum[:])) {
+			return "", fmt.Errorf("%w: ath mismatch", ErrInvalidDPoP)
+		}
+	}
+
+	thumbprint, err := jws.Header.JWK.Thumbprint()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidDPoP, err)
+	}
+
+	if v.replay != nil {
+		// Scope jti to the key so different clients cannot collide.
+		seen, err := v.replay.Seen(ctx, thumbprint+":"+c.ID, issuedAt.Add(v.cfg.DPoPMaxAge+2*v.cfg.Leeway))
+		if err != nil {
+			return "", fmt.Errorf("failed to check DPoP replay: %w", err)
+		}
+		if seen {
+			return "", fmt.Errorf("%w: replayed proof", ErrInvalidDPoP)
+		}
+	}
+
+	return thumbprint, nil
+}
+
+// sameTargetURI compares htu with the request URL, ignoring query and
+// fragment and normalizing scheme and host case (RFC 9449, Section 4.3).
+func sameTargetURI(htu, requestURL string) bool {
+	a, err := url.Parse(htu)
+	if err != nil || !a.IsAbs() {
+		return false
+	}
+	b, err := url.Parse(requestURL)
+	if err != nil || !b.IsAbs() {
+		return false
+	}
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Host, b.Host) &&
+		a.EscapedPath() == b.EscapedPath()
+}
+
+// MemoryReplayCache is an in-process ReplayCache.
+//
+// Purpose: Replay protection for single-instance resource servers and tests.
+// Domain: OAuth2
+// Invariants: Entries are dropped once expired.
+type MemoryReplayCache struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	lastPrune time.Time
+}
+
+// NewMemoryReplayCache creates an empty in-process replay cache.
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{entries: make(map[string]time.Time)}
+}
+
+// Seen records key until expiresAt and reports whether it was already recorded.
+func (c *MemoryReplayCache) Seen(_ context.Context, key string, expiresAt time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastPrune) >= time.Minute {
+		for k, exp := range c.entries {
+			if now.After(exp) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastPrune = now
+	}
+
+	if exp, ok := c.entries[key]; ok && now.Before(exp) {
+		return true, nil
+	}
+	c.entries[key] = expiresAt
+	return false, nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/crypto/testkeys"
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+const testURL = "https://api.example.test/v1/users"
+
+func signProof(t *testing.T, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	jwk, err := jose.NewJWK(key.Public(), "")
+	if err != nil {
+		t.Fatalf("NewJWK() error = %v", err)
+	}
+	payload, _ := json.Marshal(claims)
+	proof, err := jose.Sign(key, jose.Header{Typ: typDPoP, JWK: &jwk}, payload)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return proof
+}
+
+func proofClaims(method, url, accessToken string) map[string]any {
+	sum := sha256.Sum256([]byte(accessToken))
+	return map[string]any{
+		"jti": time.Now().String(),
+		"htm": method,
+		"htu": url,
+		"iat": time.Now().Unix(),
+		"ath": base64.RawURLEncoding.EncodeToString(sum[:]),
+	}
+}
+
+func thumbprint(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	jwk, _ := jose.NewJWK(key.Public(), "")
+	tp, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatalf("Thumbprint() error = %v", err)
+	}
+	return tp
+}
+
+func TestVerifyDPoP(t *testing.T) {
+	v := newVerifier(t, nil, Config{}, WithReplayCache(NewMemoryReplayCache()))
+	ctx := context.Background()
+	dpopKey := testkeys.ECDSA()
+	req := DPoPRequest{Method: "GET", URL: testURL + "?page=2", AccessToken: "at"}
+
+	with := func(mutate func(map[string]any)) map[string]any {
+		c := proofClaims("GET", testURL, "at")
+		mutate(c)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		proof   string
+		wantErr error
+	}{
+		{"valid", signProof(t, dpopKey, proofClaims("GET", testURL, "at")), nil},
+		{"host case and query ignored", signProof(t, dpopKey, proofClaims("GET", "https://API.example.test/v1/users", "at")), nil},
+		{"ed25519 key", signProof(t, testkeys.Ed25519(), proofClaims("GET", testURL, "at")), nil},
+		{"wrong method", signProof(t, dpopKey, with(func(c map[string]any) { c["htm"] = "POST" })), ErrInvalidDPoP},
+		{"wrong path", signProof(t, dpopKey, with(func(c map[string]any) { c["htu"] = "https://api.example.test/v1/admin" })), ErrInvalidDPoP},
+		{"relative htu", signProof(t, dpopKey, with(func(c map[string]any) { c["htu"] = "/v1/users" })), ErrInvalidDPoP},
+		{"wrong ath", signProof(t, dpopKey, with(func(c map[string]any) { c["ath"] = "x" })), ErrInvalidDPoP},
+		{"missing jti", signProof(t, dpopKey, with(func(c map[string]any) { delete(c, "jti") })), ErrInvalidDPoP},
+		{"too old", signProof(t, dpopKey, with(func(c map[string]any) { c["iat"] = time.Now().Add(-time.Hour).Unix() })), ErrInvalidDPoP},
+		{"from the future", signProof(t, dpopKey, with(func(c map[string]any) { c["iat"] = time.Now().Add(time.Hour).Unix() })), ErrInvalidDPoP},
+		{"access token as proof", signToken(t, dpopKey, "", "at+jwt", proofClaims("GET", testURL, "at")), ErrInvalidDPoP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.VerifyDPoP(ctx, tt.proof, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyDPoP() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyDPoPReplay(t *testing.T) {
+	v := newVerifier(t, nil, Config{}, WithReplayCache(NewMemoryReplayCache()))
+	ctx := context.Background()
+	req := DPoPRequest{Method: "GET", URL: testURL, AccessToken: "at"}
+	proof := signProof(t, testkeys.ECDSA(), proofClaims("GET", testURL, "at"))
+
+	if _, err := v.VerifyDPoP(ctx, proof, req); err != nil {
+		t.Fatalf("first VerifyDPoP() error = %v", err)
+	}
+	if _, err := v.VerifyDPoP(ctx, proof, req); !errors.Is(err, ErrInvalidDPoP) {
+		t.Errorf("replayed VerifyDPoP() error = %v, want ErrInvalidDPoP", err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	dpopKey := testkeys.ECDSA()
+
+	bound := validClaims()
+	bound["cnf"] = map[string]string{"jkt": thumbprint(t, dpopKey)}
+	boundToken := signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", bound)
+	bearerToken := signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", validClaims())
+	certBound := validClaims()
+	certBound["cnf"] = map[string]string{"x5t#S256": "bwcK0esc3ACC3DB2Y5_lESsXE8o9ltc05O89jdN-dg2"}
+	certToken := signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", certBound)
+
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr error
+	}{
+		{
+			name: "bearer",
+			req:  Request{Authorization: "Bearer " + bearerToken},
+		},
+		{
+			name: "dpop",
+			req: Request{
+				Authorization: "DPoP " + boundToken,
+				DPoP:          signProof(t, dpopKey, proofClaims("GET", testURL, boundToken)),
+				Method:        "GET",
+				URL:           testURL,
+			},
+		},
+		{
+			name: "certificate-bound token with its certificate",
+			req:  Request{Authorization: "Bearer " + certToken, CertThumbprint: "bwcK0esc3ACC3DB2Y5_lESsXE8o9ltc05O89jdN-dg2"},
+		},
+		{
+			name:    "certificate-bound token without a certificate",
+			req:     Request{Authorization: "Bearer " + certToken},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "certificate-bound token with another certificate",
+			req:     Request{Authorization: "Bearer " + certToken, CertThumbprint: "A4DtL2JmUMhAsvJj5tKyn64SqzmuXbMrJa0n761y5v0"},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "missing header",
+			req:     Request{},
+			wantErr: ErrMissingToken,
+		},
+		{
+			name:    "bound token downgraded to bearer",
+			req:     Request{Authorization: "Bearer " + boundToken},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "dpop scheme without proof",
+			req:     Request{Authorization: "DPoP " + boundToken, Method: "GET", URL: testURL},
+			wantErr: ErrInvalidDPoP,
+		},
+		{
+			name: "proof signed by another key",
+			req: Request{
+				Authorization: "DPoP " + boundToken,
+				DPoP:          signProof(t, testkeys.Ed25519(), proofClaims("GET", testURL, boundToken)),
+				Method:        "GET",
+				URL:           testURL,
+			},
+			wantErr: ErrInvalidDPoP,
+		},
+		{
+			name: "unbound token under dpop scheme",
+			req: Request{
+				Authorization: "DPoP " + bearerToken,
+				DPoP:          signProof(t, dpopKey, proofClaims("GET", testURL, bearerToken)),
+				Method:        "GET",
+				URL:           testURL,
+			},
+			wantErr: ErrInvalidDPoP,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newVerifier(t, NewJWKSCache(&countingFetcher{set: testJWKS(t)}, 0), Config{})
+			claims, err := v.Authenticate(ctx, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && claims.Subject != "user-1" {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
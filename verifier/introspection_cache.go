@@ -0,0 +1,179 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+// Introspection cache defaults.
+const (
+	// DefaultIntrospectionTTL bounds how long a revoked token can still be accepted
+	// when no revocation notice reaches the cache.
+	DefaultIntrospectionTTL = 30 * time.Second
+	// DefaultInactiveTTL is how long an inactive result is remembered.
+	DefaultInactiveTTL = 5 * time.Second
+	// DefaultIntrospectionEntries caps the number of cached tokens.
+	DefaultIntrospectionEntries = 10000
+)
+
+// IntrospectionCacheConfig tunes an IntrospectionCache. Zero values select the defaults.
+type IntrospectionCacheConfig struct {
+	// TTL is the maximum time an active result is served; never beyond the token's exp.
+	TTL time.Duration
+	// InactiveTTL is how long an inactive result is served. Negative disables negative caching.
+	InactiveTTL time.Duration
+	// MaxEntries caps memory use; expired entries are dropped first when full.
+	MaxEntries int
+}
+
+type introspectionEntry struct {
+	claims    *Claims
+	expiresAt time.Time
+}
+
+// IntrospectionCache is an Introspector that caches another Introspector's results.
+//
+// Purpose: Cuts introspection round trips for opaque tokens, at the resource server
+// or in front of the authorization server's own introspection endpoint.
+// Domain: OAuth2
+// Invariants: Keyed by the token's SHA-256 digest; raw tokens are never retained.
+// An active result is never served past the token's exp or after its revocation was
+// reported through Invalidate, InvalidateID, or HandleEvent. Errors are not cached.
+type IntrospectionCache struct {
+	next Introspector
+	cfg  IntrospectionCacheConfig
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]introspectionEntry
+	byID    map[string][sha256.Size]byte
+}
+
+// NewIntrospectionCache wraps next with a revocation-aware cache.
+//
+// Purpose: Constructor for the introspection cache.
+// Domain: OAuth2
+// Audited: No
+// Errors: None
+func NewIntrospectionCache(next Introspector, cfg IntrospectionCacheConfig) *IntrospectionCache {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultIntrospectionTTL
+	}
+	if cfg.InactiveTTL == 0 {
+		cfg.InactiveTTL = DefaultInactiveTTL
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultIntrospectionEntries
+	}
+	return &IntrospectionCache{
+		next:    next,
+		cfg:     cfg,
+		entries: make(map[[sha256.Size]byte]introspectionEntry),
+		byID:    make(map[string][sha256.Size]byte),
+	}
+}
+
+// Introspect returns the cached result for token, or asks the wrapped Introspector.
+func (c *IntrospectionCache) Introspect(ctx context.Context, token string) (*Claims, error) {
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		if now.Before(e.expiresAt) {
+			c.mu.Unlock()
+			return e.claims, nil
+		}
+		c.remove(key)
+	}
+	c.mu.Unlock()
+
+	claims, err := c.next.Introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := c.cfg.TTL
+	if claims == nil {
+		ttl = c.cfg.InactiveTTL
+	} else if claims.ExpiresAt != 0 {
+		ttl = min(ttl, time.Unix(claims.ExpiresAt, 0).Sub(now))
+	}
+	if ttl > 0 {
+		c.mu.Lock()
+		c.store(key, introspectionEntry{claims: claims, expiresAt: now.Add(ttl)}, now)
+		c.mu.Unlock()
+	}
+	return claims, nil
+}
+
+// Invalidate drops the cached result for token.
+func (c *IntrospectionCache) Invalidate(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(sha256.Sum256([]byte(token)))
+}
+
+// InvalidateID drops the cached result for the token whose "jti" is tokenID.
+func (c *IntrospectionCache) InvalidateID(tokenID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.byID[tokenID]; ok {
+		c.remove(key)
+	}
+}
+
+// HandleEvent invalidates revoked tokens; subscribe it to events.NameTokenRevoked.
+func (c *IntrospectionCache) HandleEvent(_ context.Context, e events.Event) error {
+	if ev, ok := e.(events.TokenRevoked); ok && ev.TokenID != "" {
+		c.InvalidateID(ev.TokenID)
+	}
+	return nil
+}
+
+// store adds an entry, evicting expired entries (or, failing that, an arbitrary one)
+// when the cache is full. The caller holds c.mu.
+func (c *IntrospectionCache) store(key [sha256.Size]byte, e introspectionEntry, now time.Time) {
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.cfg.MaxEntries {
+		for k, old := range c.entries {
+			if !now.Before(old.expiresAt) {
+				c.remove(k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.cfg.MaxEntries {
+				break
+			}
+			c.remove(k)
+		}
+	}
+	c.entries[key] = e
+	if e.claims != nil && e.claims.ID != "" {
+		c.byID[e.claims.ID] = key
+	}
+}
+
+// remove drops an entry and its ID index. The caller holds c.mu.
+func (c *IntrospectionCache) remove(key [sha256.Size]byte) {
+	if e, ok := c.entries[key]; ok && e.claims != nil && e.claims.ID != "" {
+		delete(c.byID, e.claims.ID)
+	}
+	delete(c.entries, key)
+}
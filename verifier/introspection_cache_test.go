@@ -0,0 +1,149 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+func TestIntrospectionCache(t *testing.T) {
+	ctx := context.Background()
+	active := func() *Claims {
+		return &Claims{ID: "tok-1", Subject: "user-1", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	}
+
+	t.Run("caches active result", func(t *testing.T) {
+		m := &mockIntrospector{claims: active()}
+		c := NewIntrospectionCache(m, IntrospectionCacheConfig{})
+		for range 3 {
+			if claims, err := c.Introspect(ctx, "opaque"); err != nil || claims == nil {
+				t.Fatalf("Introspect() = %v, %v", claims, err)
+			}
+		}
+		if m.calls != 1 {
+			t.Errorf("calls = %d, want 1", m.calls)
+		}
+	})
+
+	t.Run("does not retain raw token", func(t *testing.T) {
+		c := NewIntrospectionCache(&mockIntrospector{claims: active()}, IntrospectionCacheConfig{})
+		c.Introspect(ctx, "opaque")
+		if _, ok := c.entries[sha256.Sum256([]byte("opaque"))]; !ok {
+			t.Error("entry not keyed by token digest")
+		}
+	})
+
+	t.Run("ttl bounded by token expiry", func(t *testing.T) {
+		claims := active()
+		claims.ExpiresAt = time.Now().Add(2 * time.Second).Unix()
+		c := NewIntrospectionCache(&mockIntrospector{claims: claims}, IntrospectionCacheConfig{TTL: time.Hour})
+		c.Introspect(ctx, "opaque")
+		e := c.entries[sha256.Sum256([]byte("opaque"))]
+		if e.expiresAt.After(time.Unix(claims.ExpiresAt, 0)) {
+			t.Errorf("entry expires at %v, after token exp", e.expiresAt)
+		}
+	})
+
+	t.Run("expired entry is refetched", func(t *testing.T) {
+		m := &mockIntrospector{claims: active()}
+		c := NewIntrospectionCache(m, IntrospectionCacheConfig{})
+		c.Introspect(ctx, "opaque")
+		key := sha256.Sum256([]byte("opaque"))
+		e := c.entries[key]
+		e.expiresAt = time.Now().Add(-time.Second)
+		c.entries[key] = e
+		c.Introspect(ctx, "opaque")
+		if m.calls != 2 {
+			t.Errorf("calls = %d, want 2", m.calls)
+		}
+	})
+
+	t.Run("inactive result cached", func(t *testing.T) {
+		m := &mockIntrospector{}
+		c := NewIntrospectionCache(m, IntrospectionCacheConfig{})
+		c.Introspect(ctx, "opaque")
+		if claims, _ := c.Introspect(ctx, "opaque"); claims != nil {
+			t.Errorf("Introspect() = %v, want nil", claims)
+		}
+		if m.calls != 1 {
+			t.Errorf("calls = %d, want 1", m.calls)
+		}
+	})
+
+	t.Run("negative caching disabled", func(t *testing.T) {
+		m := &mockIntrospector{}
+		c := NewIntrospectionCache(m, IntrospectionCacheConfig{InactiveTTL: -1})
+		c.Introspect(ctx, "opaque")
+		c.Introspect(ctx, "opaque")
+		if m.calls != 2 {
+			t.Errorf("calls = %d, want 2", m.calls)
+		}
+	})
+
+	t.Run("errors not cached", func(t *testing.T) {
+		m := &mockIntrospector{err: errors.New("unavailable")}
+		c := NewIntrospectionCache(m, IntrospectionCacheConfig{})
+		for range 2 {
+			if _, err := c.Introspect(ctx, "opaque"); err == nil {
+				t.Fatal("Introspect() error = nil")
+			}
+		}
+		if m.calls != 2 {
+			t.Errorf("calls = %d, want 2", m.calls)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		invalidate func(c *IntrospectionCache)
+	}{
+		{"by token", func(c *IntrospectionCache) { c.Invalidate("opaque") }},
+		{"by id", func(c *IntrospectionCache) { c.InvalidateID("tok-1") }},
+		{"by revocation event", func(c *IntrospectionCache) {
+			c.HandleEvent(ctx, events.TokenRevoked{Meta: events.NewMeta("tenant-1", ""), TokenID: "tok-1"})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run("invalidate "+tt.name, func(t *testing.T) {
+			m := &mockIntrospector{claims: active()}
+			c := NewIntrospectionCache(m, IntrospectionCacheConfig{})
+			c.Introspect(ctx, "opaque")
+			tt.invalidate(c)
+			m.claims = nil
+			if claims, _ := c.Introspect(ctx, "opaque"); claims != nil {
+				t.Errorf("Introspect() after invalidation = %v, want nil", claims)
+			}
+			if len(c.byID) != 0 {
+				t.Errorf("byID has %d entries, want 0", len(c.byID))
+			}
+		})
+	}
+
+	t.Run("bounded size", func(t *testing.T) {
+		c := NewIntrospectionCache(&mockIntrospector{}, IntrospectionCacheConfig{MaxEntries: 2})
+		for _, tok := range []string{"a", "b", "c", "d"} {
+			c.Introspect(ctx, tok)
+		}
+		if len(c.entries) > 2 {
+			t.Errorf("entries = %d, want <= 2", len(c.entries))
+		}
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+// DefaultJWKSTTL is how long a fetched key set is served before it is refreshed.
+const DefaultJWKSTTL = 10 * time.Minute
+
+// minRefreshInterval bounds refetches triggered by unknown key IDs, so tokens
+// with random kids cannot be used to hammer the authorization server.
+const minRefreshInterval = 30 * time.Second
+
+// Fetcher retrieves the authorization server's JWKS.
+//
+// Purpose: Host-provided transport for the jwks_uri.
+// Domain: OAuth2
+type Fetcher interface {
+	FetchJWKS(ctx context.Context) (*jose.JWKS, error)
+}
+
+// FetcherFunc adapts a function to Fetcher.
+type FetcherFunc func(ctx context.Context) (*jose.JWKS, error)
+
+// FetchJWKS calls f(ctx).
+func (f FetcherFunc) FetchJWKS(ctx context.Context) (*jose.JWKS, error) {
+	return f(ctx)
+}
+
+// JWKSCache is a KeySource backed by a periodically refreshed JWKS.
+//
+// Purpose: Avoids a JWKS round trip per request while picking up key rotation.
+// Domain: OAuth2
+// Invariants: At most one fetch per minRefreshInterval, whether triggered by expiry
+// or an unknown kid. A failed refresh keeps serving the previous keys.
+type JWKSCache struct {
+	fetcher Fetcher
+	ttl     time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+}
+
+// NewJWKSCache creates a key cache that refreshes from fetcher every ttl.
+//
+// Purpose: Constructor for the default KeySource.
+// Domain: OAuth2
+// Audited: No
+// Errors: None
+func NewJWKSCache(fetcher Fetcher, ttl time.Duration) *JWKSCache {
+	if ttl <= 0 {
+		ttl = DefaultJWKSTTL
+	}
+	return &JWKSCache{fetcher: fetcher, ttl: ttl}
+}
+
+// PublicKey returns the signing key for kid.
+//
+// Purpose: Resolves a token's "kid" header to a verification key.
+// Domain: OAuth2
+// Audited: No
+// Errors: jose.ErrKeyNotFound, fetch errors when no keys have ever been loaded
+func (c *JWKSCache) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if (c.keys == nil || now.Sub(c.fetchedAt) >= c.ttl) && c.canRefresh(now) {
+		c.refresh(ctx, now)
+	}
+	if c.keys == nil {
+		return nil, c.lastErr
+	}
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+
+	// Unknown kid: the server may have rotated keys since the last fetch.
+	if c.canRefresh(now) {
+		c.refresh(ctx, now)
+		if key, ok := c.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, jose.ErrKeyNotFound
+}
+
+func (c *JWKSCache) canRefresh(now time.Time) bool {
+	return now.Sub(c.attemptedAt) >= minRefreshInterval
+}
+
+// refresh replaces the cached keys. On failure the previous keys are kept.
+func (c *JWKSCache) refresh(ctx context.Context, now time.Time) {
+	c.attemptedAt = now
+	set, err := c.fetcher.FetchJWKS(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to refresh JWKS", "error", err)
+		c.lastErr = fmt.Errorf("failed to fetch JWKS: %w", err)
+		return
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i := range set.Keys {
+		jwk := &set.Keys[i]
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "skipping unusable JWKS key", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = pub
+	}
+	c.keys = keys
+	c.fetchedAt = now
+	c.lastErr = nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+// Authorization schemes
+const (
+	SchemeBearer = "Bearer"
+	SchemeDPoP   = "DPoP"
+)
+
+// Request is the transport-neutral view of an incoming request. Host
+// middleware fills it from the request headers and URL.
+type Request struct {
+	// Authorization is the raw Authorization header.
+	Authorization string
+	// DPoP is the raw DPoP header, if any.
+	DPoP string
+	// Method is the HTTP method.
+	Method string
+	// URL is the absolute request URL as seen by the client.
+	URL string
+	// CertThumbprint is the base64url SHA-256 thumbprint of the client certificate
+	// presented on the TLS connection (RFC 8705 Section 3.1), if any.
+	CertThumbprint string
+}
+
+// ParseAuthorization splits an Authorization header into scheme and token.
+// The scheme is returned in canonical case.
+func ParseAuthorization(header string) (string, string, error) {
+	header = strings.TrimSpace(header)
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok {
+		if header == "" || strings.EqualFold(header, SchemeBearer) || strings.EqualFold(header, SchemeDPoP) {
+			return "", "", ErrMissingToken
+		}
+		return "", "", fmt.Errorf("%w: malformed authorization header", ErrInvalidToken)
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", "", ErrMissingToken
+	}
+	switch {
+	case strings.EqualFold(scheme, SchemeBearer):
+		return SchemeBearer, token, nil
+	case strings.EqualFold(scheme, SchemeDPoP):
+		return SchemeDPoP, token, nil
+	default:
+		return "", "", fmt.Errorf("%w: unsupported authorization scheme", ErrInvalidToken)
+	}
+}
+
+// Authenticate validates the token carried by r, including its DPoP proof.
+//
+// Purpose: The single call a resource-server middleware needs per request.
+// Domain: OAuth2
+// Security: A DPoP-bound token is rejected under the Bearer scheme, and a DPoP
+// proof must be signed by the key the token is bound to. A certificate-bound token
+// is rejected unless the request's client certificate has the bound thumbprint,
+// compared in constant time.
+// Audited: No
+// Errors: Verify errors, ErrInvalidDPoP
+func (v *Verifier) Authenticate(ctx context.Context, r Request) (*Claims, error) {
+	scheme, token, err := ParseAuthorization(r.Authorization)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := v.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert := claims.boundCertificate(); cert != "" {
+		if r.CertThumbprint == "" || subtle.ConstantTimeCompare([]byte(cert), []byte(r.CertThumbprint)) != 1 {
+			return nil, fmt.Errorf("%w: client certificate does not match token binding", ErrInvalidToken)
+		}
+	}
+
+	bound := claims.boundKey()
+	if scheme == SchemeBearer {
+		if bound != "" {
+			return nil, fmt.Errorf("%w: sender-constrained token presented as bearer", ErrInvalidToken)
+		}
+		return claims, nil
+	}
+
+	if r.DPoP == "" {
+		return nil, fmt.Errorf("%w: missing proof", ErrInvalidDPoP)
+	}
+	thumbprint, err := v.VerifyDPoP(ctx, r.DPoP, DPoPRequest{Method: r.Method, URL: r.URL, AccessToken: token})
+	if err != nil {
+		return nil, err
+	}
+	if bound == "" || bound != thumbprint {
+		return nil, fmt.Errorf("%w: proof key does not match token binding", ErrInvalidDPoP)
+	}
+	return claims, nil
+}
+
+// Challenge returns the WWW-Authenticate header value for an Authenticate
+// error (RFC 6750, Section 3 and RFC 9449, Section 7.1). Hosts respond 403
+// for ErrInsufficientScope and 401 otherwise.
+func (v *Verifier) Challenge(scheme string, err error) string {
+	if scheme != SchemeDPoP {
+		scheme = SchemeBearer
+	}
+	params := []string{fmt.Sprintf("realm=%q", v.cfg.Audience)}
+
+	var code string
+	switch {
+	case err == nil, errors.Is(err, ErrMissingToken):
+	case errors.Is(err, ErrInsufficientScope):
+		code = "insufficient_scope"
+	case errors.Is(err, ErrInvalidDPoP):
+		code = "invalid_dpop_proof"
+		scheme = SchemeDPoP
+	default:
+		code = "invalid_token"
+	}
+	if code != "" {
+		params = append(params, fmt.Sprintf("error=%q", code))
+	}
+	if len(v.cfg.RequiredScopes) > 0 {
+		params = append(params, fmt.Sprintf("scope=%q", strings.Join(v.cfg.RequiredScopes, " ")))
+	}
+	if scheme == SchemeDPoP {
+		params = append(params, fmt.Sprintf("algs=%q", strings.Join(jose.Algorithms(), " ")))
+	}
+	return scheme + " " + strings.Join(params, ", ")
+}
+
+type claimsKey struct{}
+
+// NewContext returns a copy of ctx carrying claims.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims stored by NewContext, if any.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
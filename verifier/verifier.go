@@ -0,0 +1,311 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package verifier validates OpenTrusty access tokens on behalf of resource
+// servers. It checks JWT signatures against a cached JWKS, enforces issuer,
+// audience, lifetime, and scope, falls back to token introspection for opaque
+// tokens, and verifies DPoP proofs for sender-constrained tokens.
+//
+// The package performs no network I/O of its own: the JWKS fetcher and the
+// introspection client are supplied by the host.
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/jose"
+)
+
+// Domain errors
+var (
+	ErrMissingToken      = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, "", "access token is missing")
+	ErrInvalidToken      = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, apperror.OAuth2InvalidToken, "access token is invalid")
+	ErrTokenExpired      = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, apperror.OAuth2InvalidToken, "access token has expired")
+	ErrInvalidIssuer     = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, apperror.OAuth2InvalidToken, "access token issuer mismatch")
+	ErrInvalidAudience   = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, apperror.OAuth2InvalidToken, "access token audience mismatch")
+	ErrInsufficientScope = apperror.New(apperror.CodeAccessDenied, apperror.StatusForbidden, apperror.OAuth2InsufficientScope, "access token lacks required scope")
+	ErrInactiveToken     = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, apperror.OAuth2InvalidToken, "access token is not active")
+	ErrInvalidDPoP       = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, apperror.OAuth2InvalidDPoPProof, "invalid DPoP proof")
+)
+
+// typDPoP is the JOSE type of a DPoP proof, which MUST NOT be accepted as an access token.
+const typDPoP = "dpop+jwt"
+
+// JOSE types of JWT access tokens (RFC 9068 Section 4). Tokens of any other type, such
+// as ID tokens signed with the same key, are not access tokens.
+const (
+	typAccessToken          = "at+jwt"
+	typAccessTokenMediaType = "application/at+jwt"
+)
+
+// Audience is the "aud" claim, which may be a single string or an array.
+type Audience []string
+
+// UnmarshalJSON accepts both the string and the array form.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Contains reports whether aud is one of the audiences.
+func (a Audience) Contains(aud string) bool {
+	return slices.Contains(a, aud)
+}
+
+// Confirmation is the "cnf" claim binding a token to a key (RFC 7800).
+type Confirmation struct {
+	// JKT is the JWK SHA-256 thumbprint of the DPoP key (RFC 9449).
+	JKT string `json:"jkt,omitempty"`
+	// X5TS256 is the SHA-256 thumbprint of the client certificate (RFC 8705).
+	X5TS256 string `json:"x5t#S256,omitempty"`
+}
+
+// Claims are the validated claims of an access token.
+//
+// Purpose: Identity and authorization context handed to the resource server.
+// Domain: OAuth2
+// Invariants: Only returned after signature (or introspection), issuer, audience, and lifetime checks pass.
+type Claims struct {
+	Issuer       string        `json:"iss"`
+	Subject      string        `json:"sub"`
+	Audience     Audience      `json:"aud"`
+	ExpiresAt    int64         `json:"exp"`
+	NotBefore    int64         `json:"nbf,omitempty"`
+	IssuedAt     int64         `json:"iat,omitempty"`
+	ID           string        `json:"jti,omitempty"`
+	Scope        string        `json:"scope,omitempty"`
+	ClientID     string        `json:"client_id,omitempty"`
+	TenantID     string        `json:"tenant_id,omitempty"`
+	Confirmation *Confirmation `json:"cnf,omitempty"`
+}
+
+// Scopes returns the space-delimited scope claim as a slice.
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// HasScope reports whether the token was granted scope.
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
+
+// boundKey returns the DPoP key thumbprint the token is bound to, if any.
+func (c *Claims) boundKey() string {
+	if c.Confirmation == nil {
+		return ""
+	}
+	return c.Confirmation.JKT
+}
+
+// boundCertificate returns the client certificate thumbprint the token is bound to, if any.
+func (c *Claims) boundCertificate() string {
+	if c.Confirmation == nil {
+		return ""
+	}
+	return c.Confirmation.X5TS256
+}
+
+// KeySource resolves token signing keys by key ID.
+//
+// Purpose: Abstraction over JWKS retrieval; see JWKSCache.
+// Domain: OAuth2
+type KeySource interface {
+	// PublicKey returns the key for kid, or jose.ErrKeyNotFound
+	PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// Introspector queries the authorization server about a token (RFC 7662).
+//
+// Purpose: Fallback for opaque tokens or when signing keys are unavailable.
+// Domain: OAuth2
+// Invariants: Returned claims go through the same issuer, audience, lifetime, and
+// scope checks as JWT claims, so implementations must populate iss and aud.
+type Introspector interface {
+	// Introspect returns the token's claims, or nil claims if the token is not active
+	Introspect(ctx context.Context, token string) (*Claims, error)
+}
+
+// Config holds the checks applied to every token.
+//
+// Purpose: Resource-server specific validation policy.
+// Domain: OAuth2
+// Invariants: Issuer and Audience are required.
+type Config struct {
+	// Issuer is the expected "iss" claim.
+	Issuer string
+	// Audience is the identifier of this resource server; it must appear in "aud".
+	Audience string
+	// RequiredScopes must all be granted to the token.
+	RequiredScopes []string
+	// Leeway tolerates clock skew on exp, nbf, and DPoP iat.
+	Leeway time.Duration
+	// DPoPMaxAge bounds how old a DPoP proof may be. Defaults to DefaultDPoPMaxAge.
+	DPoPMaxAge time.Duration
+}
+
+// Verifier validates access tokens.
+//
+// Purpose: Entry point for resource servers.
+// Domain: OAuth2
+type Verifier struct {
+	keys         KeySource
+	cfg          Config
+	introspector Introspector
+	replay       ReplayCache
+}
+
+// Option configures optional Verifier dependencies.
+type Option func(*Verifier)
+
+// WithIntrospector enables introspection for opaque tokens and as a fallback
+// when the signing key cannot be resolved.
+func WithIntrospector(i Introspector) Option {
+	return func(v *Verifier) { v.introspector = i }
+}
+
+// WithReplayCache rejects DPoP proofs whose jti was already seen.
+func WithReplayCache(c ReplayCache) Option {
+	return func(v *Verifier) { v.replay = c }
+}
+
+// New creates a token verifier.
+//
+// Purpose: Constructor for the resource-server verifier.
+// Domain: OAuth2
+// Audited: No
+// Errors: ErrInvalidToken if Issuer or Audience is empty
+func New(keys KeySource, cfg Config, opts ...Option) (*Verifier, error) {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, fmt.Errorf("%w: issuer and audience are required", ErrInvalidToken)
+	}
+	if cfg.DPoPMaxAge <= 0 {
+		cfg.DPoPMaxAge = DefaultDPoPMaxAge
+	}
+	v := &Verifier{keys: keys, cfg: cfg}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v, nil
+}
+
+// Verify validates an access token and returns its claims.
+//
+// Purpose: Authenticates a bearer token presented to a resource server.
+// Domain: OAuth2
+// Security: Rejects unsigned tokens, JWTs not typed "at+jwt" (RFC 9068 Section 4), so
+// DPoP proofs and ID tokens signed with the same key are refused, and tokens for other
+// audiences.
+// Audited: No
+// Errors: ErrMissingToken, ErrInvalidToken, ErrTokenExpired, ErrInvalidIssuer, ErrInvalidAudience, ErrInsufficientScope, ErrInactiveToken, System errors
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	if !jose.IsCompactJWS(token) {
+		return v.introspect(ctx, token)
+	}
+
+	jws, err := jose.Parse(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if strings.EqualFold(jws.Header.Typ, typDPoP) {
+		return nil, fmt.Errorf("%w: DPoP proof presented as access token", ErrInvalidToken)
+	}
+	if !strings.EqualFold(jws.Header.Typ, typAccessToken) && !strings.EqualFold(jws.Header.Typ, typAccessTokenMediaType) {
+		return nil, fmt.Errorf("%w: not an access token", ErrInvalidToken)
+	}
+
+	key, err := v.keys.PublicKey(ctx, jws.Header.Kid)
+	if err != nil {
+		if errors.Is(err, jose.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: unknown signing key", ErrInvalidToken)
+		}
+		if v.introspector != nil {
+			return v.introspect(ctx, token)
+		}
+		return nil, fmt.Errorf("failed to resolve signing key: %w", err)
+	}
+	if err := jws.Verify(key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(jws.Payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	if err := v.validate(&claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (v *Verifier) introspect(ctx context.Context, token string) (*Claims, error) {
+	if v.introspector == nil {
+		return nil, ErrInvalidToken
+	}
+	claims, err := v.introspector.Introspect(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	if claims == nil {
+		return nil, ErrInactiveToken
+	}
+	if err := v.validate(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validate applies the configured issuer, audience, lifetime, and scope checks.
+func (v *Verifier) validate(c *Claims, now time.Time) error {
+	if c.Issuer != v.cfg.Issuer {
+		return ErrInvalidIssuer
+	}
+	if !c.Audience.Contains(v.cfg.Audience) {
+		return ErrInvalidAudience
+	}
+	if c.ExpiresAt == 0 {
+		return fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if now.Add(-v.cfg.Leeway).After(time.Unix(c.ExpiresAt, 0)) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != 0 && now.Add(v.cfg.Leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return fmt.Errorf("%w: not yet valid", ErrInvalidToken)
+	}
+	for _, scope := range v.cfg.RequiredScopes {
+		if !c.HasScope(scope) {
+			return ErrInsufficientScope
+		}
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/client"
+	"github.com/opentrusty/opentrusty-core/crypto/testkeys"
+	"github.com/opentrusty/opentrusty-core/jose"
+	"github.com/opentrusty/opentrusty-core/oidc"
+	"github.com/opentrusty/opentrusty-core/user"
+)
+
+const (
+	testIssuer   = "https://auth.example.test"
+	testAudience = "https://api.example.test"
+)
+
+type countingFetcher struct {
+	set   *jose.JWKS
+	err   error
+	calls int
+}
+
+func (f *countingFetcher) FetchJWKS(context.Context) (*jose.JWKS, error) {
+	f.calls++
+	return f.set, f.err
+}
+
+type mockIntrospector struct {
+	claims *Claims
+	err    error
+	calls  int
+}
+
+func (m *mockIntrospector) Introspect(context.Context, string) (*Claims, error) {
+	m.calls++
+	return m.claims, m.err
+}
+
+func testJWKS(t *testing.T) *jose.JWKS {
+	t.Helper()
+	set := &jose.JWKS{}
+	for kid, key := range map[string]crypto.Signer{
+		testkeys.RSAKeyID:     testkeys.RSA(),
+		testkeys.ECKeyID:      testkeys.ECDSA(),
+		testkeys.Ed25519KeyID: testkeys.Ed25519(),
+	} {
+		jwk, err := jose.NewJWK(key.Public(), kid)
+		if err != nil {
+			t.Fatalf("NewJWK() error = %v", err)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func validClaims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":       testIssuer,
+		"sub":       "user-1",
+		"aud":       []string{"other", testAudience},
+		"exp":       now.Add(time.Hour).Unix(),
+		"iat":       now.Unix(),
+		"scope":     "openid read:users",
+		"client_id": "client-1",
+		"tenant_id": "tenant-1",
+	}
+}
+
+func signToken(t *testing.T, key crypto.Signer, kid, typ string, claims map[string]any) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	token, err := jose.Sign(key, jose.Header{Kid: kid, Typ: typ}, payload)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return token
+}
+
+func newVerifier(t *testing.T, keys KeySource, cfg Config, opts ...Option) *Verifier {
+	t.Helper()
+	if cfg.Issuer == "" {
+		cfg.Issuer = testIssuer
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = testAudience
+	}
+	v, err := New(keys, cfg, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return v
+}
+
+func TestVerify(t *testing.T) {
+	keys := NewJWKSCache(&countingFetcher{set: testJWKS(t)}, 0)
+	v := newVerifier(t, keys, Config{RequiredScopes: []string{"read:users"}, Leeway: time.Second})
+	ctx := context.Background()
+
+	with := func(mutate func(map[string]any)) map[string]any {
+		c := validClaims()
+		mutate(c)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"rs256", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", validClaims()), nil},
+		{"es256", signToken(t, testkeys.ECDSA(), testkeys.ECKeyID, "at+jwt", validClaims()), nil},
+		{"eddsa", signToken(t, testkeys.Ed25519(), testkeys.Ed25519KeyID, "at+jwt", validClaims()), nil},
+		{"string audience", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", with(func(c map[string]any) { c["aud"] = testAudience })), nil},
+		{"empty", "", ErrMissingToken},
+		{"expired", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", with(func(c map[string]any) { c["exp"] = time.Now().Add(-time.Minute).Unix() })), ErrTokenExpired},
+		{"missing exp", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", with(func(c map[string]any) { delete(c, "exp") })), ErrInvalidToken},
+		{"not yet valid", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", with(func(c map[string]any) { c["nbf"] = time.Now().Add(time.Minute).Unix() })), ErrInvalidToken},
+		{"wrong issuer", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", with(func(c map[string]any) { c["iss"] = "https://evil.test" })), ErrInvalidIssuer},
+		{"wrong audience", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", with(func(c map[string]any) { c["aud"] = "other" })), ErrInvalidAudience},
+		{"missing scope", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", with(func(c map[string]any) { c["scope"] = "openid" })), ErrInsufficientScope},
+		{"unknown kid", signToken(t, testkeys.RSA(), "rotated-away", "at+jwt", validClaims()), ErrInvalidToken},
+		{"kid of another key", signToken(t, testkeys.RSA(), testkeys.ECKeyID, "at+jwt", validClaims()), ErrInvalidToken},
+		{"media type typ", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "application/at+jwt", validClaims()), nil},
+		{"dpop proof as token", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "dpop+jwt", validClaims()), ErrInvalidToken},
+		{"untyped jwt", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "", validClaims()), ErrInvalidToken},
+		{"generic jwt typ", signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "JWT", validClaims()), ErrInvalidToken},
+		{"opaque without introspector", "opaque-token", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Verify(ctx, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (claims.Subject != "user-1" || claims.TenantID != "tenant-1" || !claims.HasScope("openid")) {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsIDToken(t *testing.T) {
+	ctx := context.Background()
+	issuer := oidc.NewIDTokenIssuer(oidc.StaticKey{Signer: testkeys.RSA(), KeyID: testkeys.RSAKeyID})
+	// A client whose client_id is the resource server's audience receives ID tokens
+	// and resource-less access tokens with the same aud.
+	c := &client.Client{ClientID: testAudience, TenantID: "tenant-1"}
+	now := time.Now()
+
+	idToken, err := issuer.Issue(ctx, oidc.IDTokenRequest{Issuer: testIssuer, Client: c, User: &user.User{ID: "user-1"}, Scope: "openid"})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	accessToken, err := issuer.IssueAccessToken(ctx, oidc.AccessTokenRequest{Issuer: testIssuer, Client: c, Token: &client.AccessToken{
+		ID: "at-1", TenantID: "tenant-1", ClientID: c.ClientID, UserID: "user-1", Scope: "openid", CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+	}})
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	v := newVerifier(t, NewJWKSCache(&countingFetcher{set: testJWKS(t)}, 0), Config{})
+	if _, err := v.Verify(ctx, accessToken); err != nil {
+		t.Fatalf("Verify(access token) error = %v", err)
+	}
+	if _, err := v.Verify(ctx, idToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify(ID token) error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifyTamperedToken(t *testing.T) {
+	v := newVerifier(t, NewJWKSCache(&countingFetcher{set: testJWKS(t)}, 0), Config{})
+	token := signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", validClaims())
+
+	other := validClaims()
+	other["sub"] = "admin"
+	forged := signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", other)
+	parts, forgedParts := strings.Split(token, "."), strings.Split(forged, ".")
+	tampered := parts[0] + "." + forgedParts[1] + "." + parts[2]
+
+	if _, err := v.Verify(context.Background(), tampered); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifyIntrospection(t *testing.T) {
+	ctx := context.Background()
+	active := &Claims{
+		Issuer:    testIssuer,
+		Subject:   "user-1",
+		Audience:  Audience{testAudience},
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Scope:     "read:users",
+	}
+
+	t.Run("opaque token", func(t *testing.T) {
+		in := &mockIntrospector{claims: active}
+		v := newVerifier(t, NewJWKSCache(&countingFetcher{set: testJWKS(t)}, 0), Config{}, WithIntrospector(in))
+		claims, err := v.Verify(ctx, "opaque-token")
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if claims.Subject != "user-1" || in.calls != 1 {
+			t.Errorf("claims = %+v, introspections = %d", claims, in.calls)
+		}
+	})
+
+	t.Run("inactive", func(t *testing.T) {
+		v := newVerifier(t, NewJWKSCache(&countingFetcher{set: testJWKS(t)}, 0), Config{}, WithIntrospector(&mockIntrospector{}))
+		if _, err := v.Verify(ctx, "opaque-token"); !errors.Is(err, ErrInactiveToken) {
+			t.Errorf("Verify() error = %v, want ErrInactiveToken", err)
+		}
+	})
+
+	t.Run("introspected claims are validated", func(t *testing.T) {
+		wrongAud := *active
+		wrongAud.Audience = Audience{"other"}
+		v := newVerifier(t, NewJWKSCache(&countingFetcher{set: testJWKS(t)}, 0), Config{}, WithIntrospector(&mockIntrospector{claims: &wrongAud}))
+		if _, err := v.Verify(ctx, "opaque-token"); !errors.Is(err, ErrInvalidAudience) {
+			t.Errorf("Verify() error = %v, want ErrInvalidAudience", err)
+		}
+	})
+
+	t.Run("jwks unavailable", func(t *testing.T) {
+		in := &mockIntrospector{claims: active}
+		fetcher := &countingFetcher{err: errors.New("connection refused")}
+		v := newVerifier(t, NewJWKSCache(fetcher, 0), Config{}, WithIntrospector(in))
+		token := signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", validClaims())
+		if _, err := v.Verify(ctx, token); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if in.calls != 1 {
+			t.Errorf("expected introspection fallback, got %d calls", in.calls)
+		}
+	})
+
+	t.Run("jwks unavailable without introspector", func(t *testing.T) {
+		v := newVerifier(t, NewJWKSCache(&countingFetcher{err: errors.New("connection refused")}, 0), Config{})
+		token := signToken(t, testkeys.RSA(), testkeys.RSAKeyID, "at+jwt", validClaims())
+		if _, err := v.Verify(ctx, token); err == nil || errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want system error", err)
+		}
+	})
+}
+
+func TestJWKSCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("caches between calls", func(t *testing.T) {
+		f := &countingFetcher{set: testJWKS(t)}
+		c := NewJWKSCache(f, time.Hour)
+		for range 3 {
+			if _, err := c.PublicKey(ctx, testkeys.RSAKeyID); err != nil {
+				t.Fatalf("PublicKey() error = %v", err)
+			}
+		}
+		if f.calls != 1 {
+			t.Errorf("fetches = %d, want 1", f.calls)
+		}
+	})
+
+	t.Run("unknown kid refetch is rate limited", func(t *testing.T) {
+		f := &countingFetcher{set: testJWKS(t)}
+		c := NewJWKSCache(f, time.Hour)
+		for range 5 {
+			if _, err := c.PublicKey(ctx, "unknown"); !errors.Is(err, jose.ErrKeyNotFound) {
+				t.Fatalf("PublicKey() error = %v, want ErrKeyNotFound", err)
+			}
+		}
+		if f.calls != 1 {
+			t.Errorf("fetches = %d, want 1", f.calls)
+		}
+	})
+
+	t.Run("unknown kid triggers refresh after interval", func(t *testing.T) {
+		f := &countingFetcher{set: &jose.JWKS{}}
+		c := NewJWKSCache(f, time.Hour)
+		if _, err := c.PublicKey(ctx, testkeys.RSAKeyID); !errors.Is(err, jose.ErrKeyNotFound) {
+			t.Fatalf("PublicKey() error = %v, want ErrKeyNotFound", err)
+		}
+
+		// Simulate key rotation on the server after the refresh interval.
+		f.set = testJWKS(t)
+		c.attemptedAt = c.attemptedAt.Add(-minRefreshInterval)
+		if _, err := c.PublicKey(ctx, testkeys.RSAKeyID); err != nil {
+			t.Errorf("PublicKey() error = %v", err)
+		}
+	})
+
+	t.Run("stale keys survive failed refresh", func(t *testing.T) {
+		f := &countingFetcher{set: testJWKS(t)}
+		c := NewJWKSCache(f, time.Hour)
+		if _, err := c.PublicKey(ctx, testkeys.RSAKeyID); err != nil {
+			t.Fatalf("PublicKey() error = %v", err)
+		}
+
+		f.err = errors.New("timeout")
+		c.fetchedAt = c.fetchedAt.Add(-2 * time.Hour)
+		c.attemptedAt = c.fetchedAt
+		if _, err := c.PublicKey(ctx, testkeys.RSAKeyID); err != nil {
+			t.Errorf("PublicKey() error = %v", err)
+		}
+		if f.calls != 2 {
+			t.Errorf("fetches = %d, want 2", f.calls)
+		}
+	})
+
+	t.Run("skips encryption keys", func(t *testing.T) {
+		set := testJWKS(t)
+		for i := range set.Keys {
+			set.Keys[i].Use = "enc"
+		}
+		c := NewJWKSCache(&countingFetcher{set: set}, time.Hour)
+		if _, err := c.PublicKey(ctx, testkeys.RSAKeyID); !errors.Is(err, jose.ErrKeyNotFound) {
+			t.Errorf("PublicKey() error = %v, want ErrKeyNotFound", err)
+		}
+	})
+}
+
+func TestParseAuthorization(t *testing.T) {
+	tests := []struct {
+		header     string
+		wantScheme string
+		wantToken  string
+		wantErr    error
+	}{
+		{"Bearer abc", SchemeBearer, "abc", nil},
+		{"bearer abc", SchemeBearer, "abc", nil},
+		{"DPoP abc", SchemeDPoP, "abc", nil},
+		{"", "", "", ErrMissingToken},
+		{"Bearer ", "", "", ErrMissingToken},
+		{"Basic dXNlcjpwYXNz", "", "", ErrInvalidToken},
+		{"abc", "", "", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			scheme, token, err := ParseAuthorization(tt.header)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseAuthorization() error = %v, want %v", err, tt.wantErr)
+			}
+			if scheme != tt.wantScheme || token != tt.wantToken {
+				t.Errorf("ParseAuthorization() = %q, %q", scheme, token)
+			}
+		})
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	v := newVerifier(t, nil, Config{RequiredScopes: []string{"read:users"}})
+
+	tests := []struct {
+		name   string
+		scheme string
+		err    error
+		want   string
+	}{
+		{"missing", SchemeBearer, ErrMissingToken, `Bearer realm="https://api.example.test", scope="read:users"`},
+		{"expired", SchemeBearer, ErrTokenExpired, `Bearer realm="https://api.example.test", error="invalid_token", scope="read:users"`},
+		{"scope", SchemeBearer, ErrInsufficientScope, `Bearer realm="https://api.example.test", error="insufficient_scope", scope="read:users"`},
+		{"dpop", SchemeBearer, ErrInvalidDPoP, `DPoP realm="https://api.example.test", error="invalid_dpop_proof", scope="read:users", algs="RS256 PS256 ES256 EdDSA"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.Challenge(tt.scheme, tt.err); got != tt.want {
+				t.Errorf("Challenge() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatal("expected no claims in empty context")
+	}
+	claims := &Claims{Subject: "user-1"}
+	got, ok := FromContext(NewContext(context.Background(), claims))
+	if !ok || got != claims {
+		t.Errorf("FromContext() = %v, %v", got, ok)
+	}
+}
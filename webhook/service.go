@@ -0,0 +1,340 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/events"
+	"github.com/opentrusty/opentrusty-core/id"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+// processBatchSize bounds how many due deliveries one ProcessDue call attempts.
+const processBatchSize = 100
+
+// historyLimit bounds ListDeliveries results.
+const historyLimit = 100
+
+// Payload is the JSON envelope posted to endpoints.
+type Payload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	TenantID  string    `json:"tenant_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+	// CorrelationID identifies the user action that caused the event
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// Service manages webhook endpoints and delivers events to them.
+//
+// Purpose: Outbox-style lifecycle event notification for tenants.
+// Domain: Tenant
+// Invariants: Publish never performs network I/O; ProcessDue does, via Sender.
+type Service struct {
+	repo        Repository
+	sender      Sender
+	auditLogger audit.Logger
+	retry       RetryPolicy
+}
+
+// NewService creates a new webhook service.
+//
+// Purpose: Constructor for the webhook service.
+// Domain: Tenant
+// Audited: No
+// Errors: None
+func NewService(repo Repository, sender Sender, auditLogger audit.Logger, retry RetryPolicy) *Service {
+	return &Service{
+		repo:        repo,
+		sender:      sender,
+		auditLogger: auditLogger,
+		retry:       retry,
+	}
+}
+
+// RegisterEndpoint subscribes a URL to catalog events for a tenant.
+//
+// Purpose: Tenant admin webhook configuration.
+// Domain: Tenant
+// Security: Only https URLs are accepted. The signing secret is generated here and returned once.
+// Audited: Yes (WebhookCreated)
+// Errors: ErrInvalidEndpoint, ErrUnknownEvent, ErrNoEvents, System errors
+func (s *Service) RegisterEndpoint(ctx context.Context, tenantID, rawURL string, events []string, actorID string) (*Endpoint, error) {
+	if err := validateEndpoint(rawURL, events); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	e := &Endpoint{
+		ID:        id.NewUUIDv7(),
+		TenantID:  tenantID,
+		URL:       rawURL,
+		Secret:    generateSecret(),
+		Events:    events,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.CreateEndpoint(ctx, e); err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeWebhookCreated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceWebhook,
+		TargetName: e.URL,
+		TargetID:   e.ID,
+		Metadata:   map[string]any{"events": events},
+	})
+	return e, nil
+}
+
+// UpdateEndpoint changes an endpoint's URL, subscriptions, or enabled state.
+//
+// Purpose: Tenant admin webhook configuration.
+// Domain: Tenant
+// Security: The signing secret is never changed or returned here.
+// Audited: Yes (WebhookUpdated)
+// Errors: ErrEndpointNotFound, ErrInvalidEndpoint, ErrUnknownEvent, ErrNoEvents, System errors
+func (s *Service) UpdateEndpoint(ctx context.Context, tenantID, endpointID, rawURL string, events []string, enabled bool, actorID string) (*Endpoint, error) {
+	if err := validateEndpoint(rawURL, events); err != nil {
+		return nil, err
+	}
+
+	e, err := s.repo.GetEndpoint(ctx, tenantID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	e.URL = rawURL
+	e.Events = events
+	e.Enabled = enabled
+	e.UpdatedAt = time.Now()
+	if err := s.repo.UpdateEndpoint(ctx, e); err != nil {
+		return nil, fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:       audit.TypeWebhookUpdated,
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		Resource:   audit.ResourceWebhook,
+		TargetName: e.URL,
+		TargetID:   e.ID,
+		Metadata:   map[string]any{"events": events, "enabled": enabled},
+	})
+	e.Secret = ""
+	return e, nil
+}
+
+// DeleteEndpoint removes an endpoint and its delivery history.
+//
+// Purpose: Tenant admin webhook configuration.
+// Domain: Tenant
+// Audited: Yes (WebhookDeleted)
+// Errors: ErrEndpointNotFound, System errors
+func (s *Service) DeleteEndpoint(ctx context.Context, tenantID, endpointID, actorID string) error {
+	if err := s.repo.DeleteEndpoint(ctx, tenantID, endpointID); err != nil {
+		return err
+	}
+
+	s.auditLogger.Log(ctx, audit.Event{
+		Type:     audit.TypeWebhookDeleted,
+		TenantID: tenantID,
+		ActorID:  actorID,
+		Resource: audit.ResourceWebhook,
+		TargetID: endpointID,
+	})
+	return nil
+}
+
+// ListEndpoints returns a tenant's endpoints with secrets redacted.
+func (s *Service) ListEndpoints(ctx context.Context, tenantID string) ([]*Endpoint, error) {
+	endpoints, err := s.repo.ListEndpoints(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range endpoints {
+		e.Secret = ""
+	}
+	return endpoints, nil
+}
+
+// ListDeliveries returns recent delivery history for an endpoint.
+func (s *Service) ListDeliveries(ctx context.Context, tenantID, endpointID string) ([]*Delivery, error) {
+	return s.repo.ListDeliveries(ctx, tenantID, endpointID, historyLimit)
+}
+
+// Publish queues eventType for every enabled endpoint of the tenant subscribed to it.
+//
+// Purpose: Entry point for lifecycle events; delivery happens asynchronously in ProcessDue.
+// Domain: Tenant
+// Security: data MUST NOT contain secrets or plaintext credentials.
+// Audited: No
+// Errors: ErrUnknownEvent, System errors
+func (s *Service) Publish(ctx context.Context, tenantID, eventType string, data any) error {
+	if !IsKnownEvent(eventType) {
+		return fmt.Errorf("%w: %s", ErrUnknownEvent, eventType)
+	}
+
+	endpoints, err := s.repo.ListEndpoints(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+
+	now := time.Now()
+	for _, e := range endpoints {
+		if !e.Subscribes(eventType) {
+			continue
+		}
+
+		deliveryID := id.NewUUIDv7()
+		body, err := json.Marshal(Payload{
+			ID:            deliveryID,
+			Type:          eventType,
+			TenantID:      tenantID,
+			CreatedAt:     now,
+			Data:          data,
+			CorrelationID: tracing.CorrelationID(ctx),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+
+		d := &Delivery{
+			ID:            deliveryID,
+			EndpointID:    e.ID,
+			TenantID:      tenantID,
+			EventType:     eventType,
+			Payload:       body,
+			Status:        StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := s.repo.CreateDelivery(ctx, d); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// HandleEvent queues catalog events from the event bus for delivery.
+// Events outside the catalog or without a tenant are ignored.
+func (s *Service) HandleEvent(ctx context.Context, e events.Event) error {
+	if !IsKnownEvent(e.EventName()) || e.EventTenantID() == "" {
+		return nil
+	}
+	return s.Publish(ctx, e.EventTenantID(), e.EventName(), e)
+}
+
+// ProcessDue attempts every pending delivery whose retry time has arrived.
+//
+// Purpose: Periodic delivery job for the scheduler.
+// Domain: Tenant
+// Security: Each request is signed with the endpoint secret at send time.
+// Audited: No
+// Errors: System errors (individual delivery failures are recorded, not returned)
+func (s *Service) ProcessDue(ctx context.Context) error {
+	deliveries, err := s.repo.ListDueDeliveries(ctx, time.Now(), processBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	for _, d := range deliveries {
+		if err := s.attempt(ctx, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) attempt(ctx context.Context, d *Delivery) error {
+	e, err := s.repo.GetEndpoint(ctx, d.TenantID, d.EndpointID)
+	if err != nil && !errors.Is(err, ErrEndpointNotFound) {
+		return fmt.Errorf("failed to load webhook endpoint: %w", err)
+	}
+
+	now := time.Now()
+	d.Attempts++
+	d.LastError = ""
+
+	if e == nil || !e.Enabled {
+		d.Status = StatusFailed
+		d.LastError = "endpoint removed or disabled"
+		return s.repo.UpdateDelivery(ctx, d)
+	}
+
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		HeaderSignature: Sign(e.Secret, now, d.Payload),
+		HeaderEvent:     d.EventType,
+		HeaderDelivery:  d.ID,
+	}
+	code, sendErr := s.sender.Send(ctx, e.URL, headers, d.Payload)
+	d.LastStatusCode = code
+
+	switch {
+	case sendErr == nil && code >= 200 && code < 300:
+		d.Status = StatusSucceeded
+		d.DeliveredAt = &now
+	case d.Attempts >= s.retry.MaxAttempts:
+		d.Status = StatusFailed
+	default:
+		d.NextAttemptAt = now.Add(s.retry.Backoff(d.Attempts))
+	}
+	if sendErr != nil {
+		d.LastError = sendErr.Error()
+	} else if d.Status != StatusSucceeded {
+		d.LastError = fmt.Sprintf("unexpected status %d", code)
+	}
+
+	if err := s.repo.UpdateDelivery(ctx, d); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func validateEndpoint(rawURL string, events []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return ErrInvalidEndpoint
+	}
+	if len(events) == 0 {
+		return ErrNoEvents
+	}
+	for _, ev := range events {
+		if !IsKnownEvent(ev) {
+			return fmt.Errorf("%w: %s", ErrUnknownEvent, ev)
+		}
+	}
+	return nil
+}
+
+func generateSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,263 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/audit"
+	"github.com/opentrusty/opentrusty-core/tracing"
+)
+
+type mockRepo struct {
+	endpoints  map[string]*Endpoint
+	deliveries []*Delivery
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{endpoints: make(map[string]*Endpoint)}
+}
+
+func (m *mockRepo) CreateEndpoint(ctx context.Context, e *Endpoint) error {
+	cp := *e
+	m.endpoints[e.ID] = &cp
+	return nil
+}
+
+func (m *mockRepo) GetEndpoint(ctx context.Context, tenantID, id string) (*Endpoint, error) {
+	e, ok := m.endpoints[id]
+	if !ok || e.TenantID != tenantID {
+		return nil, ErrEndpointNotFound
+	}
+	cp := *e
+	return &cp, nil
+}
+
+func (m *mockRepo) ListEndpoints(ctx context.Context, tenantID string) ([]*Endpoint, error) {
+	var res []*Endpoint
+	for _, e := range m.endpoints {
+		if e.TenantID == tenantID {
+			cp := *e
+			res = append(res, &cp)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) UpdateEndpoint(ctx context.Context, e *Endpoint) error {
+	cp := *e
+	m.endpoints[e.ID] = &cp
+	return nil
+}
+
+func (m *mockRepo) DeleteEndpoint(ctx context.Context, tenantID, id string) error {
+	if _, err := m.GetEndpoint(ctx, tenantID, id); err != nil {
+		return err
+	}
+	delete(m.endpoints, id)
+	return nil
+}
+
+func (m *mockRepo) CreateDelivery(ctx context.Context, d *Delivery) error {
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *mockRepo) UpdateDelivery(ctx context.Context, d *Delivery) error {
+	return nil
+}
+
+func (m *mockRepo) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error) {
+	var res []*Delivery
+	for _, d := range m.deliveries {
+		if d.Status == StatusPending && !d.NextAttemptAt.After(now) {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) ListDeliveries(ctx context.Context, tenantID, endpointID string, limit int) ([]*Delivery, error) {
+	return m.deliveries, nil
+}
+
+type mockSender struct {
+	codes   []int
+	headers []map[string]string
+}
+
+func (m *mockSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	m.headers = append(m.headers, headers)
+	code := m.codes[0]
+	if len(m.codes) > 1 {
+		m.codes = m.codes[1:]
+	}
+	if code == 0 {
+		return 0, errors.New("connection refused")
+	}
+	return code, nil
+}
+
+type nopAuditLogger struct{}
+
+func (nopAuditLogger) Log(context.Context, audit.Event) {}
+
+func TestRegisterEndpointValidation(t *testing.T) {
+	svc := NewService(newMockRepo(), &mockSender{}, nopAuditLogger{}, DefaultRetryPolicy())
+
+	tests := []struct {
+		name    string
+		url     string
+		events  []string
+		wantErr error
+	}{
+		{name: "valid", url: "https://hooks.example.com/ot", events: []string{EventUserCreated}},
+		{name: "plain http rejected", url: "http://hooks.example.com/ot", events: []string{EventUserCreated}, wantErr: ErrInvalidEndpoint},
+		{name: "credentials in url rejected", url: "https://u:p@hooks.example.com", events: []string{EventUserCreated}, wantErr: ErrInvalidEndpoint},
+		{name: "no events", url: "https://hooks.example.com", wantErr: ErrNoEvents},
+		{name: "unknown event", url: "https://hooks.example.com", events: []string{"user.exploded"}, wantErr: ErrUnknownEvent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := svc.RegisterEndpoint(context.Background(), "t1", tt.url, tt.events, "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RegisterEndpoint() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && e.Secret == "" {
+				t.Error("expected generated secret on creation")
+			}
+		})
+	}
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		codes        []int
+		rounds       int
+		wantStatus   string
+		wantAttempts int
+	}{
+		{name: "first attempt succeeds", codes: []int{204}, rounds: 1, wantStatus: StatusSucceeded, wantAttempts: 1},
+		{name: "retries then succeeds", codes: []int{500, 0, 200}, rounds: 3, wantStatus: StatusSucceeded, wantAttempts: 3},
+		{name: "gives up after max attempts", codes: []int{503}, rounds: 5, wantStatus: StatusFailed, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newMockRepo()
+			sender := &mockSender{codes: tt.codes}
+			svc := NewService(repo, sender, nopAuditLogger{}, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute})
+
+			e, err := svc.RegisterEndpoint(ctx, "t1", "https://hooks.example.com", []string{EventUserCreated}, "admin")
+			if err != nil {
+				t.Fatalf("RegisterEndpoint() error = %v", err)
+			}
+			if _, err := svc.RegisterEndpoint(ctx, "t1", "https://other.example.com", []string{EventTenantDeleted}, "admin"); err != nil {
+				t.Fatalf("RegisterEndpoint() error = %v", err)
+			}
+			if err := svc.Publish(ctx, "t1", EventUserCreated, map[string]string{"user_id": "u1"}); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+			if len(repo.deliveries) != 1 {
+				t.Fatalf("queued %d deliveries, want 1 (only the subscribed endpoint)", len(repo.deliveries))
+			}
+
+			d := repo.deliveries[0]
+			for i := 0; i < tt.rounds; i++ {
+				d.NextAttemptAt = time.Time{} // make due immediately
+				if err := svc.ProcessDue(ctx); err != nil {
+					t.Fatalf("ProcessDue() error = %v", err)
+				}
+			}
+
+			if d.Status != tt.wantStatus || d.Attempts != tt.wantAttempts {
+				t.Errorf("delivery status = %s after %d attempts, want %s after %d", d.Status, d.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+
+			sig := sender.headers[0][HeaderSignature]
+			if err := Verify(repo.endpoints[e.ID].Secret, sig, d.Payload, time.Minute, time.Now()); err != nil {
+				t.Errorf("Verify() of sent signature error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPublishCarriesCorrelationID(t *testing.T) {
+	ctx := tracing.WithCorrelationID(context.Background(), "corr-1")
+	repo := newMockRepo()
+	svc := NewService(repo, &mockSender{}, nopAuditLogger{}, DefaultRetryPolicy())
+
+	if _, err := svc.RegisterEndpoint(ctx, "t1", "https://hooks.example.com", []string{EventUserCreated}, "admin"); err != nil {
+		t.Fatalf("RegisterEndpoint() error = %v", err)
+	}
+	if err := svc.Publish(ctx, "t1", EventUserCreated, map[string]string{"user_id": "u1"}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	var p Payload
+	if len(repo.deliveries) != 1 || json.Unmarshal(repo.deliveries[0].Payload, &p) != nil {
+		t.Fatalf("deliveries = %v", repo.deliveries)
+	}
+	if p.CorrelationID != "corr-1" {
+		t.Errorf("payload correlation_id = %q, want corr-1", p.CorrelationID)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"user.created"}`)
+	now := time.Now()
+	header := Sign("whsec_test", now, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		ok     bool
+	}{
+		{name: "valid", secret: "whsec_test", header: header, body: body, now: now, ok: true},
+		{name: "wrong secret", secret: "whsec_other", header: header, body: body, now: now},
+		{name: "tampered body", secret: "whsec_test", header: header, body: []byte(`{}`), now: now},
+		{name: "replayed later", secret: "whsec_test", header: header, body: body, now: now.Add(10 * time.Minute)},
+		{name: "malformed", secret: "whsec_test", header: "garbage", body: body, now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if (err == nil) != tt.ok {
+				t.Errorf("Verify() error = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := p.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/crypto"
+)
+
+// Delivery headers set on every request.
+const (
+	HeaderSignature = "X-OpenTrusty-Signature"
+	HeaderEvent     = "X-OpenTrusty-Event"
+	HeaderDelivery  = "X-OpenTrusty-Delivery"
+)
+
+// Sign computes the signature header value for body at timestamp ts.
+//
+// Purpose: Lets receivers authenticate deliveries and reject replays.
+// Domain: Tenant
+// Security: HMAC-SHA256 over "<unix-ts>.<body>" keyed with the endpoint secret.
+// Audited: No
+// Errors: None
+func Sign(secret string, ts time.Time, body []byte) string {
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + unix + ",v1=" + mac(secret, unix, body)
+}
+
+// Verify checks a signature header produced by Sign.
+//
+// Purpose: Reference verification for receivers (and tests).
+// Domain: Tenant
+// Security: Constant-time comparison. Rejects timestamps outside tolerance of now.
+// Audited: No
+// Errors: ErrInvalidSignature
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var unix, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			unix = v
+		case "v1":
+			sig = v
+		}
+	}
+	if unix == "" || sig == "" {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+
+	ts, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	if !crypto.ConstantTimeEqualString(mac(secret, unix, body), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func mac(secret, unix string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(unix))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
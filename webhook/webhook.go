@@ -0,0 +1,171 @@
+// Copyright 2026 The OpenTrusty Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook delivers signed lifecycle event notifications to
+// tenant-registered endpoints. Core owns registration, signing, retry
+// scheduling, and delivery history; the host supplies the network transport
+// through Sender so this repository stays free of HTTP code.
+package webhook
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/opentrusty/opentrusty-core/apperror"
+	"github.com/opentrusty/opentrusty-core/events"
+)
+
+// Domain errors
+var (
+	ErrEndpointNotFound = apperror.New(apperror.CodeNotFound, apperror.StatusNotFound, "", "webhook endpoint not found")
+	ErrInvalidEndpoint  = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "invalid webhook endpoint URL")
+	ErrUnknownEvent     = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "unknown webhook event type")
+	ErrNoEvents         = apperror.New(apperror.CodeInvalidRequest, apperror.StatusBadRequest, "", "webhook endpoint must subscribe to at least one event")
+	ErrInvalidSignature = apperror.New(apperror.CodeInvalidToken, apperror.StatusUnauthorized, "", "invalid webhook signature")
+)
+
+// Event catalog. Names match the corresponding events package names.
+const (
+	EventUserCreated   = events.NameUserCreated
+	EventTenantDeleted = events.NameTenantDeleted
+	EventTokenRevoked  = events.NameTokenRevoked
+	EventRoleAssigned  = events.NameRoleAssigned
+)
+
+// Catalog returns every event type an endpoint may subscribe to.
+func Catalog() []string {
+	return []string{EventUserCreated, EventTenantDeleted, EventTokenRevoked, EventRoleAssigned}
+}
+
+// IsKnownEvent reports whether eventType is part of the catalog.
+func IsKnownEvent(eventType string) bool {
+	return slices.Contains(Catalog(), eventType)
+}
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Endpoint is a tenant-registered webhook receiver.
+//
+// Purpose: Subscription of one URL to a set of catalog events.
+// Domain: Tenant
+// Invariants: URL is absolute https. Secret is only returned on creation. Events are catalog members.
+type Endpoint struct {
+	ID       string
+	TenantID string
+	URL      string
+	// Secret keys the HMAC signature. It is stored as-is because signing needs the raw value.
+	Secret    string
+	Events    []string
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Subscribes reports whether the endpoint receives eventType.
+func (e *Endpoint) Subscribes(eventType string) bool {
+	return e.Enabled && slices.Contains(e.Events, eventType)
+}
+
+// Delivery is one event addressed to one endpoint, with its attempt history.
+//
+// Purpose: Durable outbox row driving retries and exposing delivery history.
+// Domain: Tenant
+// Invariants: Payload is immutable once created. Status is terminal once succeeded or failed.
+type Delivery struct {
+	ID             string
+	EndpointID     string
+	TenantID       string
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	LastStatusCode int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// RetryPolicy controls redelivery of failed attempts.
+//
+// Purpose: Exponential backoff schedule for webhook delivery.
+// Domain: Tenant
+// Invariants: MaxAttempts >= 1. Backoff doubles from InitialBackoff up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns a schedule spanning roughly a day.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     6 * time.Hour,
+	}
+}
+
+// Backoff returns the delay before the attempt following attempt number n (1-based).
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < n; i++ {
+		d *= 2
+		if d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return d
+}
+
+// Sender performs the network delivery of a signed payload.
+//
+// Purpose: Transport boundary; implemented by the host (typically with net/http).
+// Domain: Tenant
+type Sender interface {
+	// Send posts body to url with headers and returns the response status code.
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+// Repository defines persistence for endpoints and deliveries.
+//
+// Purpose: Storage abstraction for webhook configuration and history.
+// Domain: Tenant
+type Repository interface {
+	// CreateEndpoint persists a new endpoint
+	CreateEndpoint(ctx context.Context, e *Endpoint) error
+	// GetEndpoint retrieves an endpoint within a tenant
+	GetEndpoint(ctx context.Context, tenantID, id string) (*Endpoint, error)
+	// ListEndpoints returns all endpoints of a tenant
+	ListEndpoints(ctx context.Context, tenantID string) ([]*Endpoint, error)
+	// UpdateEndpoint updates URL, events, and enabled state
+	UpdateEndpoint(ctx context.Context, e *Endpoint) error
+	// DeleteEndpoint removes an endpoint and its delivery history
+	DeleteEndpoint(ctx context.Context, tenantID, id string) error
+
+	// CreateDelivery persists a pending delivery
+	CreateDelivery(ctx context.Context, d *Delivery) error
+	// UpdateDelivery records the outcome of an attempt
+	UpdateDelivery(ctx context.Context, d *Delivery) error
+	// ListDueDeliveries returns pending deliveries whose next attempt is at or before now
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error)
+	// ListDeliveries returns the most recent deliveries for an endpoint
+	ListDeliveries(ctx context.Context, tenantID, endpointID string, limit int) ([]*Delivery, error)
+}